@@ -0,0 +1,85 @@
+package apierror
+
+import (
+	"errors"
+	"net/http"
+
+	"homework10/internal/domain"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Error ошибка сервиса, переведённая в ответ API: HTTP-статус, код gRPC и поля, не прошедшие проверку
+type Error struct {
+	HTTPStatus int
+	Code       codes.Code
+	Message    string
+	Fields     []FieldViolation
+}
+
+// FieldViolation поле запроса, не прошедшее проверку, и нарушенное правило
+type FieldViolation struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Translate сопоставляет ошибку сервиса HTTP-статусу и коду gRPC по категории доменной ошибки.
+// Ошибки без категории считаются внутренними
+func Translate(err error) Error {
+	e := Error{Message: err.Error()}
+
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		e.HTTPStatus, e.Code = http.StatusNotFound, codes.NotFound
+	case errors.Is(err, domain.ErrForbidden):
+		e.HTTPStatus, e.Code = http.StatusForbidden, codes.PermissionDenied
+	case errors.Is(err, domain.ErrValidation):
+		e.HTTPStatus, e.Code = http.StatusBadRequest, codes.InvalidArgument
+		var validationErrs domain.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			e.Fields = fieldViolations(validationErrs)
+		}
+	case errors.Is(err, domain.ErrConflict):
+		e.HTTPStatus, e.Code = http.StatusConflict, codes.AlreadyExists
+	case errors.Is(err, domain.ErrUnauthenticated):
+		e.HTTPStatus, e.Code = http.StatusUnauthorized, codes.Unauthenticated
+	case errors.Is(err, domain.ErrPrecondition):
+		e.HTTPStatus, e.Code = http.StatusPreconditionFailed, codes.Aborted
+	default:
+		e.HTTPStatus, e.Code = http.StatusInternalServerError, codes.Internal
+	}
+	return e
+}
+
+// Status статус gRPC; нарушения полей передаются деталями google.rpc.BadRequest
+func (e Error) Status() *status.Status {
+	st := status.New(e.Code, e.Message)
+	if len(e.Fields) == 0 {
+		return st
+	}
+	badRequest := &errdetails.BadRequest{}
+	for _, field := range e.Fields {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field.Field,
+			Description: field.Message,
+		})
+	}
+	if withDetails, err := st.WithDetails(badRequest); err == nil {
+		return withDetails
+	}
+	return st
+}
+
+func fieldViolations(errs domain.ValidationErrors) []FieldViolation {
+	if len(errs) == 0 {
+		return nil
+	}
+	fields := make([]FieldViolation, 0, len(errs))
+	for _, e := range errs {
+		fields = append(fields, FieldViolation{Field: e.Field, Rule: e.Rule, Message: e.Message})
+	}
+	return fields
+}
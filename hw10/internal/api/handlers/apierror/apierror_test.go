@@ -0,0 +1,120 @@
+package apierror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"homework10/internal/domain"
+	"homework10/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+)
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		httpStatus int
+		code       codes.Code
+		fields     []FieldViolation
+	}{
+		{
+			name:       "not found",
+			err:        domain.ErrAdNotExist,
+			httpStatus: http.StatusNotFound,
+			code:       codes.NotFound,
+		},
+		{
+			name:       "wrapped not found",
+			err:        fmt.Errorf("setting adID status: %w", domain.ErrUserNotExist),
+			httpStatus: http.StatusNotFound,
+			code:       codes.NotFound,
+		},
+		{
+			name:       "no access",
+			err:        service.ErrNoAccess{Err: service.ErrNoAccessAd},
+			httpStatus: http.StatusForbidden,
+			code:       codes.PermissionDenied,
+		},
+		{
+			name: "validation errors",
+			err: domain.ValidationErrors{
+				{Field: "Title", Rule: "required", Message: "wrong title"},
+				{Field: "Text", Rule: "max", Message: "wrong text"},
+			},
+			httpStatus: http.StatusBadRequest,
+			code:       codes.InvalidArgument,
+			fields: []FieldViolation{
+				{Field: "Title", Rule: "required", Message: "wrong title"},
+				{Field: "Text", Rule: "max", Message: "wrong text"},
+			},
+		},
+		{
+			name:       "password validation",
+			err:        service.ErrPasswordTooShort,
+			httpStatus: http.StatusBadRequest,
+			code:       codes.InvalidArgument,
+		},
+		{
+			name:       "invalid page",
+			err:        service.ErrInvalidPage{Err: service.ErrInvalidLimit},
+			httpStatus: http.StatusBadRequest,
+			code:       codes.InvalidArgument,
+		},
+		{
+			name:       "already exists",
+			err:        domain.ErrAlreadyExists{Err: domain.ErrEmailTaken},
+			httpStatus: http.StatusConflict,
+			code:       codes.AlreadyExists,
+		},
+		{
+			name:       "invalid credentials",
+			err:        service.ErrInvalidCredentials,
+			httpStatus: http.StatusUnauthorized,
+			code:       codes.Unauthenticated,
+		},
+		{
+			name:       "ad version mismatch",
+			err:        domain.ErrAdVersionMismatch,
+			httpStatus: http.StatusPreconditionFailed,
+			code:       codes.Aborted,
+		},
+		{
+			name:       "unknown error",
+			err:        errors.New("connection refused"),
+			httpStatus: http.StatusInternalServerError,
+			code:       codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := Translate(tt.err)
+			assert.Equal(t, tt.httpStatus, e.HTTPStatus)
+			assert.Equal(t, tt.code, e.Code)
+			assert.Equal(t, tt.err.Error(), e.Message)
+			assert.Equal(t, tt.fields, e.Fields)
+		})
+	}
+}
+
+func TestError_Status(t *testing.T) {
+	st := Translate(domain.ValidationErrors{{Field: "Title", Rule: "max", Message: "wrong title"}}).Status()
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Equal(t, "wrong title", st.Message())
+	if assert.Len(t, st.Details(), 1) {
+		badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+		if assert.True(t, ok) {
+			assert.Equal(t, "Title", badRequest.FieldViolations[0].Field)
+			assert.Equal(t, "wrong title", badRequest.FieldViolations[0].Description)
+		}
+	}
+
+	st = Translate(domain.ErrAdNotExist).Status()
+	assert.Equal(t, codes.NotFound, st.Code())
+	assert.Empty(t, st.Details())
+}
@@ -2,23 +2,43 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	contracts "homework10/internal/api/handlers/grpc/contracts/langs/go"
 	"homework10/internal/api/handlers/grpc/mapper"
+	"homework10/internal/api/handlers/params"
 	"homework10/internal/domain/models"
+	"homework10/internal/events"
+	"io"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 type AdService interface {
 	GetAdByID(ctx context.Context, adID int64) (*models.Ad, error)
-	CreateAd(ctx context.Context, title string, text string, authorID int64) (*models.Ad, error)
-	ChangeAdStatus(ctx context.Context, adID int64, userID int64, published bool) (*models.Ad, error)
-	UpdateAd(ctx context.Context, adID int64, userID int64, title string, text string) (*models.Ad, error)
-	DeleteAd(ctx context.Context, adID int64, userID int64) error
-	GetAdsByTitle(ctx context.Context, text string) ([]*models.Ad, error)
-	ListAds(ctx context.Context, published string, userIDRaw string, dateCreationRaw string) ([]*models.Ad, error)
+	CreateAd(ctx context.Context, title string, text string, details models.AdDetails) (*models.Ad, error)
+	ChangeAdStatus(ctx context.Context, adID int64, published bool, expectedVersion int64) (*models.Ad, error)
+	UpdateAd(ctx context.Context, adID int64, title string, text string, details models.AdDetails,
+		expectedVersion int64) (*models.Ad, error)
+	DeleteAd(ctx context.Context, adID int64) error
+	SearchAds(ctx context.Context, query string, page models.PageParams) (*models.SearchPage, error)
+	ListAds(ctx context.Context, filter models.AdFilter, page models.PageParams) (*models.AdsPage, error)
+	WatchAds(ctx context.Context, filter models.AdFilter) *events.Subscription
+	ListAdRevisions(ctx context.Context, adID int64) ([]*models.AdRevision, error)
+	RestoreAdRevision(ctx context.Context, adID int64, number int64) (*models.Ad, error)
+	RestoreAd(ctx context.Context, adID int64) (*models.Ad, error)
+	SubmitAd(ctx context.Context, adID int64, expectedVersion int64) (*models.Ad, error)
+	ApproveAd(ctx context.Context, adID int64, expectedVersion int64) (*models.Ad, error)
+	RejectAd(ctx context.Context, adID int64, reason string, expectedVersion int64) (*models.Ad, error)
+	ListModerationQueue(ctx context.Context) ([]*models.Ad, error)
+	UploadAdImage(ctx context.Context, adID int64, r io.Reader, expectedVersion int64) (*models.Ad, error)
+	ListCategories(ctx context.Context) ([]models.Category, error)
 }
 
+var ErrMissingImageInfo = errors.New("the first UploadAdImage message must contain info")
+
 type AdHandler struct {
 	adService AdService
 }
@@ -38,7 +58,8 @@ func (g *AdHandler) GetAd(ctx context.Context, request *contracts.GetAdRequest)
 }
 
 func (g *AdHandler) CreateAd(ctx context.Context, request *contracts.CreateAdRequest) (*contracts.AdResponse, error) {
-	ad, err := g.adService.CreateAd(ctx, request.Title, request.Text, request.UserId)
+	ad, err := g.adService.CreateAd(ctx, request.Title, request.Text,
+		mapper.AdDetailsFromRequest(request.Price, request.CategoryId, request.City))
 	if err != nil {
 		return nil, err
 	}
@@ -46,7 +67,7 @@ func (g *AdHandler) CreateAd(ctx context.Context, request *contracts.CreateAdReq
 }
 
 func (g *AdHandler) ChangeAdStatus(ctx context.Context, request *contracts.ChangeAdStatusRequest) (*contracts.AdResponse, error) {
-	ad, err := g.adService.ChangeAdStatus(ctx, request.AdId, request.UserId, request.Published)
+	ad, err := g.adService.ChangeAdStatus(ctx, request.AdId, request.Published, request.ExpectedVersion)
 	if err != nil {
 		return nil, err
 	}
@@ -54,7 +75,8 @@ func (g *AdHandler) ChangeAdStatus(ctx context.Context, request *contracts.Chang
 }
 
 func (g *AdHandler) UpdateAd(ctx context.Context, request *contracts.UpdateAdRequest) (*contracts.AdResponse, error) {
-	ad, err := g.adService.UpdateAd(ctx, request.AdId, request.UserId, request.Title, request.Text)
+	ad, err := g.adService.UpdateAd(ctx, request.AdId, request.Title, request.Text,
+		mapper.AdDetailsFromRequest(request.Price, request.CategoryId, request.City), request.ExpectedVersion)
 	if err != nil {
 		return nil, err
 	}
@@ -62,25 +84,178 @@ func (g *AdHandler) UpdateAd(ctx context.Context, request *contracts.UpdateAdReq
 }
 
 func (g *AdHandler) DeleteAd(ctx context.Context, request *contracts.DeleteAdRequest) (*emptypb.Empty, error) {
-	err := g.adService.DeleteAd(ctx, request.AdId, request.UserId)
+	err := g.adService.DeleteAd(ctx, request.AdId)
 	if err != nil {
 		return nil, err
 	}
 	return &emptypb.Empty{}, nil
 }
 
-func (g *AdHandler) SearchAds(ctx context.Context, request *contracts.SearchAdsRequest) (*contracts.ListAdsResponse, error) {
-	ads, err := g.adService.GetAdsByTitle(ctx, request.Text)
+func (g *AdHandler) SearchAds(ctx context.Context, request *contracts.SearchAdsRequest) (*contracts.SearchAdsResponse, error) {
+	page := models.PageParams{
+		Limit:     int(request.Limit),
+		PageToken: request.PageToken,
+		SortBy:    models.AdSortField(request.SortBy),
+		Order:     models.SortOrder(request.Order),
+	}
+	searchPage, err := g.adService.SearchAds(ctx, request.Text, page)
 	if err != nil {
 		return nil, err
 	}
-	return mapper.AdsToListResponse(ads), nil
+	return mapper.SearchPageToResponse(searchPage), nil
 }
 
 func (g *AdHandler) ListAds(ctx context.Context, request *contracts.ListAdsRequest) (*contracts.ListAdsResponse, error) {
-	ads, err := g.adService.ListAds(ctx, request.Published, request.UserId, request.Date)
+	filter, err := params.ParseAdFilter(request.Published, []string{request.UserId}, request.Date)
+	if err != nil {
+		return nil, err
+	}
+	filter, err = params.ApplyDateRanges(filter, mapper.TimeFromProto(request.CreatedFrom),
+		mapper.TimeFromProto(request.CreatedTo), mapper.TimeFromProto(request.UpdatedSince))
+	if err != nil {
+		return nil, err
+	}
+	filter, err = params.ParseCatalogFilter(filter, request.PriceMin, request.PriceMax, request.Currency,
+		request.Category, request.City)
+	if err != nil {
+		return nil, err
+	}
+	page := models.PageParams{
+		Limit:     int(request.Limit),
+		PageToken: request.PageToken,
+		SortBy:    models.AdSortField(request.SortBy),
+		Order:     models.SortOrder(request.Order),
+	}
+	adPage, err := g.adService.ListAds(ctx, filter, page)
+	if err != nil {
+		return nil, err
+	}
+	return mapper.AdsPageToListResponse(adPage), nil
+}
+
+func (g *AdHandler) ListCategories(ctx context.Context, _ *emptypb.Empty) (*contracts.ListCategoriesResponse, error) {
+	categories, err := g.adService.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return mapper.CategoriesToListResponse(categories), nil
+}
+
+// WatchAds отправляет клиенту изменения объявлений, подходящих под фильтр, пока клиент не отменит вызов.
+// Заголовки отправляются сразу после подписки: после их получения клиент не пропустит ни одного события
+func (g *AdHandler) WatchAds(request *contracts.WatchAdsRequest, stream contracts.AdService_WatchAdsServer) error {
+	filter, err := params.ParseAdFilter(request.Published, []string{request.UserId}, request.Date)
+	if err != nil {
+		return err
+	}
+	sub := g.adService.WatchAds(stream.Context(), filter)
+	defer sub.Close()
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for event := range sub.Events() {
+		if err := stream.Send(mapper.AdEventToResponse(event)); err != nil {
+			return err
+		}
+	}
+	if err := sub.Err(); err != nil {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return nil
+}
+
+func (g *AdHandler) ListAdRevisions(ctx context.Context, request *contracts.ListAdRevisionsRequest) (*contracts.ListAdRevisionsResponse, error) {
+	revisions, err := g.adService.ListAdRevisions(ctx, request.AdId)
+	if err != nil {
+		return nil, err
+	}
+	return mapper.AdRevisionsToListResponse(revisions), nil
+}
+
+func (g *AdHandler) RestoreAdRevision(ctx context.Context, request *contracts.RestoreAdRevisionRequest) (*contracts.AdResponse, error) {
+	ad, err := g.adService.RestoreAdRevision(ctx, request.AdId, request.Number)
+	if err != nil {
+		return nil, err
+	}
+	return mapper.AdToResponse(ad), nil
+}
+
+func (g *AdHandler) RestoreAd(ctx context.Context, request *contracts.RestoreAdRequest) (*contracts.AdResponse, error) {
+	ad, err := g.adService.RestoreAd(ctx, request.AdId)
+	if err != nil {
+		return nil, err
+	}
+	return mapper.AdToResponse(ad), nil
+}
+
+func (g *AdHandler) SubmitAd(ctx context.Context, request *contracts.SubmitAdRequest) (*contracts.AdResponse, error) {
+	ad, err := g.adService.SubmitAd(ctx, request.AdId, request.ExpectedVersion)
+	if err != nil {
+		return nil, err
+	}
+	return mapper.AdToResponse(ad), nil
+}
+
+func (g *AdHandler) ApproveAd(ctx context.Context, request *contracts.ApproveAdRequest) (*contracts.AdResponse, error) {
+	ad, err := g.adService.ApproveAd(ctx, request.AdId, request.ExpectedVersion)
+	if err != nil {
+		return nil, err
+	}
+	return mapper.AdToResponse(ad), nil
+}
+
+func (g *AdHandler) RejectAd(ctx context.Context, request *contracts.RejectAdRequest) (*contracts.AdResponse, error) {
+	ad, err := g.adService.RejectAd(ctx, request.AdId, request.Reason, request.ExpectedVersion)
+	if err != nil {
+		return nil, err
+	}
+	return mapper.AdToResponse(ad), nil
+}
+
+func (g *AdHandler) ListModerationQueue(ctx context.Context, _ *emptypb.Empty) (*contracts.ListAdsResponse, error) {
+	ads, err := g.adService.ListModerationQueue(ctx)
 	if err != nil {
 		return nil, err
 	}
 	return mapper.AdsToListResponse(ads), nil
 }
+
+// UploadAdImage читает из потока описание загрузки и содержимое изображения; сервис читает части по мере прихода
+func (g *AdHandler) UploadAdImage(stream contracts.AdService_UploadAdImageServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	info := first.GetInfo()
+	if info == nil {
+		return status.Error(codes.InvalidArgument, ErrMissingImageInfo.Error())
+	}
+	ad, err := g.adService.UploadAdImage(stream.Context(), info.AdId, &imageChunkReader{stream: stream}, info.ExpectedVersion)
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(mapper.AdToResponse(ad))
+}
+
+// imageChunkReader читает содержимое изображения из сообщений chunk потока UploadAdImage
+type imageChunkReader struct {
+	stream contracts.AdService_UploadAdImageServer
+	chunk  []byte
+}
+
+func (r *imageChunkReader) Read(p []byte) (int, error) {
+	for len(r.chunk) == 0 {
+		message, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		if message.GetInfo() != nil {
+			return 0, status.Error(codes.InvalidArgument, "info must be sent only in the first message")
+		}
+		r.chunk = message.GetChunk()
+	}
+	n := copy(p, r.chunk)
+	r.chunk = r.chunk[n:]
+	return n, nil
+}
@@ -0,0 +1,30 @@
+package grpc
+
+import (
+	"context"
+	contracts "homework10/internal/api/handlers/grpc/contracts/langs/go"
+	"homework10/internal/api/handlers/grpc/mapper"
+	"homework10/internal/domain/models"
+)
+
+type AuthService interface {
+	Login(ctx context.Context, email string, password string) (*models.AuthToken, error)
+}
+
+type AuthHandler struct {
+	authService AuthService
+}
+
+func NewAuthHandler(authServ AuthService) *AuthHandler {
+	return &AuthHandler{
+		authService: authServ,
+	}
+}
+
+func (h *AuthHandler) Login(ctx context.Context, request *contracts.LoginRequest) (*contracts.LoginResponse, error) {
+	token, err := h.authService.Login(ctx, request.Email, request.Password)
+	if err != nil {
+		return nil, err
+	}
+	return mapper.TokenToLoginResponse(token), nil
+}
@@ -2,31 +2,40 @@ package interceptors
 
 import (
 	"context"
-	"encoding/json"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"homework10/internal/auth"
 	"homework10/internal/domain/models"
+	"homework10/internal/policy"
 )
 
-type userIDRequest struct {
-	UserID int64 `json:"user_id"`
-}
-
 type UserService interface {
 	GetUser(ctx context.Context, userID int64) (*models.User, error)
 }
 
+type TokenParser interface {
+	Parse(token string) (int64, error)
+}
+
 type GRPCUserIdentityMiddleware struct {
+	tokens  TokenParser
 	service UserService
+	policy  *policy.Policy
 }
 
-func NewGRPCUserIdentityMiddleware(s UserService) *GRPCUserIdentityMiddleware {
+func NewGRPCUserIdentityMiddleware(tokens TokenParser, s UserService, p *policy.Policy) *GRPCUserIdentityMiddleware {
 	return &GRPCUserIdentityMiddleware{
+		tokens:  tokens,
 		service: s,
+		policy:  p,
 	}
 }
 
+// GRPCUserMiddleware для методов, которые policy.GRPCEndpoint не объявляет публичными, проверяет токен
+// из метаданных authorization, кладёт ID и роль пользователя в контекст и отказывает ролям, которым
+// действие метода запрещено
 func (h *GRPCUserIdentityMiddleware) GRPCUserMiddleware(
 	ctx context.Context,
 	req interface{},
@@ -34,24 +43,89 @@ func (h *GRPCUserIdentityMiddleware) GRPCUserMiddleware(
 	handler grpc.UnaryHandler,
 ) (interface{}, error) {
 
-	switch info.FullMethod {
-	case "/service.AdService/CreateAd",
-		"/service.AdService/ChangeAdStatus",
-		"/service.AdService/UpdateAd",
-		"/service.AdService/DeleteAd":
-		a, err := json.Marshal(req)
-		if err != nil {
-			return nil, status.Error(codes.InvalidArgument, "invalid argument")
-		}
-		var reqBody userIDRequest
-		err = json.Unmarshal(a, &reqBody)
-		if err != nil {
-			return nil, status.Error(codes.InvalidArgument, "invalid argument")
-		}
-		_, err = h.service.GetUser(ctx, reqBody.UserID)
-		if err != nil {
-			return nil, status.Error(codes.Unauthenticated, "the user is not registered")
-		}
+	endpoint := policy.GRPCEndpoint(info.FullMethod)
+	if endpoint.Public {
+		return handler(h.identify(ctx), req)
+	}
+	ctx, err := h.authorize(ctx, endpoint)
+	if err != nil {
+		return nil, err
 	}
 	return handler(ctx, req)
 }
+
+// GRPCUserStreamMiddleware то же, что GRPCUserMiddleware, для потоковых методов
+func (h *GRPCUserIdentityMiddleware) GRPCUserStreamMiddleware(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+
+	endpoint := policy.GRPCEndpoint(info.FullMethod)
+	if endpoint.Public {
+		return handler(srv, &contextStream{ServerStream: ss, ctx: h.identify(ss.Context())})
+	}
+	ctx, err := h.authorize(ss.Context(), endpoint)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+}
+
+// contextStream подменяет контекст потока, чтобы передать обработчику ID пользователя
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// authorize аутентифицирует вызов и проверяет, что роли пользователя разрешён endpoint; возвращает контекст
+// с ID и ролью пользователя
+func (h *GRPCUserIdentityMiddleware) authorize(ctx context.Context, endpoint policy.Endpoint) (context.Context, error) {
+	user, err := h.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx = auth.WithRole(auth.WithUserID(ctx, user.ID), user.Role)
+	if !h.policy.Permits(auth.RoleFromContext(ctx), endpoint) {
+		return nil, status.Error(codes.PermissionDenied, policy.ErrDenied.Error())
+	}
+	return ctx, nil
+}
+
+// identify для публичного метода: токен необязателен, но с действительным токеном сервис знает, кто вызывает
+// метод (например, автор видит в ленте свои неопубликованные объявления). Недействительный токен не мешает
+// вызову, как и его отсутствие
+func (h *GRPCUserIdentityMiddleware) identify(ctx context.Context) context.Context {
+	user, err := h.authenticate(ctx)
+	if err != nil {
+		return ctx
+	}
+	return auth.WithRole(auth.WithUserID(ctx, user.ID), user.Role)
+}
+
+func (h *GRPCUserIdentityMiddleware) authenticate(ctx context.Context) (*models.User, error) {
+	var header string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) != 0 {
+			header = values[0]
+		}
+	}
+	token, err := auth.BearerToken(header)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	userID, err := h.tokens.Parse(token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	user, err := h.service.GetUser(ctx, userID)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "the user is not registered")
+	}
+	return user, nil
+}
@@ -0,0 +1,46 @@
+package interceptors
+
+import (
+	"context"
+
+	"homework10/internal/api/handlers/apierror"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorInterceptor переводит ошибки сервисов в статусы gRPC; ошибки, уже имеющие статус, не меняются
+func ErrorInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+
+	resp, err := handler(ctx, req)
+	if err == nil {
+		return resp, nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return nil, err
+	}
+	return nil, apierror.Translate(err).Status().Err()
+}
+
+// ErrorStreamInterceptor то же, что ErrorInterceptor, для потоковых методов
+func ErrorStreamInterceptor(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+
+	err := handler(srv, ss)
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return apierror.Translate(err).Status().Err()
+}
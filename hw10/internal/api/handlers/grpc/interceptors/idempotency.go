@@ -0,0 +1,112 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+
+	"homework10/internal/idempotency"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type IdempotencyStore interface {
+	Begin(scope string, key string, fingerprint string) (any, error)
+	Complete(scope string, key string, response any)
+	Release(scope string, key string)
+}
+
+// recordedResponse результат вызова с ключом идемпотентности
+type recordedResponse struct {
+	message proto.Message
+	err     error
+}
+
+type GRPCIdempotencyMiddleware struct {
+	store IdempotencyStore
+}
+
+func NewGRPCIdempotencyMiddleware(store IdempotencyStore) *GRPCIdempotencyMiddleware {
+	return &GRPCIdempotencyMiddleware{store: store}
+}
+
+// GRPCIdempotencyInterceptor для методов из idempotency.GRPCMethod, вызванных с метаданными idempotency-key,
+// сохраняет результат и отдаёт его повторам вызова вместо выполнения. Тот же ключ с другим запросом
+// получает InvalidArgument, повтор во время выполнения первого вызова - Aborted. Внутренние ошибки
+// не сохраняются. Должен стоять в цепочке после GRPCUserMiddleware и перед ErrorInterceptor
+func (m *GRPCIdempotencyMiddleware) GRPCIdempotencyInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+
+	key := idempotencyKey(ctx)
+	message, ok := req.(proto.Message)
+	if key == "" || !ok || !idempotency.GRPCMethod(info.FullMethod) {
+		return handler(ctx, req)
+	}
+	if err := idempotency.CheckKey(key); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	payload, err := proto.MarshalOptions{Deterministic: true}.Marshal(message)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	scope := idempotency.Scope(ctx, peerIP(ctx), info.FullMethod)
+	stored, err := m.store.Begin(scope, key, idempotency.Fingerprint([]byte(info.FullMethod), payload))
+	switch {
+	case errors.Is(err, idempotency.ErrKeyReused):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Aborted, err.Error())
+	case stored != nil:
+		_ = grpc.SetHeader(ctx, metadata.Pairs(idempotency.ReplayedHeader, "true"))
+		response := stored.(*recordedResponse)
+		if response.err != nil {
+			return nil, response.err
+		}
+		return proto.Clone(response.message), nil
+	}
+
+	completed := false
+	defer func() {
+		// обработчик упал: повтор должен выполниться заново, а не ждать истечения ключа
+		if !completed {
+			m.store.Release(scope, key)
+		}
+	}()
+	resp, err := handler(ctx, req)
+	completed = true
+
+	switch status.Code(err) {
+	case codes.Internal, codes.Unknown, codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
+		m.store.Release(scope, key)
+		return resp, err
+	}
+	response := &recordedResponse{err: err}
+	if err == nil {
+		if response.message, ok = resp.(proto.Message); !ok {
+			m.store.Release(scope, key)
+			return resp, err
+		}
+		response.message = proto.Clone(response.message)
+	}
+	m.store.Complete(scope, key, response)
+	return resp, err
+}
+
+func idempotencyKey(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(idempotency.GRPCMetadata); len(values) != 0 {
+		return values[0]
+	}
+	return ""
+}
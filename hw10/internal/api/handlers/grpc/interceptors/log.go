@@ -5,6 +5,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/status"
+	"homework10/internal/tracing"
 	"time"
 )
 
@@ -18,11 +19,30 @@ func LoggingInterceptor(ctx context.Context,
 
 	st, _ := status.FromError(err)
 
-	log.WithFields(log.Fields{
+	log.WithFields(tracing.LogFields(ctx, log.Fields{
 		"METHOD":  info.FullMethod,
 		"STATUS":  st.Code(),
 		"LATENCY": time.Since(start),
 		"Error":   err,
-	}).Info("GRPC REQUEST")
+	})).Info("GRPC REQUEST")
 	return h, err
 }
+
+func LoggingStreamInterceptor(srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+	start := time.Now()
+
+	err := handler(srv, ss)
+
+	st, _ := status.FromError(err)
+
+	log.WithFields(tracing.LogFields(ss.Context(), log.Fields{
+		"METHOD":  info.FullMethod,
+		"STATUS":  st.Code(),
+		"LATENCY": time.Since(start),
+		"Error":   err,
+	})).Info("GRPC STREAM")
+	return err
+}
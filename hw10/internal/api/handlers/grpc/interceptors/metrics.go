@@ -0,0 +1,50 @@
+package interceptors
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+type GRPCMetrics interface {
+	TrackGRPC(fullMethod string) func(code string)
+}
+
+type GRPCMetricsMiddleware struct {
+	metrics GRPCMetrics
+}
+
+func NewGRPCMetricsMiddleware(metrics GRPCMetrics) *GRPCMetricsMiddleware {
+	return &GRPCMetricsMiddleware{metrics: metrics}
+}
+
+// GRPCMetricsInterceptor учитывает вызовы, их длительность и число выполняющихся по методам и статусам;
+// должен стоять в начале цепочки, чтобы учесть и отказы остальных перехватчиков
+func (m *GRPCMetricsMiddleware) GRPCMetricsInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+
+	done := m.metrics.TrackGRPC(info.FullMethod)
+	resp, err := handler(ctx, req)
+	done(status.Code(err).String())
+	return resp, err
+}
+
+// GRPCMetricsStreamInterceptor то же, что GRPCMetricsInterceptor, для потоковых методов; длительность
+// считается до закрытия потока
+func (m *GRPCMetricsMiddleware) GRPCMetricsStreamInterceptor(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+
+	done := m.metrics.TrackGRPC(info.FullMethod)
+	err := handler(srv, ss)
+	done(status.Code(err).String())
+	return err
+}
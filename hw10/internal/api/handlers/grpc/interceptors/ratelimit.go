@@ -0,0 +1,80 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"homework10/internal/ratelimit"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// retryAfterHeader заголовок ответа с ожиданием в секундах, как Retry-After в HTTP
+const retryAfterHeader = "retry-after"
+
+type RateLimiter interface {
+	Allow(route string, client string) (bool, time.Duration)
+}
+
+type GRPCRateLimitMiddleware struct {
+	limiter RateLimiter
+}
+
+func NewGRPCRateLimitMiddleware(limiter RateLimiter) *GRPCRateLimitMiddleware {
+	return &GRPCRateLimitMiddleware{limiter: limiter}
+}
+
+// GRPCRateLimitInterceptor ограничивает частоту вызовов метода для каждого клиента: пользователя, если
+// вызов аутентифицирован, иначе IP-адреса. Превысившему лимит отвечает ResourceExhausted с заголовком retry-after;
+// должен стоять в цепочке после GRPCUserMiddleware
+func (m *GRPCRateLimitMiddleware) GRPCRateLimitInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+
+	if retryAfter, limited := m.limit(ctx, info.FullMethod); limited {
+		_ = grpc.SetHeader(ctx, metadata.Pairs(retryAfterHeader, ratelimit.RetryAfterSeconds(retryAfter)))
+		return nil, status.Error(codes.ResourceExhausted, ratelimit.ErrLimited.Error())
+	}
+	return handler(ctx, req)
+}
+
+// GRPCRateLimitStreamInterceptor то же, что GRPCRateLimitInterceptor, для открытия потоков
+func (m *GRPCRateLimitMiddleware) GRPCRateLimitStreamInterceptor(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+
+	if retryAfter, limited := m.limit(ss.Context(), info.FullMethod); limited {
+		_ = ss.SetHeader(metadata.Pairs(retryAfterHeader, ratelimit.RetryAfterSeconds(retryAfter)))
+		return status.Error(codes.ResourceExhausted, ratelimit.ErrLimited.Error())
+	}
+	return handler(srv, ss)
+}
+
+func (m *GRPCRateLimitMiddleware) limit(ctx context.Context, fullMethod string) (time.Duration, bool) {
+	allowed, retryAfter := m.limiter.Allow(fullMethod, ratelimit.ClientKey(ctx, peerIP(ctx)))
+	return retryAfter, !allowed
+}
+
+// peerIP адрес клиента вызова без порта
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
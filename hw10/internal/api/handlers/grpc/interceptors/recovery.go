@@ -26,3 +26,21 @@ func RecoverInterceptor(
 	resp, err := handler(ctx, req)
 	return resp, err
 }
+
+func RecoverStreamInterceptor(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+
+	logger := log.New()
+
+	defer func() {
+		if err := recover(); err != nil {
+			logger.Printf("[Recovery] %s panic recovered from stream %s: %s\n", time.Now(), info.FullMethod, err)
+		}
+	}()
+
+	return handler(srv, ss)
+}
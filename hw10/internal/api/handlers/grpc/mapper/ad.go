@@ -2,7 +2,11 @@ package mapper
 
 import (
 	contracts "homework10/internal/api/handlers/grpc/contracts/langs/go"
+	"homework10/internal/api/handlers/links"
 	"homework10/internal/domain/models"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func AdToResponse(ad *models.Ad) *contracts.AdResponse {
@@ -12,7 +16,91 @@ func AdToResponse(ad *models.Ad) *contracts.AdResponse {
 		Text:      ad.Text,
 		UserId:    ad.UserID,
 		Published: ad.Published,
+		Version:   ad.Version,
+
+		DateCreation: TimeToProto(ad.DateCreation),
+		DateUpdate:   TimeToProto(ad.DateUpdate),
+
+		State:           adStates[ad.State],
+		RejectionReason: ad.RejectionReason,
+		Images:          ImagesToResponse(ad),
+		Price:           PriceToResponse(ad.Price),
+		CategoryId:      ad.CategoryID,
+		City:            ad.City,
+	}
+}
+
+func PriceToResponse(price models.Price) *contracts.Price {
+	if !price.IsSet() {
+		return nil
+	}
+	return &contracts.Price{Amount: price.Amount, Currency: price.Currency}
+}
+
+// AdDetailsFromRequest собирает характеристики объявления из полей запроса; отсутствующая цена - цена не указана
+func AdDetailsFromRequest(price *contracts.Price, categoryID string, city string) models.AdDetails {
+	return models.AdDetails{
+		Price:      models.Price{Amount: price.GetAmount(), Currency: price.GetCurrency()},
+		CategoryID: categoryID,
+		City:       city,
+	}
+}
+
+func FacetCountsToResponse(counts []models.FacetCount) []*contracts.FacetCount {
+	var res []*contracts.FacetCount
+	for _, count := range counts {
+		res = append(res, &contracts.FacetCount{Value: count.Value, Count: int64(count.Count)})
+	}
+	return res
+}
+
+func CategoriesToListResponse(categories []models.Category) *contracts.ListCategoriesResponse {
+	list := make([]*contracts.Category, 0, len(categories))
+	for _, category := range categories {
+		list = append(list, &contracts.Category{Id: category.ID, Name: category.Name, ParentId: category.ParentID})
+	}
+	return &contracts.ListCategoriesResponse{List: list}
+}
+
+func ImagesToResponse(ad *models.Ad) []*contracts.AdImage {
+	var images []*contracts.AdImage
+	for _, image := range ad.Images {
+		images = append(images, &contracts.AdImage{
+			Id:           image.ID,
+			Url:          links.AdImage(ad.ID, image.ID),
+			ThumbnailUrl: links.AdImageThumbnail(ad.ID, image.ID),
+			ContentType:  image.ContentType,
+			Size:         image.Size,
+			Width:        int32(image.Width),
+			Height:       int32(image.Height),
+		})
 	}
+	return images
+}
+
+var adStates = map[models.AdState]contracts.AdState{
+	models.AdDraft:         contracts.AdState_AD_STATE_DRAFT,
+	models.AdPendingReview: contracts.AdState_AD_STATE_PENDING_REVIEW,
+	models.AdApproved:      contracts.AdState_AD_STATE_APPROVED,
+	models.AdRejected:      contracts.AdState_AD_STATE_REJECTED,
+	models.AdPublished:     contracts.AdState_AD_STATE_PUBLISHED,
+	models.AdArchived:      contracts.AdState_AD_STATE_ARCHIVED,
+}
+
+// TimeToProto переводит время в метку для ответа; нулевое время не передаётся
+func TimeToProto(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// TimeFromProto переводит необязательную метку времени из запроса; отсутствующая метка - нулевое время
+func TimeFromProto(timestamp *timestamppb.Timestamp) time.Time {
+	if timestamp == nil {
+		return time.Time{}
+	}
+	return timestamp.AsTime()
 }
 
 func AdsToListResponse(ads []*models.Ad) *contracts.ListAdsResponse {
@@ -22,3 +110,42 @@ func AdsToListResponse(ads []*models.Ad) *contracts.ListAdsResponse {
 	}
 	return &contracts.ListAdsResponse{List: listAds}
 }
+
+func AdsPageToListResponse(page *models.AdsPage) *contracts.ListAdsResponse {
+	response := AdsToListResponse(page.Ads)
+	response.NextPageToken = page.NextPageToken
+	response.CategoryFacets = FacetCountsToResponse(page.Facets.Categories)
+	response.CityFacets = FacetCountsToResponse(page.Facets.Cities)
+	return response
+}
+
+func SearchPageToResponse(page *models.SearchPage) *contracts.SearchAdsResponse {
+	list := make([]*contracts.SearchAdResponse, 0)
+	for _, result := range page.Results {
+		list = append(list, &contracts.SearchAdResponse{
+			Ad:      AdToResponse(result.Ad),
+			Score:   result.Score,
+			Snippet: result.Snippet,
+		})
+	}
+	return &contracts.SearchAdsResponse{List: list, NextPageToken: page.NextPageToken}
+}
+
+var adEventTypes = map[models.AdEventType]contracts.AdEventType{
+	models.AdCreated:       contracts.AdEventType_AD_EVENT_TYPE_CREATED,
+	models.AdUpdated:       contracts.AdEventType_AD_EVENT_TYPE_UPDATED,
+	models.AdStatusChanged: contracts.AdEventType_AD_EVENT_TYPE_STATUS_CHANGED,
+	models.AdDeleted:       contracts.AdEventType_AD_EVENT_TYPE_DELETED,
+	models.AdRestored:      contracts.AdEventType_AD_EVENT_TYPE_RESTORED,
+	models.AdModerated:     contracts.AdEventType_AD_EVENT_TYPE_MODERATED,
+	models.AdLeft:          contracts.AdEventType_AD_EVENT_TYPE_LEFT,
+}
+
+func AdEventToResponse(event models.AdEvent) *contracts.AdEvent {
+	return &contracts.AdEvent{
+		Id:   event.ID,
+		Type: adEventTypes[event.Type],
+		Ad:   AdToResponse(&event.Ad),
+		Time: timestamppb.New(event.Time),
+	}
+}
@@ -6,9 +6,13 @@ import (
 	"homework10/internal/domain/models"
 	"reflect"
 	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestAdToResponse(t *testing.T) {
+	date := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
 
 	tests := []struct {
 		name     string
@@ -32,6 +36,76 @@ func TestAdToResponse(t *testing.T) {
 				Published: false,
 			},
 		},
+		{
+			name: "map ad dates to timestamps",
+			ad: &models.Ad{
+				ID:           1,
+				Title:        "test title",
+				DateCreation: date,
+				DateUpdate:   date.Add(time.Hour),
+			},
+			expected: &contracts.AdResponse{
+				Id:           1,
+				Title:        "test title",
+				DateCreation: timestamppb.New(date),
+				DateUpdate:   timestamppb.New(date.Add(time.Hour)),
+			},
+		},
+		{
+			name: "map rejected ad",
+			ad: &models.Ad{
+				ID:              1,
+				Title:           "test title",
+				State:           models.AdRejected,
+				RejectionReason: "spam",
+			},
+			expected: &contracts.AdResponse{
+				Id:              1,
+				Title:           "test title",
+				State:           contracts.AdState_AD_STATE_REJECTED,
+				RejectionReason: "spam",
+			},
+		},
+		{
+			name: "map ad images to links",
+			ad: &models.Ad{
+				ID:     3,
+				Title:  "test title",
+				Images: []models.AdImage{{ID: 2, ContentType: "image/png", Size: 100, Width: 20, Height: 10, Key: "ads/3/a"}},
+			},
+			expected: &contracts.AdResponse{
+				Id:    3,
+				Title: "test title",
+				Images: []*contracts.AdImage{{
+					Id:           2,
+					Url:          "/api/v1/ads/3/images/2",
+					ThumbnailUrl: "/api/v1/ads/3/images/2/thumbnail",
+					ContentType:  "image/png",
+					Size:         100,
+					Width:        20,
+					Height:       10,
+				}},
+			},
+		},
+		{
+			name: "map ad details",
+			ad: &models.Ad{
+				ID:    4,
+				Title: "test title",
+				AdDetails: models.AdDetails{
+					Price:      models.Price{Amount: 150000, Currency: "RUB"},
+					CategoryID: "cars",
+					City:       "Казань",
+				},
+			},
+			expected: &contracts.AdResponse{
+				Id:         4,
+				Title:      "test title",
+				Price:      &contracts.Price{Amount: 150000, Currency: "RUB"},
+				CategoryId: "cars",
+				City:       "Казань",
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -83,3 +157,151 @@ func TestAdToSliceResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestAdsPageToListResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		page     *models.AdsPage
+		expected *contracts.ListAdsResponse
+	}{
+		{
+			name: "successfully map page to response",
+			page: &models.AdsPage{
+				Ads:           []*models.Ad{{ID: 1, Title: "test title", Text: "test text", UserID: 2}},
+				NextPageToken: "token",
+			},
+			expected: &contracts.ListAdsResponse{
+				List:          []*contracts.AdResponse{{Id: 1, Title: "test title", Text: "test text", UserId: 2}},
+				NextPageToken: "token",
+			},
+		},
+		{
+			name: "map facets",
+			page: &models.AdsPage{
+				Ads: []*models.Ad{},
+				Facets: models.AdFacets{
+					Categories: []models.FacetCount{{Value: "transport", Count: 2}, {Value: "cars", Count: 1}},
+					Cities:     []models.FacetCount{{Value: "Москва", Count: 2}},
+				},
+			},
+			expected: &contracts.ListAdsResponse{
+				List:           []*contracts.AdResponse{},
+				CategoryFacets: []*contracts.FacetCount{{Value: "transport", Count: 2}, {Value: "cars", Count: 1}},
+				CityFacets:     []*contracts.FacetCount{{Value: "Москва", Count: 2}},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			actual := AdsPageToListResponse(tc.page)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestSearchPageToResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		page     *models.SearchPage
+		expected *contracts.SearchAdsResponse
+	}{
+		{
+			name: "successfully map search page to response",
+			page: &models.SearchPage{
+				Results: []*models.SearchResult{{
+					Ad:      &models.Ad{ID: 1, Title: "test title", Text: "test text", UserID: 2},
+					Score:   0.75,
+					Snippet: "<mark>test</mark> text",
+				}},
+				NextPageToken: "token",
+			},
+			expected: &contracts.SearchAdsResponse{
+				List: []*contracts.SearchAdResponse{{
+					Ad:      &contracts.AdResponse{Id: 1, Title: "test title", Text: "test text", UserId: 2},
+					Score:   0.75,
+					Snippet: "<mark>test</mark> text",
+				}},
+				NextPageToken: "token",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			actual := SearchPageToResponse(tc.page)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestAdEventToResponse(t *testing.T) {
+	eventTime := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		event    models.AdEvent
+		expected *contracts.AdEvent
+	}{
+		{
+			name:  "successfully map status change event",
+			event: models.AdEvent{ID: 3, Type: models.AdStatusChanged, Ad: models.Ad{ID: 1, UserID: 2, Published: true}, Time: eventTime},
+			expected: &contracts.AdEvent{
+				Id:   3,
+				Type: contracts.AdEventType_AD_EVENT_TYPE_STATUS_CHANGED,
+				Ad:   &contracts.AdResponse{Id: 1, UserId: 2, Published: true},
+				Time: timestamppb.New(eventTime),
+			},
+		},
+		{
+			name:  "successfully map delete event",
+			event: models.AdEvent{ID: 4, Type: models.AdDeleted, Ad: models.Ad{ID: 1, UserID: 2}, Time: eventTime},
+			expected: &contracts.AdEvent{
+				Id:   4,
+				Type: contracts.AdEventType_AD_EVENT_TYPE_DELETED,
+				Ad:   &contracts.AdResponse{Id: 1, UserId: 2},
+				Time: timestamppb.New(eventTime),
+			},
+		},
+		{
+			name:  "successfully map restore event",
+			event: models.AdEvent{ID: 5, Type: models.AdRestored, Ad: models.Ad{ID: 1, UserID: 2}, Time: eventTime},
+			expected: &contracts.AdEvent{
+				Id:   5,
+				Type: contracts.AdEventType_AD_EVENT_TYPE_RESTORED,
+				Ad:   &contracts.AdResponse{Id: 1, UserId: 2},
+				Time: timestamppb.New(eventTime),
+			},
+		},
+		{
+			name:  "successfully map left event",
+			event: models.AdEvent{ID: 6, Type: models.AdLeft, Ad: models.Ad{ID: 1}, Time: eventTime},
+			expected: &contracts.AdEvent{
+				Id:   6,
+				Type: contracts.AdEventType_AD_EVENT_TYPE_LEFT,
+				Ad:   &contracts.AdResponse{Id: 1},
+				Time: timestamppb.New(eventTime),
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			actual := AdEventToResponse(tc.event)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestTimeFromProto(t *testing.T) {
+	date := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	require.True(t, TimeFromProto(nil).IsZero())
+	require.Equal(t, date, TimeFromProto(timestamppb.New(date)))
+	require.Nil(t, TimeToProto(time.Time{}))
+}
@@ -0,0 +1,15 @@
+package mapper
+
+import (
+	contracts "homework10/internal/api/handlers/grpc/contracts/langs/go"
+	"homework10/internal/domain/models"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TokenToLoginResponse(token *models.AuthToken) *contracts.LoginResponse {
+	return &contracts.LoginResponse{
+		Token:     token.Token,
+		ExpiresAt: timestamppb.New(token.ExpiresAt),
+	}
+}
@@ -0,0 +1,17 @@
+package mapper
+
+import (
+	contracts "homework10/internal/api/handlers/grpc/contracts/langs/go"
+	"homework10/internal/domain/models"
+)
+
+func FavoritesToListResponse(favorites []*models.FavoriteAd) *contracts.ListFavoritesResponse {
+	list := make([]*contracts.FavoriteAd, 0, len(favorites))
+	for _, favorite := range favorites {
+		list = append(list, &contracts.FavoriteAd{
+			Ad:      AdToResponse(favorite.Ad),
+			AddedAt: TimeToProto(favorite.AddedAt),
+		})
+	}
+	return &contracts.ListFavoritesResponse{List: list}
+}
@@ -0,0 +1,43 @@
+package mapper
+
+import (
+	contracts "homework10/internal/api/handlers/grpc/contracts/langs/go"
+	"homework10/internal/domain/models"
+)
+
+func MessageToResponse(message *models.Message) *contracts.Message {
+	return &contracts.Message{
+		Id:             message.ID,
+		ConversationId: message.ConversationID,
+		SenderId:       message.SenderID,
+		Text:           message.Text,
+		DateCreation:   TimeToProto(message.DateCreation),
+		ReadAt:         TimeToProto(message.ReadAt),
+	}
+}
+
+func MessagesToListResponse(messages []*models.Message) *contracts.ListMessagesResponse {
+	list := make([]*contracts.Message, 0, len(messages))
+	for _, message := range messages {
+		list = append(list, MessageToResponse(message))
+	}
+	return &contracts.ListMessagesResponse{List: list}
+}
+
+func InboxToListResponse(inbox *models.Inbox) *contracts.ListConversationsResponse {
+	list := make([]*contracts.Conversation, 0, len(inbox.Conversations))
+	for _, summary := range inbox.Conversations {
+		conversation := summary.Conversation
+		list = append(list, &contracts.Conversation{
+			Id:            conversation.ID,
+			AdId:          conversation.AdID,
+			BuyerId:       conversation.BuyerID,
+			SellerId:      conversation.SellerID,
+			DateCreation:  TimeToProto(conversation.DateCreation),
+			LastMessageAt: TimeToProto(conversation.LastMessageAt),
+			LastMessage:   MessageToResponse(summary.LastMessage),
+			UnreadCount:   int64(summary.UnreadCount),
+		})
+	}
+	return &contracts.ListConversationsResponse{List: list, UnreadCount: int64(inbox.UnreadCount)}
+}
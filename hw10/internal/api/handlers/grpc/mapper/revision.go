@@ -0,0 +1,42 @@
+package mapper
+
+import (
+	contracts "homework10/internal/api/handlers/grpc/contracts/langs/go"
+	"homework10/internal/domain/models"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var adRevisionTypes = map[models.AdRevisionType]contracts.AdRevisionType{
+	models.RevisionCreated:       contracts.AdRevisionType_AD_REVISION_TYPE_CREATED,
+	models.RevisionUpdated:       contracts.AdRevisionType_AD_REVISION_TYPE_UPDATED,
+	models.RevisionStatusChanged: contracts.AdRevisionType_AD_REVISION_TYPE_STATUS_CHANGED,
+	models.RevisionRestored:      contracts.AdRevisionType_AD_REVISION_TYPE_RESTORED,
+}
+
+func AdRevisionToResponse(revision *models.AdRevision) *contracts.AdRevision {
+	changes := make([]*contracts.FieldChange, 0, len(revision.Changes))
+	for _, change := range revision.Changes {
+		changes = append(changes, &contracts.FieldChange{Field: change.Field, Old: change.Old, New: change.New})
+	}
+	return &contracts.AdRevision{
+		AdId:         revision.AdID,
+		Number:       revision.Number,
+		Type:         adRevisionTypes[revision.Type],
+		AuthorId:     revision.AuthorID,
+		Date:         timestamppb.New(revision.Date),
+		Title:        revision.Title,
+		Text:         revision.Text,
+		Published:    revision.Published,
+		Changes:      changes,
+		RestoredFrom: revision.RestoredFrom,
+	}
+}
+
+func AdRevisionsToListResponse(revisions []*models.AdRevision) *contracts.ListAdRevisionsResponse {
+	list := make([]*contracts.AdRevision, 0, len(revisions))
+	for _, revision := range revisions {
+		list = append(list, AdRevisionToResponse(revision))
+	}
+	return &contracts.ListAdRevisionsResponse{List: list}
+}
@@ -0,0 +1,48 @@
+package mapper
+
+import (
+	"github.com/stretchr/testify/require"
+	contracts "homework10/internal/api/handlers/grpc/contracts/langs/go"
+	"homework10/internal/domain/models"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestAdRevisionsToListResponse(t *testing.T) {
+	date := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		revisions []*models.AdRevision
+		expected  *contracts.ListAdRevisionsResponse
+	}{
+		{
+			name: "successfully map restored revision",
+			revisions: []*models.AdRevision{{
+				AdID: 1, Number: 3, Type: models.RevisionRestored, AuthorID: 2, Date: date,
+				Title: "title", Text: "text", RestoredFrom: 1,
+				Changes: []models.FieldChange{{Field: "title", Old: "new title", New: "title"}},
+			}},
+			expected: &contracts.ListAdRevisionsResponse{List: []*contracts.AdRevision{{
+				AdId: 1, Number: 3, Type: contracts.AdRevisionType_AD_REVISION_TYPE_RESTORED, AuthorId: 2,
+				Date: timestamppb.New(date), Title: "title", Text: "text", RestoredFrom: 1,
+				Changes: []*contracts.FieldChange{{Field: "title", Old: "new title", New: "title"}},
+			}}},
+		},
+		{
+			name:      "empty history",
+			revisions: []*models.AdRevision{},
+			expected:  &contracts.ListAdRevisionsResponse{List: []*contracts.AdRevision{}},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			actual := AdRevisionsToListResponse(tc.revisions)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
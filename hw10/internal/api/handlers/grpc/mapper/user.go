@@ -10,5 +10,22 @@ func UserToResponse(user *models.User) *contracts.UserResponse {
 		UserId:   user.ID,
 		Nickname: user.NickName,
 		Email:    user.Email,
+		Role:     userRoles[user.Role],
 	}
 }
+
+// RoleFromContract роль из запроса; USER_ROLE_UNSPECIFIED даёт пустую роль, которую отклонит сервис
+func RoleFromContract(role contracts.UserRole) models.Role {
+	for modelRole, contractRole := range userRoles {
+		if contractRole == role {
+			return modelRole
+		}
+	}
+	return ""
+}
+
+var userRoles = map[models.Role]contracts.UserRole{
+	models.RoleUser:      contracts.UserRole_USER_ROLE_USER,
+	models.RoleModerator: contracts.UserRole_USER_ROLE_MODERATOR,
+	models.RoleAdmin:     contracts.UserRole_USER_ROLE_ADMIN,
+}
@@ -26,6 +26,21 @@ func TestUserToResponse(t *testing.T) {
 				Email:    "test_user@gmail.com",
 			},
 		},
+		{
+			name: "map moderator role",
+			user: &models.User{
+				ID:       1,
+				NickName: "moderator",
+				Email:    "moderator@gmail.com",
+				Role:     models.RoleModerator,
+			},
+			expected: &contracts.UserResponse{
+				UserId:   1,
+				Nickname: "moderator",
+				Email:    "moderator@gmail.com",
+				Role:     contracts.UserRole_USER_ROLE_MODERATOR,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -35,3 +50,22 @@ func TestUserToResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestRoleFromContract(t *testing.T) {
+	tests := []struct {
+		role     contracts.UserRole
+		expected models.Role
+	}{
+		{role: contracts.UserRole_USER_ROLE_USER, expected: models.RoleUser},
+		{role: contracts.UserRole_USER_ROLE_MODERATOR, expected: models.RoleModerator},
+		{role: contracts.UserRole_USER_ROLE_ADMIN, expected: models.RoleAdmin},
+		{role: contracts.UserRole_USER_ROLE_UNSPECIFIED, expected: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.role.String(), func(t *testing.T) {
+			if got := RoleFromContract(tt.role); got != tt.expected {
+				t.Errorf("RoleFromContract() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
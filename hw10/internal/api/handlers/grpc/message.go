@@ -0,0 +1,57 @@
+package grpc
+
+import (
+	"context"
+	contracts "homework10/internal/api/handlers/grpc/contracts/langs/go"
+	"homework10/internal/api/handlers/grpc/mapper"
+	"homework10/internal/domain/models"
+)
+
+type MessageService interface {
+	SendMessage(ctx context.Context, adID int64, conversationID int64, text string) (*models.Message, error)
+	ListConversations(ctx context.Context, userID int64) (*models.Inbox, error)
+	ListMessages(ctx context.Context, userID int64, conversationID int64) ([]*models.Message, error)
+	MarkConversationRead(ctx context.Context, userID int64, conversationID int64) (int, error)
+}
+
+type MessageHandler struct {
+	messageService MessageService
+}
+
+func NewMessageHandler(messageServ MessageService) *MessageHandler {
+	return &MessageHandler{
+		messageService: messageServ,
+	}
+}
+
+func (h *MessageHandler) SendMessage(ctx context.Context, request *contracts.SendMessageRequest) (*contracts.Message, error) {
+	message, err := h.messageService.SendMessage(ctx, request.AdId, request.ConversationId, request.Text)
+	if err != nil {
+		return nil, err
+	}
+	return mapper.MessageToResponse(message), nil
+}
+
+func (h *MessageHandler) ListConversations(ctx context.Context, request *contracts.ListConversationsRequest) (*contracts.ListConversationsResponse, error) {
+	inbox, err := h.messageService.ListConversations(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+	return mapper.InboxToListResponse(inbox), nil
+}
+
+func (h *MessageHandler) ListMessages(ctx context.Context, request *contracts.ListMessagesRequest) (*contracts.ListMessagesResponse, error) {
+	messages, err := h.messageService.ListMessages(ctx, request.UserId, request.ConversationId)
+	if err != nil {
+		return nil, err
+	}
+	return mapper.MessagesToListResponse(messages), nil
+}
+
+func (h *MessageHandler) MarkConversationRead(ctx context.Context, request *contracts.MarkConversationReadRequest) (*contracts.MarkConversationReadResponse, error) {
+	marked, err := h.messageService.MarkConversationRead(ctx, request.UserId, request.ConversationId)
+	if err != nil {
+		return nil, err
+	}
+	return &contracts.MarkConversationReadResponse{Marked: int64(marked)}, nil
+}
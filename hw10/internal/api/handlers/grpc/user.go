@@ -9,10 +9,16 @@ import (
 )
 
 type UserService interface {
-	CreateUser(ctx context.Context, nickName string, email string) (*models.User, error)
+	CreateUser(ctx context.Context, nickName string, email string, password string) (*models.User, error)
 	UpdateUser(ctx context.Context, userID int64, nickName string, email string) (*models.User, error)
+	ChangePassword(ctx context.Context, userID int64, oldPassword string, newPassword string) error
+	SetUserRole(ctx context.Context, userID int64, role models.Role) (*models.User, error)
 	GetUser(ctx context.Context, userID int64) (*models.User, error)
 	DeleteUser(ctx context.Context, userID int64) error
+	RestoreUser(ctx context.Context, userID int64, password string) (*models.User, error)
+	AddFavorite(ctx context.Context, userID int64, adID int64) (*models.Ad, error)
+	RemoveFavorite(ctx context.Context, userID int64, adID int64) error
+	ListFavorites(ctx context.Context, userID int64) ([]*models.FavoriteAd, error)
 }
 
 type UserHandler struct {
@@ -26,7 +32,7 @@ func NewUserHandler(userServ UserService) *UserHandler {
 }
 
 func (h *UserHandler) CreateUser(ctx context.Context, request *contracts.CreateUserRequest) (*contracts.UserResponse, error) {
-	user, err := h.userService.CreateUser(ctx, request.Nickname, request.Email)
+	user, err := h.userService.CreateUser(ctx, request.Nickname, request.Email, request.Password)
 	if err != nil {
 		return nil, err
 	}
@@ -56,3 +62,51 @@ func (h *UserHandler) DeleteUser(ctx context.Context, request *contracts.DeleteU
 	}
 	return &emptypb.Empty{}, nil
 }
+
+func (h *UserHandler) ChangePassword(ctx context.Context, request *contracts.ChangePasswordRequest) (*emptypb.Empty, error) {
+	err := h.userService.ChangePassword(ctx, request.UserId, request.OldPassword, request.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (h *UserHandler) SetUserRole(ctx context.Context, request *contracts.SetUserRoleRequest) (*contracts.UserResponse, error) {
+	user, err := h.userService.SetUserRole(ctx, request.UserId, mapper.RoleFromContract(request.Role))
+	if err != nil {
+		return nil, err
+	}
+	return mapper.UserToResponse(user), nil
+}
+
+func (h *UserHandler) RestoreUser(ctx context.Context, request *contracts.RestoreUserRequest) (*contracts.UserResponse, error) {
+	user, err := h.userService.RestoreUser(ctx, request.UserId, request.Password)
+	if err != nil {
+		return nil, err
+	}
+	return mapper.UserToResponse(user), nil
+}
+
+func (h *UserHandler) AddFavorite(ctx context.Context, request *contracts.FavoriteRequest) (*contracts.AdResponse, error) {
+	ad, err := h.userService.AddFavorite(ctx, request.UserId, request.AdId)
+	if err != nil {
+		return nil, err
+	}
+	return mapper.AdToResponse(ad), nil
+}
+
+func (h *UserHandler) RemoveFavorite(ctx context.Context, request *contracts.FavoriteRequest) (*emptypb.Empty, error) {
+	err := h.userService.RemoveFavorite(ctx, request.UserId, request.AdId)
+	if err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (h *UserHandler) ListFavorites(ctx context.Context, request *contracts.ListFavoritesRequest) (*contracts.ListFavoritesResponse, error) {
+	favorites, err := h.userService.ListFavorites(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+	return mapper.FavoritesToListResponse(favorites), nil
+}
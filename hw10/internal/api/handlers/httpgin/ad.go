@@ -2,51 +2,87 @@ package httpgin
 
 import (
 	"context"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	"homework10/internal/api/handlers/httpgin/mapper"
-	"homework10/internal/api/handlers/httpgin/middlewares"
 	"homework10/internal/api/handlers/httpgin/request"
+	"homework10/internal/api/handlers/params"
 	"homework10/internal/domain/models"
-	"homework10/internal/service"
+	"homework10/internal/events"
 
+	"github.com/gin-contrib/sse"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
-	"github.com/ilgizjan1/publication"
 )
 
 //go:generate mockgen -source=./ad.go -destination=../mock/ad.go -package=handlermock AdService
 type AdService interface {
 	GetAdByID(ctx context.Context, adID int64) (*models.Ad, error)
-	CreateAd(ctx context.Context, title string, text string, authorID int64) (*models.Ad, error)
-	ChangeAdStatus(ctx context.Context, adID int64, userID int64, published bool) (*models.Ad, error)
-	UpdateAd(ctx context.Context, adID int64, userID int64, title string, text string) (*models.Ad, error)
-	DeleteAd(ctx context.Context, adID int64, userID int64) error
-	GetAdsByTitle(ctx context.Context, text string) ([]*models.Ad, error)
-	ListAds(ctx context.Context, published string, userIDRaw string, dateCreationRaw string) ([]*models.Ad, error)
+	CreateAd(ctx context.Context, title string, text string, details models.AdDetails) (*models.Ad, error)
+	ChangeAdStatus(ctx context.Context, adID int64, published bool, expectedVersion int64) (*models.Ad, error)
+	UpdateAd(ctx context.Context, adID int64, title string, text string, details models.AdDetails,
+		expectedVersion int64) (*models.Ad, error)
+	DeleteAd(ctx context.Context, adID int64) error
+	SearchAds(ctx context.Context, query string, page models.PageParams) (*models.SearchPage, error)
+	ListAds(ctx context.Context, filter models.AdFilter, page models.PageParams) (*models.AdsPage, error)
+	WatchAds(ctx context.Context, filter models.AdFilter) *events.Subscription
+	WatchAdsSince(ctx context.Context, filter models.AdFilter, lastEventID int64) (*events.Subscription, bool)
+	ListAdRevisions(ctx context.Context, adID int64) ([]*models.AdRevision, error)
+	RestoreAdRevision(ctx context.Context, adID int64, number int64) (*models.Ad, error)
+	RestoreAd(ctx context.Context, adID int64) (*models.Ad, error)
+	SubmitAd(ctx context.Context, adID int64, expectedVersion int64) (*models.Ad, error)
+	ApproveAd(ctx context.Context, adID int64, expectedVersion int64) (*models.Ad, error)
+	RejectAd(ctx context.Context, adID int64, reason string, expectedVersion int64) (*models.Ad, error)
+	ListModerationQueue(ctx context.Context) ([]*models.Ad, error)
+	UploadAdImage(ctx context.Context, adID int64, r io.Reader, expectedVersion int64) (*models.Ad, error)
+	OpenAdImage(ctx context.Context, adID int64, imageID int64, thumbnail bool) (*models.AdImage, io.ReadCloser, error)
+	ListCategories(ctx context.Context) ([]models.Category, error)
 }
 
+// maxUploadBody ограничение тела запроса с изображением; точный лимит размера файла проверяет сервис
+const maxUploadBody = 32 << 20
+
+// streamKeepAlive как часто поток событий отправляет комментарий, чтобы прокси не закрывали простаивающее соединение
+const streamKeepAlive = 15 * time.Second
+
 type AdHandler struct {
-	service      AdService
-	userIdentity middlewares.UserIdentity
+	service AdService
 }
 
-func NewAdHandler(service AdService, userIdentity middlewares.UserIdentity) *AdHandler {
+func NewAdHandler(service AdService) *AdHandler {
 	return &AdHandler{
-		service:      service,
-		userIdentity: userIdentity,
+		service: service,
 	}
 }
 
 func (h *AdHandler) AddRoutes(rg *gin.RouterGroup) {
-	rg.GET("/:ad_id", h.getAd)                                                          // Метод для получения объявления (ad) по ID (ad_id)
-	rg.POST("/", h.userIdentity.UserIdentityMiddleware(), h.createAd)                   // Метод для создания объявления (ad)
-	rg.PUT("/:ad_id/status", h.userIdentity.UserIdentityMiddleware(), h.changeAdStatus) // Метод для изменения статуса объявления (опубликовано - Published = true или снято с публикации Published = false)
-	rg.PUT("/:ad_id", h.userIdentity.UserIdentityMiddleware(), h.updateAd)              // Метод для обновления текста(Text) или заголовка(Title) объявления
-	rg.DELETE("/:ad_id", h.userIdentity.UserIdentityMiddleware(), h.deleteAd)           // Метод для удаления объявления (ad) по ID (ad_id)
-	rg.GET("/search", h.searchAds)                                                      // Метод для поиска объявлений по названию (title = "...")
-	rg.GET("/", h.listAds)                                                              // Метод для получение списка объявлений с фильтрами
+	rg.GET("/:ad_id", h.getAd)                 // Метод для получения объявления (ad) по ID (ad_id)
+	rg.POST("/", h.createAd)                   // Метод для создания объявления (ad)
+	rg.PUT("/:ad_id/status", h.changeAdStatus) // Метод для изменения статуса объявления (опубликовано - Published = true или снято с публикации Published = false)
+	rg.PUT("/:ad_id", h.updateAd)              // Метод для обновления текста(Text) или заголовка(Title) объявления
+	rg.DELETE("/:ad_id", h.deleteAd)           // Метод для удаления объявления (ad) по ID (ad_id)
+	rg.GET("/search", h.searchAds)             // Метод для полнотекстового поиска объявлений (text = "...") с постраничной выдачей
+	rg.GET("/", h.listAds)                     // Метод для получение списка объявлений с фильтрами, сортировкой и постраничной выдачей
+	rg.GET("/stream", h.streamAds)             // Метод для получения изменений объявлений в виде Server-Sent Events
+	rg.GET("/categories", h.listCategories)    // Метод для получения дерева категорий объявлений
+
+	rg.GET("/:ad_id/revisions", h.listAdRevisions)                 // Метод для получения истории изменений объявления
+	rg.POST("/:ad_id/revisions/:rev/restore", h.restoreAdRevision) // Метод для восстановления заголовка и текста объявления из ревизии (rev)
+
+	rg.POST("/:ad_id/restore", h.restoreAd) // Метод для возврата объявления из корзины
+
+	rg.POST("/:ad_id/submit", h.submitAd)    // Метод для отправки объявления на проверку модератору
+	rg.POST("/:ad_id/approve", h.approveAd)  // Метод для одобрения объявления модератором
+	rg.POST("/:ad_id/reject", h.rejectAd)    // Метод для отклонения объявления модератором с причиной (reason)
+	rg.GET("/moderation", h.moderationQueue) // Метод для получения объявлений, ожидающих проверки
+
+	rg.POST("/:ad_id/images", h.uploadAdImage)                          // Метод для загрузки изображения (поле image в multipart/form-data)
+	rg.GET("/:ad_id/images/:image_id", h.getAdImage)                    // Метод для скачивания изображения объявления
+	rg.GET("/:ad_id/images/:image_id/thumbnail", h.getAdImageThumbnail) // Метод для скачивания миниатюры изображения
 }
 
 func (h *AdHandler) BasePrefix() string {
@@ -63,10 +99,10 @@ func (h *AdHandler) getAd(ctx *gin.Context) {
 	}
 	ad, err := h.service.GetAdByID(ctx, int64(adID))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, NewErrResponse(err))
+		errorResponse(ctx, err)
 		return
 	}
-	ctx.IndentedJSON(http.StatusOK, mapper.AdSuccessResponse(ad))
+	adResponse(ctx, ad)
 }
 
 // Метод для создания объявления (ad)
@@ -76,18 +112,12 @@ func (h *AdHandler) createAd(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
 		return
 	}
-	ad, err := h.service.CreateAd(ctx, reqBody.Title, reqBody.Text, reqBody.UserID)
+	ad, err := h.service.CreateAd(ctx, reqBody.Title, reqBody.Text, mapper.AdDetailsFromRequest(reqBody.AdDetailsRequest))
 	if err != nil {
-		switch err.(type) {
-		case publication.ValidationErrors:
-			ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
-			return
-		default:
-			ctx.JSON(http.StatusInternalServerError, NewErrResponse(err))
-			return
-		}
+		errorResponse(ctx, err)
+		return
 	}
-	ctx.IndentedJSON(http.StatusOK, mapper.AdSuccessResponse(ad))
+	adResponse(ctx, ad)
 }
 
 // Метод для изменения статуса объявления
@@ -103,21 +133,20 @@ func (h *AdHandler) changeAdStatus(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
 		return
 	}
-	ad, err := h.service.ChangeAdStatus(ctx, int64(adID), reqBody.UserID, reqBody.Published)
+	expectedVersion, err := ifMatchVersion(ctx)
 	if err != nil {
-		switch err.(type) {
-		case service.ErrNoAccess:
-			ctx.JSON(http.StatusForbidden, NewErrResponse(err))
-			return
-		default:
-			ctx.JSON(http.StatusInternalServerError, NewErrResponse(err))
-			return
-		}
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	ad, err := h.service.ChangeAdStatus(ctx, int64(adID), reqBody.Published, expectedVersion)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
 	}
-	ctx.IndentedJSON(http.StatusOK, mapper.AdSuccessResponse(ad))
+	adResponse(ctx, ad)
 }
 
-// Метод для обновления текста(Text) или заголовка(Title) объявления
+// Метод для обновления текста(Text), заголовка(Title) и характеристик (цена, категория, город) объявления
 func (h *AdHandler) updateAd(ctx *gin.Context) {
 	var reqBody request.UpdateAdRequest
 	if err := ctx.ShouldBindBodyWith(&reqBody, binding.JSON); err != nil {
@@ -130,71 +159,346 @@ func (h *AdHandler) updateAd(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
 		return
 	}
-	ad, err := h.service.UpdateAd(ctx, int64(adID), reqBody.UserID, reqBody.Title, reqBody.Text)
+	expectedVersion, err := ifMatchVersion(ctx)
 	if err != nil {
-		switch err.(type) {
-		case service.ErrNoAccess:
-			ctx.JSON(http.StatusForbidden, NewErrResponse(err))
-			return
-		case publication.ValidationErrors:
-			ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
-			return
-		default:
-			ctx.JSON(http.StatusInternalServerError, NewErrResponse(err))
-			return
-		}
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	ad, err := h.service.UpdateAd(ctx, int64(adID), reqBody.Title, reqBody.Text,
+		mapper.AdDetailsFromRequest(reqBody.AdDetailsRequest), expectedVersion)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
 	}
-	ctx.IndentedJSON(http.StatusOK, mapper.AdSuccessResponse(ad))
+	adResponse(ctx, ad)
 }
 
 // Метод для удаления объявления (ad)
 func (h *AdHandler) deleteAd(ctx *gin.Context) {
-	var reqBody request.DeleteAdRequest
-	if err := ctx.ShouldBindBodyWith(&reqBody, binding.JSON); err != nil {
-		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
-		return
-	}
 	adIDRaw := ctx.Param("ad_id")
 	adID, err := strconv.Atoi(adIDRaw)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
 		return
 	}
-	err = h.service.DeleteAd(ctx, int64(adID), reqBody.UserID)
+	err = h.service.DeleteAd(ctx, int64(adID))
 	if err != nil {
-		switch err.(type) {
-		case service.ErrNoAccess:
-			ctx.JSON(http.StatusForbidden, NewErrResponse(err))
-			return
-		default:
-			ctx.JSON(http.StatusInternalServerError, NewErrResponse(err))
-			return
-		}
+		errorResponse(ctx, err)
+		return
 	}
 	//ctx.IndentedJSON(http.StatusOK, AdSuccessResponse(ad))
 	ctx.IndentedJSON(http.StatusOK, gin.H{"success": "User #" + adIDRaw + " deleted"})
 }
 
-// Метод для поиска объявлений (ads) по названию (title)
+// Метод для полнотекстового поиска объявлений (ads) по заголовку (title) и тексту (text)
 func (h *AdHandler) searchAds(ctx *gin.Context) {
 	text := ctx.Query("text")
-	adList, err := h.service.GetAdsByTitle(ctx, text)
+	page, err := pageParams(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	searchPage, err := h.service.SearchAds(ctx, text, page)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, NewErrResponse(err))
+		errorResponse(ctx, err)
 		return
 	}
-	ctx.IndentedJSON(http.StatusOK, mapper.AdsSuccessResponse(adList))
+	ctx.IndentedJSON(http.StatusOK, mapper.SearchPageSuccessResponse(searchPage))
 }
 
 // Метод для получения объявлений (ads) с возможностью фильтрации
 func (h *AdHandler) listAds(ctx *gin.Context) {
-	published := ctx.Query("published")
-	userID := ctx.Query("user_id")
-	dateCreation := ctx.Query("date")
-	adSlice, err := h.service.ListAds(ctx, published, userID, dateCreation)
+	filter, err := params.ParseAdFilter(ctx.Query("published"), ctx.QueryArray("user_id"), ctx.Query("date"))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, NewErrResponse(err))
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
 		return
 	}
-	ctx.IndentedJSON(http.StatusOK, mapper.AdsSuccessResponse(adSlice))
+	filter, err = params.ParseDateRanges(filter, ctx.Query("created_from"), ctx.Query("created_to"), ctx.Query("updated_since"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	filter, err = params.ParseCatalogFilter(filter, ctx.Query("price_min"), ctx.Query("price_max"), ctx.Query("currency"),
+		ctx.QueryArray("category"), ctx.Query("city"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	page, err := pageParams(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	adPage, err := h.service.ListAds(ctx, filter, page)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, mapper.AdsPageSuccessResponse(adPage))
+}
+
+// Метод для получения дерева категорий: родительская категория идёт в списке раньше своих подкатегорий
+func (h *AdHandler) listCategories(ctx *gin.Context) {
+	categories, err := h.service.ListCategories(ctx)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, mapper.CategoriesSuccessResponse(categories))
+}
+
+// Метод для получения изменений объявлений (ads) потоком Server-Sent Events. Номер события передаётся в id:
+// переподключившийся клиент присылает последний полученный номер в заголовке Last-Event-ID и получает
+// пропущенные события. Если часть из них уже не хранится, первым приходит событие reset
+func (h *AdHandler) streamAds(ctx *gin.Context) {
+	filter, err := params.ParseAdFilter(ctx.Query("published"), ctx.QueryArray("user_id"), "")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+
+	// подписка живёт не дольше запроса, а не *gin.Context, который переиспользуется после ответа
+	var sub *events.Subscription
+	complete := true
+	if lastEventIDRaw := ctx.GetHeader("Last-Event-ID"); lastEventIDRaw != "" {
+		lastEventID, err := strconv.ParseInt(lastEventIDRaw, 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+			return
+		}
+		sub, complete = h.service.WatchAdsSince(ctx.Request.Context(), filter, lastEventID)
+	} else {
+		sub = h.service.WatchAds(ctx.Request.Context(), filter)
+	}
+	defer sub.Close()
+
+	// заголовки отправляются сразу: после их получения клиент не пропустит ни одного события
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	if !complete {
+		ctx.Render(-1, sse.Event{Event: "reset", Data: ""})
+	}
+	ctx.Writer.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+	// канал подписки закрывается, когда клиент отключается
+	for {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			ctx.Render(-1, sse.Event{
+				Id:    strconv.FormatInt(event.ID, 10),
+				Event: mapper.AdEventName(event),
+				Data:  mapper.AdToResponse(&event.Ad),
+			})
+		case <-keepAlive.C:
+			_, _ = io.WriteString(ctx.Writer, ": keep-alive\n\n")
+		}
+		ctx.Writer.Flush()
+	}
+}
+
+// Параметры постраничной выдачи: limit, page_token, sort_by и order
+func pageParams(ctx *gin.Context) (models.PageParams, error) {
+	page := models.PageParams{
+		PageToken: ctx.Query("page_token"),
+		SortBy:    models.AdSortField(ctx.Query("sort_by")),
+		Order:     models.SortOrder(ctx.Query("order")),
+	}
+	if limitRaw := ctx.Query("limit"); limitRaw != "" {
+		limit, err := strconv.Atoi(limitRaw)
+		if err != nil {
+			return page, err
+		}
+		page.Limit = limit
+	}
+	return page, nil
+}
+
+// Метод для получения истории изменений объявления
+func (h *AdHandler) listAdRevisions(ctx *gin.Context) {
+	adID, err := strconv.Atoi(ctx.Param("ad_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	revisions, err := h.service.ListAdRevisions(ctx, int64(adID))
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, mapper.AdRevisionsSuccessResponse(revisions))
+}
+
+// Метод для восстановления объявления из ревизии
+func (h *AdHandler) restoreAdRevision(ctx *gin.Context) {
+	adID, err := strconv.Atoi(ctx.Param("ad_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	number, err := strconv.ParseInt(ctx.Param("rev"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	ad, err := h.service.RestoreAdRevision(ctx, int64(adID), number)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	adResponse(ctx, ad)
+}
+
+// Метод для возврата удалённого объявления из корзины
+func (h *AdHandler) restoreAd(ctx *gin.Context) {
+	adID, err := strconv.Atoi(ctx.Param("ad_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	ad, err := h.service.RestoreAd(ctx, int64(adID))
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	adResponse(ctx, ad)
+}
+
+// Метод для отправки объявления на проверку
+func (h *AdHandler) submitAd(ctx *gin.Context) {
+	h.moderateAd(ctx, func(adID int64, expectedVersion int64) (*models.Ad, error) {
+		return h.service.SubmitAd(ctx, adID, expectedVersion)
+	})
+}
+
+// Метод для одобрения объявления
+func (h *AdHandler) approveAd(ctx *gin.Context) {
+	h.moderateAd(ctx, func(adID int64, expectedVersion int64) (*models.Ad, error) {
+		return h.service.ApproveAd(ctx, adID, expectedVersion)
+	})
+}
+
+// Метод для отклонения объявления
+func (h *AdHandler) rejectAd(ctx *gin.Context) {
+	var reqBody request.RejectAdRequest
+	if err := ctx.ShouldBindBodyWith(&reqBody, binding.JSON); err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	h.moderateAd(ctx, func(adID int64, expectedVersion int64) (*models.Ad, error) {
+		return h.service.RejectAd(ctx, adID, reqBody.Reason, expectedVersion)
+	})
+}
+
+// moderateAd разбирает ID объявления и If-Match и применяет к объявлению переход модерации
+func (h *AdHandler) moderateAd(ctx *gin.Context, transition func(adID int64, expectedVersion int64) (*models.Ad, error)) {
+	adID, err := strconv.Atoi(ctx.Param("ad_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	expectedVersion, err := ifMatchVersion(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	ad, err := transition(int64(adID), expectedVersion)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	adResponse(ctx, ad)
+}
+
+// Метод для получения очереди модерации
+func (h *AdHandler) moderationQueue(ctx *gin.Context) {
+	ads, err := h.service.ListModerationQueue(ctx)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, mapper.AdsSuccessResponse(ads))
+}
+
+// Метод для загрузки изображения объявления
+func (h *AdHandler) uploadAdImage(ctx *gin.Context) {
+	adID, err := strconv.Atoi(ctx.Param("ad_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	expectedVersion, err := ifMatchVersion(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxUploadBody)
+	fileHeader, err := ctx.FormFile("image")
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		ctx.JSON(http.StatusRequestEntityTooLarge, NewErrResponse(err))
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	defer file.Close()
+
+	ad, err := h.service.UploadAdImage(ctx, int64(adID), file, expectedVersion)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	adResponse(ctx, ad)
+}
+
+// Метод для скачивания изображения объявления
+func (h *AdHandler) getAdImage(ctx *gin.Context) {
+	h.sendAdImage(ctx, false)
+}
+
+// Метод для скачивания миниатюры изображения объявления
+func (h *AdHandler) getAdImageThumbnail(ctx *gin.Context) {
+	h.sendAdImage(ctx, true)
+}
+
+// sendAdImage отдаёт файл изображения; изображения не меняются, поэтому их можно долго кешировать
+func (h *AdHandler) sendAdImage(ctx *gin.Context, thumbnail bool) {
+	adID, err := strconv.Atoi(ctx.Param("ad_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	imageID, err := strconv.Atoi(ctx.Param("image_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	image, r, err := h.service.OpenAdImage(ctx, int64(adID), int64(imageID), thumbnail)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	defer r.Close()
+
+	contentType, size := image.ContentType, image.Size
+	if thumbnail {
+		contentType, size = "image/jpeg", -1
+	}
+	ctx.DataFromReader(http.StatusOK, size, contentType, r, map[string]string{
+		"Cache-Control":          "public, max-age=86400",
+		"X-Content-Type-Options": "nosniff",
+	})
 }
@@ -2,19 +2,22 @@ package httpgin
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
-	"github.com/ilgizjan1/publication"
 	"github.com/stretchr/testify/require"
 	handlerMock "homework10/internal/api/handlers/httpgin/mock"
 	"homework10/internal/api/handlers/httpgin/request"
+	"homework10/internal/domain"
 	"homework10/internal/domain/models"
+	"homework10/internal/events"
 	"homework10/internal/service"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestUserHandler_getAd(t *testing.T) {
@@ -50,8 +53,10 @@ func TestUserHandler_getAd(t *testing.T) {
 						"text": "test text",
 						"user_id": 0,
 						"published": true,
-						"date_creation": "",
-						"date_update": ""
+						"state": "",
+						"images": [],
+						"date_creation": "0001-01-01T00:00:00Z",
+						"date_update": "0001-01-01T00:00:00Z"
 					}
 				}
 				`,
@@ -63,6 +68,15 @@ func TestUserHandler_getAd(t *testing.T) {
 			expectedStatusCode: http.StatusBadRequest,
 			expectedResponse:   `{"error": "strconv.Atoi: parsing \"invalid_user_id_1\": invalid syntax"}`,
 		},
+		{
+			name: "error from service: ErrAdNotExist",
+			adID: "5",
+			mockBehaviour: func(service *handlerMock.MockAdService) {
+				service.EXPECT().GetAdByID(gomock.Any(), int64(5)).Return(nil, domain.ErrAdNotExist)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse:   `{"error": "the ad does not exist"}`,
+		},
 		{
 			name: "error from service",
 			adID: "0",
@@ -84,7 +98,7 @@ func TestUserHandler_getAd(t *testing.T) {
 			service := handlerMock.NewMockAdService(ctrl)
 			tc.mockBehaviour(service)
 
-			handler := NewAdHandler(service, nil)
+			handler := NewAdHandler(service)
 
 			//Test Server
 			rg := gin.New()
@@ -116,13 +130,12 @@ func TestUserHandler_createAd(t *testing.T) {
 		{
 			name: "successfully get user",
 			ad: request.CreateAdRequest{
-				Title:  "test title",
-				Text:   "test text",
-				UserID: 0,
+				Title: "test title",
+				Text:  "test text",
 			},
 			mockBehaviour: func(service *handlerMock.MockAdService) {
 				service.EXPECT().
-					CreateAd(gomock.Any(), "test title", "test text", int64(0)).
+					CreateAd(gomock.Any(), "test title", "test text", models.AdDetails{}).
 					Return(
 						&models.Ad{
 							ID:        0,
@@ -142,8 +155,10 @@ func TestUserHandler_createAd(t *testing.T) {
 						"text": "test text",
 						"user_id": 0,
 						"published": true,
-						"date_creation": "",
-						"date_update": ""
+						"state": "",
+						"images": [],
+						"date_creation": "0001-01-01T00:00:00Z",
+						"date_update": "0001-01-01T00:00:00Z"
 					}
 				}
 				`,
@@ -162,26 +177,24 @@ func TestUserHandler_createAd(t *testing.T) {
 		{
 			name: "error from service: Validation",
 			ad: request.CreateAdRequest{
-				Title:  "",
-				Text:   "test text",
-				UserID: 0,
+				Title: "",
+				Text:  "test text",
 			},
 			mockBehaviour: func(service *handlerMock.MockAdService) {
-				service.EXPECT().CreateAd(gomock.Any(), "", "test text", int64(0)).
-					Return(nil, publication.ValidationErrors{publication.ValidationError{Err: publication.ErrInvalidTitle}})
+				service.EXPECT().CreateAd(gomock.Any(), "", "test text", models.AdDetails{}).
+					Return(nil, domain.ValidationErrors{{Field: "Title", Rule: "required", Message: "wrong title"}})
 			},
 			expectedStatusCode: http.StatusBadRequest,
-			expectedResponse:   `{"error": "wrong title"}`,
+			expectedResponse:   `{"errors": [{"field": "Title", "rule": "required", "message": "wrong title"}]}`,
 		},
 		{
 			name: "error from service",
 			ad: request.CreateAdRequest{
-				Title:  "test title",
-				Text:   "test text",
-				UserID: 0,
+				Title: "test title",
+				Text:  "test text",
 			},
 			mockBehaviour: func(service *handlerMock.MockAdService) {
-				service.EXPECT().CreateAd(gomock.Any(), "test title", "test text", int64(0)).
+				service.EXPECT().CreateAd(gomock.Any(), "test title", "test text", models.AdDetails{}).
 					Return(nil, fmt.Errorf("error from service"))
 			},
 			expectedStatusCode: http.StatusInternalServerError,
@@ -199,7 +212,7 @@ func TestUserHandler_createAd(t *testing.T) {
 			service := handlerMock.NewMockAdService(ctrl)
 			tc.mockBehaviour(service)
 
-			handler := NewAdHandler(service, nil)
+			handler := NewAdHandler(service)
 
 			//Test Server
 			rg := gin.New()
@@ -235,12 +248,11 @@ func TestUserHandler_changeAdStatus(t *testing.T) {
 			name: "successfully get user",
 			adID: "0",
 			ad: request.ChangeAdStatusRequest{
-				UserID:    0,
 				Published: true,
 			},
 			mockBehaviour: func(service *handlerMock.MockAdService) {
 				service.EXPECT().
-					ChangeAdStatus(gomock.Any(), int64(0), int64(0), true).
+					ChangeAdStatus(gomock.Any(), int64(0), true, int64(0)).
 					Return(
 						&models.Ad{
 							ID:        0,
@@ -260,8 +272,10 @@ func TestUserHandler_changeAdStatus(t *testing.T) {
 						"text": "test text",
 						"user_id": 0,
 						"published": true,
-						"date_creation": "",
-						"date_update": ""
+						"state": "",
+						"images": [],
+						"date_creation": "0001-01-01T00:00:00Z",
+						"date_update": "0001-01-01T00:00:00Z"
 					}
 				}
 				`,
@@ -270,13 +284,13 @@ func TestUserHandler_changeAdStatus(t *testing.T) {
 			name: "invalid json body passed",
 			adID: "0",
 			ad: struct {
-				UserID bool `json:"user_id"`
+				Published string `json:"published"`
 			}{
-				UserID: true,
+				Published: "yes",
 			},
 			mockBehaviour:      func(service *handlerMock.MockAdService) {},
 			expectedStatusCode: http.StatusBadRequest,
-			expectedResponse:   `{"error": "json: cannot unmarshal bool into Go struct field ChangeAdStatusRequest.user_id of type int64"}`,
+			expectedResponse:   `{"error": "json: cannot unmarshal string into Go struct field ChangeAdStatusRequest.published of type bool"}`,
 		},
 		{
 			name:               "invalid user id passed",
@@ -289,11 +303,10 @@ func TestUserHandler_changeAdStatus(t *testing.T) {
 			name: "error from service: ErrNoAccess",
 			adID: "0",
 			ad: request.ChangeAdStatusRequest{
-				UserID:    0,
 				Published: true,
 			},
 			mockBehaviour: func(serv *handlerMock.MockAdService) {
-				serv.EXPECT().ChangeAdStatus(gomock.Any(), int64(0), int64(0), true).
+				serv.EXPECT().ChangeAdStatus(gomock.Any(), int64(0), true, int64(0)).
 					Return(nil, service.ErrNoAccess{Err: service.ErrNoAccessAd})
 			},
 			expectedStatusCode: http.StatusForbidden,
@@ -304,10 +317,9 @@ func TestUserHandler_changeAdStatus(t *testing.T) {
 			adID: "0",
 			ad: request.ChangeAdStatusRequest{
 				Published: true,
-				UserID:    0,
 			},
 			mockBehaviour: func(service *handlerMock.MockAdService) {
-				service.EXPECT().ChangeAdStatus(gomock.Any(), int64(0), int64(0), true).
+				service.EXPECT().ChangeAdStatus(gomock.Any(), int64(0), true, int64(0)).
 					Return(nil, fmt.Errorf("error from service"))
 			},
 			expectedStatusCode: http.StatusInternalServerError,
@@ -325,7 +337,7 @@ func TestUserHandler_changeAdStatus(t *testing.T) {
 			service := handlerMock.NewMockAdService(ctrl)
 			tc.mockBehaviour(service)
 
-			handler := NewAdHandler(service, nil)
+			handler := NewAdHandler(service)
 
 			//Test Server
 			rg := gin.New()
@@ -361,13 +373,12 @@ func TestUserHandler_updateAd(t *testing.T) {
 			name: "successfully get user",
 			adID: "0",
 			ad: request.UpdateAdRequest{
-				Title:  "test title",
-				Text:   "test text",
-				UserID: 0,
+				Title: "test title",
+				Text:  "test text",
 			},
 			mockBehaviour: func(service *handlerMock.MockAdService) {
 				service.EXPECT().
-					UpdateAd(gomock.Any(), int64(0), int64(0), "test title", "test text").
+					UpdateAd(gomock.Any(), int64(0), "test title", "test text", models.AdDetails{}, int64(0)).
 					Return(
 						&models.Ad{
 							ID:        0,
@@ -387,8 +398,10 @@ func TestUserHandler_updateAd(t *testing.T) {
 						"text": "test text",
 						"user_id": 0,
 						"published": true,
-						"date_creation": "",
-						"date_update": ""
+						"state": "",
+						"images": [],
+						"date_creation": "0001-01-01T00:00:00Z",
+						"date_update": "0001-01-01T00:00:00Z"
 					}
 				}
 				`,
@@ -397,13 +410,13 @@ func TestUserHandler_updateAd(t *testing.T) {
 			name: "invalid json body passed",
 			adID: "0",
 			ad: struct {
-				UserID bool `json:"user_id"`
+				Title bool `json:"title"`
 			}{
-				UserID: true,
+				Title: true,
 			},
 			mockBehaviour:      func(service *handlerMock.MockAdService) {},
 			expectedStatusCode: http.StatusBadRequest,
-			expectedResponse:   `{"error": "json: cannot unmarshal bool into Go struct field UpdateAdRequest.user_id of type int64"}`,
+			expectedResponse:   `{"error": "json: cannot unmarshal bool into Go struct field UpdateAdRequest.title of type string"}`,
 		},
 		{
 			name:               "invalid user id passed",
@@ -416,12 +429,11 @@ func TestUserHandler_updateAd(t *testing.T) {
 			name: "error from service: ErrNoAccess",
 			adID: "0",
 			ad: request.UpdateAdRequest{
-				Title:  "test title",
-				Text:   "test text",
-				UserID: 0,
+				Title: "test title",
+				Text:  "test text",
 			},
 			mockBehaviour: func(serv *handlerMock.MockAdService) {
-				serv.EXPECT().UpdateAd(gomock.Any(), int64(0), int64(0), "test title", "test text").
+				serv.EXPECT().UpdateAd(gomock.Any(), int64(0), "test title", "test text", models.AdDetails{}, int64(0)).
 					Return(nil, service.ErrNoAccess{Err: service.ErrNoAccessAd})
 			},
 			expectedStatusCode: http.StatusForbidden,
@@ -431,27 +443,25 @@ func TestUserHandler_updateAd(t *testing.T) {
 			name: "error from service: Validation",
 			adID: "0",
 			ad: request.UpdateAdRequest{
-				Title:  "",
-				Text:   "test text",
-				UserID: 0,
+				Title: "",
+				Text:  "test text",
 			},
 			mockBehaviour: func(service *handlerMock.MockAdService) {
-				service.EXPECT().UpdateAd(gomock.Any(), int64(0), int64(0), "", "test text").
-					Return(nil, publication.ValidationErrors{publication.ValidationError{Err: publication.ErrInvalidTitle}})
+				service.EXPECT().UpdateAd(gomock.Any(), int64(0), "", "test text", models.AdDetails{}, int64(0)).
+					Return(nil, domain.ValidationErrors{{Field: "Title", Rule: "required", Message: "wrong title"}})
 			},
 			expectedStatusCode: http.StatusBadRequest,
-			expectedResponse:   `{"error": "wrong title"}`,
+			expectedResponse:   `{"errors": [{"field": "Title", "rule": "required", "message": "wrong title"}]}`,
 		},
 		{
 			name: "error from service",
 			adID: "0",
 			ad: request.UpdateAdRequest{
-				Title:  "test title",
-				Text:   "test text",
-				UserID: 0,
+				Title: "test title",
+				Text:  "test text",
 			},
 			mockBehaviour: func(service *handlerMock.MockAdService) {
-				service.EXPECT().UpdateAd(gomock.Any(), int64(0), int64(0), "test title", "test text").
+				service.EXPECT().UpdateAd(gomock.Any(), int64(0), "test title", "test text", models.AdDetails{}, int64(0)).
 					Return(nil, fmt.Errorf("error from service"))
 			},
 			expectedStatusCode: http.StatusInternalServerError,
@@ -469,7 +479,7 @@ func TestUserHandler_updateAd(t *testing.T) {
 			service := handlerMock.NewMockAdService(ctrl)
 			tc.mockBehaviour(service)
 
-			handler := NewAdHandler(service, nil)
+			handler := NewAdHandler(service)
 
 			//Test Server
 			rg := gin.New()
@@ -496,7 +506,6 @@ func TestUserHandler_deleteAd(t *testing.T) {
 	tests := []struct {
 		name               string
 		adID               string
-		ad                 any
 		mockBehaviour      func(service *handlerMock.MockAdService)
 		expectedStatusCode int
 		expectedResponse   string
@@ -504,12 +513,9 @@ func TestUserHandler_deleteAd(t *testing.T) {
 		{
 			name: "successfully get user",
 			adID: "0",
-			ad: request.DeleteAdRequest{
-				UserID: 0,
-			},
 			mockBehaviour: func(service *handlerMock.MockAdService) {
 				service.EXPECT().
-					DeleteAd(gomock.Any(), int64(0), int64(0)).
+					DeleteAd(gomock.Any(), int64(0)).
 					Return(
 						nil,
 					).Times(1)
@@ -517,18 +523,6 @@ func TestUserHandler_deleteAd(t *testing.T) {
 			expectedStatusCode: http.StatusOK,
 			expectedResponse:   `{"success":"User #0 deleted"}`,
 		},
-		{
-			name: "invalid json body passed",
-			adID: "0",
-			ad: struct {
-				UserID bool `json:"user_id"`
-			}{
-				UserID: true,
-			},
-			mockBehaviour:      func(service *handlerMock.MockAdService) {},
-			expectedStatusCode: http.StatusBadRequest,
-			expectedResponse:   `{"error": "json: cannot unmarshal bool into Go struct field DeleteAdRequest.user_id of type int64"}`,
-		},
 		{
 			name:               "invalid user id passed",
 			adID:               "invalid_user_id_1",
@@ -539,11 +533,8 @@ func TestUserHandler_deleteAd(t *testing.T) {
 		{
 			name: "error from service: ErrNoAccess",
 			adID: "0",
-			ad: request.DeleteAdRequest{
-				UserID: 0,
-			},
 			mockBehaviour: func(serv *handlerMock.MockAdService) {
-				serv.EXPECT().DeleteAd(gomock.Any(), int64(0), int64(0)).
+				serv.EXPECT().DeleteAd(gomock.Any(), int64(0)).
 					Return(service.ErrNoAccess{Err: service.ErrNoAccessAd})
 			},
 			expectedStatusCode: http.StatusForbidden,
@@ -552,11 +543,8 @@ func TestUserHandler_deleteAd(t *testing.T) {
 		{
 			name: "error from service",
 			adID: "0",
-			ad: request.DeleteAdRequest{
-				UserID: 0,
-			},
 			mockBehaviour: func(service *handlerMock.MockAdService) {
-				service.EXPECT().DeleteAd(gomock.Any(), int64(0), int64(0)).
+				service.EXPECT().DeleteAd(gomock.Any(), int64(0)).
 					Return(fmt.Errorf("error from service"))
 			},
 			expectedStatusCode: http.StatusInternalServerError,
@@ -574,18 +562,15 @@ func TestUserHandler_deleteAd(t *testing.T) {
 			service := handlerMock.NewMockAdService(ctrl)
 			tc.mockBehaviour(service)
 
-			handler := NewAdHandler(service, nil)
+			handler := NewAdHandler(service)
 
 			//Test Server
 			rg := gin.New()
 			rg.DELETE("/:ad_id", handler.deleteAd)
 
-			jsonValue, err := json.Marshal(tc.ad)
-			require.Equal(t, err, nil)
-
 			//Test request
 			w := httptest.NewRecorder()
-			r := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/%s", tc.adID), bytes.NewBuffer(jsonValue))
+			r := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/%s", tc.adID), nil)
 
 			//Perform request
 			rg.ServeHTTP(w, r)
@@ -606,19 +591,25 @@ func TestUserHandler_searchAds(t *testing.T) {
 		expectedResponse   string
 	}{
 		{
-			name: "successfully get user",
+			name: "successfully search ads",
 			text: "test",
 			mockBehaviour: func(service *handlerMock.MockAdService) {
 				service.EXPECT().
-					GetAdsByTitle(gomock.Any(), "test").
+					SearchAds(gomock.Any(), "test", models.PageParams{}).
 					Return(
-						[]*models.Ad{
-							{
-								ID:        0,
-								Title:     "test title",
-								Text:      "test text",
-								UserID:    0,
-								Published: true,
+						&models.SearchPage{
+							Results: []*models.SearchResult{
+								{
+									Ad: &models.Ad{
+										ID:        0,
+										Title:     "test title",
+										Text:      "test text",
+										UserID:    0,
+										Published: true,
+									},
+									Score:   1.5,
+									Snippet: "<mark>test</mark> text",
+								},
 							},
 						},
 						nil,
@@ -634,18 +625,33 @@ func TestUserHandler_searchAds(t *testing.T) {
 							"text": "test text",
 							"user_id": 0,
 							"published": true,
-							"date_creation": "",
-							"date_update": ""
+							"state": "",
+							"images": [],
+							"date_creation": "0001-01-01T00:00:00Z",
+							"date_update": "0001-01-01T00:00:00Z",
+							"score": 1.5,
+							"snippet": "<mark>test</mark> text"
 						}
-					]
+					],
+					"next_page_token": ""
 				}
 				`,
 		},
+		{
+			name: "invalid page params from service",
+			text: "test",
+			mockBehaviour: func(serv *handlerMock.MockAdService) {
+				serv.EXPECT().SearchAds(gomock.Any(), "test", models.PageParams{}).
+					Return(nil, service.ErrInvalidPage{Err: service.ErrInvalidSearchSortField})
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   `{"error": "sort_by must be one of: relevance, id, date_creation, date_update, title"}`,
+		},
 		{
 			name: "error from service",
 			text: "test",
 			mockBehaviour: func(service *handlerMock.MockAdService) {
-				service.EXPECT().GetAdsByTitle(gomock.Any(), "test").
+				service.EXPECT().SearchAds(gomock.Any(), "test", models.PageParams{}).
 					Return(nil, fmt.Errorf("error from service"))
 			},
 			expectedStatusCode: http.StatusInternalServerError,
@@ -663,7 +669,7 @@ func TestUserHandler_searchAds(t *testing.T) {
 			service := handlerMock.NewMockAdService(ctrl)
 			tc.mockBehaviour(service)
 
-			handler := NewAdHandler(service, nil)
+			handler := NewAdHandler(service)
 
 			//Test Server
 			rg := gin.New()
@@ -696,15 +702,17 @@ func TestUserHandler_listAds(t *testing.T) {
 			text: "test",
 			mockBehaviour: func(service *handlerMock.MockAdService) {
 				service.EXPECT().
-					ListAds(gomock.Any(), "", "", "").
+					ListAds(gomock.Any(), models.AdFilter{}, models.PageParams{}).
 					Return(
-						[]*models.Ad{
-							{
-								ID:        0,
-								Title:     "test title",
-								Text:      "test text",
-								UserID:    0,
-								Published: true,
+						&models.AdsPage{
+							Ads: []*models.Ad{
+								{
+									ID:        0,
+									Title:     "test title",
+									Text:      "test text",
+									UserID:    0,
+									Published: true,
+								},
 							},
 						},
 						nil,
@@ -720,10 +728,14 @@ func TestUserHandler_listAds(t *testing.T) {
 							"text": "test text",
 							"user_id": 0,
 							"published": true,
-							"date_creation": "",
-							"date_update": ""
+							"state": "",
+							"images": [],
+							"date_creation": "0001-01-01T00:00:00Z",
+							"date_update": "0001-01-01T00:00:00Z"
 						}
-					]
+					],
+					"next_page_token": "",
+					"facets": {"categories": [], "cities": []}
 				}
 				`,
 		},
@@ -731,7 +743,7 @@ func TestUserHandler_listAds(t *testing.T) {
 			name: "error from service",
 			text: "test",
 			mockBehaviour: func(service *handlerMock.MockAdService) {
-				service.EXPECT().ListAds(gomock.Any(), "", "", "").
+				service.EXPECT().ListAds(gomock.Any(), models.AdFilter{}, models.PageParams{}).
 					Return(nil, fmt.Errorf("error from service"))
 			},
 			expectedStatusCode: http.StatusInternalServerError,
@@ -749,7 +761,7 @@ func TestUserHandler_listAds(t *testing.T) {
 			service := handlerMock.NewMockAdService(ctrl)
 			tc.mockBehaviour(service)
 
-			handler := NewAdHandler(service, nil)
+			handler := NewAdHandler(service)
 
 			//Test Server
 			rg := gin.New()
@@ -767,3 +779,622 @@ func TestUserHandler_listAds(t *testing.T) {
 		})
 	}
 }
+
+func TestUserHandler_listAds_PageParams(t *testing.T) {
+	tests := []struct {
+		name               string
+		query              string
+		mockBehaviour      func(service *handlerMock.MockAdService)
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name:  "page params are passed to service",
+			query: "?limit=1&page_token=abc&sort_by=title&order=desc",
+			mockBehaviour: func(service *handlerMock.MockAdService) {
+				service.EXPECT().
+					ListAds(gomock.Any(), models.AdFilter{},
+						models.PageParams{Limit: 1, PageToken: "abc", SortBy: models.AdSortByTitle, Order: models.SortDesc}).
+					Return(&models.AdsPage{Ads: []*models.Ad{}, NextPageToken: "next"}, nil).Times(1)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"data": [], "next_page_token": "next", "facets": {"categories": [], "cities": []}}`,
+		},
+		{
+			name:  "filters are parsed before calling service",
+			query: "?published=false&user_id=1&user_id=2",
+			mockBehaviour: func(service *handlerMock.MockAdService) {
+				published := false
+				service.EXPECT().
+					ListAds(gomock.Any(), models.AdFilter{Published: &published, AuthorIDs: []int64{1, 2}}, models.PageParams{}).
+					Return(&models.AdsPage{Ads: []*models.Ad{}}, nil).Times(1)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"data": [], "next_page_token": "", "facets": {"categories": [], "cities": []}}`,
+		},
+		{
+			name:               "invalid published filter",
+			query:              "?published=abc",
+			mockBehaviour:      func(service *handlerMock.MockAdService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   `{"error": "published validating error"}`,
+		},
+		{
+			name:               "invalid limit",
+			query:              "?limit=abc",
+			mockBehaviour:      func(service *handlerMock.MockAdService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   `{"error": "strconv.Atoi: parsing \"abc\": invalid syntax"}`,
+		},
+		{
+			name:  "invalid page params from service",
+			query: "?sort_by=text",
+			mockBehaviour: func(serv *handlerMock.MockAdService) {
+				serv.EXPECT().
+					ListAds(gomock.Any(), models.AdFilter{}, models.PageParams{SortBy: "text"}).
+					Return(nil, service.ErrInvalidPage{Err: service.ErrInvalidSortField}).Times(1)
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   `{"error": "sort_by must be one of: id, date_creation, date_update, title"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := handlerMock.NewMockAdService(ctrl)
+			tc.mockBehaviour(service)
+
+			handler := NewAdHandler(service)
+
+			rg := gin.New()
+			rg.GET("/", handler.listAds)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/"+tc.query, nil)
+			rg.ServeHTTP(w, r)
+
+			require.Equal(t, tc.expectedStatusCode, w.Code)
+			require.JSONEq(t, tc.expectedResponse, w.Body.String())
+		})
+	}
+}
+
+func TestUserHandler_streamAds(t *testing.T) {
+	bus := events.NewBus(2)
+	bus.Publish(models.AdCreated, nil, models.Ad{ID: 0, UserID: 1, Title: "title"})
+	bus.Publish(models.AdStatusChanged, &models.Ad{ID: 0, UserID: 1, Title: "title"},
+		models.Ad{ID: 0, UserID: 1, Title: "title", Published: true})
+	bus.Publish(models.AdDeleted, nil, models.Ad{ID: 0, UserID: 1, Title: "title", Published: true})
+
+	// подписка закрывается сразу после выдачи сохранённых событий, чтобы поток завершился
+	resume := func(_ context.Context, filter models.AdFilter, lastEventID int64) (*events.Subscription, bool) {
+		sub, complete := bus.Resume(filter, 1, lastEventID)
+		sub.Close()
+		return sub, complete
+	}
+	published := true
+
+	tests := []struct {
+		name               string
+		query              string
+		lastEventID        string
+		mockBehaviour      func(service *handlerMock.MockAdService)
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name:        "missed events are sent",
+			query:       "?user_id=1&published=true",
+			lastEventID: "1",
+			mockBehaviour: func(service *handlerMock.MockAdService) {
+				service.EXPECT().
+					WatchAdsSince(gomock.Any(), models.AdFilter{Published: &published, AuthorIDs: []int64{1}}, int64(1)).
+					DoAndReturn(resume).Times(1)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: "id:2\nevent:published\n" +
+				`data:{"id":0,"title":"title","text":"","user_id":1,"published":true,"date_creation":"0001-01-01T00:00:00Z","date_update":"0001-01-01T00:00:00Z","state":"","images":[]}` + "\n\n" +
+				"id:3\nevent:deleted\n" +
+				`data:{"id":0,"title":"title","text":"","user_id":1,"published":true,"date_creation":"0001-01-01T00:00:00Z","date_update":"0001-01-01T00:00:00Z","state":"","images":[]}` + "\n\n",
+		},
+		{
+			name: "without Last-Event-ID only new events are sent",
+			mockBehaviour: func(service *handlerMock.MockAdService) {
+				service.EXPECT().WatchAds(gomock.Any(), models.AdFilter{}).
+					DoAndReturn(func(context.Context, models.AdFilter) *events.Subscription {
+						sub := bus.Subscribe(models.AdFilter{}, 1)
+						sub.Close()
+						return sub
+					}).Times(1)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   "",
+		},
+		{
+			name:        "reset is sent when missed events are evicted",
+			lastEventID: "5",
+			mockBehaviour: func(service *handlerMock.MockAdService) {
+				service.EXPECT().WatchAdsSince(gomock.Any(), models.AdFilter{}, int64(5)).DoAndReturn(resume).Times(1)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   "event:reset\ndata:\n\n",
+		},
+		{
+			name:               "invalid last event id",
+			lastEventID:        "abc",
+			mockBehaviour:      func(service *handlerMock.MockAdService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   `{"error":"strconv.ParseInt: parsing \"abc\": invalid syntax"}`,
+		},
+		{
+			name:               "invalid published filter",
+			query:              "?published=abc",
+			mockBehaviour:      func(service *handlerMock.MockAdService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   `{"error":"published validating error"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := handlerMock.NewMockAdService(ctrl)
+			tc.mockBehaviour(service)
+
+			handler := NewAdHandler(service)
+
+			rg := gin.New()
+			rg.GET("/stream", handler.streamAds)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/stream"+tc.query, nil)
+			if tc.lastEventID != "" {
+				r.Header.Set("Last-Event-ID", tc.lastEventID)
+			}
+			rg.ServeHTTP(w, r)
+
+			require.Equal(t, tc.expectedStatusCode, w.Code)
+			require.Equal(t, tc.expectedResponse, w.Body.String())
+		})
+	}
+}
+
+func TestUserHandler_listAdRevisions(t *testing.T) {
+	tests := []struct {
+		name               string
+		adID               string
+		mockBehaviour      func(service *handlerMock.MockAdService)
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name: "successfully list revisions",
+			adID: "0",
+			mockBehaviour: func(service *handlerMock.MockAdService) {
+				service.EXPECT().ListAdRevisions(gomock.Any(), int64(0)).
+					Return([]*models.AdRevision{
+						{
+							AdID:     0,
+							Number:   1,
+							Type:     models.RevisionUpdated,
+							AuthorID: 3,
+							Date:     time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC),
+							Title:    "new title",
+							Text:     "text",
+							Changes:  []models.FieldChange{{Field: "title", Old: "title", New: "new title"}},
+						},
+					}, nil).Times(1)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `
+				{
+					"data": [
+						{
+							"ad_id": 0,
+							"number": 1,
+							"type": "updated",
+							"author_id": 3,
+							"date": "2023-05-01T12:00:00Z",
+							"title": "new title",
+							"text": "text",
+							"published": false,
+							"changes": [{"field": "title", "old": "title", "new": "new title"}]
+						}
+					]
+				}
+				`,
+		},
+		{
+			name:               "invalid ad id passed",
+			adID:               "abc",
+			mockBehaviour:      func(service *handlerMock.MockAdService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   `{"error": "strconv.Atoi: parsing \"abc\": invalid syntax"}`,
+		},
+		{
+			name: "error from service: ErrAdNotExist",
+			adID: "5",
+			mockBehaviour: func(service *handlerMock.MockAdService) {
+				service.EXPECT().ListAdRevisions(gomock.Any(), int64(5)).Return(nil, domain.ErrAdNotExist)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse:   `{"error": "the ad does not exist"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := handlerMock.NewMockAdService(ctrl)
+			tc.mockBehaviour(service)
+
+			handler := NewAdHandler(service)
+
+			rg := gin.New()
+			rg.GET("/:ad_id/revisions", handler.listAdRevisions)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/%s/revisions", tc.adID), nil)
+			rg.ServeHTTP(w, r)
+
+			require.Equal(t, tc.expectedStatusCode, w.Code)
+			require.JSONEq(t, tc.expectedResponse, w.Body.String())
+		})
+	}
+}
+
+func TestUserHandler_restoreAdRevision(t *testing.T) {
+	tests := []struct {
+		name               string
+		adID               string
+		rev                string
+		mockBehaviour      func(service *handlerMock.MockAdService)
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name: "successfully restore revision",
+			adID: "0",
+			rev:  "1",
+			mockBehaviour: func(service *handlerMock.MockAdService) {
+				service.EXPECT().RestoreAdRevision(gomock.Any(), int64(0), int64(1)).
+					Return(&models.Ad{ID: 0, Title: "title", Text: "text", UserID: 3}, nil).Times(1)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `
+				{
+					"data": {
+						"id": 0,
+						"title": "title",
+						"text": "text",
+						"user_id": 3,
+						"published": false,
+						"state": "",
+						"images": [],
+						"date_creation": "0001-01-01T00:00:00Z",
+						"date_update": "0001-01-01T00:00:00Z"
+					}
+				}
+				`,
+		},
+		{
+			name:               "invalid revision number passed",
+			adID:               "0",
+			rev:                "last",
+			mockBehaviour:      func(service *handlerMock.MockAdService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   `{"error": "strconv.ParseInt: parsing \"last\": invalid syntax"}`,
+		},
+		{
+			name: "error from service: ErrRevisionNotExist",
+			adID: "0",
+			rev:  "7",
+			mockBehaviour: func(service *handlerMock.MockAdService) {
+				service.EXPECT().RestoreAdRevision(gomock.Any(), int64(0), int64(7)).
+					Return(nil, domain.ErrRevisionNotExist)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse:   `{"error": "the revision does not exist"}`,
+		},
+		{
+			name: "error from service: ErrNoAccess",
+			adID: "0",
+			rev:  "1",
+			mockBehaviour: func(serv *handlerMock.MockAdService) {
+				serv.EXPECT().RestoreAdRevision(gomock.Any(), int64(0), int64(1)).
+					Return(nil, service.ErrNoAccess{Err: service.ErrNoAccessAd})
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedResponse:   `{"error": "you don't have access to edit the adID"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := handlerMock.NewMockAdService(ctrl)
+			tc.mockBehaviour(service)
+
+			handler := NewAdHandler(service)
+
+			rg := gin.New()
+			rg.POST("/:ad_id/revisions/:rev/restore", handler.restoreAdRevision)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/%s/revisions/%s/restore", tc.adID, tc.rev), nil)
+			rg.ServeHTTP(w, r)
+
+			require.Equal(t, tc.expectedStatusCode, w.Code)
+			require.JSONEq(t, tc.expectedResponse, w.Body.String())
+		})
+	}
+}
+
+func TestUserHandler_restoreAd(t *testing.T) {
+	tests := []struct {
+		name               string
+		adID               string
+		mockBehaviour      func(service *handlerMock.MockAdService)
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name: "successfully restore ad",
+			adID: "0",
+			mockBehaviour: func(service *handlerMock.MockAdService) {
+				service.EXPECT().RestoreAd(gomock.Any(), int64(0)).
+					Return(&models.Ad{ID: 0, Title: "title", Text: "text", UserID: 3}, nil).Times(1)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `
+				{
+					"data": {
+						"id": 0,
+						"title": "title",
+						"text": "text",
+						"user_id": 3,
+						"published": false,
+						"state": "",
+						"images": [],
+						"date_creation": "0001-01-01T00:00:00Z",
+						"date_update": "0001-01-01T00:00:00Z"
+					}
+				}
+				`,
+		},
+		{
+			name: "error from service: ErrAdNotInTrash",
+			adID: "0",
+			mockBehaviour: func(serv *handlerMock.MockAdService) {
+				serv.EXPECT().RestoreAd(gomock.Any(), int64(0)).Return(nil, service.ErrAdNotInTrash)
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedResponse:   `{"error": "the ad is not deleted"}`,
+		},
+		{
+			name: "error from service: ErrAdNotExist",
+			adID: "0",
+			mockBehaviour: func(serv *handlerMock.MockAdService) {
+				serv.EXPECT().RestoreAd(gomock.Any(), int64(0)).Return(nil, domain.ErrAdNotExist)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse:   `{"error": "the ad does not exist"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := handlerMock.NewMockAdService(ctrl)
+			tc.mockBehaviour(service)
+
+			handler := NewAdHandler(service)
+
+			rg := gin.New()
+			rg.POST("/:ad_id/restore", handler.restoreAd)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/%s/restore", tc.adID), nil)
+			rg.ServeHTTP(w, r)
+
+			require.Equal(t, tc.expectedStatusCode, w.Code)
+			require.JSONEq(t, tc.expectedResponse, w.Body.String())
+		})
+	}
+}
+
+func TestUserHandler_moderateAd(t *testing.T) {
+	tests := []struct {
+		name               string
+		path               string
+		body               string
+		ifMatch            string
+		mockBehaviour      func(service *handlerMock.MockAdService)
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name:    "successfully submit ad",
+			path:    "/0/submit",
+			ifMatch: `"2"`,
+			mockBehaviour: func(service *handlerMock.MockAdService) {
+				service.EXPECT().SubmitAd(gomock.Any(), int64(0), int64(2)).
+					Return(&models.Ad{ID: 0, Title: "title", Text: "text", UserID: 3, State: models.AdPendingReview}, nil).Times(1)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `
+				{
+					"data": {
+						"id": 0,
+						"title": "title",
+						"text": "text",
+						"user_id": 3,
+						"published": false,
+						"state": "pending_review",
+						"images": [],
+						"date_creation": "0001-01-01T00:00:00Z",
+						"date_update": "0001-01-01T00:00:00Z"
+					}
+				}
+				`,
+		},
+		{
+			name: "successfully reject ad",
+			path: "/0/reject",
+			body: `{"reason": "spam"}`,
+			mockBehaviour: func(service *handlerMock.MockAdService) {
+				service.EXPECT().RejectAd(gomock.Any(), int64(0), "spam", int64(0)).
+					Return(&models.Ad{ID: 0, Title: "title", Text: "text", UserID: 3, State: models.AdRejected,
+						RejectionReason: "spam"}, nil).Times(1)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `
+				{
+					"data": {
+						"id": 0,
+						"title": "title",
+						"text": "text",
+						"user_id": 3,
+						"published": false,
+						"state": "rejected",
+						"images": [],
+						"rejection_reason": "spam",
+						"date_creation": "0001-01-01T00:00:00Z",
+						"date_update": "0001-01-01T00:00:00Z"
+					}
+				}
+				`,
+		},
+		{
+			name: "error from service: ErrNotModerator",
+			path: "/0/approve",
+			mockBehaviour: func(serv *handlerMock.MockAdService) {
+				serv.EXPECT().ApproveAd(gomock.Any(), int64(0), int64(0)).Return(nil, service.ErrNotModerator)
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedResponse:   `{"error": "only moderators can review ads"}`,
+		},
+		{
+			name: "error from service: ErrAdNotInReview",
+			path: "/0/approve",
+			mockBehaviour: func(serv *handlerMock.MockAdService) {
+				serv.EXPECT().ApproveAd(gomock.Any(), int64(0), int64(0)).Return(nil, service.ErrAdNotInReview)
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedResponse:   `{"error": "the ad is not waiting for review"}`,
+		},
+		{
+			name: "error from service: reason is required",
+			path: "/0/reject",
+			body: `{"reason": ""}`,
+			mockBehaviour: func(serv *handlerMock.MockAdService) {
+				serv.EXPECT().RejectAd(gomock.Any(), int64(0), "", int64(0)).
+					Return(nil, domain.ValidationErrors{{Field: "Reason", Rule: "required", Message: "rejection reason is required"}})
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   `{"errors": [{"field": "Reason", "rule": "required", "message": "rejection reason is required"}]}`,
+		},
+		{
+			name:               "invalid body",
+			path:               "/0/reject",
+			body:               `{"reason": 1}`,
+			mockBehaviour:      func(serv *handlerMock.MockAdService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   `{"error": "json: cannot unmarshal number into Go struct field RejectAdRequest.reason of type string"}`,
+		},
+		{
+			name:               "invalid ad id",
+			path:               "/abc/submit",
+			mockBehaviour:      func(serv *handlerMock.MockAdService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   `{"error": "strconv.Atoi: parsing \"abc\": invalid syntax"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := handlerMock.NewMockAdService(ctrl)
+			tc.mockBehaviour(service)
+
+			handler := NewAdHandler(service)
+
+			rg := gin.New()
+			rg.POST("/:ad_id/submit", handler.submitAd)
+			rg.POST("/:ad_id/approve", handler.approveAd)
+			rg.POST("/:ad_id/reject", handler.rejectAd)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, tc.path, bytes.NewBufferString(tc.body))
+			if tc.ifMatch != "" {
+				r.Header.Set("If-Match", tc.ifMatch)
+			}
+			rg.ServeHTTP(w, r)
+
+			require.Equal(t, tc.expectedStatusCode, w.Code)
+			require.JSONEq(t, tc.expectedResponse, w.Body.String())
+		})
+	}
+}
+
+func TestUserHandler_moderationQueue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	adService := handlerMock.NewMockAdService(ctrl)
+	adService.EXPECT().ListModerationQueue(gomock.Any()).
+		Return([]*models.Ad{{ID: 4, Title: "title", UserID: 3, State: models.AdPendingReview}}, nil).Times(1)
+	adService.EXPECT().ListModerationQueue(gomock.Any()).Return(nil, service.ErrNotModerator).Times(1)
+
+	handler := NewAdHandler(adService)
+	rg := gin.New()
+	rg.GET("/moderation", handler.moderationQueue)
+
+	w := httptest.NewRecorder()
+	rg.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/moderation", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `
+		{
+			"data": [{
+				"id": 4,
+				"title": "title",
+				"text": "",
+				"user_id": 3,
+				"published": false,
+				"state": "pending_review",
+				"images": [],
+				"date_creation": "0001-01-01T00:00:00Z",
+				"date_update": "0001-01-01T00:00:00Z"
+			}]
+		}
+		`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	rg.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/moderation", nil))
+	require.Equal(t, http.StatusForbidden, w.Code)
+}
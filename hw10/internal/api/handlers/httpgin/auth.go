@@ -0,0 +1,47 @@
+package httpgin
+
+import (
+	"context"
+	"net/http"
+
+	"homework10/internal/api/handlers/httpgin/mapper"
+	"homework10/internal/api/handlers/httpgin/request"
+	"homework10/internal/domain/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:generate mockgen -source=./auth.go -destination=../mock/auth.go -package=handlermock AuthService
+type AuthService interface {
+	Login(ctx context.Context, email string, password string) (*models.AuthToken, error)
+}
+
+type AuthHandler struct {
+	service AuthService
+}
+
+func NewAuthHandler(service AuthService) *AuthHandler {
+	return &AuthHandler{service: service}
+}
+
+func (h *AuthHandler) AddRoutes(rg *gin.RouterGroup) {
+	rg.POST("/login", h.login) // Метод для получения токена доступа по почте (email) и паролю (password) пользователя
+}
+
+func (h *AuthHandler) BasePrefix() string {
+	return "/auth"
+}
+
+func (h *AuthHandler) login(ctx *gin.Context) {
+	var reqBody request.LoginRequest
+	if err := ctx.BindJSON(&reqBody); err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	token, err := h.service.Login(ctx, reqBody.Email, reqBody.Password)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, mapper.TokenSuccessResponse(token))
+}
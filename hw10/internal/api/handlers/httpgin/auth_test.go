@@ -0,0 +1,103 @@
+package httpgin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	handlerMock "homework10/internal/api/handlers/httpgin/mock"
+	"homework10/internal/api/handlers/httpgin/request"
+	"homework10/internal/domain/models"
+	"homework10/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAuthHandler_login(t *testing.T) {
+	expiresAt := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name               string
+		body               any
+		mockBehaviour      func(service *handlerMock.MockAuthService)
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name: "successfully login",
+			body: request.LoginRequest{Email: "Ivan@gmail.com", Password: "password123"},
+			mockBehaviour: func(service *handlerMock.MockAuthService) {
+				service.EXPECT().Login(gomock.Any(), "Ivan@gmail.com", "password123").
+					Return(&models.AuthToken{Token: "token", ExpiresAt: expiresAt}, nil).Times(1)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"data": {"token": "token", "expires_at": "2023-05-01T12:00:00Z"}}`,
+		},
+		{
+			name: "invalid json body passed",
+			body: struct {
+				Email bool `json:"email"`
+			}{
+				Email: true,
+			},
+			mockBehaviour:      func(service *handlerMock.MockAuthService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   `{"error": "json: cannot unmarshal bool into Go struct field LoginRequest.email of type string"}`,
+		},
+		{
+			name: "error from service: ErrInvalidCredentials",
+			body: request.LoginRequest{Email: "unknown@gmail.com", Password: "password123"},
+			mockBehaviour: func(serv *handlerMock.MockAuthService) {
+				serv.EXPECT().Login(gomock.Any(), "unknown@gmail.com", "password123").
+					Return(nil, service.ErrInvalidCredentials)
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedResponse:   `{"error": "invalid email or password"}`,
+		},
+		{
+			name: "error from service",
+			body: request.LoginRequest{Email: "Ivan@gmail.com", Password: "password123"},
+			mockBehaviour: func(service *handlerMock.MockAuthService) {
+				service.EXPECT().Login(gomock.Any(), "Ivan@gmail.com", "password123").
+					Return(nil, fmt.Errorf("error from service"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse:   `{"error": "error from service"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := handlerMock.NewMockAuthService(ctrl)
+			tc.mockBehaviour(service)
+
+			handler := NewAuthHandler(service)
+
+			//Test Server
+			rg := gin.New()
+			rg.POST("/login", handler.login)
+
+			jsonValue, err := json.Marshal(tc.body)
+			require.Equal(t, err, nil)
+
+			//Test request
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/login", bytes.NewBuffer(jsonValue))
+
+			//Perform request
+			rg.ServeHTTP(w, r)
+
+			// Assert
+			require.Equal(t, tc.expectedStatusCode, w.Code)
+			require.JSONEq(t, tc.expectedResponse, w.Body.String())
+		})
+	}
+}
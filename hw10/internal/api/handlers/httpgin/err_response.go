@@ -1,9 +1,32 @@
 package httpgin
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"homework10/internal/api/handlers/apierror"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofiber/fiber/v2"
+)
 
 func NewErrResponse(err error) *fiber.Map {
 	return &fiber.Map{
 		"error": err.Error(),
 	}
 }
+
+// NewValidationErrResponse тело ответа со списком полей, не прошедших проверку
+func NewValidationErrResponse(fields []apierror.FieldViolation) *fiber.Map {
+	return &fiber.Map{
+		"errors": fields,
+	}
+}
+
+// errorResponse отвечает статусом, соответствующим категории ошибки сервиса;
+// ошибки валидации отдаются списком полей
+func errorResponse(ctx *gin.Context, err error) {
+	apiErr := apierror.Translate(err)
+	if len(apiErr.Fields) != 0 {
+		ctx.JSON(apiErr.HTTPStatus, NewValidationErrResponse(apiErr.Fields))
+		return
+	}
+	ctx.JSON(apiErr.HTTPStatus, NewErrResponse(err))
+}
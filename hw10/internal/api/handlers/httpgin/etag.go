@@ -0,0 +1,42 @@
+package httpgin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"homework10/internal/api/handlers/httpgin/mapper"
+	"homework10/internal/domain/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+var ErrInvalidIfMatch = errors.New("invalid If-Match header: expected a single strong ETag or *")
+
+// etag строгий ETag объявления - его версия в кавычках
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ifMatchVersion возвращает версию объявления из заголовка If-Match; 0 - заголовка нет или он равен *
+func ifMatchVersion(ctx *gin.Context) (int64, error) {
+	value := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+	if len(value) < 2 || !strings.HasPrefix(value, `"`) || !strings.HasSuffix(value, `"`) {
+		return 0, ErrInvalidIfMatch
+	}
+	version, err := strconv.ParseInt(value[1:len(value)-1], 10, 64)
+	if err != nil || version < 1 {
+		return 0, ErrInvalidIfMatch
+	}
+	return version, nil
+}
+
+// adResponse отдаёт объявление вместе с его ETag
+func adResponse(ctx *gin.Context, ad *models.Ad) {
+	ctx.Header("ETag", etag(ad.Version))
+	ctx.IndentedJSON(http.StatusOK, mapper.AdSuccessResponse(ad))
+}
@@ -0,0 +1,123 @@
+package httpgin
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	handlerMock "homework10/internal/api/handlers/httpgin/mock"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdHandler_ETag(t *testing.T) {
+	tests := []struct {
+		name               string
+		method             string
+		path               string
+		body               string
+		ifMatch            string
+		mockBehaviour      func(service *handlerMock.MockAdService)
+		expectedStatusCode int
+		expectedETag       string
+	}{
+		{
+			name:   "get returns version as ETag",
+			method: http.MethodGet,
+			path:   "/0",
+			mockBehaviour: func(service *handlerMock.MockAdService) {
+				service.EXPECT().GetAdByID(gomock.Any(), int64(0)).Return(&models.Ad{ID: 0, Version: 3}, nil).Times(1)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedETag:       `"3"`,
+		},
+		{
+			name:    "update with matching If-Match",
+			method:  http.MethodPut,
+			path:    "/0",
+			body:    `{"title": "title", "text": "text"}`,
+			ifMatch: `"3"`,
+			mockBehaviour: func(service *handlerMock.MockAdService) {
+				service.EXPECT().UpdateAd(gomock.Any(), int64(0), "title", "text", models.AdDetails{}, int64(3)).
+					Return(&models.Ad{ID: 0, Title: "title", Text: "text", Version: 4}, nil).Times(1)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedETag:       `"4"`,
+		},
+		{
+			name:    "update with any version",
+			method:  http.MethodPut,
+			path:    "/0",
+			body:    `{"title": "title", "text": "text"}`,
+			ifMatch: "*",
+			mockBehaviour: func(service *handlerMock.MockAdService) {
+				service.EXPECT().UpdateAd(gomock.Any(), int64(0), "title", "text", models.AdDetails{}, int64(0)).
+					Return(&models.Ad{ID: 0, Title: "title", Text: "text", Version: 4}, nil).Times(1)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedETag:       `"4"`,
+		},
+		{
+			name:    "status change with outdated If-Match",
+			method:  http.MethodPut,
+			path:    "/0/status",
+			body:    `{"published": true}`,
+			ifMatch: `"2"`,
+			mockBehaviour: func(service *handlerMock.MockAdService) {
+				service.EXPECT().ChangeAdStatus(gomock.Any(), int64(0), true, int64(2)).
+					Return(nil, domain.ErrAdVersionMismatch).Times(1)
+			},
+			expectedStatusCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:               "weak ETag in If-Match",
+			method:             http.MethodPut,
+			path:               "/0",
+			body:               `{"title": "title", "text": "text"}`,
+			ifMatch:            `W/"3"`,
+			mockBehaviour:      func(service *handlerMock.MockAdService) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "several ETags in If-Match",
+			method:             http.MethodPut,
+			path:               "/0/status",
+			body:               `{"published": true}`,
+			ifMatch:            `"3", "4"`,
+			mockBehaviour:      func(service *handlerMock.MockAdService) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := handlerMock.NewMockAdService(ctrl)
+			tc.mockBehaviour(service)
+
+			handler := NewAdHandler(service)
+
+			rg := gin.New()
+			rg.GET("/:ad_id", handler.getAd)
+			rg.PUT("/:ad_id", handler.updateAd)
+			rg.PUT("/:ad_id/status", handler.changeAdStatus)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, tc.path, bytes.NewBufferString(tc.body))
+			if tc.ifMatch != "" {
+				r.Header.Set("If-Match", tc.ifMatch)
+			}
+			rg.ServeHTTP(w, r)
+
+			require.Equal(t, tc.expectedStatusCode, w.Code)
+			require.Equal(t, tc.expectedETag, w.Header().Get("ETag"))
+		})
+	}
+}
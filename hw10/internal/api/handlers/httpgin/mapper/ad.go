@@ -2,8 +2,11 @@ package mapper
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"homework10/internal/api/handlers/httpgin/request"
 	"homework10/internal/api/handlers/httpgin/response"
+	"homework10/internal/api/handlers/links"
 	"homework10/internal/domain/models"
+	"time"
 )
 
 func AdToResponse(ad *models.Ad) response.AdResponse {
@@ -13,11 +16,74 @@ func AdToResponse(ad *models.Ad) response.AdResponse {
 		Text:         ad.Text,
 		UserID:       ad.UserID,
 		Published:    ad.Published,
-		DateCreation: ad.DateCreation,
-		DateUpdate:   ad.DateUpdate,
+		DateCreation: ad.DateCreation.UTC().Format(time.RFC3339),
+		DateUpdate:   ad.DateUpdate.UTC().Format(time.RFC3339),
+		State:        string(ad.State),
+
+		RejectionReason: ad.RejectionReason,
+		Images:          ImagesToResponse(ad),
+		Price:           PriceToResponse(ad.Price),
+		CategoryID:      ad.CategoryID,
+		City:            ad.City,
 	}
 }
 
+func PriceToResponse(price models.Price) *response.PriceResponse {
+	if !price.IsSet() {
+		return nil
+	}
+	return &response.PriceResponse{Amount: price.Amount, Currency: price.Currency}
+}
+
+func AdDetailsFromRequest(req request.AdDetailsRequest) models.AdDetails {
+	details := models.AdDetails{CategoryID: req.CategoryID, City: req.City}
+	if req.Price != nil {
+		details.Price = models.Price{Amount: req.Price.Amount, Currency: req.Price.Currency}
+	}
+	return details
+}
+
+func FacetsToResponse(facets models.AdFacets) response.FacetsResponse {
+	return response.FacetsResponse{
+		Categories: facetCountsToResponse(facets.Categories),
+		Cities:     facetCountsToResponse(facets.Cities),
+	}
+}
+
+func facetCountsToResponse(counts []models.FacetCount) []response.FacetCountResponse {
+	res := make([]response.FacetCountResponse, 0, len(counts))
+	for _, count := range counts {
+		res = append(res, response.FacetCountResponse{Value: count.Value, Count: count.Count})
+	}
+	return res
+}
+
+func CategoriesSuccessResponse(categories []models.Category) *fiber.Map {
+	res := make([]response.CategoryResponse, 0, len(categories))
+	for _, category := range categories {
+		res = append(res, response.CategoryResponse{ID: category.ID, Name: category.Name, ParentID: category.ParentID})
+	}
+	return &fiber.Map{
+		"data": res,
+	}
+}
+
+func ImagesToResponse(ad *models.Ad) []response.ImageResponse {
+	images := make([]response.ImageResponse, 0, len(ad.Images))
+	for _, image := range ad.Images {
+		images = append(images, response.ImageResponse{
+			ID:           image.ID,
+			URL:          links.AdImage(ad.ID, image.ID),
+			ThumbnailURL: links.AdImageThumbnail(ad.ID, image.ID),
+			ContentType:  image.ContentType,
+			Size:         image.Size,
+			Width:        image.Width,
+			Height:       image.Height,
+		})
+	}
+	return images
+}
+
 func AdToSliceResponse(ads []*models.Ad) []response.AdResponse {
 	adsRes := make([]response.AdResponse, 0)
 	for _, ad := range ads {
@@ -37,3 +103,39 @@ func AdsSuccessResponse(ads []*models.Ad) *fiber.Map {
 		"data": AdToSliceResponse(ads),
 	}
 }
+
+func AdsPageSuccessResponse(page *models.AdsPage) *fiber.Map {
+	return &fiber.Map{
+		"data":            AdToSliceResponse(page.Ads),
+		"next_page_token": page.NextPageToken,
+		"facets":          FacetsToResponse(page.Facets),
+	}
+}
+
+func SearchPageSuccessResponse(page *models.SearchPage) *fiber.Map {
+	results := make([]response.SearchAdResponse, 0)
+	for _, result := range page.Results {
+		results = append(results, response.SearchAdResponse{
+			AdResponse: AdToResponse(result.Ad),
+			Score:      result.Score,
+			Snippet:    result.Snippet,
+		})
+	}
+	return &fiber.Map{
+		"data":            results,
+		"next_page_token": page.NextPageToken,
+	}
+}
+
+// AdEventName имя события для клиентов SSE; смена статуса различается на публикацию и снятие с публикации
+func AdEventName(event models.AdEvent) string {
+	switch event.Type {
+	case models.AdStatusChanged:
+		if event.Ad.Published {
+			return "published"
+		}
+		return "unpublished"
+	default:
+		return string(event.Type)
+	}
+}
@@ -12,6 +12,8 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+var testDate = time.Date(2023, time.May, 1, 12, 0, 0, 0, time.UTC)
+
 func TestAdToResponse(t *testing.T) {
 	now := time.Now().UTC()
 
@@ -28,8 +30,8 @@ func TestAdToResponse(t *testing.T) {
 				Text:         "test text",
 				UserID:       2,
 				Published:    false,
-				DateCreation: now.Format("01-02-2006"),
-				DateUpdate:   now.Format("01-02-2006"),
+				DateCreation: now,
+				DateUpdate:   now,
 			},
 			expected: response.AdResponse{
 				ID:           1,
@@ -37,8 +39,58 @@ func TestAdToResponse(t *testing.T) {
 				Text:         "test text",
 				UserID:       2,
 				Published:    false,
-				DateCreation: now.Format("01-02-2006"),
-				DateUpdate:   now.Format("01-02-2006"),
+				DateCreation: now.Format(time.RFC3339),
+				DateUpdate:   now.Format(time.RFC3339),
+				Images:       []response.ImageResponse{},
+			},
+		},
+		{
+			name: "map ad images to links",
+			ad: &models.Ad{
+				ID:           3,
+				Title:        "test title",
+				DateCreation: now,
+				DateUpdate:   now,
+				Images:       []models.AdImage{{ID: 2, ContentType: "image/png", Size: 100, Width: 20, Height: 10, Key: "ads/3/a"}},
+			},
+			expected: response.AdResponse{
+				ID:           3,
+				Title:        "test title",
+				DateCreation: now.Format(time.RFC3339),
+				DateUpdate:   now.Format(time.RFC3339),
+				Images: []response.ImageResponse{{
+					ID:           2,
+					URL:          "/api/v1/ads/3/images/2",
+					ThumbnailURL: "/api/v1/ads/3/images/2/thumbnail",
+					ContentType:  "image/png",
+					Size:         100,
+					Width:        20,
+					Height:       10,
+				}},
+			},
+		},
+		{
+			name: "map ad details",
+			ad: &models.Ad{
+				ID:           4,
+				Title:        "test title",
+				DateCreation: now,
+				DateUpdate:   now,
+				AdDetails: models.AdDetails{
+					Price:      models.Price{Amount: 150000, Currency: "RUB"},
+					CategoryID: "cars",
+					City:       "Казань",
+				},
+			},
+			expected: response.AdResponse{
+				ID:           4,
+				Title:        "test title",
+				DateCreation: now.Format(time.RFC3339),
+				DateUpdate:   now.Format(time.RFC3339),
+				Images:       []response.ImageResponse{},
+				Price:        &response.PriceResponse{Amount: 150000, Currency: "RUB"},
+				CategoryID:   "cars",
+				City:         "Казань",
 			},
 		},
 	}
@@ -70,8 +122,8 @@ func TestAdToSliceResponse(t *testing.T) {
 					Text:         "test text",
 					UserID:       2,
 					Published:    false,
-					DateCreation: now.Format("01-02-2006"),
-					DateUpdate:   now.Format("01-02-2006"),
+					DateCreation: now,
+					DateUpdate:   now,
 				},
 			},
 			expected: []response.AdResponse{
@@ -81,8 +133,9 @@ func TestAdToSliceResponse(t *testing.T) {
 					Text:         "test text",
 					UserID:       2,
 					Published:    false,
-					DateCreation: now.Format("01-02-2006"),
-					DateUpdate:   now.Format("01-02-2006"),
+					DateCreation: now.Format(time.RFC3339),
+					DateUpdate:   now.Format(time.RFC3339),
+					Images:       []response.ImageResponse{},
 				},
 			},
 		},
@@ -112,8 +165,8 @@ func TestAdSuccessResponse(t *testing.T) {
 				Text:         "test text",
 				UserID:       2,
 				Published:    false,
-				DateCreation: now.Format("01-02-2006"),
-				DateUpdate:   now.Format("01-02-2006"),
+				DateCreation: now,
+				DateUpdate:   now,
 			},
 			expected: &fiber.Map{
 				"data": response.AdResponse{
@@ -122,8 +175,9 @@ func TestAdSuccessResponse(t *testing.T) {
 					Text:         "test text",
 					UserID:       2,
 					Published:    false,
-					DateCreation: now.Format("01-02-2006"),
-					DateUpdate:   now.Format("01-02-2006"),
+					DateCreation: now.Format(time.RFC3339),
+					DateUpdate:   now.Format(time.RFC3339),
+					Images:       []response.ImageResponse{},
 				},
 			},
 		},
@@ -154,8 +208,8 @@ func TestAdsSuccessResponse(t *testing.T) {
 					Text:         "test text",
 					UserID:       2,
 					Published:    false,
-					DateCreation: now.Format("01-02-2006"),
-					DateUpdate:   now.Format("01-02-2006"),
+					DateCreation: now,
+					DateUpdate:   now,
 				},
 			},
 			expected: &fiber.Map{
@@ -166,8 +220,9 @@ func TestAdsSuccessResponse(t *testing.T) {
 						Text:         "test text",
 						UserID:       2,
 						Published:    false,
-						DateCreation: now.Format("01-02-2006"),
-						DateUpdate:   now.Format("01-02-2006"),
+						DateCreation: now.Format(time.RFC3339),
+						DateUpdate:   now.Format(time.RFC3339),
+						Images:       []response.ImageResponse{},
 					},
 				},
 			},
@@ -181,3 +236,134 @@ func TestAdsSuccessResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestAdsPageSuccessResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		page     *models.AdsPage
+		expected *fiber.Map
+	}{
+		{
+			name: "successfully map page with next page token",
+			page: &models.AdsPage{
+				Ads:           []*models.Ad{{ID: 1, Title: "test title", Text: "test text", UserID: 2, DateCreation: testDate, DateUpdate: testDate}},
+				NextPageToken: "token",
+			},
+			expected: &fiber.Map{
+				"data": []response.AdResponse{
+					{ID: 1, Title: "test title", Text: "test text", UserID: 2,
+						DateCreation: "2023-05-01T12:00:00Z", DateUpdate: "2023-05-01T12:00:00Z", Images: []response.ImageResponse{}},
+				},
+				"next_page_token": "token",
+				"facets": response.FacetsResponse{
+					Categories: []response.FacetCountResponse{},
+					Cities:     []response.FacetCountResponse{},
+				},
+			},
+		},
+		{
+			name: "successfully map empty last page",
+			page: &models.AdsPage{Ads: []*models.Ad{}},
+			expected: &fiber.Map{
+				"data":            []response.AdResponse{},
+				"next_page_token": "",
+				"facets": response.FacetsResponse{
+					Categories: []response.FacetCountResponse{},
+					Cities:     []response.FacetCountResponse{},
+				},
+			},
+		},
+		{
+			name: "successfully map facets",
+			page: &models.AdsPage{
+				Ads: []*models.Ad{},
+				Facets: models.AdFacets{
+					Categories: []models.FacetCount{{Value: "transport", Count: 2}, {Value: "cars", Count: 1}},
+					Cities:     []models.FacetCount{{Value: "Москва", Count: 2}},
+				},
+			},
+			expected: &fiber.Map{
+				"data":            []response.AdResponse{},
+				"next_page_token": "",
+				"facets": response.FacetsResponse{
+					Categories: []response.FacetCountResponse{{Value: "transport", Count: 2}, {Value: "cars", Count: 1}},
+					Cities:     []response.FacetCountResponse{{Value: "Москва", Count: 2}},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AdsPageSuccessResponse(tt.page); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("AdsPageSuccessResponse() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSearchPageSuccessResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		page     *models.SearchPage
+		expected *fiber.Map
+	}{
+		{
+			name: "successfully map search page",
+			page: &models.SearchPage{
+				Results: []*models.SearchResult{{
+					Ad:      &models.Ad{ID: 1, Title: "test title", Text: "test text", UserID: 2, DateCreation: testDate, DateUpdate: testDate},
+					Score:   0.75,
+					Snippet: "<mark>test</mark> text",
+				}},
+				NextPageToken: "token",
+			},
+			expected: &fiber.Map{
+				"data": []response.SearchAdResponse{{
+					AdResponse: response.AdResponse{ID: 1, Title: "test title", Text: "test text", UserID: 2,
+						DateCreation: "2023-05-01T12:00:00Z", DateUpdate: "2023-05-01T12:00:00Z", Images: []response.ImageResponse{}},
+					Score:   0.75,
+					Snippet: "<mark>test</mark> text",
+				}},
+				"next_page_token": "token",
+			},
+		},
+		{
+			name: "successfully map empty page",
+			page: &models.SearchPage{Results: []*models.SearchResult{}},
+			expected: &fiber.Map{
+				"data":            []response.SearchAdResponse{},
+				"next_page_token": "",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SearchPageSuccessResponse(tt.page); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("SearchPageSuccessResponse() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAdEventName(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    models.AdEvent
+		expected string
+	}{
+		{name: "created", event: models.AdEvent{Type: models.AdCreated}, expected: "created"},
+		{name: "updated", event: models.AdEvent{Type: models.AdUpdated}, expected: "updated"},
+		{name: "published", event: models.AdEvent{Type: models.AdStatusChanged, Ad: models.Ad{Published: true}}, expected: "published"},
+		{name: "unpublished", event: models.AdEvent{Type: models.AdStatusChanged}, expected: "unpublished"},
+		{name: "deleted", event: models.AdEvent{Type: models.AdDeleted, Ad: models.Ad{Published: true}}, expected: "deleted"},
+		{name: "left", event: models.AdEvent{Type: models.AdLeft}, expected: "left"},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, AdEventName(tc.event))
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package mapper
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"homework10/internal/api/handlers/httpgin/response"
+	"homework10/internal/domain/models"
+)
+
+func TokenSuccessResponse(token *models.AuthToken) *fiber.Map {
+	return &fiber.Map{
+		"data": response.TokenResponse{
+			Token:     token.Token,
+			ExpiresAt: token.ExpiresAt,
+		},
+	}
+}
@@ -0,0 +1,25 @@
+package mapper
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"homework10/internal/api/handlers/httpgin/response"
+	"homework10/internal/domain/models"
+	"time"
+)
+
+func FavoriteAdToResponse(favorite *models.FavoriteAd) response.FavoriteAdResponse {
+	return response.FavoriteAdResponse{
+		AdResponse: AdToResponse(favorite.Ad),
+		AddedAt:    favorite.AddedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func FavoritesSuccessResponse(favorites []*models.FavoriteAd) *fiber.Map {
+	favoritesRes := make([]response.FavoriteAdResponse, 0, len(favorites))
+	for _, favorite := range favorites {
+		favoritesRes = append(favoritesRes, FavoriteAdToResponse(favorite))
+	}
+	return &fiber.Map{
+		"data": favoritesRes,
+	}
+}
@@ -0,0 +1,59 @@
+package mapper
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"homework10/internal/api/handlers/httpgin/response"
+	"homework10/internal/domain/models"
+	"time"
+)
+
+func MessageToResponse(message *models.Message) response.MessageResponse {
+	res := response.MessageResponse{
+		ID:             message.ID,
+		ConversationID: message.ConversationID,
+		SenderID:       message.SenderID,
+		Text:           message.Text,
+		DateCreation:   message.DateCreation.UTC().Format(time.RFC3339),
+	}
+	if message.IsRead() {
+		res.ReadAt = message.ReadAt.UTC().Format(time.RFC3339)
+	}
+	return res
+}
+
+func MessageSuccessResponse(message *models.Message) *fiber.Map {
+	return &fiber.Map{
+		"data": MessageToResponse(message),
+	}
+}
+
+func MessagesSuccessResponse(messages []*models.Message) *fiber.Map {
+	messagesRes := make([]response.MessageResponse, 0, len(messages))
+	for _, message := range messages {
+		messagesRes = append(messagesRes, MessageToResponse(message))
+	}
+	return &fiber.Map{
+		"data": messagesRes,
+	}
+}
+
+func InboxSuccessResponse(inbox *models.Inbox) *fiber.Map {
+	conversationsRes := make([]response.ConversationResponse, 0, len(inbox.Conversations))
+	for _, summary := range inbox.Conversations {
+		conversation := summary.Conversation
+		conversationsRes = append(conversationsRes, response.ConversationResponse{
+			ID:            conversation.ID,
+			AdID:          conversation.AdID,
+			BuyerID:       conversation.BuyerID,
+			SellerID:      conversation.SellerID,
+			DateCreation:  conversation.DateCreation.UTC().Format(time.RFC3339),
+			LastMessageAt: conversation.LastMessageAt.UTC().Format(time.RFC3339),
+			LastMessage:   MessageToResponse(summary.LastMessage),
+			UnreadCount:   summary.UnreadCount,
+		})
+	}
+	return &fiber.Map{
+		"data":         conversationsRes,
+		"unread_count": inbox.UnreadCount,
+	}
+}
@@ -0,0 +1,37 @@
+package mapper
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"homework10/internal/api/handlers/httpgin/response"
+	"homework10/internal/domain/models"
+	"time"
+)
+
+func AdRevisionToResponse(revision *models.AdRevision) response.AdRevisionResponse {
+	changes := make([]response.FieldChangeResponse, 0, len(revision.Changes))
+	for _, change := range revision.Changes {
+		changes = append(changes, response.FieldChangeResponse{Field: change.Field, Old: change.Old, New: change.New})
+	}
+	return response.AdRevisionResponse{
+		AdID:         revision.AdID,
+		Number:       revision.Number,
+		Type:         string(revision.Type),
+		AuthorID:     revision.AuthorID,
+		Date:         revision.Date.UTC().Format(time.RFC3339),
+		Title:        revision.Title,
+		Text:         revision.Text,
+		Published:    revision.Published,
+		Changes:      changes,
+		RestoredFrom: revision.RestoredFrom,
+	}
+}
+
+func AdRevisionsSuccessResponse(revisions []*models.AdRevision) *fiber.Map {
+	revisionsRes := make([]response.AdRevisionResponse, 0, len(revisions))
+	for _, revision := range revisions {
+		revisionsRes = append(revisionsRes, AdRevisionToResponse(revision))
+	}
+	return &fiber.Map{
+		"data": revisionsRes,
+	}
+}
@@ -0,0 +1,26 @@
+package mapper
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"homework10/internal/api/handlers/httpgin/response"
+	"homework10/internal/domain/models"
+	"time"
+)
+
+func TrashedAdToResponse(trashed *models.TrashedAd) response.TrashedAdResponse {
+	return response.TrashedAdResponse{
+		AdResponse: AdToResponse(trashed.Ad),
+		DeletedAt:  trashed.Ad.DeletedAt.UTC().Format(time.RFC3339),
+		PurgeAt:    trashed.PurgeAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func TrashSuccessResponse(trash []*models.TrashedAd) *fiber.Map {
+	trashRes := make([]response.TrashedAdResponse, 0, len(trash))
+	for _, trashed := range trash {
+		trashRes = append(trashRes, TrashedAdToResponse(trashed))
+	}
+	return &fiber.Map{
+		"data": trashRes,
+	}
+}
@@ -13,6 +13,7 @@ func UserSuccessResponse(user *models.User) *fiber.Map {
 			ID:       user.ID,
 			Nickname: user.NickName,
 			Email:    user.Email,
+			Role:     string(user.Role),
 		},
 	}
 }
@@ -0,0 +1,119 @@
+package httpgin
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"homework10/internal/api/handlers/httpgin/mapper"
+	"homework10/internal/api/handlers/httpgin/request"
+	"homework10/internal/domain/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:generate mockgen -source=./message.go -destination=../mock/message.go -package=handlermock MessageService
+type MessageService interface {
+	SendMessage(ctx context.Context, adID int64, conversationID int64, text string) (*models.Message, error)
+	ListConversations(ctx context.Context, userID int64) (*models.Inbox, error)
+	ListMessages(ctx context.Context, userID int64, conversationID int64) ([]*models.Message, error)
+	MarkConversationRead(ctx context.Context, userID int64, conversationID int64) (int, error)
+}
+
+// MessageHandler маршруты переписки; они относятся и к объявлениям, и к пользователям, поэтому
+// регистрируются от корня API
+type MessageHandler struct {
+	service MessageService
+}
+
+func NewMessageHandler(service MessageService) *MessageHandler {
+	return &MessageHandler{
+		service: service,
+	}
+}
+
+func (h *MessageHandler) AddRoutes(rg *gin.RouterGroup) {
+	rg.POST("/ads/:ad_id/messages", h.sendMessage) // Метод для отправки сообщения (message) об объявлении
+
+	rg.GET("/users/:user_id/conversations", h.listConversations)                           // Метод для получения бесед пользователя
+	rg.GET("/users/:user_id/conversations/:conversation_id/messages", h.listMessages)      // Метод для получения сообщений беседы
+	rg.POST("/users/:user_id/conversations/:conversation_id/read", h.markConversationRead) // Метод для отметки сообщений беседы прочитанными
+}
+
+func (h *MessageHandler) BasePrefix() string {
+	return ""
+}
+
+func (h *MessageHandler) sendMessage(ctx *gin.Context) {
+	var reqBody request.SendMessageRequest
+	if err := ctx.BindJSON(&reqBody); err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	adID, err := strconv.Atoi(ctx.Param("ad_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	message, err := h.service.SendMessage(ctx, int64(adID), reqBody.ConversationID, reqBody.Text)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, mapper.MessageSuccessResponse(message))
+}
+
+func (h *MessageHandler) listConversations(ctx *gin.Context) {
+	userID, err := strconv.Atoi(ctx.Param("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	inbox, err := h.service.ListConversations(ctx, int64(userID))
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, mapper.InboxSuccessResponse(inbox))
+}
+
+func (h *MessageHandler) listMessages(ctx *gin.Context) {
+	userID, conversationID, ok := conversationParams(ctx)
+	if !ok {
+		return
+	}
+	messages, err := h.service.ListMessages(ctx, userID, conversationID)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, mapper.MessagesSuccessResponse(messages))
+}
+
+func (h *MessageHandler) markConversationRead(ctx *gin.Context) {
+	userID, conversationID, ok := conversationParams(ctx)
+	if !ok {
+		return
+	}
+	marked, err := h.service.MarkConversationRead(ctx, userID, conversationID)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, gin.H{"data": gin.H{"marked": marked}})
+}
+
+// conversationParams разбирает user_id и conversation_id маршрутов бесед; при ошибке ответ уже отправлен
+func conversationParams(ctx *gin.Context) (userID int64, conversationID int64, ok bool) {
+	user, err := strconv.Atoi(ctx.Param("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return 0, 0, false
+	}
+	conversation, err := strconv.Atoi(ctx.Param("conversation_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return 0, 0, false
+	}
+	return int64(user), int64(conversation), true
+}
@@ -0,0 +1,291 @@
+package httpgin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	handlerMock "homework10/internal/api/handlers/httpgin/mock"
+	"homework10/internal/api/handlers/httpgin/request"
+	"homework10/internal/domain/models"
+	"homework10/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMessageHandler_sendMessage(t *testing.T) {
+	created := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name               string
+		adID               string
+		body               any
+		mockBehaviour      func(service *handlerMock.MockMessageService)
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name: "successfully send message",
+			adID: "3",
+			body: request.SendMessageRequest{Text: "hello"},
+			mockBehaviour: func(service *handlerMock.MockMessageService) {
+				service.EXPECT().SendMessage(gomock.Any(), int64(3), int64(0), "hello").
+					Return(&models.Message{ID: 1, ConversationID: 2, SenderID: 5, Text: "hello", DateCreation: created}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `{"data": {"id": 1, "conversation_id": 2, "sender_id": 5, "text": "hello",
+				"date_creation": "2023-05-01T12:00:00Z"}}`,
+		},
+		{
+			name: "reply in conversation",
+			adID: "3",
+			body: request.SendMessageRequest{Text: "yes", ConversationID: 2},
+			mockBehaviour: func(service *handlerMock.MockMessageService) {
+				service.EXPECT().SendMessage(gomock.Any(), int64(3), int64(2), "yes").
+					Return(&models.Message{ID: 2, ConversationID: 2, SenderID: 7, Text: "yes", DateCreation: created}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `{"data": {"id": 2, "conversation_id": 2, "sender_id": 7, "text": "yes",
+				"date_creation": "2023-05-01T12:00:00Z"}}`,
+		},
+		{
+			name:               "invalid ad id",
+			adID:               "abc",
+			body:               request.SendMessageRequest{Text: "hello"},
+			mockBehaviour:      func(service *handlerMock.MockMessageService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   `{"error": "strconv.Atoi: parsing \"abc\": invalid syntax"}`,
+		},
+		{
+			name: "error from service: ErrOwnAdConversation",
+			adID: "3",
+			body: request.SendMessageRequest{Text: "hello"},
+			mockBehaviour: func(serv *handlerMock.MockMessageService) {
+				serv.EXPECT().SendMessage(gomock.Any(), int64(3), int64(0), "hello").Return(nil, service.ErrOwnAdConversation)
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedResponse:   `{"error": "the author can only reply in an existing conversation"}`,
+		},
+		{
+			name: "error from service: ErrAdNotAvailable",
+			adID: "3",
+			body: request.SendMessageRequest{Text: "hello"},
+			mockBehaviour: func(serv *handlerMock.MockMessageService) {
+				serv.EXPECT().SendMessage(gomock.Any(), int64(3), int64(0), "hello").Return(nil, service.ErrAdNotAvailable)
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedResponse:   `{"error": "conversations can only be started about published ads"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := handlerMock.NewMockMessageService(ctrl)
+			tc.mockBehaviour(service)
+
+			handler := NewMessageHandler(service)
+
+			//Test Server
+			rg := gin.New()
+			rg.POST("/ads/:ad_id/messages", handler.sendMessage)
+
+			jsonValue, err := json.Marshal(tc.body)
+			require.Equal(t, err, nil)
+
+			//Test request
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/ads/%s/messages", tc.adID), bytes.NewBuffer(jsonValue))
+
+			//Perform request
+			rg.ServeHTTP(w, r)
+
+			// Assert
+			require.Equal(t, tc.expectedStatusCode, w.Code)
+			require.JSONEq(t, tc.expectedResponse, w.Body.String())
+		})
+	}
+}
+
+func TestMessageHandler_listConversations(t *testing.T) {
+	created := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	conversation := &models.Conversation{ID: 2, AdID: 3, BuyerID: 5, SellerID: 7, DateCreation: created, LastMessageAt: created}
+	tests := []struct {
+		name               string
+		userID             string
+		mockBehaviour      func(service *handlerMock.MockMessageService)
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name:   "successfully list conversations",
+			userID: "7",
+			mockBehaviour: func(service *handlerMock.MockMessageService) {
+				service.EXPECT().ListConversations(gomock.Any(), int64(7)).Return(&models.Inbox{
+					Conversations: []*models.ConversationSummary{{
+						Conversation: conversation,
+						LastMessage:  &models.Message{ID: 1, ConversationID: 2, SenderID: 5, Text: "hello", DateCreation: created},
+						UnreadCount:  1,
+					}},
+					UnreadCount: 1,
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `{"data": [{"id": 2, "ad_id": 3, "buyer_id": 5, "seller_id": 7,
+				"date_creation": "2023-05-01T12:00:00Z", "last_message_at": "2023-05-01T12:00:00Z",
+				"last_message": {"id": 1, "conversation_id": 2, "sender_id": 5, "text": "hello",
+				"date_creation": "2023-05-01T12:00:00Z"}, "unread_count": 1}], "unread_count": 1}`,
+		},
+		{
+			name:   "no conversations",
+			userID: "7",
+			mockBehaviour: func(service *handlerMock.MockMessageService) {
+				service.EXPECT().ListConversations(gomock.Any(), int64(7)).Return(&models.Inbox{}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"data": [], "unread_count": 0}`,
+		},
+		{
+			name:   "error from service: ErrNoAccess",
+			userID: "4",
+			mockBehaviour: func(serv *handlerMock.MockMessageService) {
+				serv.EXPECT().ListConversations(gomock.Any(), int64(4)).
+					Return(nil, service.ErrNoAccess{Err: service.ErrNoAccessConversation})
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedResponse:   `{"error": "you don't have access to the conversation"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := handlerMock.NewMockMessageService(ctrl)
+			tc.mockBehaviour(service)
+
+			handler := NewMessageHandler(service)
+
+			//Test Server
+			rg := gin.New()
+			rg.GET("/users/:user_id/conversations", handler.listConversations)
+
+			//Test request
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/users/%s/conversations", tc.userID), nil)
+
+			//Perform request
+			rg.ServeHTTP(w, r)
+
+			// Assert
+			require.Equal(t, tc.expectedStatusCode, w.Code)
+			require.JSONEq(t, tc.expectedResponse, w.Body.String())
+		})
+	}
+}
+
+func TestMessageHandler_conversation(t *testing.T) {
+	created := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	readAt := created.Add(time.Hour)
+	tests := []struct {
+		name               string
+		method             string
+		path               string
+		mockBehaviour      func(service *handlerMock.MockMessageService)
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name:   "successfully list messages",
+			method: http.MethodGet,
+			path:   "/users/5/conversations/2/messages",
+			mockBehaviour: func(service *handlerMock.MockMessageService) {
+				service.EXPECT().ListMessages(gomock.Any(), int64(5), int64(2)).Return([]*models.Message{
+					{ID: 1, ConversationID: 2, SenderID: 5, Text: "hello", DateCreation: created, ReadAt: readAt},
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `{"data": [{"id": 1, "conversation_id": 2, "sender_id": 5, "text": "hello",
+				"date_creation": "2023-05-01T12:00:00Z", "read_at": "2023-05-01T13:00:00Z"}]}`,
+		},
+		{
+			name:   "error from service: ErrNotParticipant",
+			method: http.MethodGet,
+			path:   "/users/4/conversations/2/messages",
+			mockBehaviour: func(serv *handlerMock.MockMessageService) {
+				serv.EXPECT().ListMessages(gomock.Any(), int64(4), int64(2)).Return(nil, service.ErrNotParticipant)
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedResponse:   `{"error": "only the buyer and the seller can access the conversation"}`,
+		},
+		{
+			name:               "invalid conversation id",
+			method:             http.MethodGet,
+			path:               "/users/5/conversations/abc/messages",
+			mockBehaviour:      func(service *handlerMock.MockMessageService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   `{"error": "strconv.Atoi: parsing \"abc\": invalid syntax"}`,
+		},
+		{
+			name:   "successfully mark conversation read",
+			method: http.MethodPost,
+			path:   "/users/7/conversations/2/read",
+			mockBehaviour: func(service *handlerMock.MockMessageService) {
+				service.EXPECT().MarkConversationRead(gomock.Any(), int64(7), int64(2)).Return(3, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"data": {"marked": 3}}`,
+		},
+		{
+			name:   "error from service",
+			method: http.MethodPost,
+			path:   "/users/7/conversations/2/read",
+			mockBehaviour: func(service *handlerMock.MockMessageService) {
+				service.EXPECT().MarkConversationRead(gomock.Any(), int64(7), int64(2)).
+					Return(0, fmt.Errorf("error from service"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse:   `{"error": "error from service"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := handlerMock.NewMockMessageService(ctrl)
+			tc.mockBehaviour(service)
+
+			handler := NewMessageHandler(service)
+
+			//Test Server
+			rg := gin.New()
+			rg.GET("/users/:user_id/conversations/:conversation_id/messages", handler.listMessages)
+			rg.POST("/users/:user_id/conversations/:conversation_id/read", handler.markConversationRead)
+
+			//Test request
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, tc.path, nil)
+
+			//Perform request
+			rg.ServeHTTP(w, r)
+
+			// Assert
+			require.Equal(t, tc.expectedStatusCode, w.Code)
+			require.JSONEq(t, tc.expectedResponse, w.Body.String())
+		})
+	}
+}
@@ -2,50 +2,86 @@ package middlewares
 
 import (
 	"context"
+	"errors"
 	"net/http"
 
+	"homework10/internal/auth"
 	"homework10/internal/domain/models"
+	"homework10/internal/policy"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gin-gonic/gin/binding"
 )
 
-type userIDRequest struct {
-	UserID int64 `json:"user_id"`
-}
+var errNotRegistered = errors.New("the user is not registered")
 
 type HTTPUserService interface {
 	GetUser(ctx context.Context, userID int64) (*models.User, error)
 }
 
+type TokenParser interface {
+	Parse(token string) (int64, error)
+}
+
 type UserIdentity interface {
 	UserIdentityMiddleware() gin.HandlerFunc
 }
 
 type UserIdentityMiddleware struct {
+	tokens  TokenParser
 	service HTTPUserService
+	policy  *policy.Policy
 }
 
-func NewUserIdentityMiddleware(s HTTPUserService) *UserIdentityMiddleware {
+func NewUserIdentityMiddleware(tokens TokenParser, s HTTPUserService, p *policy.Policy) *UserIdentityMiddleware {
 	return &UserIdentityMiddleware{
+		tokens:  tokens,
 		service: s,
+		policy:  p,
 	}
 }
 
+// UserIdentityMiddleware для маршрутов, которые policy.HTTPEndpoint не объявляет публичными, проверяет токен
+// из заголовка Authorization, кладёт ID и роль пользователя в контекст запроса и отказывает ролям,
+// которым действие маршрута запрещено. На публичных маршрутах токен необязателен: с действительным токеном
+// сервис знает, кто вызывает маршрут (например, автор видит в ленте свои неопубликованные объявления),
+// а недействительный токен не мешает запросу, как и его отсутствие
 func (a *UserIdentityMiddleware) UserIdentityMiddleware() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		var body userIDRequest
-		if err := ctx.ShouldBindBodyWith(&body, binding.JSON); err != nil {
-			ctx.Status(http.StatusBadRequest)
-			ctx.Abort()
+		endpoint := policy.HTTPEndpoint(ctx.Request.Method, ctx.FullPath())
+		reqCtx, err := a.identify(ctx)
+		if endpoint.Public {
+			if err == nil {
+				ctx.Request = ctx.Request.WithContext(reqCtx)
+			}
+			ctx.Next()
 			return
 		}
-		_, err := a.service.GetUser(ctx, body.UserID)
 		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "the user is not registered"})
-			ctx.Abort()
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		if !a.policy.Permits(auth.RoleFromContext(reqCtx), endpoint) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": policy.ErrDenied.Error()})
 			return
 		}
+		ctx.Request = ctx.Request.WithContext(reqCtx)
 		ctx.Next()
 	}
 }
+
+// identify возвращает контекст запроса с ID и ролью пользователя из токена
+func (a *UserIdentityMiddleware) identify(ctx *gin.Context) (context.Context, error) {
+	token, err := auth.BearerToken(ctx.GetHeader("Authorization"))
+	if err != nil {
+		return nil, err
+	}
+	userID, err := a.tokens.Parse(token)
+	if err != nil {
+		return nil, err
+	}
+	user, err := a.service.GetUser(ctx, userID)
+	if err != nil {
+		return nil, errNotRegistered
+	}
+	return auth.WithRole(auth.WithUserID(ctx.Request.Context(), userID), user.Role), nil
+}
@@ -0,0 +1,119 @@
+package middlewares
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+
+	"homework10/internal/idempotency"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IdempotencyStore interface {
+	Begin(scope string, key string, fingerprint string) (any, error)
+	Complete(scope string, key string, response any)
+	Release(scope string, key string)
+}
+
+// recordedResponse ответ на HTTP-запрос с ключом идемпотентности
+type recordedResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// recordingWriter копирует тело ответа, чтобы его можно было сохранить
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// IdempotencyMiddleware для маршрутов из idempotency.HTTPRoute, вызванных с заголовком Idempotency-Key,
+// сохраняет ответ и отдаёт его повторам запроса вместо выполнения. Тот же ключ с другими методом, путём
+// или телом получает 422, повтор во время выполнения первого запроса - 409. Ответы 5xx не сохраняются.
+// Тело больше idempotency.MaxBodySize получает 413. Должна стоять после UserIdentityMiddleware: ключи
+// разных пользователей не пересекаются
+func IdempotencyMiddleware(store IdempotencyStore) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(idempotency.HTTPHeader)
+		if key == "" || !idempotency.HTTPRoute(ctx.Request.Method, ctx.FullPath()) {
+			ctx.Next()
+			return
+		}
+		if err := idempotency.CheckKey(key); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, idempotency.MaxBodySize))
+		if err != nil {
+			code := http.StatusBadRequest
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				code = http.StatusRequestEntityTooLarge
+			}
+			ctx.AbortWithStatusJSON(code, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		scope := idempotency.Scope(ctx.Request.Context(), ctx.ClientIP(), ctx.Request.Method+" "+ctx.FullPath())
+		fingerprint := idempotency.Fingerprint([]byte(ctx.Request.Method), []byte(ctx.Request.URL.Path), body)
+		stored, err := store.Begin(scope, key, fingerprint)
+		switch {
+		case errors.Is(err, idempotency.ErrKeyReused):
+			ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		case stored != nil:
+			replay(ctx, stored.(*recordedResponse))
+			return
+		}
+
+		completed := false
+		defer func() {
+			// обработчик упал: повтор должен выполниться заново, а не ждать истечения ключа
+			if !completed {
+				store.Release(scope, key)
+			}
+		}()
+		writer := &recordingWriter{ResponseWriter: ctx.Writer}
+		ctx.Writer = writer
+		ctx.Next()
+		ctx.Writer = writer.ResponseWriter
+
+		completed = true
+		if writer.Status() >= http.StatusInternalServerError {
+			store.Release(scope, key)
+			return
+		}
+		store.Complete(scope, key, &recordedResponse{
+			status: writer.Status(),
+			header: writer.Header().Clone(),
+			body:   writer.body.Bytes(),
+		})
+	}
+}
+
+func replay(ctx *gin.Context, response *recordedResponse) {
+	for name, values := range response.header {
+		ctx.Writer.Header()[name] = values
+	}
+	ctx.Header(idempotency.ReplayedHeader, "true")
+	ctx.Writer.WriteHeader(response.status)
+	_, _ = ctx.Writer.Write(response.body)
+	ctx.Abort()
+}
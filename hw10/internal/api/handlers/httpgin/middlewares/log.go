@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
+	"homework10/internal/tracing"
 )
 
 func LoggingMiddleware() gin.HandlerFunc {
@@ -25,13 +26,13 @@ func LoggingMiddleware() gin.HandlerFunc {
 
 		clientIP := ctx.ClientIP()
 
-		log.WithFields(log.Fields{
+		log.WithFields(tracing.LogFields(ctx.Request.Context(), log.Fields{
 			"METHOD":    reqMethod,
 			"URI":       reqUri,
 			"STATUS":    statusCode,
 			"LATENCY":   latencyTime,
 			"CLIENT_IP": clientIP,
-		}).Info("HTTP REQUEST")
+		})).Info("HTTP REQUEST")
 
 		ctx.Next()
 	}
@@ -0,0 +1,21 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type HTTPMetrics interface {
+	TrackHTTP(method string, route string) func(status int)
+}
+
+// MetricsMiddleware учитывает запросы, их длительность и число выполняющихся по маршрутам gin,
+// а не по фактическим путям, чтобы ID в пути не порождали новые ряды метрик
+func MetricsMiddleware(metrics HTTPMetrics) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		done := metrics.TrackHTTP(ctx.Request.Method, ctx.FullPath())
+		defer func() {
+			done(ctx.Writer.Status())
+		}()
+		ctx.Next()
+	}
+}
@@ -0,0 +1,30 @@
+package middlewares
+
+import (
+	"net/http"
+	"time"
+
+	"homework10/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RateLimiter interface {
+	Allow(route string, client string) (bool, time.Duration)
+}
+
+// RateLimitMiddleware ограничивает частоту запросов к маршруту для каждого клиента: пользователя,
+// если UserIdentityMiddleware его аутентифицировала, иначе IP-адреса. Превысившему лимит отвечает 429
+// с заголовком Retry-After
+func RateLimitMiddleware(limiter RateLimiter) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		route := ctx.Request.Method + " " + ctx.FullPath()
+		allowed, retryAfter := limiter.Allow(route, ratelimit.ClientKey(ctx.Request.Context(), ctx.ClientIP()))
+		if !allowed {
+			ctx.Header("Retry-After", ratelimit.RetryAfterSeconds(retryAfter))
+			ctx.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": ratelimit.ErrLimited.Error()})
+			return
+		}
+		ctx.Next()
+	}
+}
@@ -0,0 +1,47 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	"homework10/internal/tracing"
+)
+
+// TracingMiddleware продолжает трассировку из заголовка traceparent (или начинает новую) спаном запроса
+// с именем по маршруту gin и кладёт его в контекст запроса, откуда спан берут сервисы и логи.
+// Должна стоять первой, чтобы спан охватил остальные middleware
+func TracingMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		req := ctx.Request
+		parent := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+
+		route := ctx.FullPath()
+		name := "HTTP " + req.Method
+		if route != "" {
+			name = req.Method + " " + route
+		}
+		spanCtx, span := tracing.Start(parent, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPMethodKey.String(req.Method),
+				semconv.HTTPTargetKey.String(req.URL.RequestURI()),
+				semconv.HTTPRouteKey.String(route),
+			),
+		)
+		defer span.End()
+		ctx.Request = req.WithContext(spanCtx)
+
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
@@ -7,6 +7,8 @@ package handlerMock
 import (
 	context "context"
 	models "homework10/internal/domain/models"
+	events "homework10/internal/events"
+	io "io"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -35,48 +37,63 @@ func (m *MockAdService) EXPECT() *MockAdServiceMockRecorder {
 	return m.recorder
 }
 
+// ApproveAd mocks base method.
+func (m *MockAdService) ApproveAd(ctx context.Context, adID, expectedVersion int64) (*models.Ad, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveAd", ctx, adID, expectedVersion)
+	ret0, _ := ret[0].(*models.Ad)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApproveAd indicates an expected call of ApproveAd.
+func (mr *MockAdServiceMockRecorder) ApproveAd(ctx, adID, expectedVersion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveAd", reflect.TypeOf((*MockAdService)(nil).ApproveAd), ctx, adID, expectedVersion)
+}
+
 // ChangeAdStatus mocks base method.
-func (m *MockAdService) ChangeAdStatus(ctx context.Context, adID, userID int64, published bool) (*models.Ad, error) {
+func (m *MockAdService) ChangeAdStatus(ctx context.Context, adID int64, published bool, expectedVersion int64) (*models.Ad, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ChangeAdStatus", ctx, adID, userID, published)
+	ret := m.ctrl.Call(m, "ChangeAdStatus", ctx, adID, published, expectedVersion)
 	ret0, _ := ret[0].(*models.Ad)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ChangeAdStatus indicates an expected call of ChangeAdStatus.
-func (mr *MockAdServiceMockRecorder) ChangeAdStatus(ctx, adID, userID, published interface{}) *gomock.Call {
+func (mr *MockAdServiceMockRecorder) ChangeAdStatus(ctx, adID, published, expectedVersion interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeAdStatus", reflect.TypeOf((*MockAdService)(nil).ChangeAdStatus), ctx, adID, userID, published)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeAdStatus", reflect.TypeOf((*MockAdService)(nil).ChangeAdStatus), ctx, adID, published, expectedVersion)
 }
 
 // CreateAd mocks base method.
-func (m *MockAdService) CreateAd(ctx context.Context, title, text string, authorID int64) (*models.Ad, error) {
+func (m *MockAdService) CreateAd(ctx context.Context, title, text string, details models.AdDetails) (*models.Ad, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateAd", ctx, title, text, authorID)
+	ret := m.ctrl.Call(m, "CreateAd", ctx, title, text, details)
 	ret0, _ := ret[0].(*models.Ad)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateAd indicates an expected call of CreateAd.
-func (mr *MockAdServiceMockRecorder) CreateAd(ctx, title, text, authorID interface{}) *gomock.Call {
+func (mr *MockAdServiceMockRecorder) CreateAd(ctx, title, text, details interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAd", reflect.TypeOf((*MockAdService)(nil).CreateAd), ctx, title, text, authorID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAd", reflect.TypeOf((*MockAdService)(nil).CreateAd), ctx, title, text, details)
 }
 
 // DeleteAd mocks base method.
-func (m *MockAdService) DeleteAd(ctx context.Context, adID, userID int64) error {
+func (m *MockAdService) DeleteAd(ctx context.Context, adID int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteAd", ctx, adID, userID)
+	ret := m.ctrl.Call(m, "DeleteAd", ctx, adID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteAd indicates an expected call of DeleteAd.
-func (mr *MockAdServiceMockRecorder) DeleteAd(ctx, adID, userID interface{}) *gomock.Call {
+func (mr *MockAdServiceMockRecorder) DeleteAd(ctx, adID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAd", reflect.TypeOf((*MockAdService)(nil).DeleteAd), ctx, adID, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAd", reflect.TypeOf((*MockAdService)(nil).DeleteAd), ctx, adID)
 }
 
 // GetAdByID mocks base method.
@@ -94,47 +111,212 @@ func (mr *MockAdServiceMockRecorder) GetAdByID(ctx, adID interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAdByID", reflect.TypeOf((*MockAdService)(nil).GetAdByID), ctx, adID)
 }
 
-// GetAdsByTitle mocks base method.
-func (m *MockAdService) GetAdsByTitle(ctx context.Context, text string) ([]*models.Ad, error) {
+// ListAdRevisions mocks base method.
+func (m *MockAdService) ListAdRevisions(ctx context.Context, adID int64) ([]*models.AdRevision, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAdsByTitle", ctx, text)
-	ret0, _ := ret[0].([]*models.Ad)
+	ret := m.ctrl.Call(m, "ListAdRevisions", ctx, adID)
+	ret0, _ := ret[0].([]*models.AdRevision)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAdsByTitle indicates an expected call of GetAdsByTitle.
-func (mr *MockAdServiceMockRecorder) GetAdsByTitle(ctx, text interface{}) *gomock.Call {
+// ListAdRevisions indicates an expected call of ListAdRevisions.
+func (mr *MockAdServiceMockRecorder) ListAdRevisions(ctx, adID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAdsByTitle", reflect.TypeOf((*MockAdService)(nil).GetAdsByTitle), ctx, text)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAdRevisions", reflect.TypeOf((*MockAdService)(nil).ListAdRevisions), ctx, adID)
 }
 
 // ListAds mocks base method.
-func (m *MockAdService) ListAds(ctx context.Context, published, userIDRaw, dateCreationRaw string) ([]*models.Ad, error) {
+func (m *MockAdService) ListAds(ctx context.Context, filter models.AdFilter, page models.PageParams) (*models.AdsPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListAds", ctx, published, userIDRaw, dateCreationRaw)
-	ret0, _ := ret[0].([]*models.Ad)
+	ret := m.ctrl.Call(m, "ListAds", ctx, filter, page)
+	ret0, _ := ret[0].(*models.AdsPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ListAds indicates an expected call of ListAds.
-func (mr *MockAdServiceMockRecorder) ListAds(ctx, published, userIDRaw, dateCreationRaw interface{}) *gomock.Call {
+func (mr *MockAdServiceMockRecorder) ListAds(ctx, filter, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAds", reflect.TypeOf((*MockAdService)(nil).ListAds), ctx, filter, page)
+}
+
+// ListCategories mocks base method.
+func (m *MockAdService) ListCategories(ctx context.Context) ([]models.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCategories", ctx)
+	ret0, _ := ret[0].([]models.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCategories indicates an expected call of ListCategories.
+func (mr *MockAdServiceMockRecorder) ListCategories(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAds", reflect.TypeOf((*MockAdService)(nil).ListAds), ctx, published, userIDRaw, dateCreationRaw)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCategories", reflect.TypeOf((*MockAdService)(nil).ListCategories), ctx)
+}
+
+// ListModerationQueue mocks base method.
+func (m *MockAdService) ListModerationQueue(ctx context.Context) ([]*models.Ad, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListModerationQueue", ctx)
+	ret0, _ := ret[0].([]*models.Ad)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListModerationQueue indicates an expected call of ListModerationQueue.
+func (mr *MockAdServiceMockRecorder) ListModerationQueue(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListModerationQueue", reflect.TypeOf((*MockAdService)(nil).ListModerationQueue), ctx)
+}
+
+// OpenAdImage mocks base method.
+func (m *MockAdService) OpenAdImage(ctx context.Context, adID, imageID int64, thumbnail bool) (*models.AdImage, io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenAdImage", ctx, adID, imageID, thumbnail)
+	ret0, _ := ret[0].(*models.AdImage)
+	ret1, _ := ret[1].(io.ReadCloser)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// OpenAdImage indicates an expected call of OpenAdImage.
+func (mr *MockAdServiceMockRecorder) OpenAdImage(ctx, adID, imageID, thumbnail interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenAdImage", reflect.TypeOf((*MockAdService)(nil).OpenAdImage), ctx, adID, imageID, thumbnail)
+}
+
+// RejectAd mocks base method.
+func (m *MockAdService) RejectAd(ctx context.Context, adID int64, reason string, expectedVersion int64) (*models.Ad, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectAd", ctx, adID, reason, expectedVersion)
+	ret0, _ := ret[0].(*models.Ad)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RejectAd indicates an expected call of RejectAd.
+func (mr *MockAdServiceMockRecorder) RejectAd(ctx, adID, reason, expectedVersion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectAd", reflect.TypeOf((*MockAdService)(nil).RejectAd), ctx, adID, reason, expectedVersion)
+}
+
+// RestoreAd mocks base method.
+func (m *MockAdService) RestoreAd(ctx context.Context, adID int64) (*models.Ad, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreAd", ctx, adID)
+	ret0, _ := ret[0].(*models.Ad)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreAd indicates an expected call of RestoreAd.
+func (mr *MockAdServiceMockRecorder) RestoreAd(ctx, adID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreAd", reflect.TypeOf((*MockAdService)(nil).RestoreAd), ctx, adID)
+}
+
+// RestoreAdRevision mocks base method.
+func (m *MockAdService) RestoreAdRevision(ctx context.Context, adID, number int64) (*models.Ad, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreAdRevision", ctx, adID, number)
+	ret0, _ := ret[0].(*models.Ad)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreAdRevision indicates an expected call of RestoreAdRevision.
+func (mr *MockAdServiceMockRecorder) RestoreAdRevision(ctx, adID, number interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreAdRevision", reflect.TypeOf((*MockAdService)(nil).RestoreAdRevision), ctx, adID, number)
+}
+
+// SearchAds mocks base method.
+func (m *MockAdService) SearchAds(ctx context.Context, query string, page models.PageParams) (*models.SearchPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchAds", ctx, query, page)
+	ret0, _ := ret[0].(*models.SearchPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchAds indicates an expected call of SearchAds.
+func (mr *MockAdServiceMockRecorder) SearchAds(ctx, query, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchAds", reflect.TypeOf((*MockAdService)(nil).SearchAds), ctx, query, page)
+}
+
+// SubmitAd mocks base method.
+func (m *MockAdService) SubmitAd(ctx context.Context, adID, expectedVersion int64) (*models.Ad, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubmitAd", ctx, adID, expectedVersion)
+	ret0, _ := ret[0].(*models.Ad)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubmitAd indicates an expected call of SubmitAd.
+func (mr *MockAdServiceMockRecorder) SubmitAd(ctx, adID, expectedVersion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubmitAd", reflect.TypeOf((*MockAdService)(nil).SubmitAd), ctx, adID, expectedVersion)
 }
 
 // UpdateAd mocks base method.
-func (m *MockAdService) UpdateAd(ctx context.Context, adID, userID int64, title, text string) (*models.Ad, error) {
+func (m *MockAdService) UpdateAd(ctx context.Context, adID int64, title, text string, details models.AdDetails, expectedVersion int64) (*models.Ad, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateAd", ctx, adID, userID, title, text)
+	ret := m.ctrl.Call(m, "UpdateAd", ctx, adID, title, text, details, expectedVersion)
 	ret0, _ := ret[0].(*models.Ad)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UpdateAd indicates an expected call of UpdateAd.
-func (mr *MockAdServiceMockRecorder) UpdateAd(ctx, adID, userID, title, text interface{}) *gomock.Call {
+func (mr *MockAdServiceMockRecorder) UpdateAd(ctx, adID, title, text, details, expectedVersion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAd", reflect.TypeOf((*MockAdService)(nil).UpdateAd), ctx, adID, title, text, details, expectedVersion)
+}
+
+// UploadAdImage mocks base method.
+func (m *MockAdService) UploadAdImage(ctx context.Context, adID int64, r io.Reader, expectedVersion int64) (*models.Ad, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadAdImage", ctx, adID, r, expectedVersion)
+	ret0, _ := ret[0].(*models.Ad)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadAdImage indicates an expected call of UploadAdImage.
+func (mr *MockAdServiceMockRecorder) UploadAdImage(ctx, adID, r, expectedVersion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadAdImage", reflect.TypeOf((*MockAdService)(nil).UploadAdImage), ctx, adID, r, expectedVersion)
+}
+
+// WatchAds mocks base method.
+func (m *MockAdService) WatchAds(ctx context.Context, filter models.AdFilter) *events.Subscription {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchAds", ctx, filter)
+	ret0, _ := ret[0].(*events.Subscription)
+	return ret0
+}
+
+// WatchAds indicates an expected call of WatchAds.
+func (mr *MockAdServiceMockRecorder) WatchAds(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchAds", reflect.TypeOf((*MockAdService)(nil).WatchAds), ctx, filter)
+}
+
+// WatchAdsSince mocks base method.
+func (m *MockAdService) WatchAdsSince(ctx context.Context, filter models.AdFilter, lastEventID int64) (*events.Subscription, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchAdsSince", ctx, filter, lastEventID)
+	ret0, _ := ret[0].(*events.Subscription)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// WatchAdsSince indicates an expected call of WatchAdsSince.
+func (mr *MockAdServiceMockRecorder) WatchAdsSince(ctx, filter, lastEventID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAd", reflect.TypeOf((*MockAdService)(nil).UpdateAd), ctx, adID, userID, title, text)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchAdsSince", reflect.TypeOf((*MockAdService)(nil).WatchAdsSince), ctx, filter, lastEventID)
 }
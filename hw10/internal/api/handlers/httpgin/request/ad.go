@@ -1,22 +1,34 @@
 package request
 
 type CreateAdRequest struct {
-	Title  string `json:"title"`
-	Text   string `json:"text"`
-	UserID int64  `json:"user_id"`
+	Title string `json:"title"`
+	Text  string `json:"text"`
+	AdDetailsRequest
+}
+
+// AdDetailsRequest характеристики объявления; не переданные поля остаются пустыми
+type AdDetailsRequest struct {
+	Price      *PriceRequest `json:"price"`
+	CategoryID string        `json:"category_id"`
+	City       string        `json:"city"`
+}
+
+// PriceRequest цена в минимальных единицах валюты (копейках, центах) и код валюты по ISO 4217
+type PriceRequest struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
 }
 
 type ChangeAdStatusRequest struct {
-	Published bool  `json:"published"`
-	UserID    int64 `json:"user_id"`
+	Published bool `json:"published"`
 }
 
 type UpdateAdRequest struct {
-	Title  string `json:"title"`
-	Text   string `json:"text"`
-	UserID int64  `json:"user_id"`
+	Title string `json:"title"`
+	Text  string `json:"text"`
+	AdDetailsRequest
 }
 
-type DeleteAdRequest struct {
-	UserID int64 `json:"user_id"`
+type RejectAdRequest struct {
+	Reason string `json:"reason"`
 }
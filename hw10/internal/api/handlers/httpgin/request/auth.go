@@ -0,0 +1,6 @@
+package request
+
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
@@ -0,0 +1,7 @@
+package request
+
+type SendMessageRequest struct {
+	Text string `json:"text"`
+	// ConversationID беседа, в которую отвечают; без неё покупатель пишет автору в свою беседу
+	ConversationID int64 `json:"conversation_id"`
+}
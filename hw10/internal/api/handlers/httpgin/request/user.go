@@ -3,9 +3,23 @@ package request
 type CreateUserRequest struct {
 	NickName string `json:"nickname"`
 	Email    string `json:"email"`
+	Password string `json:"password"`
 }
 
 type UpdateUserRequest struct {
 	NickName string `json:"nickname"`
 	Email    string `json:"email"`
 }
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
+}
+
+type SetUserRoleRequest struct {
+	Role string `json:"role"`
+}
+
+type RestoreUserRequest struct {
+	Password string `json:"password"`
+}
@@ -8,4 +8,49 @@ type AdResponse struct {
 	Published    bool   `json:"published"`
 	DateCreation string `json:"date_creation"`
 	DateUpdate   string `json:"date_update"`
+	State        string `json:"state"`
+	// RejectionReason заполнена только у отклонённых модератором объявлений
+	RejectionReason string          `json:"rejection_reason,omitempty"`
+	Images          []ImageResponse `json:"images"`
+	// Price, CategoryID и City передаются, только если указаны у объявления
+	Price      *PriceResponse `json:"price,omitempty"`
+	CategoryID string         `json:"category_id,omitempty"`
+	City       string         `json:"city,omitempty"`
+}
+
+type PriceResponse struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+type CategoryResponse struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	ParentID string `json:"parent_id,omitempty"`
+}
+
+type FacetCountResponse struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+type FacetsResponse struct {
+	Categories []FacetCountResponse `json:"categories"`
+	Cities     []FacetCountResponse `json:"cities"`
+}
+
+type ImageResponse struct {
+	ID           int64  `json:"id"`
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	ContentType  string `json:"content_type"`
+	Size         int64  `json:"size"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+}
+
+type SearchAdResponse struct {
+	AdResponse
+	Score   float64 `json:"score"`
+	Snippet string  `json:"snippet"`
 }
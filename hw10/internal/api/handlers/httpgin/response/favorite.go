@@ -0,0 +1,6 @@
+package response
+
+type FavoriteAdResponse struct {
+	AdResponse
+	AddedAt string `json:"added_at"`
+}
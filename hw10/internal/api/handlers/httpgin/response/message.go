@@ -0,0 +1,22 @@
+package response
+
+type MessageResponse struct {
+	ID             int64  `json:"id"`
+	ConversationID int64  `json:"conversation_id"`
+	SenderID       int64  `json:"sender_id"`
+	Text           string `json:"text"`
+	DateCreation   string `json:"date_creation"`
+	// ReadAt передаётся, когда собеседник прочитал сообщение
+	ReadAt string `json:"read_at,omitempty"`
+}
+
+type ConversationResponse struct {
+	ID            int64           `json:"id"`
+	AdID          int64           `json:"ad_id"`
+	BuyerID       int64           `json:"buyer_id"`
+	SellerID      int64           `json:"seller_id"`
+	DateCreation  string          `json:"date_creation"`
+	LastMessageAt string          `json:"last_message_at"`
+	LastMessage   MessageResponse `json:"last_message"`
+	UnreadCount   int             `json:"unread_count"`
+}
@@ -0,0 +1,20 @@
+package response
+
+type FieldChangeResponse struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+type AdRevisionResponse struct {
+	AdID         int64                 `json:"ad_id"`
+	Number       int64                 `json:"number"`
+	Type         string                `json:"type"`
+	AuthorID     int64                 `json:"author_id"`
+	Date         string                `json:"date"`
+	Title        string                `json:"title"`
+	Text         string                `json:"text"`
+	Published    bool                  `json:"published"`
+	Changes      []FieldChangeResponse `json:"changes"`
+	RestoredFrom int64                 `json:"restored_from,omitempty"`
+}
@@ -0,0 +1,7 @@
+package response
+
+type TrashedAdResponse struct {
+	AdResponse
+	DeletedAt string `json:"deleted_at"`
+	PurgeAt   string `json:"purge_at"`
+}
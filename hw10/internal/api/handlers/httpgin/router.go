@@ -16,16 +16,46 @@ type Router interface {
 	BasePrefix() string
 }
 
-func MakeRoutes(apiVersion ApiVersion, routers ...Router) *gin.Engine {
+// Options необязательные middleware; nil отключает соответствующую
+type Options struct {
+	// Metrics учитывает все запросы к серверу
+	Metrics middlewares.HTTPMetrics
+	// Limiter ограничивает частоту запросов к маршрутам API
+	Limiter middlewares.RateLimiter
+	// Idempotency хранит ответы на запросы с ключом идемпотентности
+	Idempotency middlewares.IdempotencyStore
+}
+
+// MakeRoutes собирает маршруты routers; userIdentity проверяет доступ ко всем маршрутам API
+// по таблице policy.HTTPEndpoint
+func MakeRoutes(apiVersion ApiVersion, userIdentity middlewares.UserIdentity, opts Options, routers ...Router) *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.New()
+	// по умолчанию ни один адрес не считается прокси и ClientIP - адрес соединения: иначе клиент подделает
+	// X-Forwarded-For и обойдёт лимит по адресу. Прокси перед сервером задаются SetTrustedProxies
+	// у возвращённого *gin.Engine
+	_ = r.SetTrustedProxies(nil) // для nil ошибки не бывает
+	// данные, положенные middleware в контекст запроса, доступны сервисам через *gin.Context
+	r.ContextWithFallback = true
 
+	// спан запроса начинается первым, чтобы его ID попал в логи
+	r.Use(middlewares.TracingMiddleware())
+	if opts.Metrics != nil {
+		r.Use(middlewares.MetricsMiddleware(opts.Metrics))
+	}
 	r.Use(
 		middlewares.LoggingMiddleware(),
 		middlewares.RecoverMiddleware(),
 	)
 
-	apiVersionGroup := r.Group(string(apiVersion))
+	apiVersionGroup := r.Group(string(apiVersion), userIdentity.UserIdentityMiddleware())
+	if opts.Limiter != nil {
+		// лимит проверяется после аутентификации, чтобы считать запросы пользователя, а не его адреса
+		apiVersionGroup.Use(middlewares.RateLimitMiddleware(opts.Limiter))
+	}
+	if opts.Idempotency != nil {
+		apiVersionGroup.Use(middlewares.IdempotencyMiddleware(opts.Idempotency))
+	}
 	for _, router := range routers {
 		router.AddRoutes(apiVersionGroup.Group(router.BasePrefix()))
 	}
@@ -14,10 +14,17 @@ import (
 
 //go:generate mockgen -source=./user.go -destination=../mock/user.go -package=handlermock UserService
 type UserService interface {
-	CreateUser(ctx context.Context, nickName string, email string) (*models.User, error)
+	CreateUser(ctx context.Context, nickName string, email string, password string) (*models.User, error)
 	UpdateUser(ctx context.Context, userID int64, nickName string, email string) (*models.User, error)
+	ChangePassword(ctx context.Context, userID int64, oldPassword string, newPassword string) error
+	SetUserRole(ctx context.Context, userID int64, role models.Role) (*models.User, error)
 	GetUser(ctx context.Context, userID int64) (*models.User, error)
 	DeleteUser(ctx context.Context, userID int64) error
+	RestoreUser(ctx context.Context, userID int64, password string) (*models.User, error)
+	ListTrash(ctx context.Context, userID int64) ([]*models.TrashedAd, error)
+	AddFavorite(ctx context.Context, userID int64, adID int64) (*models.Ad, error)
+	RemoveFavorite(ctx context.Context, userID int64, adID int64) error
+	ListFavorites(ctx context.Context, userID int64) ([]*models.FavoriteAd, error)
 }
 
 type UserHandler struct {
@@ -25,14 +32,25 @@ type UserHandler struct {
 }
 
 func NewUserHandler(service UserService) *UserHandler {
-	return &UserHandler{service: service}
+	return &UserHandler{
+		service: service,
+	}
 }
 
 func (h *UserHandler) AddRoutes(rg *gin.RouterGroup) {
-	rg.GET("/:user_id", h.getUser)       // Метод для получения пользователя (user) по ID (user_id)
-	rg.POST("", h.createUser)            // Метод для создания пользователя (user)
-	rg.PUT("/:user_id", h.updateUser)    // Метод для обновления никнейма (Nickname) или почты (Email) пользователя
-	rg.DELETE("/:user_id", h.deleteUser) // Метод для удаления пользователя (user) по его ID (user_id)и
+	rg.GET("/:user_id", h.getUser)                 // Метод для получения пользователя (user) по ID (user_id)
+	rg.POST("", h.createUser)                      // Метод для создания пользователя (user)
+	rg.PUT("/:user_id", h.updateUser)              // Метод для обновления никнейма (Nickname) или почты (Email) пользователя
+	rg.DELETE("/:user_id", h.deleteUser)           // Метод для удаления пользователя (user) по его ID (user_id)и
+	rg.PUT("/:user_id/password", h.changePassword) // Метод для смены пароля (password) пользователя
+	rg.PUT("/:user_id/role", h.setUserRole)        // Метод для смены роли (role) пользователя администратором
+
+	rg.GET("/:user_id/trash", h.listTrash)      // Метод для получения удалённых объявлений пользователя
+	rg.POST("/:user_id/restore", h.restoreUser) // Метод для восстановления удалённого пользователя по паролю (password)
+
+	rg.GET("/:user_id/favorites", h.listFavorites)            // Метод для получения закладок пользователя
+	rg.POST("/:user_id/favorites/:ad_id", h.addFavorite)      // Метод для добавления объявления (ad) в закладки
+	rg.DELETE("/:user_id/favorites/:ad_id", h.removeFavorite) // Метод для удаления объявления (ad) из закладок
 }
 
 func (h *UserHandler) BasePrefix() string {
@@ -48,7 +66,7 @@ func (h *UserHandler) getUser(ctx *gin.Context) {
 	}
 	user, err := h.service.GetUser(ctx, int64(userID))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, NewErrResponse(err))
+		errorResponse(ctx, err)
 		return
 	}
 	ctx.IndentedJSON(http.StatusOK, mapper.UserSuccessResponse(user))
@@ -60,9 +78,9 @@ func (h *UserHandler) createUser(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
 		return
 	}
-	user, err := h.service.CreateUser(ctx, reqBody.NickName, reqBody.Email)
+	user, err := h.service.CreateUser(ctx, reqBody.NickName, reqBody.Email, reqBody.Password)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, NewErrResponse(err))
+		errorResponse(ctx, err)
 		return
 	}
 	ctx.IndentedJSON(http.StatusOK, mapper.UserSuccessResponse(user))
@@ -82,7 +100,46 @@ func (h *UserHandler) updateUser(ctx *gin.Context) {
 	}
 	user, err := h.service.UpdateUser(ctx, int64(userID), reqBody.NickName, reqBody.Email)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, NewErrResponse(err))
+		errorResponse(ctx, err)
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, mapper.UserSuccessResponse(user))
+}
+
+func (h *UserHandler) changePassword(ctx *gin.Context) {
+	var reqBody request.ChangePasswordRequest
+	if err := ctx.BindJSON(&reqBody); err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	userIDRaw := ctx.Param("user_id")
+	userID, err := strconv.Atoi(userIDRaw)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	err = h.service.ChangePassword(ctx, int64(userID), reqBody.OldPassword, reqBody.NewPassword)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, gin.H{"success": "User #" + userIDRaw + " password changed"})
+}
+
+func (h *UserHandler) setUserRole(ctx *gin.Context) {
+	var reqBody request.SetUserRoleRequest
+	if err := ctx.BindJSON(&reqBody); err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	userID, err := strconv.Atoi(ctx.Param("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	user, err := h.service.SetUserRole(ctx, int64(userID), models.Role(reqBody.Role))
+	if err != nil {
+		errorResponse(ctx, err)
 		return
 	}
 	ctx.IndentedJSON(http.StatusOK, mapper.UserSuccessResponse(user))
@@ -97,8 +154,95 @@ func (h *UserHandler) deleteUser(ctx *gin.Context) {
 	}
 	err = h.service.DeleteUser(ctx, int64(userID))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, NewErrResponse(err))
+		errorResponse(ctx, err)
 		return
 	}
 	ctx.IndentedJSON(http.StatusOK, gin.H{"success": "User #" + userIDRaw + " deleted"})
 }
+
+func (h *UserHandler) restoreUser(ctx *gin.Context) {
+	var reqBody request.RestoreUserRequest
+	if err := ctx.BindJSON(&reqBody); err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	userID, err := strconv.Atoi(ctx.Param("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	user, err := h.service.RestoreUser(ctx, int64(userID), reqBody.Password)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, mapper.UserSuccessResponse(user))
+}
+
+func (h *UserHandler) listTrash(ctx *gin.Context) {
+	userID, err := strconv.Atoi(ctx.Param("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	trash, err := h.service.ListTrash(ctx, int64(userID))
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, mapper.TrashSuccessResponse(trash))
+}
+
+func (h *UserHandler) listFavorites(ctx *gin.Context) {
+	userID, err := strconv.Atoi(ctx.Param("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return
+	}
+	favorites, err := h.service.ListFavorites(ctx, int64(userID))
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, mapper.FavoritesSuccessResponse(favorites))
+}
+
+func (h *UserHandler) addFavorite(ctx *gin.Context) {
+	userID, adID, ok := favoriteParams(ctx)
+	if !ok {
+		return
+	}
+	ad, err := h.service.AddFavorite(ctx, userID, adID)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, mapper.AdSuccessResponse(ad))
+}
+
+func (h *UserHandler) removeFavorite(ctx *gin.Context) {
+	userID, adID, ok := favoriteParams(ctx)
+	if !ok {
+		return
+	}
+	if err := h.service.RemoveFavorite(ctx, userID, adID); err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	ctx.IndentedJSON(http.StatusOK, gin.H{"success": "Ad #" + ctx.Param("ad_id") + " removed from favorites"})
+}
+
+// favoriteParams разбирает user_id и ad_id маршрутов закладок; при ошибке ответ уже отправлен
+func favoriteParams(ctx *gin.Context) (userID int64, adID int64, ok bool) {
+	user, err := strconv.Atoi(ctx.Param("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return 0, 0, false
+	}
+	ad, err := strconv.Atoi(ctx.Param("ad_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, NewErrResponse(err))
+		return 0, 0, false
+	}
+	return int64(user), int64(ad), true
+}
@@ -8,9 +8,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"homework10/internal/api/handlers/httpgin/mock"
+	"homework10/internal/domain"
 	"homework10/internal/domain/models"
+	"homework10/internal/service"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
@@ -45,7 +48,8 @@ func TestUserHandler_getUser(t *testing.T) {
 					"data": {
 						"id": 1,
 						"nickname": "test nickname",
-						"email": "test email"
+						"email": "test email",
+						"role": ""
 					}
 				}
 				`,
@@ -57,6 +61,15 @@ func TestUserHandler_getUser(t *testing.T) {
 			expectedStatusCode: http.StatusBadRequest,
 			expectedResponse:   `{"error": "strconv.Atoi: parsing \"invalid_user_id_1\": invalid syntax"}`,
 		},
+		{
+			name:   "error from service: ErrUserNotExist",
+			userID: "7",
+			mockBehaviour: func(service *handlerMock.MockUserService) {
+				service.EXPECT().GetUser(gomock.Any(), int64(7)).Return(nil, domain.ErrUserNotExist)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse:   `{"error": "the user does not exist"}`,
+		},
 		{
 			name:   "error from service",
 			userID: "1",
@@ -111,9 +124,10 @@ func TestUserHandler_createUser(t *testing.T) {
 			user: request.CreateUserRequest{
 				NickName: "test nickname",
 				Email:    "test email",
+				Password: "test password",
 			},
 			mockBehaviour: func(service *handlerMock.MockUserService) {
-				service.EXPECT().CreateUser(gomock.Any(), "test nickname", "test email").
+				service.EXPECT().CreateUser(gomock.Any(), "test nickname", "test email", "test password").
 					Return(&models.User{
 						ID:       int64(0),
 						NickName: "test nickname",
@@ -126,7 +140,8 @@ func TestUserHandler_createUser(t *testing.T) {
 					"data": {
 						"id": 0,
 						"nickname": "test nickname",
-						"email": "test email"
+						"email": "test email",
+						"role": ""
 					}
 				}
 				`,
@@ -142,14 +157,43 @@ func TestUserHandler_createUser(t *testing.T) {
 			expectedStatusCode: http.StatusBadRequest,
 			expectedResponse:   `{"error": "json: cannot unmarshal bool into Go struct field CreateUserRequest.nickname of type string"}`,
 		},
+		{
+			name: "error from service: ErrAlreadyExists",
+			user: request.CreateUserRequest{
+				NickName: "test nickname",
+				Email:    "test email",
+				Password: "test password",
+			},
+			mockBehaviour: func(service *handlerMock.MockUserService) {
+				service.EXPECT().CreateUser(gomock.Any(), "test nickname", "test email", "test password").
+					Return(nil, domain.ErrAlreadyExists{Err: domain.ErrEmailTaken})
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedResponse:   `{"error": "the email is already taken"}`,
+		},
+		{
+			name: "error from service: ErrPasswordTooShort",
+			user: request.CreateUserRequest{
+				NickName: "test nickname",
+				Email:    "test email",
+				Password: "test",
+			},
+			mockBehaviour: func(serv *handlerMock.MockUserService) {
+				serv.EXPECT().CreateUser(gomock.Any(), "test nickname", "test email", "test").
+					Return(nil, service.ErrPasswordTooShort)
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   `{"error": "the password must be at least 8 characters long"}`,
+		},
 		{
 			name: "error from service",
 			user: request.CreateUserRequest{
 				NickName: "test nickname",
 				Email:    "test email",
+				Password: "test password",
 			},
 			mockBehaviour: func(service *handlerMock.MockUserService) {
-				service.EXPECT().CreateUser(gomock.Any(), "test nickname", "test email").
+				service.EXPECT().CreateUser(gomock.Any(), "test nickname", "test email", "test password").
 					Return(nil, fmt.Errorf("error from service"))
 			},
 			expectedStatusCode: http.StatusInternalServerError,
@@ -220,7 +264,8 @@ func TestUserHandler_updateUser(t *testing.T) {
 					"data": {
 						"id": 0,
 						"nickname": "test nickname",
-						"email": "test email"
+						"email": "test email",
+						"role": ""
 					}
 				}
 				`,
@@ -248,6 +293,20 @@ func TestUserHandler_updateUser(t *testing.T) {
 			expectedStatusCode: http.StatusBadRequest,
 			expectedResponse:   `{"error": "strconv.Atoi: parsing \"invalid_param\": invalid syntax"}`,
 		},
+		{
+			name:   "error from service: ErrAlreadyExists",
+			userID: "0",
+			user: request.UpdateUserRequest{
+				NickName: "test nickname",
+				Email:    "test email",
+			},
+			mockBehaviour: func(service *handlerMock.MockUserService) {
+				service.EXPECT().UpdateUser(gomock.Any(), int64(0), "test nickname", "test email").
+					Return(nil, domain.ErrAlreadyExists{Err: domain.ErrNickNameTaken})
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedResponse:   `{"error": "the nickname is already taken"}`,
+		},
 		{
 			name:   "error from service",
 			userID: "0",
@@ -373,3 +432,542 @@ func TestUserHandler_deleteUser(t *testing.T) {
 		})
 	}
 }
+
+func TestUserHandler_changePassword(t *testing.T) {
+	tests := []struct {
+		name               string
+		userID             string
+		body               any
+		mockBehaviour      func(service *handlerMock.MockUserService)
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name:   "successfully change password",
+			userID: "0",
+			body: request.ChangePasswordRequest{
+				OldPassword: "old password",
+				NewPassword: "new password",
+			},
+			mockBehaviour: func(service *handlerMock.MockUserService) {
+				service.EXPECT().ChangePassword(gomock.Any(), int64(0), "old password", "new password").
+					Return(nil).Times(1)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"success": "User #0 password changed"}`,
+		},
+		{
+			name:   "invalid json passed",
+			userID: "0",
+			body: struct {
+				OldPassword bool `json:"old_password"`
+			}{
+				OldPassword: true,
+			},
+			mockBehaviour:      func(service *handlerMock.MockUserService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   `{"error": "json: cannot unmarshal bool into Go struct field ChangePasswordRequest.old_password of type string"}`,
+		},
+		{
+			name:   "error param parsing",
+			userID: "invalid_param",
+			body: request.ChangePasswordRequest{
+				OldPassword: "old password",
+				NewPassword: "new password",
+			},
+			mockBehaviour:      func(service *handlerMock.MockUserService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   `{"error": "strconv.Atoi: parsing \"invalid_param\": invalid syntax"}`,
+		},
+		{
+			name:   "error from service: ErrNoAccess",
+			userID: "1",
+			body: request.ChangePasswordRequest{
+				OldPassword: "old password",
+				NewPassword: "new password",
+			},
+			mockBehaviour: func(serv *handlerMock.MockUserService) {
+				serv.EXPECT().ChangePassword(gomock.Any(), int64(1), "old password", "new password").
+					Return(service.ErrNoAccess{Err: service.ErrNoAccessUser})
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedResponse:   `{"error": "you don't have access to edit the user"}`,
+		},
+		{
+			name:   "error from service: ErrWrongPassword",
+			userID: "0",
+			body: request.ChangePasswordRequest{
+				OldPassword: "wrong password",
+				NewPassword: "new password",
+			},
+			mockBehaviour: func(serv *handlerMock.MockUserService) {
+				serv.EXPECT().ChangePassword(gomock.Any(), int64(0), "wrong password", "new password").
+					Return(service.ErrWrongPassword)
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedResponse:   `{"error": "the current password is wrong"}`,
+		},
+		{
+			name:   "error from service: ErrPasswordTooShort",
+			userID: "0",
+			body: request.ChangePasswordRequest{
+				OldPassword: "old password",
+				NewPassword: "new",
+			},
+			mockBehaviour: func(serv *handlerMock.MockUserService) {
+				serv.EXPECT().ChangePassword(gomock.Any(), int64(0), "old password", "new").
+					Return(service.ErrPasswordTooShort)
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   `{"error": "the password must be at least 8 characters long"}`,
+		},
+		{
+			name:   "error from service",
+			userID: "0",
+			body: request.ChangePasswordRequest{
+				OldPassword: "old password",
+				NewPassword: "new password",
+			},
+			mockBehaviour: func(service *handlerMock.MockUserService) {
+				service.EXPECT().ChangePassword(gomock.Any(), int64(0), "old password", "new password").
+					Return(fmt.Errorf("error from service"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse:   `{"error": "error from service"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := handlerMock.NewMockUserService(ctrl)
+			tc.mockBehaviour(service)
+
+			handler := NewUserHandler(service)
+
+			//Test Server
+			rg := gin.New()
+			rg.PUT("/:user_id/password", handler.changePassword)
+
+			jsonValue, err := json.Marshal(tc.body)
+			require.Equal(t, err, nil)
+
+			//Test request
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/%s/password", tc.userID), bytes.NewBuffer(jsonValue))
+
+			//Perform request
+			rg.ServeHTTP(w, r)
+
+			// Assert
+			require.Equal(t, tc.expectedStatusCode, w.Code)
+			require.JSONEq(t, tc.expectedResponse, w.Body.String())
+		})
+	}
+}
+
+func TestUserHandler_restoreUser(t *testing.T) {
+	tests := []struct {
+		name               string
+		userID             string
+		body               any
+		mockBehaviour      func(service *handlerMock.MockUserService)
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name:   "successfully restore user",
+			userID: "0",
+			body:   request.RestoreUserRequest{Password: "password123"},
+			mockBehaviour: func(service *handlerMock.MockUserService) {
+				service.EXPECT().RestoreUser(gomock.Any(), int64(0), "password123").
+					Return(&models.User{ID: 0, NickName: "ivan", Email: "ivan@gmail.com"}, nil).Times(1)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"data": {"id": 0, "nickname": "ivan", "email": "ivan@gmail.com", "role": ""}}`,
+		},
+		{
+			name:               "error param parsing",
+			userID:             "invalid_param",
+			body:               request.RestoreUserRequest{Password: "password123"},
+			mockBehaviour:      func(service *handlerMock.MockUserService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   `{"error": "strconv.Atoi: parsing \"invalid_param\": invalid syntax"}`,
+		},
+		{
+			name:   "error from service: ErrUserNotInTrash",
+			userID: "0",
+			body:   request.RestoreUserRequest{Password: "password123"},
+			mockBehaviour: func(serv *handlerMock.MockUserService) {
+				serv.EXPECT().RestoreUser(gomock.Any(), int64(0), "password123").Return(nil, service.ErrUserNotInTrash)
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedResponse:   `{"error": "the user is not deleted"}`,
+		},
+		{
+			name:   "error from service: ErrWrongPassword",
+			userID: "0",
+			body:   request.RestoreUserRequest{Password: "password321"},
+			mockBehaviour: func(serv *handlerMock.MockUserService) {
+				serv.EXPECT().RestoreUser(gomock.Any(), int64(0), "password321").Return(nil, service.ErrWrongPassword)
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedResponse:   `{"error": "the current password is wrong"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := handlerMock.NewMockUserService(ctrl)
+			tc.mockBehaviour(service)
+
+			handler := NewUserHandler(service)
+
+			rg := gin.New()
+			rg.POST("/:user_id/restore", handler.restoreUser)
+
+			jsonValue, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/%s/restore", tc.userID), bytes.NewBuffer(jsonValue))
+			rg.ServeHTTP(w, r)
+
+			require.Equal(t, tc.expectedStatusCode, w.Code)
+			require.JSONEq(t, tc.expectedResponse, w.Body.String())
+		})
+	}
+}
+
+func TestUserHandler_listTrash(t *testing.T) {
+	deletedAt := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name               string
+		userID             string
+		mockBehaviour      func(service *handlerMock.MockUserService)
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name:   "successfully list trash",
+			userID: "3",
+			mockBehaviour: func(service *handlerMock.MockUserService) {
+				service.EXPECT().ListTrash(gomock.Any(), int64(3)).Return([]*models.TrashedAd{{
+					Ad:      &models.Ad{ID: 1, Title: "title", Text: "text", UserID: 3, DeletedAt: deletedAt},
+					PurgeAt: deletedAt.Add(30 * 24 * time.Hour),
+				}}, nil).Times(1)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `
+				{
+					"data": [
+						{
+							"id": 1,
+							"title": "title",
+							"text": "text",
+							"user_id": 3,
+							"published": false,
+							"state": "",
+							"images": [],
+							"date_creation": "0001-01-01T00:00:00Z",
+							"date_update": "0001-01-01T00:00:00Z",
+							"deleted_at": "2023-05-01T10:00:00Z",
+							"purge_at": "2023-05-31T10:00:00Z"
+						}
+					]
+				}
+				`,
+		},
+		{
+			name:   "error from service: ErrNoAccess",
+			userID: "4",
+			mockBehaviour: func(serv *handlerMock.MockUserService) {
+				serv.EXPECT().ListTrash(gomock.Any(), int64(4)).Return(nil, service.ErrNoAccess{Err: service.ErrNoAccessUser})
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedResponse:   `{"error": "you don't have access to edit the user"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := handlerMock.NewMockUserService(ctrl)
+			tc.mockBehaviour(service)
+
+			handler := NewUserHandler(service)
+
+			rg := gin.New()
+			rg.GET("/:user_id/trash", handler.listTrash)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/%s/trash", tc.userID), nil)
+			rg.ServeHTTP(w, r)
+
+			require.Equal(t, tc.expectedStatusCode, w.Code)
+			require.JSONEq(t, tc.expectedResponse, w.Body.String())
+		})
+	}
+}
+
+func TestUserHandler_listFavorites(t *testing.T) {
+	addedAt := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name               string
+		userID             string
+		mockBehaviour      func(service *handlerMock.MockUserService)
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name:   "successfully list favorites",
+			userID: "3",
+			mockBehaviour: func(service *handlerMock.MockUserService) {
+				service.EXPECT().ListFavorites(gomock.Any(), int64(3)).Return([]*models.FavoriteAd{{
+					Ad:      &models.Ad{ID: 1, Title: "title", Text: "text", UserID: 4, Published: true, State: models.AdPublished},
+					AddedAt: addedAt,
+				}}, nil).Times(1)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `
+				{
+					"data": [
+						{
+							"id": 1,
+							"title": "title",
+							"text": "text",
+							"user_id": 4,
+							"published": true,
+							"state": "published",
+							"images": [],
+							"date_creation": "0001-01-01T00:00:00Z",
+							"date_update": "0001-01-01T00:00:00Z",
+							"added_at": "2023-05-01T10:00:00Z"
+						}
+					]
+				}
+				`,
+		},
+		{
+			name:   "error from service: ErrNoAccess",
+			userID: "4",
+			mockBehaviour: func(serv *handlerMock.MockUserService) {
+				serv.EXPECT().ListFavorites(gomock.Any(), int64(4)).Return(nil, service.ErrNoAccess{Err: service.ErrNoAccessUser})
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedResponse:   `{"error": "you don't have access to edit the user"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := handlerMock.NewMockUserService(ctrl)
+			tc.mockBehaviour(service)
+
+			handler := NewUserHandler(service)
+
+			rg := gin.New()
+			rg.GET("/:user_id/favorites", handler.listFavorites)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/%s/favorites", tc.userID), nil)
+			rg.ServeHTTP(w, r)
+
+			require.Equal(t, tc.expectedStatusCode, w.Code)
+			require.JSONEq(t, tc.expectedResponse, w.Body.String())
+		})
+	}
+}
+
+func TestUserHandler_changeFavorites(t *testing.T) {
+	tests := []struct {
+		name               string
+		method             string
+		path               string
+		mockBehaviour      func(service *handlerMock.MockUserService)
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name:   "successfully add favorite",
+			method: http.MethodPost,
+			path:   "/3/favorites/1",
+			mockBehaviour: func(service *handlerMock.MockUserService) {
+				service.EXPECT().AddFavorite(gomock.Any(), int64(3), int64(1)).Return(&models.Ad{ID: 1, Title: "title",
+					Text: "text", UserID: 4, Published: true, State: models.AdPublished}, nil).Times(1)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `
+				{
+					"data": {
+						"id": 1,
+						"title": "title",
+						"text": "text",
+						"user_id": 4,
+						"published": true,
+						"state": "published",
+						"images": [],
+						"date_creation": "0001-01-01T00:00:00Z",
+						"date_update": "0001-01-01T00:00:00Z"
+					}
+				}
+				`,
+		},
+		{
+			name:   "error from service: ErrAdNotPublished",
+			method: http.MethodPost,
+			path:   "/3/favorites/1",
+			mockBehaviour: func(serv *handlerMock.MockUserService) {
+				serv.EXPECT().AddFavorite(gomock.Any(), int64(3), int64(1)).Return(nil, service.ErrAdNotPublished)
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedResponse:   `{"error": "only published ads can be added to favorites"}`,
+		},
+		{
+			name:               "invalid ad id",
+			method:             http.MethodPost,
+			path:               "/3/favorites/abc",
+			mockBehaviour:      func(service *handlerMock.MockUserService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   `{"error": "strconv.Atoi: parsing \"abc\": invalid syntax"}`,
+		},
+		{
+			name:   "successfully remove favorite",
+			method: http.MethodDelete,
+			path:   "/3/favorites/1",
+			mockBehaviour: func(service *handlerMock.MockUserService) {
+				service.EXPECT().RemoveFavorite(gomock.Any(), int64(3), int64(1)).Return(nil).Times(1)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"success": "Ad #1 removed from favorites"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := handlerMock.NewMockUserService(ctrl)
+			tc.mockBehaviour(service)
+
+			handler := NewUserHandler(service)
+
+			rg := gin.New()
+			rg.POST("/:user_id/favorites/:ad_id", handler.addFavorite)
+			rg.DELETE("/:user_id/favorites/:ad_id", handler.removeFavorite)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tc.method, tc.path, nil)
+			rg.ServeHTTP(w, r)
+
+			require.Equal(t, tc.expectedStatusCode, w.Code)
+			require.JSONEq(t, tc.expectedResponse, w.Body.String())
+		})
+	}
+}
+
+func TestUserHandler_setUserRole(t *testing.T) {
+	tests := []struct {
+		name               string
+		userID             string
+		body               any
+		mockBehaviour      func(service *handlerMock.MockUserService)
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name:   "successfully set role",
+			userID: "1",
+			body:   request.SetUserRoleRequest{Role: "moderator"},
+			mockBehaviour: func(service *handlerMock.MockUserService) {
+				service.EXPECT().SetUserRole(gomock.Any(), int64(1), models.RoleModerator).
+					Return(&models.User{ID: 1, NickName: "ivan", Email: "ivan@gmail.com", Role: models.RoleModerator}, nil).Times(1)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `{"data": {"id": 1, "nickname": "ivan", "email": "ivan@gmail.com", "role": "moderator"}}`,
+		},
+		{
+			name:               "error param parsing",
+			userID:             "invalid_param",
+			body:               request.SetUserRoleRequest{Role: "moderator"},
+			mockBehaviour:      func(service *handlerMock.MockUserService) {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   `{"error": "strconv.Atoi: parsing \"invalid_param\": invalid syntax"}`,
+		},
+		{
+			name:   "error from service: ErrNoAccess",
+			userID: "1",
+			body:   request.SetUserRoleRequest{Role: "admin"},
+			mockBehaviour: func(serv *handlerMock.MockUserService) {
+				serv.EXPECT().SetUserRole(gomock.Any(), int64(1), models.RoleAdmin).
+					Return(nil, service.ErrNoAccess{Err: service.ErrNoAccessRole})
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedResponse:   `{"error": "only an administrator can change user roles"}`,
+		},
+		{
+			name:   "error from service: ErrUnknownRole",
+			userID: "1",
+			body:   request.SetUserRoleRequest{Role: "superuser"},
+			mockBehaviour: func(serv *handlerMock.MockUserService) {
+				serv.EXPECT().SetUserRole(gomock.Any(), int64(1), models.Role("superuser")).
+					Return(nil, service.ErrUnknownRole)
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   `{"error": "the role must be user, moderator or admin"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := handlerMock.NewMockUserService(ctrl)
+			tc.mockBehaviour(service)
+
+			handler := NewUserHandler(service)
+
+			//Test Server
+			rg := gin.New()
+			rg.PUT("/:user_id/role", handler.setUserRole)
+
+			jsonValue, err := json.Marshal(tc.body)
+			require.Equal(t, err, nil)
+
+			//Test request
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/%s/role", tc.userID), bytes.NewBuffer(jsonValue))
+
+			//Perform request
+			rg.ServeHTTP(w, r)
+
+			// Assert
+			require.Equal(t, tc.expectedStatusCode, w.Code)
+			require.JSONEq(t, tc.expectedResponse, w.Body.String())
+		})
+	}
+}
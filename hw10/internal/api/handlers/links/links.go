@@ -0,0 +1,17 @@
+package links
+
+import "fmt"
+
+// adsPath путь к объявлениям в HTTP API; изображения отдаются только по HTTP, поэтому их адреса
+// одинаковы в ответах обоих транспортов
+const adsPath = "/api/v1/ads"
+
+// AdImage путь для скачивания изображения объявления
+func AdImage(adID int64, imageID int64) string {
+	return fmt.Sprintf("%s/%d/images/%d", adsPath, adID, imageID)
+}
+
+// AdImageThumbnail путь для скачивания миниатюры изображения объявления
+func AdImageThumbnail(adID int64, imageID int64) string {
+	return AdImage(adID, imageID) + "/thumbnail"
+}
@@ -0,0 +1,154 @@
+package params
+
+import (
+	"errors"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateFormat формат параметра date: выборка объявлений, созданных за один день (UTC)
+const DateFormat = "01-02-2006"
+
+var (
+	ErrInvalidPublished    = domain.ErrInvalid{Err: errors.New("published validating error")}
+	ErrInvalidUserID       = domain.ErrInvalid{Err: errors.New("userID validating error")}
+	ErrInvalidDateCreation = domain.ErrInvalid{Err: errors.New("dateCreation validating error")}
+	ErrInvalidCreatedFrom  = domain.ErrInvalid{Err: errors.New("created_from must be an RFC 3339 timestamp")}
+	ErrInvalidCreatedTo    = domain.ErrInvalid{Err: errors.New("created_to must be an RFC 3339 timestamp")}
+	ErrInvalidUpdatedSince = domain.ErrInvalid{Err: errors.New("updated_since must be an RFC 3339 timestamp")}
+	ErrDateWithRange       = domain.ErrInvalid{Err: errors.New("date cannot be combined with created_from or created_to")}
+	ErrEmptyCreatedRange   = domain.ErrInvalid{Err: errors.New("created_from must be earlier than created_to")}
+	ErrInvalidPriceMin     = domain.ErrInvalid{Err: errors.New("price_min must be an integer amount in minor units")}
+	ErrInvalidPriceMax     = domain.ErrInvalid{Err: errors.New("price_max must be an integer amount in minor units")}
+	ErrEmptyPriceRange     = domain.ErrInvalid{Err: errors.New("price_min must not be greater than price_max")}
+	// ErrPriceWithoutCurrency суммы в разных валютах несравнимы, поэтому диапазон цены задаётся вместе с валютой
+	ErrPriceWithoutCurrency = domain.ValidationErrors{{Field: "Currency", Rule: "required",
+		Message: "currency is required when price_min or price_max is set"}}
+)
+
+// ParseAdFilter разбирает строковые параметры фильтрации объявлений, пришедшие от клиента.
+// Пустые строки не ограничивают выборку
+func ParseAdFilter(publishedRaw string, userIDsRaw []string, dateCreationRaw string) (models.AdFilter, error) {
+	var filter models.AdFilter
+	if publishedRaw != "" {
+		published, err := strconv.ParseBool(publishedRaw)
+		if err != nil {
+			return filter, ErrInvalidPublished
+		}
+		filter.Published = &published
+	}
+
+	for _, userIDRaw := range userIDsRaw {
+		if userIDRaw == "" {
+			continue
+		}
+		userID, err := strconv.ParseInt(userIDRaw, 10, 64)
+		if err != nil {
+			return filter, ErrInvalidUserID
+		}
+		filter.AuthorIDs = append(filter.AuthorIDs, userID)
+	}
+
+	if dateCreationRaw != "" {
+		date, err := time.Parse(DateFormat, dateCreationRaw)
+		if err != nil {
+			return filter, ErrInvalidDateCreation
+		}
+		filter.CreatedFrom = date
+		filter.CreatedTo = date.AddDate(0, 0, 1)
+	}
+	return filter, nil
+}
+
+// ParseDateRanges разбирает границы дат в RFC 3339 и добавляет их к фильтру так же, как ApplyDateRanges
+func ParseDateRanges(filter models.AdFilter, createdFromRaw string, createdToRaw string, updatedSinceRaw string) (
+	models.AdFilter, error) {
+	createdFrom, err := parseTimestamp(createdFromRaw, ErrInvalidCreatedFrom)
+	if err != nil {
+		return filter, err
+	}
+	createdTo, err := parseTimestamp(createdToRaw, ErrInvalidCreatedTo)
+	if err != nil {
+		return filter, err
+	}
+	updatedSince, err := parseTimestamp(updatedSinceRaw, ErrInvalidUpdatedSince)
+	if err != nil {
+		return filter, err
+	}
+	return ApplyDateRanges(filter, createdFrom, createdTo, updatedSince)
+}
+
+// ApplyDateRanges ограничивает выборку объявлениями, созданными в [createdFrom, createdTo) и изменёнными
+// не раньше updatedSince; нулевые значения не ограничивают выборку. Диапазон создания нельзя совмещать
+// с параметром date, который уже задаёт его
+func ApplyDateRanges(filter models.AdFilter, createdFrom time.Time, createdTo time.Time, updatedSince time.Time) (
+	models.AdFilter, error) {
+	if !createdFrom.IsZero() || !createdTo.IsZero() {
+		if !filter.CreatedFrom.IsZero() || !filter.CreatedTo.IsZero() {
+			return filter, ErrDateWithRange
+		}
+		if !createdFrom.IsZero() && !createdTo.IsZero() && !createdFrom.Before(createdTo) {
+			return filter, ErrEmptyCreatedRange
+		}
+		filter.CreatedFrom = createdFrom
+		filter.CreatedTo = createdTo
+	}
+	filter.UpdatedFrom = updatedSince
+	return filter, nil
+}
+
+// ParseCatalogFilter добавляет к фильтру условия каталога: диапазон цены в минимальных единицах (включительно),
+// валюту, категории и город. Пустые строки не ограничивают выборку; диапазон цены без валюты - ошибка валидации
+func ParseCatalogFilter(filter models.AdFilter, priceMinRaw string, priceMaxRaw string, currency string,
+	categoryIDs []string, city string) (models.AdFilter, error) {
+	priceMin, err := parsePrice(priceMinRaw, ErrInvalidPriceMin)
+	if err != nil {
+		return filter, err
+	}
+	priceMax, err := parsePrice(priceMaxRaw, ErrInvalidPriceMax)
+	if err != nil {
+		return filter, err
+	}
+	if priceMin != nil && priceMax != nil && *priceMin > *priceMax {
+		return filter, ErrEmptyPriceRange
+	}
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if (priceMin != nil || priceMax != nil) && currency == "" {
+		return filter, ErrPriceWithoutCurrency
+	}
+	filter.PriceMin = priceMin
+	filter.PriceMax = priceMax
+	filter.Currency = currency
+	for _, categoryID := range categoryIDs {
+		if categoryID = strings.TrimSpace(categoryID); categoryID != "" {
+			filter.CategoryIDs = append(filter.CategoryIDs, categoryID)
+		}
+	}
+	filter.City = strings.TrimSpace(city)
+	return filter, nil
+}
+
+func parsePrice(raw string, invalid error) (*int64, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	price, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || price < 0 {
+		return nil, invalid
+	}
+	return &price, nil
+}
+
+func parseTimestamp(raw string, invalid error) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	timestamp, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, invalid
+	}
+	return timestamp.UTC(), nil
+}
@@ -0,0 +1,219 @@
+package params
+
+import (
+	"github.com/stretchr/testify/assert"
+	"homework10/internal/domain/models"
+	"testing"
+	"time"
+)
+
+func TestParseAdFilter(t *testing.T) {
+	published := true
+	date := time.Date(2023, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		published    string
+		userIDs      []string
+		dateCreation string
+		expected     models.AdFilter
+		err          error
+	}{
+		{
+			name:     "empty params",
+			expected: models.AdFilter{},
+		},
+		{
+			name:         "all params",
+			published:    "true",
+			userIDs:      []string{"1", "", "2"},
+			dateCreation: "05-01-2023",
+			expected: models.AdFilter{
+				Published:   &published,
+				AuthorIDs:   []int64{1, 2},
+				CreatedFrom: date,
+				CreatedTo:   date.AddDate(0, 0, 1),
+			},
+		},
+		{
+			name:      "invalid published",
+			published: "yes please",
+			err:       ErrInvalidPublished,
+		},
+		{
+			name:    "invalid userID",
+			userIDs: []string{"abc"},
+			err:     ErrInvalidUserID,
+		},
+		{
+			name:         "invalid date",
+			dateCreation: "2023-05-01",
+			err:          ErrInvalidDateCreation,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := ParseAdFilter(tc.published, tc.userIDs, tc.dateCreation)
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, filter)
+		})
+	}
+}
+
+func TestParseDateRanges(t *testing.T) {
+	from := time.Date(2023, time.May, 1, 10, 0, 0, 0, time.UTC)
+	to := time.Date(2023, time.May, 3, 0, 0, 0, 0, time.UTC)
+	day := models.AdFilter{CreatedFrom: from, CreatedTo: from.AddDate(0, 0, 1)}
+
+	tests := []struct {
+		name         string
+		filter       models.AdFilter
+		createdFrom  string
+		createdTo    string
+		updatedSince string
+		expected     models.AdFilter
+		err          error
+	}{
+		{
+			name: "empty params",
+		},
+		{
+			name:         "all params",
+			createdFrom:  "2023-05-01T13:00:00+03:00",
+			createdTo:    "2023-05-03T00:00:00Z",
+			updatedSince: "2023-05-01T10:00:00Z",
+			expected:     models.AdFilter{CreatedFrom: from, CreatedTo: to, UpdatedFrom: from},
+		},
+		{
+			name:      "open range",
+			createdTo: "2023-05-03T00:00:00Z",
+			expected:  models.AdFilter{CreatedTo: to},
+		},
+		{
+			name:         "updated since keeps date",
+			filter:       day,
+			updatedSince: "2023-05-01T10:00:00Z",
+			expected:     models.AdFilter{CreatedFrom: from, CreatedTo: from.AddDate(0, 0, 1), UpdatedFrom: from},
+		},
+		{
+			name:        "invalid created_from",
+			createdFrom: "05-01-2023",
+			err:         ErrInvalidCreatedFrom,
+		},
+		{
+			name:      "invalid created_to",
+			createdTo: "tomorrow",
+			err:       ErrInvalidCreatedTo,
+		},
+		{
+			name:         "invalid updated_since",
+			updatedSince: "2023-05-01",
+			err:          ErrInvalidUpdatedSince,
+		},
+		{
+			name:        "empty range",
+			createdFrom: "2023-05-03T00:00:00Z",
+			createdTo:   "2023-05-01T10:00:00Z",
+			err:         ErrEmptyCreatedRange,
+		},
+		{
+			name:        "date with range",
+			filter:      day,
+			createdFrom: "2023-05-01T10:00:00Z",
+			err:         ErrDateWithRange,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := ParseDateRanges(tc.filter, tc.createdFrom, tc.createdTo, tc.updatedSince)
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, filter)
+		})
+	}
+}
+
+func TestParseCatalogFilter(t *testing.T) {
+	priceMin, priceMax := int64(1000), int64(5000)
+
+	tests := []struct {
+		name       string
+		priceMin   string
+		priceMax   string
+		currency   string
+		categories []string
+		city       string
+		expected   models.AdFilter
+		err        error
+	}{
+		{
+			name: "empty params",
+		},
+		{
+			name:       "all params",
+			priceMin:   "1000",
+			priceMax:   "5000",
+			currency:   " rub",
+			categories: []string{"cars", "", "phones"},
+			city:       " Москва ",
+			expected: models.AdFilter{
+				PriceMin:    &priceMin,
+				PriceMax:    &priceMax,
+				Currency:    "RUB",
+				CategoryIDs: []string{"cars", "phones"},
+				City:        "Москва",
+			},
+		},
+		{
+			name:     "open price range",
+			priceMax: "5000",
+			currency: "RUB",
+			expected: models.AdFilter{PriceMax: &priceMax, Currency: "RUB"},
+		},
+		{
+			name:     "price_min without currency",
+			priceMin: "1000",
+			err:      ErrPriceWithoutCurrency,
+		},
+		{
+			name:     "price_max without currency",
+			priceMax: "5000",
+			currency: " ",
+			err:      ErrPriceWithoutCurrency,
+		},
+		{
+			name:     "invalid price_min",
+			priceMin: "10.50",
+			err:      ErrInvalidPriceMin,
+		},
+		{
+			name:     "negative price_max",
+			priceMax: "-1",
+			err:      ErrInvalidPriceMax,
+		},
+		{
+			name:     "empty price range",
+			priceMin: "5000",
+			priceMax: "1000",
+			err:      ErrEmptyPriceRange,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := ParseCatalogFilter(models.AdFilter{}, tc.priceMin, tc.priceMax, tc.currency, tc.categories, tc.city)
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, filter)
+		})
+	}
+}
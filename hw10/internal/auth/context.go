@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"context"
+	"homework10/internal/domain/models"
+)
+
+type userIDKey struct{}
+
+// WithUserID кладёт в контекст ID аутентифицированного пользователя
+func WithUserID(ctx context.Context, userID int64) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// UserIDFromContext достаёт из контекста ID аутентифицированного пользователя
+func UserIDFromContext(ctx context.Context) (int64, bool) {
+	userID, ok := ctx.Value(userIDKey{}).(int64)
+	return userID, ok
+}
+
+type roleKey struct{}
+
+// WithRole кладёт в контекст роль аутентифицированного пользователя
+func WithRole(ctx context.Context, role models.Role) context.Context {
+	return context.WithValue(ctx, roleKey{}, role)
+}
+
+// RoleFromContext достаёт из контекста роль аутентифицированного пользователя; без роли в контексте
+// пользователь считается обычным
+func RoleFromContext(ctx context.Context) models.Role {
+	role, ok := ctx.Value(roleKey{}).(models.Role)
+	if !ok || role == "" {
+		return models.RoleUser
+	}
+	return role
+}
@@ -0,0 +1,24 @@
+package auth
+
+import (
+	"context"
+	"homework10/internal/domain/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserIDFromContext(t *testing.T) {
+	_, ok := UserIDFromContext(context.Background())
+	assert.False(t, ok)
+
+	userID, ok := UserIDFromContext(WithUserID(context.Background(), 0))
+	assert.True(t, ok)
+	assert.Equal(t, int64(0), userID)
+}
+
+func TestRoleFromContext(t *testing.T) {
+	assert.Equal(t, models.RoleUser, RoleFromContext(context.Background()))
+	assert.Equal(t, models.RoleUser, RoleFromContext(WithRole(context.Background(), "")))
+	assert.Equal(t, models.RoleModerator, RoleFromContext(WithRole(context.Background(), models.RoleModerator)))
+}
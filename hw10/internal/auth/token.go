@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"homework10/internal/domain/models"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const bearerPrefix = "bearer "
+
+var (
+	ErrNoToken      = errors.New("authorization token is required")
+	ErrInvalidToken = errors.New("invalid authorization token")
+)
+
+// TokenManager выпускает и проверяет подписанные HMAC-SHA256 JWT; в subject хранится ID пользователя
+type TokenManager struct {
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+func NewTokenManager(secret []byte, ttl time.Duration) *TokenManager {
+	return &TokenManager{
+		secret: secret,
+		ttl:    ttl,
+		now:    time.Now,
+	}
+}
+
+func (m *TokenManager) Issue(userID int64) (*models.AuthToken, error) {
+	now := m.now()
+	expiresAt := now.Add(m.ttl)
+	claims := jwt.RegisteredClaims{
+		Subject:   strconv.FormatInt(userID, 10),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.secret)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AuthToken{Token: token, ExpiresAt: expiresAt.UTC().Truncate(time.Second)}, nil
+}
+
+// Parse проверяет подпись и срок действия токена и возвращает ID пользователя
+func (m *TokenManager) Parse(token string) (int64, error) {
+	var claims jwt.RegisteredClaims
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	_, err := parser.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		return m.secret, nil
+	})
+	if err != nil {
+		return 0, ErrInvalidToken
+	}
+	userID, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidToken
+	}
+	return userID, nil
+}
+
+// BearerToken достаёт токен из значения заголовка "Authorization: Bearer <token>"
+func BearerToken(header string) (string, error) {
+	if header == "" {
+		return "", ErrNoToken
+	}
+	if len(header) <= len(bearerPrefix) || !strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+		return "", ErrInvalidToken
+	}
+	return strings.TrimSpace(header[len(bearerPrefix):]), nil
+}
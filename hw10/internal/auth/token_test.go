@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenManager_IssueParse(t *testing.T) {
+	manager := NewTokenManager([]byte("test secret"), time.Hour)
+
+	token, err := manager.Issue(42)
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), token.ExpiresAt, 2*time.Second)
+
+	userID, err := manager.Parse(token.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), userID)
+}
+
+func TestTokenManager_ParseInvalid(t *testing.T) {
+	manager := NewTokenManager([]byte("test secret"), time.Hour)
+
+	expiredManager := NewTokenManager([]byte("test secret"), time.Hour)
+	expiredManager.now = func() time.Time { return time.Now().Add(-2 * time.Hour) }
+	expired, err := expiredManager.Issue(1)
+	assert.NoError(t, err)
+
+	foreign, err := NewTokenManager([]byte("other secret"), time.Hour).Issue(1)
+	assert.NoError(t, err)
+
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.RegisteredClaims{Subject: "1"}).
+		SignedString(jwt.UnsafeAllowNoneSignatureType)
+	assert.NoError(t, err)
+
+	notNumeric, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{Subject: "admin"}).
+		SignedString([]byte("test secret"))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "expired token", token: expired.Token},
+		{name: "wrong secret", token: foreign.Token},
+		{name: "alg none", token: unsigned},
+		{name: "subject is not a user ID", token: notNumeric},
+		{name: "malformed token", token: "not.a.token"},
+		{name: "empty token", token: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := manager.Parse(tt.token)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		expected string
+		wantErr  error
+	}{
+		{name: "bearer token", header: "Bearer abc.def.ghi", expected: "abc.def.ghi"},
+		{name: "scheme is case-insensitive", header: "bearer abc.def.ghi", expected: "abc.def.ghi"},
+		{name: "no header", header: "", wantErr: ErrNoToken},
+		{name: "basic scheme", header: "Basic dXNlcjpwYXNz", wantErr: ErrInvalidToken},
+		{name: "scheme without token", header: "Bearer ", wantErr: ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := BearerToken(tt.header)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, token)
+		})
+	}
+}
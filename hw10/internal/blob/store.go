@@ -0,0 +1,31 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"homework10/internal/domain"
+	"io"
+	"io/fs"
+)
+
+var (
+	ErrNotExist   = domain.ErrNotExist{Err: errors.New("the blob does not exist")}
+	ErrInvalidKey = errors.New("invalid blob key")
+)
+
+// Store хранилище двоичных данных по ключу. Ключи - относительные пути через "/", например "ads/1/abc"
+type Store interface {
+	// Put сохраняет данные из r под ключом key, заменяя прежние; частично записанные данные не видны
+	Put(ctx context.Context, key string, r io.Reader) error
+	// Open открывает данные по ключу; если их нет, возвращает ErrNotExist
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete удаляет данные по ключу; отсутствие данных ошибкой не считается
+	Delete(ctx context.Context, key string) error
+}
+
+func validateKey(key string) error {
+	if key == "" || key == "." || !fs.ValidPath(key) {
+		return ErrInvalidKey
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStores(t *testing.T) {
+	local, err := NewLocalStore(filepath.Join(t.TempDir(), "blobs"))
+	require.NoError(t, err)
+
+	stores := map[string]Store{
+		"memory": NewMemoryStore(),
+		"local":  local,
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			_, err := store.Open(ctx, "ads/1/image")
+			assert.ErrorIs(t, err, ErrNotExist)
+
+			assert.NoError(t, store.Put(ctx, "ads/1/image", strings.NewReader("first")))
+			assert.NoError(t, store.Put(ctx, "ads/1/image", strings.NewReader("second")))
+			assert.Equal(t, "second", read(t, store, "ads/1/image"))
+
+			assert.NoError(t, store.Delete(ctx, "ads/1/image"))
+			_, err = store.Open(ctx, "ads/1/image")
+			assert.ErrorIs(t, err, ErrNotExist)
+			assert.NoError(t, store.Delete(ctx, "ads/1/image"), "deleting a missing blob is not an error")
+
+			for _, key := range []string{"", "../outside", "/etc/passwd", "ads/../../outside"} {
+				assert.ErrorIs(t, store.Put(ctx, key, strings.NewReader("data")), ErrInvalidKey, key)
+			}
+		})
+	}
+}
+
+func TestLocalStore_KeepsFilesInDir(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewLocalStore(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put(context.Background(), "ads/7/image", strings.NewReader("data")))
+	data, err := os.ReadFile(filepath.Join(dir, "ads", "7", "image"))
+	assert.NoError(t, err)
+	assert.Equal(t, "data", string(data))
+
+	entries, err := os.ReadDir(filepath.Join(dir, "ads", "7"))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1, "temporary files are removed")
+}
+
+func read(t *testing.T, store Store, key string) string {
+	r, err := store.Open(context.Background(), key)
+	require.NoError(t, err)
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(data)
+}
@@ -0,0 +1,33 @@
+package category
+
+import "homework10/internal/domain/models"
+
+var defaultCategories = []models.Category{
+	{ID: "transport", Name: "Транспорт"},
+	{ID: "cars", Name: "Автомобили", ParentID: "transport"},
+	{ID: "motorcycles", Name: "Мотоциклы", ParentID: "transport"},
+	{ID: "spare-parts", Name: "Запчасти", ParentID: "transport"},
+	{ID: "real-estate", Name: "Недвижимость"},
+	{ID: "apartments", Name: "Квартиры", ParentID: "real-estate"},
+	{ID: "rooms", Name: "Комнаты", ParentID: "real-estate"},
+	{ID: "houses", Name: "Дома и дачи", ParentID: "real-estate"},
+	{ID: "electronics", Name: "Электроника"},
+	{ID: "phones", Name: "Телефоны", ParentID: "electronics"},
+	{ID: "computers", Name: "Компьютеры", ParentID: "electronics"},
+	{ID: "audio-video", Name: "Аудио и видео", ParentID: "electronics"},
+	{ID: "home", Name: "Для дома"},
+	{ID: "furniture", Name: "Мебель", ParentID: "home"},
+	{ID: "appliances", Name: "Бытовая техника", ParentID: "home"},
+	{ID: "clothing", Name: "Одежда и обувь"},
+	{ID: "services", Name: "Услуги"},
+	{ID: "jobs", Name: "Работа"},
+}
+
+// Default дерево категорий, с которым сервер запускается без флага --categories
+func Default() *Tree {
+	tree, err := NewTree(defaultCategories)
+	if err != nil {
+		panic(err)
+	}
+	return tree
+}
@@ -0,0 +1,100 @@
+package category
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"homework10/internal/domain/models"
+	"io"
+)
+
+var (
+	ErrEmptyID       = errors.New("category id is required")
+	ErrDuplicateID   = errors.New("duplicate category id")
+	ErrUnknownParent = errors.New("parent category must be listed before its subcategories")
+)
+
+// Tree неизменяемое дерево категорий объявлений
+type Tree struct {
+	// categories категории в порядке обхода в глубину: каждая родительская раньше своих подкатегорий
+	categories []models.Category
+	byID       map[string]models.Category
+	children   map[string][]string
+}
+
+// NewTree строит дерево из списка категорий, в котором родительская категория идёт раньше подкатегорий
+func NewTree(categories []models.Category) (*Tree, error) {
+	t := &Tree{
+		byID:     make(map[string]models.Category, len(categories)),
+		children: make(map[string][]string),
+	}
+	for _, category := range categories {
+		if category.ID == "" {
+			return nil, ErrEmptyID
+		}
+		if _, ok := t.byID[category.ID]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateID, category.ID)
+		}
+		if _, ok := t.byID[category.ParentID]; category.ParentID != "" && !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownParent, category.ID)
+		}
+		t.byID[category.ID] = category
+		t.children[category.ParentID] = append(t.children[category.ParentID], category.ID)
+	}
+	t.categories = make([]models.Category, 0, len(categories))
+	t.walk("", func(id string) {
+		t.categories = append(t.categories, t.byID[id])
+	})
+	return t, nil
+}
+
+// Load читает дерево из JSON-массива категорий
+func Load(r io.Reader) (*Tree, error) {
+	var categories []models.Category
+	if err := json.NewDecoder(r).Decode(&categories); err != nil {
+		return nil, fmt.Errorf("decoding categories: %w", err)
+	}
+	return NewTree(categories)
+}
+
+// Get возвращает категорию по ID
+func (t *Tree) Get(id string) (models.Category, bool) {
+	category, ok := t.byID[id]
+	return category, ok
+}
+
+// Categories возвращает все категории; родительская категория идёт раньше своих подкатегорий
+func (t *Tree) Categories() []models.Category {
+	categories := make([]models.Category, len(t.categories))
+	copy(categories, t.categories)
+	return categories
+}
+
+// Subtree возвращает ID категории и всех её подкатегорий; для неизвестной категории - nil
+func (t *Tree) Subtree(id string) []string {
+	if _, ok := t.byID[id]; !ok {
+		return nil
+	}
+	ids := []string{id}
+	t.walk(id, func(child string) {
+		ids = append(ids, child)
+	})
+	return ids
+}
+
+// Path возвращает ID категории и всех её родительских категорий, начиная с самой категории
+func (t *Tree) Path(id string) []string {
+	var ids []string
+	for category, ok := t.byID[id]; ok; category, ok = t.byID[category.ParentID] {
+		ids = append(ids, category.ID)
+	}
+	return ids
+}
+
+// walk обходит в глубину подкатегории id, не включая саму id
+func (t *Tree) walk(id string, visit func(id string)) {
+	for _, child := range t.children[id] {
+		visit(child)
+		t.walk(child, visit)
+	}
+}
@@ -0,0 +1,93 @@
+package category
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"homework10/internal/domain/models"
+)
+
+func TestNewTree(t *testing.T) {
+	tests := []struct {
+		name       string
+		categories []models.Category
+		err        error
+	}{
+		{
+			name: "parents before children",
+			categories: []models.Category{
+				{ID: "a"}, {ID: "b", ParentID: "a"}, {ID: "c", ParentID: "b"},
+			},
+		},
+		{
+			name:       "empty id",
+			categories: []models.Category{{Name: "a"}},
+			err:        ErrEmptyID,
+		},
+		{
+			name:       "duplicate id",
+			categories: []models.Category{{ID: "a"}, {ID: "a"}},
+			err:        ErrDuplicateID,
+		},
+		{
+			name:       "child before parent",
+			categories: []models.Category{{ID: "b", ParentID: "a"}, {ID: "a"}},
+			err:        ErrUnknownParent,
+		},
+		{
+			name:       "parent is itself",
+			categories: []models.Category{{ID: "a", ParentID: "a"}},
+			err:        ErrUnknownParent,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewTree(tc.categories)
+			assert.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func TestTree(t *testing.T) {
+	tree, err := NewTree([]models.Category{
+		{ID: "transport"},
+		{ID: "realty"},
+		{ID: "cars", ParentID: "transport"},
+		{ID: "sedans", ParentID: "cars"},
+		{ID: "bikes", ParentID: "transport"},
+	})
+	require.NoError(t, err)
+
+	ids := make([]string, 0)
+	for _, category := range tree.Categories() {
+		ids = append(ids, category.ID)
+	}
+	assert.Equal(t, []string{"transport", "cars", "sedans", "bikes", "realty"}, ids)
+
+	assert.Equal(t, []string{"transport", "cars", "sedans", "bikes"}, tree.Subtree("transport"))
+	assert.Equal(t, []string{"sedans"}, tree.Subtree("sedans"))
+	assert.Nil(t, tree.Subtree("boats"))
+
+	assert.Equal(t, []string{"sedans", "cars", "transport"}, tree.Path("sedans"))
+	assert.Nil(t, tree.Path("boats"))
+
+	category, ok := tree.Get("cars")
+	assert.True(t, ok)
+	assert.Equal(t, "transport", category.ParentID)
+}
+
+func TestLoad(t *testing.T) {
+	tree, err := Load(strings.NewReader(`[{"id": "a", "name": "A"}, {"id": "b", "name": "B", "parent_id": "a"}]`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, tree.Subtree("a"))
+
+	_, err = Load(strings.NewReader(`{"id": "a"}`))
+	assert.Error(t, err)
+}
+
+func TestDefault(t *testing.T) {
+	tree := Default()
+	assert.Contains(t, tree.Subtree("transport"), "cars")
+}
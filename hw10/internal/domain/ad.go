@@ -3,14 +3,34 @@ package domain
 import (
 	"context"
 	"homework10/internal/domain/models"
+	"time"
 )
 
 //go:generate mockgen -source=./ad.go -destination=../service/mock/ad.go -package=repoMock AdRepository
 type AdRepository interface {
 	AddAd(ctx context.Context, ad models.Ad) (int64, error)
 	GetAd(ctx context.Context, adID int64) (*models.Ad, error)
-	SetStatus(ctx context.Context, adID int64, published bool) (*models.Ad, error)
-	Update(ctx context.Context, adID int64, title string, text string) (*models.Ad, error)
+	// SetState и Update меняют объявление, только если его текущая версия равна version, увеличивают её
+	// и обновляют DateUpdate; иначе возвращают ErrAdVersionMismatch. Оба выставляют Published по новому
+	// состоянию; Update заменяет заголовок, текст и характеристики и сбрасывает причину отклонения
+	SetState(ctx context.Context, adID int64, state models.AdState, reason string, version int64) (*models.Ad, error)
+	Update(ctx context.Context, adID int64, title string, text string, details models.AdDetails, state models.AdState,
+		version int64) (*models.Ad, error)
+	// AddImage добавляет объявлению изображение со следующим номером и переводит его в state; версия проверяется
+	// и увеличивается так же, как в Update
+	AddImage(ctx context.Context, adID int64, image models.AdImage, state models.AdState, version int64) (*models.Ad, error)
+	// SetDeletedAt переносит объявление в корзину или, при нулевом deletedAt, возвращает из неё;
+	// версия объявления не меняется
+	SetDeletedAt(ctx context.Context, adID int64, deletedAt time.Time) (*models.Ad, error)
+	// DeleteAd удаляет объявление окончательно
 	DeleteAd(ctx context.Context, adID int64) error
+	// GetAds возвращает все объявления, включая лежащие в корзине
 	GetAds(ctx context.Context) ([]*models.Ad, error)
+	// FindAds возвращает объявления по фильтру в порядке filter.SortBy и filter.Order, не больше filter.Limit
+	FindAds(ctx context.Context, filter models.AdFilter) ([]*models.Ad, error)
+	// CountFacets считает объявления выборки по собственным категориям и по городам без учёта регистра
+	// (см. models.CountFacets); порядок, курсор и ограничение фильтра не учитываются
+	CountFacets(ctx context.Context, filter models.AdFilter) (models.AdFacets, error)
+	// CountAds возвращает число объявлений вне корзины и опубликованных среди них, не загружая сами объявления
+	CountAds(ctx context.Context) (total int, published int, err error)
 }
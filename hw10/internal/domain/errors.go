@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Категории доменных ошибок; конкретные ошибки сопоставляются с ними через errors.Is
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrForbidden       = errors.New("forbidden")
+	ErrValidation      = errors.New("validation failed")
+	ErrConflict        = errors.New("conflict")
+	ErrUnauthenticated = errors.New("unauthenticated")
+	ErrPrecondition    = errors.New("precondition failed")
+)
+
+var (
+	ErrEmailTaken    = errors.New("the email is already taken")
+	ErrNickNameTaken = errors.New("the nickname is already taken")
+)
+
+var (
+	ErrAdNotExist   = ErrNotExist{Err: errors.New("the ad does not exist")}
+	ErrUserNotExist = ErrNotExist{Err: errors.New("the user does not exist")}
+	// ErrRevisionNotExist возвращается, если у объявления нет ревизии с запрошенным номером
+	ErrRevisionNotExist = ErrNotExist{Err: errors.New("the revision does not exist")}
+	// ErrImageNotExist возвращается, если у объявления нет изображения с запрошенным номером
+	ErrImageNotExist        = ErrNotExist{Err: errors.New("the image does not exist")}
+	ErrConversationNotExist = ErrNotExist{Err: errors.New("the conversation does not exist")}
+)
+
+// ErrAdVersionMismatch возвращается, если объявление успели изменить после того, как клиент получил его версию
+var ErrAdVersionMismatch = ErrOutdated{Err: errors.New("the ad has been modified, its version does not match")}
+
+// ErrNotExist возвращается хранилищем, если запрошенной записи нет
+type ErrNotExist struct {
+	Err error
+}
+
+func (e ErrNotExist) Error() string {
+	return fmt.Sprintf("%s", e.Err)
+}
+
+func (e ErrNotExist) Unwrap() error {
+	return e.Err
+}
+
+func (e ErrNotExist) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// ErrAlreadyExists возвращается хранилищем, если запись нарушает уникальность (почта или никнейм пользователя)
+type ErrAlreadyExists struct {
+	Err error
+}
+
+func (e ErrAlreadyExists) Error() string {
+	return fmt.Sprintf("%s", e.Err)
+}
+
+func (e ErrAlreadyExists) Unwrap() error {
+	return e.Err
+}
+
+func (e ErrAlreadyExists) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// ErrInvalid оборачивает ошибку проверки входных данных
+type ErrInvalid struct {
+	Err error
+}
+
+func (e ErrInvalid) Error() string {
+	return fmt.Sprintf("%s", e.Err)
+}
+
+func (e ErrInvalid) Unwrap() error {
+	return e.Err
+}
+
+func (e ErrInvalid) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// ErrNotAuthorized оборачивает ошибку отсутствующей или неверной аутентификации
+type ErrNotAuthorized struct {
+	Err error
+}
+
+func (e ErrNotAuthorized) Error() string {
+	return fmt.Sprintf("%s", e.Err)
+}
+
+func (e ErrNotAuthorized) Unwrap() error {
+	return e.Err
+}
+
+func (e ErrNotAuthorized) Is(target error) bool {
+	return target == ErrUnauthenticated
+}
+
+// ErrOutdated возвращается, если запись изменилась с тех пор, как её прочитал клиент
+type ErrOutdated struct {
+	Err error
+}
+
+func (e ErrOutdated) Error() string {
+	return fmt.Sprintf("%s", e.Err)
+}
+
+func (e ErrOutdated) Unwrap() error {
+	return e.Err
+}
+
+func (e ErrOutdated) Is(target error) bool {
+	return target == ErrPrecondition
+}
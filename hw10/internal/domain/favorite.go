@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"context"
+	"homework10/internal/domain/models"
+)
+
+//go:generate mockgen -source=./favorite.go -destination=../service/mock/favorite.go -package=repoMock FavoriteRepository
+type FavoriteRepository interface {
+	// AddFavorite добавляет закладку; повторное добавление оставляет прежний момент AddedAt
+	AddFavorite(ctx context.Context, favorite models.Favorite) error
+	// RemoveFavorite удаляет закладку; отсутствие закладки ошибкой не считается
+	RemoveFavorite(ctx context.Context, userID int64, adID int64) error
+	// GetFavorites возвращает закладки пользователя, начиная с добавленных последними
+	GetFavorites(ctx context.Context, userID int64) ([]models.Favorite, error)
+	// RemoveAdFavorites удаляет объявление из закладок всех пользователей
+	RemoveAdFavorites(ctx context.Context, adID int64) error
+	// RemoveUserFavorites удаляет все закладки пользователя
+	RemoveUserFavorites(ctx context.Context, userID int64) error
+}
@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"context"
+	"homework10/internal/domain/models"
+	"time"
+)
+
+//go:generate mockgen -source=./message.go -destination=../service/mock/message.go -package=repoMock MessageRepository
+type MessageRepository interface {
+	// GetOrAddConversation возвращает беседу покупателя об объявлении из conversation или, если её ещё нет,
+	// сохраняет conversation как новую
+	GetOrAddConversation(ctx context.Context, conversation models.Conversation) (*models.Conversation, error)
+	GetConversation(ctx context.Context, conversationID int64) (*models.Conversation, error)
+	// GetConversations возвращает беседы, в которых пользователь покупатель или продавец
+	GetConversations(ctx context.Context, userID int64) ([]*models.Conversation, error)
+	// AddMessage сохраняет сообщение и переносит LastMessageAt беседы на время сообщения
+	AddMessage(ctx context.Context, message models.Message) (int64, error)
+	// GetMessages возвращает сообщения беседы в порядке отправки
+	GetMessages(ctx context.Context, conversationID int64) ([]*models.Message, error)
+	// MarkRead отмечает прочитанными в момент readAt непрочитанные сообщения беседы, отправленные не readerID,
+	// и возвращает их число
+	MarkRead(ctx context.Context, conversationID int64, readerID int64, readAt time.Time) (int, error)
+}
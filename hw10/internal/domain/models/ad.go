@@ -1,11 +1,97 @@
 package models
 
+import "time"
+
+// AdState этап модерации объявления
+type AdState string
+
+const (
+	// AdDraft черновик: новое объявление или изменённое после проверки
+	AdDraft AdState = "draft"
+	// AdPendingReview объявление отправлено автором на проверку модератору
+	AdPendingReview AdState = "pending_review"
+	AdApproved      AdState = "approved"
+	AdRejected      AdState = "rejected"
+	AdPublished     AdState = "published"
+	// AdArchived объявление снято автором с публикации; его можно опубликовать снова без проверки
+	AdArchived AdState = "archived"
+)
+
+// Ad объявление; Version начинается с 1 и увеличивается при каждом изменении заголовка, текста, статуса
+// или набора изображений.
+// Даты хранятся в UTC, DateUpdate хранилище обновляет вместе с версией.
+// Published совпадает с State == AdPublished и хранится отдельно для выборок по публикации
 type Ad struct {
-	ID           int64  `json:"id"`
-	Title        string `json:"title"`
-	Text         string `json:"text"`
-	UserID       int64  `json:"author_id"`
-	Published    bool   `json:"published"`
-	DateCreation string `json:"date_creation"`
-	DateUpdate   string `json:"date_update"`
+	ID           int64     `json:"id"`
+	Title        string    `json:"title"`
+	Text         string    `json:"text"`
+	UserID       int64     `json:"author_id"`
+	Published    bool      `json:"published"`
+	State        AdState   `json:"state"`
+	DateCreation time.Time `json:"date_creation"`
+	DateUpdate   time.Time `json:"date_update"`
+	Version      int64     `json:"version"`
+
+	// RejectionReason причина, которую модератор указал при отклонении; у остальных состояний пустая
+	RejectionReason string `json:"rejection_reason"`
+	// DeletedAt момент переноса объявления в корзину; нулевое значение - объявление не удалено
+	DeletedAt time.Time `json:"deleted_at"`
+	// Images изображения в порядке загрузки
+	Images []AdImage `json:"images"`
+
+	AdDetails
+}
+
+// Price цена в минимальных единицах валюты (копейках, центах); Currency - код валюты по ISO 4217.
+// Нулевая цена без валюты означает, что цена не указана
+type Price struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// IsSet сообщает, что у объявления указана цена
+func (p Price) IsSet() bool {
+	return p.Currency != ""
+}
+
+// AdDetails характеристики объявления, по которым его ищут в каталоге; пустые значения означают,
+// что характеристика не указана
+type AdDetails struct {
+	Price      Price  `json:"price"`
+	CategoryID string `json:"category_id"`
+	City       string `json:"city"`
+}
+
+// AdImage изображение объявления. ID начинаются с 1 в пределах объявления; сами файлы лежат
+// в хранилище blob.Store под ключами Key (оригинал) и ThumbnailKey (миниатюра в JPEG)
+type AdImage struct {
+	ID           int64     `json:"id"`
+	ContentType  string    `json:"content_type"`
+	Size         int64     `json:"size"`
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
+	Key          string    `json:"key"`
+	ThumbnailKey string    `json:"thumbnail_key"`
+	DateCreation time.Time `json:"date_creation"`
+}
+
+// Image возвращает изображение объявления с номером imageID
+func (a *Ad) Image(imageID int64) (*AdImage, bool) {
+	for i := range a.Images {
+		if a.Images[i].ID == imageID {
+			return &a.Images[i], true
+		}
+	}
+	return nil, false
+}
+
+// IsDeleted сообщает, что объявление лежит в корзине
+func (a *Ad) IsDeleted() bool {
+	return !a.DeletedAt.IsZero()
+}
+
+// TrashedAd объявление в корзине вместе с моментом, после которого оно будет удалено окончательно
+type TrashedAd struct {
+	Ad      *Ad
+	PurgeAt time.Time
 }
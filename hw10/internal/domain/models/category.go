@@ -0,0 +1,8 @@
+package models
+
+// Category категория объявлений; у корневых категорий ParentID пустой
+type Category struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	ParentID string `json:"parent_id"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// AdEventType вид изменения объявления
+type AdEventType string
+
+const (
+	AdCreated       AdEventType = "created"
+	AdUpdated       AdEventType = "updated"
+	AdStatusChanged AdEventType = "status_changed"
+	AdDeleted       AdEventType = "deleted"
+	AdRestored      AdEventType = "restored"
+	// AdModerated объявление отправлено на проверку, одобрено или отклонено
+	AdModerated AdEventType = "moderated"
+	// AdLeft объявление перестало подходить под фильтр подписки или стало недоступно подписчику;
+	// событие несёт только ID объявления
+	AdLeft AdEventType = "left"
+)
+
+// AdEvent изменение объявления; ID событий возрастают в порядке публикации.
+// Ad - состояние объявления после изменения (для удаления - перед ним), у AdLeft - только ID
+type AdEvent struct {
+	ID   int64
+	Type AdEventType
+	Ad   Ad
+	Time time.Time
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Favorite закладка пользователя на опубликованное объявление
+type Favorite struct {
+	UserID  int64
+	AdID    int64
+	AddedAt time.Time
+}
+
+// FavoriteAd объявление из закладок вместе с моментом, когда его добавили
+type FavoriteAd struct {
+	Ad      *Ad
+	AddedAt time.Time
+}
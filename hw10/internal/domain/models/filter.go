@@ -0,0 +1,168 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// DeletedFilter определяет, попадают ли в выборку объявления из корзины
+type DeletedFilter int
+
+const (
+	// WithoutDeleted объявления из корзины не выбираются; значение по умолчанию
+	WithoutDeleted DeletedFilter = iota
+	// OnlyDeleted выбираются только объявления из корзины
+	OnlyDeleted
+	// WithDeleted выбираются все объявления
+	WithDeleted
+)
+
+// AdFilter условия выборки объявлений; нулевые значения полей не ограничивают выборку.
+// Диапазоны дат полуоткрытые: [From, To)
+type AdFilter struct {
+	IDs         []int64
+	Published   *bool
+	State       AdState
+	AuthorIDs   []int64
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	UpdatedFrom time.Time
+	UpdatedTo   time.Time
+	Title       string
+	Deleted     DeletedFilter
+	// PriceMin и PriceMax - границы цены включительно; объявления без цены под них не подходят
+	PriceMin *int64
+	PriceMax *int64
+	Currency string
+	// CategoryIDs категории объявлений; подкатегории в выборку не попадают, их перечисляет вызывающий
+	CategoryIDs []string
+	// City город объявления без учёта регистра
+	City string
+	// VisibleTo, если задан, оставляет только опубликованные объявления и объявления пользователя *VisibleTo.
+	// Анонимному зрителю соответствует ID, которого нет ни у одного пользователя
+	VisibleTo *int64
+
+	// SortBy и Order задают порядок выборки, по умолчанию - по возрастанию ID; равные по полю объявления
+	// упорядочены по ID в том же направлении
+	SortBy AdSortField
+	Order  SortOrder
+	// After - курсор keyset-пагинации: остаются объявления, идущие в этом порядке строго после After.
+	// У курсора учитываются только ID и поле сортировки
+	After *Ad
+	// Limit ограничивает число объявлений выборки; 0 не ограничивает
+	Limit int
+}
+
+// IsEmpty сообщает, что фильтр не задаёт ни одного условия
+func (f AdFilter) IsEmpty() bool {
+	return len(f.IDs) == 0 && f.Published == nil && f.State == "" && len(f.AuthorIDs) == 0 &&
+		f.CreatedFrom.IsZero() && f.CreatedTo.IsZero() &&
+		f.UpdatedFrom.IsZero() && f.UpdatedTo.IsZero() &&
+		f.Title == "" && f.Deleted == WithoutDeleted &&
+		f.PriceMin == nil && f.PriceMax == nil && f.Currency == "" && len(f.CategoryIDs) == 0 && f.City == "" &&
+		f.VisibleTo == nil && f.After == nil
+}
+
+// Match проверяет объявление на соответствие всем условиям фильтра
+func (f AdFilter) Match(ad *Ad) bool {
+	if len(f.IDs) != 0 && !containsID(f.IDs, ad.ID) {
+		return false
+	}
+	if f.Published != nil && ad.Published != *f.Published {
+		return false
+	}
+	if f.VisibleTo != nil && !ad.Published && ad.UserID != *f.VisibleTo {
+		return false
+	}
+	if f.State != "" && ad.State != f.State {
+		return false
+	}
+	if f.After != nil && CompareAds(ad, f.After, f.SortBy, f.Order) <= 0 {
+		return false
+	}
+	if !f.matchDeleted(ad) {
+		return false
+	}
+	if len(f.AuthorIDs) != 0 && !containsID(f.AuthorIDs, ad.UserID) {
+		return false
+	}
+	if !inRange(ad.DateCreation, f.CreatedFrom, f.CreatedTo) || !inRange(ad.DateUpdate, f.UpdatedFrom, f.UpdatedTo) {
+		return false
+	}
+	if !f.matchDetails(ad.AdDetails) {
+		return false
+	}
+	return strings.Contains(ad.Title, f.Title)
+}
+
+// Page упорядочивает подходящие под фильтр объявления по SortBy и Order и оставляет первые Limit из них
+func (f AdFilter) Page(ads []*Ad) []*Ad {
+	sort.Slice(ads, func(i, j int) bool {
+		return CompareAds(ads[i], ads[j], f.SortBy, f.Order) < 0
+	})
+	if f.Limit > 0 && len(ads) > f.Limit {
+		ads = ads[:f.Limit]
+	}
+	return ads
+}
+
+func (f AdFilter) matchDetails(details AdDetails) bool {
+	if f.Currency != "" && details.Price.Currency != f.Currency {
+		return false
+	}
+	if f.PriceMin != nil || f.PriceMax != nil {
+		if !details.Price.IsSet() {
+			return false
+		}
+		if f.PriceMin != nil && details.Price.Amount < *f.PriceMin {
+			return false
+		}
+		if f.PriceMax != nil && details.Price.Amount > *f.PriceMax {
+			return false
+		}
+	}
+	if len(f.CategoryIDs) != 0 && !containsString(f.CategoryIDs, details.CategoryID) {
+		return false
+	}
+	return f.City == "" || strings.EqualFold(details.City, f.City)
+}
+
+func (f AdFilter) matchDeleted(ad *Ad) bool {
+	switch f.Deleted {
+	case OnlyDeleted:
+		return ad.IsDeleted()
+	case WithDeleted:
+		return true
+	default:
+		return !ad.IsDeleted()
+	}
+}
+
+func containsID(ids []int64, id int64) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func inRange(date time.Time, from time.Time, to time.Time) bool {
+	if !from.IsZero() && date.Before(from) {
+		return false
+	}
+	if !to.IsZero() && !date.Before(to) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Conversation переписка покупателя с автором объявления; у объявления одна беседа на покупателя
+type Conversation struct {
+	ID            int64
+	AdID          int64
+	BuyerID       int64
+	SellerID      int64
+	DateCreation  time.Time
+	LastMessageAt time.Time
+}
+
+// HasParticipant сообщает, что пользователь - покупатель или продавец в беседе
+func (c *Conversation) HasParticipant(userID int64) bool {
+	return c.BuyerID == userID || c.SellerID == userID
+}
+
+// Message сообщение беседы; ReadAt - когда его прочитал собеседник, нулевое для непрочитанных
+type Message struct {
+	ID             int64
+	ConversationID int64
+	SenderID       int64
+	Text           string
+	DateCreation   time.Time
+	ReadAt         time.Time
+}
+
+// IsRead сообщает, что собеседник прочитал сообщение
+func (m *Message) IsRead() bool {
+	return !m.ReadAt.IsZero()
+}
+
+// ConversationSummary беседа в списке бесед пользователя: последнее сообщение и число непрочитанных им сообщений
+type ConversationSummary struct {
+	Conversation *Conversation
+	LastMessage  *Message
+	UnreadCount  int
+}
+
+// Inbox беседы пользователя, начиная с последних по времени сообщения, и общее число непрочитанных сообщений
+type Inbox struct {
+	Conversations []*ConversationSummary
+	UnreadCount   int
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+type AdSortField string
+
+const (
+	AdSortByID           AdSortField = "id"
+	AdSortByDateCreation AdSortField = "date_creation"
+	AdSortByDateUpdate   AdSortField = "date_update"
+	AdSortByTitle        AdSortField = "title"
+	// AdSortByRelevance порядок по релевантности, доступен только для полнотекстового поиска
+	AdSortByRelevance AdSortField = "relevance"
+)
+
+type SortOrder string
+
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
+
+// PageParams параметры постраничной выдачи объявлений; пустые значения заменяются значениями по умолчанию
+type PageParams struct {
+	Limit     int
+	PageToken string
+	SortBy    AdSortField
+	Order     SortOrder
+}
+
+// AdsPage страница объявлений; NextPageToken пуст, если страница последняя.
+// Facets считаются по всей выборке, а не только по странице
+type AdsPage struct {
+	Ads           []*Ad
+	NextPageToken string
+	Facets        AdFacets
+}
+
+// FacetCount число объявлений выборки с одним значением характеристики
+type FacetCount struct {
+	Value string
+	Count int
+}
+
+// AdFacets распределение объявлений выборки по категориям и городам, от частых значений к редким.
+// Объявление учитывается в своей категории и во всех родительских
+type AdFacets struct {
+	Categories []FacetCount
+	Cities     []FacetCount
+}
+
+// CompareAds сравнивает объявления в порядке выдачи: по полю сортировки, а при равенстве - по ID.
+// Пустое поле сортировки означает ID, пустой порядок - по возрастанию
+func CompareAds(a, b *Ad, field AdSortField, order SortOrder) int {
+	var result int
+	switch field {
+	case AdSortByDateCreation:
+		result = compareDates(a.DateCreation, b.DateCreation)
+	case AdSortByDateUpdate:
+		result = compareDates(a.DateUpdate, b.DateUpdate)
+	case AdSortByTitle:
+		result = strings.Compare(a.Title, b.Title)
+	}
+	if result == 0 {
+		result = compareIDs(a.ID, b.ID)
+	}
+	if order == SortDesc {
+		return -result
+	}
+	return result
+}
+
+// CountFacets считает объявления по их собственным категориям (без родительских) и по городам без учёта
+// регистра; город называется наименьшим из встретившихся написаний. Значения не упорядочены
+func CountFacets(ads []*Ad) AdFacets {
+	categories := make(map[string]int)
+	cities := make(map[string]FacetCount)
+	for _, ad := range ads {
+		if ad.CategoryID != "" {
+			categories[ad.CategoryID]++
+		}
+		if ad.City == "" {
+			continue
+		}
+		key := strings.ToLower(ad.City)
+		city, ok := cities[key]
+		if !ok || ad.City < city.Value {
+			city.Value = ad.City
+		}
+		city.Count++
+		cities[key] = city
+	}
+
+	facets := AdFacets{
+		Categories: make([]FacetCount, 0, len(categories)),
+		Cities:     make([]FacetCount, 0, len(cities)),
+	}
+	for categoryID, count := range categories {
+		facets.Categories = append(facets.Categories, FacetCount{Value: categoryID, Count: count})
+	}
+	for _, city := range cities {
+		facets.Cities = append(facets.Cities, city)
+	}
+	return facets
+}
+
+func compareIDs(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareDates(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	default:
+		return 0
+	}
+}
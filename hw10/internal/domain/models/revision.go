@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// AdRevisionType вид изменения, записанного в ревизию
+type AdRevisionType string
+
+const (
+	RevisionCreated       AdRevisionType = "created"
+	RevisionUpdated       AdRevisionType = "updated"
+	RevisionStatusChanged AdRevisionType = "status_changed"
+	RevisionRestored      AdRevisionType = "restored"
+)
+
+// FieldChange изменение одного поля объявления
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// AdRevision неизменяемая запись об изменении объявления: кто и когда его поменял, что изменилось
+// и каким объявление стало. Номера ревизий идут подряд с 1 для каждого объявления;
+// RestoredFrom - номер восстановленной ревизии (только для RevisionRestored)
+type AdRevision struct {
+	AdID         int64
+	Number       int64
+	Type         AdRevisionType
+	AuthorID     int64
+	Date         time.Time
+	Title        string
+	Text         string
+	Published    bool
+	Changes      []FieldChange
+	RestoredFrom int64
+}
@@ -0,0 +1,14 @@
+package models
+
+// SearchResult объявление, найденное полнотекстовым поиском; в Snippet совпавшие слова выделены тегом <mark>
+type SearchResult struct {
+	Ad      *Ad
+	Score   float64
+	Snippet string
+}
+
+// SearchPage страница результатов поиска; NextPageToken пуст, если страница последняя
+type SearchPage struct {
+	Results       []*SearchResult
+	NextPageToken string
+}
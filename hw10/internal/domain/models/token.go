@@ -0,0 +1,9 @@
+package models
+
+import "time"
+
+// AuthToken подписанный токен доступа, выданный пользователю при входе
+type AuthToken struct {
+	Token     string
+	ExpiresAt time.Time
+}
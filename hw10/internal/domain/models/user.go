@@ -1,11 +1,43 @@
 package models
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
+
+// Role роль пользователя; пустая роль у пользователей, созданных до появления ролей, означает RoleUser
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
 
 type User struct {
 	ID       int64
 	NickName string
 	Email    string
+	Role     Role
+	// PasswordHash - bcrypt-хеш пароля, сам пароль нигде не хранится
+	PasswordHash []byte
+	// DeletedAt момент удаления пользователя; до окончательного удаления его можно восстановить
+	DeletedAt time.Time
+}
+
+// IsDeleted сообщает, что пользователь удалён и ожидает окончательного удаления
+func (u *User) IsDeleted() bool {
+	return !u.DeletedAt.IsZero()
+}
+
+// Valid сообщает, что роль - одна из ролей сервиса
+func (r Role) Valid() bool {
+	return r == RoleUser || r == RoleModerator || r == RoleAdmin
+}
+
+// IsModerator сообщает, что у пользователя роль модератора
+func (u *User) IsModerator() bool {
+	return u.Role == RoleModerator
 }
 
 func (u *User) String() string {
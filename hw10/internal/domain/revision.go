@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"context"
+	"homework10/internal/domain/models"
+)
+
+//go:generate mockgen -source=./revision.go -destination=../service/mock/revision.go -package=repoMock AdRevisionRepository
+type AdRevisionRepository interface {
+	// AddRevision сохраняет ревизию со следующим номером для объявления и возвращает этот номер
+	AddRevision(ctx context.Context, revision models.AdRevision) (int64, error)
+	// GetRevisions возвращает ревизии объявления по возрастанию номера
+	GetRevisions(ctx context.Context, adID int64) ([]*models.AdRevision, error)
+	GetRevision(ctx context.Context, adID int64, number int64) (*models.AdRevision, error)
+	// DeleteRevisions удаляет все ревизии объявления; объявления без ревизий не считаются ошибкой
+	DeleteRevisions(ctx context.Context, adID int64) error
+}
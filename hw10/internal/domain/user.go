@@ -3,12 +3,23 @@ package domain
 import (
 	"context"
 	"homework10/internal/domain/models"
+	"time"
 )
 
 //go:generate mockgen -source=./user.go -destination=../service/mock/user.go -package=repoMock UserRepository
 type UserRepository interface {
 	GetUser(ctx context.Context, id int64) (*models.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
 	AddUser(ctx context.Context, user models.User) (int64, error)
 	Update(ctx context.Context, userID int64, nickName string, email string) (*models.User, error)
+	SetPasswordHash(ctx context.Context, userID int64, passwordHash []byte) error
+	SetRole(ctx context.Context, userID int64, role models.Role) (*models.User, error)
+	// SetDeletedAt помечает пользователя удалённым или, при нулевом deletedAt, снимает пометку
+	SetDeletedAt(ctx context.Context, userID int64, deletedAt time.Time) (*models.User, error)
+	// GetDeletedUsers возвращает пользователей, удалённых раньше before
+	GetDeletedUsers(ctx context.Context, before time.Time) ([]*models.User, error)
+	// CountUsers возвращает число пользователей, не помеченных удалёнными
+	CountUsers(ctx context.Context) (int, error)
+	// Delete удаляет пользователя окончательно
 	Delete(ctx context.Context, userID int64) error
 }
@@ -0,0 +1,25 @@
+package domain
+
+import "strings"
+
+// FieldError нарушение правила проверки одного поля: поле, правило (required, max, ...) и сообщение
+type FieldError struct {
+	Field   string
+	Rule    string
+	Message string
+}
+
+// ValidationErrors все нарушения, найденные при проверке сущности
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fieldErr := range e {
+		messages = append(messages, fieldErr.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e ValidationErrors) Is(target error) bool {
+	return target == ErrValidation
+}
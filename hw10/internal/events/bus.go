@@ -0,0 +1,189 @@
+package events
+
+import (
+	"errors"
+	"homework10/internal/domain/models"
+	"sync"
+	"time"
+)
+
+var ErrSlowSubscriber = errors.New("the subscriber is too slow to receive ad events")
+
+// Bus рассылает события изменения объявлений подписчикам внутри процесса.
+// Публикация не ждёт подписчиков: подписка, буфер которой переполнен, закрывается с ErrSlowSubscriber.
+// Последние historySize событий хранятся, чтобы переподключившийся подписчик мог их получить
+type Bus struct {
+	subscribers map[*Subscription]struct{}
+	history     []published
+	historySize int
+	lastID      int64
+	closed      bool
+	now         func() time.Time
+	mutex       sync.Mutex
+}
+
+func NewBus(historySize int) *Bus {
+	return &Bus{
+		subscribers: make(map[*Subscription]struct{}),
+		history:     make([]published, 0, historySize),
+		historySize: historySize,
+		now:         time.Now,
+	}
+}
+
+// published событие вместе с состоянием объявления до изменения
+type published struct {
+	event    models.AdEvent
+	previous *models.Ad
+}
+
+// eventFor событие для подписки с фильтром filter; ok = false, если оно подписке не нужно. Объявление,
+// которое подходило под фильтр только до изменения (например, снято с публикации), приходит событием AdLeft
+// с одним ID: новое состояние фильтр не выбирает, и отдавать его подписчику нельзя
+func (p published) eventFor(filter models.AdFilter) (event models.AdEvent, ok bool) {
+	if filter.Match(&p.event.Ad) {
+		return p.event, true
+	}
+	if p.previous == nil || !filter.Match(p.previous) {
+		return models.AdEvent{}, false
+	}
+	return models.AdEvent{ID: p.event.ID, Type: models.AdLeft, Ad: models.Ad{ID: p.event.Ad.ID}, Time: p.event.Time}, true
+}
+
+// Publish присваивает событию очередной номер и отправляет его подходящим подпискам. previous - состояние
+// объявления до изменения; nil, если его нет (создание) или оно совпадает с ad (удаление)
+func (b *Bus) Publish(eventType models.AdEventType, previous *models.Ad, ad models.Ad) models.AdEvent {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.lastID++
+	p := published{
+		event:    models.AdEvent{ID: b.lastID, Type: eventType, Ad: ad, Time: b.now().UTC()},
+		previous: previous,
+	}
+	if b.historySize > 0 {
+		if len(b.history) == b.historySize {
+			copy(b.history, b.history[1:])
+			b.history = b.history[:len(b.history)-1]
+		}
+		b.history = append(b.history, p)
+	}
+	for sub := range b.subscribers {
+		event, ok := p.eventFor(sub.filter)
+		if !ok {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			b.unsubscribe(sub, ErrSlowSubscriber)
+		}
+	}
+	return p.event
+}
+
+// Subscribe подписывает на события объявлений, подходящих под фильтр; buffer - сколько событий
+// подписчик может не забирать, прежде чем подписка будет закрыта
+func (b *Bus) Subscribe(filter models.AdFilter, buffer int) *Subscription {
+	sub := &Subscription{
+		bus:    b,
+		filter: filter,
+		events: make(chan models.AdEvent, buffer),
+	}
+	b.mutex.Lock()
+	b.subscribe(sub)
+	b.mutex.Unlock()
+	return sub
+}
+
+// Resume подписывает так же, как Subscribe, но сначала отправляет подходящие под фильтр события
+// из истории с номером больше lastEventID. complete = false, если часть событий после lastEventID
+// уже вытеснена из истории или номер неизвестен шине (например, после перезапуска)
+func (b *Bus) Resume(filter models.AdFilter, buffer int, lastEventID int64) (sub *Subscription, complete bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	missed := make([]models.AdEvent, 0)
+	for _, p := range b.history {
+		if p.event.ID <= lastEventID {
+			continue
+		}
+		if event, ok := p.eventFor(filter); ok {
+			missed = append(missed, event)
+		}
+	}
+	switch {
+	case lastEventID > b.lastID:
+		complete = false
+	case lastEventID == b.lastID:
+		complete = true
+	default:
+		complete = len(b.history) != 0 && b.history[0].event.ID <= lastEventID+1
+	}
+
+	sub = &Subscription{
+		bus:    b,
+		filter: filter,
+		events: make(chan models.AdEvent, buffer+len(missed)),
+	}
+	for _, event := range missed {
+		sub.events <- event
+	}
+	b.subscribe(sub)
+	return sub, complete
+}
+
+// Close закрывает все подписки, в том числе будущие: после остановки сервера потоки событий
+// должны завершиться, иначе они не дадут серверу остановиться
+func (b *Bus) Close() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.closed = true
+	for sub := range b.subscribers {
+		b.unsubscribe(sub, nil)
+	}
+}
+
+// subscribe вызывается под b.mutex
+func (b *Bus) subscribe(sub *Subscription) {
+	if b.closed {
+		close(sub.events)
+		return
+	}
+	b.subscribers[sub] = struct{}{}
+}
+
+// unsubscribe вызывается под b.mutex, поэтому канал не закрывается во время отправки в него
+func (b *Bus) unsubscribe(sub *Subscription, err error) {
+	if _, ok := b.subscribers[sub]; !ok {
+		return
+	}
+	delete(b.subscribers, sub)
+	sub.err = err
+	close(sub.events)
+}
+
+type Subscription struct {
+	bus    *Bus
+	filter models.AdFilter
+	events chan models.AdEvent
+	err    error
+}
+
+// Events канал событий; закрывается после Close или при переполнении буфера
+func (s *Subscription) Events() <-chan models.AdEvent {
+	return s.events
+}
+
+// Err причина закрытия подписки; nil, если подписка закрыта через Close или ещё активна
+func (s *Subscription) Err() error {
+	s.bus.mutex.Lock()
+	defer s.bus.mutex.Unlock()
+	return s.err
+}
+
+func (s *Subscription) Close() {
+	s.bus.mutex.Lock()
+	defer s.bus.mutex.Unlock()
+	s.bus.unsubscribe(s, nil)
+}
@@ -0,0 +1,223 @@
+package events
+
+import (
+	"homework10/internal/domain/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func eventIDs(sub *Subscription) []int64 {
+	ids := make([]int64, 0)
+	for {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				return ids
+			}
+			ids = append(ids, event.ID)
+		default:
+			return ids
+		}
+	}
+}
+
+func TestBus_Publish(t *testing.T) {
+	published := true
+	tests := []struct {
+		name     string
+		filter   models.AdFilter
+		expected []int64
+	}{
+		{
+			name:     "empty filter receives all events",
+			expected: []int64{1, 2, 3, 4},
+		},
+		{
+			name:     "filter by author",
+			filter:   models.AdFilter{AuthorIDs: []int64{1}},
+			expected: []int64{1, 3},
+		},
+		{
+			name:     "filter by status",
+			filter:   models.AdFilter{Published: &published},
+			expected: []int64{2, 4},
+		},
+		{
+			name:     "filter by state",
+			filter:   models.AdFilter{State: models.AdArchived},
+			expected: []int64{4},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			bus := NewBus(0)
+			sub := bus.Subscribe(tc.filter, 10)
+			defer sub.Close()
+
+			bus.Publish(models.AdCreated, nil, models.Ad{ID: 0, UserID: 1})
+			bus.Publish(models.AdStatusChanged, nil, models.Ad{ID: 1, UserID: 2, Published: true})
+			bus.Publish(models.AdDeleted, nil, models.Ad{ID: 0, UserID: 1})
+			// снятое с публикации объявление уже не подходит под фильтр, но подписчик должен узнать об этом
+			bus.Publish(models.AdStatusChanged, &models.Ad{ID: 1, UserID: 2, Published: true, State: models.AdPublished},
+				models.Ad{ID: 1, UserID: 2, State: models.AdArchived})
+
+			assert.Equal(t, tc.expected, eventIDs(sub))
+			assert.NoError(t, sub.Err())
+		})
+	}
+}
+
+func TestBus_PublishLeftFilter(t *testing.T) {
+	published := true
+	bus := NewBus(0)
+	sub := bus.Subscribe(models.AdFilter{Published: &published}, 10)
+	defer sub.Close()
+
+	// правка опубликованного объявления возвращает его в черновик: новое содержимое подписчику не положено
+	bus.Publish(models.AdUpdated, &models.Ad{ID: 1, UserID: 2, Title: "title", Published: true},
+		models.Ad{ID: 1, UserID: 2, Title: "UNMODERATED", Text: "SECRET", State: models.AdDraft})
+
+	event := <-sub.Events()
+	assert.Equal(t, models.AdLeft, event.Type)
+	assert.Equal(t, models.Ad{ID: 1}, event.Ad)
+	assert.Equal(t, int64(1), event.ID)
+}
+
+func TestBus_SlowSubscriber(t *testing.T) {
+	bus := NewBus(0)
+	slow := bus.Subscribe(models.AdFilter{}, 1)
+	fast := bus.Subscribe(models.AdFilter{}, 10)
+	defer fast.Close()
+
+	bus.Publish(models.AdCreated, nil, models.Ad{ID: 0})
+	bus.Publish(models.AdUpdated, nil, models.Ad{ID: 0})
+
+	assert.Equal(t, []int64{1}, eventIDs(slow))
+	_, ok := <-slow.Events()
+	assert.False(t, ok)
+	assert.ErrorIs(t, slow.Err(), ErrSlowSubscriber)
+	assert.Equal(t, []int64{1, 2}, eventIDs(fast))
+
+	slow.Close()
+	assert.ErrorIs(t, slow.Err(), ErrSlowSubscriber)
+}
+
+func TestSubscription_Close(t *testing.T) {
+	bus := NewBus(0)
+	sub := bus.Subscribe(models.AdFilter{}, 1)
+	sub.Close()
+	sub.Close()
+
+	bus.Publish(models.AdCreated, nil, models.Ad{ID: 0})
+	_, ok := <-sub.Events()
+	assert.False(t, ok)
+	assert.NoError(t, sub.Err())
+}
+
+func TestBus_Resume(t *testing.T) {
+	bus := NewBus(3)
+	for i := int64(0); i < 5; i++ {
+		bus.Publish(models.AdCreated, nil, models.Ad{ID: i, UserID: i % 2})
+	}
+
+	tests := []struct {
+		name        string
+		filter      models.AdFilter
+		lastEventID int64
+		expected    []int64
+		complete    bool
+	}{
+		{
+			name:        "all missed events are in history",
+			lastEventID: 3,
+			expected:    []int64{4, 5},
+			complete:    true,
+		},
+		{
+			name:        "oldest event in history follows last event",
+			lastEventID: 2,
+			expected:    []int64{3, 4, 5},
+			complete:    true,
+		},
+		{
+			name:        "missed events are evicted",
+			lastEventID: 1,
+			expected:    []int64{3, 4, 5},
+			complete:    false,
+		},
+		{
+			name:        "unknown event id",
+			lastEventID: 10,
+			expected:    []int64{},
+			complete:    false,
+		},
+		{
+			name:        "up to date",
+			lastEventID: 5,
+			expected:    []int64{},
+			complete:    true,
+		},
+		{
+			name:        "history is filtered",
+			filter:      models.AdFilter{AuthorIDs: []int64{1}},
+			lastEventID: 2,
+			expected:    []int64{4},
+			complete:    true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			sub, complete := bus.Resume(tc.filter, 1, tc.lastEventID)
+			defer sub.Close()
+			assert.Equal(t, tc.complete, complete)
+			assert.Equal(t, tc.expected, eventIDs(sub))
+		})
+	}
+}
+
+func TestBus_ResumeUnpublished(t *testing.T) {
+	published := true
+	bus := NewBus(10)
+	bus.Publish(models.AdStatusChanged, &models.Ad{ID: 0}, models.Ad{ID: 0, Published: true})
+	bus.Publish(models.AdStatusChanged, &models.Ad{ID: 0, Published: true}, models.Ad{ID: 0})
+	bus.Publish(models.AdUpdated, &models.Ad{ID: 0}, models.Ad{ID: 0, Title: "edited"})
+
+	sub, complete := bus.Resume(models.AdFilter{Published: &published}, 1, 0)
+	defer sub.Close()
+	assert.True(t, complete)
+	assert.Equal(t, models.AdStatusChanged, (<-sub.Events()).Type)
+	left := <-sub.Events()
+	assert.Equal(t, models.AdEvent{ID: 2, Type: models.AdLeft, Ad: models.Ad{ID: 0}, Time: left.Time}, left)
+	assert.Empty(t, eventIDs(sub))
+}
+
+func TestBus_ResumeThenLive(t *testing.T) {
+	bus := NewBus(10)
+	bus.Publish(models.AdCreated, nil, models.Ad{ID: 0})
+	bus.Publish(models.AdUpdated, nil, models.Ad{ID: 0})
+
+	sub, complete := bus.Resume(models.AdFilter{}, 1, 1)
+	defer sub.Close()
+	assert.True(t, complete)
+	bus.Publish(models.AdDeleted, nil, models.Ad{ID: 0})
+	assert.Equal(t, []int64{2, 3}, eventIDs(sub))
+}
+
+func TestBus_Close(t *testing.T) {
+	bus := NewBus(10)
+	sub := bus.Subscribe(models.AdFilter{}, 1)
+	bus.Publish(models.AdCreated, nil, models.Ad{ID: 0})
+	bus.Close()
+
+	assert.Equal(t, []int64{1}, eventIDs(sub))
+	_, ok := <-sub.Events()
+	assert.False(t, ok)
+	assert.NoError(t, sub.Err())
+
+	resumed, _ := bus.Resume(models.AdFilter{}, 1, 0)
+	assert.Equal(t, []int64{1}, eventIDs(resumed))
+	_, ok = <-resumed.Events()
+	assert.False(t, ok)
+	resumed.Close()
+}
@@ -0,0 +1,194 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"homework10/internal/auth"
+)
+
+const (
+	// DefaultTTL сколько хранится ответ на запрос с ключом идемпотентности
+	DefaultTTL = 24 * time.Hour
+	// MaxKeyLen максимальная длина ключа идемпотентности
+	MaxKeyLen = 255
+	// MaxBodySize максимальный размер тела HTTP-запроса с ключом идемпотентности: тело читается в память целиком
+	MaxBodySize = 1 << 20
+
+	// HTTPHeader заголовок HTTP-запроса с ключом идемпотентности
+	HTTPHeader = "Idempotency-Key"
+	// GRPCMetadata ключ метаданных gRPC-вызова с ключом идемпотентности
+	GRPCMetadata = "idempotency-key"
+	// ReplayedHeader заголовок (и ключ метаданных gRPC) повторно отданного сохранённого ответа
+	ReplayedHeader = "Idempotent-Replayed"
+)
+
+var (
+	ErrKeyTooLong = errors.New("the idempotency key must be at most 255 characters")
+	// ErrKeyReused ключ уже использован с другим запросом
+	ErrKeyReused = errors.New("the idempotency key was already used with a different request")
+	// ErrInProgress запрос с тем же ключом ещё выполняется
+	ErrInProgress = errors.New("a request with the same idempotency key is still in progress")
+)
+
+// httpRoutes маршруты gin (метод и шаблон пути), которые принимают ключ идемпотентности
+var httpRoutes = map[string]bool{
+	"POST /api/v1/ads/":             true,
+	"PUT /api/v1/ads/:ad_id/status": true,
+	"DELETE /api/v1/ads/:ad_id":     true,
+	"POST /api/v1/users":            true,
+}
+
+// grpcMethods gRPC-методы, которые принимают ключ идемпотентности
+var grpcMethods = map[string]bool{
+	"/service.AdService/CreateAd":       true,
+	"/service.AdService/ChangeAdStatus": true,
+	"/service.AdService/DeleteAd":       true,
+	"/service.UserService/CreateUser":   true,
+}
+
+// HTTPRoute сообщает, принимает ли HTTP-маршрут ключ идемпотентности
+func HTTPRoute(method string, path string) bool {
+	return httpRoutes[method+" "+path]
+}
+
+// GRPCMethod сообщает, принимает ли gRPC-метод ключ идемпотентности
+func GRPCMethod(fullMethod string) bool {
+	return grpcMethods[fullMethod]
+}
+
+// Store хранит в памяти ответы на запросы с ключами идемпотентности, чтобы повтор запроса
+// получил тот же ответ, а не выполнился ещё раз. Ключи разных пользователей не пересекаются
+type Store struct {
+	// TTL сколько хранится ответ; по истечении ключ можно использовать заново
+	TTL time.Duration
+
+	entries map[entryKey]*entry
+	now     func() time.Time
+	mutex   sync.Mutex
+}
+
+type entryKey struct {
+	scope string
+	key   string
+}
+
+type entry struct {
+	fingerprint string
+	// response сохранённый ответ; nil, пока первый запрос выполняется
+	response  any
+	expiresAt time.Time
+}
+
+func NewStore(ttl time.Duration) *Store {
+	return &Store{
+		TTL:     ttl,
+		entries: make(map[entryKey]*entry),
+		now:     time.Now,
+	}
+}
+
+// Begin начинает запрос с ключом key. Если ответ на такой запрос уже сохранён, возвращает его,
+// иначе резервирует ключ, и запрос нужно выполнить и завершить через Complete или Release.
+// ErrKeyReused - ключ использован с запросом с другим отпечатком, ErrInProgress - такой запрос ещё выполняется
+func (s *Store) Begin(scope string, key string, fingerprint string) (any, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	k := entryKey{scope: scope, key: key}
+	e, ok := s.entries[k]
+	if !ok || !now.Before(e.expiresAt) {
+		s.entries[k] = &entry{fingerprint: fingerprint, expiresAt: now.Add(s.TTL)}
+		return nil, nil
+	}
+	if e.fingerprint != fingerprint {
+		return nil, ErrKeyReused
+	}
+	if e.response == nil {
+		return nil, ErrInProgress
+	}
+	return e.response, nil
+}
+
+// Complete сохраняет ответ на запрос, начатый Begin; TTL отсчитывается от сохранения
+func (s *Store) Complete(scope string, key string, response any) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if e, ok := s.entries[entryKey{scope: scope, key: key}]; ok {
+		e.response = response
+		e.expiresAt = s.now().Add(s.TTL)
+	}
+}
+
+// Release освобождает ключ запроса, начатого Begin, не сохраняя ответ: повтор выполнится заново
+func (s *Store) Release(scope string, key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.entries, entryKey{scope: scope, key: key})
+}
+
+// Evict удаляет истёкшие ответы и возвращает их число
+func (s *Store) Evict() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	evicted := 0
+	for k, e := range s.entries {
+		if !now.Before(e.expiresAt) {
+			delete(s.entries, k)
+			evicted++
+		}
+	}
+	return evicted
+}
+
+// Run удаляет истёкшие ответы с периодом interval, пока не завершится контекст
+func (s *Store) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			s.Evict()
+		}
+	}
+}
+
+// Scope пространство ключей клиента: аутентифицированного пользователя, а для запросов без токена -
+// адреса клиента и маршрута, чтобы анонимные клиенты не получали чужие сохранённые ответы
+func Scope(ctx context.Context, clientIP string, route string) string {
+	if userID, ok := auth.UserIDFromContext(ctx); ok {
+		return "user:" + strconv.FormatInt(userID, 10)
+	}
+	return "ip:" + clientIP + " " + route
+}
+
+// Fingerprint отпечаток запроса из частей, которые должны совпасть у повторов
+func Fingerprint(parts ...[]byte) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		hash.Write([]byte(strconv.Itoa(len(part))))
+		hash.Write([]byte{':'})
+		hash.Write(part)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// CheckKey проверяет ключ идемпотентности из запроса
+func CheckKey(key string) error {
+	if len(key) > MaxKeyLen {
+		return ErrKeyTooLong
+	}
+	return nil
+}
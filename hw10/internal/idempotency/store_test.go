@@ -0,0 +1,90 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"homework10/internal/auth"
+)
+
+func newTestStore(ttl time.Duration) (*Store, *time.Time) {
+	now := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := NewStore(ttl)
+	store.now = func() time.Time { return now }
+	return store, &now
+}
+
+func TestStore_Begin(t *testing.T) {
+	store, now := newTestStore(time.Hour)
+
+	stored, err := store.Begin("user:1", "key", "request")
+	assert.NoError(t, err)
+	assert.Nil(t, stored)
+
+	_, err = store.Begin("user:1", "key", "request")
+	assert.ErrorIs(t, err, ErrInProgress)
+	_, err = store.Begin("user:1", "key", "another request")
+	assert.ErrorIs(t, err, ErrKeyReused)
+
+	store.Complete("user:1", "key", "response")
+	stored, err = store.Begin("user:1", "key", "request")
+	assert.NoError(t, err)
+	assert.Equal(t, "response", stored)
+	_, err = store.Begin("user:1", "key", "another request")
+	assert.ErrorIs(t, err, ErrKeyReused)
+
+	stored, err = store.Begin("user:2", "key", "another request")
+	assert.NoError(t, err, "scopes do not clash")
+	assert.Nil(t, stored)
+
+	*now = now.Add(time.Hour)
+	stored, err = store.Begin("user:1", "key", "another request")
+	assert.NoError(t, err, "the key can be reused after the TTL")
+	assert.Nil(t, stored)
+}
+
+func TestStore_Release(t *testing.T) {
+	store, _ := newTestStore(time.Hour)
+
+	_, err := store.Begin("user:1", "key", "request")
+	assert.NoError(t, err)
+	store.Release("user:1", "key")
+
+	stored, err := store.Begin("user:1", "key", "another request")
+	assert.NoError(t, err)
+	assert.Nil(t, stored)
+}
+
+func TestStore_Evict(t *testing.T) {
+	store, now := newTestStore(time.Hour)
+
+	_, _ = store.Begin("user:1", "first", "request")
+	store.Complete("user:1", "first", "response")
+	*now = now.Add(30 * time.Minute)
+	_, _ = store.Begin("user:1", "second", "request")
+	store.Complete("user:1", "second", "response")
+	*now = now.Add(30 * time.Minute)
+
+	assert.Equal(t, 1, store.Evict())
+	assert.Len(t, store.entries, 1)
+	assert.Contains(t, store.entries, entryKey{scope: "user:1", key: "second"})
+}
+
+func TestScope(t *testing.T) {
+	assert.Equal(t, "ip:10.0.0.1 POST /api/v1/users", Scope(context.Background(), "10.0.0.1", "POST /api/v1/users"))
+	assert.NotEqual(t, Scope(context.Background(), "10.0.0.1", "POST /api/v1/users"),
+		Scope(context.Background(), "10.0.0.2", "POST /api/v1/users"), "anonymous clients do not share keys")
+	assert.Equal(t, "user:0", Scope(auth.WithUserID(context.Background(), 0), "10.0.0.1", "POST /api/v1/users"))
+}
+
+func TestFingerprint(t *testing.T) {
+	assert.Equal(t, Fingerprint([]byte("POST"), []byte("/ads")), Fingerprint([]byte("POST"), []byte("/ads")))
+	assert.NotEqual(t, Fingerprint([]byte("ab"), []byte("c")), Fingerprint([]byte("a"), []byte("bc")))
+}
+
+func TestCheckKey(t *testing.T) {
+	assert.NoError(t, CheckKey("6f1c2a4e-8a8b-4b8e-9d51-1d3c1f5a7b21"))
+	assert.ErrorIs(t, CheckKey(string(make([]byte, MaxKeyLen+1))), ErrKeyTooLong)
+}
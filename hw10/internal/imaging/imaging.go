@@ -0,0 +1,116 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"io"
+	"net/http"
+
+	// декодеры форматов из supportedTypes
+	_ "image/gif"
+	_ "image/png"
+)
+
+// thumbnailQuality качество JPEG миниатюр
+const thumbnailQuality = 85
+
+var (
+	ErrUnsupportedType = errors.New("only JPEG, PNG and GIF images are supported")
+	ErrCorrupt         = errors.New("the file is not a valid image")
+)
+
+// supportedTypes MIME-типы, которые определяет http.DetectContentType и умеет декодировать пакет image
+var supportedTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// ErrTooLarge возвращается, если в изображении больше пикселей, чем разрешено
+type ErrTooLarge struct {
+	MaxPixels int
+}
+
+func (e ErrTooLarge) Error() string {
+	return fmt.Sprintf("the image must have at most %d pixels", e.MaxPixels)
+}
+
+// Decode определяет MIME-тип данных по их содержимому и декодирует изображение (у GIF - первый кадр).
+// Размер проверяется по заголовку до декодирования, чтобы маленький файл не развернулся в огромный растр
+func Decode(data []byte, maxPixels int) (image.Image, string, error) {
+	contentType := http.DetectContentType(data)
+	if !supportedTypes[contentType] {
+		return nil, "", ErrUnsupportedType
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", ErrCorrupt
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, "", ErrCorrupt
+	}
+	if config.Width*config.Height > maxPixels {
+		return nil, "", ErrTooLarge{MaxPixels: maxPixels}
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", ErrCorrupt
+	}
+	return img, contentType, nil
+}
+
+// Thumbnail уменьшает img так, чтобы большая сторона не превышала size, усредняя попадающие в пиксель
+// миниатюры пиксели оригинала. Прозрачные участки заливаются белым; изображение меньше size не увеличивается
+func Thumbnail(img image.Image, size int) *image.RGBA {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	dstW, dstH := srcW, srcH
+	if srcW > size || srcH > size {
+		if srcW >= srcH {
+			dstW, dstH = size, max(1, srcH*size/srcW)
+		} else {
+			dstW, dstH = max(1, srcW*size/srcH), size
+		}
+	}
+
+	src := image.NewRGBA(image.Rect(0, 0, srcW, srcH))
+	draw.Draw(src, src.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Over)
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0, y1 := y*srcH/dstH, max((y+1)*srcH/dstH, y*srcH/dstH+1)
+		for x := 0; x < dstW; x++ {
+			x0, x1 := x*srcW/dstW, max((x+1)*srcW/dstW, x*srcW/dstW+1)
+			var r, g, b, n int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					r += int(row[sx*4])
+					g += int(row[sx*4+1])
+					b += int(row[sx*4+2])
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: 0xff})
+		}
+	}
+	return dst
+}
+
+// EncodeThumbnail уменьшает img функцией Thumbnail и записывает результат в w в формате JPEG
+func EncodeThumbnail(w io.Writer, img image.Image, size int) error {
+	return jpeg.Encode(w, Thumbnail(img, size), &jpeg.Options{Quality: thumbnailQuality})
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,93 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encode(t *testing.T, format string, img image.Image) []byte {
+	var buf bytes.Buffer
+	switch format {
+	case "png":
+		require.NoError(t, png.Encode(&buf, img))
+	case "jpeg":
+		require.NoError(t, jpeg.Encode(&buf, img, nil))
+	case "gif":
+		require.NoError(t, gif.Encode(&buf, img, nil))
+	}
+	return buf.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+
+	testTable := []struct {
+		name        string
+		data        []byte
+		maxPixels   int
+		contentType string
+		wantErr     error
+	}{
+		{name: "png", data: encode(t, "png", img), maxPixels: 800, contentType: "image/png"},
+		{name: "jpeg", data: encode(t, "jpeg", img), maxPixels: 800, contentType: "image/jpeg"},
+		{name: "gif", data: encode(t, "gif", img), maxPixels: 800, contentType: "image/gif"},
+		{name: "text", data: []byte("definitely not an image"), maxPixels: 800, wantErr: ErrUnsupportedType},
+		{name: "truncated png", data: encode(t, "png", img)[:20], maxPixels: 800, wantErr: ErrCorrupt},
+		{name: "too many pixels", data: encode(t, "png", img), maxPixels: 799, wantErr: ErrTooLarge{MaxPixels: 799}},
+	}
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			decoded, contentType, err := Decode(testCase.data, testCase.maxPixels)
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.contentType, contentType)
+			assert.Equal(t, image.Rect(0, 0, 40, 20), decoded.Bounds())
+		})
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	testTable := []struct {
+		name   string
+		bounds image.Rectangle
+		size   int
+		want   image.Rectangle
+	}{
+		{name: "landscape", bounds: image.Rect(0, 0, 400, 100), size: 200, want: image.Rect(0, 0, 200, 50)},
+		{name: "portrait", bounds: image.Rect(0, 0, 100, 400), size: 200, want: image.Rect(0, 0, 50, 200)},
+		{name: "small image is not enlarged", bounds: image.Rect(0, 0, 30, 20), size: 200, want: image.Rect(0, 0, 30, 20)},
+		{name: "thin line keeps one pixel", bounds: image.Rect(0, 0, 1000, 1), size: 100, want: image.Rect(0, 0, 100, 1)},
+		{name: "offset bounds", bounds: image.Rect(10, 10, 410, 110), size: 200, want: image.Rect(0, 0, 200, 50)},
+	}
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			thumbnail := Thumbnail(image.NewRGBA(testCase.bounds), testCase.size)
+			assert.Equal(t, testCase.want, thumbnail.Bounds())
+		})
+	}
+}
+
+func TestThumbnail_Colors(t *testing.T) {
+	// левая половина красная, правая прозрачная
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 2; x++ {
+			img.Set(x, y, color.NRGBA{R: 0xff, A: 0xff})
+		}
+	}
+	thumbnail := Thumbnail(img, 2)
+
+	assert.Equal(t, color.RGBA{R: 0xff, A: 0xff}, thumbnail.RGBAAt(0, 0))
+	assert.Equal(t, color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, thumbnail.RGBAAt(1, 0), "transparent pixels become white")
+}
@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// businessTimeout сколько сбор бизнес-метрик может ждать хранилище при каждом опросе
+const businessTimeout = 5 * time.Second
+
+type AdCounter interface {
+	CountAds(ctx context.Context) (total int, published int, err error)
+}
+
+type UserCounter interface {
+	CountUsers(ctx context.Context) (int, error)
+}
+
+// businessCollector считает объявления и пользователей при каждом опросе /metrics, поэтому значения
+// не расходятся с хранилищем после перезапуска или изменений в обход сервисов
+type businessCollector struct {
+	ads   AdCounter
+	users UserCounter
+
+	adsTotal     *prometheus.Desc
+	adsPublished *prometheus.Desc
+	usersTotal   *prometheus.Desc
+}
+
+// RegisterBusiness добавляет метрики числа объявлений (всех и опубликованных) и пользователей.
+// Удалённые в корзину не учитываются
+func (m *Metrics) RegisterBusiness(ads AdCounter, users UserCounter) {
+	m.registry.MustRegister(&businessCollector{
+		ads:   ads,
+		users: users,
+		adsTotal: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "ads"),
+			"Number of ads that are not in the trash.", nil, nil),
+		adsPublished: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "ads_published"),
+			"Number of published ads.", nil, nil),
+		usersTotal: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "users"),
+			"Number of users that are not in the trash.", nil, nil),
+	})
+}
+
+func (c *businessCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.adsTotal
+	ch <- c.adsPublished
+	ch <- c.usersTotal
+}
+
+// Collect пропускает метрики, которые не удалось посчитать, чтобы остальные метрики опроса не потерялись
+func (c *businessCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), businessTimeout)
+	defer cancel()
+
+	if total, published, err := c.ads.CountAds(ctx); err != nil {
+		log.Printf("can't count ads for metrics: %s", err.Error())
+	} else {
+		ch <- prometheus.MustNewConstMetric(c.adsTotal, prometheus.GaugeValue, float64(total))
+		ch <- prometheus.MustNewConstMetric(c.adsPublished, prometheus.GaugeValue, float64(published))
+	}
+	if users, err := c.users.CountUsers(ctx); err != nil {
+		log.Printf("can't count users for metrics: %s", err.Error())
+	} else {
+		ch <- prometheus.MustNewConstMetric(c.usersTotal, prometheus.GaugeValue, float64(users))
+	}
+}
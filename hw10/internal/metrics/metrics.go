@@ -0,0 +1,133 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "homework10"
+
+const (
+	resultOK    = "ok"
+	resultError = "error"
+
+	// unmatchedRoute метка запросов, не попавших ни в один маршрут, чтобы произвольные пути
+	// не порождали новые ряды
+	unmatchedRoute = "unmatched"
+)
+
+// Metrics метрики сервера в собственном реестре Prometheus
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	httpInFlight *prometheus.GaugeVec
+
+	grpcRequests *prometheus.CounterVec
+	grpcDuration *prometheus.HistogramVec
+	grpcInFlight *prometheus.GaugeVec
+
+	repositoryDuration *prometheus.HistogramVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of HTTP requests by route, method and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route, method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		httpInFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "http_requests_in_flight",
+			Help:      "Number of HTTP requests being served by route and method.",
+		}, []string{"method", "route"}),
+		grpcRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "grpc_requests_total",
+			Help:      "Number of gRPC calls by method and status code.",
+		}, []string{"method", "code"}),
+		grpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "grpc_request_duration_seconds",
+			Help:      "gRPC call latency by method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "code"}),
+		grpcInFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "grpc_requests_in_flight",
+			Help:      "Number of gRPC calls being served by method.",
+		}, []string{"method"}),
+		repositoryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "repository_operation_duration_seconds",
+			Help:      "Repository operation latency by repository, operation and result (ok or error).",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"repository", "operation", "result"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests, m.httpDuration, m.httpInFlight,
+		m.grpcRequests, m.grpcDuration, m.grpcInFlight,
+		m.repositoryDuration,
+	)
+	return m
+}
+
+// Handler отдаёт метрики в текстовом формате Prometheus
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// TrackHTTP учитывает начало HTTP-запроса к маршруту route (шаблону пути gin); возвращённая функция
+// завершает запрос с кодом ответа status
+func (m *Metrics) TrackHTTP(method string, route string) func(status int) {
+	if route == "" {
+		route = unmatchedRoute
+	}
+	started := time.Now()
+	inFlight := m.httpInFlight.WithLabelValues(method, route)
+	inFlight.Inc()
+	return func(status int) {
+		inFlight.Dec()
+		code := strconv.Itoa(status)
+		m.httpRequests.WithLabelValues(method, route, code).Inc()
+		m.httpDuration.WithLabelValues(method, route, code).Observe(time.Since(started).Seconds())
+	}
+}
+
+// TrackGRPC учитывает начало вызова gRPC-метода fullMethod; возвращённая функция завершает вызов
+// со статусом code
+func (m *Metrics) TrackGRPC(fullMethod string) func(code string) {
+	started := time.Now()
+	inFlight := m.grpcInFlight.WithLabelValues(fullMethod)
+	inFlight.Inc()
+	return func(code string) {
+		inFlight.Dec()
+		m.grpcRequests.WithLabelValues(fullMethod, code).Inc()
+		m.grpcDuration.WithLabelValues(fullMethod, code).Observe(time.Since(started).Seconds())
+	}
+}
+
+// ObserveRepository учитывает операцию operation хранилища repository, начатую в started и завершённую с err
+func (m *Metrics) ObserveRepository(repository string, operation string, started time.Time, err error) {
+	result := resultOK
+	if err != nil {
+		result = resultError
+	}
+	m.repositoryDuration.WithLabelValues(repository, operation, result).Observe(time.Since(started).Seconds())
+}
@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCounters struct {
+	err error
+}
+
+func (c testCounters) CountAds(context.Context) (int, int, error) {
+	return 5, 2, c.err
+}
+
+func (c testCounters) CountUsers(context.Context) (int, error) {
+	return 3, nil
+}
+
+func scrape(t *testing.T, m *Metrics) string {
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	body, err := io.ReadAll(w.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMetrics_TrackHTTP(t *testing.T) {
+	m := New()
+
+	done := m.TrackHTTP(http.MethodGet, "/api/v1/ads/:ad_id")
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.httpInFlight.WithLabelValues(http.MethodGet, "/api/v1/ads/:ad_id")))
+	done(http.StatusNotFound)
+	m.TrackHTTP(http.MethodGet, "")(http.StatusNotFound)
+
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.httpInFlight.WithLabelValues(http.MethodGet, "/api/v1/ads/:ad_id")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.httpRequests.WithLabelValues(http.MethodGet, "/api/v1/ads/:ad_id", "404")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.httpRequests.WithLabelValues(http.MethodGet, unmatchedRoute, "404")))
+	assert.Equal(t, 2, testutil.CollectAndCount(m.httpDuration))
+}
+
+func TestMetrics_TrackGRPC(t *testing.T) {
+	m := New()
+
+	done := m.TrackGRPC("/service.AdService/CreateAd")
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.grpcInFlight.WithLabelValues("/service.AdService/CreateAd")))
+	done("OK")
+
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.grpcInFlight.WithLabelValues("/service.AdService/CreateAd")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.grpcRequests.WithLabelValues("/service.AdService/CreateAd", "OK")))
+}
+
+func TestMetrics_ObserveRepository(t *testing.T) {
+	m := New()
+
+	m.ObserveRepository("ad", "GetAd", time.Now(), nil)
+	m.ObserveRepository("ad", "GetAd", time.Now(), errors.New("not found"))
+	m.ObserveRepository("ad", "GetAd", time.Now(), nil)
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `homework10_repository_operation_duration_seconds_count{operation="GetAd",repository="ad",result="ok"} 2`)
+	assert.Contains(t, body, `homework10_repository_operation_duration_seconds_count{operation="GetAd",repository="ad",result="error"} 1`)
+}
+
+func TestMetrics_RegisterBusiness(t *testing.T) {
+	m := New()
+	m.RegisterBusiness(testCounters{}, testCounters{})
+
+	body := scrape(t, m)
+	assert.Contains(t, body, "homework10_ads 5\n")
+	assert.Contains(t, body, "homework10_ads_published 2\n")
+	assert.Contains(t, body, "homework10_users 3\n")
+
+	failing := New()
+	failing.RegisterBusiness(testCounters{err: errors.New("storage is down")}, testCounters{})
+	body = scrape(t, failing)
+	assert.NotContains(t, body, "homework10_ads ")
+	assert.Contains(t, body, "homework10_users 3\n", "other metrics are still collected")
+}
@@ -0,0 +1,114 @@
+package policy
+
+import (
+	"errors"
+	"homework10/internal/domain/models"
+)
+
+// ErrDenied отказ транспорта: роли пользователя запрещено действие маршрута или метода
+var ErrDenied = errors.New("the action is not allowed for the user role")
+
+// Endpoint требования к вызову HTTP-маршрута или gRPC-метода
+type Endpoint struct {
+	// Public вызов доступен без токена
+	Public bool
+	// Action действие, которое выполняет вызов; роли, которым оно запрещено даже над своими ресурсами,
+	// получают отказ ещё в транспорте, владение ресурсом проверяет сервис
+	Action Action
+}
+
+// httpEndpoints маршруты gin (метод и шаблон пути). Маршрут, которого здесь нет, требует токен:
+// новый маршрут не останется открытым, если его забыли описать
+var httpEndpoints = map[string]Endpoint{
+	"POST /api/v1/auth/login": {Public: true},
+
+	"GET /api/v1/ads/":                                  {Public: true},
+	"GET /api/v1/ads/:ad_id":                            {Public: true},
+	"GET /api/v1/ads/search":                            {Public: true},
+	"GET /api/v1/ads/stream":                            {Public: true},
+	"GET /api/v1/ads/categories":                        {Public: true},
+	"GET /api/v1/ads/:ad_id/revisions":                  {Action: ViewRevisions},
+	"POST /api/v1/ads/":                                 {Action: CreateAd},
+	"PUT /api/v1/ads/:ad_id":                            {Action: UpdateAd},
+	"PUT /api/v1/ads/:ad_id/status":                     {Action: PublishAd},
+	"DELETE /api/v1/ads/:ad_id":                         {Action: DeleteAd},
+	"POST /api/v1/ads/:ad_id/revisions/:rev/restore":    {Action: UpdateAd},
+	"POST /api/v1/ads/:ad_id/restore":                   {Action: RestoreAd},
+	"POST /api/v1/ads/:ad_id/submit":                    {Action: SubmitAd},
+	"POST /api/v1/ads/:ad_id/approve":                   {Action: ReviewAd},
+	"POST /api/v1/ads/:ad_id/reject":                    {Action: ReviewAd},
+	"GET /api/v1/ads/moderation":                        {Action: ReviewAd},
+	"POST /api/v1/ads/:ad_id/images":                    {Action: UpdateAd},
+	"GET /api/v1/ads/:ad_id/images/:image_id":           {Public: true},
+	"GET /api/v1/ads/:ad_id/images/:image_id/thumbnail": {Public: true},
+	"POST /api/v1/ads/:ad_id/messages":                  {Action: SendMessage},
+
+	"GET /api/v1/users/:user_id":                                         {Public: true},
+	"POST /api/v1/users":                                                 {Public: true},
+	"POST /api/v1/users/:user_id/restore":                                {Public: true},
+	"PUT /api/v1/users/:user_id":                                         {Action: UpdateUser},
+	"DELETE /api/v1/users/:user_id":                                      {Action: DeleteUser},
+	"PUT /api/v1/users/:user_id/password":                                {Action: ChangePassword},
+	"PUT /api/v1/users/:user_id/role":                                    {Action: AssignRole},
+	"GET /api/v1/users/:user_id/trash":                                   {Action: ViewTrash},
+	"GET /api/v1/users/:user_id/favorites":                               {Action: ManageFavorites},
+	"POST /api/v1/users/:user_id/favorites/:ad_id":                       {Action: ManageFavorites},
+	"DELETE /api/v1/users/:user_id/favorites/:ad_id":                     {Action: ManageFavorites},
+	"GET /api/v1/users/:user_id/conversations":                           {Action: ManageMessages},
+	"GET /api/v1/users/:user_id/conversations/:conversation_id/messages": {Action: ManageMessages},
+	"POST /api/v1/users/:user_id/conversations/:conversation_id/read":    {Action: ManageMessages},
+}
+
+// grpcEndpoints методы gRPC; как и для HTTP, не описанный метод требует токен
+var grpcEndpoints = map[string]Endpoint{
+	"/service.AuthService/Login": {Public: true},
+
+	"/service.AdService/GetAd":               {Public: true},
+	"/service.AdService/SearchAds":           {Public: true},
+	"/service.AdService/ListAds":             {Public: true},
+	"/service.AdService/WatchAds":            {Public: true},
+	"/service.AdService/ListCategories":      {Public: true},
+	"/service.AdService/ListAdRevisions":     {Action: ViewRevisions},
+	"/service.AdService/CreateAd":            {Action: CreateAd},
+	"/service.AdService/UpdateAd":            {Action: UpdateAd},
+	"/service.AdService/ChangeAdStatus":      {Action: PublishAd},
+	"/service.AdService/DeleteAd":            {Action: DeleteAd},
+	"/service.AdService/RestoreAdRevision":   {Action: UpdateAd},
+	"/service.AdService/RestoreAd":           {Action: RestoreAd},
+	"/service.AdService/SubmitAd":            {Action: SubmitAd},
+	"/service.AdService/ApproveAd":           {Action: ReviewAd},
+	"/service.AdService/RejectAd":            {Action: ReviewAd},
+	"/service.AdService/ListModerationQueue": {Action: ReviewAd},
+	"/service.AdService/UploadAdImage":       {Action: UpdateAd},
+
+	"/service.UserService/CreateUser":     {Public: true},
+	"/service.UserService/GetUser":        {Public: true},
+	"/service.UserService/RestoreUser":    {Public: true},
+	"/service.UserService/UpdateUser":     {Action: UpdateUser},
+	"/service.UserService/DeleteUser":     {Action: DeleteUser},
+	"/service.UserService/ChangePassword": {Action: ChangePassword},
+	"/service.UserService/SetUserRole":    {Action: AssignRole},
+	"/service.UserService/AddFavorite":    {Action: ManageFavorites},
+	"/service.UserService/RemoveFavorite": {Action: ManageFavorites},
+	"/service.UserService/ListFavorites":  {Action: ManageFavorites},
+
+	"/service.MessageService/SendMessage":          {Action: SendMessage},
+	"/service.MessageService/ListConversations":    {Action: ManageMessages},
+	"/service.MessageService/ListMessages":         {Action: ManageMessages},
+	"/service.MessageService/MarkConversationRead": {Action: ManageMessages},
+}
+
+// HTTPEndpoint возвращает требования к маршруту gin method и path (шаблон, как в gin.Context.FullPath)
+func HTTPEndpoint(method string, path string) Endpoint {
+	return httpEndpoints[method+" "+path]
+}
+
+// GRPCEndpoint возвращает требования к gRPC-методу fullMethod
+func GRPCEndpoint(fullMethod string) Endpoint {
+	return grpcEndpoints[fullMethod]
+}
+
+// Permits сообщает, может ли пользователь с ролью role вызвать endpoint хотя бы над своими ресурсами
+func (p *Policy) Permits(role models.Role, endpoint Endpoint) bool {
+	return endpoint.Action == "" || p.Can(role, endpoint.Action, true)
+}
@@ -0,0 +1,96 @@
+package policy
+
+import "homework10/internal/domain/models"
+
+// Action действие над ресурсом, право на которое проверяет Policy
+type Action string
+
+const (
+	CreateAd  Action = "ad.create"
+	UpdateAd  Action = "ad.update"
+	PublishAd Action = "ad.publish"
+	DeleteAd  Action = "ad.delete"
+	RestoreAd Action = "ad.restore"
+	SubmitAd  Action = "ad.submit"
+	ReviewAd  Action = "ad.review"
+	// ViewRevisions просмотр истории изменений объявления, в которой есть и неопубликованные версии
+	ViewRevisions Action = "ad.view_revisions"
+	// ViewUnpublished просмотр черновиков, объявлений на проверке, отклонённых и снятых с публикации
+	ViewUnpublished Action = "ad.view_unpublished"
+	// SendMessage отправка сообщения автору объявления или покупателю
+	SendMessage Action = "ad.send_message"
+
+	UpdateUser     Action = "user.update"
+	DeleteUser     Action = "user.delete"
+	ChangePassword Action = "user.change_password"
+	ViewTrash      Action = "user.view_trash"
+	// ManageFavorites просмотр и изменение закладок пользователя
+	ManageFavorites Action = "user.manage_favorites"
+	// ManageMessages просмотр бесед пользователя и отметка сообщений прочитанными
+	ManageMessages Action = "user.manage_messages"
+	// AssignRole смена роли пользователя
+	AssignRole Action = "user.assign_role"
+)
+
+// Rule кому разрешено действие: пользователям с ролями из Roles и, если Owner, владельцу ресурса
+type Rule struct {
+	Roles []models.Role
+	Owner bool
+}
+
+// Policy решает, может ли пользователь выполнить действие. Действия без правила запрещены всем
+type Policy struct {
+	rules map[Action]Rule
+}
+
+func New(rules map[Action]Rule) *Policy {
+	return &Policy{rules: rules}
+}
+
+// Default политика сервиса: владелец управляет своими объявлениями и профилем, модератор проверяет
+// объявления, администратору разрешено всё, кроме смены чужого пароля
+func Default() *Policy {
+	everyone := []models.Role{models.RoleUser, models.RoleModerator, models.RoleAdmin}
+	admin := []models.Role{models.RoleAdmin}
+	moderators := []models.Role{models.RoleModerator, models.RoleAdmin}
+	return New(map[Action]Rule{
+		CreateAd:        {Roles: everyone},
+		UpdateAd:        {Roles: admin, Owner: true},
+		PublishAd:       {Roles: admin, Owner: true},
+		DeleteAd:        {Roles: admin, Owner: true},
+		RestoreAd:       {Roles: admin, Owner: true},
+		SubmitAd:        {Roles: admin, Owner: true},
+		ReviewAd:        {Roles: moderators},
+		ViewRevisions:   {Roles: moderators, Owner: true},
+		ViewUnpublished: {Roles: moderators, Owner: true},
+		SendMessage:     {Roles: everyone},
+
+		UpdateUser:     {Roles: admin, Owner: true},
+		DeleteUser:     {Roles: admin, Owner: true},
+		ChangePassword: {Owner: true},
+		ViewTrash:      {Roles: admin, Owner: true},
+		// закладки личные, их не видит даже администратор
+		ManageFavorites: {Owner: true},
+		// переписку читают только её участники
+		ManageMessages: {Owner: true},
+		// роль нельзя выдать себе, даже будучи владельцем профиля
+		AssignRole: {Roles: admin},
+	})
+}
+
+// Can сообщает, может ли пользователь с ролью role выполнить action; owner - является ли он владельцем ресурса
+func (p *Policy) Can(role models.Role, action Action, owner bool) bool {
+	rule, ok := p.rules[action]
+	if !ok {
+		return false
+	}
+	if rule.Owner && owner {
+		return true
+	}
+	for _, allowed := range rule.Roles {
+		if allowed == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package policy
+
+import (
+	"homework10/internal/domain/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy_Can(t *testing.T) {
+	p := Default()
+
+	testTable := []struct {
+		name   string
+		role   models.Role
+		action Action
+		owner  bool
+		want   bool
+	}{
+		{name: "user creates an ad", role: models.RoleUser, action: CreateAd, want: true},
+		{name: "owner updates the ad", role: models.RoleUser, action: UpdateAd, owner: true, want: true},
+		{name: "user updates someone else's ad", role: models.RoleUser, action: UpdateAd},
+		{name: "moderator updates someone else's ad", role: models.RoleModerator, action: UpdateAd},
+		{name: "admin updates someone else's ad", role: models.RoleAdmin, action: UpdateAd, want: true},
+		{name: "owner can't review the ad", role: models.RoleUser, action: ReviewAd, owner: true},
+		{name: "moderator reviews the ad", role: models.RoleModerator, action: ReviewAd, want: true},
+		{name: "admin reviews the ad", role: models.RoleAdmin, action: ReviewAd, want: true},
+		{name: "owner changes the password", role: models.RoleUser, action: ChangePassword, owner: true, want: true},
+		{name: "admin changes someone else's password", role: models.RoleAdmin, action: ChangePassword},
+		{name: "owner manages favorites", role: models.RoleUser, action: ManageFavorites, owner: true, want: true},
+		{name: "admin views someone else's favorites", role: models.RoleAdmin, action: ManageFavorites},
+		{name: "user sends a message", role: models.RoleUser, action: SendMessage, want: true},
+		{name: "admin reads someone else's conversations", role: models.RoleAdmin, action: ManageMessages},
+		{name: "unknown action", role: models.RoleAdmin, action: Action("ad.unknown"), owner: true},
+	}
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.want, p.Can(testCase.role, testCase.action, testCase.owner))
+		})
+	}
+}
+
+func TestEndpoints(t *testing.T) {
+	assert.True(t, HTTPEndpoint("GET", "/api/v1/ads/:ad_id").Public)
+	assert.Equal(t, Endpoint{Action: ReviewAd}, HTTPEndpoint("GET", "/api/v1/ads/moderation"))
+	assert.True(t, GRPCEndpoint("/service.AuthService/Login").Public)
+	assert.Equal(t, Endpoint{Action: DeleteAd}, GRPCEndpoint("/service.AdService/DeleteAd"))
+
+	// не описанные маршруты и методы не публичные
+	assert.False(t, HTTPEndpoint("POST", "/api/v1/ads/:ad_id/unknown").Public)
+	assert.False(t, HTTPEndpoint("GET", "").Public)
+	assert.False(t, GRPCEndpoint("/service.AdService/Unknown").Public)
+}
+
+func TestPolicy_Permits(t *testing.T) {
+	p := Default()
+
+	assert.True(t, p.Permits(models.RoleUser, Endpoint{}))
+	assert.True(t, p.Permits(models.RoleUser, Endpoint{Action: DeleteAd}))
+	assert.False(t, p.Permits(models.RoleUser, Endpoint{Action: ReviewAd}))
+	assert.True(t, p.Permits(models.RoleModerator, Endpoint{Action: ReviewAd}))
+	assert.False(t, p.Permits(models.RoleAdmin, Endpoint{Action: Action("ad.unknown")}))
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// limitOff значение лимита, отключающее ограничение
+const limitOff = "off"
+
+var limitUnits = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+}
+
+// Limit допустимая частота запросов: Burst запросов подряд, затем Rate запросов в секунду.
+// Нулевой Limit ограничения не задаёт
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Unlimited сообщает, что лимит не ограничивает запросы
+func (l Limit) Unlimited() bool {
+	return l.Rate <= 0 || l.Burst <= 0
+}
+
+// ParseLimit разбирает лимит вида «10/s», «100/m» или «1000/h»: столько запросов за секунду, минуту
+// или час, столько же можно сделать подряд. Размер пачки задаётся отдельно через двоеточие: «10/m:3».
+// Значение «off» ограничение снимает
+func ParseLimit(value string) (Limit, error) {
+	value = strings.TrimSpace(value)
+	if value == limitOff {
+		return Limit{}, nil
+	}
+	spec, burstValue, hasBurst := strings.Cut(value, ":")
+	countValue, unitValue, ok := strings.Cut(spec, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: expected requests/unit", value)
+	}
+	count, err := strconv.Atoi(countValue)
+	if err != nil || count <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: the number of requests must be positive", value)
+	}
+	unit, ok := limitUnits[unitValue]
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: the unit must be s, m or h", value)
+	}
+	burst := count
+	if hasBurst {
+		burst, err = strconv.Atoi(burstValue)
+		if err != nil || burst <= 0 {
+			return Limit{}, fmt.Errorf("invalid rate limit %q: the burst must be positive", value)
+		}
+	}
+	return Limit{Rate: float64(count) / unit.Seconds(), Burst: burst}, nil
+}
+
+// ParseRoutes разбирает лимиты маршрутов через запятую: «POST /api/v1/ads/=5/m,/service.AdService/CreateAd=5/m».
+// Маршрут записывается так же, как ключ таблицы доступа policy: метод и шаблон пути gin или полное имя gRPC-метода
+func ParseRoutes(value string) (map[string]Limit, error) {
+	routes := make(map[string]Limit)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		separator := strings.LastIndex(item, "=")
+		if separator <= 0 {
+			return nil, fmt.Errorf("invalid route rate limit %q: expected route=limit", item)
+		}
+		limit, err := ParseLimit(item[separator+1:])
+		if err != nil {
+			return nil, err
+		}
+		routes[strings.TrimSpace(item[:separator])] = limit
+	}
+	return routes, nil
+}
@@ -0,0 +1,134 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"homework10/internal/auth"
+)
+
+// DefaultIdleTimeout сколько хранится корзина клиента, к которой не обращались
+const DefaultIdleTimeout = 10 * time.Minute
+
+// ErrLimited отказ транспорта: клиент превысил допустимую частоту запросов
+var ErrLimited = errors.New("too many requests, retry later")
+
+// Limiter ограничивает частоту запросов алгоритмом token bucket. Корзина заводится на каждую пару
+// «маршрут — клиент»: маршрут - это ключ HTTP-маршрута («POST /api/v1/ads/») или полное имя gRPC-метода,
+// клиент - аутентифицированный пользователь или IP-адрес (см. ClientKey)
+type Limiter struct {
+	// IdleTimeout через сколько без запросов корзина удаляется из памяти; удалённая корзина
+	// при следующем запросе создаётся заново полной
+	IdleTimeout time.Duration
+
+	defaultLimit Limit
+	routes       map[string]Limit
+	buckets      map[bucketKey]*bucket
+	now          func() time.Time
+	mutex        sync.Mutex
+}
+
+type bucketKey struct {
+	route  string
+	client string
+}
+
+type bucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// NewLimiter создаёт ограничитель: маршрутам из routes - их лимит, остальным - defaultLimit
+func NewLimiter(defaultLimit Limit, routes map[string]Limit) *Limiter {
+	if routes == nil {
+		routes = make(map[string]Limit)
+	}
+	return &Limiter{
+		IdleTimeout:  DefaultIdleTimeout,
+		defaultLimit: defaultLimit,
+		routes:       routes,
+		buckets:      make(map[bucketKey]*bucket),
+		now:          time.Now,
+	}
+}
+
+// Allow расходует токен корзины маршрута route клиента client. Если токенов нет, запрос отклоняется,
+// а retryAfter - через сколько появится следующий токен
+func (l *Limiter) Allow(route string, client string) (allowed bool, retryAfter time.Duration) {
+	limit, ok := l.routes[route]
+	if !ok {
+		limit = l.defaultLimit
+	}
+	if limit.Unlimited() {
+		return true, 0
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	key := bucketKey{route: route, client: client}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), lastSeen: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.lastSeen).Seconds()*limit.Rate)
+	b.lastSeen = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+}
+
+// Evict удаляет корзины, к которым не обращались дольше IdleTimeout, и возвращает их число
+func (l *Limiter) Evict() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	evicted := 0
+	for key, b := range l.buckets {
+		if now.Sub(b.lastSeen) >= l.IdleTimeout {
+			delete(l.buckets, key)
+			evicted++
+		}
+	}
+	return evicted
+}
+
+// Run удаляет простаивающие корзины каждые IdleTimeout, пока не завершится контекст
+func (l *Limiter) Run(ctx context.Context) error {
+	ticker := time.NewTicker(l.IdleTimeout)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			l.Evict()
+		}
+	}
+}
+
+// ClientKey ключ клиента: ID пользователя, если запрос аутентифицирован, иначе IP-адрес
+func ClientKey(ctx context.Context, ip string) string {
+	if userID, ok := auth.UserIDFromContext(ctx); ok {
+		return "user:" + strconv.FormatInt(userID, 10)
+	}
+	return "ip:" + ip
+}
+
+// RetryAfterSeconds значение заголовка Retry-After: ожидание в целых секундах, не меньше одной
+func RetryAfterSeconds(retryAfter time.Duration) string {
+	seconds := int64(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.FormatInt(seconds, 10)
+}
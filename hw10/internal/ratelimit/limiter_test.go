@@ -0,0 +1,128 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"homework10/internal/auth"
+)
+
+// testClock время, которое тест сдвигает вручную
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func newTestLimiter(defaultLimit Limit, routes map[string]Limit) (*Limiter, *testClock) {
+	clock := &testClock{now: time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)}
+	limiter := NewLimiter(defaultLimit, routes)
+	limiter.now = clock.Now
+	return limiter, clock
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	limiter, clock := newTestLimiter(Limit{Rate: 1, Burst: 2}, map[string]Limit{
+		"POST /api/v1/ads/": {Rate: 0.5, Burst: 1},
+		"GET /api/v1/ads/":  {},
+	})
+
+	allowed, _ := limiter.Allow("GET /api/v1/users/:user_id", "ip:1")
+	assert.True(t, allowed)
+	allowed, _ = limiter.Allow("GET /api/v1/users/:user_id", "ip:1")
+	assert.True(t, allowed, "burst")
+	allowed, retryAfter := limiter.Allow("GET /api/v1/users/:user_id", "ip:1")
+	assert.False(t, allowed)
+	assert.Equal(t, time.Second, retryAfter)
+
+	allowed, _ = limiter.Allow("GET /api/v1/users/:user_id", "ip:2")
+	assert.True(t, allowed, "clients have separate buckets")
+	allowed, _ = limiter.Allow("POST /api/v1/ads/", "ip:1")
+	assert.True(t, allowed, "routes have separate buckets")
+	allowed, retryAfter = limiter.Allow("POST /api/v1/ads/", "ip:1")
+	assert.False(t, allowed)
+	assert.Equal(t, 2*time.Second, retryAfter, "route limit overrides the default one")
+
+	clock.now = clock.now.Add(500 * time.Millisecond)
+	allowed, retryAfter = limiter.Allow("GET /api/v1/users/:user_id", "ip:1")
+	assert.False(t, allowed)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+	clock.now = clock.now.Add(500 * time.Millisecond)
+	allowed, _ = limiter.Allow("GET /api/v1/users/:user_id", "ip:1")
+	assert.True(t, allowed, "the bucket is refilled over time")
+
+	for i := 0; i < 100; i++ {
+		allowed, _ = limiter.Allow("GET /api/v1/ads/", "ip:1")
+		assert.True(t, allowed, "zero limit disables throttling")
+	}
+}
+
+func TestLimiter_Evict(t *testing.T) {
+	limiter, clock := newTestLimiter(Limit{Rate: 1, Burst: 1}, nil)
+	limiter.IdleTimeout = time.Minute
+
+	limiter.Allow("GET /api/v1/ads/", "ip:1")
+	clock.now = clock.now.Add(30 * time.Second)
+	limiter.Allow("GET /api/v1/ads/", "ip:2")
+	clock.now = clock.now.Add(30 * time.Second)
+
+	assert.Equal(t, 1, limiter.Evict())
+	assert.Len(t, limiter.buckets, 1)
+	assert.Contains(t, limiter.buckets, bucketKey{route: "GET /api/v1/ads/", client: "ip:2"})
+}
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected Limit
+		wantErr  bool
+	}{
+		{value: "10/s", expected: Limit{Rate: 10, Burst: 10}},
+		{value: "120/m", expected: Limit{Rate: 2, Burst: 120}},
+		{value: "3600/h:5", expected: Limit{Rate: 1, Burst: 5}},
+		{value: "off", expected: Limit{}},
+		{value: "10", wantErr: true},
+		{value: "0/s", wantErr: true},
+		{value: "10/d", wantErr: true},
+		{value: "10/s:0", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.value, func(t *testing.T) {
+			limit, err := ParseLimit(tc.value)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, limit)
+		})
+	}
+}
+
+func TestParseRoutes(t *testing.T) {
+	routes, err := ParseRoutes("POST /api/v1/ads/=60/m, /service.AdService/CreateAd=1/s:3,GET /api/v1/ads/:ad_id=off")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]Limit{
+		"POST /api/v1/ads/":           {Rate: 1, Burst: 60},
+		"/service.AdService/CreateAd": {Rate: 1, Burst: 3},
+		"GET /api/v1/ads/:ad_id":      {},
+	}, routes)
+
+	_, err = ParseRoutes("POST /api/v1/ads/")
+	assert.Error(t, err)
+}
+
+func TestClientKey(t *testing.T) {
+	assert.Equal(t, "ip:10.0.0.1", ClientKey(context.Background(), "10.0.0.1"))
+	assert.Equal(t, "user:0", ClientKey(auth.WithUserID(context.Background(), 0), "10.0.0.1"))
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	assert.Equal(t, "1", RetryAfterSeconds(0))
+	assert.Equal(t, "1", RetryAfterSeconds(200*time.Millisecond))
+	assert.Equal(t, "3", RetryAfterSeconds(2100*time.Millisecond))
+}
@@ -0,0 +1,251 @@
+package filerepo
+
+import (
+	"context"
+	"encoding/json"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+type AdRepo struct {
+	db *bolt.DB
+}
+
+func NewAdRepo(db *bolt.DB) *AdRepo {
+	return &AdRepo{db: db}
+}
+
+func (r *AdRepo) GetAd(ctx context.Context, adID int64) (*models.Ad, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var ad *models.Ad
+	err := r.db.View(func(tx *bolt.Tx) error {
+		var err error
+		ad, err = getAd(tx.Bucket(adsBucket), adID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ad, nil
+}
+
+func (r *AdRepo) GetAds(ctx context.Context) ([]*models.Ad, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	adSlice := make([]*models.Ad, 0)
+	err := r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(adsBucket).ForEach(func(_, value []byte) error {
+			var ad models.Ad
+			if err := json.Unmarshal(value, &ad); err != nil {
+				return err
+			}
+			adSlice = append(adSlice, &ad)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return adSlice, nil
+}
+
+func (r *AdRepo) FindAds(ctx context.Context, filter models.AdFilter) ([]*models.Ad, error) {
+	adSlice, err := r.match(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return filter.Page(adSlice), nil
+}
+
+func (r *AdRepo) CountFacets(ctx context.Context, filter models.AdFilter) (models.AdFacets, error) {
+	filter.After, filter.Limit = nil, 0
+	adSlice, err := r.match(ctx, filter)
+	if err != nil {
+		return models.AdFacets{}, err
+	}
+	return models.CountFacets(adSlice), nil
+}
+
+// match читает из файла объявления, подходящие под фильтр; остальные не удерживаются в памяти
+func (r *AdRepo) match(ctx context.Context, filter models.AdFilter) ([]*models.Ad, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	adSlice := make([]*models.Ad, 0)
+	err := r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(adsBucket).ForEach(func(_, value []byte) error {
+			var ad models.Ad
+			if err := json.Unmarshal(value, &ad); err != nil {
+				return err
+			}
+			if filter.Match(&ad) {
+				adSlice = append(adSlice, &ad)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return adSlice, nil
+}
+
+func (r *AdRepo) CountAds(ctx context.Context) (int, int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, 0, err
+	}
+	total, published := 0, 0
+	err := r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(adsBucket).ForEach(func(_, value []byte) error {
+			var ad models.Ad
+			if err := json.Unmarshal(value, &ad); err != nil {
+				return err
+			}
+			if ad.IsDeleted() {
+				return nil
+			}
+			total++
+			if ad.Published {
+				published++
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return total, published, nil
+}
+
+func (r *AdRepo) AddAd(ctx context.Context, ad models.Ad) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(adsBucket)
+		id, err := nextID(bucket)
+		if err != nil {
+			return err
+		}
+		ad.ID = id
+		return putAd(bucket, &ad)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return ad.ID, nil
+}
+
+func (r *AdRepo) SetState(ctx context.Context, adID int64, state models.AdState, reason string, version int64) (*models.Ad, error) {
+	return r.modify(ctx, adID, version, func(ad *models.Ad) {
+		ad.State = state
+		ad.Published = state == models.AdPublished
+		ad.RejectionReason = reason
+	})
+}
+
+func (r *AdRepo) Update(ctx context.Context, adID int64, title string, text string, details models.AdDetails,
+	state models.AdState, version int64) (*models.Ad, error) {
+	return r.modify(ctx, adID, version, func(ad *models.Ad) {
+		ad.Title = title
+		ad.Text = text
+		ad.AdDetails = details
+		ad.State = state
+		ad.Published = state == models.AdPublished
+		ad.RejectionReason = ""
+	})
+}
+
+func (r *AdRepo) AddImage(ctx context.Context, adID int64, image models.AdImage, state models.AdState,
+	version int64) (*models.Ad, error) {
+	return r.modify(ctx, adID, version, func(ad *models.Ad) {
+		image.ID = int64(len(ad.Images)) + 1
+		ad.Images = append(ad.Images, image)
+		ad.State = state
+		ad.Published = state == models.AdPublished
+		ad.RejectionReason = ""
+	})
+}
+
+func (r *AdRepo) SetDeletedAt(ctx context.Context, adID int64, deletedAt time.Time) (*models.Ad, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var ad *models.Ad
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(adsBucket)
+		var err error
+		ad, err = getAd(bucket, adID)
+		if err != nil {
+			return err
+		}
+		ad.DeletedAt = deletedAt
+		return putAd(bucket, ad)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ad, nil
+}
+
+func (r *AdRepo) DeleteAd(ctx context.Context, adID int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return r.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(adsBucket).Delete(idToKey(adID))
+	})
+}
+
+// modify применяет change к объявлению версии version, увеличивает версию и обновляет DateUpdate
+func (r *AdRepo) modify(ctx context.Context, adID int64, version int64, change func(ad *models.Ad)) (*models.Ad, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var ad *models.Ad
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(adsBucket)
+		var err error
+		ad, err = getAd(bucket, adID)
+		if err != nil {
+			return err
+		}
+		if ad.Version != version {
+			return domain.ErrAdVersionMismatch
+		}
+		change(ad)
+		ad.Version++
+		ad.DateUpdate = time.Now().UTC()
+		return putAd(bucket, ad)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ad, nil
+}
+
+func getAd(bucket *bolt.Bucket, adID int64) (*models.Ad, error) {
+	value := bucket.Get(idToKey(adID))
+	if value == nil {
+		return nil, domain.ErrAdNotExist
+	}
+	var ad models.Ad
+	if err := json.Unmarshal(value, &ad); err != nil {
+		return nil, err
+	}
+	return &ad, nil
+}
+
+func putAd(bucket *bolt.Bucket, ad *models.Ad) error {
+	value, err := json.Marshal(ad)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(idToKey(ad.ID), value)
+}
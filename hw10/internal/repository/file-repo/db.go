@@ -0,0 +1,154 @@
+package filerepo
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"homework10/internal/domain/models"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	adsBucket   = []byte("ads")
+	usersBucket = []byte("users")
+	// revisionsBucket содержит по вложенному бакету на объявление; ключи в нём - номера ревизий
+	revisionsBucket = []byte("revisions")
+	// favoritesBucket содержит по вложенному бакету на пользователя; ключи в нём - ID объявлений
+	favoritesBucket     = []byte("favorites")
+	conversationsBucket = []byte("conversations")
+	// conversationKeysBucket по паре «ID объявления, ID покупателя» хранит ID беседы
+	conversationKeysBucket = []byte("conversation_keys")
+	// messagesBucket содержит по вложенному бакету на беседу; ключи в нём - ID сообщений
+	messagesBucket = []byte("messages")
+)
+
+// Open открывает (или создаёт) файл базы и подготавливает в нём бакеты для объявлений, пользователей, ревизий,
+// закладок и переписки
+func Open(path string) (*bolt.DB, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("opening storage file: %w", err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{adsBucket, usersBucket, revisionsBucket, favoritesBucket,
+			conversationsBucket, conversationKeysBucket, messagesBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		if err := migrateRecords(tx.Bucket(adsBucket), legacyAdDates, legacyAdState); err != nil {
+			return fmt.Errorf("migrating ads: %w", err)
+		}
+		if err := migrateRecords(tx.Bucket(usersBucket), legacyUserRole); err != nil {
+			return fmt.Errorf("migrating users: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("creating buckets: %w", err)
+	}
+	return db, nil
+}
+
+// legacyDateFormat формат, в котором даты объявлений хранились до перехода на time.Time
+const legacyDateFormat = "01-02-2006"
+
+// migrateRecords переписывает записи бакета, которые хотя бы одна из fixes привела к текущему формату
+func migrateRecords(bucket *bolt.Bucket, fixes ...func(fields map[string]json.RawMessage) bool) error {
+	updated := make(map[string][]byte)
+	err := bucket.ForEach(func(key, value []byte) error {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(value, &fields); err != nil {
+			return err
+		}
+		changed := false
+		for _, fix := range fixes {
+			if fix(fields) {
+				changed = true
+			}
+		}
+		if !changed {
+			return nil
+		}
+		data, err := json.Marshal(fields)
+		if err != nil {
+			return err
+		}
+		updated[string(key)] = data
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for key, value := range updated {
+		if err := bucket.Put([]byte(key), value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// legacyAdDates переводит даты объявления, сохранённые в legacyDateFormat, в RFC 3339 (полночь UTC)
+func legacyAdDates(fields map[string]json.RawMessage) bool {
+	changed := false
+	for _, name := range []string{"date_creation", "date_update"} {
+		var raw string
+		if err := json.Unmarshal(fields[name], &raw); err != nil {
+			continue
+		}
+		date, err := time.Parse(legacyDateFormat, raw)
+		if err != nil {
+			continue
+		}
+		fields[name], _ = json.Marshal(date)
+		changed = true
+	}
+	return changed
+}
+
+// legacyAdState выставляет состояние объявлениям, сохранённым до появления модерации:
+// опубликованные остаются опубликованными, остальные становятся черновиками
+func legacyAdState(fields map[string]json.RawMessage) bool {
+	if _, ok := fields["state"]; ok {
+		return false
+	}
+	var published bool
+	_ = json.Unmarshal(fields["published"], &published)
+	state := models.AdDraft
+	if published {
+		state = models.AdPublished
+	}
+	fields["state"], _ = json.Marshal(state)
+	return true
+}
+
+// legacyUserRole выставляет роль по умолчанию пользователям, сохранённым до появления ролей
+func legacyUserRole(fields map[string]json.RawMessage) bool {
+	if _, ok := fields["Role"]; ok {
+		return false
+	}
+	fields["Role"], _ = json.Marshal(models.RoleUser)
+	return true
+}
+
+// nextID выдаёт следующий ID из последовательности бакета; нумерация, как и в localrepo, начинается с 0
+func nextID(bucket *bolt.Bucket) (int64, error) {
+	seq, err := bucket.NextSequence()
+	if err != nil {
+		return 0, err
+	}
+	return int64(seq) - 1, nil
+}
+
+func idToKey(id int64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(id))
+	return key
+}
+
+func keyToID(key []byte) int64 {
+	return int64(binary.BigEndian.Uint64(key))
+}
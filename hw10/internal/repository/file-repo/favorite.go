@@ -0,0 +1,107 @@
+package filerepo
+
+import (
+	"context"
+	"encoding/json"
+	"homework10/internal/domain/models"
+	"sort"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+type FavoriteRepo struct {
+	db *bolt.DB
+}
+
+func NewFavoriteRepo(db *bolt.DB) *FavoriteRepo {
+	return &FavoriteRepo{db: db}
+}
+
+func (r *FavoriteRepo) AddFavorite(ctx context.Context, favorite models.Favorite) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return r.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.Bucket(favoritesBucket).CreateBucketIfNotExists(idToKey(favorite.UserID))
+		if err != nil {
+			return err
+		}
+		if bucket.Get(idToKey(favorite.AdID)) != nil {
+			return nil
+		}
+		value, err := json.Marshal(favorite)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(idToKey(favorite.AdID), value)
+	})
+}
+
+func (r *FavoriteRepo) RemoveFavorite(ctx context.Context, userID int64, adID int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return r.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(favoritesBucket).Bucket(idToKey(userID))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.Delete(idToKey(adID))
+	})
+}
+
+func (r *FavoriteRepo) GetFavorites(ctx context.Context, userID int64) ([]models.Favorite, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	favorites := make([]models.Favorite, 0)
+	err := r.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(favoritesBucket).Bucket(idToKey(userID))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(_, value []byte) error {
+			var favorite models.Favorite
+			if err := json.Unmarshal(value, &favorite); err != nil {
+				return err
+			}
+			favorites = append(favorites, favorite)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(favorites, func(i, j int) bool {
+		if !favorites[i].AddedAt.Equal(favorites[j].AddedAt) {
+			return favorites[i].AddedAt.After(favorites[j].AddedAt)
+		}
+		return favorites[i].AdID > favorites[j].AdID
+	})
+	return favorites, nil
+}
+
+func (r *FavoriteRepo) RemoveAdFavorites(ctx context.Context, adID int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return r.db.Update(func(tx *bolt.Tx) error {
+		root := tx.Bucket(favoritesBucket)
+		return root.ForEach(func(userKey, _ []byte) error {
+			return root.Bucket(userKey).Delete(idToKey(adID))
+		})
+	})
+}
+
+func (r *FavoriteRepo) RemoveUserFavorites(ctx context.Context, userID int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return r.db.Update(func(tx *bolt.Tx) error {
+		err := tx.Bucket(favoritesBucket).DeleteBucket(idToKey(userID))
+		if err == bolt.ErrBucketNotFound {
+			return nil
+		}
+		return err
+	})
+}
@@ -0,0 +1,224 @@
+package filerepo
+
+import (
+	"context"
+	"encoding/json"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// MessageRepo хранит беседы в conversationsBucket, а сообщения - в messagesBucket, по вложенному бакету на
+// беседу. ID бесед и сообщений, как и в localrepo, начинаются с 1
+type MessageRepo struct {
+	db *bolt.DB
+}
+
+func NewMessageRepo(db *bolt.DB) *MessageRepo {
+	return &MessageRepo{db: db}
+}
+
+func (r *MessageRepo) GetOrAddConversation(ctx context.Context, conversation models.Conversation) (*models.Conversation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		keys := tx.Bucket(conversationKeysBucket)
+		key := append(idToKey(conversation.AdID), idToKey(conversation.BuyerID)...)
+		if id := keys.Get(key); id != nil {
+			existing, err := getConversation(tx.Bucket(conversationsBucket), keyToID(id))
+			if err != nil {
+				return err
+			}
+			conversation = *existing
+			return nil
+		}
+		bucket := tx.Bucket(conversationsBucket)
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		conversation.ID = int64(seq)
+		if err := putConversation(bucket, &conversation); err != nil {
+			return err
+		}
+		return keys.Put(key, idToKey(conversation.ID))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+func (r *MessageRepo) GetConversation(ctx context.Context, conversationID int64) (*models.Conversation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var conversation *models.Conversation
+	err := r.db.View(func(tx *bolt.Tx) error {
+		var err error
+		conversation, err = getConversation(tx.Bucket(conversationsBucket), conversationID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return conversation, nil
+}
+
+func (r *MessageRepo) GetConversations(ctx context.Context, userID int64) ([]*models.Conversation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	conversations := make([]*models.Conversation, 0)
+	err := r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(conversationsBucket).ForEach(func(_, value []byte) error {
+			var conversation models.Conversation
+			if err := json.Unmarshal(value, &conversation); err != nil {
+				return err
+			}
+			if conversation.HasParticipant(userID) {
+				conversations = append(conversations, &conversation)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return conversations, nil
+}
+
+func (r *MessageRepo) AddMessage(ctx context.Context, message models.Message) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		conversations := tx.Bucket(conversationsBucket)
+		conversation, err := getConversation(conversations, message.ConversationID)
+		if err != nil {
+			return err
+		}
+		root := tx.Bucket(messagesBucket)
+		seq, err := root.NextSequence()
+		if err != nil {
+			return err
+		}
+		message.ID = int64(seq)
+		bucket, err := root.CreateBucketIfNotExists(idToKey(message.ConversationID))
+		if err != nil {
+			return err
+		}
+		if err := putMessage(bucket, &message); err != nil {
+			return err
+		}
+		conversation.LastMessageAt = message.DateCreation
+		return putConversation(conversations, conversation)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return message.ID, nil
+}
+
+func (r *MessageRepo) GetMessages(ctx context.Context, conversationID int64) ([]*models.Message, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	messages := make([]*models.Message, 0)
+	err := r.db.View(func(tx *bolt.Tx) error {
+		if _, err := getConversation(tx.Bucket(conversationsBucket), conversationID); err != nil {
+			return err
+		}
+		bucket := tx.Bucket(messagesBucket).Bucket(idToKey(conversationID))
+		if bucket == nil {
+			return nil
+		}
+		// ключи - ID сообщений в big-endian, поэтому ForEach обходит их в порядке отправки
+		return bucket.ForEach(func(_, value []byte) error {
+			var message models.Message
+			if err := json.Unmarshal(value, &message); err != nil {
+				return err
+			}
+			messages = append(messages, &message)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (r *MessageRepo) MarkRead(ctx context.Context, conversationID int64, readerID int64, readAt time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	marked := 0
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		if _, err := getConversation(tx.Bucket(conversationsBucket), conversationID); err != nil {
+			return err
+		}
+		bucket := tx.Bucket(messagesBucket).Bucket(idToKey(conversationID))
+		if bucket == nil {
+			return nil
+		}
+		// бакет нельзя менять во время ForEach, поэтому сообщения сохраняются после обхода
+		unread := make([]*models.Message, 0)
+		err := bucket.ForEach(func(_, value []byte) error {
+			var message models.Message
+			if err := json.Unmarshal(value, &message); err != nil {
+				return err
+			}
+			if message.SenderID != readerID && !message.IsRead() {
+				unread = append(unread, &message)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, message := range unread {
+			message.ReadAt = readAt
+			if err := putMessage(bucket, message); err != nil {
+				return err
+			}
+		}
+		marked = len(unread)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return marked, nil
+}
+
+func getConversation(bucket *bolt.Bucket, conversationID int64) (*models.Conversation, error) {
+	value := bucket.Get(idToKey(conversationID))
+	if value == nil {
+		return nil, domain.ErrConversationNotExist
+	}
+	var conversation models.Conversation
+	if err := json.Unmarshal(value, &conversation); err != nil {
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+func putConversation(bucket *bolt.Bucket, conversation *models.Conversation) error {
+	value, err := json.Marshal(conversation)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(idToKey(conversation.ID), value)
+}
+
+func putMessage(bucket *bolt.Bucket, message *models.Message) error {
+	value, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(idToKey(message.ID), value)
+}
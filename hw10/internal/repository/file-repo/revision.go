@@ -0,0 +1,106 @@
+package filerepo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+type RevisionRepo struct {
+	db *bolt.DB
+}
+
+func NewRevisionRepo(db *bolt.DB) *RevisionRepo {
+	return &RevisionRepo{db: db}
+}
+
+func (r *RevisionRepo) AddRevision(ctx context.Context, revision models.AdRevision) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.Bucket(revisionsBucket).CreateBucketIfNotExists(idToKey(revision.AdID))
+		if err != nil {
+			return err
+		}
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		revision.Number = int64(seq)
+		value, err := json.Marshal(revision)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(idToKey(revision.Number), value)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return revision.Number, nil
+}
+
+func (r *RevisionRepo) GetRevisions(ctx context.Context, adID int64) ([]*models.AdRevision, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	revisions := make([]*models.AdRevision, 0)
+	err := r.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(revisionsBucket).Bucket(idToKey(adID))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(_, value []byte) error {
+			var revision models.AdRevision
+			if err := json.Unmarshal(value, &revision); err != nil {
+				return err
+			}
+			revisions = append(revisions, &revision)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (r *RevisionRepo) GetRevision(ctx context.Context, adID int64, number int64) (*models.AdRevision, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var revision *models.AdRevision
+	err := r.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(revisionsBucket).Bucket(idToKey(adID))
+		if bucket == nil {
+			return domain.ErrRevisionNotExist
+		}
+		value := bucket.Get(idToKey(number))
+		if value == nil {
+			return domain.ErrRevisionNotExist
+		}
+		revision = &models.AdRevision{}
+		return json.Unmarshal(value, revision)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+func (r *RevisionRepo) DeleteRevisions(ctx context.Context, adID int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return r.db.Update(func(tx *bolt.Tx) error {
+		err := tx.Bucket(revisionsBucket).DeleteBucket(idToKey(adID))
+		if errors.Is(err, bolt.ErrBucketNotFound) {
+			return nil
+		}
+		return err
+	})
+}
@@ -0,0 +1,617 @@
+package filerepo
+
+import (
+	"context"
+	"fmt"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	bolt "go.etcd.io/bbolt"
+)
+
+type TestSuite struct {
+	suite.Suite
+	path     string
+	db       *bolt.DB
+	userRepo *UserRepo
+	adRepo   *AdRepo
+	revRepo  *RevisionRepo
+	favRepo  *FavoriteRepo
+	msgRepo  *MessageRepo
+	user     *models.User
+}
+
+func (suite *TestSuite) SetupTest() {
+	suite.path = filepath.Join(suite.T().TempDir(), "storage.db")
+	suite.open()
+
+	suite.user = &models.User{
+		ID:       0,
+		NickName: "test nickname",
+		Email:    "test email",
+	}
+}
+
+func (suite *TestSuite) TearDownTest() {
+	suite.Require().NoError(suite.db.Close())
+}
+
+func (suite *TestSuite) open() {
+	db, err := Open(suite.path)
+	suite.Require().NoError(err)
+	suite.db = db
+	suite.userRepo = NewUserRepo(db)
+	suite.adRepo = NewAdRepo(db)
+	suite.revRepo = NewRevisionRepo(db)
+	suite.favRepo = NewFavoriteRepo(db)
+	suite.msgRepo = NewMessageRepo(db)
+}
+
+func (suite *TestSuite) reopen() {
+	suite.Require().NoError(suite.db.Close())
+	suite.open()
+}
+
+func (suite *TestSuite) TestGetUser() {
+	id, err := suite.userRepo.AddUser(context.Background(), *suite.user)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(0), id)
+
+	user, err := suite.userRepo.GetUser(context.Background(), 0)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), *suite.user, *user)
+}
+
+func (suite *TestSuite) TestGetUser_NotExist() {
+	user, err := suite.userRepo.GetUser(context.Background(), 100)
+	assert.Nil(suite.T(), user)
+	assert.Equal(suite.T(), domain.ErrUserNotExist, err)
+}
+
+func (suite *TestSuite) TestGetUserByEmail() {
+	ctx := context.Background()
+	_, err := suite.userRepo.AddUser(ctx, *suite.user)
+	assert.NoError(suite.T(), err)
+
+	user, err := suite.userRepo.GetUserByEmail(ctx, suite.user.Email)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), *suite.user, *user)
+
+	user, err = suite.userRepo.GetUserByEmail(ctx, "unknown email")
+	assert.Nil(suite.T(), user)
+	assert.Equal(suite.T(), domain.ErrUserNotExist, err)
+}
+
+func (suite *TestSuite) TestUserUniqueness() {
+	ctx := context.Background()
+	_, err := suite.userRepo.AddUser(ctx, *suite.user)
+	suite.Require().NoError(err)
+	secondID, err := suite.userRepo.AddUser(ctx, models.User{NickName: "second", Email: "second email"})
+	suite.Require().NoError(err)
+
+	_, err = suite.userRepo.AddUser(ctx, models.User{NickName: "third", Email: suite.user.Email})
+	assert.Equal(suite.T(), domain.ErrAlreadyExists{Err: domain.ErrEmailTaken}, err)
+
+	_, err = suite.userRepo.AddUser(ctx, models.User{NickName: suite.user.NickName, Email: "third email"})
+	assert.Equal(suite.T(), domain.ErrAlreadyExists{Err: domain.ErrNickNameTaken}, err)
+
+	_, err = suite.userRepo.Update(ctx, secondID, suite.user.NickName, "second email")
+	assert.Equal(suite.T(), domain.ErrAlreadyExists{Err: domain.ErrNickNameTaken}, err)
+
+	user, err := suite.userRepo.Update(ctx, secondID, "renamed", "second email")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "renamed", user.NickName)
+}
+
+func (suite *TestSuite) TestSetPasswordHash() {
+	ctx := context.Background()
+	id, err := suite.userRepo.AddUser(ctx, *suite.user)
+	suite.Require().NoError(err)
+
+	assert.NoError(suite.T(), suite.userRepo.SetPasswordHash(ctx, id, []byte("hash")))
+	user, err := suite.userRepo.GetUser(ctx, id)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []byte("hash"), user.PasswordHash)
+
+	assert.Equal(suite.T(), domain.ErrUserNotExist, suite.userRepo.SetPasswordHash(ctx, 100, []byte("hash")))
+}
+
+func (suite *TestSuite) TestUpdateAndDeleteUser() {
+	ctx := context.Background()
+	id, err := suite.userRepo.AddUser(ctx, *suite.user)
+	assert.NoError(suite.T(), err)
+
+	user, err := suite.userRepo.Update(ctx, id, "new nickname", "new email")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.User{ID: id, NickName: "new nickname", Email: "new email"}, *user)
+
+	assert.NoError(suite.T(), suite.userRepo.Delete(ctx, id))
+	_, err = suite.userRepo.GetUser(ctx, id)
+	assert.Error(suite.T(), err)
+}
+
+func (suite *TestSuite) TestAdLifecycle() {
+	ctx := context.Background()
+	ad := models.Ad{
+		Title:        "test title",
+		Text:         "test text",
+		UserID:       0,
+		DateCreation: time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC),
+		DateUpdate:   time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	id, err := suite.adRepo.AddAd(ctx, ad)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(0), id)
+	ad.ID = id
+
+	got, err := suite.adRepo.GetAd(ctx, id)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), ad, *got)
+
+	got, err = suite.adRepo.SetState(ctx, id, models.AdPublished, "", 0)
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), got.Published)
+	assert.Equal(suite.T(), models.AdPublished, got.State)
+	assert.Equal(suite.T(), int64(1), got.Version)
+
+	_, err = suite.adRepo.Update(ctx, id, "stale title", "stale text", models.AdDetails{}, models.AdDraft, 0)
+	assert.Equal(suite.T(), domain.ErrAdVersionMismatch, err)
+
+	got, err = suite.adRepo.SetState(ctx, id, models.AdRejected, "spam", 1)
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), got.Published)
+	assert.Equal(suite.T(), "spam", got.RejectionReason)
+
+	got, err = suite.adRepo.Update(ctx, id, "new title", "new text", models.AdDetails{}, models.AdDraft, 2)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "new title", got.Title)
+	assert.Equal(suite.T(), "new text", got.Text)
+	assert.Equal(suite.T(), models.AdDraft, got.State)
+	assert.Empty(suite.T(), got.RejectionReason)
+	assert.Equal(suite.T(), int64(3), got.Version)
+
+	got, err = suite.adRepo.GetAd(ctx, id)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.AdDraft, got.State)
+
+	ads, err := suite.adRepo.GetAds(ctx)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), ads, 1)
+
+	assert.NoError(suite.T(), suite.adRepo.DeleteAd(ctx, id))
+	_, err = suite.adRepo.GetAd(ctx, id)
+	assert.Equal(suite.T(), domain.ErrAdNotExist, err)
+}
+
+func (suite *TestSuite) TestFindAds() {
+	ctx := context.Background()
+	for _, ad := range []models.Ad{
+		{Title: "red cat", UserID: 1, DateCreation: time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{Title: "blue cat", UserID: 2, DateCreation: time.Date(2023, 5, 2, 0, 0, 0, 0, time.UTC)},
+	} {
+		_, err := suite.adRepo.AddAd(ctx, ad)
+		assert.NoError(suite.T(), err)
+	}
+	_, err := suite.adRepo.SetState(ctx, 1, models.AdPublished, "", 0)
+	assert.NoError(suite.T(), err)
+
+	published := true
+	ads, err := suite.adRepo.FindAds(ctx, models.AdFilter{Published: &published, Title: "cat"})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), ads, 1)
+	assert.Equal(suite.T(), int64(1), ads[0].ID)
+
+	ads, err = suite.adRepo.FindAds(ctx, models.AdFilter{AuthorIDs: []int64{1}})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), ads, 1)
+	assert.Equal(suite.T(), "red cat", ads[0].Title)
+}
+
+func (suite *TestSuite) TestSetState_NotExist() {
+	ad, err := suite.adRepo.SetState(context.Background(), 100, models.AdPublished, "", 0)
+	assert.Nil(suite.T(), ad)
+	assert.Equal(suite.T(), domain.ErrAdNotExist, err)
+}
+
+func (suite *TestSuite) TestDataSurvivesReopen() {
+	ctx := context.Background()
+	adID, err := suite.adRepo.AddAd(ctx, models.Ad{Title: "title", Text: "text"})
+	assert.NoError(suite.T(), err)
+	userID, err := suite.userRepo.AddUser(ctx, *suite.user)
+	assert.NoError(suite.T(), err)
+
+	suite.reopen()
+
+	ad, err := suite.adRepo.GetAd(ctx, adID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "title", ad.Title)
+	_, err = suite.userRepo.GetUser(ctx, userID)
+	assert.NoError(suite.T(), err)
+
+	nextAdID, err := suite.adRepo.AddAd(ctx, models.Ad{Title: "title", Text: "text"})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), adID+1, nextAdID)
+}
+
+func (suite *TestSuite) TestLegacyDatesMigration() {
+	ctx := context.Background()
+	adID, err := suite.adRepo.AddAd(ctx, models.Ad{Title: "title", Text: "text"})
+	assert.NoError(suite.T(), err)
+	err = suite.db.Update(func(tx *bolt.Tx) error {
+		legacy := `{"id":0,"title":"title","text":"text","date_creation":"05-01-2023","date_update":"05-02-2023"}`
+		return tx.Bucket(adsBucket).Put(idToKey(adID), []byte(legacy))
+	})
+	assert.NoError(suite.T(), err)
+
+	suite.reopen()
+
+	ad, err := suite.adRepo.GetAd(ctx, adID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC), ad.DateCreation)
+	assert.Equal(suite.T(), time.Date(2023, 5, 2, 0, 0, 0, 0, time.UTC), ad.DateUpdate)
+}
+
+func (suite *TestSuite) TestLegacyModerationMigration() {
+	ctx := context.Background()
+	draftID, err := suite.adRepo.AddAd(ctx, models.Ad{Title: "draft"})
+	assert.NoError(suite.T(), err)
+	publishedID, err := suite.adRepo.AddAd(ctx, models.Ad{Title: "published"})
+	assert.NoError(suite.T(), err)
+	userID, err := suite.userRepo.AddUser(ctx, models.User{NickName: "legacy", Email: "legacy@gmail.com"})
+	assert.NoError(suite.T(), err)
+	err = suite.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(adsBucket).Put(idToKey(draftID), []byte(`{"title":"draft","published":false}`)); err != nil {
+			return err
+		}
+		if err := tx.Bucket(adsBucket).Put(idToKey(publishedID), []byte(`{"title":"published","published":true}`)); err != nil {
+			return err
+		}
+		legacy := fmt.Sprintf(`{"ID":%d,"NickName":"legacy","Email":"legacy@gmail.com"}`, userID)
+		return tx.Bucket(usersBucket).Put(idToKey(userID), []byte(legacy))
+	})
+	assert.NoError(suite.T(), err)
+
+	suite.reopen()
+
+	ad, err := suite.adRepo.GetAd(ctx, draftID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.AdDraft, ad.State)
+	ad, err = suite.adRepo.GetAd(ctx, publishedID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.AdPublished, ad.State)
+	user, err := suite.userRepo.GetUser(ctx, userID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.RoleUser, user.Role)
+
+	user, err = suite.userRepo.SetRole(ctx, userID, models.RoleModerator)
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), user.IsModerator())
+}
+
+func (suite *TestSuite) TestRevisions() {
+	ctx := context.Background()
+	changes := []models.FieldChange{{Field: "title", Old: "title", New: "new title"}}
+	for i, revision := range []models.AdRevision{
+		{AdID: 1, Type: models.RevisionCreated, Title: "title"},
+		{AdID: 2, Type: models.RevisionCreated, Title: "other"},
+		{AdID: 1, Type: models.RevisionUpdated, Title: "new title", Changes: changes},
+	} {
+		number, err := suite.revRepo.AddRevision(ctx, revision)
+		suite.Require().NoError(err)
+		assert.Equal(suite.T(), []int64{1, 1, 2}[i], number)
+	}
+
+	suite.reopen()
+
+	revisions, err := suite.revRepo.GetRevisions(ctx, 1)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), revisions, 2)
+	assert.Equal(suite.T(), changes, revisions[1].Changes)
+
+	revision, err := suite.revRepo.GetRevision(ctx, 1, 2)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "new title", revision.Title)
+
+	revisions, err = suite.revRepo.GetRevisions(ctx, 3)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), revisions)
+	_, err = suite.revRepo.GetRevision(ctx, 1, 3)
+	assert.Equal(suite.T(), domain.ErrRevisionNotExist, err)
+	_, err = suite.revRepo.GetRevision(ctx, 3, 1)
+	assert.Equal(suite.T(), domain.ErrRevisionNotExist, err)
+
+	assert.NoError(suite.T(), suite.revRepo.DeleteRevisions(ctx, 1))
+	assert.NoError(suite.T(), suite.revRepo.DeleteRevisions(ctx, 3), "an ad without revisions")
+	revisions, err = suite.revRepo.GetRevisions(ctx, 1)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), revisions)
+	revisions, err = suite.revRepo.GetRevisions(ctx, 2)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), revisions, 1)
+}
+
+func (suite *TestSuite) TestSoftDelete() {
+	ctx := context.Background()
+	adID, err := suite.adRepo.AddAd(ctx, models.Ad{Title: "title", UserID: 1, Version: 1})
+	suite.Require().NoError(err)
+	userID, err := suite.userRepo.AddUser(ctx, *suite.user)
+	suite.Require().NoError(err)
+	deletedAt := time.Now().UTC().Truncate(time.Microsecond)
+
+	ad, err := suite.adRepo.SetDeletedAt(ctx, adID, deletedAt)
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), deletedAt.Equal(ad.DeletedAt))
+	assert.Equal(suite.T(), int64(1), ad.Version)
+	ads, err := suite.adRepo.FindAds(ctx, models.AdFilter{})
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), ads)
+	ads, err = suite.adRepo.FindAds(ctx, models.AdFilter{Deleted: models.OnlyDeleted})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), ads, 1)
+	ads, err = suite.adRepo.GetAds(ctx)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), ads, 1)
+	total, published, err := suite.adRepo.CountAds(ctx)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, total)
+	assert.Equal(suite.T(), 0, published)
+
+	ad, err = suite.adRepo.SetDeletedAt(ctx, adID, time.Time{})
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), ad.IsDeleted())
+	_, err = suite.adRepo.AddAd(ctx, models.Ad{Title: "published", UserID: 1, Version: 1, Published: true})
+	suite.Require().NoError(err)
+	total, published, err = suite.adRepo.CountAds(ctx)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, total)
+	assert.Equal(suite.T(), 1, published)
+	_, err = suite.adRepo.SetDeletedAt(ctx, adID+100, deletedAt)
+	assert.Equal(suite.T(), domain.ErrAdNotExist, err)
+
+	count, err := suite.userRepo.CountUsers(ctx)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, count)
+	user, err := suite.userRepo.SetDeletedAt(ctx, userID, deletedAt)
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), deletedAt.Equal(user.DeletedAt))
+	count, err = suite.userRepo.CountUsers(ctx)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, count)
+	users, err := suite.userRepo.GetDeletedUsers(ctx, deletedAt)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), users)
+	users, err = suite.userRepo.GetDeletedUsers(ctx, deletedAt.Add(time.Second))
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), users, 1)
+	_, err = suite.userRepo.SetDeletedAt(ctx, userID+100, deletedAt)
+	assert.Equal(suite.T(), domain.ErrUserNotExist, err)
+}
+
+func (suite *TestSuite) TestCanceledContext() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := suite.adRepo.AddAd(ctx, models.Ad{})
+	assert.Equal(suite.T(), context.Canceled, err)
+	_, err = suite.userRepo.GetUser(ctx, 0)
+	assert.Equal(suite.T(), context.Canceled, err)
+}
+
+func (suite *TestSuite) TestAddImage() {
+	ctx := context.Background()
+	adID, err := suite.adRepo.AddAd(ctx, models.Ad{Title: "title", UserID: 1, Version: 1})
+	suite.Require().NoError(err)
+	created := time.Now().UTC().Truncate(time.Microsecond)
+	image := models.AdImage{ContentType: "image/png", Size: 10, Width: 2, Height: 1, Key: "ads/1/a",
+		ThumbnailKey: "ads/1/a.thumb", DateCreation: created}
+
+	ad, err := suite.adRepo.AddImage(ctx, adID, image, models.AdDraft, 1)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(2), ad.Version)
+	suite.Require().Len(ad.Images, 1)
+	assert.Equal(suite.T(), int64(1), ad.Images[0].ID)
+	assert.Equal(suite.T(), "ads/1/a.thumb", ad.Images[0].ThumbnailKey)
+	assert.True(suite.T(), created.Equal(ad.Images[0].DateCreation))
+
+	ad, err = suite.adRepo.AddImage(ctx, adID, models.AdImage{Key: "ads/1/b"}, models.AdDraft, 2)
+	assert.NoError(suite.T(), err)
+	suite.Require().Len(ad.Images, 2)
+	assert.Equal(suite.T(), int64(2), ad.Images[1].ID)
+	ad, err = suite.adRepo.GetAd(ctx, adID)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), ad.Images, 2)
+
+	_, err = suite.adRepo.AddImage(ctx, adID, models.AdImage{}, models.AdDraft, 2)
+	assert.Equal(suite.T(), domain.ErrAdVersionMismatch, err)
+	_, err = suite.adRepo.AddImage(ctx, adID+100, models.AdImage{}, models.AdDraft, 1)
+	assert.Equal(suite.T(), domain.ErrAdNotExist, err)
+}
+
+func (suite *TestSuite) TestAdDetails() {
+	ctx := context.Background()
+	cars := models.AdDetails{Price: models.Price{Amount: 100000, Currency: "RUB"}, CategoryID: "cars", City: "Москва"}
+	adID, err := suite.adRepo.AddAd(ctx, models.Ad{Title: "car", UserID: 1, Version: 1, AdDetails: cars})
+	suite.Require().NoError(err)
+	_, err = suite.adRepo.AddAd(ctx, models.Ad{Title: "job", UserID: 1, Version: 1,
+		AdDetails: models.AdDetails{CategoryID: "jobs", City: "Казань"}})
+	suite.Require().NoError(err)
+
+	ad, err := suite.adRepo.GetAd(ctx, adID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), cars, ad.AdDetails)
+
+	priceMin, priceMax := int64(0), int64(100000)
+	for _, filter := range []models.AdFilter{
+		{CategoryIDs: []string{"cars", "phones"}},
+		{City: "москва"},
+		{PriceMin: &priceMin, PriceMax: &priceMax, Currency: "RUB"},
+	} {
+		ads, err := suite.adRepo.FindAds(ctx, filter)
+		assert.NoError(suite.T(), err)
+		if assert.Len(suite.T(), ads, 1) {
+			assert.Equal(suite.T(), adID, ads[0].ID)
+		}
+	}
+
+	phones := models.AdDetails{CategoryID: "phones"}
+	ad, err = suite.adRepo.Update(ctx, adID, "phone", "text", phones, models.AdDraft, 1)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), phones, ad.AdDetails)
+	ads, err := suite.adRepo.FindAds(ctx, models.AdFilter{PriceMin: &priceMin})
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), ads)
+}
+
+func (suite *TestSuite) TestFindAds_Keyset() {
+	ctx := context.Background()
+	for _, ad := range []models.Ad{
+		{Title: "b", AdDetails: models.AdDetails{CategoryID: "cars", City: "москва"}},
+		{Title: "a", AdDetails: models.AdDetails{CategoryID: "cars", City: "Москва"}},
+		{Title: "b"},
+		{Title: "c", AdDetails: models.AdDetails{CategoryID: "jobs", City: "Казань"}},
+	} {
+		ad.Version = 1
+		_, err := suite.adRepo.AddAd(ctx, ad)
+		suite.Require().NoError(err)
+	}
+
+	filter := models.AdFilter{SortBy: models.AdSortByTitle, Order: models.SortDesc}
+	all, err := suite.adRepo.FindAds(ctx, filter)
+	suite.Require().NoError(err)
+	suite.Require().Len(all, 4)
+	assert.Equal(suite.T(), []string{"c", "b", "b", "a"}, []string{all[0].Title, all[1].Title, all[2].Title, all[3].Title})
+	assert.Greater(suite.T(), all[1].ID, all[2].ID, "ties are ordered by id in the same direction")
+
+	walked := make([]*models.Ad, 0, len(all))
+	filter.Limit = 3
+	for {
+		page, err := suite.adRepo.FindAds(ctx, filter)
+		suite.Require().NoError(err)
+		walked = append(walked, page...)
+		if len(page) < filter.Limit {
+			break
+		}
+		filter.After = page[len(page)-1]
+	}
+	assert.Equal(suite.T(), all, walked)
+
+	facets, err := suite.adRepo.CountFacets(ctx, models.AdFilter{Limit: 1, After: all[0]})
+	assert.NoError(suite.T(), err)
+	assert.ElementsMatch(suite.T(), []models.FacetCount{{Value: "cars", Count: 2}, {Value: "jobs", Count: 1}},
+		facets.Categories)
+	assert.ElementsMatch(suite.T(), []models.FacetCount{{Value: "Москва", Count: 2}, {Value: "Казань", Count: 1}},
+		facets.Cities)
+}
+
+func (suite *TestSuite) TestFavorites() {
+	ctx := context.Background()
+	start := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, favorite := range []models.Favorite{
+		{UserID: 1, AdID: 10, AddedAt: start},
+		{UserID: 1, AdID: 11, AddedAt: start.Add(time.Minute)},
+		{UserID: 2, AdID: 10, AddedAt: start},
+		{UserID: 1, AdID: 10, AddedAt: start.Add(time.Hour)},
+	} {
+		suite.Require().NoError(suite.favRepo.AddFavorite(ctx, favorite))
+	}
+	suite.reopen()
+
+	favorites, err := suite.favRepo.GetFavorites(ctx, 1)
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), favorites, 2) {
+		assert.Equal(suite.T(), int64(11), favorites[0].AdID)
+		assert.Equal(suite.T(), int64(10), favorites[1].AdID)
+		assert.True(suite.T(), start.Equal(favorites[1].AddedAt))
+	}
+
+	assert.NoError(suite.T(), suite.favRepo.RemoveFavorite(ctx, 1, 11))
+	assert.NoError(suite.T(), suite.favRepo.RemoveFavorite(ctx, 3, 11))
+	assert.NoError(suite.T(), suite.favRepo.RemoveAdFavorites(ctx, 10))
+	for _, userID := range []int64{1, 2} {
+		favorites, err = suite.favRepo.GetFavorites(ctx, userID)
+		assert.NoError(suite.T(), err)
+		assert.Empty(suite.T(), favorites)
+	}
+
+	suite.Require().NoError(suite.favRepo.AddFavorite(ctx, models.Favorite{UserID: 2, AdID: 12, AddedAt: start}))
+	assert.NoError(suite.T(), suite.favRepo.RemoveUserFavorites(ctx, 2))
+	assert.NoError(suite.T(), suite.favRepo.RemoveUserFavorites(ctx, 3))
+	favorites, err = suite.favRepo.GetFavorites(ctx, 2)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), favorites)
+}
+
+func (suite *TestSuite) TestMessages() {
+	ctx := context.Background()
+	start := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	conversation, err := suite.msgRepo.GetOrAddConversation(ctx,
+		models.Conversation{AdID: 10, BuyerID: 2, SellerID: 1, DateCreation: start})
+	suite.Require().NoError(err)
+	assert.Equal(suite.T(), int64(1), conversation.ID)
+	same, err := suite.msgRepo.GetOrAddConversation(ctx,
+		models.Conversation{AdID: 10, BuyerID: 2, SellerID: 1, DateCreation: start.Add(time.Hour)})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), conversation, same, "the conversation is keyed by ad and buyer")
+	other, err := suite.msgRepo.GetOrAddConversation(ctx,
+		models.Conversation{AdID: 10, BuyerID: 3, SellerID: 1, DateCreation: start})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(2), other.ID)
+
+	for i, message := range []models.Message{
+		{ConversationID: 1, SenderID: 2, Text: "hello"},
+		{ConversationID: 1, SenderID: 2, Text: "is it available?"},
+		{ConversationID: 1, SenderID: 1, Text: "yes"},
+	} {
+		message.DateCreation = start.Add(time.Duration(i) * time.Minute)
+		id, err := suite.msgRepo.AddMessage(ctx, message)
+		assert.NoError(suite.T(), err)
+		assert.Equal(suite.T(), int64(i+1), id)
+	}
+	_, err = suite.msgRepo.AddMessage(ctx, models.Message{ConversationID: 5, DateCreation: start})
+	assert.Equal(suite.T(), domain.ErrConversationNotExist, err)
+
+	suite.reopen()
+
+	conversation, err = suite.msgRepo.GetConversation(ctx, 1)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), start.Add(2*time.Minute), conversation.LastMessageAt)
+
+	conversations, err := suite.msgRepo.GetConversations(ctx, 1)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), conversations, 2)
+	conversations, err = suite.msgRepo.GetConversations(ctx, 3)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []*models.Conversation{other}, conversations)
+
+	marked, err := suite.msgRepo.MarkRead(ctx, 1, 1, start.Add(time.Hour))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, marked)
+	messages, err := suite.msgRepo.GetMessages(ctx, 1)
+	assert.NoError(suite.T(), err)
+	suite.Require().Len(messages, 3)
+	assert.Equal(suite.T(), "hello", messages[0].Text)
+	assert.Equal(suite.T(), start.Add(time.Hour), messages[0].ReadAt)
+	assert.False(suite.T(), messages[2].IsRead(), "own messages are not marked")
+
+	marked, err = suite.msgRepo.MarkRead(ctx, 1, 1, start.Add(2*time.Hour))
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, marked)
+
+	_, err = suite.msgRepo.GetConversation(ctx, 5)
+	assert.Equal(suite.T(), domain.ErrConversationNotExist, err)
+	_, err = suite.msgRepo.GetMessages(ctx, 5)
+	assert.Equal(suite.T(), domain.ErrConversationNotExist, err)
+	_, err = suite.msgRepo.MarkRead(ctx, 5, 1, start)
+	assert.Equal(suite.T(), domain.ErrConversationNotExist, err)
+}
+
+func TestExampleTestSuite(t *testing.T) {
+	suite.Run(t, new(TestSuite))
+}
@@ -0,0 +1,260 @@
+package filerepo
+
+import (
+	"context"
+	"encoding/json"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+type UserRepo struct {
+	db *bolt.DB
+}
+
+func NewUserRepo(db *bolt.DB) *UserRepo {
+	return &UserRepo{db: db}
+}
+
+func (r *UserRepo) GetUser(ctx context.Context, id int64) (*models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var user *models.User
+	err := r.db.View(func(tx *bolt.Tx) error {
+		var err error
+		user, err = getUser(tx.Bucket(usersBucket), id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *UserRepo) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var found *models.User
+	err := r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(usersBucket).ForEach(func(_, value []byte) error {
+			var user models.User
+			if err := json.Unmarshal(value, &user); err != nil {
+				return err
+			}
+			if found == nil && user.Email == email {
+				found = &user
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, domain.ErrUserNotExist
+	}
+	return found, nil
+}
+
+func (r *UserRepo) AddUser(ctx context.Context, user models.User) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(usersBucket)
+		if err := checkUnique(bucket, -1, user.NickName, user.Email); err != nil {
+			return err
+		}
+		id, err := nextID(bucket)
+		if err != nil {
+			return err
+		}
+		user.ID = id
+		return putUser(bucket, &user)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return user.ID, nil
+}
+
+func (r *UserRepo) Update(ctx context.Context, userID int64, nickName string, email string) (*models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var user *models.User
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(usersBucket)
+		var err error
+		user, err = getUser(bucket, userID)
+		if err != nil {
+			return err
+		}
+		if err := checkUnique(bucket, userID, nickName, email); err != nil {
+			return err
+		}
+		user.NickName = nickName
+		user.Email = email
+		return putUser(bucket, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *UserRepo) SetPasswordHash(ctx context.Context, userID int64, passwordHash []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return r.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(usersBucket)
+		user, err := getUser(bucket, userID)
+		if err != nil {
+			return err
+		}
+		user.PasswordHash = passwordHash
+		return putUser(bucket, user)
+	})
+}
+
+func (r *UserRepo) SetRole(ctx context.Context, userID int64, role models.Role) (*models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var user *models.User
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(usersBucket)
+		var err error
+		user, err = getUser(bucket, userID)
+		if err != nil {
+			return err
+		}
+		user.Role = role
+		return putUser(bucket, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *UserRepo) SetDeletedAt(ctx context.Context, userID int64, deletedAt time.Time) (*models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var user *models.User
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(usersBucket)
+		var err error
+		user, err = getUser(bucket, userID)
+		if err != nil {
+			return err
+		}
+		user.DeletedAt = deletedAt
+		return putUser(bucket, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *UserRepo) GetDeletedUsers(ctx context.Context, before time.Time) ([]*models.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	users := make([]*models.User, 0)
+	err := r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(usersBucket).ForEach(func(_, value []byte) error {
+			var user models.User
+			if err := json.Unmarshal(value, &user); err != nil {
+				return err
+			}
+			if user.IsDeleted() && user.DeletedAt.Before(before) {
+				users = append(users, &user)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *UserRepo) CountUsers(ctx context.Context) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	count := 0
+	err := r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(usersBucket).ForEach(func(_, value []byte) error {
+			var user models.User
+			if err := json.Unmarshal(value, &user); err != nil {
+				return err
+			}
+			if !user.IsDeleted() {
+				count++
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *UserRepo) Delete(ctx context.Context, userID int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return r.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(usersBucket).Delete(idToKey(userID))
+	})
+}
+
+func getUser(bucket *bolt.Bucket, userID int64) (*models.User, error) {
+	value := bucket.Get(idToKey(userID))
+	if value == nil {
+		return nil, domain.ErrUserNotExist
+	}
+	var user models.User
+	if err := json.Unmarshal(value, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func putUser(bucket *bolt.Bucket, user *models.User) error {
+	value, err := json.Marshal(user)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(idToKey(user.ID), value)
+}
+
+// checkUnique проверяет, что почта и никнейм не заняты другими пользователями, кроме userID
+func checkUnique(bucket *bolt.Bucket, userID int64, nickName string, email string) error {
+	return bucket.ForEach(func(_, value []byte) error {
+		var user models.User
+		if err := json.Unmarshal(value, &user); err != nil {
+			return err
+		}
+		if user.ID == userID {
+			return nil
+		}
+		if user.Email == email {
+			return domain.ErrAlreadyExists{Err: domain.ErrEmailTaken}
+		}
+		if user.NickName == nickName {
+			return domain.ErrAlreadyExists{Err: domain.ErrNickNameTaken}
+		}
+		return nil
+	})
+}
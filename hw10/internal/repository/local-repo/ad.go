@@ -2,19 +2,31 @@ package localrepo
 
 import (
 	"context"
-	"fmt"
+	"homework10/internal/domain"
 	"homework10/internal/domain/models"
 	"sync"
+	"time"
 )
 
+type adIDSet map[int64]struct{}
+
 type AdRepo struct {
-	storage  map[int64]*models.Ad
-	lastAdID int64
-	mutex    sync.Mutex
+	storage     map[int64]*models.Ad
+	byAuthor    map[int64]adIDSet
+	byPublished map[bool]adIDSet
+	byCategory  map[string]adIDSet
+	lastAdID    int64
+	mutex       sync.Mutex
 }
 
 func NewAdRepo() *AdRepo {
-	return &AdRepo{storage: make(map[int64]*models.Ad), lastAdID: -1}
+	return &AdRepo{
+		storage:     make(map[int64]*models.Ad),
+		byAuthor:    make(map[int64]adIDSet),
+		byPublished: map[bool]adIDSet{true: make(adIDSet), false: make(adIDSet)},
+		byCategory:  make(map[string]adIDSet),
+		lastAdID:    -1,
+	}
 }
 
 func (r *AdRepo) GetAd(ctx context.Context, adID int64) (*models.Ad, error) {
@@ -26,9 +38,9 @@ func (r *AdRepo) GetAd(ctx context.Context, adID int64) (*models.Ad, error) {
 		defer r.mutex.Unlock()
 		ad, ok := r.storage[adID]
 		if !ok {
-			return nil, fmt.Errorf("the ad does not exist")
+			return nil, domain.ErrAdNotExist
 		}
-		return ad, nil
+		return copyAd(ad), nil
 	}
 }
 
@@ -41,12 +53,117 @@ func (r *AdRepo) GetAds(ctx context.Context) ([]*models.Ad, error) {
 		r.mutex.Lock()
 		defer r.mutex.Unlock()
 		for _, ad := range r.storage {
-			adSlice = append(adSlice, ad)
+			adSlice = append(adSlice, copyAd(ad))
 		}
 		return adSlice, nil
 	}
 }
 
+func (r *AdRepo) FindAds(ctx context.Context, filter models.AdFilter) ([]*models.Ad, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		// копируются только объявления, попавшие в страницу
+		adSlice := filter.Page(r.match(filter))
+		for i, ad := range adSlice {
+			adSlice[i] = copyAd(ad)
+		}
+		return adSlice, nil
+	}
+}
+
+func (r *AdRepo) CountFacets(ctx context.Context, filter models.AdFilter) (models.AdFacets, error) {
+	filter.After, filter.Limit = nil, 0
+	select {
+	case <-ctx.Done():
+		return models.AdFacets{}, ctx.Err()
+	default:
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		return models.CountFacets(r.match(filter)), nil
+	}
+}
+
+// match хранимые объявления, подходящие под фильтр; вызывается под мьютексом
+func (r *AdRepo) match(filter models.AdFilter) []*models.Ad {
+	adSlice := make([]*models.Ad, 0)
+	for adID := range r.candidates(filter) {
+		ad := r.storage[adID]
+		if filter.Match(ad) {
+			adSlice = append(adSlice, ad)
+		}
+	}
+	return adSlice
+}
+
+func (r *AdRepo) CountAds(ctx context.Context) (int, int, error) {
+	select {
+	case <-ctx.Done():
+		return 0, 0, ctx.Err()
+	default:
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		total, published := 0, 0
+		for _, ad := range r.storage {
+			if ad.IsDeleted() {
+				continue
+			}
+			total++
+			if ad.Published {
+				published++
+			}
+		}
+		return total, published, nil
+	}
+}
+
+// candidates выбирает по индексам наименьшее множество ID, среди которых нужно проверять фильтр
+func (r *AdRepo) candidates(filter models.AdFilter) adIDSet {
+	var best adIDSet
+	if len(filter.IDs) != 0 {
+		best = make(adIDSet, len(filter.IDs))
+		for _, adID := range filter.IDs {
+			if _, ok := r.storage[adID]; ok {
+				best[adID] = struct{}{}
+			}
+		}
+	}
+	if len(filter.AuthorIDs) != 0 && best == nil {
+		best = make(adIDSet)
+		for _, authorID := range filter.AuthorIDs {
+			for adID := range r.byAuthor[authorID] {
+				best[adID] = struct{}{}
+			}
+		}
+	}
+	if filter.Published != nil {
+		if published := r.byPublished[*filter.Published]; best == nil || len(published) < len(best) {
+			best = published
+		}
+	}
+	if len(filter.CategoryIDs) != 0 {
+		inCategories := make(adIDSet)
+		for _, categoryID := range filter.CategoryIDs {
+			for adID := range r.byCategory[categoryID] {
+				inCategories[adID] = struct{}{}
+			}
+		}
+		if best == nil || len(inCategories) < len(best) {
+			best = inCategories
+		}
+	}
+	if best == nil {
+		best = make(adIDSet, len(r.storage))
+		for adID := range r.storage {
+			best[adID] = struct{}{}
+		}
+	}
+	return best
+}
+
 func (r *AdRepo) AddAd(ctx context.Context, ad models.Ad) (int64, error) {
 	select {
 	case <-ctx.Done():
@@ -55,34 +172,113 @@ func (r *AdRepo) AddAd(ctx context.Context, ad models.Ad) (int64, error) {
 		r.mutex.Lock()
 		defer r.mutex.Unlock()
 		r.lastAdID++
-		r.storage[r.lastAdID] = &ad
-		r.storage[r.lastAdID].ID = r.lastAdID
-		return r.lastAdID, nil
+		ad.ID = r.lastAdID
+		stored := copyAd(&ad)
+		r.storage[stored.ID] = stored
+		r.index(stored)
+		return stored.ID, nil
 	}
 }
 
-func (r *AdRepo) SetStatus(ctx context.Context, adID int64, published bool) (*models.Ad, error) {
+func (r *AdRepo) SetState(ctx context.Context, adID int64, state models.AdState, reason string, version int64) (*models.Ad, error) {
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	default:
 		r.mutex.Lock()
 		defer r.mutex.Unlock()
-		r.storage[adID].Published = published
-		return r.storage[adID], nil
+		ad, err := r.versioned(adID, version)
+		if err != nil {
+			return nil, err
+		}
+		r.unindex(ad)
+		ad.State = state
+		ad.Published = state == models.AdPublished
+		ad.RejectionReason = reason
+		ad.Version++
+		ad.DateUpdate = time.Now().UTC()
+		r.index(ad)
+		return copyAd(ad), nil
 	}
 }
 
-func (r *AdRepo) Update(ctx context.Context, adID int64, title string, text string) (*models.Ad, error) {
+func (r *AdRepo) Update(ctx context.Context, adID int64, title string, text string, details models.AdDetails,
+	state models.AdState, version int64) (*models.Ad, error) {
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	default:
 		r.mutex.Lock()
 		defer r.mutex.Unlock()
-		r.storage[adID].Title = title
-		r.storage[adID].Text = text
-		return r.storage[adID], nil
+		ad, err := r.versioned(adID, version)
+		if err != nil {
+			return nil, err
+		}
+		r.unindex(ad)
+		ad.Title = title
+		ad.Text = text
+		ad.AdDetails = details
+		ad.State = state
+		ad.Published = state == models.AdPublished
+		ad.RejectionReason = ""
+		ad.Version++
+		ad.DateUpdate = time.Now().UTC()
+		r.index(ad)
+		return copyAd(ad), nil
+	}
+}
+
+func (r *AdRepo) AddImage(ctx context.Context, adID int64, image models.AdImage, state models.AdState,
+	version int64) (*models.Ad, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		ad, err := r.versioned(adID, version)
+		if err != nil {
+			return nil, err
+		}
+		r.unindex(ad)
+		image.ID = int64(len(ad.Images)) + 1
+		// новый срез, чтобы не менять изображения у ранее возвращённых копий объявления
+		ad.Images = append(ad.Images[:len(ad.Images):len(ad.Images)], image)
+		ad.State = state
+		ad.Published = state == models.AdPublished
+		ad.RejectionReason = ""
+		ad.Version++
+		ad.DateUpdate = time.Now().UTC()
+		r.index(ad)
+		return copyAd(ad), nil
+	}
+}
+
+// versioned возвращает объявление, если его текущая версия равна version; вызывается под мьютексом
+func (r *AdRepo) versioned(adID int64, version int64) (*models.Ad, error) {
+	ad, ok := r.storage[adID]
+	if !ok {
+		return nil, domain.ErrAdNotExist
+	}
+	if ad.Version != version {
+		return nil, domain.ErrAdVersionMismatch
+	}
+	return ad, nil
+}
+
+func (r *AdRepo) SetDeletedAt(ctx context.Context, adID int64, deletedAt time.Time) (*models.Ad, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		ad, ok := r.storage[adID]
+		if !ok {
+			return nil, domain.ErrAdNotExist
+		}
+		ad.DeletedAt = deletedAt
+		return copyAd(ad), nil
 	}
 }
 
@@ -93,7 +289,47 @@ func (r *AdRepo) DeleteAd(ctx context.Context, adID int64) error {
 	default:
 		r.mutex.Lock()
 		defer r.mutex.Unlock()
+		if ad, ok := r.storage[adID]; ok {
+			r.unindex(ad)
+		}
 		delete(r.storage, adID)
 		return nil
 	}
 }
+
+// copyAd копия объявления для вызывающего: хранимые объявления меняются только под мьютексом, а копию
+// можно читать без него. Срез изображений общий, поэтому изображения добавляются в новый срез
+func copyAd(ad *models.Ad) *models.Ad {
+	adCopy := *ad
+	return &adCopy
+}
+
+func (r *AdRepo) index(ad *models.Ad) {
+	authorAds, ok := r.byAuthor[ad.UserID]
+	if !ok {
+		authorAds = make(adIDSet)
+		r.byAuthor[ad.UserID] = authorAds
+	}
+	authorAds[ad.ID] = struct{}{}
+	r.byPublished[ad.Published][ad.ID] = struct{}{}
+	if ad.CategoryID != "" {
+		categoryAds, ok := r.byCategory[ad.CategoryID]
+		if !ok {
+			categoryAds = make(adIDSet)
+			r.byCategory[ad.CategoryID] = categoryAds
+		}
+		categoryAds[ad.ID] = struct{}{}
+	}
+}
+
+func (r *AdRepo) unindex(ad *models.Ad) {
+	delete(r.byAuthor[ad.UserID], ad.ID)
+	if len(r.byAuthor[ad.UserID]) == 0 {
+		delete(r.byAuthor, ad.UserID)
+	}
+	delete(r.byPublished[ad.Published], ad.ID)
+	delete(r.byCategory[ad.CategoryID], ad.ID)
+	if len(r.byCategory[ad.CategoryID]) == 0 {
+		delete(r.byCategory, ad.CategoryID)
+	}
+}
@@ -2,10 +2,11 @@ package localrepo
 
 import (
 	"context"
-	"fmt"
 	"github.com/stretchr/testify/assert"
+	"homework10/internal/domain"
 	"homework10/internal/domain/models"
 	"testing"
+	"time"
 )
 
 func TestAdRepo_GetAd(t *testing.T) {
@@ -35,7 +36,7 @@ func TestAdRepo_GetAd(t *testing.T) {
 			adID: 10,
 
 			expected: nil,
-			err:      fmt.Errorf("the ad does not exist"),
+			err:      domain.ErrAdNotExist,
 			cancel:   false,
 		},
 		{
@@ -164,7 +165,7 @@ func TestAdRepo_AddAd(t *testing.T) {
 	}
 }
 
-func TestAdRepo_SetStatus(t *testing.T) {
+func TestAdRepo_SetState(t *testing.T) {
 	adRepo := NewAdRepo()
 
 	adRepo.storage[0] = &models.Ad{
@@ -176,12 +177,12 @@ func TestAdRepo_SetStatus(t *testing.T) {
 	}
 
 	tests := []struct {
-		name      string
-		adID      int64
-		expected  *models.Ad
-		published bool
-		err       error
-		cancel    bool
+		name     string
+		adID     int64
+		expected *models.Ad
+		state    models.AdState
+		err      error
+		cancel   bool
 	}{
 		{
 			name: "successfully test AddAd",
@@ -192,9 +193,11 @@ func TestAdRepo_SetStatus(t *testing.T) {
 				Text:      "test text",
 				UserID:    0,
 				Published: true,
+				State:     models.AdPublished,
+				Version:   1,
 			},
-			published: true,
-			cancel:    false,
+			state:  models.AdPublished,
+			cancel: false,
 		},
 		{
 			name: "successfully test AddAd",
@@ -206,9 +209,9 @@ func TestAdRepo_SetStatus(t *testing.T) {
 				UserID:    0,
 				Published: true,
 			},
-			published: true,
-			err:       context.Canceled,
-			cancel:    true,
+			state:  models.AdPublished,
+			err:    context.Canceled,
+			cancel: true,
 		},
 	}
 	for _, tc := range tests {
@@ -220,12 +223,15 @@ func TestAdRepo_SetStatus(t *testing.T) {
 				cancel(context.Canceled)
 			}
 
-			ad, err := adRepo.SetStatus(ctx, tc.adID, tc.published)
+			ad, err := adRepo.SetState(ctx, tc.adID, tc.state, "", 0)
 			if err != nil {
 				assert.Nil(t, ad)
 				assert.Equal(t, tc.err, err)
 			} else {
-				assert.Equal(t, *tc.expected, *ad)
+				got := *ad
+				assert.False(t, got.DateUpdate.IsZero())
+				got.DateUpdate = time.Time{}
+				assert.Equal(t, *tc.expected, got)
 			}
 		})
 	}
@@ -262,6 +268,8 @@ func TestAdRepo_Update(t *testing.T) {
 				Text:      "new text",
 				UserID:    0,
 				Published: false,
+				State:     models.AdDraft,
+				Version:   1,
 			},
 			cancel: false,
 		},
@@ -291,12 +299,15 @@ func TestAdRepo_Update(t *testing.T) {
 				cancel(context.Canceled)
 			}
 
-			ad, err := adRepo.Update(ctx, tc.adID, tc.title, tc.text)
+			ad, err := adRepo.Update(ctx, tc.adID, tc.title, tc.text, models.AdDetails{}, models.AdDraft, 0)
 			if err != nil {
 				assert.Nil(t, ad)
 				assert.Equal(t, tc.err, err)
 			} else {
-				assert.Equal(t, *tc.expected, *ad)
+				got := *ad
+				assert.False(t, got.DateUpdate.IsZero())
+				got.DateUpdate = time.Time{}
+				assert.Equal(t, *tc.expected, got)
 			}
 		})
 	}
@@ -350,3 +361,377 @@ func TestAdRepo_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestAdRepo_FindAds(t *testing.T) {
+	adRepo := NewAdRepo()
+	ctx := context.Background()
+	for _, ad := range []models.Ad{
+		{Title: "red cat", UserID: 1, DateCreation: time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{Title: "blue cat", UserID: 2, DateCreation: time.Date(2023, 5, 2, 0, 0, 0, 0, time.UTC)},
+		{Title: "red dog", UserID: 1, DateCreation: time.Date(2023, 5, 3, 0, 0, 0, 0, time.UTC)},
+	} {
+		_, err := adRepo.AddAd(ctx, ad)
+		assert.NoError(t, err)
+	}
+	_, err := adRepo.SetState(ctx, 2, models.AdPublished, "", 0)
+	assert.NoError(t, err)
+
+	published := true
+	unpublished := false
+	from := time.Date(2023, time.May, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		filter   models.AdFilter
+		expected []int64
+	}{
+		{
+			name:     "empty filter",
+			filter:   models.AdFilter{},
+			expected: []int64{0, 1, 2},
+		},
+		{
+			name:     "by published index",
+			filter:   models.AdFilter{Published: &published},
+			expected: []int64{2},
+		},
+		{
+			name:     "by author index",
+			filter:   models.AdFilter{AuthorIDs: []int64{1}},
+			expected: []int64{0, 2},
+		},
+		{
+			name:     "by several authors and published",
+			filter:   models.AdFilter{AuthorIDs: []int64{1, 2}, Published: &unpublished},
+			expected: []int64{0, 1},
+		},
+		{
+			name:     "by title and date range",
+			filter:   models.AdFilter{Title: "cat", CreatedFrom: from},
+			expected: []int64{1},
+		},
+		{
+			name:     "by ids with unknown id",
+			filter:   models.AdFilter{IDs: []int64{2, 1, 100}},
+			expected: []int64{1, 2},
+		},
+		{
+			name:     "unknown author",
+			filter:   models.AdFilter{AuthorIDs: []int64{100}},
+			expected: []int64{},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ads, err := adRepo.FindAds(ctx, tc.filter)
+			assert.NoError(t, err)
+			ids := make([]int64, 0, len(ads))
+			for _, ad := range ads {
+				ids = append(ids, ad.ID)
+			}
+			assert.Equal(t, tc.expected, ids)
+		})
+	}
+}
+
+func TestAdRepo_FindAds_IndexesFollowChanges(t *testing.T) {
+	adRepo := NewAdRepo()
+	ctx := context.Background()
+	adID, err := adRepo.AddAd(ctx, models.Ad{Title: "title", UserID: 1})
+	assert.NoError(t, err)
+
+	published := true
+	_, err = adRepo.SetState(ctx, adID, models.AdPublished, "", 0)
+	assert.NoError(t, err)
+	ads, err := adRepo.FindAds(ctx, models.AdFilter{Published: &published})
+	assert.NoError(t, err)
+	assert.Len(t, ads, 1)
+
+	assert.NoError(t, adRepo.DeleteAd(ctx, adID))
+	ads, err = adRepo.FindAds(ctx, models.AdFilter{Published: &published})
+	assert.NoError(t, err)
+	assert.Empty(t, ads)
+	assert.Empty(t, adRepo.byAuthor)
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = adRepo.FindAds(ctx, models.AdFilter{})
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestAdRepo_FindAds_State(t *testing.T) {
+	adRepo := NewAdRepo()
+	ctx := context.Background()
+	draftID, err := adRepo.AddAd(ctx, models.Ad{Title: "draft", State: models.AdDraft})
+	assert.NoError(t, err)
+	reviewID, err := adRepo.AddAd(ctx, models.Ad{Title: "review", State: models.AdDraft})
+	assert.NoError(t, err)
+
+	_, err = adRepo.SetState(ctx, reviewID, models.AdPendingReview, "", 0)
+	assert.NoError(t, err)
+	ads, err := adRepo.FindAds(ctx, models.AdFilter{State: models.AdPendingReview})
+	assert.NoError(t, err)
+	if assert.Len(t, ads, 1) {
+		assert.Equal(t, reviewID, ads[0].ID)
+	}
+
+	ad, err := adRepo.SetState(ctx, reviewID, models.AdRejected, "spam", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "spam", ad.RejectionReason)
+	ads, err = adRepo.FindAds(ctx, models.AdFilter{State: models.AdDraft})
+	assert.NoError(t, err)
+	if assert.Len(t, ads, 1) {
+		assert.Equal(t, draftID, ads[0].ID)
+	}
+}
+
+func TestAdRepo_VersionMismatch(t *testing.T) {
+	adRepo := NewAdRepo()
+	ctx := context.Background()
+	adID, err := adRepo.AddAd(ctx, models.Ad{Title: "title", Text: "text", Version: 1})
+	assert.NoError(t, err)
+
+	ad, err := adRepo.Update(ctx, adID, "new title", "text", models.AdDetails{}, models.AdDraft, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), ad.Version)
+
+	_, err = adRepo.Update(ctx, adID, "stale title", "text", models.AdDetails{}, models.AdDraft, 1)
+	assert.Equal(t, domain.ErrAdVersionMismatch, err)
+	_, err = adRepo.SetState(ctx, adID, models.AdPublished, "", 1)
+	assert.Equal(t, domain.ErrAdVersionMismatch, err)
+
+	ad, err = adRepo.GetAd(ctx, adID)
+	assert.NoError(t, err)
+	assert.Equal(t, "new title", ad.Title)
+	assert.False(t, ad.Published)
+
+	_, err = adRepo.SetState(ctx, 100, models.AdPublished, "", 1)
+	assert.Equal(t, domain.ErrAdNotExist, err)
+}
+
+func TestAdRepo_SetDeletedAt(t *testing.T) {
+	ctx := context.Background()
+	adRepo := NewAdRepo()
+	adID, err := adRepo.AddAd(ctx, models.Ad{Title: "title", UserID: 1, Published: true})
+	assert.NoError(t, err)
+
+	deletedAt := time.Now().UTC()
+	ad, err := adRepo.SetDeletedAt(ctx, adID, deletedAt)
+	assert.NoError(t, err)
+	assert.Equal(t, deletedAt, ad.DeletedAt)
+
+	published := true
+	ads, err := adRepo.FindAds(ctx, models.AdFilter{Published: &published})
+	assert.NoError(t, err)
+	assert.Empty(t, ads)
+	ads, err = adRepo.FindAds(ctx, models.AdFilter{AuthorIDs: []int64{1}, Deleted: models.OnlyDeleted})
+	assert.NoError(t, err)
+	assert.Len(t, ads, 1)
+	total, publishedCount, err := adRepo.CountAds(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, total, "ads in the trash are not counted")
+	assert.Equal(t, 0, publishedCount)
+
+	ad, err = adRepo.SetDeletedAt(ctx, adID, time.Time{})
+	assert.NoError(t, err)
+	assert.False(t, ad.IsDeleted())
+	ads, err = adRepo.FindAds(ctx, models.AdFilter{Published: &published})
+	assert.NoError(t, err)
+	assert.Len(t, ads, 1)
+	_, err = adRepo.AddAd(ctx, models.Ad{Title: "draft", UserID: 1})
+	assert.NoError(t, err)
+	total, publishedCount, err = adRepo.CountAds(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, 1, publishedCount)
+
+	_, err = adRepo.SetDeletedAt(ctx, 100, deletedAt)
+	assert.Equal(t, domain.ErrAdNotExist, err)
+}
+
+func TestAdRepo_AddImage(t *testing.T) {
+	ctx := context.Background()
+	adRepo := NewAdRepo()
+	adID, err := adRepo.AddAd(ctx, models.Ad{Title: "title", UserID: 1, State: models.AdPublished, Published: true, Version: 1})
+	assert.NoError(t, err)
+
+	ad, err := adRepo.AddImage(ctx, adID, models.AdImage{Key: "ads/1/a", ThumbnailKey: "ads/1/a.thumb"}, models.AdDraft, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), ad.Version)
+	assert.Equal(t, models.AdDraft, ad.State)
+	assert.Equal(t, []models.AdImage{{ID: 1, Key: "ads/1/a", ThumbnailKey: "ads/1/a.thumb"}}, ad.Images)
+
+	old := *ad
+	ad, err = adRepo.AddImage(ctx, adID, models.AdImage{Key: "ads/1/b"}, models.AdDraft, 2)
+	assert.NoError(t, err)
+	assert.Len(t, old.Images, 1, "copies of the ad must keep their images")
+	assert.Len(t, ad.Images, 2)
+	assert.Equal(t, int64(2), ad.Images[1].ID)
+
+	published := true
+	ads, err := adRepo.FindAds(ctx, models.AdFilter{Published: &published})
+	assert.NoError(t, err)
+	assert.Empty(t, ads)
+
+	_, err = adRepo.AddImage(ctx, adID, models.AdImage{}, models.AdDraft, 2)
+	assert.Equal(t, domain.ErrAdVersionMismatch, err)
+	_, err = adRepo.AddImage(ctx, 100, models.AdImage{}, models.AdDraft, 1)
+	assert.Equal(t, domain.ErrAdNotExist, err)
+}
+
+func TestAdRepo_FindAds_Details(t *testing.T) {
+	adRepo := NewAdRepo()
+	ctx := context.Background()
+	for _, details := range []models.AdDetails{
+		{Price: models.Price{Amount: 100000, Currency: "RUB"}, CategoryID: "cars", City: "Москва"},
+		{Price: models.Price{Amount: 500000, Currency: "RUB"}, CategoryID: "cars", City: "Казань"},
+		{Price: models.Price{Amount: 300, Currency: "USD"}, CategoryID: "phones", City: "москва"},
+		{CategoryID: "jobs"},
+	} {
+		_, err := adRepo.AddAd(ctx, models.Ad{Title: "title", Version: 1, AdDetails: details})
+		assert.NoError(t, err)
+	}
+
+	priceMin, priceMax := int64(300), int64(200000)
+	zero := int64(0)
+
+	tests := []struct {
+		name     string
+		filter   models.AdFilter
+		expected []int64
+	}{
+		{
+			name:     "by category index",
+			filter:   models.AdFilter{CategoryIDs: []string{"cars", "jobs"}},
+			expected: []int64{0, 1, 3},
+		},
+		{
+			name:     "by city ignoring case",
+			filter:   models.AdFilter{City: "МОСКВА"},
+			expected: []int64{0, 2},
+		},
+		{
+			name:     "by price range",
+			filter:   models.AdFilter{PriceMin: &priceMin, PriceMax: &priceMax},
+			expected: []int64{0, 2},
+		},
+		{
+			name:     "by price range and currency",
+			filter:   models.AdFilter{PriceMin: &priceMin, PriceMax: &priceMax, Currency: "RUB"},
+			expected: []int64{0},
+		},
+		{
+			name:     "ads without price don't match price range",
+			filter:   models.AdFilter{PriceMin: &zero},
+			expected: []int64{0, 1, 2},
+		},
+		{
+			name:     "unknown category",
+			filter:   models.AdFilter{CategoryIDs: []string{"boats"}},
+			expected: []int64{},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ads, err := adRepo.FindAds(ctx, tc.filter)
+			assert.NoError(t, err)
+			ids := make([]int64, 0, len(ads))
+			for _, ad := range ads {
+				ids = append(ids, ad.ID)
+			}
+			assert.Equal(t, tc.expected, ids)
+		})
+	}
+
+	_, err := adRepo.Update(ctx, 3, "title", "text", models.AdDetails{CategoryID: "cars"}, models.AdDraft, 1)
+	assert.NoError(t, err)
+	ads, err := adRepo.FindAds(ctx, models.AdFilter{CategoryIDs: []string{"jobs"}})
+	assert.NoError(t, err)
+	assert.Empty(t, ads)
+	assert.NotContains(t, adRepo.byCategory, "jobs")
+	ads, err = adRepo.FindAds(ctx, models.AdFilter{CategoryIDs: []string{"cars"}})
+	assert.NoError(t, err)
+	assert.Len(t, ads, 3)
+}
+
+func TestAdRepo_FindAds_Keyset(t *testing.T) {
+	adRepo := NewAdRepo()
+	ctx := context.Background()
+	for _, ad := range []models.Ad{
+		{Title: "b", AdDetails: models.AdDetails{CategoryID: "cars", City: "москва"}},
+		{Title: "a", AdDetails: models.AdDetails{CategoryID: "cars", City: "Москва"}},
+		{Title: "b"},
+		{Title: "c", AdDetails: models.AdDetails{CategoryID: "jobs", City: "Казань"}},
+	} {
+		_, err := adRepo.AddAd(ctx, ad)
+		assert.NoError(t, err)
+	}
+
+	tests := []struct {
+		name     string
+		filter   models.AdFilter
+		expected []int64
+	}{
+		{
+			name:     "by title desc, ties by id desc",
+			filter:   models.AdFilter{SortBy: models.AdSortByTitle, Order: models.SortDesc},
+			expected: []int64{3, 2, 0, 1},
+		},
+		{
+			name:     "limit",
+			filter:   models.AdFilter{Limit: 2},
+			expected: []int64{0, 1},
+		},
+		{
+			name:     "after cursor",
+			filter:   models.AdFilter{SortBy: models.AdSortByTitle, After: &models.Ad{ID: 0, Title: "b"}},
+			expected: []int64{2, 3},
+		},
+		{
+			name:     "after cursor by id desc",
+			filter:   models.AdFilter{Order: models.SortDesc, After: &models.Ad{ID: 2}, Limit: 1},
+			expected: []int64{1},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ads, err := adRepo.FindAds(ctx, tc.filter)
+			assert.NoError(t, err)
+			ids := make([]int64, 0, len(ads))
+			for _, ad := range ads {
+				ids = append(ids, ad.ID)
+			}
+			assert.Equal(t, tc.expected, ids)
+		})
+	}
+
+	facets, err := adRepo.CountFacets(ctx, models.AdFilter{Limit: 1})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []models.FacetCount{{Value: "cars", Count: 2}, {Value: "jobs", Count: 1}}, facets.Categories)
+	assert.ElementsMatch(t, []models.FacetCount{{Value: "Москва", Count: 2}, {Value: "Казань", Count: 1}}, facets.Cities)
+}
+
+func TestAdRepo_ReturnsCopies(t *testing.T) {
+	adRepo := NewAdRepo()
+	ctx := context.Background()
+	adID, err := adRepo.AddAd(ctx, models.Ad{Title: "title", Version: 1})
+	assert.NoError(t, err)
+
+	ad, err := adRepo.GetAd(ctx, adID)
+	assert.NoError(t, err)
+	ad.Title = "changed by the caller"
+	ads, err := adRepo.FindAds(ctx, models.AdFilter{})
+	assert.NoError(t, err)
+	ads[0].Version = 100
+
+	updated, err := adRepo.Update(ctx, adID, "new title", "text", models.AdDetails{}, models.AdDraft, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "new title", updated.Title)
+	assert.Equal(t, int64(2), updated.Version, "changes of returned ads do not reach the storage")
+	assert.Equal(t, "changed by the caller", ad.Title, "returned ads do not change with the storage")
+	assert.Equal(t, int64(1), ad.Version)
+
+	_, err = adRepo.AddImage(ctx, adID, models.AdImage{Key: "image"}, models.AdDraft, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, updated.Images)
+}
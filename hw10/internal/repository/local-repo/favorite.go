@@ -0,0 +1,101 @@
+package localrepo
+
+import (
+	"context"
+	"homework10/internal/domain/models"
+	"sort"
+	"sync"
+)
+
+type FavoriteRepo struct {
+	// storage закладки по пользователю и объявлению
+	storage map[int64]map[int64]models.Favorite
+	mutex   sync.Mutex
+}
+
+func NewFavoriteRepo() *FavoriteRepo {
+	return &FavoriteRepo{storage: make(map[int64]map[int64]models.Favorite)}
+}
+
+func (r *FavoriteRepo) AddFavorite(ctx context.Context, favorite models.Favorite) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		favorites, ok := r.storage[favorite.UserID]
+		if !ok {
+			favorites = make(map[int64]models.Favorite)
+			r.storage[favorite.UserID] = favorites
+		}
+		if _, ok := favorites[favorite.AdID]; !ok {
+			favorites[favorite.AdID] = favorite
+		}
+		return nil
+	}
+}
+
+func (r *FavoriteRepo) RemoveFavorite(ctx context.Context, userID int64, adID int64) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		delete(r.storage[userID], adID)
+		return nil
+	}
+}
+
+func (r *FavoriteRepo) GetFavorites(ctx context.Context, userID int64) ([]models.Favorite, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		favorites := make([]models.Favorite, 0, len(r.storage[userID]))
+		for _, favorite := range r.storage[userID] {
+			favorites = append(favorites, favorite)
+		}
+		sortFavorites(favorites)
+		return favorites, nil
+	}
+}
+
+func (r *FavoriteRepo) RemoveAdFavorites(ctx context.Context, adID int64) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		for _, favorites := range r.storage {
+			delete(favorites, adID)
+		}
+		return nil
+	}
+}
+
+func (r *FavoriteRepo) RemoveUserFavorites(ctx context.Context, userID int64) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		delete(r.storage, userID)
+		return nil
+	}
+}
+
+// sortFavorites упорядочивает закладки от новых к старым, при равном времени - по убыванию ID объявления
+func sortFavorites(favorites []models.Favorite) {
+	sort.Slice(favorites, func(i, j int) bool {
+		if !favorites[i].AddedAt.Equal(favorites[j].AddedAt) {
+			return favorites[i].AddedAt.After(favorites[j].AddedAt)
+		}
+		return favorites[i].AdID > favorites[j].AdID
+	})
+}
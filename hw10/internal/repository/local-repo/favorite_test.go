@@ -0,0 +1,48 @@
+package localrepo
+
+import (
+	"context"
+	"homework10/internal/domain/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFavoriteRepo(t *testing.T) {
+	favoriteRepo := NewFavoriteRepo()
+	ctx := context.Background()
+	start := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, favorite := range []models.Favorite{
+		{UserID: 1, AdID: 10, AddedAt: start},
+		{UserID: 1, AdID: 11, AddedAt: start.Add(time.Minute)},
+		{UserID: 2, AdID: 10, AddedAt: start},
+		{UserID: 1, AdID: 10, AddedAt: start.Add(time.Hour)},
+	} {
+		assert.NoError(t, favoriteRepo.AddFavorite(ctx, favorite))
+	}
+
+	favorites, err := favoriteRepo.GetFavorites(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Favorite{
+		{UserID: 1, AdID: 11, AddedAt: start.Add(time.Minute)},
+		{UserID: 1, AdID: 10, AddedAt: start},
+	}, favorites, "newest first, adding again keeps the original time")
+
+	assert.NoError(t, favoriteRepo.RemoveFavorite(ctx, 1, 11))
+	assert.NoError(t, favoriteRepo.RemoveFavorite(ctx, 3, 11))
+	assert.NoError(t, favoriteRepo.RemoveAdFavorites(ctx, 10))
+	favorites, err = favoriteRepo.GetFavorites(ctx, 1)
+	assert.NoError(t, err)
+	assert.Empty(t, favorites)
+	favorites, err = favoriteRepo.GetFavorites(ctx, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, favorites)
+
+	assert.NoError(t, favoriteRepo.AddFavorite(ctx, models.Favorite{UserID: 2, AdID: 12, AddedAt: start}))
+	assert.NoError(t, favoriteRepo.RemoveUserFavorites(ctx, 2))
+	favorites, err = favoriteRepo.GetFavorites(ctx, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, favorites)
+}
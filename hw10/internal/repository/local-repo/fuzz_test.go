@@ -2,8 +2,8 @@ package localrepo
 
 import (
 	"context"
-	"fmt"
 	"github.com/stretchr/testify/assert"
+	"homework10/internal/domain"
 	"testing"
 )
 
@@ -20,6 +20,6 @@ func FuzzIntGetUser(f *testing.F) {
 		user, err := userRepo.GetUser(context.Background(), int64(s))
 
 		assert.Nil(t, user)
-		assert.Equal(t, fmt.Errorf("the user does not exist"), err)
+		assert.Equal(t, domain.ErrUserNotExist, err)
 	})
 }
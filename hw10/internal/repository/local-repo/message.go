@@ -0,0 +1,146 @@
+package localrepo
+
+import (
+	"context"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	"sync"
+	"time"
+)
+
+// conversationKey беседа однозначно задаётся объявлением и покупателем
+type conversationKey struct {
+	adID    int64
+	buyerID int64
+}
+
+// MessageRepo хранит беседы и сообщения в памяти. В отличие от AdRepo, возвращает копии: MarkRead
+// меняет сообщения, уже отданные вызывающему. ID бесед и сообщений начинаются с 1
+type MessageRepo struct {
+	conversations map[int64]*models.Conversation
+	byKey         map[conversationKey]int64
+	messages      map[int64][]*models.Message
+	lastMessageID int64
+	mutex         sync.Mutex
+}
+
+func NewMessageRepo() *MessageRepo {
+	return &MessageRepo{
+		conversations: make(map[int64]*models.Conversation),
+		byKey:         make(map[conversationKey]int64),
+		messages:      make(map[int64][]*models.Message),
+	}
+}
+
+func (r *MessageRepo) GetOrAddConversation(ctx context.Context, conversation models.Conversation) (*models.Conversation, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		key := conversationKey{adID: conversation.AdID, buyerID: conversation.BuyerID}
+		if id, ok := r.byKey[key]; ok {
+			existing := *r.conversations[id]
+			return &existing, nil
+		}
+		conversation.ID = int64(len(r.conversations)) + 1
+		stored := conversation
+		r.conversations[conversation.ID] = &stored
+		r.byKey[key] = conversation.ID
+		return &conversation, nil
+	}
+}
+
+func (r *MessageRepo) GetConversation(ctx context.Context, conversationID int64) (*models.Conversation, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		conversation, ok := r.conversations[conversationID]
+		if !ok {
+			return nil, domain.ErrConversationNotExist
+		}
+		found := *conversation
+		return &found, nil
+	}
+}
+
+func (r *MessageRepo) GetConversations(ctx context.Context, userID int64) ([]*models.Conversation, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		conversations := make([]*models.Conversation, 0)
+		for id := int64(1); id <= int64(len(r.conversations)); id++ {
+			if conversation := r.conversations[id]; conversation.HasParticipant(userID) {
+				found := *conversation
+				conversations = append(conversations, &found)
+			}
+		}
+		return conversations, nil
+	}
+}
+
+func (r *MessageRepo) AddMessage(ctx context.Context, message models.Message) (int64, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		conversation, ok := r.conversations[message.ConversationID]
+		if !ok {
+			return 0, domain.ErrConversationNotExist
+		}
+		r.lastMessageID++
+		message.ID = r.lastMessageID
+		r.messages[message.ConversationID] = append(r.messages[message.ConversationID], &message)
+		conversation.LastMessageAt = message.DateCreation
+		return message.ID, nil
+	}
+}
+
+func (r *MessageRepo) GetMessages(ctx context.Context, conversationID int64) ([]*models.Message, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		if _, ok := r.conversations[conversationID]; !ok {
+			return nil, domain.ErrConversationNotExist
+		}
+		messages := make([]*models.Message, 0, len(r.messages[conversationID]))
+		for _, message := range r.messages[conversationID] {
+			found := *message
+			messages = append(messages, &found)
+		}
+		return messages, nil
+	}
+}
+
+func (r *MessageRepo) MarkRead(ctx context.Context, conversationID int64, readerID int64, readAt time.Time) (int, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		if _, ok := r.conversations[conversationID]; !ok {
+			return 0, domain.ErrConversationNotExist
+		}
+		marked := 0
+		for _, message := range r.messages[conversationID] {
+			if message.SenderID != readerID && !message.IsRead() {
+				message.ReadAt = readAt
+				marked++
+			}
+		}
+		return marked, nil
+	}
+}
@@ -0,0 +1,76 @@
+package localrepo
+
+import (
+	"context"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageRepo(t *testing.T) {
+	messageRepo := NewMessageRepo()
+	ctx := context.Background()
+	start := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	conversation, err := messageRepo.GetOrAddConversation(ctx,
+		models.Conversation{AdID: 10, BuyerID: 2, SellerID: 1, DateCreation: start})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), conversation.ID)
+	same, err := messageRepo.GetOrAddConversation(ctx,
+		models.Conversation{AdID: 10, BuyerID: 2, SellerID: 1, DateCreation: start.Add(time.Hour)})
+	assert.NoError(t, err)
+	assert.Equal(t, conversation, same, "the conversation is keyed by ad and buyer")
+	other, err := messageRepo.GetOrAddConversation(ctx, models.Conversation{AdID: 10, BuyerID: 3, SellerID: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), other.ID)
+
+	for i, message := range []models.Message{
+		{ConversationID: 1, SenderID: 2, Text: "hello"},
+		{ConversationID: 1, SenderID: 2, Text: "is it available?"},
+		{ConversationID: 1, SenderID: 1, Text: "yes"},
+	} {
+		message.DateCreation = start.Add(time.Duration(i) * time.Minute)
+		id, err := messageRepo.AddMessage(ctx, message)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(i+1), id)
+	}
+	_, err = messageRepo.AddMessage(ctx, models.Message{ConversationID: 5})
+	assert.Equal(t, domain.ErrConversationNotExist, err)
+
+	conversation, err = messageRepo.GetConversation(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, start.Add(2*time.Minute), conversation.LastMessageAt)
+
+	conversations, err := messageRepo.GetConversations(ctx, 1)
+	assert.NoError(t, err)
+	assert.Len(t, conversations, 2)
+	conversations, err = messageRepo.GetConversations(ctx, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.Conversation{other}, conversations)
+
+	messages, err := messageRepo.GetMessages(ctx, 1)
+	assert.NoError(t, err)
+	require.Len(t, messages, 3)
+
+	marked, err := messageRepo.MarkRead(ctx, 1, 1, start.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, marked)
+	assert.False(t, messages[0].IsRead(), "returned messages are copies")
+	messages, err = messageRepo.GetMessages(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, start.Add(time.Hour), messages[0].ReadAt)
+	assert.False(t, messages[2].IsRead(), "own messages are not marked")
+
+	marked, err = messageRepo.MarkRead(ctx, 1, 1, start.Add(2*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, marked)
+
+	_, err = messageRepo.GetConversation(ctx, 5)
+	assert.Equal(t, domain.ErrConversationNotExist, err)
+	_, err = messageRepo.GetMessages(ctx, 5)
+	assert.Equal(t, domain.ErrConversationNotExist, err)
+}
@@ -0,0 +1,70 @@
+package localrepo
+
+import (
+	"context"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	"sync"
+)
+
+type RevisionRepo struct {
+	storage map[int64][]*models.AdRevision
+	mutex   sync.Mutex
+}
+
+func NewRevisionRepo() *RevisionRepo {
+	return &RevisionRepo{storage: make(map[int64][]*models.AdRevision)}
+}
+
+func (r *RevisionRepo) AddRevision(ctx context.Context, revision models.AdRevision) (int64, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		revision.Number = int64(len(r.storage[revision.AdID])) + 1
+		r.storage[revision.AdID] = append(r.storage[revision.AdID], &revision)
+		return revision.Number, nil
+	}
+}
+
+func (r *RevisionRepo) GetRevisions(ctx context.Context, adID int64) ([]*models.AdRevision, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		revisions := make([]*models.AdRevision, len(r.storage[adID]))
+		copy(revisions, r.storage[adID])
+		return revisions, nil
+	}
+}
+
+func (r *RevisionRepo) GetRevision(ctx context.Context, adID int64, number int64) (*models.AdRevision, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		revisions := r.storage[adID]
+		if number < 1 || number > int64(len(revisions)) {
+			return nil, domain.ErrRevisionNotExist
+		}
+		return revisions[number-1], nil
+	}
+}
+
+func (r *RevisionRepo) DeleteRevisions(ctx context.Context, adID int64) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		delete(r.storage, adID)
+		return nil
+	}
+}
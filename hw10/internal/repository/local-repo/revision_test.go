@@ -0,0 +1,82 @@
+package localrepo
+
+import (
+	"context"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevisionRepo(t *testing.T) {
+	revisionRepo := NewRevisionRepo()
+	ctx := context.Background()
+
+	for _, revision := range []models.AdRevision{
+		{AdID: 1, Type: models.RevisionCreated, Title: "title"},
+		{AdID: 2, Type: models.RevisionCreated, Title: "other"},
+		{AdID: 1, Type: models.RevisionUpdated, Title: "new title"},
+	} {
+		_, err := revisionRepo.AddRevision(ctx, revision)
+		assert.NoError(t, err)
+	}
+
+	tests := []struct {
+		name     string
+		adID     int64
+		number   int64
+		expected *models.AdRevision
+		err      error
+	}{
+		{
+			name:     "first revision",
+			adID:     1,
+			number:   1,
+			expected: &models.AdRevision{AdID: 1, Number: 1, Type: models.RevisionCreated, Title: "title"},
+		},
+		{
+			name:     "numbers are per ad",
+			adID:     1,
+			number:   2,
+			expected: &models.AdRevision{AdID: 1, Number: 2, Type: models.RevisionUpdated, Title: "new title"},
+		},
+		{
+			name:   "revision does not exist",
+			adID:   2,
+			number: 2,
+			err:    domain.ErrRevisionNotExist,
+		},
+		{
+			name:   "zero number",
+			adID:   1,
+			number: 0,
+			err:    domain.ErrRevisionNotExist,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			revision, err := revisionRepo.GetRevision(ctx, tc.adID, tc.number)
+			assert.Equal(t, tc.err, err)
+			assert.Equal(t, tc.expected, revision)
+		})
+	}
+
+	revisions, err := revisionRepo.GetRevisions(ctx, 1)
+	assert.NoError(t, err)
+	assert.Len(t, revisions, 2)
+
+	assert.NoError(t, revisionRepo.DeleteRevisions(ctx, 1))
+	revisions, err = revisionRepo.GetRevisions(ctx, 1)
+	assert.NoError(t, err)
+	assert.Empty(t, revisions)
+	revisions, err = revisionRepo.GetRevisions(ctx, 2)
+	assert.NoError(t, err)
+	assert.Len(t, revisions, 1, "revisions of other ads are kept")
+	assert.NoError(t, revisionRepo.DeleteRevisions(ctx, 3), "an ad without revisions")
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = revisionRepo.AddRevision(canceled, models.AdRevision{AdID: 1})
+	assert.Equal(t, context.Canceled, err)
+}
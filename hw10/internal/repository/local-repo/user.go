@@ -2,9 +2,11 @@ package localrepo
 
 import (
 	"context"
-	"fmt"
+	"homework10/internal/domain"
 	"homework10/internal/domain/models"
+	"sort"
 	"sync"
+	"time"
 )
 
 type UserRepo struct {
@@ -26,12 +28,32 @@ func (r *UserRepo) GetUser(ctx context.Context, id int64) (*models.User, error)
 		defer r.mutex.Unlock()
 		user, ok := r.storage[id]
 		if !ok {
-			return nil, fmt.Errorf("the user does not exist")
+			return nil, domain.ErrUserNotExist
 		}
 		return user, nil
 	}
 }
 
+func (r *UserRepo) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		var found *models.User
+		for _, user := range r.storage {
+			if user.Email == email && (found == nil || user.ID < found.ID) {
+				found = user
+			}
+		}
+		if found == nil {
+			return nil, domain.ErrUserNotExist
+		}
+		return found, nil
+	}
+}
+
 func (r *UserRepo) AddUser(ctx context.Context, user models.User) (int64, error) {
 	select {
 	case <-ctx.Done():
@@ -39,7 +61,11 @@ func (r *UserRepo) AddUser(ctx context.Context, user models.User) (int64, error)
 	default:
 		r.mutex.Lock()
 		defer r.mutex.Unlock()
+		if err := r.checkUnique(-1, user.NickName, user.Email); err != nil {
+			return 0, err
+		}
 		r.lastUserID++
+		user.ID = r.lastUserID
 		r.storage[r.lastUserID] = &user
 		return r.lastUserID, nil
 	}
@@ -52,9 +78,101 @@ func (r *UserRepo) Update(ctx context.Context, userID int64, nickName string, em
 	default:
 		r.mutex.Lock()
 		defer r.mutex.Unlock()
-		r.storage[userID].NickName = nickName
-		r.storage[userID].Email = email
-		return r.storage[userID], nil
+		user, ok := r.storage[userID]
+		if !ok {
+			return nil, domain.ErrUserNotExist
+		}
+		if err := r.checkUnique(userID, nickName, email); err != nil {
+			return nil, err
+		}
+		user.NickName = nickName
+		user.Email = email
+		return user, nil
+	}
+}
+
+func (r *UserRepo) SetPasswordHash(ctx context.Context, userID int64, passwordHash []byte) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		user, ok := r.storage[userID]
+		if !ok {
+			return domain.ErrUserNotExist
+		}
+		user.PasswordHash = passwordHash
+		return nil
+	}
+}
+
+func (r *UserRepo) SetRole(ctx context.Context, userID int64, role models.Role) (*models.User, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		user, ok := r.storage[userID]
+		if !ok {
+			return nil, domain.ErrUserNotExist
+		}
+		user.Role = role
+		return user, nil
+	}
+}
+
+func (r *UserRepo) SetDeletedAt(ctx context.Context, userID int64, deletedAt time.Time) (*models.User, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		user, ok := r.storage[userID]
+		if !ok {
+			return nil, domain.ErrUserNotExist
+		}
+		user.DeletedAt = deletedAt
+		return user, nil
+	}
+}
+
+func (r *UserRepo) GetDeletedUsers(ctx context.Context, before time.Time) ([]*models.User, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		users := make([]*models.User, 0)
+		for _, user := range r.storage {
+			if user.IsDeleted() && user.DeletedAt.Before(before) {
+				users = append(users, user)
+			}
+		}
+		sort.Slice(users, func(i, j int) bool {
+			return users[i].ID < users[j].ID
+		})
+		return users, nil
+	}
+}
+
+func (r *UserRepo) CountUsers(ctx context.Context) (int, error) {
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	default:
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		count := 0
+		for _, user := range r.storage {
+			if !user.IsDeleted() {
+				count++
+			}
+		}
+		return count, nil
 	}
 }
 
@@ -69,3 +187,19 @@ func (r *UserRepo) Delete(ctx context.Context, userID int64) error {
 		return nil
 	}
 }
+
+// checkUnique проверяет, что почта и никнейм не заняты другими пользователями, кроме userID
+func (r *UserRepo) checkUnique(userID int64, nickName string, email string) error {
+	for _, user := range r.storage {
+		if user.ID == userID {
+			continue
+		}
+		if user.Email == email {
+			return domain.ErrAlreadyExists{Err: domain.ErrEmailTaken}
+		}
+		if user.NickName == nickName {
+			return domain.ErrAlreadyExists{Err: domain.ErrNickNameTaken}
+		}
+	}
+	return nil
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"homework10/internal/domain"
 	"homework10/internal/domain/models"
 	"testing"
+	"time"
 )
 
 func TestUserRepo_GetUser(t *testing.T) {
@@ -36,7 +38,7 @@ func TestUserRepo_GetUser(t *testing.T) {
 		{
 			name:   " test GetUser()",
 			userID: 10,
-			err:    fmt.Errorf("the user does not exist"),
+			err:    domain.ErrUserNotExist,
 			cancel: false,
 		},
 		{
@@ -217,3 +219,176 @@ func TestUserRepo_DeleteUser(t *testing.T) {
 		})
 	}
 }
+
+func TestUserRepo_GetUserByEmail(t *testing.T) {
+	userRepo := NewUserRepo()
+	userRepo.storage[0] = &models.User{ID: 0, NickName: "first", Email: "test email"}
+	userRepo.storage[1] = &models.User{ID: 1, NickName: "second", Email: "test email"}
+	userRepo.storage[2] = &models.User{ID: 2, NickName: "third", Email: "other email"}
+
+	tests := []struct {
+		name         string
+		email        string
+		expectedUser *models.User
+		err          error
+		cancel       bool
+	}{
+		{
+			name:         "the earliest user with the email is returned",
+			email:        "test email",
+			expectedUser: &models.User{ID: 0, NickName: "first", Email: "test email"},
+		},
+		{
+			name:         "successfully test GetUserByEmail()",
+			email:        "other email",
+			expectedUser: &models.User{ID: 2, NickName: "third", Email: "other email"},
+		},
+		{
+			name:  "unknown email",
+			email: "unknown email",
+			err:   domain.ErrUserNotExist,
+		},
+		{
+			name:   "cancel context test GetUserByEmail()",
+			email:  "test email",
+			err:    context.Canceled,
+			cancel: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tc.cancel {
+				cancel()
+			}
+
+			user, err := userRepo.GetUserByEmail(ctx, tc.email)
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				assert.Nil(t, user)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, *tc.expectedUser, *user)
+		})
+	}
+}
+
+func TestUserRepo_Unique(t *testing.T) {
+	userRepo := NewUserRepo()
+	ctx := context.Background()
+
+	_, err := userRepo.AddUser(ctx, models.User{NickName: "first", Email: "first email"})
+	assert.NoError(t, err)
+	secondID, err := userRepo.AddUser(ctx, models.User{NickName: "second", Email: "second email"})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name string
+		err  error
+		act  func() error
+	}{
+		{
+			name: "add user with taken email",
+			err:  domain.ErrAlreadyExists{Err: domain.ErrEmailTaken},
+			act: func() error {
+				_, err := userRepo.AddUser(ctx, models.User{NickName: "third", Email: "first email"})
+				return err
+			},
+		},
+		{
+			name: "add user with taken nickname",
+			err:  domain.ErrAlreadyExists{Err: domain.ErrNickNameTaken},
+			act: func() error {
+				_, err := userRepo.AddUser(ctx, models.User{NickName: "first", Email: "third email"})
+				return err
+			},
+		},
+		{
+			name: "update user to taken email",
+			err:  domain.ErrAlreadyExists{Err: domain.ErrEmailTaken},
+			act: func() error {
+				_, err := userRepo.Update(ctx, secondID, "second", "first email")
+				return err
+			},
+		},
+		{
+			name: "update user keeping own email",
+			act: func() error {
+				_, err := userRepo.Update(ctx, secondID, "renamed", "second email")
+				return err
+			},
+		},
+		{
+			name: "update not existing user",
+			err:  domain.ErrUserNotExist,
+			act: func() error {
+				_, err := userRepo.Update(ctx, 100, "nickname", "email")
+				return err
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.err, tc.act())
+		})
+	}
+	assert.Len(t, userRepo.storage, 2)
+}
+
+func TestUserRepo_SetPasswordHash(t *testing.T) {
+	userRepo := NewUserRepo()
+	ctx := context.Background()
+
+	userID, err := userRepo.AddUser(ctx, models.User{NickName: "test nickname", Email: "test email"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, userRepo.SetPasswordHash(ctx, userID, []byte("hash")))
+	user, err := userRepo.GetUser(ctx, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("hash"), user.PasswordHash)
+
+	assert.Equal(t, domain.ErrUserNotExist, userRepo.SetPasswordHash(ctx, 100, []byte("hash")))
+}
+
+func TestUserRepo_SetRole(t *testing.T) {
+	userRepo := NewUserRepo()
+	ctx := context.Background()
+
+	userID, err := userRepo.AddUser(ctx, models.User{NickName: "test nickname", Email: "test email", Role: models.RoleUser})
+	assert.NoError(t, err)
+
+	user, err := userRepo.SetRole(ctx, userID, models.RoleModerator)
+	assert.NoError(t, err)
+	assert.True(t, user.IsModerator())
+	user, err = userRepo.GetUser(ctx, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.RoleModerator, user.Role)
+
+	_, err = userRepo.SetRole(ctx, 100, models.RoleModerator)
+	assert.Equal(t, domain.ErrUserNotExist, err)
+}
+
+func TestUserRepo_GetDeletedUsers(t *testing.T) {
+	ctx := context.Background()
+	userRepo := NewUserRepo()
+	now := time.Now().UTC()
+	for i, deletedAt := range []time.Time{{}, now.Add(-time.Hour), now} {
+		userID, err := userRepo.AddUser(ctx, models.User{NickName: fmt.Sprint("user", i), Email: fmt.Sprint("email", i)})
+		assert.NoError(t, err)
+		_, err = userRepo.SetDeletedAt(ctx, userID, deletedAt)
+		assert.NoError(t, err)
+	}
+
+	users, err := userRepo.GetDeletedUsers(ctx, now.Add(-time.Minute))
+	assert.NoError(t, err)
+	assert.Len(t, users, 1)
+	assert.Equal(t, int64(1), users[0].ID)
+	count, err := userRepo.CountUsers(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	_, err = userRepo.SetDeletedAt(ctx, 100, now)
+	assert.Equal(t, domain.ErrUserNotExist, err)
+}
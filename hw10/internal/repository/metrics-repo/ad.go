@@ -0,0 +1,85 @@
+package metricsrepo
+
+import (
+	"context"
+	"time"
+
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+)
+
+type Observer interface {
+	ObserveRepository(repository string, operation string, started time.Time, err error)
+}
+
+// AdRepo измеряет время операций хранилища объявлений next
+type AdRepo struct {
+	next     domain.AdRepository
+	observer Observer
+}
+
+func NewAdRepo(next domain.AdRepository, observer Observer) *AdRepo {
+	return &AdRepo{next: next, observer: observer}
+}
+
+func (r *AdRepo) observe(operation string, started time.Time, err *error) {
+	r.observer.ObserveRepository("ad", operation, started, *err)
+}
+
+func (r *AdRepo) AddAd(ctx context.Context, ad models.Ad) (adID int64, err error) {
+	defer r.observe("AddAd", time.Now(), &err)
+	return r.next.AddAd(ctx, ad)
+}
+
+func (r *AdRepo) GetAd(ctx context.Context, adID int64) (ad *models.Ad, err error) {
+	defer r.observe("GetAd", time.Now(), &err)
+	return r.next.GetAd(ctx, adID)
+}
+
+func (r *AdRepo) SetState(ctx context.Context, adID int64, state models.AdState, reason string, version int64) (
+	ad *models.Ad, err error) {
+	defer r.observe("SetState", time.Now(), &err)
+	return r.next.SetState(ctx, adID, state, reason, version)
+}
+
+func (r *AdRepo) Update(ctx context.Context, adID int64, title string, text string, details models.AdDetails,
+	state models.AdState, version int64) (ad *models.Ad, err error) {
+	defer r.observe("Update", time.Now(), &err)
+	return r.next.Update(ctx, adID, title, text, details, state, version)
+}
+
+func (r *AdRepo) AddImage(ctx context.Context, adID int64, image models.AdImage, state models.AdState, version int64) (
+	ad *models.Ad, err error) {
+	defer r.observe("AddImage", time.Now(), &err)
+	return r.next.AddImage(ctx, adID, image, state, version)
+}
+
+func (r *AdRepo) SetDeletedAt(ctx context.Context, adID int64, deletedAt time.Time) (ad *models.Ad, err error) {
+	defer r.observe("SetDeletedAt", time.Now(), &err)
+	return r.next.SetDeletedAt(ctx, adID, deletedAt)
+}
+
+func (r *AdRepo) DeleteAd(ctx context.Context, adID int64) (err error) {
+	defer r.observe("DeleteAd", time.Now(), &err)
+	return r.next.DeleteAd(ctx, adID)
+}
+
+func (r *AdRepo) GetAds(ctx context.Context) (ads []*models.Ad, err error) {
+	defer r.observe("GetAds", time.Now(), &err)
+	return r.next.GetAds(ctx)
+}
+
+func (r *AdRepo) FindAds(ctx context.Context, filter models.AdFilter) (ads []*models.Ad, err error) {
+	defer r.observe("FindAds", time.Now(), &err)
+	return r.next.FindAds(ctx, filter)
+}
+
+func (r *AdRepo) CountFacets(ctx context.Context, filter models.AdFilter) (facets models.AdFacets, err error) {
+	defer r.observe("CountFacets", time.Now(), &err)
+	return r.next.CountFacets(ctx, filter)
+}
+
+func (r *AdRepo) CountAds(ctx context.Context) (total int, published int, err error) {
+	defer r.observe("CountAds", time.Now(), &err)
+	return r.next.CountAds(ctx)
+}
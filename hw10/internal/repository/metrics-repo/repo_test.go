@@ -0,0 +1,67 @@
+package metricsrepo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	localrepo "homework10/internal/repository/local-repo"
+)
+
+type observation struct {
+	repository string
+	operation  string
+	err        error
+}
+
+type testObserver struct {
+	observations []observation
+}
+
+func (o *testObserver) ObserveRepository(repository string, operation string, started time.Time, err error) {
+	o.observations = append(o.observations, observation{repository: repository, operation: operation, err: err})
+}
+
+func TestAdRepo(t *testing.T) {
+	ctx := context.Background()
+	observer := &testObserver{}
+	repo := NewAdRepo(localrepo.NewAdRepo(), observer)
+
+	adID, err := repo.AddAd(ctx, models.Ad{Title: "hello", Text: "world"})
+	assert.NoError(t, err)
+	ad, err := repo.GetAd(ctx, adID)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", ad.Title)
+	_, err = repo.GetAd(ctx, adID+100)
+	assert.Equal(t, domain.ErrAdNotExist, err, "errors are passed as is")
+	total, _, err := repo.CountAds(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+
+	assert.Equal(t, []observation{
+		{repository: "ad", operation: "AddAd"},
+		{repository: "ad", operation: "GetAd"},
+		{repository: "ad", operation: "GetAd", err: domain.ErrAdNotExist},
+		{repository: "ad", operation: "CountAds"},
+	}, observer.observations)
+}
+
+func TestUserRepo(t *testing.T) {
+	ctx := context.Background()
+	observer := &testObserver{}
+	repo := NewUserRepo(localrepo.NewUserRepo(), observer)
+
+	_, err := repo.AddUser(ctx, models.User{NickName: "ivan", Email: "ivan@gmail.com"})
+	assert.NoError(t, err)
+	count, err := repo.CountUsers(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	assert.Equal(t, []observation{
+		{repository: "user", operation: "AddUser"},
+		{repository: "user", operation: "CountUsers"},
+	}, observer.observations)
+}
@@ -0,0 +1,73 @@
+package metricsrepo
+
+import (
+	"context"
+	"time"
+
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+)
+
+// UserRepo измеряет время операций хранилища пользователей next
+type UserRepo struct {
+	next     domain.UserRepository
+	observer Observer
+}
+
+func NewUserRepo(next domain.UserRepository, observer Observer) *UserRepo {
+	return &UserRepo{next: next, observer: observer}
+}
+
+func (r *UserRepo) observe(operation string, started time.Time, err *error) {
+	r.observer.ObserveRepository("user", operation, started, *err)
+}
+
+func (r *UserRepo) GetUser(ctx context.Context, id int64) (user *models.User, err error) {
+	defer r.observe("GetUser", time.Now(), &err)
+	return r.next.GetUser(ctx, id)
+}
+
+func (r *UserRepo) GetUserByEmail(ctx context.Context, email string) (user *models.User, err error) {
+	defer r.observe("GetUserByEmail", time.Now(), &err)
+	return r.next.GetUserByEmail(ctx, email)
+}
+
+func (r *UserRepo) AddUser(ctx context.Context, user models.User) (userID int64, err error) {
+	defer r.observe("AddUser", time.Now(), &err)
+	return r.next.AddUser(ctx, user)
+}
+
+func (r *UserRepo) Update(ctx context.Context, userID int64, nickName string, email string) (user *models.User, err error) {
+	defer r.observe("Update", time.Now(), &err)
+	return r.next.Update(ctx, userID, nickName, email)
+}
+
+func (r *UserRepo) SetPasswordHash(ctx context.Context, userID int64, passwordHash []byte) (err error) {
+	defer r.observe("SetPasswordHash", time.Now(), &err)
+	return r.next.SetPasswordHash(ctx, userID, passwordHash)
+}
+
+func (r *UserRepo) SetRole(ctx context.Context, userID int64, role models.Role) (user *models.User, err error) {
+	defer r.observe("SetRole", time.Now(), &err)
+	return r.next.SetRole(ctx, userID, role)
+}
+
+func (r *UserRepo) SetDeletedAt(ctx context.Context, userID int64, deletedAt time.Time) (user *models.User, err error) {
+	defer r.observe("SetDeletedAt", time.Now(), &err)
+	return r.next.SetDeletedAt(ctx, userID, deletedAt)
+}
+
+func (r *UserRepo) GetDeletedUsers(ctx context.Context, before time.Time) (users []*models.User, err error) {
+	defer r.observe("GetDeletedUsers", time.Now(), &err)
+	return r.next.GetDeletedUsers(ctx, before)
+}
+
+func (r *UserRepo) CountUsers(ctx context.Context) (count int, err error) {
+	defer r.observe("CountUsers", time.Now(), &err)
+	return r.next.CountUsers(ctx)
+}
+
+func (r *UserRepo) Delete(ctx context.Context, userID int64) (err error) {
+	defer r.observe("Delete", time.Now(), &err)
+	return r.next.Delete(ctx, userID)
+}
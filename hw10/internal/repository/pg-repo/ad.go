@@ -0,0 +1,327 @@
+package pgrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const adColumns = "id, title, text, user_id, published, state, rejection_reason, date_creation, date_update, version, " +
+	"deleted_at, images, price_amount, price_currency, category_id, city"
+
+type AdRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewAdRepo(pool *pgxpool.Pool) *AdRepo {
+	return &AdRepo{pool: pool}
+}
+
+func (r *AdRepo) GetAd(ctx context.Context, adID int64) (*models.Ad, error) {
+	row := r.pool.QueryRow(ctx, "SELECT "+adColumns+" FROM ads WHERE id = $1", adID)
+	return scanAd(row)
+}
+
+func (r *AdRepo) GetAds(ctx context.Context) ([]*models.Ad, error) {
+	return r.FindAds(ctx, models.AdFilter{Deleted: models.WithDeleted})
+}
+
+// FindAds выбирает страницу по ключу (поле сортировки, id): курсор превращается в условие на пару значений,
+// поэтому смещение не нужно, и база читает только строки страницы
+func (r *AdRepo) FindAds(ctx context.Context, filter models.AdFilter) ([]*models.Ad, error) {
+	where, args := filterToWhere(filter)
+	query := "SELECT " + adColumns + " FROM ads" + where + orderBy(filter)
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	adSlice := make([]*models.Ad, 0)
+	for rows.Next() {
+		ad, err := scanAd(rows)
+		if err != nil {
+			return nil, err
+		}
+		adSlice = append(adSlice, ad)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return adSlice, nil
+}
+
+func (r *AdRepo) CountFacets(ctx context.Context, filter models.AdFilter) (models.AdFacets, error) {
+	filter.After, filter.Limit = nil, 0
+	where, args := filterToWhere(filter)
+	categories, err := r.countGroups(ctx,
+		"SELECT category_id, count(*) FROM ads"+where+" GROUP BY category_id", args)
+	if err != nil {
+		return models.AdFacets{}, err
+	}
+	cities, err := r.countGroups(ctx, "SELECT min(city COLLATE \"C\"), count(*) FROM ads"+where+" GROUP BY lower(city)", args)
+	if err != nil {
+		return models.AdFacets{}, err
+	}
+	return models.AdFacets{Categories: categories, Cities: cities}, nil
+}
+
+// countGroups читает пары (значение, число) запроса с GROUP BY; пустое значение не считается
+func (r *AdRepo) countGroups(ctx context.Context, query string, args []any) ([]models.FacetCount, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make([]models.FacetCount, 0)
+	for rows.Next() {
+		var count models.FacetCount
+		if err := rows.Scan(&count.Value, &count.Count); err != nil {
+			return nil, err
+		}
+		if count.Value != "" {
+			counts = append(counts, count)
+		}
+	}
+	return counts, rows.Err()
+}
+
+func (r *AdRepo) AddAd(ctx context.Context, ad models.Ad) (int64, error) {
+	var id int64
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO ads (title, text, user_id, published, state, rejection_reason, date_creation, date_update, version,
+		price_amount, price_currency, category_id, city)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id`,
+		ad.Title, ad.Text, ad.UserID, ad.Published, ad.State, ad.RejectionReason, ad.DateCreation, ad.DateUpdate,
+		ad.Version, ad.Price.Amount, ad.Price.Currency, ad.CategoryID, ad.City).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *AdRepo) SetState(ctx context.Context, adID int64, state models.AdState, reason string, version int64) (*models.Ad, error) {
+	row := r.pool.QueryRow(ctx,
+		`UPDATE ads SET state = $3, published = $4, rejection_reason = $5, version = version + 1, date_update = now()
+		WHERE id = $1 AND version = $2 RETURNING `+adColumns,
+		adID, version, state, state == models.AdPublished, reason)
+	return r.scanVersioned(ctx, row, adID)
+}
+
+func (r *AdRepo) Update(ctx context.Context, adID int64, title string, text string, details models.AdDetails,
+	state models.AdState, version int64) (*models.Ad, error) {
+	row := r.pool.QueryRow(ctx,
+		`UPDATE ads SET title = $3, text = $4, state = $5, published = $6, rejection_reason = '', version = version + 1,
+		date_update = now(), price_amount = $7, price_currency = $8, category_id = $9, city = $10
+		WHERE id = $1 AND version = $2 RETURNING `+adColumns,
+		adID, version, title, text, state, state == models.AdPublished,
+		details.Price.Amount, details.Price.Currency, details.CategoryID, details.City)
+	return r.scanVersioned(ctx, row, adID)
+}
+
+// AddImage дописывает изображение в JSONB-массив images; номер изображения - новая длина массива
+func (r *AdRepo) AddImage(ctx context.Context, adID int64, image models.AdImage, state models.AdState,
+	version int64) (*models.Ad, error) {
+	row := r.pool.QueryRow(ctx,
+		`UPDATE ads SET images = images || jsonb_build_array(jsonb_set($3::jsonb, '{id}', to_jsonb(jsonb_array_length(images) + 1))),
+		state = $4, published = $5, rejection_reason = '', version = version + 1, date_update = now()
+		WHERE id = $1 AND version = $2 RETURNING `+adColumns,
+		adID, version, image, state, state == models.AdPublished)
+	return r.scanVersioned(ctx, row, adID)
+}
+
+// scanVersioned читает результат UPDATE с проверкой версии; если строка не обновилась, различает
+// отсутствующее объявление и несовпадение версии
+func (r *AdRepo) scanVersioned(ctx context.Context, row pgx.Row, adID int64) (*models.Ad, error) {
+	ad, err := scanAd(row)
+	if !errors.Is(err, domain.ErrAdNotExist) {
+		return ad, err
+	}
+	var exists bool
+	if err := r.pool.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM ads WHERE id = $1)", adID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, domain.ErrAdVersionMismatch
+	}
+	return nil, domain.ErrAdNotExist
+}
+
+func (r *AdRepo) SetDeletedAt(ctx context.Context, adID int64, deletedAt time.Time) (*models.Ad, error) {
+	row := r.pool.QueryRow(ctx, "UPDATE ads SET deleted_at = $2 WHERE id = $1 RETURNING "+adColumns,
+		adID, nullTime(deletedAt))
+	return scanAd(row)
+}
+
+func (r *AdRepo) DeleteAd(ctx context.Context, adID int64) error {
+	_, err := r.pool.Exec(ctx, "DELETE FROM ads WHERE id = $1", adID)
+	return err
+}
+
+func (r *AdRepo) CountAds(ctx context.Context) (int, int, error) {
+	var total, published int
+	err := r.pool.QueryRow(ctx,
+		"SELECT count(*), count(*) FILTER (WHERE published) FROM ads WHERE deleted_at IS NULL").Scan(&total, &published)
+	return total, published, err
+}
+
+// filterToWhere собирает из фильтра WHERE-условие и его аргументы
+func filterToWhere(filter models.AdFilter) (string, []any) {
+	conditions := make([]string, 0)
+	args := make([]any, 0)
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if len(filter.IDs) != 0 {
+		add("id = ANY($%d)", filter.IDs)
+	}
+	if filter.Published != nil {
+		add("published = $%d", *filter.Published)
+	}
+	if filter.State != "" {
+		add("state = $%d", filter.State)
+	}
+	if filter.VisibleTo != nil {
+		add("(published OR user_id = $%d)", *filter.VisibleTo)
+	}
+	if len(filter.AuthorIDs) != 0 {
+		add("user_id = ANY($%d)", filter.AuthorIDs)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		add("date_creation >= $%d", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		add("date_creation < $%d", filter.CreatedTo)
+	}
+	if !filter.UpdatedFrom.IsZero() {
+		add("date_update >= $%d", filter.UpdatedFrom)
+	}
+	if !filter.UpdatedTo.IsZero() {
+		add("date_update < $%d", filter.UpdatedTo)
+	}
+	if filter.Title != "" {
+		add("strpos(title, $%d) > 0", filter.Title)
+	}
+	if filter.Currency != "" {
+		add("price_currency = $%d", filter.Currency)
+	}
+	if filter.PriceMin != nil || filter.PriceMax != nil {
+		conditions = append(conditions, "price_currency <> ''")
+	}
+	if filter.PriceMin != nil {
+		add("price_amount >= $%d", *filter.PriceMin)
+	}
+	if filter.PriceMax != nil {
+		add("price_amount <= $%d", *filter.PriceMax)
+	}
+	if len(filter.CategoryIDs) != 0 {
+		add("category_id = ANY($%d)", filter.CategoryIDs)
+	}
+	if filter.City != "" {
+		add("lower(city) = lower($%d)", filter.City)
+	}
+	if filter.After != nil {
+		column, value := sortColumn(filter.SortBy, filter.After)
+		operator := ">"
+		if filter.Order == models.SortDesc {
+			operator = "<"
+		}
+		if column == "id" {
+			add("id "+operator+" $%d", filter.After.ID)
+		} else {
+			args = append(args, value, filter.After.ID)
+			conditions = append(conditions,
+				fmt.Sprintf("(%s, id) %s ($%d, $%d)", column, operator, len(args)-1, len(args)))
+		}
+	}
+	switch filter.Deleted {
+	case models.OnlyDeleted:
+		conditions = append(conditions, "deleted_at IS NOT NULL")
+	case models.WithoutDeleted:
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// sortColumn столбец поля сортировки и его значение у объявления
+func sortColumn(field models.AdSortField, ad *models.Ad) (string, any) {
+	switch field {
+	case models.AdSortByDateCreation:
+		return "date_creation", ad.DateCreation
+	case models.AdSortByDateUpdate:
+		return "date_update", ad.DateUpdate
+	case models.AdSortByTitle:
+		return "title", ad.Title
+	default:
+		return "id", ad.ID
+	}
+}
+
+// orderBy ORDER BY для порядка фильтра; при равных значениях поля строки упорядочены по id
+func orderBy(filter models.AdFilter) string {
+	direction := ""
+	if filter.Order == models.SortDesc {
+		direction = " DESC"
+	}
+	column, _ := sortColumn(filter.SortBy, &models.Ad{})
+	if column == "id" {
+		return " ORDER BY id" + direction
+	}
+	return " ORDER BY " + column + direction + ", id" + direction
+}
+
+func scanAd(row pgx.Row) (*models.Ad, error) {
+	var ad models.Ad
+	var deletedAt *time.Time
+	err := row.Scan(&ad.ID, &ad.Title, &ad.Text, &ad.UserID, &ad.Published, &ad.State, &ad.RejectionReason,
+		&ad.DateCreation, &ad.DateUpdate, &ad.Version, &deletedAt, &ad.Images,
+		&ad.Price.Amount, &ad.Price.Currency, &ad.CategoryID, &ad.City)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrAdNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	ad.DateCreation = ad.DateCreation.UTC()
+	ad.DateUpdate = ad.DateUpdate.UTC()
+	ad.DeletedAt = fromNullTime(deletedAt)
+	if len(ad.Images) == 0 {
+		// как в остальных хранилищах: у объявления без изображений срез пустой
+		ad.Images = nil
+	}
+	return &ad, nil
+}
+
+// nullTime заменяет нулевое время на NULL
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// fromNullTime переводит NULL в нулевое время, остальные значения - в UTC
+func fromNullTime(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return t.UTC()
+}
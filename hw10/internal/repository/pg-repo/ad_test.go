@@ -0,0 +1,125 @@
+package pgrepo
+
+import (
+	"errors"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterToWhere(t *testing.T) {
+	published := true
+	viewer := int64(5)
+	priceMin := int64(100)
+
+	tests := []struct {
+		name  string
+		where string
+		args  []any
+		in    models.AdFilter
+	}{
+		{
+			name:  "empty filter hides the trash",
+			where: " WHERE deleted_at IS NULL",
+			args:  []any{},
+		},
+		{
+			name: "with deleted ads there is no condition",
+			in:   models.AdFilter{Deleted: models.WithDeleted},
+			args: []any{},
+		},
+		{
+			name:  "published and visible to the viewer",
+			in:    models.AdFilter{Published: &published, VisibleTo: &viewer, Deleted: models.OnlyDeleted},
+			where: " WHERE published = $1 AND (published OR user_id = $2) AND deleted_at IS NOT NULL",
+			args:  []any{true, viewer},
+		},
+		{
+			name: "price range requires a price",
+			in:   models.AdFilter{PriceMin: &priceMin, Currency: "RUB", City: "Москва"},
+			where: " WHERE price_currency = $1 AND price_currency <> '' AND price_amount >= $2 AND lower(city) = lower($3)" +
+				" AND deleted_at IS NULL",
+			args: []any{"RUB", priceMin, "Москва"},
+		},
+		{
+			name:  "cursor by id",
+			in:    models.AdFilter{After: &models.Ad{ID: 3, Title: "ignored"}, Deleted: models.WithDeleted},
+			where: " WHERE id > $1",
+			args:  []any{int64(3)},
+		},
+		{
+			name: "cursor by title descending",
+			in: models.AdFilter{AuthorIDs: []int64{1}, SortBy: models.AdSortByTitle, Order: models.SortDesc,
+				After: &models.Ad{ID: 3, Title: "b"}},
+			where: " WHERE user_id = ANY($1) AND (title, id) < ($2, $3) AND deleted_at IS NULL",
+			args:  []any{[]int64{1}, "b", int64(3)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, args := filterToWhere(tt.in)
+			assert.Equal(t, tt.where, where)
+			assert.Equal(t, tt.args, args)
+		})
+	}
+}
+
+func TestOrderBy(t *testing.T) {
+	assert.Equal(t, " ORDER BY id", orderBy(models.AdFilter{}))
+	assert.Equal(t, " ORDER BY id DESC", orderBy(models.AdFilter{Order: models.SortDesc}))
+	assert.Equal(t, " ORDER BY date_update DESC, id DESC",
+		orderBy(models.AdFilter{SortBy: models.AdSortByDateUpdate, Order: models.SortDesc}))
+	assert.Equal(t, " ORDER BY date_creation, id", orderBy(models.AdFilter{SortBy: models.AdSortByDateCreation}))
+}
+
+// testRow строка результата запроса: Scan раскладывает values по переданным указателям
+type testRow struct {
+	values []any
+	err    error
+}
+
+func (r testRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	for i, value := range r.values {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(value))
+	}
+	return nil
+}
+
+func TestScanAd(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	created := time.Date(2023, 1, 2, 15, 0, 0, 0, moscow)
+	deleted := time.Date(2023, 1, 3, 15, 0, 0, 0, moscow)
+	row := func(deletedAt *time.Time, images []models.AdImage) testRow {
+		return testRow{values: []any{int64(7), "title", "text", int64(1), true, models.AdPublished, "",
+			created, created, int64(2), deletedAt, images, int64(100), "RUB", "cars", "Москва"}}
+	}
+
+	ad, err := scanAd(row(nil, []models.AdImage{}))
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Ad{ID: 7, Title: "title", Text: "text", UserID: 1, Published: true,
+		State: models.AdPublished, DateCreation: created.UTC(), DateUpdate: created.UTC(), Version: 2,
+		AdDetails: models.AdDetails{Price: models.Price{Amount: 100, Currency: "RUB"}, CategoryID: "cars",
+			City: "Москва"}}, ad)
+	assert.Equal(t, time.UTC, ad.DateCreation.Location())
+	assert.Nil(t, ad.Images, "ads without images have a nil slice as in other repositories")
+
+	images := []models.AdImage{{ID: 1, Key: "ads/7/a"}}
+	ad, err = scanAd(row(&deleted, images))
+	assert.NoError(t, err)
+	assert.Equal(t, deleted.UTC(), ad.DeletedAt)
+	assert.Equal(t, images, ad.Images)
+
+	_, err = scanAd(testRow{err: pgx.ErrNoRows})
+	assert.Equal(t, domain.ErrAdNotExist, err)
+	broken := errors.New("connection lost")
+	_, err = scanAd(testRow{err: broken})
+	assert.Equal(t, broken, err)
+}
@@ -0,0 +1,62 @@
+package pgrepo
+
+import (
+	"context"
+	"homework10/internal/domain/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type FavoriteRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewFavoriteRepo(pool *pgxpool.Pool) *FavoriteRepo {
+	return &FavoriteRepo{pool: pool}
+}
+
+func (r *FavoriteRepo) AddFavorite(ctx context.Context, favorite models.Favorite) error {
+	_, err := r.pool.Exec(ctx,
+		"INSERT INTO favorites (user_id, ad_id, added_at) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING",
+		favorite.UserID, favorite.AdID, favorite.AddedAt)
+	return err
+}
+
+func (r *FavoriteRepo) RemoveFavorite(ctx context.Context, userID int64, adID int64) error {
+	_, err := r.pool.Exec(ctx, "DELETE FROM favorites WHERE user_id = $1 AND ad_id = $2", userID, adID)
+	return err
+}
+
+func (r *FavoriteRepo) GetFavorites(ctx context.Context, userID int64) ([]models.Favorite, error) {
+	rows, err := r.pool.Query(ctx,
+		"SELECT user_id, ad_id, added_at FROM favorites WHERE user_id = $1 ORDER BY added_at DESC, ad_id DESC",
+		userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	favorites := make([]models.Favorite, 0)
+	for rows.Next() {
+		var favorite models.Favorite
+		if err := rows.Scan(&favorite.UserID, &favorite.AdID, &favorite.AddedAt); err != nil {
+			return nil, err
+		}
+		favorite.AddedAt = favorite.AddedAt.UTC()
+		favorites = append(favorites, favorite)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return favorites, nil
+}
+
+func (r *FavoriteRepo) RemoveAdFavorites(ctx context.Context, adID int64) error {
+	_, err := r.pool.Exec(ctx, "DELETE FROM favorites WHERE ad_id = $1", adID)
+	return err
+}
+
+func (r *FavoriteRepo) RemoveUserFavorites(ctx context.Context, userID int64) error {
+	_, err := r.pool.Exec(ctx, "DELETE FROM favorites WHERE user_id = $1", userID)
+	return err
+}
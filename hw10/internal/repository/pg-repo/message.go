@@ -0,0 +1,163 @@
+package pgrepo
+
+import (
+	"context"
+	"errors"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	conversationColumns = "id, ad_id, buyer_id, seller_id, date_creation, last_message_at"
+	messageColumns      = "id, conversation_id, sender_id, text, date_creation, read_at"
+)
+
+type MessageRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewMessageRepo(pool *pgxpool.Pool) *MessageRepo {
+	return &MessageRepo{pool: pool}
+}
+
+// GetOrAddConversation опирается на уникальность (ad_id, buyer_id): при одновременном открытии беседы
+// вставка одного из запросов ничего не делает, и оба читают одну и ту же беседу
+func (r *MessageRepo) GetOrAddConversation(ctx context.Context, conversation models.Conversation) (*models.Conversation, error) {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO conversations (ad_id, buyer_id, seller_id, date_creation, last_message_at)
+		VALUES ($1, $2, $3, $4, $5) ON CONFLICT (ad_id, buyer_id) DO NOTHING`,
+		conversation.AdID, conversation.BuyerID, conversation.SellerID, conversation.DateCreation,
+		nullTime(conversation.LastMessageAt))
+	if err != nil {
+		return nil, err
+	}
+	row := r.pool.QueryRow(ctx, "SELECT "+conversationColumns+" FROM conversations WHERE ad_id = $1 AND buyer_id = $2",
+		conversation.AdID, conversation.BuyerID)
+	return scanConversation(row)
+}
+
+func (r *MessageRepo) GetConversation(ctx context.Context, conversationID int64) (*models.Conversation, error) {
+	row := r.pool.QueryRow(ctx, "SELECT "+conversationColumns+" FROM conversations WHERE id = $1", conversationID)
+	return scanConversation(row)
+}
+
+func (r *MessageRepo) GetConversations(ctx context.Context, userID int64) ([]*models.Conversation, error) {
+	rows, err := r.pool.Query(ctx,
+		"SELECT "+conversationColumns+" FROM conversations WHERE buyer_id = $1 OR seller_id = $1 ORDER BY id", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	conversations := make([]*models.Conversation, 0)
+	for rows.Next() {
+		conversation, err := scanConversation(rows)
+		if err != nil {
+			return nil, err
+		}
+		conversations = append(conversations, conversation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return conversations, nil
+}
+
+func (r *MessageRepo) AddMessage(ctx context.Context, message models.Message) (int64, error) {
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, "UPDATE conversations SET last_message_at = $2 WHERE id = $1",
+			message.ConversationID, message.DateCreation)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrConversationNotExist
+		}
+		return tx.QueryRow(ctx,
+			`INSERT INTO messages (conversation_id, sender_id, text, date_creation, read_at)
+			VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+			message.ConversationID, message.SenderID, message.Text, message.DateCreation,
+			nullTime(message.ReadAt)).Scan(&message.ID)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return message.ID, nil
+}
+
+func (r *MessageRepo) GetMessages(ctx context.Context, conversationID int64) ([]*models.Message, error) {
+	if err := r.checkConversation(ctx, conversationID); err != nil {
+		return nil, err
+	}
+	rows, err := r.pool.Query(ctx,
+		"SELECT "+messageColumns+" FROM messages WHERE conversation_id = $1 ORDER BY id", conversationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := make([]*models.Message, 0)
+	for rows.Next() {
+		var message models.Message
+		var readAt *time.Time
+		err := rows.Scan(&message.ID, &message.ConversationID, &message.SenderID, &message.Text,
+			&message.DateCreation, &readAt)
+		if err != nil {
+			return nil, err
+		}
+		message.DateCreation = message.DateCreation.UTC()
+		message.ReadAt = fromNullTime(readAt)
+		messages = append(messages, &message)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (r *MessageRepo) MarkRead(ctx context.Context, conversationID int64, readerID int64, readAt time.Time) (int, error) {
+	if err := r.checkConversation(ctx, conversationID); err != nil {
+		return 0, err
+	}
+	tag, err := r.pool.Exec(ctx,
+		"UPDATE messages SET read_at = $3 WHERE conversation_id = $1 AND sender_id <> $2 AND read_at IS NULL",
+		conversationID, readerID, readAt)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// checkConversation возвращает ErrConversationNotExist, если беседы нет
+func (r *MessageRepo) checkConversation(ctx context.Context, conversationID int64) error {
+	var exists bool
+	err := r.pool.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM conversations WHERE id = $1)", conversationID).
+		Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrConversationNotExist
+	}
+	return nil
+}
+
+func scanConversation(row pgx.Row) (*models.Conversation, error) {
+	var conversation models.Conversation
+	var lastMessageAt *time.Time
+	err := row.Scan(&conversation.ID, &conversation.AdID, &conversation.BuyerID, &conversation.SellerID,
+		&conversation.DateCreation, &lastMessageAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrConversationNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	conversation.DateCreation = conversation.DateCreation.UTC()
+	conversation.LastMessageAt = fromNullTime(lastMessageAt)
+	return &conversation, nil
+}
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS users
+(
+    id       BIGINT GENERATED BY DEFAULT AS IDENTITY (MINVALUE 0 START WITH 0) PRIMARY KEY,
+    nickname TEXT NOT NULL,
+    email    TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS ads
+(
+    id            BIGINT GENERATED BY DEFAULT AS IDENTITY (MINVALUE 0 START WITH 0) PRIMARY KEY,
+    title         TEXT    NOT NULL,
+    text          TEXT    NOT NULL,
+    user_id       BIGINT  NOT NULL,
+    published     BOOLEAN NOT NULL DEFAULT FALSE,
+    date_creation TEXT    NOT NULL,
+    date_update   TEXT    NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS ads_user_id_idx ON ads (user_id);
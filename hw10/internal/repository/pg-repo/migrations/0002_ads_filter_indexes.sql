@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS ads_published_idx ON ads (published);
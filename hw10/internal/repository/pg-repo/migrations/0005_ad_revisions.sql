@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS ad_revisions
+(
+    ad_id         BIGINT      NOT NULL,
+    number        BIGINT      NOT NULL,
+    type          TEXT        NOT NULL,
+    author_id     BIGINT      NOT NULL,
+    date          TIMESTAMPTZ NOT NULL,
+    title         TEXT        NOT NULL,
+    text          TEXT        NOT NULL,
+    published     BOOLEAN     NOT NULL,
+    changes       JSONB       NOT NULL DEFAULT '[]'::JSONB,
+    restored_from BIGINT      NOT NULL DEFAULT 0,
+    PRIMARY KEY (ad_id, number)
+);
@@ -0,0 +1,6 @@
+ALTER TABLE ads
+    ALTER COLUMN date_creation TYPE TIMESTAMPTZ USING to_date(date_creation, 'MM-DD-YYYY')::timestamp AT TIME ZONE 'UTC',
+    ALTER COLUMN date_update TYPE TIMESTAMPTZ USING to_date(date_update, 'MM-DD-YYYY')::timestamp AT TIME ZONE 'UTC';
+
+CREATE INDEX IF NOT EXISTS ads_date_creation_idx ON ads (date_creation);
+CREATE INDEX IF NOT EXISTS ads_date_update_idx ON ads (date_update);
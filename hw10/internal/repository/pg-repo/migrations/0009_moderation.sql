@@ -0,0 +1,10 @@
+ALTER TABLE ads
+    ADD COLUMN IF NOT EXISTS state TEXT NOT NULL DEFAULT 'draft',
+    ADD COLUMN IF NOT EXISTS rejection_reason TEXT NOT NULL DEFAULT '';
+
+-- уже опубликованные объявления остаются опубликованными без проверки
+UPDATE ads SET state = 'published' WHERE published;
+
+CREATE INDEX IF NOT EXISTS ads_state_idx ON ads (state);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'user';
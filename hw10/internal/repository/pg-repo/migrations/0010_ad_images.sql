@@ -0,0 +1 @@
+ALTER TABLE ads ADD COLUMN IF NOT EXISTS images JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,9 @@
+ALTER TABLE ads
+    ADD COLUMN IF NOT EXISTS price_amount BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS price_currency TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS category_id TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS city TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS ads_category_idx ON ads (category_id);
+CREATE INDEX IF NOT EXISTS ads_city_idx ON ads (lower(city));
+CREATE INDEX IF NOT EXISTS ads_price_idx ON ads (price_currency, price_amount);
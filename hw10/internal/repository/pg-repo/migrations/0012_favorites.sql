@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS favorites
+(
+    user_id  BIGINT      NOT NULL,
+    ad_id    BIGINT      NOT NULL,
+    added_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, ad_id)
+);
+
+CREATE INDEX IF NOT EXISTS favorites_ad_id_idx ON favorites (ad_id);
@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS ads_date_creation_idx ON ads (date_creation, id);
+CREATE INDEX IF NOT EXISTS ads_date_update_idx ON ads (date_update, id);
+CREATE INDEX IF NOT EXISTS ads_title_idx ON ads (title, id);
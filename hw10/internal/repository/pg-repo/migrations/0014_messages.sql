@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS conversations
+(
+    id              BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    ad_id           BIGINT      NOT NULL,
+    buyer_id        BIGINT      NOT NULL,
+    seller_id       BIGINT      NOT NULL,
+    date_creation   TIMESTAMPTZ NOT NULL,
+    last_message_at TIMESTAMPTZ,
+    UNIQUE (ad_id, buyer_id)
+);
+
+CREATE INDEX IF NOT EXISTS conversations_buyer_id_idx ON conversations (buyer_id);
+CREATE INDEX IF NOT EXISTS conversations_seller_id_idx ON conversations (seller_id);
+
+CREATE TABLE IF NOT EXISTS messages
+(
+    id              BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    conversation_id BIGINT      NOT NULL REFERENCES conversations (id),
+    sender_id       BIGINT      NOT NULL,
+    text            TEXT        NOT NULL,
+    date_creation   TIMESTAMPTZ NOT NULL,
+    read_at         TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS messages_conversation_id_idx ON messages (conversation_id, id);
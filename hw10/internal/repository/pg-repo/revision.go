@@ -0,0 +1,97 @@
+package pgrepo
+
+import (
+	"context"
+	"errors"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const revisionColumns = "ad_id, number, type, author_id, date, title, text, published, changes, restored_from"
+
+// addRevisionAttempts ограничивает число повторов вставки при гонке за номер ревизии
+const addRevisionAttempts = 5
+
+type RevisionRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewRevisionRepo(pool *pgxpool.Pool) *RevisionRepo {
+	return &RevisionRepo{pool: pool}
+}
+
+func (r *RevisionRepo) AddRevision(ctx context.Context, revision models.AdRevision) (int64, error) {
+	changes := revision.Changes
+	if changes == nil {
+		changes = []models.FieldChange{}
+	}
+	var err error
+	for i := 0; i < addRevisionAttempts; i++ {
+		var number int64
+		err = r.pool.QueryRow(ctx,
+			`INSERT INTO ad_revisions (`+revisionColumns+`)
+			SELECT $1, COALESCE(MAX(number), 0) + 1, $2, $3, $4, $5, $6, $7, $8, $9
+			FROM ad_revisions WHERE ad_id = $1 RETURNING number`,
+			revision.AdID, revision.Type, revision.AuthorID, revision.Date, revision.Title, revision.Text,
+			revision.Published, changes, revision.RestoredFrom).Scan(&number)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		return number, nil
+	}
+	return 0, err
+}
+
+func (r *RevisionRepo) GetRevisions(ctx context.Context, adID int64) ([]*models.AdRevision, error) {
+	rows, err := r.pool.Query(ctx,
+		"SELECT "+revisionColumns+" FROM ad_revisions WHERE ad_id = $1 ORDER BY number", adID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := make([]*models.AdRevision, 0)
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (r *RevisionRepo) GetRevision(ctx context.Context, adID int64, number int64) (*models.AdRevision, error) {
+	row := r.pool.QueryRow(ctx,
+		"SELECT "+revisionColumns+" FROM ad_revisions WHERE ad_id = $1 AND number = $2", adID, number)
+	return scanRevision(row)
+}
+
+func (r *RevisionRepo) DeleteRevisions(ctx context.Context, adID int64) error {
+	_, err := r.pool.Exec(ctx, "DELETE FROM ad_revisions WHERE ad_id = $1", adID)
+	return err
+}
+
+func scanRevision(row pgx.Row) (*models.AdRevision, error) {
+	var revision models.AdRevision
+	err := row.Scan(&revision.AdID, &revision.Number, &revision.Type, &revision.AuthorID, &revision.Date,
+		&revision.Title, &revision.Text, &revision.Published, &revision.Changes, &revision.RestoredFrom)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrRevisionNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
@@ -0,0 +1,161 @@
+package pgrepo
+
+import (
+	"context"
+	"errors"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const userColumns = "id, nickname, email, role, password_hash, deleted_at"
+
+// uniqueViolation код ошибки PostgreSQL при нарушении уникального индекса
+const uniqueViolation = "23505"
+
+type UserRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewUserRepo(pool *pgxpool.Pool) *UserRepo {
+	return &UserRepo{pool: pool}
+}
+
+func (r *UserRepo) GetUser(ctx context.Context, id int64) (*models.User, error) {
+	row := r.pool.QueryRow(ctx, "SELECT "+userColumns+" FROM users WHERE id = $1", id)
+	return scanUser(row)
+}
+
+func (r *UserRepo) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	row := r.pool.QueryRow(ctx, "SELECT "+userColumns+" FROM users WHERE email = $1 ORDER BY id LIMIT 1", email)
+	return scanUser(row)
+}
+
+func (r *UserRepo) AddUser(ctx context.Context, user models.User) (int64, error) {
+	var id int64
+	err := r.pool.QueryRow(ctx,
+		"INSERT INTO users (nickname, email, role, password_hash) VALUES ($1, $2, $3, $4) RETURNING id",
+		user.NickName, user.Email, userRole(user.Role), passwordHash(user.PasswordHash)).Scan(&id)
+	if err != nil {
+		return 0, uniqueError(err)
+	}
+	return id, nil
+}
+
+func (r *UserRepo) Update(ctx context.Context, userID int64, nickName string, email string) (*models.User, error) {
+	row := r.pool.QueryRow(ctx,
+		"UPDATE users SET nickname = $2, email = $3 WHERE id = $1 RETURNING "+userColumns,
+		userID, nickName, email)
+	user, err := scanUser(row)
+	if err != nil {
+		return nil, uniqueError(err)
+	}
+	return user, nil
+}
+
+func (r *UserRepo) SetPasswordHash(ctx context.Context, userID int64, passwordHash []byte) error {
+	tag, err := r.pool.Exec(ctx, "UPDATE users SET password_hash = $2 WHERE id = $1", userID, passwordHash)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrUserNotExist
+	}
+	return nil
+}
+
+func (r *UserRepo) SetRole(ctx context.Context, userID int64, role models.Role) (*models.User, error) {
+	row := r.pool.QueryRow(ctx, "UPDATE users SET role = $2 WHERE id = $1 RETURNING "+userColumns, userID, role)
+	return scanUser(row)
+}
+
+func (r *UserRepo) SetDeletedAt(ctx context.Context, userID int64, deletedAt time.Time) (*models.User, error) {
+	row := r.pool.QueryRow(ctx, "UPDATE users SET deleted_at = $2 WHERE id = $1 RETURNING "+userColumns,
+		userID, nullTime(deletedAt))
+	return scanUser(row)
+}
+
+func (r *UserRepo) GetDeletedUsers(ctx context.Context, before time.Time) ([]*models.User, error) {
+	rows, err := r.pool.Query(ctx, "SELECT "+userColumns+" FROM users WHERE deleted_at < $1 ORDER BY id", before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]*models.User, 0)
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *UserRepo) CountUsers(ctx context.Context) (int, error) {
+	var count int
+	err := r.pool.QueryRow(ctx, "SELECT count(*) FROM users WHERE deleted_at IS NULL").Scan(&count)
+	return count, err
+}
+
+func (r *UserRepo) Delete(ctx context.Context, userID int64) error {
+	_, err := r.pool.Exec(ctx, "DELETE FROM users WHERE id = $1", userID)
+	return err
+}
+
+func scanUser(row pgx.Row) (*models.User, error) {
+	var user models.User
+	var deletedAt *time.Time
+	err := row.Scan(&user.ID, &user.NickName, &user.Email, &user.Role, &user.PasswordHash, &deletedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrUserNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(user.PasswordHash) == 0 {
+		user.PasswordHash = nil
+	}
+	user.DeletedAt = fromNullTime(deletedAt)
+	return &user, nil
+}
+
+// uniqueError переводит нарушение уникальности почты или никнейма в domain.ErrAlreadyExists
+func uniqueError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != uniqueViolation {
+		return err
+	}
+	switch pgErr.ConstraintName {
+	case "users_email_key":
+		return domain.ErrAlreadyExists{Err: domain.ErrEmailTaken}
+	case "users_nickname_key":
+		return domain.ErrAlreadyExists{Err: domain.ErrNickNameTaken}
+	default:
+		return err
+	}
+}
+
+// passwordHash заменяет отсутствующий хеш пустым значением, так как колонка NOT NULL
+func passwordHash(hash []byte) []byte {
+	if hash == nil {
+		return []byte{}
+	}
+	return hash
+}
+
+// userRole заменяет пустую роль ролью по умолчанию
+func userRole(role models.Role) models.Role {
+	if role == "" {
+		return models.RoleUser
+	}
+	return role
+}
@@ -0,0 +1,88 @@
+package tracingrepo
+
+import (
+	"context"
+	"time"
+
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	"homework10/internal/tracing"
+)
+
+// AdRepo записывает операции хранилища объявлений next спанами трассировки
+type AdRepo struct {
+	next domain.AdRepository
+}
+
+func NewAdRepo(next domain.AdRepository) *AdRepo {
+	return &AdRepo{next: next}
+}
+
+func (r *AdRepo) AddAd(ctx context.Context, ad models.Ad) (adID int64, err error) {
+	ctx, span := tracing.Start(ctx, "AdRepository.AddAd")
+	defer tracing.End(span, &err)
+	return r.next.AddAd(ctx, ad)
+}
+
+func (r *AdRepo) GetAd(ctx context.Context, adID int64) (ad *models.Ad, err error) {
+	ctx, span := tracing.Start(ctx, "AdRepository.GetAd")
+	defer tracing.End(span, &err)
+	return r.next.GetAd(ctx, adID)
+}
+
+func (r *AdRepo) SetState(ctx context.Context, adID int64, state models.AdState, reason string, version int64) (
+	ad *models.Ad, err error) {
+	ctx, span := tracing.Start(ctx, "AdRepository.SetState")
+	defer tracing.End(span, &err)
+	return r.next.SetState(ctx, adID, state, reason, version)
+}
+
+func (r *AdRepo) Update(ctx context.Context, adID int64, title string, text string, details models.AdDetails,
+	state models.AdState, version int64) (ad *models.Ad, err error) {
+	ctx, span := tracing.Start(ctx, "AdRepository.Update")
+	defer tracing.End(span, &err)
+	return r.next.Update(ctx, adID, title, text, details, state, version)
+}
+
+func (r *AdRepo) AddImage(ctx context.Context, adID int64, image models.AdImage, state models.AdState, version int64) (
+	ad *models.Ad, err error) {
+	ctx, span := tracing.Start(ctx, "AdRepository.AddImage")
+	defer tracing.End(span, &err)
+	return r.next.AddImage(ctx, adID, image, state, version)
+}
+
+func (r *AdRepo) SetDeletedAt(ctx context.Context, adID int64, deletedAt time.Time) (ad *models.Ad, err error) {
+	ctx, span := tracing.Start(ctx, "AdRepository.SetDeletedAt")
+	defer tracing.End(span, &err)
+	return r.next.SetDeletedAt(ctx, adID, deletedAt)
+}
+
+func (r *AdRepo) DeleteAd(ctx context.Context, adID int64) (err error) {
+	ctx, span := tracing.Start(ctx, "AdRepository.DeleteAd")
+	defer tracing.End(span, &err)
+	return r.next.DeleteAd(ctx, adID)
+}
+
+func (r *AdRepo) GetAds(ctx context.Context) (ads []*models.Ad, err error) {
+	ctx, span := tracing.Start(ctx, "AdRepository.GetAds")
+	defer tracing.End(span, &err)
+	return r.next.GetAds(ctx)
+}
+
+func (r *AdRepo) FindAds(ctx context.Context, filter models.AdFilter) (ads []*models.Ad, err error) {
+	ctx, span := tracing.Start(ctx, "AdRepository.FindAds")
+	defer tracing.End(span, &err)
+	return r.next.FindAds(ctx, filter)
+}
+
+func (r *AdRepo) CountFacets(ctx context.Context, filter models.AdFilter) (facets models.AdFacets, err error) {
+	ctx, span := tracing.Start(ctx, "AdRepository.CountFacets")
+	defer tracing.End(span, &err)
+	return r.next.CountFacets(ctx, filter)
+}
+
+func (r *AdRepo) CountAds(ctx context.Context) (total int, published int, err error) {
+	ctx, span := tracing.Start(ctx, "AdRepository.CountAds")
+	defer tracing.End(span, &err)
+	return r.next.CountAds(ctx)
+}
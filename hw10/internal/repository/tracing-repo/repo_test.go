@@ -0,0 +1,75 @@
+package tracingrepo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	localrepo "homework10/internal/repository/local-repo"
+)
+
+type recordedSpan struct {
+	name   string
+	status codes.Code
+}
+
+func useSpanRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() {
+		otel.SetTracerProvider(trace.NewNoopTracerProvider())
+	})
+	return recorder
+}
+
+func recordedSpans(recorder *tracetest.SpanRecorder) []recordedSpan {
+	spans := make([]recordedSpan, 0)
+	for _, span := range recorder.Ended() {
+		spans = append(spans, recordedSpan{name: span.Name(), status: span.Status().Code})
+	}
+	return spans
+}
+
+func TestAdRepo(t *testing.T) {
+	recorder := useSpanRecorder(t)
+	ctx := context.Background()
+	repo := NewAdRepo(localrepo.NewAdRepo())
+
+	adID, err := repo.AddAd(ctx, models.Ad{Title: "hello", Text: "world"})
+	assert.NoError(t, err)
+	ad, err := repo.GetAd(ctx, adID)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", ad.Title)
+	_, err = repo.GetAd(ctx, adID+100)
+	assert.Equal(t, domain.ErrAdNotExist, err, "errors are passed as is")
+
+	assert.Equal(t, []recordedSpan{
+		{name: "AdRepository.AddAd", status: codes.Unset},
+		{name: "AdRepository.GetAd", status: codes.Unset},
+		{name: "AdRepository.GetAd", status: codes.Error},
+	}, recordedSpans(recorder))
+}
+
+func TestUserRepo(t *testing.T) {
+	recorder := useSpanRecorder(t)
+	ctx := context.Background()
+	repo := NewUserRepo(localrepo.NewUserRepo())
+
+	_, err := repo.AddUser(ctx, models.User{NickName: "ivan", Email: "ivan@gmail.com"})
+	assert.NoError(t, err)
+	count, err := repo.CountUsers(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	assert.Equal(t, []recordedSpan{
+		{name: "UserRepository.AddUser", status: codes.Unset},
+		{name: "UserRepository.CountUsers", status: codes.Unset},
+	}, recordedSpans(recorder))
+}
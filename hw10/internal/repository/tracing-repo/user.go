@@ -0,0 +1,79 @@
+package tracingrepo
+
+import (
+	"context"
+	"time"
+
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	"homework10/internal/tracing"
+)
+
+// UserRepo записывает операции хранилища пользователей next спанами трассировки
+type UserRepo struct {
+	next domain.UserRepository
+}
+
+func NewUserRepo(next domain.UserRepository) *UserRepo {
+	return &UserRepo{next: next}
+}
+
+func (r *UserRepo) GetUser(ctx context.Context, id int64) (user *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.GetUser")
+	defer tracing.End(span, &err)
+	return r.next.GetUser(ctx, id)
+}
+
+func (r *UserRepo) GetUserByEmail(ctx context.Context, email string) (user *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.GetUserByEmail")
+	defer tracing.End(span, &err)
+	return r.next.GetUserByEmail(ctx, email)
+}
+
+func (r *UserRepo) AddUser(ctx context.Context, user models.User) (userID int64, err error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.AddUser")
+	defer tracing.End(span, &err)
+	return r.next.AddUser(ctx, user)
+}
+
+func (r *UserRepo) Update(ctx context.Context, userID int64, nickName string, email string) (user *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.Update")
+	defer tracing.End(span, &err)
+	return r.next.Update(ctx, userID, nickName, email)
+}
+
+func (r *UserRepo) SetPasswordHash(ctx context.Context, userID int64, passwordHash []byte) (err error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.SetPasswordHash")
+	defer tracing.End(span, &err)
+	return r.next.SetPasswordHash(ctx, userID, passwordHash)
+}
+
+func (r *UserRepo) SetRole(ctx context.Context, userID int64, role models.Role) (user *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.SetRole")
+	defer tracing.End(span, &err)
+	return r.next.SetRole(ctx, userID, role)
+}
+
+func (r *UserRepo) SetDeletedAt(ctx context.Context, userID int64, deletedAt time.Time) (user *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.SetDeletedAt")
+	defer tracing.End(span, &err)
+	return r.next.SetDeletedAt(ctx, userID, deletedAt)
+}
+
+func (r *UserRepo) GetDeletedUsers(ctx context.Context, before time.Time) (users []*models.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.GetDeletedUsers")
+	defer tracing.End(span, &err)
+	return r.next.GetDeletedUsers(ctx, before)
+}
+
+func (r *UserRepo) CountUsers(ctx context.Context) (count int, err error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.CountUsers")
+	defer tracing.End(span, &err)
+	return r.next.CountUsers(ctx)
+}
+
+func (r *UserRepo) Delete(ctx context.Context, userID int64) (err error) {
+	ctx, span := tracing.Start(ctx, "UserRepository.Delete")
+	defer tracing.End(span, &err)
+	return r.next.Delete(ctx, userID)
+}
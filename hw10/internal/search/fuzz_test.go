@@ -0,0 +1,33 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func FuzzSnippet(f *testing.F) {
+	testcases := []struct {
+		text  string
+		query string
+	}{
+		{"Two cats, one dog.", "cat"},
+		{"Продам котёнка недорого", "котята"},
+		{"", ""},
+	}
+
+	for _, tc := range testcases {
+		f.Add(tc.text, tc.query)
+	}
+
+	f.Fuzz(func(t *testing.T, text string, query string) {
+		snippet := Snippet("", text, query)
+
+		snippet = strings.ReplaceAll(snippet, HighlightStart, "")
+		snippet = strings.ReplaceAll(snippet, HighlightEnd, "")
+		snippet = strings.Trim(html.UnescapeString(snippet), Ellipsis)
+		assert.Contains(t, text, snippet)
+	})
+}
@@ -0,0 +1,164 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// Параметры ранжирования BM25; совпадения в заголовке весят больше, чем в тексте
+const (
+	k1          = 1.2
+	b           = 0.75
+	titleWeight = 2
+)
+
+// Hit найденный документ и его релевантность запросу
+type Hit struct {
+	ID    int64
+	Score float64
+}
+
+// frequency число вхождений терма в заголовок и в текст документа
+type frequency struct {
+	title int
+	text  int
+}
+
+func (f frequency) weighted() float64 {
+	return float64(titleWeight*f.title + f.text)
+}
+
+type document struct {
+	terms  []string
+	length float64
+}
+
+// Index инвертированный индекс по заголовку и тексту объявлений
+type Index struct {
+	postings    map[string]map[int64]frequency
+	docs        map[int64]document
+	totalLength float64
+	mutex       sync.RWMutex
+}
+
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[int64]frequency),
+		docs:     make(map[int64]document),
+	}
+}
+
+// Add индексирует документ; ранее проиндексированная версия документа заменяется
+func (idx *Index) Add(id int64, title string, text string) {
+	freqs := make(map[string]frequency)
+	titleTerms := Tokenize(title)
+	for _, term := range titleTerms {
+		f := freqs[term]
+		f.title++
+		freqs[term] = f
+	}
+	textTerms := Tokenize(text)
+	for _, term := range textTerms {
+		f := freqs[term]
+		f.text++
+		freqs[term] = f
+	}
+
+	doc := document{
+		terms:  make([]string, 0, len(freqs)),
+		length: float64(titleWeight*len(titleTerms) + len(textTerms)),
+	}
+	for term := range freqs {
+		doc.terms = append(doc.terms, term)
+	}
+
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+	idx.remove(id)
+	for term, f := range freqs {
+		docs, ok := idx.postings[term]
+		if !ok {
+			docs = make(map[int64]frequency)
+			idx.postings[term] = docs
+		}
+		docs[id] = f
+	}
+	idx.docs[id] = doc
+	idx.totalLength += doc.length
+}
+
+func (idx *Index) Remove(id int64) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+	idx.remove(id)
+}
+
+func (idx *Index) remove(id int64) {
+	doc, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+	for _, term := range doc.terms {
+		delete(idx.postings[term], id)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	delete(idx.docs, id)
+	idx.totalLength -= doc.length
+}
+
+// Search возвращает документы, содержащие хотя бы один терм запроса, по убыванию релевантности
+func (idx *Index) Search(query string) []Hit {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+	if len(idx.docs) == 0 {
+		return nil
+	}
+
+	n := float64(len(idx.docs))
+	avgLength := idx.totalLength / n
+	scores := make(map[int64]float64)
+	for _, term := range uniqueTerms(query) {
+		docs := idx.postings[term]
+		if len(docs) == 0 {
+			continue
+		}
+		df := float64(len(docs))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for id, f := range docs {
+			tf := f.weighted()
+			norm := 1 - b
+			if avgLength > 0 {
+				norm += b * idx.docs[id].length / avgLength
+			}
+			scores[id] += idf * tf * (k1 + 1) / (tf + k1*norm)
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	return hits
+}
+
+func uniqueTerms(query string) []string {
+	seen := make(map[string]struct{})
+	terms := make([]string, 0)
+	for _, term := range Tokenize(query) {
+		if _, ok := seen[term]; ok {
+			continue
+		}
+		seen[term] = struct{}{}
+		terms = append(terms, term)
+	}
+	return terms
+}
@@ -0,0 +1,104 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{
+			name:     "english words are lowercased and stemmed",
+			text:     "Running CATS, running!",
+			expected: []string{"run", "cat", "run"},
+		},
+		{
+			name:     "russian words are stemmed, ё is replaced",
+			text:     "Продаю котов и ёлки",
+			expected: []string{"прода", "кот", "и", "елк"},
+		},
+		{
+			name:     "numbers are kept",
+			text:     "iphone 13",
+			expected: []string{"iphon", "13"},
+		},
+		{
+			name:     "empty text",
+			text:     " ,. ",
+			expected: []string{},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Tokenize(tc.text))
+		})
+	}
+}
+
+func hitIDs(hits []Hit) []int64 {
+	ids := make([]int64, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+	return ids
+}
+
+func TestIndex_Search(t *testing.T) {
+	idx := NewIndex()
+	idx.Add(0, "old bike", "a bike for kids")
+	idx.Add(1, "bike", "")
+	idx.Add(2, "car", "no bike here, just a car with a bike rack")
+	idx.Add(3, "sofa", "comfortable")
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []int64
+	}{
+		{
+			name:     "title matches outweigh text matches",
+			query:    "bikes",
+			expected: []int64{1, 0, 2},
+		},
+		{
+			name:     "more matched terms rank higher",
+			query:    "old bike",
+			expected: []int64{0, 1, 2},
+		},
+		{
+			name:     "unknown word",
+			query:    "table",
+			expected: []int64{},
+		},
+		{
+			name:     "empty query",
+			query:    "",
+			expected: []int64{},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, hitIDs(idx.Search(tc.query)))
+		})
+	}
+}
+
+func TestIndex_AddReplacesAndRemove(t *testing.T) {
+	idx := NewIndex()
+	idx.Add(0, "red bike", "")
+	idx.Add(0, "blue car", "")
+
+	assert.Empty(t, idx.Search("bike"))
+	assert.Equal(t, []int64{0}, hitIDs(idx.Search("car")))
+
+	idx.Remove(0)
+	idx.Remove(10)
+	assert.Empty(t, idx.Search("car"))
+	assert.Empty(t, idx.postings)
+	assert.Zero(t, idx.totalLength)
+}
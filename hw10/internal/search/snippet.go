@@ -0,0 +1,95 @@
+package search
+
+import (
+	"html"
+	"strings"
+)
+
+const (
+	HighlightStart = "<mark>"
+	HighlightEnd   = "</mark>"
+	Ellipsis       = "…"
+
+	// snippetWords число слов в фрагменте текста
+	snippetWords = 20
+)
+
+// Snippet вырезает из текста объявления фрагмент вокруг первого совпадения с запросом
+// и выделяет совпавшие слова; если в тексте совпадений нет, подсвечивается заголовок
+func Snippet(title string, text string, query string) string {
+	terms := make(map[string]struct{})
+	for _, term := range Tokenize(query) {
+		terms[term] = struct{}{}
+	}
+
+	words := splitWords(text)
+	first := -1
+	for i, w := range words {
+		if _, ok := terms[w.term]; ok {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		titleWords := splitWords(title)
+		if !hasMatch(titleWords, terms) {
+			return cut(text, words, 0, terms)
+		}
+		return highlight(title, titleWords, 0, len(titleWords), terms)
+	}
+	from := first - snippetWords/4
+	if from < 0 {
+		from = 0
+	}
+	return cut(text, words, from, terms)
+}
+
+func hasMatch(words []word, terms map[string]struct{}) bool {
+	for _, w := range words {
+		if _, ok := terms[w.term]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// cut оставляет не больше snippetWords слов начиная со слова from и отмечает многоточием обрезанные края
+func cut(text string, words []word, from int, terms map[string]struct{}) string {
+	if len(words) == 0 {
+		return ""
+	}
+	to := from + snippetWords
+	if to > len(words) {
+		to = len(words)
+	}
+	snippet := highlight(text, words, from, to, terms)
+	if from > 0 {
+		snippet = Ellipsis + snippet
+	}
+	if to < len(words) {
+		snippet += Ellipsis
+	}
+	return snippet
+}
+
+// highlight возвращает текст от слова from до слова to, обрамляя совпавшие с запросом слова.
+// Текст экранируется: фрагмент вставляется в HTML как есть, и разметка из объявления не должна сработать
+func highlight(text string, words []word, from int, to int, terms map[string]struct{}) string {
+	if from >= to {
+		return ""
+	}
+	var sb strings.Builder
+	pos := words[from].start
+	for _, w := range words[from:to] {
+		sb.WriteString(html.EscapeString(text[pos:w.start]))
+		if _, ok := terms[w.term]; ok {
+			sb.WriteString(HighlightStart)
+			sb.WriteString(html.EscapeString(text[w.start:w.end]))
+			sb.WriteString(HighlightEnd)
+		} else {
+			sb.WriteString(html.EscapeString(text[w.start:w.end]))
+		}
+		pos = w.end
+	}
+	return sb.String()
+}
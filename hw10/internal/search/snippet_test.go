@@ -0,0 +1,62 @@
+package search
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnippet(t *testing.T) {
+	long := strings.Repeat("word ", 10) + "the black cat sleeps" + strings.Repeat(" word", 30)
+
+	tests := []struct {
+		name     string
+		title    string
+		text     string
+		query    string
+		expected string
+	}{
+		{
+			name:     "match in text is highlighted",
+			title:    "Cats",
+			text:     "Two cats, one dog.",
+			query:    "cat",
+			expected: "Two <mark>cats</mark>, one dog",
+		},
+		{
+			name:     "match in title only",
+			title:    "Продам кота",
+			text:     "недорого",
+			query:    "коты",
+			expected: "Продам <mark>кота</mark>",
+		},
+		{
+			name:  "long text is cut around the first match",
+			title: "title",
+			text:  long,
+			query: "cats",
+			expected: Ellipsis + "word word word the black <mark>cat</mark> sleeps" +
+				strings.Repeat(" word", 13) + Ellipsis,
+		},
+		{
+			name:     "markup is escaped",
+			title:    "title",
+			text:     `<script>alert("cat")</script> & <b>cat</b>`,
+			query:    "cat",
+			expected: `script&gt;alert(&#34;<mark>cat</mark>&#34;)&lt;/script&gt; &amp; &lt;b&gt;<mark>cat</mark>&lt;/b`,
+		},
+		{
+			name:     "no match",
+			title:    "title",
+			text:     "some text",
+			query:    "dog",
+			expected: "some text",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Snippet(tc.title, tc.text, tc.query))
+		})
+	}
+}
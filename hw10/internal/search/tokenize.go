@@ -0,0 +1,65 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/kljensen/snowball/english"
+	"github.com/kljensen/snowball/russian"
+)
+
+// word слово исходного текста: байтовые границы и терм, под которым оно попадает в индекс
+type word struct {
+	start int
+	end   int
+	term  string
+}
+
+// Tokenize разбивает текст на термы: слова приводятся к нижнему регистру и к основе
+func Tokenize(text string) []string {
+	words := splitWords(text)
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		terms = append(terms, w.term)
+	}
+	return terms
+}
+
+func splitWords(text string) []word {
+	words := make([]word, 0)
+	start := -1
+	for i, r := range text {
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			words = append(words, word{start: start, end: i, term: stem(text[start:i])})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		words = append(words, word{start: start, end: len(text), term: stem(text[start:])})
+	}
+	return words
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// stem приводит слово к основе стеммером того языка, к алфавиту которого относится его первая буква
+func stem(w string) string {
+	w = strings.ReplaceAll(strings.ToLower(w), "ё", "е")
+	for _, r := range w {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			return russian.Stem(w, false)
+		case unicode.Is(unicode.Latin, r):
+			return english.Stem(w, false)
+		}
+	}
+	return w
+}
@@ -4,18 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"github.com/ilgizjan1/publication"
+	"homework10/internal/auth"
+	"homework10/internal/blob"
+	"homework10/internal/category"
 	"homework10/internal/domain"
 	"homework10/internal/domain/models"
-	"strconv"
-	"strings"
+	"homework10/internal/events"
+	"homework10/internal/policy"
+	"homework10/internal/search"
 	"time"
 )
 
-const (
-	dateFormat = "01-02-2006"
-)
-
 type ErrNoAccess struct {
 	Err error
 }
@@ -24,31 +23,113 @@ func (e ErrNoAccess) Error() string {
 	return fmt.Sprintf("%s", e.Err)
 }
 
+func (e ErrNoAccess) Unwrap() error {
+	return e.Err
+}
+
+func (e ErrNoAccess) Is(target error) bool {
+	return target == domain.ErrForbidden
+}
+
 var ErrNoAccessAd = errors.New("you don't have access to edit the adID")
 
+const (
+	// watchBuffer сколько событий подписчик WatchAds может не забирать, прежде чем подписка будет закрыта
+	watchBuffer = 64
+	// eventHistorySize сколько последних событий хранится для переподключившихся подписчиков
+	eventHistorySize = 256
+)
+
 type AdService struct {
-	adRepo domain.AdRepository
+	adRepo       domain.AdRepository
+	revisionRepo domain.AdRevisionRepository
+	searchIndex  *search.Index
+	events       *events.Bus
+	// TrashRetention сколько удалённое объявление можно восстановить
+	TrashRetention time.Duration
+	// Policy решает, кто может изменять объявления и проверять их
+	Policy *policy.Policy
+	// Images хранилище файлов изображений объявлений; по умолчанию файлы хранятся в памяти
+	Images         blob.Store
+	MaxImageSize   int64
+	MaxImagePixels int
+	MaxImagesPerAd int
+	ThumbnailSize  int
+	// Categories дерево категорий, в которые можно помещать объявления
+	Categories *category.Tree
+	// Favorites закладки пользователей для методов закладок UserService; из них убираются удалённые
+	// и снятые с публикации объявления
+	Favorites domain.FavoriteRepository
 }
 
-func NewAdService(adRepo domain.AdRepository) *AdService {
+func NewAdService(adRepo domain.AdRepository, revisionRepo domain.AdRevisionRepository) *AdService {
 	return &AdService{
-		adRepo: adRepo,
+		adRepo:       adRepo,
+		revisionRepo: revisionRepo,
+		searchIndex:  search.NewIndex(),
+		events:       events.NewBus(eventHistorySize),
+
+		TrashRetention: DefaultTrashRetention,
+		Policy:         policy.Default(),
+		Images:         blob.NewMemoryStore(),
+		MaxImageSize:   DefaultMaxImageSize,
+		MaxImagePixels: DefaultMaxImagePixels,
+		MaxImagesPerAd: DefaultMaxImagesPerAd,
+		ThumbnailSize:  DefaultThumbnailSize,
+		Categories:     category.Default(),
+	}
+}
+
+// BuildSearchIndex индексирует для полнотекстового поиска объявления, уже лежащие в хранилище
+func (s *AdService) BuildSearchIndex(ctx context.Context) error {
+	ads, err := s.adRepo.GetAds(ctx)
+	if err != nil {
+		return fmt.Errorf("building search index: %w", err)
 	}
+	for _, ad := range ads {
+		if !ad.IsDeleted() {
+			s.searchIndex.Add(ad.ID, ad.Title, ad.Text)
+		}
+	}
+	return nil
 }
 
+// GetAdByID возвращает объявление; объявления из корзины и неопубликованные объявления, которые пользователю
+// из контекста не видны, считаются несуществующими
 func (s *AdService) GetAdByID(ctx context.Context, adID int64) (*models.Ad, error) {
+	ad, err := s.getAd(ctx, adID)
+	if err != nil {
+		return nil, err
+	}
+	if !s.canView(ctx, ad) {
+		return nil, domain.ErrAdNotExist
+	}
+	return ad, nil
+}
+
+// getAd возвращает объявление не из корзины без проверки видимости; права проверяет вызывающий
+func (s *AdService) getAd(ctx context.Context, adID int64) (*models.Ad, error) {
 	ad, err := s.adRepo.GetAd(ctx, adID)
 	if err != nil {
 		return nil, err
 	}
+	if ad.IsDeleted() {
+		return nil, domain.ErrAdNotExist
+	}
 	return ad, nil
 }
 
-func (s *AdService) CreateAd(ctx context.Context, title string, text string, userID int64) (*models.Ad, error) {
-	ad := models.Ad{Title: title, Text: text, UserID: userID,
-		DateCreation: time.Now().UTC().Format(dateFormat), DateUpdate: time.Now().UTC().Format(dateFormat)}
+// CreateAd создаёт черновик объявления автора из контекста
+func (s *AdService) CreateAd(ctx context.Context, title string, text string, details models.AdDetails) (*models.Ad, error) {
+	if err := authorize(ctx, s.Policy, policy.CreateAd, noOwner, ErrNoAccess{Err: ErrNoAccessAd}); err != nil {
+		return nil, err
+	}
+	userID, _ := auth.UserIDFromContext(ctx)
+	now := now()
+	ad := models.Ad{Title: title, Text: text, UserID: userID, State: models.AdDraft, Version: 1, DateCreation: now,
+		DateUpdate: now, AdDetails: normalizeDetails(details)}
 
-	if err := publication.Validate(ad); err != nil {
+	if err := s.validateContent(ad); err != nil {
 		return nil, err
 	}
 
@@ -58,136 +139,244 @@ func (s *AdService) CreateAd(ctx context.Context, title string, text string, use
 	}
 
 	ad.ID = id
+	if err := s.recordRevision(ctx, models.RevisionCreated, models.Ad{}, ad, 0); err != nil {
+		return nil, err
+	}
+	s.searchIndex.Add(ad.ID, ad.Title, ad.Text)
+	s.events.Publish(models.AdCreated, nil, ad)
 
 	return &ad, nil
 }
 
-func (s *AdService) ChangeAdStatus(ctx context.Context, adID int64, userID int64, published bool) (*models.Ad, error) {
-	ad, err := s.adRepo.GetAd(ctx, adID)
+// ChangeAdStatus публикует или снимает объявление с публикации; ненулевой expectedVersion должен совпадать
+// с текущей версией объявления, иначе возвращается domain.ErrAdVersionMismatch. Опубликовать можно только
+// одобренное модератором или снятое с публикации объявление, снятое попадает в архив. Если объявление уже
+// в нужном статусе, оно возвращается без изменений
+func (s *AdService) ChangeAdStatus(ctx context.Context, adID int64, published bool, expectedVersion int64) (*models.Ad, error) {
+	ad, err := s.authorizedAd(ctx, adID, policy.PublishAd)
 	if err != nil {
 		return nil, err
 	}
-	if ad.UserID != userID {
-		return nil, ErrNoAccess{Err: ErrNoAccessAd}
+	if err := checkVersion(ad, expectedVersion); err != nil {
+		return nil, err
 	}
-	newAd, err := s.adRepo.SetStatus(ctx, adID, published)
-	if err != nil {
-		return nil, fmt.Errorf("setting adID status: %w", err)
+	if ad.Published == published {
+		return ad, nil
 	}
-	newAd.DateUpdate = time.Now().UTC().Format(dateFormat)
-	return newAd, nil
+	state := models.AdArchived
+	if published {
+		if ad.State != models.AdApproved && ad.State != models.AdArchived {
+			return nil, ErrAdNotApproved
+		}
+		state = models.AdPublished
+	}
+	return s.setState(ctx, *ad, state, "", models.AdStatusChanged)
 }
 
-func (s *AdService) UpdateAd(ctx context.Context, adID int64, userID int64, title string, text string) (*models.Ad, error) {
-	ad, err := s.adRepo.GetAd(ctx, adID)
+// UpdateAd заменяет заголовок, текст и характеристики объявления; expectedVersion проверяется так же,
+// как в ChangeAdStatus
+func (s *AdService) UpdateAd(ctx context.Context, adID int64, title string, text string, details models.AdDetails,
+	expectedVersion int64) (*models.Ad, error) {
+	ad, err := s.authorizedAd(ctx, adID, policy.UpdateAd)
 	if err != nil {
 		return nil, err
 	}
-	if ad.UserID != userID {
-		return nil, ErrNoAccess{Err: ErrNoAccessAd}
+	if err := checkVersion(ad, expectedVersion); err != nil {
+		return nil, err
+	}
+	return s.updateAd(ctx, *ad, title, text, details, models.RevisionUpdated, 0)
+}
+
+// updateAd меняет заголовок, текст и характеристики объявления и записывает ревизию указанного типа;
+// изменённое объявление снимается с публикации и должно снова пройти проверку. Объявление на проверке тоже
+// становится черновиком: иначе модератор одобрил бы содержимое, которого не видел
+func (s *AdService) updateAd(ctx context.Context, old models.Ad, title string, text string, details models.AdDetails,
+	revisionType models.AdRevisionType, restoredFrom int64) (*models.Ad, error) {
+	details = normalizeDetails(details)
+	if err := s.validateContent(models.Ad{Title: title, Text: text, AdDetails: details}); err != nil {
+		return nil, err
 	}
-	newAd, err := s.adRepo.Update(ctx, adID, title, text)
+	newAd, err := s.adRepo.Update(ctx, old.ID, title, text, details, models.AdDraft, old.Version)
 	if err != nil {
 		return nil, fmt.Errorf("updating add: %w", err)
 	}
-	newAd.DateUpdate = time.Now().UTC().Format(dateFormat)
-	if err := publication.Validate(*newAd); err != nil {
+	if err := s.recordRevision(ctx, revisionType, old, *newAd, restoredFrom); err != nil {
 		return nil, err
 	}
+	if err := s.dropUnpublishedFavorites(ctx, old, newAd.Published); err != nil {
+		return nil, err
+	}
+	s.searchIndex.Add(newAd.ID, newAd.Title, newAd.Text)
+	s.events.Publish(models.AdUpdated, &old, *newAd)
 
 	return newAd, nil
 }
 
-func (s *AdService) DeleteAd(ctx context.Context, adID int64, userID int64) error {
-	ad, err := s.adRepo.GetAd(ctx, adID)
+// DeleteAd переносит объявление в корзину, откуда автор может вернуть его в течение TrashRetention
+func (s *AdService) DeleteAd(ctx context.Context, adID int64) error {
+	ad, err := s.authorizedAd(ctx, adID, policy.DeleteAd)
 	if err != nil {
 		return err
 	}
-	if ad.UserID != userID {
-		return ErrNoAccess{Err: ErrNoAccessAd}
+	old := *ad
+	if _, err := s.adRepo.SetDeletedAt(ctx, adID, now()); err != nil {
+		return err
+	}
+	if err := s.dropUnpublishedFavorites(ctx, old, false); err != nil {
+		return err
+	}
+	s.searchIndex.Remove(adID)
+	s.events.Publish(models.AdDeleted, nil, old)
+	return nil
+}
+
+// WatchAds подписывает на изменения объявлений, подходящих под фильтр; пустой фильтр пропускает все события.
+// Подписчик получает события только видимых ему объявлений (см. canView): объявление, ставшее невидимым,
+// приходит событием AdLeft без содержимого. Подписка закрывается вместе с контекстом
+func (s *AdService) WatchAds(ctx context.Context, filter models.AdFilter) *events.Subscription {
+	sub := s.events.Subscribe(s.visibleFilter(ctx, filter), watchBuffer)
+	go func() {
+		<-ctx.Done()
+		sub.Close()
+	}()
+	return sub
+}
+
+// CloseWatchers завершает подписки WatchAds и WatchAdsSince; вызывается при остановке сервера
+func (s *AdService) CloseWatchers() {
+	s.events.Close()
+}
+
+// WatchAdsSince подписывает так же, как WatchAds, но сначала отдаёт сохранённые события с номером больше
+// lastEventID; complete = false, если часть пропущенных событий уже не хранится
+func (s *AdService) WatchAdsSince(ctx context.Context, filter models.AdFilter, lastEventID int64) (
+	sub *events.Subscription, complete bool) {
+	sub, complete = s.events.Resume(s.visibleFilter(ctx, filter), watchBuffer, lastEventID)
+	go func() {
+		<-ctx.Done()
+		sub.Close()
+	}()
+	return sub, complete
+}
+
+// now текущее время в том виде, в котором его вернёт хранилище: в UTC с точностью до микросекунд, как в PostgreSQL
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// checkVersion сверяет версию объявления с ожидаемой клиентом; нулевая ожидаемая версия не проверяется
+func checkVersion(ad *models.Ad, expectedVersion int64) error {
+	if expectedVersion != 0 && ad.Version != expectedVersion {
+		return domain.ErrAdVersionMismatch
 	}
-	return s.adRepo.DeleteAd(ctx, adID)
+	return nil
 }
 
-func (s *AdService) GetAdsByTitle(ctx context.Context, text string) ([]*models.Ad, error) {
-	adSlice, err := s.adRepo.GetAds(ctx)
+// authorizedAd возвращает объявление не из корзины, если Policy разрешает пользователю из контекста action над ним
+func (s *AdService) authorizedAd(ctx context.Context, adID int64, action policy.Action) (*models.Ad, error) {
+	if _, ok := auth.UserIDFromContext(ctx); !ok {
+		return nil, ErrNotAuthenticated
+	}
+	ad, err := s.getAd(ctx, adID)
 	if err != nil {
 		return nil, err
 	}
-	cleanSlice := make([]*models.Ad, 0)
-	for _, ad := range adSlice {
-		if strings.Contains(ad.Title, text) {
-			cleanSlice = append(cleanSlice, ad)
-		}
+	if err := authorize(ctx, s.Policy, action, ad.UserID, ErrNoAccess{Err: ErrNoAccessAd}); err != nil {
+		return nil, err
 	}
-	return cleanSlice, nil
+	return ad, nil
 }
 
-func (s *AdService) ListAds(ctx context.Context, publishedRaw string, userIDRaw string, dateCreationRaw string) ([]*models.Ad, error) {
-	adSlice, err := s.adRepo.GetAds(ctx)
-	if err != nil {
-		return nil, err
+// canView сообщает, что пользователь из контекста видит объявление: опубликованное видят все, остальные -
+// те, кому Policy разрешает policy.ViewUnpublished (автор, модераторы и администраторы)
+func (s *AdService) canView(ctx context.Context, ad *models.Ad) bool {
+	if ad.Published {
+		return true
 	}
-	funSlice := make([]funCheckAd, 0)
-	if publishedRaw != "" {
-		published, err := strconv.ParseBool(publishedRaw)
-		if err != nil {
-			return nil, err
-		}
-		funSlice = append(funSlice, checkPublished(published))
-	} else if userIDRaw == "" && dateCreationRaw == "" {
-		funSlice = append(funSlice, checkPublished(true))
+	userID, ok := auth.UserIDFromContext(ctx)
+	return ok && s.Policy.Can(auth.RoleFromContext(ctx), policy.ViewUnpublished, userID == ad.UserID)
+}
+
+// visibleFilter ограничивает фильтр объявлениями, которые видит пользователь из контекста, по тому же правилу,
+// что и canView
+func (s *AdService) visibleFilter(ctx context.Context, filter models.AdFilter) models.AdFilter {
+	userID, ok := auth.UserIDFromContext(ctx)
+	switch {
+	case !ok:
+		anonymous := noOwner
+		filter.VisibleTo = &anonymous
+	case !s.Policy.Can(auth.RoleFromContext(ctx), policy.ViewUnpublished, false):
+		filter.VisibleTo = &userID
 	}
+	return filter
+}
 
-	if userIDRaw != "" {
-		userID, err := strconv.Atoi(userIDRaw)
-		if err != nil {
-			return nil, fmt.Errorf("userID validating error")
+// SearchAds ищет среди опубликованных объявлений по словам из заголовка и текста; по умолчанию выдача
+// упорядочена по релевантности
+func (s *AdService) SearchAds(ctx context.Context, query string, page models.PageParams) (*models.SearchPage, error) {
+	hits := s.searchIndex.Search(query)
+	results := make([]*models.SearchResult, 0, len(hits))
+	if len(hits) != 0 {
+		ids := make([]int64, 0, len(hits))
+		scores := make(map[int64]float64, len(hits))
+		for _, hit := range hits {
+			ids = append(ids, hit.ID)
+			scores[hit.ID] = hit.Score
 		}
-		funSlice = append(funSlice, checkUserID(int64(userID)))
-	}
-	if dateCreationRaw != "" {
-		date, err := time.Parse(dateFormat, dateCreationRaw)
+		published := true
+		ads, err := s.adRepo.FindAds(ctx, models.AdFilter{IDs: ids, Published: &published})
 		if err != nil {
-			return nil, fmt.Errorf("dateCreation validating error")
+			return nil, err
 		}
-		funSlice = append(funSlice, checkDate(date))
-	}
-	cleanSlice := make([]*models.Ad, 0)
-	for _, ad := range adSlice {
-		if !check(*ad, funSlice) {
-			continue
+		for _, ad := range ads {
+			results = append(results, &models.SearchResult{Ad: ad, Score: scores[ad.ID]})
 		}
-		cleanSlice = append(cleanSlice, ad)
 	}
-	return cleanSlice, nil
-}
-
-type funCheckAd func(ad models.Ad) bool
 
-func checkPublished(published bool) funCheckAd {
-	return func(ad models.Ad) bool {
-		return ad.Published == published
+	searchPage, err := paginateSearch(results, page)
+	if err != nil {
+		return nil, err
 	}
-}
-
-func checkUserID(userID int64) funCheckAd {
-	return func(ad models.Ad) bool {
-		return ad.UserID == userID
+	for _, result := range searchPage.Results {
+		result.Snippet = search.Snippet(result.Ad.Title, result.Ad.Text, query)
 	}
+	return searchPage, nil
 }
 
-func checkDate(date time.Time) funCheckAd {
-	return func(ad models.Ad) bool {
-		return date.Format(dateFormat) == ad.DateCreation
+// ListAds возвращает объявления по фильтру вместе с распределением всей выборки по категориям и городам.
+// Без фильтров, как и с одними фильтрами каталога (цена, категория, город), выдаются только опубликованные
+// объявления. Неопубликованные объявления выдаются только тем, кому они видны (см. canView). Категория фильтра
+// выбирает объявления и из всех её подкатегорий
+func (s *AdService) ListAds(ctx context.Context, filter models.AdFilter, page models.PageParams) (*models.AdsPage, error) {
+	if withoutDetails(filter).IsEmpty() {
+		published := true
+		filter.Published = &published
+	}
+	filter = s.visibleFilter(ctx, filter)
+	filter, err := s.expandCategories(filter)
+	if err != nil {
+		return nil, err
 	}
+	pageFilter, page, err := pageFilter(filter, page)
+	if err != nil {
+		return nil, err
+	}
+	adSlice, err := s.adRepo.FindAds(ctx, pageFilter)
+	if err != nil {
+		return nil, err
+	}
+	facets, err := s.adRepo.CountFacets(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	adPage := adsPage(adSlice, page)
+	adPage.Facets = s.adFacets(facets)
+	return adPage, nil
 }
 
-func check(ad models.Ad, functions []funCheckAd) bool {
-	for _, fun := range functions {
-		if !fun(ad) {
-			return false
-		}
-	}
-	return true
+// withoutDetails фильтр без условий на цену, категорию и город
+func withoutDetails(filter models.AdFilter) models.AdFilter {
+	filter.PriceMin, filter.PriceMax, filter.Currency = nil, nil, ""
+	filter.CategoryIDs, filter.City = nil, ""
+	return filter
 }
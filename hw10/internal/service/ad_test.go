@@ -5,13 +5,23 @@ import (
 	"fmt"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"homework10/internal/auth"
+	"homework10/internal/domain"
 	"homework10/internal/domain/models"
 	repoMock "homework10/internal/service/mock"
-	"reflect"
 	"testing"
 	"time"
 )
 
+var testDate = time.Date(2023, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+// anyRevisions возвращает хранилище ревизий, принимающее любые записи, для тестов, которые их не проверяют
+func anyRevisions(ctrl *gomock.Controller) *repoMock.MockAdRevisionRepository {
+	revisionRepo := repoMock.NewMockAdRevisionRepository(ctrl)
+	revisionRepo.EXPECT().AddRevision(gomock.Any(), gomock.Any()).Return(int64(1), nil).AnyTimes()
+	return revisionRepo
+}
+
 func TestErrNoAccess_Error(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -39,137 +49,12 @@ func TestErrNoAccess_Error(t *testing.T) {
 	}
 }
 
-func Test_checkPublished(t *testing.T) {
-	tests := []struct {
-		name      string
-		published bool
-		expected  funCheckAd
-	}{
-		{
-			name:      "test for published = true",
-			published: true,
-			expected: func(ad models.Ad) bool {
-				return ad.Published == true
-			},
-		},
-		{
-			name:      "test for published = false",
-			published: false,
-			expected: func(ad models.Ad) bool {
-				return ad.Published == false
-			},
-		},
-	}
-	ad := models.Ad{Published: true}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := checkPublished(tt.published); !reflect.DeepEqual(got(ad), tt.expected(ad)) {
-				t.Errorf("checkPublished() = %v, inUser %v", got(ad), tt.expected(ad))
-			}
-		})
-	}
-}
-
-func Test_checkUserID(t *testing.T) {
-	tests := []struct {
-		name     string
-		userID   int64
-		expected funCheckAd
-	}{
-		{
-			name:   "test for userID = 0",
-			userID: 0,
-			expected: func(ad models.Ad) bool {
-				return ad.UserID == 0
-			},
-		},
-		{
-			name:   "test for userID = 100",
-			userID: 100,
-			expected: func(ad models.Ad) bool {
-				return ad.UserID == 100
-			},
-		},
-	}
-	ad := models.Ad{UserID: 0}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := checkUserID(tt.userID); !reflect.DeepEqual(got(ad), tt.expected(ad)) {
-				t.Errorf("checkUserID() = %v, inUser %v", got(ad), tt.expected(ad))
-			}
-		})
-	}
-}
-
-func Test_checkDate(t *testing.T) {
-	now := time.Now()
-	testTime, _ := time.Parse("2/1/2006", "31/7/2015")
-	tests := []struct {
-		name     string
-		Date     time.Time
-		expected funCheckAd
-	}{
-		{
-			name: "test for true date",
-			Date: now,
-			expected: func(ad models.Ad) bool {
-				return ad.DateCreation == now.Format(dateFormat)
-			},
-		},
-		{
-			name: "test for false date",
-			Date: testTime,
-			expected: func(ad models.Ad) bool {
-				return ad.DateCreation == testTime.Format(dateFormat)
-			},
-		},
-	}
-	ad := models.Ad{UserID: 0}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := checkDate(tt.Date); !reflect.DeepEqual(got(ad), tt.expected(ad)) {
-				t.Errorf("checkDate() = %v, inUser %v", got(ad), tt.expected(ad))
-			}
-		})
-	}
-}
-
-func Test_check(t *testing.T) {
-	fun1 := checkPublished(true)
-	tests := []struct {
-		name      string
-		ad        models.Ad
-		functions []funCheckAd
-		expected  bool
-	}{
-		{
-			name:      "test for true",
-			ad:        models.Ad{Published: true},
-			functions: []funCheckAd{fun1},
-			expected:  true,
-		},
-		{
-			name:      "test for false",
-			ad:        models.Ad{Published: false},
-			functions: []funCheckAd{fun1},
-			expected:  false,
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if got := check(tt.ad, tt.functions); got != tt.expected {
-				t.Errorf("check() = %v, inUser %v", got, tt.expected)
-			}
-		})
-	}
-}
-
 func TestCreateAd(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	adRepo := repoMock.NewMockAdRepository(ctrl)
-	adService := NewAdService(adRepo)
+	adService := NewAdService(adRepo, anyRevisions(ctrl))
 	testTable := []struct {
 		name    string
 		inAd    models.Ad
@@ -179,12 +64,12 @@ func TestCreateAd(t *testing.T) {
 		{
 			name: "true createAd",
 			inAd: models.Ad{
-				ID:           0,
-				Title:        "test Title",
-				Text:         "test Text",
-				UserID:       111,
-				DateCreation: time.Now().UTC().Format(dateFormat),
-				DateUpdate:   time.Now().UTC().Format(dateFormat),
+				ID:      0,
+				Title:   "test Title",
+				Text:    "test Text",
+				UserID:  111,
+				State:   models.AdDraft,
+				Version: 1,
 			},
 			repoErr: nil,
 			wantErr: false,
@@ -192,12 +77,12 @@ func TestCreateAd(t *testing.T) {
 		{
 			name: "validate error",
 			inAd: models.Ad{
-				ID:           0,
-				Title:        "test Title",
-				Text:         "test Text",
-				UserID:       111,
-				DateCreation: time.Now().UTC().Format(dateFormat),
-				DateUpdate:   time.Now().UTC().Format(dateFormat),
+				ID:      0,
+				Title:   "test Title",
+				Text:    "test Text",
+				UserID:  111,
+				State:   models.AdDraft,
+				Version: 1,
 			},
 			repoErr: fmt.Errorf("error from the data repository"),
 			wantErr: true,
@@ -206,16 +91,24 @@ func TestCreateAd(t *testing.T) {
 
 	for _, testCase := range testTable {
 		t.Run(testCase.name, func(t *testing.T) {
-			ctx := context.Background()
-			adRepo.EXPECT().AddAd(ctx, testCase.inAd).Return(testCase.inAd.ID, testCase.repoErr).Times(1)
+			ctx := auth.WithUserID(context.Background(), testCase.inAd.UserID)
+			adRepo.EXPECT().AddAd(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, ad models.Ad) (int64, error) {
+				assert.False(t, ad.DateCreation.IsZero())
+				assert.Equal(t, ad.DateCreation, ad.DateUpdate)
+				ad.DateCreation, ad.DateUpdate = time.Time{}, time.Time{}
+				assert.Equal(t, testCase.inAd, ad)
+				return testCase.inAd.ID, testCase.repoErr
+			}).Times(1)
 
-			ad, err := adService.CreateAd(ctx, testCase.inAd.Title, testCase.inAd.Text, testCase.inAd.UserID)
+			ad, err := adService.CreateAd(ctx, testCase.inAd.Title, testCase.inAd.Text, models.AdDetails{})
 			if testCase.wantErr {
 				assert.Error(t, err)
 				assert.Nil(t, ad)
 				return
 			}
 			assert.NoError(t, err)
+			assert.Equal(t, time.UTC, ad.DateCreation.Location())
+			ad.DateCreation, ad.DateUpdate = time.Time{}, time.Time{}
 			assert.Equal(t, testCase.inAd, *ad)
 		})
 	}
@@ -226,7 +119,7 @@ func TestCreateAd_Validate(t *testing.T) {
 	defer ctrl.Finish()
 
 	adRepo := repoMock.NewMockAdRepository(ctrl)
-	adService := NewAdService(adRepo)
+	adService := NewAdService(adRepo, anyRevisions(ctrl))
 
 	testTable := []struct {
 		name string
@@ -253,8 +146,8 @@ func TestCreateAd_Validate(t *testing.T) {
 	}
 	for _, testCase := range testTable {
 		t.Run(testCase.name, func(t *testing.T) {
-			ctx := context.Background()
-			ad, err := adService.CreateAd(ctx, testCase.inAd.Title, testCase.inAd.Text, testCase.inAd.UserID)
+			ctx := auth.WithUserID(context.Background(), testCase.inAd.UserID)
+			ad, err := adService.CreateAd(ctx, testCase.inAd.Title, testCase.inAd.Text, models.AdDetails{})
 
 			assert.Error(t, err)
 			assert.Nil(t, ad)
@@ -262,51 +155,109 @@ func TestCreateAd_Validate(t *testing.T) {
 	}
 }
 
+func TestAdMutations_NotAuthenticated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	adRepo := repoMock.NewMockAdRepository(ctrl)
+	adService := NewAdService(adRepo, anyRevisions(ctrl))
+	ctx := context.Background()
+
+	ad, err := adService.CreateAd(ctx, "test Title", "test Text", models.AdDetails{})
+	assert.ErrorIs(t, err, ErrNotAuthenticated)
+	assert.Nil(t, ad)
+
+	ad, err = adService.ChangeAdStatus(ctx, 0, true, 0)
+	assert.ErrorIs(t, err, ErrNotAuthenticated)
+	assert.Nil(t, ad)
+
+	ad, err = adService.UpdateAd(ctx, 0, "test Title", "test Text", models.AdDetails{}, 0)
+	assert.ErrorIs(t, err, ErrNotAuthenticated)
+	assert.Nil(t, ad)
+
+	assert.ErrorIs(t, adService.DeleteAd(ctx, 0), ErrNotAuthenticated)
+}
+
 func TestGetAd(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	adRepo := repoMock.NewMockAdRepository(ctrl)
-	adService := NewAdService(adRepo)
+	adService := NewAdService(adRepo, anyRevisions(ctrl))
+	draft := &models.Ad{
+		ID:           100,
+		Title:        "test Title",
+		Text:         "test Text",
+		UserID:       111,
+		DateCreation: testDate,
+		DateUpdate:   testDate,
+	}
+	published := &models.Ad{ID: 100, Title: "test Title", UserID: 111, Published: true}
+	repoErr := fmt.Errorf("error from the data repository")
 	testTable := []struct {
 		name    string
 		adID    int64
+		userID  int64
+		role    models.Role
 		outAd   *models.Ad
 		repoErr error
-		wantErr bool
+		wantErr error
 	}{
 		{
-			name: "true createAd",
-			adID: 100,
-			outAd: &models.Ad{
-				ID:           100,
-				Title:        "test Title",
-				Text:         "test Text",
-				UserID:       111,
-				DateCreation: time.Now().UTC().Format(dateFormat),
-				DateUpdate:   time.Now().UTC().Format(dateFormat),
-			},
-			repoErr: nil,
-			wantErr: false,
+			name:   "owner reads draft",
+			adID:   100,
+			userID: 111,
+			outAd:  draft,
+		},
+		{
+			name:    "anonymous reads draft",
+			adID:    100,
+			outAd:   draft,
+			wantErr: domain.ErrAdNotExist,
+		},
+		{
+			name:    "other user reads draft",
+			adID:    100,
+			userID:  112,
+			role:    models.RoleUser,
+			outAd:   draft,
+			wantErr: domain.ErrAdNotExist,
+		},
+		{
+			name:   "moderator reads draft",
+			adID:   100,
+			userID: 112,
+			role:   models.RoleModerator,
+			outAd:  draft,
+		},
+		{
+			name:  "anonymous reads published",
+			adID:  100,
+			outAd: published,
 		},
 		{
 			name:    "validate error",
 			adID:    100,
+			userID:  111,
 			outAd:   nil,
-			repoErr: fmt.Errorf("error from the data repository"),
-			wantErr: true,
+			repoErr: repoErr,
+			wantErr: repoErr,
 		},
 	}
 
 	for _, testCase := range testTable {
 		t.Run(testCase.name, func(t *testing.T) {
 			ctx := context.Background()
+			if testCase.userID != 0 {
+				ctx = auth.WithRole(auth.WithUserID(ctx, testCase.userID), testCase.role)
+			}
 			adRepo.EXPECT().GetAd(ctx, testCase.adID).Return(testCase.outAd, testCase.repoErr).Times(1)
 
 			ad, err := adService.GetAdByID(ctx, testCase.adID)
-			if testCase.wantErr {
+			if testCase.wantErr != nil {
 				assert.Error(t, err)
 				assert.Nil(t, ad)
+				assert.ErrorIs(t, err, testCase.wantErr)
 				return
 			}
 			assert.NoError(t, err)
@@ -320,7 +271,7 @@ func TestChangeAdStatus_After(t *testing.T) {
 	defer ctrl.Finish()
 
 	adRepo := repoMock.NewMockAdRepository(ctrl)
-	adService := NewAdService(adRepo)
+	adService := NewAdService(adRepo, anyRevisions(ctrl))
 	testTable := []struct {
 		name         string
 		inAd         models.Ad
@@ -337,13 +288,23 @@ func TestChangeAdStatus_After(t *testing.T) {
 				UserID:    47,
 			},
 			outGetAd: &models.Ad{
+				ID:           100,
+				Title:        "test Title",
+				Text:         "test Text",
+				UserID:       47,
+				State:        models.AdApproved,
+				DateCreation: testDate,
+				DateUpdate:   testDate,
+			},
+			outSetStatus: &models.Ad{
 				ID:           100,
 				Title:        "test Title",
 				Text:         "test Text",
 				UserID:       47,
 				Published:    true,
-				DateCreation: time.Now().UTC().Format(dateFormat),
-				DateUpdate:   time.Now().UTC().Format(dateFormat),
+				State:        models.AdPublished,
+				DateCreation: testDate,
+				DateUpdate:   testDate,
 			},
 			repoErr: nil,
 			wantErr: false,
@@ -360,30 +321,29 @@ func TestChangeAdStatus_After(t *testing.T) {
 				Title:        "test Title",
 				Text:         "test Text",
 				UserID:       47,
-				Published:    true,
-				DateCreation: time.Now().UTC().Format(dateFormat),
-				DateUpdate:   time.Now().UTC().Format(dateFormat),
+				State:        models.AdApproved,
+				DateCreation: testDate,
+				DateUpdate:   testDate,
 			},
-			outSetStatus: nil,
-			repoErr:      fmt.Errorf("error from the data repository: SetStatus()"),
-			wantErr:      true,
+			repoErr: fmt.Errorf("error from the data repository: SetState()"),
+			wantErr: true,
 		},
 	}
 	for _, testCase := range testTable {
 		t.Run(testCase.name, func(t *testing.T) {
-			ctx := context.Background()
+			ctx := auth.WithUserID(context.Background(), testCase.inAd.UserID)
 			adRepo.EXPECT().GetAd(ctx, testCase.inAd.ID).Return(testCase.outGetAd, nil).Times(1)
-			adRepo.EXPECT().SetStatus(ctx, testCase.inAd.ID, testCase.inAd.Published).
-				Return(testCase.outGetAd, testCase.repoErr).Times(1)
+			adRepo.EXPECT().SetState(ctx, testCase.inAd.ID, models.AdPublished, "", int64(0)).
+				Return(testCase.outSetStatus, testCase.repoErr).Times(1)
 
-			ad, err := adService.ChangeAdStatus(ctx, testCase.inAd.ID, testCase.inAd.UserID, testCase.inAd.Published)
+			ad, err := adService.ChangeAdStatus(ctx, testCase.inAd.ID, testCase.inAd.Published, 0)
 			if testCase.wantErr {
 				assert.Error(t, err)
 				assert.Nil(t, ad)
 				return
 			}
 			assert.NoError(t, err)
-			assert.Equal(t, *testCase.outGetAd, *ad)
+			assert.Equal(t, *testCase.outSetStatus, *ad)
 		})
 	}
 }
@@ -393,7 +353,7 @@ func TestChangeAdStatus_Before(t *testing.T) {
 	defer ctrl.Finish()
 
 	adRepo := repoMock.NewMockAdRepository(ctrl)
-	adService := NewAdService(adRepo)
+	adService := NewAdService(adRepo, anyRevisions(ctrl))
 	testTable := []struct {
 		name     string
 		inAd     models.Ad
@@ -413,8 +373,8 @@ func TestChangeAdStatus_Before(t *testing.T) {
 				Text:         "test Text",
 				UserID:       48,
 				Published:    true,
-				DateCreation: time.Now().UTC().Format(dateFormat),
-				DateUpdate:   time.Now().UTC().Format(dateFormat),
+				DateCreation: testDate,
+				DateUpdate:   testDate,
 			},
 			repoErr: nil,
 		},
@@ -431,18 +391,18 @@ func TestChangeAdStatus_Before(t *testing.T) {
 				Text:         "test Text",
 				UserID:       47,
 				Published:    true,
-				DateCreation: time.Now().UTC().Format(dateFormat),
-				DateUpdate:   time.Now().UTC().Format(dateFormat),
+				DateCreation: testDate,
+				DateUpdate:   testDate,
 			},
 			repoErr: fmt.Errorf("error from the data repository: SetStatus()"),
 		},
 	}
 	for _, testCase := range testTable {
 		t.Run(testCase.name, func(t *testing.T) {
-			ctx := context.Background()
+			ctx := auth.WithUserID(context.Background(), testCase.inAd.UserID)
 			adRepo.EXPECT().GetAd(ctx, testCase.inAd.ID).Return(testCase.outGetAd, testCase.repoErr).Times(1)
 
-			ad, err := adService.ChangeAdStatus(ctx, testCase.inAd.ID, testCase.inAd.UserID, testCase.inAd.Published)
+			ad, err := adService.ChangeAdStatus(ctx, testCase.inAd.ID, testCase.inAd.Published, 0)
 			assert.Error(t, err)
 			assert.Nil(t, ad)
 		})
@@ -454,7 +414,7 @@ func TestUpdateAd_Before(t *testing.T) {
 	defer ctrl.Finish()
 
 	adRepo := repoMock.NewMockAdRepository(ctrl)
-	adService := NewAdService(adRepo)
+	adService := NewAdService(adRepo, anyRevisions(ctrl))
 	testTable := []struct {
 		name     string
 		inAd     models.Ad
@@ -474,8 +434,8 @@ func TestUpdateAd_Before(t *testing.T) {
 				Text:         "test Text",
 				UserID:       48,
 				Published:    true,
-				DateCreation: time.Now().UTC().Format(dateFormat),
-				DateUpdate:   time.Now().UTC().Format(dateFormat),
+				DateCreation: testDate,
+				DateUpdate:   testDate,
 			},
 			errGetAd: nil,
 		},
@@ -493,10 +453,10 @@ func TestUpdateAd_Before(t *testing.T) {
 	}
 	for _, testCase := range testTable {
 		t.Run(testCase.name, func(t *testing.T) {
-			ctx := context.Background()
+			ctx := auth.WithUserID(context.Background(), testCase.inAd.UserID)
 			adRepo.EXPECT().GetAd(ctx, testCase.inAd.ID).Return(testCase.outGetAd, testCase.errGetAd).Times(1)
 
-			ad, err := adService.UpdateAd(ctx, testCase.inAd.ID, testCase.inAd.UserID, testCase.inAd.Title, testCase.inAd.Text)
+			ad, err := adService.UpdateAd(ctx, testCase.inAd.ID, testCase.inAd.Title, testCase.inAd.Text, models.AdDetails{}, 0)
 			assert.Error(t, err)
 			assert.Nil(t, ad)
 		})
@@ -508,12 +468,13 @@ func TestUpdateAd_After(t *testing.T) {
 	defer ctrl.Finish()
 
 	adRepo := repoMock.NewMockAdRepository(ctrl)
-	adService := NewAdService(adRepo)
+	adService := NewAdService(adRepo, anyRevisions(ctrl))
 	testTable := []struct {
 		name          string
 		inAd          models.Ad
 		outAd         *models.Ad
 		errRepoUpdate error
+		updateTimes   int
 		wantErr       bool
 	}{
 		{
@@ -529,10 +490,11 @@ func TestUpdateAd_After(t *testing.T) {
 				Text:         "test Text",
 				UserID:       47,
 				Published:    true,
-				DateCreation: time.Now().UTC().Format(dateFormat),
-				DateUpdate:   time.Now().UTC().Format(dateFormat),
+				DateCreation: testDate,
+				DateUpdate:   testDate,
 			},
 			errRepoUpdate: fmt.Errorf("error from repository Update()"),
+			updateTimes:   1,
 			wantErr:       true,
 		},
 		{
@@ -549,10 +511,11 @@ func TestUpdateAd_After(t *testing.T) {
 				Text:         "test Text",
 				UserID:       47,
 				Published:    true,
-				DateCreation: time.Now().UTC().Format(dateFormat),
-				DateUpdate:   time.Now().UTC().Format(dateFormat),
+				DateCreation: testDate,
+				DateUpdate:   testDate,
 			},
 			errRepoUpdate: nil,
+			updateTimes:   0,
 			wantErr:       true,
 		},
 		{
@@ -569,22 +532,23 @@ func TestUpdateAd_After(t *testing.T) {
 				Text:         "test Text",
 				UserID:       47,
 				Published:    true,
-				DateCreation: time.Now().UTC().Format(dateFormat),
-				DateUpdate:   time.Now().UTC().Format(dateFormat),
+				DateCreation: testDate,
+				DateUpdate:   testDate,
 			},
 			errRepoUpdate: nil,
+			updateTimes:   1,
 			wantErr:       false,
 		},
 	}
 	for _, testCase := range testTable {
 		t.Run(testCase.name, func(t *testing.T) {
-			ctx := context.Background()
+			ctx := auth.WithUserID(context.Background(), testCase.outAd.UserID)
 			adRepo.EXPECT().GetAd(ctx, testCase.inAd.ID).Return(testCase.outAd, nil).Times(1)
 
-			adRepo.EXPECT().Update(ctx, testCase.outAd.ID, testCase.outAd.Title, testCase.outAd.Text).
-				Return(testCase.outAd, testCase.errRepoUpdate).Times(1)
+			adRepo.EXPECT().Update(ctx, testCase.outAd.ID, testCase.outAd.Title, testCase.outAd.Text, models.AdDetails{}, models.AdDraft, int64(0)).
+				Return(testCase.outAd, testCase.errRepoUpdate).Times(testCase.updateTimes)
 
-			ad, err := adService.UpdateAd(ctx, testCase.outAd.ID, testCase.outAd.UserID, testCase.outAd.Title, testCase.outAd.Text)
+			ad, err := adService.UpdateAd(ctx, testCase.outAd.ID, testCase.outAd.Title, testCase.outAd.Text, models.AdDetails{}, 0)
 			if testCase.wantErr {
 				assert.Error(t, err)
 				assert.Nil(t, ad)
@@ -601,7 +565,7 @@ func TestDeleteAd(t *testing.T) {
 	defer ctrl.Finish()
 
 	adRepo := repoMock.NewMockAdRepository(ctrl)
-	adService := NewAdService(adRepo)
+	adService := NewAdService(adRepo, anyRevisions(ctrl))
 
 	testTable := []struct {
 		name      string
@@ -643,28 +607,28 @@ func TestDeleteAd(t *testing.T) {
 			wantErr:   true,
 		},
 		{
-			name:   "error test delete: DeleteAd()",
+			name:   "error test delete: SetDeletedAt()",
 			adID:   10,
 			userID: 47,
 			adGet: &models.Ad{
 				ID:     10,
 				UserID: 47,
 			},
-			errDelete: fmt.Errorf("error from repository DeleteAd()"),
+			errDelete: fmt.Errorf("error from repository SetDeletedAt()"),
 			wantErr:   true,
 		},
 	}
 	for _, testCase := range testTable {
 		t.Run(testCase.name, func(t *testing.T) {
-			ctx := context.Background()
+			ctx := auth.WithUserID(context.Background(), testCase.userID)
 			adRepo.EXPECT().GetAd(ctx, testCase.adID).Return(testCase.adGet, testCase.errGet).Times(1)
 
 			if testCase.errGet == nil && testCase.errAccess == nil {
-				adRepo.EXPECT().DeleteAd(ctx, testCase.adID).
-					Return(testCase.errDelete).Times(1)
+				adRepo.EXPECT().SetDeletedAt(ctx, testCase.adID, gomock.Any()).
+					Return(testCase.adGet, testCase.errDelete).Times(1)
 			}
 
-			err := adService.DeleteAd(ctx, testCase.adID, testCase.userID)
+			err := adService.DeleteAd(ctx, testCase.adID)
 			if testCase.wantErr {
 				assert.Error(t, err)
 				return
@@ -678,194 +642,384 @@ const (
 	GetAd       = "GetAd"
 	AccessUsers = "AccessUsers"
 	DeleteAd    = "DeleteAd"
-	GetAds      = "GetAds"
+	FindAds     = "FindAds"
 )
 
-func TestGetAdsByTitle(t *testing.T) {
+func TestSearchAds(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	adRepo := repoMock.NewMockAdRepository(ctrl)
-	adService := NewAdService(adRepo)
+	ads := []*models.Ad{
+		{ID: 0, Title: "Продам кота", Text: "Пушистый котёнок ищет дом"},
+		{ID: 1, Title: "black cats", Text: "two cats for sale"},
+		{ID: 2, Title: "dog", Text: "good boy"},
+		{ID: 3, Title: "cat food", Text: "dry, for adults only"},
+	}
 
 	testTable := []struct {
 		name         string
-		search       string
-		rawAds       []*models.Ad
-		expected     []*models.Ad
+		query        string
+		page         models.PageParams
+		foundAds     []*models.Ad
+		expectedIDs  []int64
+		snippet      string
 		storageError map[string]error
 		wantError    bool
 	}{
 		{
-			name:   "true test",
-			search: "cats",
-			rawAds: []*models.Ad{
-				{Title: "cats"},
-				{Title: "cat"},
-			},
-			expected: []*models.Ad{
-				{Title: "cats"},
-			},
-			storageError: make(map[string]error),
-			wantError:    false,
+			name:        "true test: russian stemming",
+			query:       "коты",
+			foundAds:    []*models.Ad{ads[0]},
+			expectedIDs: []int64{0},
+			snippet:     "Продам <mark>кота</mark>",
 		},
 		{
-			name:         "error from repository GetAds()",
-			search:       "",
-			rawAds:       nil,
-			expected:     nil,
-			storageError: map[string]error{GetAds: fmt.Errorf("error from repository GetAds()")},
+			name:        "true test: english stemming, case insensitive, most relevant first",
+			query:       "CAT",
+			foundAds:    []*models.Ad{ads[1], ads[3]},
+			expectedIDs: []int64{1, 3},
+			snippet:     "two <mark>cats</mark> for sale",
+		},
+		{
+			name:        "true test: match in title only",
+			query:       "food",
+			foundAds:    []*models.Ad{ads[3]},
+			expectedIDs: []int64{3},
+			snippet:     "cat <mark>food</mark>",
+		},
+		{
+			name:        "true test: nothing found",
+			query:       "parrot",
+			expectedIDs: []int64{},
+		},
+		{
+			name:         "error from repository FindAds()",
+			query:        "dog",
+			storageError: map[string]error{FindAds: fmt.Errorf("error from repository FindAds()")},
 			wantError:    true,
 		},
+		{
+			name:      "error invalid sort field",
+			query:     "dog",
+			page:      models.PageParams{SortBy: "text"},
+			foundAds:  []*models.Ad{ads[2]},
+			wantError: true,
+		},
 	}
 
 	for _, testCase := range testTable {
 		t.Run(testCase.name, func(t *testing.T) {
 			ctx := context.Background()
-			adRepo.EXPECT().GetAds(ctx).Return(testCase.rawAds, testCase.storageError[GetAds]).Times(1)
+			adRepo := repoMock.NewMockAdRepository(ctrl)
+			adService := NewAdService(adRepo, anyRevisions(ctrl))
+
+			adRepo.EXPECT().GetAds(ctx).Return(ads, nil).Times(1)
+			assert.NoError(t, adService.BuildSearchIndex(ctx))
 
-			ads, err := adService.GetAdsByTitle(ctx, testCase.search)
+			if testCase.foundAds != nil || testCase.storageError != nil {
+				adRepo.EXPECT().FindAds(ctx, gomock.Any()).
+					Return(testCase.foundAds, testCase.storageError[FindAds]).Times(1)
+			}
+
+			page, err := adService.SearchAds(ctx, testCase.query, testCase.page)
 			if testCase.wantError {
 				assert.Error(t, err)
-				assert.Nil(t, ads)
+				assert.Nil(t, page)
 				return
 			}
 			assert.NoError(t, err)
-			assert.Equal(t, *testCase.expected[0], *ads[0])
+			ids := make([]int64, 0)
+			for _, result := range page.Results {
+				ids = append(ids, result.Ad.ID)
+				assert.Greater(t, result.Score, 0.0)
+			}
+			assert.Equal(t, testCase.expectedIDs, ids)
+			if len(page.Results) != 0 && testCase.snippet != "" {
+				assert.Equal(t, testCase.snippet, page.Results[0].Snippet)
+			}
 		})
 	}
 }
 
-func TestListAds(t *testing.T) {
+func TestSearchAds_IndexFollowsChanges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := auth.WithUserID(context.Background(), 1)
+	adRepo := repoMock.NewMockAdRepository(ctrl)
+	adService := NewAdService(adRepo, anyRevisions(ctrl))
+
+	adRepo.EXPECT().AddAd(ctx, gomock.Any()).Return(int64(0), nil).Times(1)
+	_, err := adService.CreateAd(ctx, "old title", "text", models.AdDetails{})
+	assert.NoError(t, err)
+
+	adRepo.EXPECT().GetAd(ctx, int64(0)).Return(&models.Ad{ID: 0, UserID: 1}, nil).Times(2)
+	adRepo.EXPECT().Update(ctx, int64(0), "new title", "text", models.AdDetails{}, models.AdDraft, int64(0)).
+		Return(&models.Ad{ID: 0, UserID: 1, Title: "new title", Text: "text"}, nil).Times(1)
+	_, err = adService.UpdateAd(ctx, 0, "new title", "text", models.AdDetails{}, 0)
+	assert.NoError(t, err)
+
+	page, err := adService.SearchAds(ctx, "old", models.PageParams{})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Results)
+
+	published := true
+	adRepo.EXPECT().FindAds(ctx, models.AdFilter{IDs: []int64{0}, Published: &published}).
+		Return([]*models.Ad{{ID: 0, Title: "new title", Text: "text", Published: true}}, nil).Times(1)
+	page, err = adService.SearchAds(ctx, "new", models.PageParams{})
+	assert.NoError(t, err)
+	assert.Len(t, page.Results, 1)
+	assert.Equal(t, "<mark>new</mark> title", page.Results[0].Snippet)
+
+	adRepo.EXPECT().SetDeletedAt(ctx, int64(0), gomock.Any()).Return(&models.Ad{}, nil).Times(1)
+	assert.NoError(t, adService.DeleteAd(ctx, 0))
+	page, err = adService.SearchAds(ctx, "new", models.PageParams{})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Results)
+}
+
+func TestAdAccess_Roles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	adRepo := repoMock.NewMockAdRepository(ctrl)
+	adService := NewAdService(adRepo, anyRevisions(ctrl))
+	ad := &models.Ad{ID: 0, UserID: 1, Title: "title", Text: "text"}
+
+	// модератор проверяет объявления, но не редактирует чужие
+	moderatorCtx := moderatorContext(2)
+	adRepo.EXPECT().GetAd(moderatorCtx, int64(0)).Return(ad, nil).Times(2)
+	_, err := adService.UpdateAd(moderatorCtx, 0, "new title", "text", models.AdDetails{}, 0)
+	assert.ErrorIs(t, err, ErrNoAccessAd)
+	assert.ErrorIs(t, adService.DeleteAd(moderatorCtx, 0), ErrNoAccessAd)
+
+	// администратор управляет любыми объявлениями
+	adminCtx := auth.WithRole(auth.WithUserID(context.Background(), 3), models.RoleAdmin)
+	adRepo.EXPECT().GetAd(adminCtx, int64(0)).Return(ad, nil).Times(2)
+	adRepo.EXPECT().Update(adminCtx, int64(0), "new title", "text", models.AdDetails{}, models.AdDraft, int64(0)).
+		Return(&models.Ad{ID: 0, UserID: 1, Title: "new title", Text: "text"}, nil).Times(1)
+	updated, err := adService.UpdateAd(adminCtx, 0, "new title", "text", models.AdDetails{}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "new title", updated.Title)
+	adRepo.EXPECT().SetDeletedAt(adminCtx, int64(0), gomock.Any()).Return(ad, nil).Times(1)
+	assert.NoError(t, adService.DeleteAd(adminCtx, 0))
+}
+
+func TestWatchAds(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	ctx := auth.WithUserID(context.Background(), 1)
 	adRepo := repoMock.NewMockAdRepository(ctrl)
-	adService := NewAdService(adRepo)
+	adService := NewAdService(adRepo, anyRevisions(ctrl))
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	sub := adService.WatchAds(watchCtx, models.AdFilter{AuthorIDs: []int64{1}})
+	// анонимный подписчик узнаёт только о публикации
+	anonymousCtx, cancelAnonymous := context.WithCancel(context.Background())
+	anonymous := adService.WatchAds(anonymousCtx, models.AdFilter{})
+
+	adRepo.EXPECT().AddAd(ctx, gomock.Any()).Return(int64(0), nil).Times(1)
+	_, err := adService.CreateAd(ctx, "title", "text", models.AdDetails{})
+	assert.NoError(t, err)
+
+	adRepo.EXPECT().GetAd(ctx, int64(0)).
+		Return(&models.Ad{ID: 0, UserID: 1, Title: "title", Text: "text", State: models.AdApproved}, nil).Times(3)
+	adRepo.EXPECT().Update(ctx, int64(0), "new title", "text", models.AdDetails{}, models.AdDraft, int64(0)).
+		Return(&models.Ad{ID: 0, UserID: 1, Title: "new title", Text: "text"}, nil).Times(1)
+	_, err = adService.UpdateAd(ctx, 0, "new title", "text", models.AdDetails{}, 0)
+	assert.NoError(t, err)
+
+	adRepo.EXPECT().SetState(ctx, int64(0), models.AdPublished, "", int64(0)).
+		Return(&models.Ad{ID: 0, UserID: 1, Title: "new title", Text: "text", Published: true}, nil).Times(1)
+	_, err = adService.ChangeAdStatus(ctx, 0, true, 0)
+	assert.NoError(t, err)
 
-	type filters struct {
-		published    string
-		userID       string
-		dateCreation string
+	adRepo.EXPECT().SetDeletedAt(ctx, int64(0), gomock.Any()).Return(&models.Ad{}, nil).Times(1)
+	assert.NoError(t, adService.DeleteAd(ctx, 0))
+
+	expected := []models.AdEventType{models.AdCreated, models.AdUpdated, models.AdStatusChanged, models.AdDeleted}
+	for i, eventType := range expected {
+		event := <-sub.Events()
+		assert.Equal(t, int64(i+1), event.ID)
+		assert.Equal(t, eventType, event.Type)
+		assert.Equal(t, int64(0), event.Ad.ID)
 	}
 
+	cancel()
+	_, ok := <-sub.Events()
+	assert.False(t, ok)
+	assert.NoError(t, sub.Err())
+
+	event := <-anonymous.Events()
+	assert.Equal(t, models.AdStatusChanged, event.Type)
+	cancelAnonymous()
+	_, ok = <-anonymous.Events()
+	assert.False(t, ok)
+}
+
+func TestWatchAds_Visibility(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := auth.WithUserID(context.Background(), 1)
+	adRepo := repoMock.NewMockAdRepository(ctrl)
+	adService := NewAdService(adRepo, anyRevisions(ctrl))
+
+	watchCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	user := adService.WatchAds(auth.WithUserID(watchCtx, 2), models.AdFilter{})
+	moderator := adService.WatchAds(auth.WithRole(auth.WithUserID(watchCtx, 3), models.RoleModerator),
+		models.AdFilter{})
+
+	approved := &models.Ad{ID: 5, UserID: 1, Title: "title", State: models.AdApproved}
+	published := &models.Ad{ID: 5, UserID: 1, Title: "title", State: models.AdPublished, Published: true}
+	adRepo.EXPECT().GetAd(ctx, int64(5)).Return(approved, nil).Times(1)
+	adRepo.EXPECT().SetState(ctx, int64(5), models.AdPublished, "", int64(0)).Return(published, nil).Times(1)
+	_, err := adService.ChangeAdStatus(ctx, 5, true, 0)
+	assert.NoError(t, err)
+
+	adRepo.EXPECT().GetAd(ctx, int64(5)).Return(published, nil).Times(1)
+	adRepo.EXPECT().Update(ctx, int64(5), "hidden", "text", models.AdDetails{}, gomock.Any(), int64(0)).
+		Return(&models.Ad{ID: 5, UserID: 1, Title: "hidden"}, nil).Times(1)
+	_, err = adService.UpdateAd(ctx, 5, "hidden", "text", models.AdDetails{}, 0)
+	assert.NoError(t, err)
+
+	event := <-user.Events()
+	assert.Equal(t, models.AdStatusChanged, event.Type)
+	event = <-user.Events()
+	assert.Equal(t, models.AdLeft, event.Type, "the edited ad is no longer visible to other users")
+	assert.Equal(t, models.Ad{ID: 5}, event.Ad)
+
+	<-moderator.Events()
+	event = <-moderator.Events()
+	assert.Equal(t, models.AdUpdated, event.Type)
+	assert.Equal(t, "hidden", event.Ad.Title)
+}
+
+func TestListAds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	adRepo := repoMock.NewMockAdRepository(ctrl)
+	adService := NewAdService(adRepo, anyRevisions(ctrl))
+
+	published := true
+	unpublished := false
+	anonymous := noOwner
+	author := int64(10)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
 	testTable := []struct {
 		name         string
-		filters      filters
+		userID       int64
+		role         models.Role
+		filter       models.AdFilter
+		repoFilter   models.AdFilter
 		rawAds       []*models.Ad
 		expected     []*models.Ad
 		storageError map[string]error
 		wantError    bool
 	}{
 		{
-			name:         "error from repository GetAds()",
+			name:         "error from repository FindAds()",
+			repoFilter:   models.AdFilter{Published: &published, VisibleTo: &anonymous},
 			rawAds:       nil,
 			expected:     nil,
-			storageError: map[string]error{GetAds: fmt.Errorf("error from repository GetAds()")},
+			storageError: map[string]error{FindAds: fmt.Errorf("error from repository FindAds()")},
 			wantError:    true,
 		},
 		{
-			name:    "error invalid filters: published",
-			filters: filters{published: "abcdjsjsk"},
-			rawAds: []*models.Ad{
-				{Published: true}, {Published: false},
-			},
-			expected:  nil,
-			wantError: true,
+			name:       "true test: empty filter => published = true",
+			repoFilter: models.AdFilter{Published: &published, VisibleTo: &anonymous},
+			rawAds:     []*models.Ad{{Published: true}},
+			expected:   []*models.Ad{{Published: true}},
 		},
 		{
-			name:    "error invalid filters: userID",
-			filters: filters{userID: "absfsjf"},
-			rawAds: []*models.Ad{
-				{UserID: 10}, {UserID: 15},
-			},
-			expected:  nil,
-			wantError: true,
+			name:       "true test: published - false, user sees only own drafts",
+			userID:     author,
+			filter:     models.AdFilter{Published: &unpublished},
+			repoFilter: models.AdFilter{Published: &unpublished, VisibleTo: &author},
+			rawAds:     []*models.Ad{{UserID: author, Published: false}},
+			expected:   []*models.Ad{{UserID: author, Published: false}},
 		},
 		{
-			name:    "error invalid filters: date_creation",
-			filters: filters{dateCreation: "absfsjf"},
-			rawAds: []*models.Ad{
-				{DateCreation: "absfsjf"}, {DateCreation: time.Now().Format(dateFormat)},
-			},
-			expected:  nil,
-			wantError: true,
+			name:       "true test: moderator sees all drafts",
+			userID:     author,
+			role:       models.RoleModerator,
+			filter:     models.AdFilter{Published: &unpublished},
+			repoFilter: models.AdFilter{Published: &unpublished},
+			rawAds:     []*models.Ad{{UserID: 11, Published: false}},
+			expected:   []*models.Ad{{UserID: 11, Published: false}},
 		},
 		{
-			name:    "true test: published - true",
-			filters: filters{published: "true"},
-			rawAds: []*models.Ad{
-				{Published: true}, {Published: false},
-			},
-			expected: []*models.Ad{
-				{Published: true},
-			},
-			wantError: false,
-		},
-		{
-			name:    "true test: published - false",
-			filters: filters{published: "false"},
-			rawAds: []*models.Ad{
-				{Published: true}, {Published: false},
-			},
-			expected: []*models.Ad{
-				{Published: false},
-			},
-			wantError: false,
-		},
-		{
-			name: "true test: empty filters => published = true",
-			rawAds: []*models.Ad{
-				{Published: true}, {Published: false},
-			},
-			expected: []*models.Ad{
-				{Published: true},
-			},
-			wantError: false,
+			name:       "true test: userID is passed as is",
+			filter:     models.AdFilter{AuthorIDs: []int64{10}},
+			repoFilter: models.AdFilter{AuthorIDs: []int64{10}, VisibleTo: &anonymous},
+			rawAds:     []*models.Ad{{UserID: 10, Published: true}},
+			expected:   []*models.Ad{{UserID: 10, Published: true}},
 		},
 		{
-			name:    "true test: userID",
-			filters: filters{userID: "10"},
-			rawAds: []*models.Ad{
-				{UserID: 10}, {UserID: 20},
-			},
-			expected: []*models.Ad{
-				{UserID: 10},
-			},
-			wantError: false,
-		},
-		{
-			name:    "true test: dateCreation",
-			filters: filters{dateCreation: time.Now().Format(dateFormat)},
-			rawAds: []*models.Ad{
-				{DateCreation: time.Now().Format(dateFormat)}, {DateCreation: "31/7/2015"},
-			},
-			expected: []*models.Ad{
-				{DateCreation: time.Now().Format(dateFormat)},
-			},
-			wantError: false,
+			name:   "true test: date range is passed as is",
+			filter: models.AdFilter{CreatedFrom: today, CreatedTo: today.AddDate(0, 0, 1)},
+			repoFilter: models.AdFilter{CreatedFrom: today, CreatedTo: today.AddDate(0, 0, 1),
+				VisibleTo: &anonymous},
+			rawAds:   []*models.Ad{{DateCreation: today, Published: true}},
+			expected: []*models.Ad{{DateCreation: today, Published: true}},
 		},
 	}
 	for _, testCase := range testTable {
 		t.Run(testCase.name, func(t *testing.T) {
 			ctx := context.Background()
-			adRepo.EXPECT().GetAds(ctx).Return(testCase.rawAds, testCase.storageError[GetAds]).Times(1)
+			if testCase.userID != 0 {
+				ctx = auth.WithRole(auth.WithUserID(ctx, testCase.userID), testCase.role)
+			}
+			pageFilter := testCase.repoFilter
+			pageFilter.SortBy, pageFilter.Order, pageFilter.Limit = models.AdSortByID, models.SortAsc, defaultPageLimit+1
+			adRepo.EXPECT().FindAds(ctx, pageFilter).
+				Return(testCase.rawAds, testCase.storageError[FindAds]).Times(1)
+			if !testCase.wantError {
+				adRepo.EXPECT().CountFacets(ctx, testCase.repoFilter).Return(models.AdFacets{}, nil).Times(1)
+			}
 
-			ads, err := adService.ListAds(
-				ctx,
-				testCase.filters.published,
-				testCase.filters.userID,
-				testCase.filters.dateCreation,
-			)
+			page, err := adService.ListAds(ctx, testCase.filter, models.PageParams{})
 
 			if testCase.wantError {
 				assert.Error(t, err)
-				assert.Nil(t, ads)
+				assert.Nil(t, page)
 				return
 			}
 			assert.NoError(t, err)
-			assert.Equal(t, *testCase.expected[0], *ads[0])
-			assert.Equal(t, len(testCase.expected), len(ads))
+			assert.Equal(t, *testCase.expected[0], *page.Ads[0])
+			assert.Equal(t, len(testCase.expected), len(page.Ads))
 		})
 	}
 }
+
+func TestAdMutations_VersionMismatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := auth.WithUserID(context.Background(), 1)
+	adRepo := repoMock.NewMockAdRepository(ctrl)
+	adService := NewAdService(adRepo, anyRevisions(ctrl))
+	adRepo.EXPECT().GetAd(ctx, int64(0)).
+		Return(&models.Ad{ID: 0, UserID: 1, Title: "title", State: models.AdApproved, Version: 3}, nil).AnyTimes()
+
+	_, err := adService.UpdateAd(ctx, 0, "new title", "text", models.AdDetails{}, 2)
+	assert.ErrorIs(t, err, domain.ErrPrecondition)
+	_, err = adService.ChangeAdStatus(ctx, 0, true, 2)
+	assert.ErrorIs(t, err, domain.ErrPrecondition)
+
+	// версию, с которой сверился сервис, успели изменить до записи
+	adRepo.EXPECT().Update(ctx, int64(0), "new title", "text", models.AdDetails{}, models.AdDraft, int64(3)).
+		Return(nil, domain.ErrAdVersionMismatch).Times(1)
+	_, err = adService.UpdateAd(ctx, 0, "new title", "text", models.AdDetails{}, 3)
+	assert.ErrorIs(t, err, domain.ErrPrecondition)
+
+	adRepo.EXPECT().SetState(ctx, int64(0), models.AdPublished, "", int64(3)).
+		Return(&models.Ad{ID: 0, UserID: 1, Title: "title", Published: true, Version: 4}, nil).Times(1)
+	ad, err := adService.ChangeAdStatus(ctx, 0, true, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), ad.Version)
+}
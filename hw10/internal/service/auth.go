@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"homework10/internal/auth"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	"homework10/internal/policy"
+)
+
+var (
+	ErrInvalidCredentials = domain.ErrNotAuthorized{Err: errors.New("invalid email or password")}
+	ErrNotAuthenticated   = domain.ErrNotAuthorized{Err: errors.New("the user is not authenticated")}
+)
+
+type TokenIssuer interface {
+	Issue(userID int64) (*models.AuthToken, error)
+}
+
+type AuthService struct {
+	userRepo domain.UserRepository
+	tokens   TokenIssuer
+}
+
+func NewAuthService(userRepo domain.UserRepository, tokens TokenIssuer) *AuthService {
+	return &AuthService{
+		userRepo: userRepo,
+		tokens:   tokens,
+	}
+}
+
+// Login выдаёт токен доступа пользователю, если пароль совпадает с сохранённым хешем; удалённые пользователи
+// войти не могут
+func (s *AuthService) Login(ctx context.Context, email string, password string) (*models.AuthToken, error) {
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		checkPassword(nil, password)
+		return nil, ErrInvalidCredentials
+	}
+	if !checkPassword(user.PasswordHash, password) || user.IsDeleted() {
+		return nil, ErrInvalidCredentials
+	}
+	return s.tokens.Issue(user.ID)
+}
+
+// noOwner владелец ресурса, которого нет, например у ещё не созданного объявления
+const noOwner int64 = -1
+
+// authorize проверяет по политике p, что пользователь из контекста может выполнить action над ресурсом
+// пользователя ownerID; при отказе возвращает denied
+func authorize(ctx context.Context, p *policy.Policy, action policy.Action, ownerID int64, denied error) error {
+	userID, ok := auth.UserIDFromContext(ctx)
+	if !ok {
+		return ErrNotAuthenticated
+	}
+	if !p.Can(auth.RoleFromContext(ctx), action, ownerID != noOwner && userID == ownerID) {
+		return denied
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"homework10/internal/auth"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	repoMock "homework10/internal/service/mock"
+	"testing"
+	"time"
+)
+
+func TestLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := repoMock.NewMockUserRepository(ctrl)
+	tokens := auth.NewTokenManager([]byte("test secret"), time.Hour)
+	authService := NewAuthService(userRepo, tokens)
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	testTable := []struct {
+		name     string
+		email    string
+		password string
+		outUser  *models.User
+		repoErr  error
+		wantErr  error
+	}{
+		{
+			name:     "true test Login()",
+			email:    "Ivan@gmail.com",
+			password: "password123",
+			outUser:  &models.User{ID: 7, NickName: "Ivan", Email: "Ivan@gmail.com", PasswordHash: passwordHash},
+		},
+		{
+			name:     "wrong password",
+			email:    "Ivan@gmail.com",
+			password: "password321",
+			outUser:  &models.User{ID: 7, NickName: "Ivan", Email: "Ivan@gmail.com", PasswordHash: passwordHash},
+			wantErr:  ErrInvalidCredentials,
+		},
+		{
+			name:     "user without password",
+			email:    "Ivan@gmail.com",
+			password: "",
+			outUser:  &models.User{ID: 7, NickName: "Ivan", Email: "Ivan@gmail.com"},
+			wantErr:  ErrInvalidCredentials,
+		},
+		{
+			name:     "deleted user",
+			email:    "Ivan@gmail.com",
+			password: "password123",
+			outUser: &models.User{ID: 7, NickName: "Ivan", Email: "Ivan@gmail.com", PasswordHash: passwordHash,
+				DeletedAt: time.Now().UTC()},
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:     "unknown email",
+			email:    "unknown@gmail.com",
+			password: "password123",
+			repoErr:  domain.ErrUserNotExist,
+			wantErr:  ErrInvalidCredentials,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctx := context.Background()
+			userRepo.EXPECT().GetUserByEmail(ctx, testCase.email).Return(testCase.outUser, testCase.repoErr).Times(1)
+
+			token, err := authService.Login(ctx, testCase.email, testCase.password)
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+				assert.Nil(t, token)
+				return
+			}
+			assert.NoError(t, err)
+			userID, err := tokens.Parse(token.Token)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.outUser.ID, userID)
+		})
+	}
+}
+
+func TestLogin_CanceledContext(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := repoMock.NewMockUserRepository(ctrl)
+	authService := NewAuthService(userRepo, auth.NewTokenManager([]byte("test secret"), time.Hour))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	userRepo.EXPECT().GetUserByEmail(ctx, "Ivan@gmail.com").Return(nil, ctx.Err()).Times(1)
+
+	token, err := authService.Login(ctx, "Ivan@gmail.com", "password123")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, token)
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxCityLen ограничение длины названия города в символах
+const maxCityLen = 100
+
+var (
+	errNegativePrice   = errors.New("price must not be negative")
+	errCurrencyMissing = errors.New("currency is required when price is set")
+	errInvalidCurrency = errors.New("currency must be a three-letter ISO 4217 code")
+	errUnknownCategory = errors.New("unknown category")
+	errCityTooLong     = fmt.Errorf("city must be at most %d characters", maxCityLen)
+)
+
+// ListCategories возвращает дерево категорий списком, в котором родительская категория идёт раньше подкатегорий
+func (s *AdService) ListCategories(ctx context.Context) ([]models.Category, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.Categories.Categories(), nil
+}
+
+// expandCategories заменяет категории фильтра их поддеревьями; неизвестная категория - ошибка валидации
+func (s *AdService) expandCategories(filter models.AdFilter) (models.AdFilter, error) {
+	if len(filter.CategoryIDs) == 0 {
+		return filter, nil
+	}
+	categoryIDs := make([]string, 0, len(filter.CategoryIDs))
+	for _, categoryID := range filter.CategoryIDs {
+		subtree := s.Categories.Subtree(categoryID)
+		if subtree == nil {
+			return filter, domain.ValidationErrors{{Field: "Category", Rule: "exists", Message: errUnknownCategory.Error()}}
+		}
+		categoryIDs = append(categoryIDs, subtree...)
+	}
+	filter.CategoryIDs = categoryIDs
+	return filter, nil
+}
+
+// adFacets дополняет счётчики хранилища по собственным категориям объявлений родительскими категориями
+// и упорядочивает значения
+func (s *AdService) adFacets(counts models.AdFacets) models.AdFacets {
+	categories := make(map[string]int)
+	for _, count := range counts.Categories {
+		for _, categoryID := range s.Categories.Path(count.Value) {
+			categories[categoryID] += count.Count
+		}
+	}
+	cities := make(map[string]int, len(counts.Cities))
+	for _, count := range counts.Cities {
+		cities[count.Value] += count.Count
+	}
+	return models.AdFacets{
+		Categories: facetCounts(categories),
+		Cities:     facetCounts(cities),
+	}
+}
+
+// facetCounts упорядочивает значения по убыванию числа объявлений, равные - по значению
+func facetCounts(counts map[string]int) []models.FacetCount {
+	facets := make([]models.FacetCount, 0, len(counts))
+	for value, count := range counts {
+		facets = append(facets, models.FacetCount{Value: value, Count: count})
+	}
+	sort.Slice(facets, func(i, j int) bool {
+		if facets[i].Count != facets[j].Count {
+			return facets[i].Count > facets[j].Count
+		}
+		return facets[i].Value < facets[j].Value
+	})
+	return facets
+}
+
+// normalizeDetails убирает пробелы вокруг города и приводит код валюты к верхнему регистру
+func normalizeDetails(details models.AdDetails) models.AdDetails {
+	details.City = strings.TrimSpace(details.City)
+	details.CategoryID = strings.TrimSpace(details.CategoryID)
+	details.Price.Currency = strings.ToUpper(strings.TrimSpace(details.Price.Currency))
+	return details
+}
+
+// validateDetails проверяет цену, категорию и город объявления
+func (s *AdService) validateDetails(details models.AdDetails) domain.ValidationErrors {
+	var errs domain.ValidationErrors
+	if details.Price.Amount < 0 {
+		errs = append(errs, domain.FieldError{Field: "Price", Rule: "min", Message: errNegativePrice.Error()})
+	}
+	switch {
+	case details.Price.Currency == "" && details.Price.Amount != 0:
+		errs = append(errs, domain.FieldError{Field: "Currency", Rule: "required", Message: errCurrencyMissing.Error()})
+	case details.Price.Currency != "" && !isCurrencyCode(details.Price.Currency):
+		errs = append(errs, domain.FieldError{Field: "Currency", Rule: "iso4217", Message: errInvalidCurrency.Error()})
+	}
+	if _, ok := s.Categories.Get(details.CategoryID); details.CategoryID != "" && !ok {
+		errs = append(errs, domain.FieldError{Field: "Category", Rule: "exists", Message: errUnknownCategory.Error()})
+	}
+	if utf8.RuneCountInString(details.City) > maxCityLen {
+		errs = append(errs, domain.FieldError{Field: "City", Rule: "max", Message: errCityTooLong.Error()})
+	}
+	return errs
+}
+
+// validateContent проверяет заголовок, текст и характеристики объявления и возвращает все ошибки сразу
+func (s *AdService) validateContent(ad models.Ad) error {
+	errs := s.validateDetails(ad.AdDetails)
+	if err := validateAd(ad); err != nil {
+		var fieldErrs domain.ValidationErrors
+		if !errors.As(err, &fieldErrs) {
+			return err
+		}
+		errs = append(fieldErrs, errs...)
+	}
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
+
+func isCurrencyCode(currency string) bool {
+	if len(currency) != 3 {
+		return false
+	}
+	for _, r := range currency {
+		if r > unicode.MaxASCII || !unicode.IsUpper(r) {
+			return false
+		}
+	}
+	return true
+}
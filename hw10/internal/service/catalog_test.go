@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"homework10/internal/auth"
+	"homework10/internal/category"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	repoMock "homework10/internal/service/mock"
+	"testing"
+)
+
+// testCategories transport > cars > sedans, transport > bikes и отдельная jobs
+func testCategories(t *testing.T) *category.Tree {
+	tree, err := category.NewTree([]models.Category{
+		{ID: "transport"},
+		{ID: "cars", ParentID: "transport"},
+		{ID: "sedans", ParentID: "cars"},
+		{ID: "bikes", ParentID: "transport"},
+		{ID: "jobs"},
+	})
+	require.NoError(t, err)
+	return tree
+}
+
+func TestCreateAd_Details(t *testing.T) {
+	testTable := []struct {
+		name      string
+		details   models.AdDetails
+		expected  models.AdDetails
+		wantRules map[string]string
+	}{
+		{
+			name:     "details are normalized",
+			details:  models.AdDetails{Price: models.Price{Amount: 150000, Currency: " rub"}, CategoryID: "sedans", City: " Казань "},
+			expected: models.AdDetails{Price: models.Price{Amount: 150000, Currency: "RUB"}, CategoryID: "sedans", City: "Казань"},
+		},
+		{
+			name:     "free ad",
+			details:  models.AdDetails{Price: models.Price{Currency: "RUB"}},
+			expected: models.AdDetails{Price: models.Price{Currency: "RUB"}},
+		},
+		{
+			name:      "negative price without currency",
+			details:   models.AdDetails{Price: models.Price{Amount: -1}},
+			wantRules: map[string]string{"Price": "min", "Currency": "required"},
+		},
+		{
+			name:      "unknown currency format and category",
+			details:   models.AdDetails{Price: models.Price{Amount: 1, Currency: "рубли"}, CategoryID: "boats"},
+			wantRules: map[string]string{"Currency": "iso4217", "Category": "exists"},
+		},
+	}
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			adRepo := repoMock.NewMockAdRepository(ctrl)
+			adService := NewAdService(adRepo, anyRevisions(ctrl))
+			adService.Categories = testCategories(t)
+			ctx := auth.WithUserID(context.Background(), 1)
+
+			if testCase.wantRules == nil {
+				adRepo.EXPECT().AddAd(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, ad models.Ad) (int64, error) {
+					assert.Equal(t, testCase.expected, ad.AdDetails)
+					return 1, nil
+				}).Times(1)
+			}
+
+			ad, err := adService.CreateAd(ctx, "title", "text", testCase.details)
+			if testCase.wantRules == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.expected, ad.AdDetails)
+				return
+			}
+			var fieldErrs domain.ValidationErrors
+			require.ErrorAs(t, err, &fieldErrs)
+			rules := make(map[string]string)
+			for _, fieldErr := range fieldErrs {
+				rules[fieldErr.Field] = fieldErr.Rule
+			}
+			assert.Equal(t, testCase.wantRules, rules)
+		})
+	}
+}
+
+func TestUpdateAd_ValidationCombinesFields(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	adRepo := repoMock.NewMockAdRepository(ctrl)
+	adService := NewAdService(adRepo, anyRevisions(ctrl))
+	ctx := auth.WithUserID(context.Background(), 1)
+	adRepo.EXPECT().GetAd(ctx, int64(1)).Return(&models.Ad{ID: 1, UserID: 1}, nil).Times(1)
+
+	_, err := adService.UpdateAd(ctx, 1, "", "text", models.AdDetails{CategoryID: "boats"}, 0)
+	var fieldErrs domain.ValidationErrors
+	require.ErrorAs(t, err, &fieldErrs)
+	assert.Len(t, fieldErrs, 2)
+	assert.Equal(t, "Title", fieldErrs[0].Field)
+	assert.Equal(t, "Category", fieldErrs[1].Field)
+}
+
+func TestListAds_Catalog(t *testing.T) {
+	published := true
+	anonymous := noOwner
+	ads := []*models.Ad{
+		{ID: 1, Published: true, AdDetails: models.AdDetails{CategoryID: "sedans", City: "Москва"}},
+		{ID: 2, Published: true, AdDetails: models.AdDetails{CategoryID: "cars", City: "москва"}},
+		{ID: 3, Published: true, AdDetails: models.AdDetails{CategoryID: "bikes", City: "Казань"}},
+		{ID: 4, Published: true},
+	}
+
+	testTable := []struct {
+		name       string
+		filter     models.AdFilter
+		repoFilter models.AdFilter
+		wantErr    error
+	}{
+		{
+			name:   "category is expanded to its subtree, catalog filters show only published ads",
+			filter: models.AdFilter{CategoryIDs: []string{"cars"}, City: "Москва"},
+			repoFilter: models.AdFilter{Published: &published, CategoryIDs: []string{"cars", "sedans"},
+				City: "Москва", VisibleTo: &anonymous},
+		},
+		{
+			name:       "several categories",
+			filter:     models.AdFilter{CategoryIDs: []string{"bikes", "jobs"}, AuthorIDs: []int64{1}},
+			repoFilter: models.AdFilter{CategoryIDs: []string{"bikes", "jobs"}, AuthorIDs: []int64{1}, VisibleTo: &anonymous},
+		},
+		{
+			name:    "unknown category",
+			filter:  models.AdFilter{CategoryIDs: []string{"boats"}},
+			wantErr: domain.ErrValidation,
+		},
+	}
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			adRepo := repoMock.NewMockAdRepository(ctrl)
+			adService := NewAdService(adRepo, anyRevisions(ctrl))
+			adService.Categories = testCategories(t)
+			ctx := context.Background()
+
+			if testCase.wantErr == nil {
+				pageFilter := testCase.repoFilter
+				pageFilter.SortBy, pageFilter.Order, pageFilter.Limit = models.AdSortByID, models.SortAsc, 2
+				adRepo.EXPECT().FindAds(ctx, pageFilter).Return(ads[:2], nil).Times(1)
+				adRepo.EXPECT().CountFacets(ctx, testCase.repoFilter).Return(models.CountFacets(ads), nil).Times(1)
+			}
+			page, err := adService.ListAds(ctx, testCase.filter, models.PageParams{Limit: 1})
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, page.Ads, 1, "facets are counted over all pages")
+			assert.Equal(t, []models.FacetCount{
+				{Value: "transport", Count: 3},
+				{Value: "cars", Count: 2},
+				{Value: "bikes", Count: 1},
+				{Value: "sedans", Count: 1},
+			}, page.Facets.Categories)
+			assert.Equal(t, []models.FacetCount{
+				{Value: "Москва", Count: 2},
+				{Value: "Казань", Count: 1},
+			}, page.Facets.Cities)
+		})
+	}
+}
+
+func TestListCategories(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	adService := NewAdService(repoMock.NewMockAdRepository(ctrl), anyRevisions(ctrl))
+	adService.Categories = testCategories(t)
+	categories, err := adService.ListCategories(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, categories, 5)
+	assert.Equal(t, models.Category{ID: "cars", ParentID: "transport"}, categories[1])
+}
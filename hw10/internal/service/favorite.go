@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	"homework10/internal/policy"
+)
+
+// ErrAdNotPublished возвращается при попытке добавить в закладки неопубликованное объявление
+var ErrAdNotPublished = domain.ErrAlreadyExists{Err: errors.New("only published ads can be added to favorites")}
+
+// AddFavorite добавляет опубликованное объявление в закладки пользователя; повторное добавление ничего не меняет
+func (s *UserService) AddFavorite(ctx context.Context, userID int64, adID int64) (*models.Ad, error) {
+	if err := authorize(ctx, s.Policy, policy.ManageFavorites, userID, ErrNoAccess{Err: ErrNoAccessUser}); err != nil {
+		return nil, err
+	}
+	ad, err := s.ads.GetAdByID(ctx, adID)
+	if err != nil {
+		return nil, err
+	}
+	if !ad.Published {
+		return nil, ErrAdNotPublished
+	}
+	favorite := models.Favorite{UserID: userID, AdID: adID, AddedAt: now()}
+	if err := s.ads.Favorites.AddFavorite(ctx, favorite); err != nil {
+		return nil, fmt.Errorf("adding favorite: %w", err)
+	}
+	return ad, nil
+}
+
+// RemoveFavorite убирает объявление из закладок пользователя; если закладки не было, ошибки нет
+func (s *UserService) RemoveFavorite(ctx context.Context, userID int64, adID int64) error {
+	if err := authorize(ctx, s.Policy, policy.ManageFavorites, userID, ErrNoAccess{Err: ErrNoAccessUser}); err != nil {
+		return err
+	}
+	return s.ads.Favorites.RemoveFavorite(ctx, userID, adID)
+}
+
+// ListFavorites возвращает закладки пользователя, начиная с добавленных последними
+func (s *UserService) ListFavorites(ctx context.Context, userID int64) ([]*models.FavoriteAd, error) {
+	if err := authorize(ctx, s.Policy, policy.ManageFavorites, userID, ErrNoAccess{Err: ErrNoAccessUser}); err != nil {
+		return nil, err
+	}
+	favorites, err := s.ads.Favorites.GetFavorites(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	favoriteAds := make([]*models.FavoriteAd, 0, len(favorites))
+	for _, favorite := range favorites {
+		ad, err := s.ads.GetAdByID(ctx, favorite.AdID)
+		if errors.Is(err, domain.ErrAdNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		// закладки снятых объявлений удаляются вместе со снятием, но могли остаться после сбоя
+		if !ad.Published {
+			continue
+		}
+		favoriteAds = append(favoriteAds, &models.FavoriteAd{Ad: ad, AddedAt: favorite.AddedAt})
+	}
+	return favoriteAds, nil
+}
+
+// dropUnpublishedFavorites убирает объявление из закладок всех пользователей, если до изменения old оно было
+// опубликовано, а после - нет. Без Favorites убирать нечего
+func (s *AdService) dropUnpublishedFavorites(ctx context.Context, old models.Ad, published bool) error {
+	if s.Favorites == nil || !old.Published || published {
+		return nil
+	}
+	if err := s.Favorites.RemoveAdFavorites(ctx, old.ID); err != nil {
+		return fmt.Errorf("removing ad %d from favorites: %w", old.ID, err)
+	}
+	return nil
+}
+
+// removeUserFavorites удаляет закладки окончательно удаляемого пользователя
+func (s *AdService) removeUserFavorites(ctx context.Context, userID int64) error {
+	if s.Favorites == nil {
+		return nil
+	}
+	return s.Favorites.RemoveUserFavorites(ctx, userID)
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"homework10/internal/auth"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	repoMock "homework10/internal/service/mock"
+	"testing"
+	"time"
+)
+
+func TestAddFavorite(t *testing.T) {
+	testTable := []struct {
+		name    string
+		userID  int64
+		ad      *models.Ad
+		getErr  error
+		wantErr error
+	}{
+		{
+			name:   "published ad",
+			userID: 1,
+			ad:     &models.Ad{ID: 10, UserID: 2, Published: true, State: models.AdPublished},
+		},
+		{
+			name:    "own ad is not published",
+			userID:  1,
+			ad:      &models.Ad{ID: 10, UserID: 1, State: models.AdDraft},
+			wantErr: ErrAdNotPublished,
+		},
+		{
+			name:    "draft of another user is hidden",
+			userID:  1,
+			ad:      &models.Ad{ID: 10, UserID: 2, State: models.AdDraft},
+			wantErr: domain.ErrAdNotExist,
+		},
+		{
+			name:    "the ad is in the trash",
+			userID:  1,
+			ad:      &models.Ad{ID: 10, UserID: 2, Published: true, DeletedAt: time.Now()},
+			wantErr: domain.ErrAdNotExist,
+		},
+		{
+			name:    "the ad does not exist",
+			userID:  1,
+			getErr:  domain.ErrAdNotExist,
+			wantErr: domain.ErrNotFound,
+		},
+		{
+			name:    "favorites of another user",
+			userID:  3,
+			wantErr: domain.ErrForbidden,
+		},
+	}
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			adRepo := repoMock.NewMockAdRepository(ctrl)
+			favoriteRepo := repoMock.NewMockFavoriteRepository(ctrl)
+			adService := NewAdService(adRepo, anyRevisions(ctrl))
+			adService.Favorites = favoriteRepo
+			userService := NewUserService(repoMock.NewMockUserRepository(ctrl), adService)
+			ctx := auth.WithUserID(context.Background(), testCase.userID)
+
+			if testCase.userID == 1 {
+				adRepo.EXPECT().GetAd(ctx, int64(10)).Return(testCase.ad, testCase.getErr).Times(1)
+			}
+			if testCase.wantErr == nil {
+				favoriteRepo.EXPECT().AddFavorite(ctx, gomock.Any()).DoAndReturn(
+					func(_ context.Context, favorite models.Favorite) error {
+						assert.Equal(t, int64(1), favorite.UserID)
+						assert.Equal(t, int64(10), favorite.AdID)
+						assert.False(t, favorite.AddedAt.IsZero())
+						return nil
+					}).Times(1)
+			}
+
+			ad, err := userService.AddFavorite(ctx, 1, 10)
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.ad, ad)
+		})
+	}
+}
+
+func TestListFavorites(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	adRepo := repoMock.NewMockAdRepository(ctrl)
+	favoriteRepo := repoMock.NewMockFavoriteRepository(ctrl)
+	adService := NewAdService(adRepo, anyRevisions(ctrl))
+	adService.Favorites = favoriteRepo
+	userService := NewUserService(repoMock.NewMockUserRepository(ctrl), adService)
+	ctx := auth.WithUserID(context.Background(), 1)
+	added := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	published := &models.Ad{ID: 10, Published: true, State: models.AdPublished}
+	favoriteRepo.EXPECT().GetFavorites(ctx, int64(1)).Return([]models.Favorite{
+		{UserID: 1, AdID: 10, AddedAt: added},
+		{UserID: 1, AdID: 11, AddedAt: added},
+		{UserID: 1, AdID: 12, AddedAt: added},
+	}, nil).Times(1)
+	adRepo.EXPECT().GetAd(ctx, int64(10)).Return(published, nil).Times(1)
+	adRepo.EXPECT().GetAd(ctx, int64(11)).Return(&models.Ad{ID: 11, State: models.AdArchived}, nil).Times(1)
+	adRepo.EXPECT().GetAd(ctx, int64(12)).Return(nil, domain.ErrAdNotExist).Times(1)
+
+	favorites, err := userService.ListFavorites(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.FavoriteAd{{Ad: published, AddedAt: added}}, favorites)
+
+	_, err = userService.ListFavorites(auth.WithUserID(context.Background(), 2), 1)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+}
+
+func TestRemoveFavorite(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	favoriteRepo := repoMock.NewMockFavoriteRepository(ctrl)
+	adService := NewAdService(repoMock.NewMockAdRepository(ctrl), anyRevisions(ctrl))
+	adService.Favorites = favoriteRepo
+	userService := NewUserService(repoMock.NewMockUserRepository(ctrl), adService)
+	ctx := auth.WithUserID(context.Background(), 1)
+
+	favoriteRepo.EXPECT().RemoveFavorite(ctx, int64(1), int64(10)).Return(nil).Times(1)
+	assert.NoError(t, userService.RemoveFavorite(ctx, 1, 10))
+	assert.ErrorIs(t, userService.RemoveFavorite(ctx, 2, 10), domain.ErrForbidden)
+}
+
+func TestFavoritesCleanup(t *testing.T) {
+	published := models.Ad{ID: 10, UserID: 1, Published: true, State: models.AdPublished, Version: 3}
+	archived := published
+	archived.Published, archived.State, archived.Version = false, models.AdArchived, 4
+	draft := published
+	draft.Published, draft.State, draft.Version = false, models.AdDraft, 4
+
+	testTable := []struct {
+		name    string
+		ad      models.Ad
+		setup   func(adRepo *repoMock.MockAdRepository)
+		do      func(ctx context.Context, adService *AdService) error
+		cleanup bool
+	}{
+		{
+			name: "unpublished ad is removed from favorites",
+			ad:   published,
+			setup: func(adRepo *repoMock.MockAdRepository) {
+				adRepo.EXPECT().SetState(gomock.Any(), int64(10), models.AdArchived, "", int64(3)).Return(&archived, nil)
+			},
+			do: func(ctx context.Context, adService *AdService) error {
+				_, err := adService.ChangeAdStatus(ctx, 10, false, 0)
+				return err
+			},
+			cleanup: true,
+		},
+		{
+			name: "edited ad goes back to draft and is removed from favorites",
+			ad:   published,
+			setup: func(adRepo *repoMock.MockAdRepository) {
+				adRepo.EXPECT().Update(gomock.Any(), int64(10), "title", "text", models.AdDetails{}, models.AdDraft,
+					int64(3)).Return(&draft, nil)
+			},
+			do: func(ctx context.Context, adService *AdService) error {
+				_, err := adService.UpdateAd(ctx, 10, "title", "text", models.AdDetails{}, 0)
+				return err
+			},
+			cleanup: true,
+		},
+		{
+			name: "deleted ad is removed from favorites",
+			ad:   published,
+			setup: func(adRepo *repoMock.MockAdRepository) {
+				adRepo.EXPECT().SetDeletedAt(gomock.Any(), int64(10), gomock.Any()).Return(&published, nil)
+			},
+			do: func(ctx context.Context, adService *AdService) error {
+				return adService.DeleteAd(ctx, 10)
+			},
+			cleanup: true,
+		},
+		{
+			name: "deleted draft can't be in favorites",
+			ad:   draft,
+			setup: func(adRepo *repoMock.MockAdRepository) {
+				adRepo.EXPECT().SetDeletedAt(gomock.Any(), int64(10), gomock.Any()).Return(&draft, nil)
+			},
+			do: func(ctx context.Context, adService *AdService) error {
+				return adService.DeleteAd(ctx, 10)
+			},
+		},
+	}
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			adRepo := repoMock.NewMockAdRepository(ctrl)
+			favoriteRepo := repoMock.NewMockFavoriteRepository(ctrl)
+			adService := NewAdService(adRepo, anyRevisions(ctrl))
+			adService.Favorites = favoriteRepo
+			ctx := auth.WithUserID(context.Background(), 1)
+
+			ad := testCase.ad
+			adRepo.EXPECT().GetAd(ctx, int64(10)).Return(&ad, nil)
+			testCase.setup(adRepo)
+			if testCase.cleanup {
+				favoriteRepo.EXPECT().RemoveAdFavorites(ctx, int64(10)).Return(nil).Times(1)
+			}
+
+			assert.NoError(t, testCase.do(ctx, adService))
+		})
+	}
+}
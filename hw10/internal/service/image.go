@@ -0,0 +1,157 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	"homework10/internal/imaging"
+	"homework10/internal/policy"
+	"io"
+	"log"
+)
+
+const (
+	// DefaultMaxImageSize ограничение размера загружаемого изображения в байтах
+	DefaultMaxImageSize = 10 << 20
+	// DefaultMaxImagePixels ограничение числа пикселей изображения, чтобы декодирование не съело память
+	DefaultMaxImagePixels = 4096 * 4096
+	// DefaultMaxImagesPerAd сколько изображений можно приложить к одному объявлению
+	DefaultMaxImagesPerAd = 10
+	// DefaultThumbnailSize длина большей стороны миниатюры в пикселях
+	DefaultThumbnailSize = 320
+)
+
+// UploadAdImage сохраняет изображение из r в хранилище Images, делает миниатюру и добавляет изображение
+// к объявлению; expectedVersion проверяется так же, как в UpdateAd. Тип файла определяется по содержимому.
+// Как и после изменения текста, объявление становится черновиком и должно снова пройти проверку
+func (s *AdService) UploadAdImage(ctx context.Context, adID int64, r io.Reader, expectedVersion int64) (*models.Ad, error) {
+	ad, err := s.authorizedAd(ctx, adID, policy.UpdateAd)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(ad, expectedVersion); err != nil {
+		return nil, err
+	}
+	if len(ad.Images) >= s.MaxImagesPerAd {
+		return nil, imageError("max_count",
+			fmt.Sprintf("an ad can have at most %d images", s.MaxImagesPerAd))
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, s.MaxImageSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading image: %w", err)
+	}
+	if int64(len(data)) > s.MaxImageSize {
+		return nil, imageError("max", fmt.Sprintf("the image must be at most %d bytes", s.MaxImageSize))
+	}
+	img, contentType, err := imaging.Decode(data, s.MaxImagePixels)
+	var tooLarge imaging.ErrTooLarge
+	switch {
+	case errors.Is(err, imaging.ErrUnsupportedType):
+		return nil, imageError("mime", err.Error())
+	case errors.As(err, &tooLarge):
+		return nil, imageError("max_pixels", err.Error())
+	case err != nil:
+		return nil, imageError("invalid", err.Error())
+	}
+	var thumbnail bytes.Buffer
+	if err := imaging.EncodeThumbnail(&thumbnail, img, s.ThumbnailSize); err != nil {
+		return nil, fmt.Errorf("making thumbnail: %w", err)
+	}
+
+	key, err := imageKey(adID)
+	if err != nil {
+		return nil, err
+	}
+	image := models.AdImage{
+		ContentType:  contentType,
+		Size:         int64(len(data)),
+		Width:        img.Bounds().Dx(),
+		Height:       img.Bounds().Dy(),
+		Key:          key,
+		ThumbnailKey: key + ".thumb",
+		DateCreation: now(),
+	}
+	if err := s.Images.Put(ctx, image.Key, bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("storing image: %w", err)
+	}
+	if err := s.Images.Put(ctx, image.ThumbnailKey, &thumbnail); err != nil {
+		s.deleteImageFiles(ctx, image)
+		return nil, fmt.Errorf("storing thumbnail: %w", err)
+	}
+
+	newAd, err := s.adRepo.AddImage(ctx, adID, image, models.AdDraft, ad.Version)
+	if err != nil {
+		s.deleteImageFiles(ctx, image)
+		return nil, fmt.Errorf("adding image: %w", err)
+	}
+	if err := s.recordRevision(ctx, models.RevisionUpdated, *ad, *newAd, 0); err != nil {
+		return nil, err
+	}
+	if err := s.dropUnpublishedFavorites(ctx, *ad, newAd.Published); err != nil {
+		return nil, err
+	}
+	s.events.Publish(models.AdUpdated, ad, *newAd)
+	return newAd, nil
+}
+
+// OpenAdImage открывает изображение объявления или, если thumbnail, его миниатюру; читатель нужно закрыть
+func (s *AdService) OpenAdImage(ctx context.Context, adID int64, imageID int64, thumbnail bool) (
+	*models.AdImage, io.ReadCloser, error) {
+	ad, err := s.GetAdByID(ctx, adID)
+	if err != nil {
+		return nil, nil, err
+	}
+	image, ok := ad.Image(imageID)
+	if !ok {
+		return nil, nil, domain.ErrImageNotExist
+	}
+	key := image.Key
+	if thumbnail {
+		key = image.ThumbnailKey
+	}
+	r, err := s.Images.Open(ctx, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening image: %w", err)
+	}
+	return image, r, nil
+}
+
+// deleteAdImages удаляет файлы изображений окончательно удаляемого объявления
+func (s *AdService) deleteAdImages(ctx context.Context, ad *models.Ad) error {
+	for _, image := range ad.Images {
+		for _, key := range []string{image.Key, image.ThumbnailKey} {
+			if err := s.Images.Delete(ctx, key); err != nil {
+				return fmt.Errorf("deleting image %d: %w", image.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// deleteImageFiles убирает файлы изображения, которое не удалось добавить к объявлению
+func (s *AdService) deleteImageFiles(ctx context.Context, image models.AdImage) {
+	for _, key := range []string{image.Key, image.ThumbnailKey} {
+		if err := s.Images.Delete(ctx, key); err != nil {
+			log.Printf("deleting orphaned image %s: %s", key, err.Error())
+		}
+	}
+}
+
+// imageKey случайный ключ файла изображения: номер изображения выдаёт хранилище объявлений только после загрузки
+func imageKey(adID int64) (string, error) {
+	suffix := make([]byte, 16)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("generating image key: %w", err)
+	}
+	return fmt.Sprintf("ads/%d/%s", adID, hex.EncodeToString(suffix)), nil
+}
+
+func imageError(rule string, message string) error {
+	return domain.ValidationErrors{{Field: "Image", Rule: rule, Message: message}}
+}
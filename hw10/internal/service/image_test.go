@@ -0,0 +1,224 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"homework10/internal/auth"
+	"homework10/internal/blob"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	repoMock "homework10/internal/service/mock"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testPNG PNG-изображение размером width на height
+func testPNG(t *testing.T, width int, height int) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+func TestUploadAdImage(t *testing.T) {
+	photo := testPNG(t, 800, 400)
+
+	testTable := []struct {
+		name            string
+		userID          int64
+		ad              *models.Ad
+		data            []byte
+		expectedVersion int64
+		wantErr         error
+		wantRule        string
+	}{
+		{
+			name:   "true test UploadAdImage()",
+			userID: 1,
+			ad:     &models.Ad{ID: 5, UserID: 1, State: models.AdPublished, Published: true, Version: 3},
+			data:   photo,
+		},
+		{
+			name:    "the ad of another user",
+			userID:  2,
+			ad:      &models.Ad{ID: 5, UserID: 1, Version: 3},
+			data:    photo,
+			wantErr: ErrNoAccessAd,
+		},
+		{
+			name:            "version mismatch",
+			userID:          1,
+			ad:              &models.Ad{ID: 5, UserID: 1, Version: 3},
+			data:            photo,
+			expectedVersion: 2,
+			wantErr:         domain.ErrAdVersionMismatch,
+		},
+		{
+			name:     "not an image",
+			userID:   1,
+			ad:       &models.Ad{ID: 5, UserID: 1, Version: 3},
+			data:     []byte("hello, world"),
+			wantErr:  domain.ErrValidation,
+			wantRule: "mime",
+		},
+		{
+			name:     "the file is too large",
+			userID:   1,
+			ad:       &models.Ad{ID: 5, UserID: 1, Version: 3},
+			data:     append(photo, make([]byte, 64<<10)...),
+			wantErr:  domain.ErrValidation,
+			wantRule: "max",
+		},
+		{
+			name:     "too many pixels",
+			userID:   1,
+			ad:       &models.Ad{ID: 5, UserID: 1, Version: 3},
+			data:     testPNG(t, 2000, 1000),
+			wantErr:  domain.ErrValidation,
+			wantRule: "max_pixels",
+		},
+		{
+			name:     "truncated image",
+			userID:   1,
+			ad:       &models.Ad{ID: 5, UserID: 1, Version: 3},
+			data:     photo[:len(photo)/2],
+			wantErr:  domain.ErrValidation,
+			wantRule: "invalid",
+		},
+		{
+			name:     "too many images",
+			userID:   1,
+			ad:       &models.Ad{ID: 5, UserID: 1, Version: 3, Images: []models.AdImage{{ID: 1}, {ID: 2}}},
+			data:     photo,
+			wantErr:  domain.ErrValidation,
+			wantRule: "max_count",
+		},
+	}
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			adRepo := repoMock.NewMockAdRepository(ctrl)
+			adService := NewAdService(adRepo, anyRevisions(ctrl))
+			store := blob.NewMemoryStore()
+			adService.Images = store
+			adService.MaxImageSize = 64 << 10
+			adService.MaxImagePixels = 1_000_000
+			adService.MaxImagesPerAd = 2
+			adService.ThumbnailSize = 100
+
+			ctx := auth.WithUserID(context.Background(), testCase.userID)
+			adRepo.EXPECT().GetAd(ctx, testCase.ad.ID).Return(testCase.ad, nil).Times(1)
+			var added models.AdImage
+			if testCase.wantErr == nil {
+				adRepo.EXPECT().AddImage(ctx, testCase.ad.ID, gomock.Any(), models.AdDraft, testCase.ad.Version).DoAndReturn(
+					func(_ context.Context, adID int64, image models.AdImage, state models.AdState, _ int64) (*models.Ad, error) {
+						added = image
+						image.ID = 1
+						return &models.Ad{ID: adID, UserID: 1, State: state, Version: 4, Images: []models.AdImage{image}}, nil
+					}).Times(1)
+			}
+
+			ad, err := adService.UploadAdImage(ctx, testCase.ad.ID, bytes.NewReader(testCase.data), testCase.expectedVersion)
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+				var fieldErrs domain.ValidationErrors
+				if testCase.wantRule != "" && assert.ErrorAs(t, err, &fieldErrs) {
+					assert.Equal(t, "Image", fieldErrs[0].Field)
+					assert.Equal(t, testCase.wantRule, fieldErrs[0].Rule)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, models.AdDraft, ad.State, "the ad with a new image must be reviewed again")
+			assert.Equal(t, "image/png", added.ContentType)
+			assert.Equal(t, int64(len(photo)), added.Size)
+			assert.Equal(t, 800, added.Width)
+			assert.Equal(t, 400, added.Height)
+			assert.True(t, strings.HasPrefix(added.Key, "ads/5/"))
+
+			stored, err := store.Open(ctx, added.Key)
+			require.NoError(t, err)
+			data, _ := io.ReadAll(stored)
+			assert.Equal(t, photo, data)
+
+			stored, err = store.Open(ctx, added.ThumbnailKey)
+			require.NoError(t, err)
+			thumbnail, err := jpeg.Decode(stored)
+			require.NoError(t, err)
+			assert.Equal(t, image.Rect(0, 0, 100, 50), thumbnail.Bounds())
+		})
+	}
+}
+
+func TestUploadAdImage_RemovesFilesOnConflict(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	adRepo := repoMock.NewMockAdRepository(ctrl)
+	adService := NewAdService(adRepo, anyRevisions(ctrl))
+	store := blob.NewMemoryStore()
+	adService.Images = store
+
+	ctx := auth.WithUserID(context.Background(), 1)
+	adRepo.EXPECT().GetAd(ctx, int64(5)).Return(&models.Ad{ID: 5, UserID: 1, Version: 1}, nil).Times(1)
+	var added models.AdImage
+	adRepo.EXPECT().AddImage(ctx, int64(5), gomock.Any(), models.AdDraft, int64(1)).DoAndReturn(
+		func(_ context.Context, _ int64, image models.AdImage, _ models.AdState, _ int64) (*models.Ad, error) {
+			added = image
+			return nil, domain.ErrAdVersionMismatch
+		}).Times(1)
+
+	_, err := adService.UploadAdImage(ctx, 5, bytes.NewReader(testPNG(t, 10, 10)), 0)
+	assert.ErrorIs(t, err, domain.ErrAdVersionMismatch)
+	for _, key := range []string{added.Key, added.ThumbnailKey} {
+		_, err := store.Open(ctx, key)
+		assert.ErrorIs(t, err, blob.ErrNotExist)
+	}
+}
+
+func TestOpenAdImage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	adRepo := repoMock.NewMockAdRepository(ctrl)
+	adService := NewAdService(adRepo, anyRevisions(ctrl))
+	require.NoError(t, adService.Images.Put(ctx, "ads/5/a", strings.NewReader("original")))
+	require.NoError(t, adService.Images.Put(ctx, "ads/5/a.thumb", strings.NewReader("thumbnail")))
+	ad := &models.Ad{ID: 5, Published: true,
+		Images: []models.AdImage{{ID: 1, Key: "ads/5/a", ThumbnailKey: "ads/5/a.thumb"}}}
+	adRepo.EXPECT().GetAd(ctx, int64(5)).Return(ad, nil).AnyTimes()
+
+	image, r, err := adService.OpenAdImage(ctx, 5, 1, false)
+	require.NoError(t, err)
+	data, _ := io.ReadAll(r)
+	assert.Equal(t, int64(1), image.ID)
+	assert.Equal(t, "original", string(data))
+
+	_, r, err = adService.OpenAdImage(ctx, 5, 1, true)
+	require.NoError(t, err)
+	data, _ = io.ReadAll(r)
+	assert.Equal(t, "thumbnail", string(data))
+
+	_, _, err = adService.OpenAdImage(ctx, 5, 2, false)
+	assert.ErrorIs(t, err, domain.ErrImageNotExist)
+
+	adRepo.EXPECT().GetAd(ctx, int64(6)).Return(&models.Ad{ID: 6, DeletedAt: time.Now()}, nil).Times(1)
+	_, _, err = adService.OpenAdImage(ctx, 6, 1, false)
+	assert.ErrorIs(t, err, domain.ErrNotFound, "images of deleted ads are not available")
+
+	draft := &models.Ad{ID: 7, UserID: 1, Images: ad.Images}
+	adRepo.EXPECT().GetAd(gomock.Any(), int64(7)).Return(draft, nil).Times(2)
+	_, _, err = adService.OpenAdImage(ctx, 7, 1, false)
+	assert.ErrorIs(t, err, domain.ErrNotFound, "images of drafts are hidden from other users")
+	_, r, err = adService.OpenAdImage(auth.WithUserID(ctx, 1), 7, 1, false)
+	require.NoError(t, err, "the author sees images of the draft")
+	_ = r.Close()
+}
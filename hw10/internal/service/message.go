@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework10/internal/auth"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	"homework10/internal/policy"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxMessageLen ограничение длины сообщения в символах
+const maxMessageLen = 2000
+
+var ErrNoAccessConversation = errors.New("you don't have access to the conversation")
+
+var (
+	// ErrNotParticipant возвращается, если пользователь не покупатель и не продавец в беседе
+	ErrNotParticipant = ErrNoAccess{Err: errors.New("only the buyer and the seller can access the conversation")}
+	// ErrOwnAdConversation возвращается автору, который пишет об объявлении, не указав беседу с покупателем
+	ErrOwnAdConversation = ErrNoAccess{Err: errors.New("the author can only reply in an existing conversation")}
+	ErrAdNotAvailable    = domain.ErrAlreadyExists{Err: errors.New("conversations can only be started about published ads")}
+	errMessageRequired   = errors.New("message text is required")
+	errMessageTooLong    = fmt.Errorf("message must be at most %d characters", maxMessageLen)
+)
+
+type MessageService struct {
+	MessageRepo domain.MessageRepository
+	// Policy решает, кто может писать сообщения и читать беседы
+	Policy *policy.Policy
+
+	ads *AdService
+}
+
+// NewMessageService создаёт сервис переписки покупателей с авторами объявлений из ads
+func NewMessageService(messageRepo domain.MessageRepository, ads *AdService) *MessageService {
+	return &MessageService{MessageRepo: messageRepo, Policy: policy.Default(), ads: ads}
+}
+
+// SendMessage отправляет сообщение об объявлении от пользователя из контекста. С нулевым conversationID
+// покупатель пишет автору в свою беседу об объявлении, которая создаётся при первом сообщении; начать беседу
+// можно только об опубликованном объявлении. С ненулевым conversationID сообщение добавляется в эту беседу
+// объявления: так автор отвечает покупателю, а покупатель продолжает беседу о снятом с публикации объявлении
+func (s *MessageService) SendMessage(ctx context.Context, adID int64, conversationID int64, text string) (*models.Message, error) {
+	if err := authorize(ctx, s.Policy, policy.SendMessage, noOwner, ErrNoAccess{Err: ErrNoAccessConversation}); err != nil {
+		return nil, err
+	}
+	userID, _ := auth.UserIDFromContext(ctx)
+	text = strings.TrimSpace(text)
+	if err := validateMessage(text); err != nil {
+		return nil, err
+	}
+	// беседу о снятом с публикации объявлении продолжают её участники, поэтому видимость здесь не проверяется
+	ad, err := s.ads.getAd(ctx, adID)
+	if err != nil {
+		return nil, err
+	}
+
+	var conversation *models.Conversation
+	if conversationID == 0 {
+		conversation, err = s.startConversation(ctx, ad, userID)
+	} else {
+		conversation, err = s.conversation(ctx, userID, conversationID)
+		if err == nil && conversation.AdID != adID {
+			err = domain.ErrConversationNotExist
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	message := models.Message{ConversationID: conversation.ID, SenderID: userID, Text: text, DateCreation: now()}
+	id, err := s.MessageRepo.AddMessage(ctx, message)
+	if err != nil {
+		return nil, fmt.Errorf("adding message: %w", err)
+	}
+	message.ID = id
+	return &message, nil
+}
+
+// ListConversations возвращает беседы пользователя, начиная с последних по времени сообщения, с числом
+// непрочитанных им сообщений
+func (s *MessageService) ListConversations(ctx context.Context, userID int64) (*models.Inbox, error) {
+	if err := authorize(ctx, s.Policy, policy.ManageMessages, userID, ErrNoAccess{Err: ErrNoAccessConversation}); err != nil {
+		return nil, err
+	}
+	conversations, err := s.MessageRepo.GetConversations(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	inbox := &models.Inbox{Conversations: make([]*models.ConversationSummary, 0, len(conversations))}
+	for _, conversation := range conversations {
+		messages, err := s.MessageRepo.GetMessages(ctx, conversation.ID)
+		if err != nil {
+			return nil, err
+		}
+		// беседа без сообщений остаётся, если не удалось сохранить первое сообщение
+		if len(messages) == 0 {
+			continue
+		}
+		summary := &models.ConversationSummary{Conversation: conversation, LastMessage: messages[len(messages)-1]}
+		for _, message := range messages {
+			if message.SenderID != userID && !message.IsRead() {
+				summary.UnreadCount++
+			}
+		}
+		inbox.UnreadCount += summary.UnreadCount
+		inbox.Conversations = append(inbox.Conversations, summary)
+	}
+	sort.SliceStable(inbox.Conversations, func(i, j int) bool {
+		return inbox.Conversations[i].Conversation.LastMessageAt.After(inbox.Conversations[j].Conversation.LastMessageAt)
+	})
+	return inbox, nil
+}
+
+// ListMessages возвращает сообщения беседы пользователя в порядке отправки; сообщения не отмечаются прочитанными
+func (s *MessageService) ListMessages(ctx context.Context, userID int64, conversationID int64) ([]*models.Message, error) {
+	if err := authorize(ctx, s.Policy, policy.ManageMessages, userID, ErrNoAccess{Err: ErrNoAccessConversation}); err != nil {
+		return nil, err
+	}
+	if _, err := s.conversation(ctx, userID, conversationID); err != nil {
+		return nil, err
+	}
+	return s.MessageRepo.GetMessages(ctx, conversationID)
+}
+
+// MarkConversationRead отмечает прочитанными сообщения собеседника в беседе пользователя и возвращает их число;
+// собеседник видит момент прочтения в ReadAt своих сообщений
+func (s *MessageService) MarkConversationRead(ctx context.Context, userID int64, conversationID int64) (int, error) {
+	if err := authorize(ctx, s.Policy, policy.ManageMessages, userID, ErrNoAccess{Err: ErrNoAccessConversation}); err != nil {
+		return 0, err
+	}
+	if _, err := s.conversation(ctx, userID, conversationID); err != nil {
+		return 0, err
+	}
+	return s.MessageRepo.MarkRead(ctx, conversationID, userID, now())
+}
+
+// startConversation возвращает беседу покупателя buyerID об объявлении, при необходимости создавая её
+func (s *MessageService) startConversation(ctx context.Context, ad *models.Ad, buyerID int64) (*models.Conversation, error) {
+	if ad.UserID == buyerID {
+		return nil, ErrOwnAdConversation
+	}
+	if !ad.Published {
+		return nil, ErrAdNotAvailable
+	}
+	return s.MessageRepo.GetOrAddConversation(ctx, models.Conversation{AdID: ad.ID, BuyerID: buyerID,
+		SellerID: ad.UserID, DateCreation: now()})
+}
+
+// conversation возвращает беседу, если пользователь её участник
+func (s *MessageService) conversation(ctx context.Context, userID int64, conversationID int64) (*models.Conversation, error) {
+	conversation, err := s.MessageRepo.GetConversation(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if !conversation.HasParticipant(userID) {
+		return nil, ErrNotParticipant
+	}
+	return conversation, nil
+}
+
+func validateMessage(text string) error {
+	switch {
+	case text == "":
+		return domain.ValidationErrors{{Field: "Text", Rule: "required", Message: errMessageRequired.Error()}}
+	case utf8.RuneCountInString(text) > maxMessageLen:
+		return domain.ValidationErrors{{Field: "Text", Rule: "max", Message: errMessageTooLong.Error()}}
+	}
+	return nil
+}
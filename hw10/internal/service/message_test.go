@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"homework10/internal/auth"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	repoMock "homework10/internal/service/mock"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSendMessage(t *testing.T) {
+	published := &models.Ad{ID: 10, UserID: 1, Published: true, State: models.AdPublished}
+	archived := &models.Ad{ID: 10, UserID: 1, State: models.AdArchived}
+	conversation := &models.Conversation{ID: 5, AdID: 10, BuyerID: 2, SellerID: 1}
+
+	testTable := []struct {
+		name           string
+		userID         int64
+		conversationID int64
+		text           string
+		ad             *models.Ad
+		conversation   *models.Conversation
+		startsNew      bool
+		wantErr        error
+	}{
+		{
+			name:         "buyer starts a conversation",
+			userID:       2,
+			text:         " hello ",
+			ad:           published,
+			conversation: conversation,
+			startsNew:    true,
+		},
+		{
+			name:           "seller replies",
+			userID:         1,
+			conversationID: 5,
+			text:           "hello",
+			ad:             archived,
+			conversation:   conversation,
+		},
+		{
+			name:    "author can't start a conversation",
+			userID:  1,
+			text:    "hello",
+			ad:      published,
+			wantErr: ErrOwnAdConversation,
+		},
+		{
+			name:    "the ad is not published",
+			userID:  2,
+			text:    "hello",
+			ad:      archived,
+			wantErr: domain.ErrConflict,
+		},
+		{
+			name:           "not a participant",
+			userID:         3,
+			conversationID: 5,
+			text:           "hello",
+			ad:             published,
+			conversation:   conversation,
+			wantErr:        domain.ErrForbidden,
+		},
+		{
+			name:           "the conversation is about another ad",
+			userID:         2,
+			conversationID: 5,
+			text:           "hello",
+			ad:             &models.Ad{ID: 10, UserID: 1, Published: true},
+			conversation:   &models.Conversation{ID: 5, AdID: 11, BuyerID: 2, SellerID: 1},
+			wantErr:        domain.ErrConversationNotExist,
+		},
+		{
+			name:    "empty message",
+			userID:  2,
+			text:    "  ",
+			wantErr: domain.ErrValidation,
+		},
+		{
+			name:    "too long message",
+			userID:  2,
+			text:    strings.Repeat("a", maxMessageLen+1),
+			wantErr: domain.ErrValidation,
+		},
+	}
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			adRepo := repoMock.NewMockAdRepository(ctrl)
+			messageRepo := repoMock.NewMockMessageRepository(ctrl)
+			messageService := NewMessageService(messageRepo, NewAdService(adRepo, anyRevisions(ctrl)))
+			ctx := auth.WithUserID(context.Background(), testCase.userID)
+
+			if testCase.ad != nil {
+				adRepo.EXPECT().GetAd(ctx, int64(10)).Return(testCase.ad, nil).Times(1)
+			}
+			if testCase.startsNew {
+				messageRepo.EXPECT().GetOrAddConversation(ctx, gomock.Any()).DoAndReturn(
+					func(_ context.Context, conversation models.Conversation) (*models.Conversation, error) {
+						assert.Equal(t, int64(10), conversation.AdID)
+						assert.Equal(t, testCase.userID, conversation.BuyerID)
+						assert.Equal(t, int64(1), conversation.SellerID)
+						return testCase.conversation, nil
+					}).Times(1)
+			} else if testCase.conversationID != 0 {
+				messageRepo.EXPECT().GetConversation(ctx, testCase.conversationID).Return(testCase.conversation, nil).Times(1)
+			}
+			if testCase.wantErr == nil {
+				messageRepo.EXPECT().AddMessage(ctx, gomock.Any()).Return(int64(7), nil).Times(1)
+			}
+
+			message, err := messageService.SendMessage(ctx, 10, testCase.conversationID, testCase.text)
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, int64(7), message.ID)
+			assert.Equal(t, int64(5), message.ConversationID)
+			assert.Equal(t, testCase.userID, message.SenderID)
+			assert.Equal(t, strings.TrimSpace(testCase.text), message.Text)
+		})
+	}
+}
+
+func TestListConversations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	messageRepo := repoMock.NewMockMessageRepository(ctrl)
+	messageService := NewMessageService(messageRepo, NewAdService(repoMock.NewMockAdRepository(ctrl), anyRevisions(ctrl)))
+	ctx := auth.WithUserID(context.Background(), 1)
+	start := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	older := &models.Conversation{ID: 1, AdID: 10, BuyerID: 2, SellerID: 1, LastMessageAt: start}
+	newer := &models.Conversation{ID: 2, AdID: 11, BuyerID: 1, SellerID: 3, LastMessageAt: start.Add(time.Hour)}
+	empty := &models.Conversation{ID: 3, AdID: 12, BuyerID: 4, SellerID: 1}
+	olderMessages := []*models.Message{
+		{ID: 1, ConversationID: 1, SenderID: 2, Text: "hello", ReadAt: start},
+		{ID: 2, ConversationID: 1, SenderID: 2, Text: "are you there?"},
+		{ID: 3, ConversationID: 1, SenderID: 2, Text: "hello?"},
+	}
+	newerMessages := []*models.Message{
+		{ID: 4, ConversationID: 2, SenderID: 1, Text: "is it available?"},
+		{ID: 5, ConversationID: 2, SenderID: 3, Text: "yes"},
+	}
+	messageRepo.EXPECT().GetConversations(ctx, int64(1)).Return([]*models.Conversation{older, newer, empty}, nil).Times(1)
+	messageRepo.EXPECT().GetMessages(ctx, int64(1)).Return(olderMessages, nil).Times(1)
+	messageRepo.EXPECT().GetMessages(ctx, int64(2)).Return(newerMessages, nil).Times(1)
+	messageRepo.EXPECT().GetMessages(ctx, int64(3)).Return([]*models.Message{}, nil).Times(1)
+
+	inbox, err := messageService.ListConversations(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, &models.Inbox{
+		Conversations: []*models.ConversationSummary{
+			{Conversation: newer, LastMessage: newerMessages[1], UnreadCount: 1},
+			{Conversation: older, LastMessage: olderMessages[2], UnreadCount: 2},
+		},
+		UnreadCount: 3,
+	}, inbox)
+
+	_, err = messageService.ListConversations(ctx, 2)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+}
+
+func TestConversationAccess(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	messageRepo := repoMock.NewMockMessageRepository(ctrl)
+	messageService := NewMessageService(messageRepo, NewAdService(repoMock.NewMockAdRepository(ctrl), anyRevisions(ctrl)))
+	conversation := &models.Conversation{ID: 5, AdID: 10, BuyerID: 2, SellerID: 1}
+	messageRepo.EXPECT().GetConversation(gomock.Any(), int64(5)).Return(conversation, nil).AnyTimes()
+	messageRepo.EXPECT().GetConversation(gomock.Any(), int64(6)).Return(nil, domain.ErrConversationNotExist).AnyTimes()
+
+	sellerCtx := auth.WithUserID(context.Background(), 1)
+	messages := []*models.Message{{ID: 1, ConversationID: 5, SenderID: 2, Text: "hello"}}
+	messageRepo.EXPECT().GetMessages(sellerCtx, int64(5)).Return(messages, nil).Times(1)
+	got, err := messageService.ListMessages(sellerCtx, 1, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, messages, got)
+
+	messageRepo.EXPECT().MarkRead(sellerCtx, int64(5), int64(1), gomock.Any()).Return(1, nil).Times(1)
+	marked, err := messageService.MarkConversationRead(sellerCtx, 1, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, marked)
+
+	strangerCtx := auth.WithUserID(context.Background(), 3)
+	_, err = messageService.ListMessages(strangerCtx, 3, 5)
+	require.ErrorIs(t, err, domain.ErrForbidden)
+	assert.Equal(t, ErrNotParticipant, err)
+	_, err = messageService.MarkConversationRead(strangerCtx, 3, 5)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	_, err = messageService.ListMessages(sellerCtx, 2, 5)
+	assert.ErrorIs(t, err, domain.ErrForbidden, "messages of another user")
+	_, err = messageService.ListMessages(sellerCtx, 1, 6)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
@@ -8,6 +8,7 @@ import (
 	context "context"
 	models "homework10/internal/domain/models"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -50,6 +51,52 @@ func (mr *MockAdRepositoryMockRecorder) AddAd(ctx, ad interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAd", reflect.TypeOf((*MockAdRepository)(nil).AddAd), ctx, ad)
 }
 
+// AddImage mocks base method.
+func (m *MockAdRepository) AddImage(ctx context.Context, adID int64, image models.AdImage, state models.AdState, version int64) (*models.Ad, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddImage", ctx, adID, image, state, version)
+	ret0, _ := ret[0].(*models.Ad)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddImage indicates an expected call of AddImage.
+func (mr *MockAdRepositoryMockRecorder) AddImage(ctx, adID, image, state, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddImage", reflect.TypeOf((*MockAdRepository)(nil).AddImage), ctx, adID, image, state, version)
+}
+
+// CountAds mocks base method.
+func (m *MockAdRepository) CountAds(ctx context.Context) (int, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountAds", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CountAds indicates an expected call of CountAds.
+func (mr *MockAdRepositoryMockRecorder) CountAds(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountAds", reflect.TypeOf((*MockAdRepository)(nil).CountAds), ctx)
+}
+
+// CountFacets mocks base method.
+func (m *MockAdRepository) CountFacets(ctx context.Context, filter models.AdFilter) (models.AdFacets, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountFacets", ctx, filter)
+	ret0, _ := ret[0].(models.AdFacets)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountFacets indicates an expected call of CountFacets.
+func (mr *MockAdRepositoryMockRecorder) CountFacets(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountFacets", reflect.TypeOf((*MockAdRepository)(nil).CountFacets), ctx, filter)
+}
+
 // DeleteAd mocks base method.
 func (m *MockAdRepository) DeleteAd(ctx context.Context, adID int64) error {
 	m.ctrl.T.Helper()
@@ -64,6 +111,21 @@ func (mr *MockAdRepositoryMockRecorder) DeleteAd(ctx, adID interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAd", reflect.TypeOf((*MockAdRepository)(nil).DeleteAd), ctx, adID)
 }
 
+// FindAds mocks base method.
+func (m *MockAdRepository) FindAds(ctx context.Context, filter models.AdFilter) ([]*models.Ad, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAds", ctx, filter)
+	ret0, _ := ret[0].([]*models.Ad)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAds indicates an expected call of FindAds.
+func (mr *MockAdRepositoryMockRecorder) FindAds(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAds", reflect.TypeOf((*MockAdRepository)(nil).FindAds), ctx, filter)
+}
+
 // GetAd mocks base method.
 func (m *MockAdRepository) GetAd(ctx context.Context, adID int64) (*models.Ad, error) {
 	m.ctrl.T.Helper()
@@ -94,32 +156,47 @@ func (mr *MockAdRepositoryMockRecorder) GetAds(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAds", reflect.TypeOf((*MockAdRepository)(nil).GetAds), ctx)
 }
 
-// SetStatus mocks base method.
-func (m *MockAdRepository) SetStatus(ctx context.Context, adID int64, published bool) (*models.Ad, error) {
+// SetDeletedAt mocks base method.
+func (m *MockAdRepository) SetDeletedAt(ctx context.Context, adID int64, deletedAt time.Time) (*models.Ad, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDeletedAt", ctx, adID, deletedAt)
+	ret0, _ := ret[0].(*models.Ad)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetDeletedAt indicates an expected call of SetDeletedAt.
+func (mr *MockAdRepositoryMockRecorder) SetDeletedAt(ctx, adID, deletedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDeletedAt", reflect.TypeOf((*MockAdRepository)(nil).SetDeletedAt), ctx, adID, deletedAt)
+}
+
+// SetState mocks base method.
+func (m *MockAdRepository) SetState(ctx context.Context, adID int64, state models.AdState, reason string, version int64) (*models.Ad, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetStatus", ctx, adID, published)
+	ret := m.ctrl.Call(m, "SetState", ctx, adID, state, reason, version)
 	ret0, _ := ret[0].(*models.Ad)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SetStatus indicates an expected call of SetStatus.
-func (mr *MockAdRepositoryMockRecorder) SetStatus(ctx, adID, published interface{}) *gomock.Call {
+// SetState indicates an expected call of SetState.
+func (mr *MockAdRepositoryMockRecorder) SetState(ctx, adID, state, reason, version interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStatus", reflect.TypeOf((*MockAdRepository)(nil).SetStatus), ctx, adID, published)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetState", reflect.TypeOf((*MockAdRepository)(nil).SetState), ctx, adID, state, reason, version)
 }
 
 // Update mocks base method.
-func (m *MockAdRepository) Update(ctx context.Context, adID int64, title, text string) (*models.Ad, error) {
+func (m *MockAdRepository) Update(ctx context.Context, adID int64, title, text string, details models.AdDetails, state models.AdState, version int64) (*models.Ad, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", ctx, adID, title, text)
+	ret := m.ctrl.Call(m, "Update", ctx, adID, title, text, details, state, version)
 	ret0, _ := ret[0].(*models.Ad)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockAdRepositoryMockRecorder) Update(ctx, adID, title, text interface{}) *gomock.Call {
+func (mr *MockAdRepositoryMockRecorder) Update(ctx, adID, title, text, details, state, version interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockAdRepository)(nil).Update), ctx, adID, title, text)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockAdRepository)(nil).Update), ctx, adID, title, text, details, state, version)
 }
@@ -0,0 +1,107 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./favorite.go
+
+// Package repoMock is a generated GoMock package.
+package repoMock
+
+import (
+	context "context"
+	models "homework10/internal/domain/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockFavoriteRepository is a mock of FavoriteRepository interface.
+type MockFavoriteRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFavoriteRepositoryMockRecorder
+}
+
+// MockFavoriteRepositoryMockRecorder is the mock recorder for MockFavoriteRepository.
+type MockFavoriteRepositoryMockRecorder struct {
+	mock *MockFavoriteRepository
+}
+
+// NewMockFavoriteRepository creates a new mock instance.
+func NewMockFavoriteRepository(ctrl *gomock.Controller) *MockFavoriteRepository {
+	mock := &MockFavoriteRepository{ctrl: ctrl}
+	mock.recorder = &MockFavoriteRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFavoriteRepository) EXPECT() *MockFavoriteRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddFavorite mocks base method.
+func (m *MockFavoriteRepository) AddFavorite(ctx context.Context, favorite models.Favorite) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddFavorite", ctx, favorite)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddFavorite indicates an expected call of AddFavorite.
+func (mr *MockFavoriteRepositoryMockRecorder) AddFavorite(ctx, favorite interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddFavorite", reflect.TypeOf((*MockFavoriteRepository)(nil).AddFavorite), ctx, favorite)
+}
+
+// GetFavorites mocks base method.
+func (m *MockFavoriteRepository) GetFavorites(ctx context.Context, userID int64) ([]models.Favorite, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFavorites", ctx, userID)
+	ret0, _ := ret[0].([]models.Favorite)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFavorites indicates an expected call of GetFavorites.
+func (mr *MockFavoriteRepositoryMockRecorder) GetFavorites(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFavorites", reflect.TypeOf((*MockFavoriteRepository)(nil).GetFavorites), ctx, userID)
+}
+
+// RemoveAdFavorites mocks base method.
+func (m *MockFavoriteRepository) RemoveAdFavorites(ctx context.Context, adID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveAdFavorites", ctx, adID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveAdFavorites indicates an expected call of RemoveAdFavorites.
+func (mr *MockFavoriteRepositoryMockRecorder) RemoveAdFavorites(ctx, adID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveAdFavorites", reflect.TypeOf((*MockFavoriteRepository)(nil).RemoveAdFavorites), ctx, adID)
+}
+
+// RemoveFavorite mocks base method.
+func (m *MockFavoriteRepository) RemoveFavorite(ctx context.Context, userID, adID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveFavorite", ctx, userID, adID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveFavorite indicates an expected call of RemoveFavorite.
+func (mr *MockFavoriteRepositoryMockRecorder) RemoveFavorite(ctx, userID, adID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveFavorite", reflect.TypeOf((*MockFavoriteRepository)(nil).RemoveFavorite), ctx, userID, adID)
+}
+
+// RemoveUserFavorites mocks base method.
+func (m *MockFavoriteRepository) RemoveUserFavorites(ctx context.Context, userID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveUserFavorites", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveUserFavorites indicates an expected call of RemoveUserFavorites.
+func (mr *MockFavoriteRepositoryMockRecorder) RemoveUserFavorites(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveUserFavorites", reflect.TypeOf((*MockFavoriteRepository)(nil).RemoveUserFavorites), ctx, userID)
+}
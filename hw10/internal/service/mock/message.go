@@ -0,0 +1,127 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./message.go
+
+// Package repoMock is a generated GoMock package.
+package repoMock
+
+import (
+	context "context"
+	models "homework10/internal/domain/models"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockMessageRepository is a mock of MessageRepository interface.
+type MockMessageRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMessageRepositoryMockRecorder
+}
+
+// MockMessageRepositoryMockRecorder is the mock recorder for MockMessageRepository.
+type MockMessageRepositoryMockRecorder struct {
+	mock *MockMessageRepository
+}
+
+// NewMockMessageRepository creates a new mock instance.
+func NewMockMessageRepository(ctrl *gomock.Controller) *MockMessageRepository {
+	mock := &MockMessageRepository{ctrl: ctrl}
+	mock.recorder = &MockMessageRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMessageRepository) EXPECT() *MockMessageRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddMessage mocks base method.
+func (m *MockMessageRepository) AddMessage(ctx context.Context, message models.Message) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddMessage", ctx, message)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddMessage indicates an expected call of AddMessage.
+func (mr *MockMessageRepositoryMockRecorder) AddMessage(ctx, message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMessage", reflect.TypeOf((*MockMessageRepository)(nil).AddMessage), ctx, message)
+}
+
+// GetConversation mocks base method.
+func (m *MockMessageRepository) GetConversation(ctx context.Context, conversationID int64) (*models.Conversation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConversation", ctx, conversationID)
+	ret0, _ := ret[0].(*models.Conversation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConversation indicates an expected call of GetConversation.
+func (mr *MockMessageRepositoryMockRecorder) GetConversation(ctx, conversationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConversation", reflect.TypeOf((*MockMessageRepository)(nil).GetConversation), ctx, conversationID)
+}
+
+// GetConversations mocks base method.
+func (m *MockMessageRepository) GetConversations(ctx context.Context, userID int64) ([]*models.Conversation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConversations", ctx, userID)
+	ret0, _ := ret[0].([]*models.Conversation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConversations indicates an expected call of GetConversations.
+func (mr *MockMessageRepositoryMockRecorder) GetConversations(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConversations", reflect.TypeOf((*MockMessageRepository)(nil).GetConversations), ctx, userID)
+}
+
+// GetMessages mocks base method.
+func (m *MockMessageRepository) GetMessages(ctx context.Context, conversationID int64) ([]*models.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMessages", ctx, conversationID)
+	ret0, _ := ret[0].([]*models.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMessages indicates an expected call of GetMessages.
+func (mr *MockMessageRepositoryMockRecorder) GetMessages(ctx, conversationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessages", reflect.TypeOf((*MockMessageRepository)(nil).GetMessages), ctx, conversationID)
+}
+
+// GetOrAddConversation mocks base method.
+func (m *MockMessageRepository) GetOrAddConversation(ctx context.Context, conversation models.Conversation) (*models.Conversation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrAddConversation", ctx, conversation)
+	ret0, _ := ret[0].(*models.Conversation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrAddConversation indicates an expected call of GetOrAddConversation.
+func (mr *MockMessageRepositoryMockRecorder) GetOrAddConversation(ctx, conversation interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrAddConversation", reflect.TypeOf((*MockMessageRepository)(nil).GetOrAddConversation), ctx, conversation)
+}
+
+// MarkRead mocks base method.
+func (m *MockMessageRepository) MarkRead(ctx context.Context, conversationID, readerID int64, readAt time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRead", ctx, conversationID, readerID, readAt)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkRead indicates an expected call of MarkRead.
+func (mr *MockMessageRepositoryMockRecorder) MarkRead(ctx, conversationID, readerID, readAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRead", reflect.TypeOf((*MockMessageRepository)(nil).MarkRead), ctx, conversationID, readerID, readAt)
+}
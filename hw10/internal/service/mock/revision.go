@@ -0,0 +1,95 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./revision.go
+
+// Package repoMock is a generated GoMock package.
+package repoMock
+
+import (
+	context "context"
+	models "homework10/internal/domain/models"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAdRevisionRepository is a mock of AdRevisionRepository interface.
+type MockAdRevisionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdRevisionRepositoryMockRecorder
+}
+
+// MockAdRevisionRepositoryMockRecorder is the mock recorder for MockAdRevisionRepository.
+type MockAdRevisionRepositoryMockRecorder struct {
+	mock *MockAdRevisionRepository
+}
+
+// NewMockAdRevisionRepository creates a new mock instance.
+func NewMockAdRevisionRepository(ctrl *gomock.Controller) *MockAdRevisionRepository {
+	mock := &MockAdRevisionRepository{ctrl: ctrl}
+	mock.recorder = &MockAdRevisionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdRevisionRepository) EXPECT() *MockAdRevisionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddRevision mocks base method.
+func (m *MockAdRevisionRepository) AddRevision(ctx context.Context, revision models.AdRevision) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRevision", ctx, revision)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddRevision indicates an expected call of AddRevision.
+func (mr *MockAdRevisionRepositoryMockRecorder) AddRevision(ctx, revision interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRevision", reflect.TypeOf((*MockAdRevisionRepository)(nil).AddRevision), ctx, revision)
+}
+
+// DeleteRevisions mocks base method.
+func (m *MockAdRevisionRepository) DeleteRevisions(ctx context.Context, adID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRevisions", ctx, adID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRevisions indicates an expected call of DeleteRevisions.
+func (mr *MockAdRevisionRepositoryMockRecorder) DeleteRevisions(ctx, adID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRevisions", reflect.TypeOf((*MockAdRevisionRepository)(nil).DeleteRevisions), ctx, adID)
+}
+
+// GetRevision mocks base method.
+func (m *MockAdRevisionRepository) GetRevision(ctx context.Context, adID, number int64) (*models.AdRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevision", ctx, adID, number)
+	ret0, _ := ret[0].(*models.AdRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevision indicates an expected call of GetRevision.
+func (mr *MockAdRevisionRepositoryMockRecorder) GetRevision(ctx, adID, number interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevision", reflect.TypeOf((*MockAdRevisionRepository)(nil).GetRevision), ctx, adID, number)
+}
+
+// GetRevisions mocks base method.
+func (m *MockAdRevisionRepository) GetRevisions(ctx context.Context, adID int64) ([]*models.AdRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevisions", ctx, adID)
+	ret0, _ := ret[0].([]*models.AdRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevisions indicates an expected call of GetRevisions.
+func (mr *MockAdRevisionRepositoryMockRecorder) GetRevisions(ctx, adID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevisions", reflect.TypeOf((*MockAdRevisionRepository)(nil).GetRevisions), ctx, adID)
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework10/internal/auth"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	"homework10/internal/policy"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxRejectionReasonLen ограничение длины причины отклонения в символах
+const maxRejectionReasonLen = 1000
+
+var (
+	ErrNotModerator   = ErrNoAccess{Err: errors.New("only moderators can review ads")}
+	ErrOwnAdReview    = ErrNoAccess{Err: errors.New("moderators can't review their own ads")}
+	ErrAdNotApproved  = domain.ErrAlreadyExists{Err: errors.New("the ad must be approved by a moderator before publishing")}
+	ErrAdNotDraft     = domain.ErrAlreadyExists{Err: errors.New("only drafts and rejected ads can be submitted for review")}
+	ErrAdNotInReview  = domain.ErrAlreadyExists{Err: errors.New("the ad is not waiting for review")}
+	errReasonRequired = errors.New("rejection reason is required")
+	errReasonTooLong  = fmt.Errorf("rejection reason must be at most %d characters", maxRejectionReasonLen)
+)
+
+// SubmitAd отправляет черновик или отклонённое объявление автора на проверку модератору
+func (s *AdService) SubmitAd(ctx context.Context, adID int64, expectedVersion int64) (*models.Ad, error) {
+	ad, err := s.authorizedAd(ctx, adID, policy.SubmitAd)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(ad, expectedVersion); err != nil {
+		return nil, err
+	}
+	if ad.State != models.AdDraft && ad.State != models.AdRejected {
+		return nil, ErrAdNotDraft
+	}
+	return s.setState(ctx, *ad, models.AdPendingReview, "", models.AdModerated)
+}
+
+// ApproveAd одобряет объявление, ожидающее проверки; после этого автор может его опубликовать
+func (s *AdService) ApproveAd(ctx context.Context, adID int64, expectedVersion int64) (*models.Ad, error) {
+	ad, err := s.reviewedAd(ctx, adID, expectedVersion)
+	if err != nil {
+		return nil, err
+	}
+	return s.setState(ctx, *ad, models.AdApproved, "", models.AdModerated)
+}
+
+// RejectAd отклоняет объявление, ожидающее проверки, с причиной, которую увидит автор
+func (s *AdService) RejectAd(ctx context.Context, adID int64, reason string, expectedVersion int64) (*models.Ad, error) {
+	reason = strings.TrimSpace(reason)
+	if err := validateRejectionReason(reason); err != nil {
+		return nil, err
+	}
+	ad, err := s.reviewedAd(ctx, adID, expectedVersion)
+	if err != nil {
+		return nil, err
+	}
+	return s.setState(ctx, *ad, models.AdRejected, reason, models.AdModerated)
+}
+
+// ListModerationQueue возвращает объявления, ожидающие проверки, начиная с давно отправленных
+func (s *AdService) ListModerationQueue(ctx context.Context) ([]*models.Ad, error) {
+	if err := authorize(ctx, s.Policy, policy.ReviewAd, noOwner, ErrNotModerator); err != nil {
+		return nil, err
+	}
+	ads, err := s.adRepo.FindAds(ctx, models.AdFilter{State: models.AdPendingReview})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(ads, func(i, j int) bool {
+		return ads[i].DateUpdate.Before(ads[j].DateUpdate)
+	})
+	return ads, nil
+}
+
+// reviewedAd возвращает объявление, ожидающее проверки, если пользователь из контекста может проверять объявления
+// и не является его автором
+func (s *AdService) reviewedAd(ctx context.Context, adID int64, expectedVersion int64) (*models.Ad, error) {
+	if err := authorize(ctx, s.Policy, policy.ReviewAd, noOwner, ErrNotModerator); err != nil {
+		return nil, err
+	}
+	ad, err := s.getAd(ctx, adID)
+	if err != nil {
+		return nil, err
+	}
+	if userID, _ := auth.UserIDFromContext(ctx); ad.UserID == userID {
+		return nil, ErrOwnAdReview
+	}
+	if err := checkVersion(ad, expectedVersion); err != nil {
+		return nil, err
+	}
+	if ad.State != models.AdPendingReview {
+		return nil, ErrAdNotInReview
+	}
+	return ad, nil
+}
+
+// setState переводит объявление в state, записывает ревизию и публикует событие eventType
+func (s *AdService) setState(ctx context.Context, old models.Ad, state models.AdState, reason string,
+	eventType models.AdEventType) (*models.Ad, error) {
+	newAd, err := s.adRepo.SetState(ctx, old.ID, state, reason, old.Version)
+	if err != nil {
+		return nil, fmt.Errorf("setting ad state: %w", err)
+	}
+	if err := s.recordRevision(ctx, models.RevisionStatusChanged, old, *newAd, 0); err != nil {
+		return nil, err
+	}
+	if err := s.dropUnpublishedFavorites(ctx, old, newAd.Published); err != nil {
+		return nil, err
+	}
+	s.events.Publish(eventType, &old, *newAd)
+	return newAd, nil
+}
+
+func validateRejectionReason(reason string) error {
+	switch {
+	case reason == "":
+		return domain.ValidationErrors{{Field: "Reason", Rule: "required", Message: errReasonRequired.Error()}}
+	case utf8.RuneCountInString(reason) > maxRejectionReasonLen:
+		return domain.ValidationErrors{{Field: "Reason", Rule: "max", Message: errReasonTooLong.Error()}}
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"homework10/internal/auth"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	repoMock "homework10/internal/service/mock"
+	"strings"
+	"testing"
+	"time"
+)
+
+func moderatorContext(userID int64) context.Context {
+	return auth.WithRole(auth.WithUserID(context.Background(), userID), models.RoleModerator)
+}
+
+func TestSubmitAd(t *testing.T) {
+	testTable := []struct {
+		name    string
+		userID  int64
+		state   models.AdState
+		wantErr error
+	}{
+		{
+			name:   "draft",
+			userID: 1,
+			state:  models.AdDraft,
+		},
+		{
+			name:   "rejected ad is resubmitted",
+			userID: 1,
+			state:  models.AdRejected,
+		},
+		{
+			name:    "already approved",
+			userID:  1,
+			state:   models.AdApproved,
+			wantErr: domain.ErrConflict,
+		},
+		{
+			name:    "already in review",
+			userID:  1,
+			state:   models.AdPendingReview,
+			wantErr: domain.ErrConflict,
+		},
+		{
+			name:    "not the author",
+			userID:  2,
+			state:   models.AdDraft,
+			wantErr: domain.ErrForbidden,
+		},
+	}
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			adRepo := repoMock.NewMockAdRepository(ctrl)
+			adService := NewAdService(adRepo, anyRevisions(ctrl))
+			ctx := auth.WithUserID(context.Background(), testCase.userID)
+			adRepo.EXPECT().GetAd(ctx, int64(5)).
+				Return(&models.Ad{ID: 5, UserID: 1, State: testCase.state, RejectionReason: "spam"}, nil).Times(1)
+			if testCase.wantErr == nil {
+				adRepo.EXPECT().SetState(ctx, int64(5), models.AdPendingReview, "", int64(0)).
+					Return(&models.Ad{ID: 5, UserID: 1, State: models.AdPendingReview}, nil).Times(1)
+			}
+
+			ad, err := adService.SubmitAd(ctx, 5, 0)
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+				assert.Nil(t, ad)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, models.AdPendingReview, ad.State)
+		})
+	}
+}
+
+func TestReviewAd(t *testing.T) {
+	testTable := []struct {
+		name      string
+		ctx       context.Context
+		state     models.AdState
+		reject    bool
+		reason    string
+		wantState models.AdState
+		wantErr   error
+	}{
+		{
+			name:      "approve",
+			ctx:       moderatorContext(2),
+			state:     models.AdPendingReview,
+			wantState: models.AdApproved,
+		},
+		{
+			name:      "reject",
+			ctx:       moderatorContext(2),
+			state:     models.AdPendingReview,
+			reject:    true,
+			reason:    "  spam  ",
+			wantState: models.AdRejected,
+		},
+		{
+			name:    "reject without reason",
+			ctx:     moderatorContext(2),
+			state:   models.AdPendingReview,
+			reject:  true,
+			reason:  " ",
+			wantErr: domain.ErrValidation,
+		},
+		{
+			name:    "reject with too long reason",
+			ctx:     moderatorContext(2),
+			state:   models.AdPendingReview,
+			reject:  true,
+			reason:  strings.Repeat("a", maxRejectionReasonLen+1),
+			wantErr: domain.ErrValidation,
+		},
+		{
+			name:    "not a moderator",
+			ctx:     auth.WithUserID(context.Background(), 2),
+			state:   models.AdPendingReview,
+			wantErr: domain.ErrForbidden,
+		},
+		{
+			name:    "own ad",
+			ctx:     moderatorContext(1),
+			state:   models.AdPendingReview,
+			wantErr: domain.ErrForbidden,
+		},
+		{
+			name:    "not submitted",
+			ctx:     moderatorContext(2),
+			state:   models.AdDraft,
+			wantErr: domain.ErrConflict,
+		},
+		{
+			name:    "not authenticated",
+			ctx:     context.Background(),
+			state:   models.AdPendingReview,
+			wantErr: domain.ErrUnauthenticated,
+		},
+	}
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			adRepo := repoMock.NewMockAdRepository(ctrl)
+			adService := NewAdService(adRepo, anyRevisions(ctrl))
+			ctx := testCase.ctx
+			adRepo.EXPECT().GetAd(ctx, int64(5)).
+				Return(&models.Ad{ID: 5, UserID: 1, State: testCase.state}, nil).MaxTimes(1)
+			if testCase.wantErr == nil {
+				adRepo.EXPECT().SetState(ctx, int64(5), testCase.wantState, strings.TrimSpace(testCase.reason), int64(0)).
+					Return(&models.Ad{ID: 5, UserID: 1, State: testCase.wantState}, nil).Times(1)
+			}
+
+			var ad *models.Ad
+			var err error
+			if testCase.reject {
+				ad, err = adService.RejectAd(ctx, 5, testCase.reason, 0)
+			} else {
+				ad, err = adService.ApproveAd(ctx, 5, 0)
+			}
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+				assert.Nil(t, ad)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.wantState, ad.State)
+		})
+	}
+}
+
+func TestChangeAdStatus_RequiresApproval(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := auth.WithUserID(context.Background(), 1)
+	adRepo := repoMock.NewMockAdRepository(ctrl)
+	adService := NewAdService(adRepo, anyRevisions(ctrl))
+
+	for _, state := range []models.AdState{models.AdDraft, models.AdPendingReview, models.AdRejected} {
+		adRepo.EXPECT().GetAd(ctx, int64(5)).Return(&models.Ad{ID: 5, UserID: 1, State: state}, nil).Times(1)
+		_, err := adService.ChangeAdStatus(ctx, 5, true, 0)
+		assert.ErrorIs(t, err, ErrAdNotApproved)
+	}
+
+	// снятое с публикации объявление можно вернуть без повторной проверки
+	adRepo.EXPECT().GetAd(ctx, int64(5)).Return(&models.Ad{ID: 5, UserID: 1, State: models.AdArchived}, nil).Times(1)
+	adRepo.EXPECT().SetState(ctx, int64(5), models.AdPublished, "", int64(0)).
+		Return(&models.Ad{ID: 5, UserID: 1, State: models.AdPublished, Published: true}, nil).Times(1)
+	ad, err := adService.ChangeAdStatus(ctx, 5, true, 0)
+	assert.NoError(t, err)
+	assert.True(t, ad.Published)
+
+	adRepo.EXPECT().GetAd(ctx, int64(5)).
+		Return(&models.Ad{ID: 5, UserID: 1, State: models.AdPublished, Published: true}, nil).Times(1)
+	adRepo.EXPECT().SetState(ctx, int64(5), models.AdArchived, "", int64(0)).
+		Return(&models.Ad{ID: 5, UserID: 1, State: models.AdArchived}, nil).Times(1)
+	ad, err = adService.ChangeAdStatus(ctx, 5, false, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, models.AdArchived, ad.State)
+}
+
+func TestEditDuringReview_ReturnsToDraft(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := auth.WithUserID(context.Background(), 1)
+	adRepo := repoMock.NewMockAdRepository(ctrl)
+	adService := NewAdService(adRepo, anyRevisions(ctrl))
+	pending := &models.Ad{ID: 5, UserID: 1, Title: "title", Text: "text", State: models.AdPendingReview, Version: 2}
+	adRepo.EXPECT().GetAd(ctx, int64(5)).Return(pending, nil).Times(2)
+
+	adRepo.EXPECT().Update(ctx, int64(5), "new title", "text", models.AdDetails{}, models.AdDraft, int64(2)).
+		Return(&models.Ad{ID: 5, UserID: 1, Title: "new title", Text: "text", State: models.AdDraft}, nil).Times(1)
+	ad, err := adService.UpdateAd(ctx, 5, "new title", "text", models.AdDetails{}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, models.AdDraft, ad.State, "the moderator must not approve content they have not seen")
+
+	adRepo.EXPECT().AddImage(ctx, int64(5), gomock.Any(), models.AdDraft, int64(2)).
+		Return(&models.Ad{ID: 5, UserID: 1, State: models.AdDraft}, nil).Times(1)
+	ad, err = adService.UploadAdImage(ctx, 5, bytes.NewReader(testPNG(t, 10, 10)), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, models.AdDraft, ad.State)
+}
+
+func TestListModerationQueue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	adRepo := repoMock.NewMockAdRepository(ctrl)
+	adService := NewAdService(adRepo, anyRevisions(ctrl))
+
+	_, err := adService.ListModerationQueue(auth.WithUserID(context.Background(), 2))
+	assert.ErrorIs(t, err, ErrNotModerator)
+
+	ctx := moderatorContext(2)
+	adRepo.EXPECT().FindAds(ctx, models.AdFilter{State: models.AdPendingReview}).Return([]*models.Ad{
+		{ID: 1, DateUpdate: testDate.Add(time.Hour)},
+		{ID: 2, DateUpdate: testDate},
+	}, nil).Times(1)
+	ads, err := adService.ListModerationQueue(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, ads, 2)
+	assert.Equal(t, int64(2), ads[0].ID)
+	assert.Equal(t, int64(1), ads[1].ID)
+}
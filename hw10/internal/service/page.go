@@ -0,0 +1,273 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+type ErrInvalidPage struct {
+	Err error
+}
+
+func (e ErrInvalidPage) Error() string {
+	return fmt.Sprintf("%s", e.Err)
+}
+
+func (e ErrInvalidPage) Unwrap() error {
+	return e.Err
+}
+
+func (e ErrInvalidPage) Is(target error) bool {
+	return target == domain.ErrValidation
+}
+
+var (
+	ErrInvalidLimit           = errors.New("limit must not be negative")
+	ErrInvalidSortField       = errors.New("sort_by must be one of: id, date_creation, date_update, title")
+	ErrInvalidSearchSortField = errors.New(
+		"sort_by must be one of: relevance, id, date_creation, date_update, title")
+	ErrInvalidSortOrder = errors.New("order must be one of: asc, desc")
+	ErrInvalidPageToken = errors.New("invalid page token")
+)
+
+// pageItem элемент выдачи; score заполнен только у результатов полнотекстового поиска
+type pageItem struct {
+	ad    *models.Ad
+	score float64
+}
+
+// pageToken курсор на последнее объявление выданной страницы; сортировка зашита в токен,
+// чтобы его нельзя было применить к выдаче с другим порядком
+type pageToken struct {
+	SortBy models.AdSortField `json:"s"`
+	Order  models.SortOrder   `json:"o"`
+	Key    string             `json:"k"`
+	ID     int64              `json:"id"`
+}
+
+func normalizePageParams(params models.PageParams, search bool) (models.PageParams, error) {
+	switch {
+	case params.Limit < 0:
+		return params, ErrInvalidPage{Err: ErrInvalidLimit}
+	case params.Limit == 0:
+		params.Limit = defaultPageLimit
+	case params.Limit > maxPageLimit:
+		params.Limit = maxPageLimit
+	}
+
+	switch params.SortBy {
+	case "":
+		params.SortBy = models.AdSortByID
+		if search {
+			params.SortBy = models.AdSortByRelevance
+		}
+	case models.AdSortByID, models.AdSortByDateCreation, models.AdSortByDateUpdate, models.AdSortByTitle:
+	case models.AdSortByRelevance:
+		if !search {
+			return params, ErrInvalidPage{Err: ErrInvalidSortField}
+		}
+	default:
+		if search {
+			return params, ErrInvalidPage{Err: ErrInvalidSearchSortField}
+		}
+		return params, ErrInvalidPage{Err: ErrInvalidSortField}
+	}
+
+	switch params.Order {
+	case "":
+		params.Order = models.SortAsc
+		// по умолчанию сначала самые релевантные
+		if params.SortBy == models.AdSortByRelevance {
+			params.Order = models.SortDesc
+		}
+	case models.SortAsc, models.SortDesc:
+	default:
+		return params, ErrInvalidPage{Err: ErrInvalidSortOrder}
+	}
+	return params, nil
+}
+
+// pageFilter дополняет фильтр порядком и курсором страницы params и просит у хранилища на одно объявление
+// больше страницы: по лишнему объявлению видно, что страница не последняя
+func pageFilter(filter models.AdFilter, params models.PageParams) (models.AdFilter, models.PageParams, error) {
+	params, err := normalizePageParams(params, false)
+	if err != nil {
+		return filter, params, err
+	}
+	filter.SortBy, filter.Order, filter.Limit = params.SortBy, params.Order, params.Limit+1
+	if params.PageToken != "" {
+		cursor, err := decodePageToken(params)
+		if err != nil {
+			return filter, params, err
+		}
+		filter.After = cursor.ad
+	}
+	return filter, params, nil
+}
+
+// adsPage страница из выборки по pageFilter: лишнее объявление отбрасывается, а токен следующей страницы
+// указывает на последнее объявление страницы
+func adsPage(ads []*models.Ad, params models.PageParams) *models.AdsPage {
+	page := &models.AdsPage{Ads: ads}
+	if len(ads) > params.Limit {
+		page.Ads = ads[:params.Limit]
+		page.NextPageToken = encodePageToken(params, pageItem{ad: page.Ads[params.Limit-1]})
+	}
+	return page
+}
+
+// paginateSearch сортирует результаты поиска и вырезает из них страницу, следующую за курсором из
+// params.PageToken; по умолчанию результаты упорядочены по релевантности
+func paginateSearch(results []*models.SearchResult, params models.PageParams) (*models.SearchPage, error) {
+	params, err := normalizePageParams(params, true)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]pageItem, 0, len(results))
+	for _, result := range results {
+		items = append(items, pageItem{ad: result.Ad, score: result.Score})
+	}
+	items, nextPageToken, err := paginateItems(items, params)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.SearchPage{Results: make([]*models.SearchResult, 0, len(items)), NextPageToken: nextPageToken}
+	for _, item := range items {
+		page.Results = append(page.Results, &models.SearchResult{Ad: item.ad, Score: item.score})
+	}
+	return page, nil
+}
+
+func paginateItems(items []pageItem, params models.PageParams) ([]pageItem, string, error) {
+	compare := itemComparator(params.SortBy, params.Order)
+	sort.SliceStable(items, func(i, j int) bool {
+		return compare(items[i], items[j]) < 0
+	})
+
+	start := 0
+	if params.PageToken != "" {
+		cursor, err := decodePageToken(params)
+		if err != nil {
+			return nil, "", err
+		}
+		start = sort.Search(len(items), func(i int) bool {
+			return compare(items[i], cursor) > 0
+		})
+	}
+	end := start + params.Limit
+	if end > len(items) {
+		end = len(items)
+	}
+
+	nextPageToken := ""
+	if end < len(items) {
+		nextPageToken = encodePageToken(params, items[end-1])
+	}
+	return items[start:end], nextPageToken, nil
+}
+
+func encodePageToken(params models.PageParams, last pageItem) string {
+	token := pageToken{SortBy: params.SortBy, Order: params.Order, Key: sortKey(last, params.SortBy), ID: last.ad.ID}
+	data, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodePageToken восстанавливает из токена элемент-курсор, с которым сравниваются элементы выдачи
+func decodePageToken(params models.PageParams) (pageItem, error) {
+	data, err := base64.RawURLEncoding.DecodeString(params.PageToken)
+	if err != nil {
+		return pageItem{}, ErrInvalidPage{Err: ErrInvalidPageToken}
+	}
+	var token pageToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		return pageItem{}, ErrInvalidPage{Err: ErrInvalidPageToken}
+	}
+	if token.SortBy != params.SortBy || token.Order != params.Order {
+		return pageItem{}, ErrInvalidPage{Err: ErrInvalidPageToken}
+	}
+
+	cursor := pageItem{ad: &models.Ad{ID: token.ID}}
+	switch token.SortBy {
+	case models.AdSortByDateCreation:
+		cursor.ad.DateCreation, err = time.Parse(time.RFC3339Nano, token.Key)
+	case models.AdSortByDateUpdate:
+		cursor.ad.DateUpdate, err = time.Parse(time.RFC3339Nano, token.Key)
+	case models.AdSortByTitle:
+		cursor.ad.Title = token.Key
+	case models.AdSortByRelevance:
+		cursor.score, err = strconv.ParseFloat(token.Key, 64)
+	}
+	if err != nil {
+		return pageItem{}, ErrInvalidPage{Err: ErrInvalidPageToken}
+	}
+	return cursor, nil
+}
+
+func sortKey(item pageItem, field models.AdSortField) string {
+	switch field {
+	case models.AdSortByDateCreation:
+		return item.ad.DateCreation.Format(time.RFC3339Nano)
+	case models.AdSortByDateUpdate:
+		return item.ad.DateUpdate.Format(time.RFC3339Nano)
+	case models.AdSortByTitle:
+		return item.ad.Title
+	case models.AdSortByRelevance:
+		return strconv.FormatFloat(item.score, 'g', -1, 64)
+	default:
+		return ""
+	}
+}
+
+// itemComparator задаёт полный порядок элементов выдачи: по полю сортировки, а при равенстве - по ID
+func itemComparator(field models.AdSortField, order models.SortOrder) func(a, b pageItem) int {
+	if field != models.AdSortByRelevance {
+		return func(a, b pageItem) int {
+			return models.CompareAds(a.ad, b.ad, field, order)
+		}
+	}
+	return func(a, b pageItem) int {
+		result := compareScores(a.score, b.score)
+		if result == 0 {
+			result = compareIDs(a.ad.ID, b.ad.ID)
+		}
+		if order == models.SortDesc {
+			return -result
+		}
+		return result
+	}
+}
+
+func compareIDs(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareScores(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
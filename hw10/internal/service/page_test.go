@@ -0,0 +1,222 @@
+package service
+
+import (
+	"homework10/internal/domain/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testAds() []*models.Ad {
+	return []*models.Ad{
+		{ID: 2, Title: "b", DateCreation: date(2023, 1, 2), DateUpdate: date(2023, 3, 1)},
+		{ID: 0, Title: "c", DateCreation: date(2022, 12, 31), DateUpdate: date(2023, 1, 5)},
+		{ID: 3, Title: "a", DateCreation: date(2023, 1, 2), DateUpdate: date(2023, 1, 5)},
+		{ID: 1, Title: "b", DateCreation: date(2022, 5, 10), DateUpdate: date(2023, 2, 1)},
+	}
+}
+
+// paginate выдаёт страницу так же, как ListAds с хранилищем в памяти
+func paginate(ads []*models.Ad, params models.PageParams) (*models.AdsPage, error) {
+	filter, params, err := pageFilter(models.AdFilter{}, params)
+	if err != nil {
+		return nil, err
+	}
+	matched := make([]*models.Ad, 0, len(ads))
+	for _, ad := range ads {
+		if filter.Match(ad) {
+			matched = append(matched, ad)
+		}
+	}
+	return adsPage(filter.Page(matched), params), nil
+}
+
+func adIDs(ads []*models.Ad) []int64 {
+	ids := make([]int64, 0, len(ads))
+	for _, ad := range ads {
+		ids = append(ids, ad.ID)
+	}
+	return ids
+}
+
+func TestPaginate_Sort(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   models.PageParams
+		expected []int64
+	}{
+		{
+			name:     "default: by id asc",
+			params:   models.PageParams{},
+			expected: []int64{0, 1, 2, 3},
+		},
+		{
+			name:     "by id desc",
+			params:   models.PageParams{Order: models.SortDesc},
+			expected: []int64{3, 2, 1, 0},
+		},
+		{
+			name:     "by title asc, ties by id",
+			params:   models.PageParams{SortBy: models.AdSortByTitle},
+			expected: []int64{3, 1, 2, 0},
+		},
+		{
+			name:     "by date_creation desc, ties by id",
+			params:   models.PageParams{SortBy: models.AdSortByDateCreation, Order: models.SortDesc},
+			expected: []int64{3, 2, 0, 1},
+		},
+		{
+			name:     "by date_update asc",
+			params:   models.PageParams{SortBy: models.AdSortByDateUpdate},
+			expected: []int64{0, 3, 1, 2},
+		},
+		{
+			name:     "limit",
+			params:   models.PageParams{Limit: 2},
+			expected: []int64{0, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := paginate(testAds(), tt.params)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, adIDs(page.Ads))
+		})
+	}
+}
+
+func TestPaginate_WalkPages(t *testing.T) {
+	sorts := []models.PageParams{
+		{SortBy: models.AdSortByID},
+		{SortBy: models.AdSortByTitle, Order: models.SortDesc},
+		{SortBy: models.AdSortByDateCreation},
+		{SortBy: models.AdSortByDateUpdate, Order: models.SortDesc},
+	}
+	for _, params := range sorts {
+		t.Run(string(params.SortBy)+" "+string(params.Order), func(t *testing.T) {
+			full, err := paginate(testAds(), params)
+			assert.NoError(t, err)
+			assert.Empty(t, full.NextPageToken)
+
+			params.Limit = 3
+			walked := make([]int64, 0)
+			for {
+				page, err := paginate(testAds(), params)
+				assert.NoError(t, err)
+				walked = append(walked, adIDs(page.Ads)...)
+				if page.NextPageToken == "" {
+					break
+				}
+				params.PageToken = page.NextPageToken
+			}
+			assert.Equal(t, adIDs(full.Ads), walked)
+		})
+	}
+}
+
+func TestPaginate_CursorSurvivesDeletion(t *testing.T) {
+	page, err := paginate(testAds(), models.PageParams{Limit: 2})
+	assert.NoError(t, err)
+
+	ads := testAds()
+	ads = ads[1:] // удалено объявление с ID 2, следующее за курсором
+	next, err := paginate(ads, models.PageParams{Limit: 2, PageToken: page.NextPageToken})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3}, adIDs(next.Ads))
+}
+
+func TestPaginate_Errors(t *testing.T) {
+	page, err := paginate(testAds(), models.PageParams{Limit: 1})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		params   models.PageParams
+		expected error
+	}{
+		{
+			name:     "negative limit",
+			params:   models.PageParams{Limit: -1},
+			expected: ErrInvalidPage{Err: ErrInvalidLimit},
+		},
+		{
+			name:     "unknown sort field",
+			params:   models.PageParams{SortBy: "text"},
+			expected: ErrInvalidPage{Err: ErrInvalidSortField},
+		},
+		{
+			name:     "unknown order",
+			params:   models.PageParams{Order: "up"},
+			expected: ErrInvalidPage{Err: ErrInvalidSortOrder},
+		},
+		{
+			name:     "broken token",
+			params:   models.PageParams{PageToken: "%%%"},
+			expected: ErrInvalidPage{Err: ErrInvalidPageToken},
+		},
+		{
+			name:     "token from another sort",
+			params:   models.PageParams{PageToken: page.NextPageToken, SortBy: models.AdSortByTitle},
+			expected: ErrInvalidPage{Err: ErrInvalidPageToken},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := paginate(testAds(), tt.params)
+			assert.Nil(t, page)
+			assert.Equal(t, tt.expected, err)
+		})
+	}
+}
+
+func TestPaginate_MaxLimit(t *testing.T) {
+	ads := make([]*models.Ad, 0, maxPageLimit+1)
+	for i := 0; i <= maxPageLimit; i++ {
+		ads = append(ads, &models.Ad{ID: int64(i)})
+	}
+	page, err := paginate(ads, models.PageParams{Limit: maxPageLimit * 10})
+	assert.NoError(t, err)
+	assert.Len(t, page.Ads, maxPageLimit)
+	assert.NotEmpty(t, page.NextPageToken)
+}
+
+func TestPaginateSearch_Relevance(t *testing.T) {
+	results := func() []*models.SearchResult {
+		return []*models.SearchResult{
+			{Ad: &models.Ad{ID: 0, Title: "c"}, Score: 0.5},
+			{Ad: &models.Ad{ID: 1, Title: "a"}, Score: 2.25},
+			{Ad: &models.Ad{ID: 2, Title: "b"}, Score: 0.5},
+			{Ad: &models.Ad{ID: 3, Title: "d"}, Score: 1},
+		}
+	}
+	resultIDs := func(page *models.SearchPage) []int64 {
+		ids := make([]int64, 0, len(page.Results))
+		for _, result := range page.Results {
+			ids = append(ids, result.Ad.ID)
+		}
+		return ids
+	}
+
+	page, err := paginateSearch(results(), models.PageParams{Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 3}, resultIDs(page))
+
+	next, err := paginateSearch(results(), models.PageParams{Limit: 2, PageToken: page.NextPageToken})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{2, 0}, resultIDs(next))
+	assert.Empty(t, next.NextPageToken)
+
+	byTitle, err := paginateSearch(results(), models.PageParams{SortBy: models.AdSortByTitle})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 0, 3}, resultIDs(byTitle))
+
+	_, err = paginateSearch(results(), models.PageParams{SortBy: "text"})
+	assert.Equal(t, ErrInvalidPage{Err: ErrInvalidSearchSortField}, err)
+	_, err = paginate(testAds(), models.PageParams{SortBy: models.AdSortByRelevance})
+	assert.Equal(t, ErrInvalidPage{Err: ErrInvalidSortField}, err)
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"errors"
+	"homework10/internal/domain"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const minPasswordLength = 8
+
+var (
+	ErrPasswordTooShort = domain.ErrInvalid{Err: errors.New("the password must be at least 8 characters long")}
+	ErrPasswordTooLong  = domain.ErrInvalid{Err: errors.New("the password must be at most 72 bytes long")}
+	ErrWrongPassword    = ErrNoAccess{Err: errors.New("the current password is wrong")}
+)
+
+// dummyPasswordHash сравнивается с паролем, когда пользователь не найден, чтобы по времени ответа
+// нельзя было узнать, зарегистрирована ли почта
+var dummyPasswordHash = []byte("$2a$10$giDjxj0JPiqMMJXol1I7Ie4LPPfzL806LHQV7jq0S/WNM.o9Av0h.")
+
+func hashPassword(password string, cost int) ([]byte, error) {
+	if utf8.RuneCountInString(password) < minPasswordLength {
+		return nil, ErrPasswordTooShort
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		return nil, ErrPasswordTooLong
+	}
+	return hash, err
+}
+
+func checkPassword(hash []byte, password string) bool {
+	if len(hash) == 0 {
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return false
+	}
+	return bcrypt.CompareHashAndPassword(hash, []byte(password)) == nil
+}
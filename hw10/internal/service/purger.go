@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Purger периодически окончательно удаляет пользователей и объявления, у которых истёк срок хранения в корзине
+type Purger struct {
+	ads      *AdService
+	users    *UserService
+	interval time.Duration
+}
+
+func NewPurger(ads *AdService, users *UserService, interval time.Duration) *Purger {
+	return &Purger{ads: ads, users: users, interval: interval}
+}
+
+// Run очищает корзину сразу и затем каждые interval, пока не завершится контекст
+func (p *Purger) Run(ctx context.Context) error {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		if err := p.Purge(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("purging trash: %s", err.Error())
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge очищает корзину один раз: сначала пользователей вместе с их объявлениями, затем остальные объявления
+func (p *Purger) Purge(ctx context.Context) error {
+	users, err := p.users.PurgeDeletedUsers(ctx)
+	if err != nil {
+		return err
+	}
+	ads, err := p.ads.PurgeDeletedAds(ctx)
+	if err != nil {
+		return err
+	}
+	if users != 0 || ads != 0 {
+		log.Printf("purged %d users and %d ads from the trash", users, ads)
+	}
+	return nil
+}
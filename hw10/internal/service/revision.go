@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework10/internal/auth"
+	"homework10/internal/domain/models"
+	"homework10/internal/policy"
+	"strconv"
+	"time"
+)
+
+var ErrNoAccessRevisions = errors.New("only the author, a moderator or an administrator can view the ad history")
+
+// ListAdRevisions возвращает историю изменений объявления, начиная с его создания. В истории есть
+// неопубликованные версии, поэтому её видят только автор, модераторы и администраторы
+func (s *AdService) ListAdRevisions(ctx context.Context, adID int64) ([]*models.AdRevision, error) {
+	ad, err := s.getAd(ctx, adID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(ctx, s.Policy, policy.ViewRevisions, ad.UserID, ErrNoAccess{Err: ErrNoAccessRevisions}); err != nil {
+		return nil, err
+	}
+	return s.revisionRepo.GetRevisions(ctx, adID)
+}
+
+// RestoreAdRevision возвращает объявлению заголовок и текст из ревизии number. Статус и характеристики не откатываются:
+// как и после UpdateAd, объявление становится черновиком; само восстановление записывается новой ревизией
+func (s *AdService) RestoreAdRevision(ctx context.Context, adID int64, number int64) (*models.Ad, error) {
+	ad, err := s.authorizedAd(ctx, adID, policy.UpdateAd)
+	if err != nil {
+		return nil, err
+	}
+	revision, err := s.revisionRepo.GetRevision(ctx, adID, number)
+	if err != nil {
+		return nil, err
+	}
+	return s.updateAd(ctx, *ad, revision.Title, revision.Text, ad.AdDetails, models.RevisionRestored, revision.Number)
+}
+
+// recordRevision сохраняет снимок объявления после изменения вместе с отличиями от old
+func (s *AdService) recordRevision(ctx context.Context, revisionType models.AdRevisionType, old models.Ad,
+	ad models.Ad, restoredFrom int64) error {
+	authorID, _ := auth.UserIDFromContext(ctx)
+	_, err := s.revisionRepo.AddRevision(ctx, models.AdRevision{
+		AdID:         ad.ID,
+		Type:         revisionType,
+		AuthorID:     authorID,
+		Date:         time.Now().UTC(),
+		Title:        ad.Title,
+		Text:         ad.Text,
+		Published:    ad.Published,
+		Changes:      adChanges(old, ad),
+		RestoredFrom: restoredFrom,
+	})
+	if err != nil {
+		return fmt.Errorf("recording revision: %w", err)
+	}
+	return nil
+}
+
+// adChanges перечисляет поля, которые различаются у old и ad
+func adChanges(old models.Ad, ad models.Ad) []models.FieldChange {
+	changes := make([]models.FieldChange, 0)
+	if old.Title != ad.Title {
+		changes = append(changes, models.FieldChange{Field: "title", Old: old.Title, New: ad.Title})
+	}
+	if old.Text != ad.Text {
+		changes = append(changes, models.FieldChange{Field: "text", Old: old.Text, New: ad.Text})
+	}
+	if old.Price != ad.Price {
+		changes = append(changes, models.FieldChange{Field: "price", Old: formatPrice(old.Price), New: formatPrice(ad.Price)})
+	}
+	if old.CategoryID != ad.CategoryID {
+		changes = append(changes, models.FieldChange{Field: "category", Old: old.CategoryID, New: ad.CategoryID})
+	}
+	if old.City != ad.City {
+		changes = append(changes, models.FieldChange{Field: "city", Old: old.City, New: ad.City})
+	}
+	if old.State != ad.State {
+		changes = append(changes, models.FieldChange{Field: "state", Old: string(old.State), New: string(ad.State)})
+	}
+	if len(old.Images) != len(ad.Images) {
+		changes = append(changes, models.FieldChange{Field: "images",
+			Old: strconv.Itoa(len(old.Images)), New: strconv.Itoa(len(ad.Images))})
+	}
+	if old.Published != ad.Published {
+		changes = append(changes, models.FieldChange{Field: "published",
+			Old: strconv.FormatBool(old.Published), New: strconv.FormatBool(ad.Published)})
+	}
+	return changes
+}
+
+// formatPrice записывает цену в минимальных единицах вместе с валютой, например "150000 RUB"
+func formatPrice(price models.Price) string {
+	if !price.IsSet() {
+		return ""
+	}
+	return strconv.FormatInt(price.Amount, 10) + " " + price.Currency
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"homework10/internal/auth"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	repoMock "homework10/internal/service/mock"
+	"testing"
+)
+
+func TestAdRevisions_Recorded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := auth.WithUserID(context.Background(), 1)
+	adRepo := repoMock.NewMockAdRepository(ctrl)
+	revisionRepo := repoMock.NewMockAdRevisionRepository(ctrl)
+	adService := NewAdService(adRepo, revisionRepo)
+
+	recorded := make([]models.AdRevision, 0)
+	revisionRepo.EXPECT().AddRevision(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, revision models.AdRevision) (int64, error) {
+			recorded = append(recorded, revision)
+			return int64(len(recorded)), nil
+		}).Times(3)
+
+	adRepo.EXPECT().AddAd(ctx, gomock.Any()).Return(int64(5), nil).Times(1)
+	_, err := adService.CreateAd(ctx, "title", "text", models.AdDetails{})
+	assert.NoError(t, err)
+
+	adRepo.EXPECT().GetAd(ctx, int64(5)).
+		Return(&models.Ad{ID: 5, UserID: 1, Title: "title", Text: "text", State: models.AdDraft}, nil).Times(1)
+	adRepo.EXPECT().Update(ctx, int64(5), "title", "new text", models.AdDetails{}, models.AdDraft, int64(0)).
+		Return(&models.Ad{ID: 5, UserID: 1, Title: "title", Text: "new text", State: models.AdDraft}, nil).Times(1)
+	_, err = adService.UpdateAd(ctx, 5, "title", "new text", models.AdDetails{}, 0)
+	assert.NoError(t, err)
+
+	adRepo.EXPECT().GetAd(ctx, int64(5)).
+		Return(&models.Ad{ID: 5, UserID: 1, Title: "title", Text: "new text", State: models.AdApproved}, nil).Times(1)
+	adRepo.EXPECT().SetState(ctx, int64(5), models.AdPublished, "", int64(0)).
+		Return(&models.Ad{ID: 5, UserID: 1, Title: "title", Text: "new text", Published: true, State: models.AdPublished}, nil).Times(1)
+	_, err = adService.ChangeAdStatus(ctx, 5, true, 0)
+	assert.NoError(t, err)
+
+	expected := []struct {
+		revisionType models.AdRevisionType
+		changes      []models.FieldChange
+	}{
+		{
+			revisionType: models.RevisionCreated,
+			changes: []models.FieldChange{
+				{Field: "title", Old: "", New: "title"},
+				{Field: "text", Old: "", New: "text"},
+				{Field: "state", Old: "", New: "draft"},
+			},
+		},
+		{
+			revisionType: models.RevisionUpdated,
+			changes:      []models.FieldChange{{Field: "text", Old: "text", New: "new text"}},
+		},
+		{
+			revisionType: models.RevisionStatusChanged,
+			changes: []models.FieldChange{
+				{Field: "state", Old: "approved", New: "published"},
+				{Field: "published", Old: "false", New: "true"},
+			},
+		},
+	}
+	assert.Len(t, recorded, len(expected))
+	for i, revision := range recorded {
+		assert.Equal(t, int64(5), revision.AdID)
+		assert.Equal(t, int64(1), revision.AuthorID)
+		assert.False(t, revision.Date.IsZero())
+		assert.Equal(t, expected[i].revisionType, revision.Type)
+		assert.Equal(t, expected[i].changes, revision.Changes)
+	}
+}
+
+func TestRestoreAdRevision(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	adRepo := repoMock.NewMockAdRepository(ctrl)
+	revisionRepo := repoMock.NewMockAdRevisionRepository(ctrl)
+	adService := NewAdService(adRepo, revisionRepo)
+	ad := models.Ad{ID: 5, UserID: 1, Title: "new title", Text: "text"}
+	revision := &models.AdRevision{AdID: 5, Number: 1, Type: models.RevisionCreated, Title: "title", Text: "text"}
+
+	testTable := []struct {
+		name       string
+		userID     int64
+		number     int64
+		revision   *models.AdRevision
+		errGetRev  error
+		restored   *models.Ad
+		wantErr    error
+		wantRecord *models.AdRevision
+	}{
+		{
+			name:     "restore",
+			userID:   1,
+			number:   1,
+			revision: revision,
+			restored: &models.Ad{ID: 5, UserID: 1, Title: "title", Text: "text"},
+			wantRecord: &models.AdRevision{AdID: 5, Type: models.RevisionRestored, AuthorID: 1,
+				Title: "title", Text: "text", RestoredFrom: 1,
+				Changes: []models.FieldChange{{Field: "title", Old: "new title", New: "title"}}},
+		},
+		{
+			name:    "not the owner",
+			userID:  2,
+			number:  1,
+			wantErr: domain.ErrForbidden,
+		},
+		{
+			name:      "revision does not exist",
+			userID:    1,
+			number:    7,
+			errGetRev: domain.ErrRevisionNotExist,
+			wantErr:   domain.ErrNotFound,
+		},
+	}
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctx := auth.WithUserID(context.Background(), testCase.userID)
+			stored := ad
+			adRepo.EXPECT().GetAd(ctx, int64(5)).Return(&stored, nil).Times(1)
+			if testCase.userID == ad.UserID {
+				revisionRepo.EXPECT().GetRevision(ctx, int64(5), testCase.number).
+					Return(testCase.revision, testCase.errGetRev).Times(1)
+			}
+			if testCase.restored != nil {
+				adRepo.EXPECT().Update(ctx, int64(5), testCase.revision.Title, testCase.revision.Text, models.AdDetails{}, models.AdDraft, int64(0)).
+					Return(testCase.restored, nil).Times(1)
+				revisionRepo.EXPECT().AddRevision(ctx, gomock.Any()).
+					DoAndReturn(func(_ context.Context, got models.AdRevision) (int64, error) {
+						got.Date = testCase.wantRecord.Date
+						assert.Equal(t, *testCase.wantRecord, got)
+						return 3, nil
+					}).Times(1)
+			}
+
+			restored, err := adService.RestoreAdRevision(ctx, 5, testCase.number)
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+				assert.Nil(t, restored)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "title", restored.Title)
+		})
+	}
+}
+
+func TestListAdRevisions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := auth.WithUserID(context.Background(), 1)
+	adRepo := repoMock.NewMockAdRepository(ctrl)
+	revisionRepo := repoMock.NewMockAdRevisionRepository(ctrl)
+	adService := NewAdService(adRepo, revisionRepo)
+
+	revisions := []*models.AdRevision{{AdID: 5, Number: 1, Type: models.RevisionCreated}}
+	adRepo.EXPECT().GetAd(gomock.Any(), int64(5)).Return(&models.Ad{ID: 5, UserID: 1}, nil).Times(4)
+	revisionRepo.EXPECT().GetRevisions(gomock.Any(), int64(5)).Return(revisions, nil).Times(2)
+	got, err := adService.ListAdRevisions(ctx, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, revisions, got)
+	got, err = adService.ListAdRevisions(moderatorContext(2), 5)
+	assert.NoError(t, err)
+	assert.Equal(t, revisions, got)
+
+	// история с неопубликованными версиями закрыта от анонимов и других пользователей
+	_, err = adService.ListAdRevisions(context.Background(), 5)
+	assert.ErrorIs(t, err, ErrNotAuthenticated)
+	_, err = adService.ListAdRevisions(auth.WithUserID(context.Background(), 2), 5)
+	assert.ErrorIs(t, err, ErrNoAccessRevisions)
+
+	adRepo.EXPECT().GetAd(ctx, int64(6)).Return(nil, domain.ErrAdNotExist).Times(1)
+	_, err = adService.ListAdRevisions(ctx, 6)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"homework10/internal/domain/models"
+	"homework10/internal/tracing"
+	"io"
+)
+
+// TracedAdService записывает вызовы методов AdService спанами трассировки. Хранилища получают
+// контекст со спаном метода, поэтому их спаны вкладываются в него
+type TracedAdService struct {
+	*AdService
+}
+
+func NewTracedAdService(s *AdService) *TracedAdService {
+	return &TracedAdService{AdService: s}
+}
+
+// TracedUserService то же, что TracedAdService, для UserService
+type TracedUserService struct {
+	*UserService
+}
+
+func NewTracedUserService(s *UserService) *TracedUserService {
+	return &TracedUserService{UserService: s}
+}
+
+func (s *TracedAdService) BuildSearchIndex(ctx context.Context) (err error) {
+	ctx, span := tracing.Start(ctx, "AdService.BuildSearchIndex")
+	defer tracing.End(span, &err)
+	return s.AdService.BuildSearchIndex(ctx)
+}
+
+func (s *TracedAdService) GetAdByID(ctx context.Context, adID int64) (_ *models.Ad, err error) {
+	ctx, span := tracing.Start(ctx, "AdService.GetAdByID")
+	defer tracing.End(span, &err)
+	return s.AdService.GetAdByID(ctx, adID)
+}
+
+func (s *TracedAdService) CreateAd(ctx context.Context, title string, text string, details models.AdDetails) (
+	_ *models.Ad, err error) {
+	ctx, span := tracing.Start(ctx, "AdService.CreateAd")
+	defer tracing.End(span, &err)
+	return s.AdService.CreateAd(ctx, title, text, details)
+}
+
+func (s *TracedAdService) ChangeAdStatus(ctx context.Context, adID int64, published bool, expectedVersion int64) (
+	_ *models.Ad, err error) {
+	ctx, span := tracing.Start(ctx, "AdService.ChangeAdStatus")
+	defer tracing.End(span, &err)
+	return s.AdService.ChangeAdStatus(ctx, adID, published, expectedVersion)
+}
+
+func (s *TracedAdService) UpdateAd(ctx context.Context, adID int64, title string, text string, details models.AdDetails,
+	expectedVersion int64) (_ *models.Ad, err error) {
+	ctx, span := tracing.Start(ctx, "AdService.UpdateAd")
+	defer tracing.End(span, &err)
+	return s.AdService.UpdateAd(ctx, adID, title, text, details, expectedVersion)
+}
+
+func (s *TracedAdService) DeleteAd(ctx context.Context, adID int64) (err error) {
+	ctx, span := tracing.Start(ctx, "AdService.DeleteAd")
+	defer tracing.End(span, &err)
+	return s.AdService.DeleteAd(ctx, adID)
+}
+
+func (s *TracedAdService) SearchAds(ctx context.Context, query string, page models.PageParams) (
+	_ *models.SearchPage, err error) {
+	ctx, span := tracing.Start(ctx, "AdService.SearchAds")
+	defer tracing.End(span, &err)
+	return s.AdService.SearchAds(ctx, query, page)
+}
+
+func (s *TracedAdService) ListAds(ctx context.Context, filter models.AdFilter, page models.PageParams) (
+	_ *models.AdsPage, err error) {
+	ctx, span := tracing.Start(ctx, "AdService.ListAds")
+	defer tracing.End(span, &err)
+	return s.AdService.ListAds(ctx, filter, page)
+}
+
+func (s *TracedAdService) ListCategories(ctx context.Context) (_ []models.Category, err error) {
+	ctx, span := tracing.Start(ctx, "AdService.ListCategories")
+	defer tracing.End(span, &err)
+	return s.AdService.ListCategories(ctx)
+}
+
+func (s *TracedAdService) UploadAdImage(ctx context.Context, adID int64, r io.Reader, expectedVersion int64) (
+	_ *models.Ad, err error) {
+	ctx, span := tracing.Start(ctx, "AdService.UploadAdImage")
+	defer tracing.End(span, &err)
+	return s.AdService.UploadAdImage(ctx, adID, r, expectedVersion)
+}
+
+func (s *TracedAdService) OpenAdImage(ctx context.Context, adID int64, imageID int64, thumbnail bool) (
+	_ *models.AdImage, _ io.ReadCloser, err error) {
+	ctx, span := tracing.Start(ctx, "AdService.OpenAdImage")
+	defer tracing.End(span, &err)
+	return s.AdService.OpenAdImage(ctx, adID, imageID, thumbnail)
+}
+
+func (s *TracedAdService) SubmitAd(ctx context.Context, adID int64, expectedVersion int64) (_ *models.Ad, err error) {
+	ctx, span := tracing.Start(ctx, "AdService.SubmitAd")
+	defer tracing.End(span, &err)
+	return s.AdService.SubmitAd(ctx, adID, expectedVersion)
+}
+
+func (s *TracedAdService) ApproveAd(ctx context.Context, adID int64, expectedVersion int64) (_ *models.Ad, err error) {
+	ctx, span := tracing.Start(ctx, "AdService.ApproveAd")
+	defer tracing.End(span, &err)
+	return s.AdService.ApproveAd(ctx, adID, expectedVersion)
+}
+
+func (s *TracedAdService) RejectAd(ctx context.Context, adID int64, reason string, expectedVersion int64) (
+	_ *models.Ad, err error) {
+	ctx, span := tracing.Start(ctx, "AdService.RejectAd")
+	defer tracing.End(span, &err)
+	return s.AdService.RejectAd(ctx, adID, reason, expectedVersion)
+}
+
+func (s *TracedAdService) ListModerationQueue(ctx context.Context) (_ []*models.Ad, err error) {
+	ctx, span := tracing.Start(ctx, "AdService.ListModerationQueue")
+	defer tracing.End(span, &err)
+	return s.AdService.ListModerationQueue(ctx)
+}
+
+func (s *TracedAdService) ListAdRevisions(ctx context.Context, adID int64) (_ []*models.AdRevision, err error) {
+	ctx, span := tracing.Start(ctx, "AdService.ListAdRevisions")
+	defer tracing.End(span, &err)
+	return s.AdService.ListAdRevisions(ctx, adID)
+}
+
+func (s *TracedAdService) RestoreAdRevision(ctx context.Context, adID int64, number int64) (_ *models.Ad, err error) {
+	ctx, span := tracing.Start(ctx, "AdService.RestoreAdRevision")
+	defer tracing.End(span, &err)
+	return s.AdService.RestoreAdRevision(ctx, adID, number)
+}
+
+func (s *TracedAdService) RestoreAd(ctx context.Context, adID int64) (_ *models.Ad, err error) {
+	ctx, span := tracing.Start(ctx, "AdService.RestoreAd")
+	defer tracing.End(span, &err)
+	return s.AdService.RestoreAd(ctx, adID)
+}
+
+func (s *TracedAdService) ListDeletedAds(ctx context.Context, userID int64) (_ []*models.TrashedAd, err error) {
+	ctx, span := tracing.Start(ctx, "AdService.ListDeletedAds")
+	defer tracing.End(span, &err)
+	return s.AdService.ListDeletedAds(ctx, userID)
+}
+
+func (s *TracedAdService) PurgeDeletedAds(ctx context.Context) (_ int, err error) {
+	ctx, span := tracing.Start(ctx, "AdService.PurgeDeletedAds")
+	defer tracing.End(span, &err)
+	return s.AdService.PurgeDeletedAds(ctx)
+}
+
+func (s *TracedUserService) RestoreUser(ctx context.Context, userID int64, password string) (
+	_ *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.RestoreUser")
+	defer tracing.End(span, &err)
+	return s.UserService.RestoreUser(ctx, userID, password)
+}
+
+func (s *TracedUserService) ListTrash(ctx context.Context, userID int64) (_ []*models.TrashedAd, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.ListTrash")
+	defer tracing.End(span, &err)
+	return s.UserService.ListTrash(ctx, userID)
+}
+
+func (s *TracedUserService) PurgeDeletedUsers(ctx context.Context) (_ int, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.PurgeDeletedUsers")
+	defer tracing.End(span, &err)
+	return s.UserService.PurgeDeletedUsers(ctx)
+}
+
+func (s *TracedUserService) AddFavorite(ctx context.Context, userID int64, adID int64) (_ *models.Ad, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.AddFavorite")
+	defer tracing.End(span, &err)
+	return s.UserService.AddFavorite(ctx, userID, adID)
+}
+
+func (s *TracedUserService) RemoveFavorite(ctx context.Context, userID int64, adID int64) (err error) {
+	ctx, span := tracing.Start(ctx, "UserService.RemoveFavorite")
+	defer tracing.End(span, &err)
+	return s.UserService.RemoveFavorite(ctx, userID, adID)
+}
+
+func (s *TracedUserService) ListFavorites(ctx context.Context, userID int64) (_ []*models.FavoriteAd, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.ListFavorites")
+	defer tracing.End(span, &err)
+	return s.UserService.ListFavorites(ctx, userID)
+}
+
+func (s *TracedUserService) GetUser(ctx context.Context, userID int64) (_ *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetUser")
+	defer tracing.End(span, &err)
+	return s.UserService.GetUser(ctx, userID)
+}
+
+func (s *TracedUserService) CreateUser(ctx context.Context, nickName string, email string, password string) (
+	_ *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.CreateUser")
+	defer tracing.End(span, &err)
+	return s.UserService.CreateUser(ctx, nickName, email, password)
+}
+
+func (s *TracedUserService) UpdateUser(ctx context.Context, userID int64, nickName string, email string) (
+	_ *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.UpdateUser")
+	defer tracing.End(span, &err)
+	return s.UserService.UpdateUser(ctx, userID, nickName, email)
+}
+
+func (s *TracedUserService) ChangePassword(ctx context.Context, userID int64, oldPassword string, newPassword string) (
+	err error) {
+	ctx, span := tracing.Start(ctx, "UserService.ChangePassword")
+	defer tracing.End(span, &err)
+	return s.UserService.ChangePassword(ctx, userID, oldPassword, newPassword)
+}
+
+func (s *TracedUserService) SetUserRole(ctx context.Context, userID int64, role models.Role) (_ *models.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.SetUserRole")
+	defer tracing.End(span, &err)
+	return s.UserService.SetUserRole(ctx, userID, role)
+}
+
+func (s *TracedUserService) DeleteUser(ctx context.Context, userID int64) (err error) {
+	ctx, span := tracing.Start(ctx, "UserService.DeleteUser")
+	defer tracing.End(span, &err)
+	return s.UserService.DeleteUser(ctx, userID)
+}
+
+func (s *TracedUserService) AssignRoles(ctx context.Context) (err error) {
+	ctx, span := tracing.Start(ctx, "UserService.AssignRoles")
+	defer tracing.End(span, &err)
+	return s.UserService.AssignRoles(ctx)
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"homework10/internal/auth"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	"homework10/internal/policy"
+	"time"
+)
+
+// DefaultTrashRetention сколько удалённые объявления и пользователи хранятся в корзине до окончательного удаления
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+var (
+	ErrAdNotInTrash   = domain.ErrAlreadyExists{Err: errors.New("the ad is not deleted")}
+	ErrUserNotInTrash = domain.ErrAlreadyExists{Err: errors.New("the user is not deleted")}
+)
+
+// expired сообщает, что срок хранения в корзине истёк и запись ожидает окончательного удаления
+func expired(deletedAt time.Time, retention time.Duration) bool {
+	return !deletedAt.Add(retention).After(time.Now())
+}
+
+// RestoreAd возвращает объявление автора из корзины, пока не истёк срок хранения
+func (s *AdService) RestoreAd(ctx context.Context, adID int64) (*models.Ad, error) {
+	if _, ok := auth.UserIDFromContext(ctx); !ok {
+		return nil, ErrNotAuthenticated
+	}
+	ad, err := s.adRepo.GetAd(ctx, adID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(ctx, s.Policy, policy.RestoreAd, ad.UserID, ErrNoAccess{Err: ErrNoAccessAd}); err != nil {
+		return nil, err
+	}
+	if !ad.IsDeleted() {
+		return nil, ErrAdNotInTrash
+	}
+	if expired(ad.DeletedAt, s.TrashRetention) {
+		return nil, domain.ErrAdNotExist
+	}
+	restored, err := s.adRepo.SetDeletedAt(ctx, adID, time.Time{})
+	if err != nil {
+		return nil, fmt.Errorf("restoring ad: %w", err)
+	}
+	s.searchIndex.Add(restored.ID, restored.Title, restored.Text)
+	s.events.Publish(models.AdRestored, ad, *restored)
+	return restored, nil
+}
+
+// ListDeletedAds возвращает объявления пользователя, лежащие в корзине, с моментом их окончательного удаления
+func (s *AdService) ListDeletedAds(ctx context.Context, userID int64) ([]*models.TrashedAd, error) {
+	ads, err := s.adRepo.FindAds(ctx, models.AdFilter{AuthorIDs: []int64{userID}, Deleted: models.OnlyDeleted})
+	if err != nil {
+		return nil, err
+	}
+	trashed := make([]*models.TrashedAd, 0, len(ads))
+	for _, ad := range ads {
+		if expired(ad.DeletedAt, s.TrashRetention) {
+			continue
+		}
+		trashed = append(trashed, &models.TrashedAd{Ad: ad, PurgeAt: ad.DeletedAt.Add(s.TrashRetention)})
+	}
+	return trashed, nil
+}
+
+// deleteUserAds переносит в корзину все объявления удаляемого пользователя с его моментом удаления,
+// чтобы при восстановлении пользователя вернуть именно их
+func (s *AdService) deleteUserAds(ctx context.Context, userID int64, deletedAt time.Time) error {
+	ads, err := s.adRepo.FindAds(ctx, models.AdFilter{AuthorIDs: []int64{userID}})
+	if err != nil {
+		return err
+	}
+	for _, ad := range ads {
+		old := *ad
+		if _, err := s.adRepo.SetDeletedAt(ctx, ad.ID, deletedAt); err != nil {
+			return fmt.Errorf("deleting ad %d: %w", ad.ID, err)
+		}
+		if err := s.dropUnpublishedFavorites(ctx, old, false); err != nil {
+			return err
+		}
+		s.searchIndex.Remove(ad.ID)
+		s.events.Publish(models.AdDeleted, nil, old)
+	}
+	return nil
+}
+
+// restoreUserAds возвращает из корзины объявления, удалённые вместе с пользователем; объявления,
+// удалённые автором раньше, остаются в корзине
+func (s *AdService) restoreUserAds(ctx context.Context, userID int64, deletedAt time.Time) error {
+	ads, err := s.adRepo.FindAds(ctx, models.AdFilter{AuthorIDs: []int64{userID}, Deleted: models.OnlyDeleted})
+	if err != nil {
+		return err
+	}
+	for _, ad := range ads {
+		if !ad.DeletedAt.Equal(deletedAt) {
+			continue
+		}
+		restored, err := s.adRepo.SetDeletedAt(ctx, ad.ID, time.Time{})
+		if err != nil {
+			return fmt.Errorf("restoring ad %d: %w", ad.ID, err)
+		}
+		s.searchIndex.Add(restored.ID, restored.Title, restored.Text)
+		s.events.Publish(models.AdRestored, ad, *restored)
+	}
+	return nil
+}
+
+// PurgeDeletedAds окончательно удаляет объявления, у которых истёк срок хранения в корзине
+func (s *AdService) PurgeDeletedAds(ctx context.Context) (int, error) {
+	ads, err := s.adRepo.FindAds(ctx, models.AdFilter{Deleted: models.OnlyDeleted})
+	if err != nil {
+		return 0, err
+	}
+	purged := 0
+	for _, ad := range ads {
+		if !expired(ad.DeletedAt, s.TrashRetention) {
+			continue
+		}
+		if err := s.deleteAdImages(ctx, ad); err != nil {
+			return purged, fmt.Errorf("purging ad %d: %w", ad.ID, err)
+		}
+		if err := s.adRepo.DeleteAd(ctx, ad.ID); err != nil {
+			return purged, fmt.Errorf("purging ad %d: %w", ad.ID, err)
+		}
+		if err := s.revisionRepo.DeleteRevisions(ctx, ad.ID); err != nil {
+			return purged, fmt.Errorf("purging ad %d: %w", ad.ID, err)
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// purgeUserAds окончательно удаляет все объявления пользователя, в том числе не попавшие в корзину
+func (s *AdService) purgeUserAds(ctx context.Context, userID int64) error {
+	ads, err := s.adRepo.FindAds(ctx, models.AdFilter{AuthorIDs: []int64{userID}, Deleted: models.WithDeleted})
+	if err != nil {
+		return err
+	}
+	for _, ad := range ads {
+		if err := s.deleteAdImages(ctx, ad); err != nil {
+			return fmt.Errorf("purging ad %d: %w", ad.ID, err)
+		}
+		if err := s.adRepo.DeleteAd(ctx, ad.ID); err != nil {
+			return fmt.Errorf("purging ad %d: %w", ad.ID, err)
+		}
+		if err := s.revisionRepo.DeleteRevisions(ctx, ad.ID); err != nil {
+			return fmt.Errorf("purging ad %d: %w", ad.ID, err)
+		}
+		s.searchIndex.Remove(ad.ID)
+	}
+	return nil
+}
+
+// RestoreUser снимает с пользователя пометку удаления после проверки пароля и возвращает объявления,
+// удалённые вместе с ним
+func (s *UserService) RestoreUser(ctx context.Context, userID int64, password string) (*models.User, error) {
+	user, err := s.UserRepo.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsDeleted() {
+		return nil, ErrUserNotInTrash
+	}
+	if expired(user.DeletedAt, s.TrashRetention) {
+		return nil, domain.ErrUserNotExist
+	}
+	if !checkPassword(user.PasswordHash, password) {
+		return nil, ErrWrongPassword
+	}
+	deletedAt := user.DeletedAt
+	restored, err := s.UserRepo.SetDeletedAt(ctx, userID, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ads.restoreUserAds(ctx, userID, deletedAt); err != nil {
+		return nil, fmt.Errorf("restoring user ads: %w", err)
+	}
+	return restored, nil
+}
+
+// ListTrash возвращает корзину аутентифицированного пользователя
+func (s *UserService) ListTrash(ctx context.Context, userID int64) ([]*models.TrashedAd, error) {
+	if err := authorize(ctx, s.Policy, policy.ViewTrash, userID, ErrNoAccess{Err: ErrNoAccessUser}); err != nil {
+		return nil, err
+	}
+	return s.ads.ListDeletedAds(ctx, userID)
+}
+
+// PurgeDeletedUsers окончательно удаляет пользователей, у которых истёк срок хранения, вместе со всеми их объявлениями
+func (s *UserService) PurgeDeletedUsers(ctx context.Context) (int, error) {
+	users, err := s.UserRepo.GetDeletedUsers(ctx, time.Now().Add(-s.TrashRetention))
+	if err != nil {
+		return 0, err
+	}
+	purged := 0
+	for _, user := range users {
+		if err := s.ads.purgeUserAds(ctx, user.ID); err != nil {
+			return purged, err
+		}
+		if err := s.ads.removeUserFavorites(ctx, user.ID); err != nil {
+			return purged, fmt.Errorf("purging user %d: %w", user.ID, err)
+		}
+		if err := s.UserRepo.Delete(ctx, user.ID); err != nil {
+			return purged, fmt.Errorf("purging user %d: %w", user.ID, err)
+		}
+		purged++
+	}
+	return purged, nil
+}
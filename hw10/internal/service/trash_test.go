@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"homework10/internal/auth"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	repoMock "homework10/internal/service/mock"
+	"testing"
+	"time"
+)
+
+func TestRestoreAd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	adRepo := repoMock.NewMockAdRepository(ctrl)
+	adService := NewAdService(adRepo, anyRevisions(ctrl))
+	adService.TrashRetention = time.Hour
+	now := time.Now().UTC()
+
+	testTable := []struct {
+		name    string
+		userID  int64
+		ad      *models.Ad
+		getErr  error
+		wantErr error
+	}{
+		{
+			name:   "true test RestoreAd()",
+			userID: 47,
+			ad:     &models.Ad{ID: 10, UserID: 47, Title: "title", DeletedAt: now.Add(-time.Minute)},
+		},
+		{
+			name:    "the ad does not exist",
+			userID:  47,
+			getErr:  domain.ErrAdNotExist,
+			wantErr: domain.ErrAdNotExist,
+		},
+		{
+			name:    "the ad of another user",
+			userID:  100,
+			ad:      &models.Ad{ID: 10, UserID: 47, DeletedAt: now.Add(-time.Minute)},
+			wantErr: domain.ErrForbidden,
+		},
+		{
+			name:    "the ad is not deleted",
+			userID:  47,
+			ad:      &models.Ad{ID: 10, UserID: 47},
+			wantErr: ErrAdNotInTrash,
+		},
+		{
+			name:    "the retention period has expired",
+			userID:  47,
+			ad:      &models.Ad{ID: 10, UserID: 47, DeletedAt: now.Add(-2 * time.Hour)},
+			wantErr: domain.ErrAdNotExist,
+		},
+	}
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctx := auth.WithUserID(context.Background(), testCase.userID)
+			adRepo.EXPECT().GetAd(ctx, int64(10)).Return(testCase.ad, testCase.getErr).Times(1)
+			if testCase.wantErr == nil {
+				restored := *testCase.ad
+				restored.DeletedAt = time.Time{}
+				adRepo.EXPECT().SetDeletedAt(ctx, int64(10), time.Time{}).Return(&restored, nil).Times(1)
+			}
+
+			ad, err := adService.RestoreAd(ctx, 10)
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+				assert.Nil(t, ad)
+				return
+			}
+			assert.NoError(t, err)
+			assert.False(t, ad.IsDeleted())
+		})
+	}
+}
+
+func TestListTrash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	adRepo := repoMock.NewMockAdRepository(ctrl)
+	adService := NewAdService(adRepo, anyRevisions(ctrl))
+	adService.TrashRetention = time.Hour
+	userService := NewUserService(repoMock.NewMockUserRepository(ctrl), adService)
+	now := time.Now().UTC()
+
+	ctx := auth.WithUserID(context.Background(), 47)
+	_, err := userService.ListTrash(ctx, 48)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+
+	_, err = userService.ListTrash(context.Background(), 47)
+	assert.ErrorIs(t, err, ErrNotAuthenticated)
+
+	fresh := &models.Ad{ID: 1, UserID: 47, DeletedAt: now.Add(-time.Minute)}
+	stale := &models.Ad{ID: 2, UserID: 47, DeletedAt: now.Add(-2 * time.Hour)}
+	adRepo.EXPECT().FindAds(ctx, models.AdFilter{AuthorIDs: []int64{47}, Deleted: models.OnlyDeleted}).
+		Return([]*models.Ad{fresh, stale}, nil).Times(1)
+	trash, err := userService.ListTrash(ctx, 47)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.TrashedAd{{Ad: fresh, PurgeAt: fresh.DeletedAt.Add(time.Hour)}}, trash)
+}
+
+func TestRestoreUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := repoMock.NewMockUserRepository(ctrl)
+	adRepo := repoMock.NewMockAdRepository(ctrl)
+	userService := NewUserService(userRepo, NewAdService(adRepo, anyRevisions(ctrl)))
+	userService.TrashRetention = time.Hour
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	deletedAt := time.Now().UTC().Add(-time.Minute)
+
+	testTable := []struct {
+		name     string
+		user     *models.User
+		password string
+		wantErr  error
+	}{
+		{
+			name:     "true test RestoreUser()",
+			user:     &models.User{ID: 7, PasswordHash: passwordHash, DeletedAt: deletedAt},
+			password: "password123",
+		},
+		{
+			name:     "wrong password",
+			user:     &models.User{ID: 7, PasswordHash: passwordHash, DeletedAt: deletedAt},
+			password: "password321",
+			wantErr:  ErrWrongPassword,
+		},
+		{
+			name:     "the user is not deleted",
+			user:     &models.User{ID: 7, PasswordHash: passwordHash},
+			password: "password123",
+			wantErr:  ErrUserNotInTrash,
+		},
+		{
+			name:     "the retention period has expired",
+			user:     &models.User{ID: 7, PasswordHash: passwordHash, DeletedAt: deletedAt.Add(-time.Hour)},
+			password: "password123",
+			wantErr:  domain.ErrUserNotExist,
+		},
+	}
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctx := context.Background()
+			userRepo.EXPECT().GetUser(ctx, int64(7)).Return(testCase.user, nil).Times(1)
+			if testCase.wantErr == nil {
+				restored := *testCase.user
+				restored.DeletedAt = time.Time{}
+				userRepo.EXPECT().SetDeletedAt(ctx, int64(7), time.Time{}).Return(&restored, nil).Times(1)
+
+				withUser := &models.Ad{ID: 1, UserID: 7, DeletedAt: deletedAt}
+				before := &models.Ad{ID: 2, UserID: 7, DeletedAt: deletedAt.Add(-time.Second)}
+				adRepo.EXPECT().FindAds(ctx, models.AdFilter{AuthorIDs: []int64{7}, Deleted: models.OnlyDeleted}).
+					Return([]*models.Ad{withUser, before}, nil).Times(1)
+				adRepo.EXPECT().SetDeletedAt(ctx, withUser.ID, time.Time{}).Return(&models.Ad{ID: 1, UserID: 7}, nil).Times(1)
+			}
+
+			user, err := userService.RestoreUser(ctx, 7, testCase.password)
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+				assert.Nil(t, user)
+				return
+			}
+			assert.NoError(t, err)
+			assert.False(t, user.IsDeleted())
+		})
+	}
+}
+
+func TestPurger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := repoMock.NewMockUserRepository(ctrl)
+	adRepo := repoMock.NewMockAdRepository(ctrl)
+	revisionRepo := repoMock.NewMockAdRevisionRepository(ctrl)
+	adService := NewAdService(adRepo, revisionRepo)
+	adService.TrashRetention = time.Hour
+	userService := NewUserService(userRepo, adService)
+	userService.TrashRetention = time.Hour
+	purger := NewPurger(adService, userService, time.Minute)
+	now := time.Now().UTC()
+	ctx := context.Background()
+
+	userRepo.EXPECT().GetDeletedUsers(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, before time.Time) ([]*models.User, error) {
+			assert.WithinDuration(t, now.Add(-time.Hour), before, time.Second)
+			return []*models.User{{ID: 7, DeletedAt: now.Add(-2 * time.Hour)}}, nil
+		}).Times(1)
+	adRepo.EXPECT().FindAds(ctx, models.AdFilter{AuthorIDs: []int64{7}, Deleted: models.WithDeleted}).
+		Return([]*models.Ad{{ID: 1, UserID: 7}}, nil).Times(1)
+	adRepo.EXPECT().DeleteAd(ctx, int64(1)).Return(nil).Times(1)
+	revisionRepo.EXPECT().DeleteRevisions(ctx, int64(1)).Return(nil).Times(1)
+	userRepo.EXPECT().Delete(ctx, int64(7)).Return(nil).Times(1)
+
+	adRepo.EXPECT().FindAds(ctx, models.AdFilter{Deleted: models.OnlyDeleted}).Return([]*models.Ad{
+		{ID: 2, UserID: 8, DeletedAt: now.Add(-2 * time.Hour)},
+		{ID: 3, UserID: 8, DeletedAt: now.Add(-time.Minute)},
+	}, nil).Times(1)
+	adRepo.EXPECT().DeleteAd(ctx, int64(2)).Return(nil).Times(1)
+	revisionRepo.EXPECT().DeleteRevisions(ctx, int64(2)).Return(nil).Times(1)
+
+	assert.NoError(t, purger.Purge(ctx))
+}
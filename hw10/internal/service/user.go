@@ -2,28 +2,62 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"homework10/internal/domain"
 	"homework10/internal/domain/models"
+	"homework10/internal/policy"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrNoAccessUser = errors.New("you don't have access to edit the user")
+	ErrNoAccessRole = errors.New("only an administrator can change user roles")
+	ErrUnknownRole  = domain.ErrInvalid{Err: errors.New("the role must be user, moderator or admin")}
 )
 
 type UserService struct {
 	UserRepo domain.UserRepository
+	// PasswordCost - стоимость bcrypt при хешировании паролей
+	PasswordCost int
+	// TrashRetention сколько удалённого пользователя можно восстановить
+	TrashRetention time.Duration
+	// Moderators и Admins ID пользователей, которым AssignRoles выдаёт роль модератора или администратора.
+	// Роли выдаются только по ID, заданным оператором: почту пользователь выбирает сам и не подтверждает
+	Moderators []int64
+	Admins     []int64
+	// Policy решает, кто может изменять и удалять пользователей
+	Policy *policy.Policy
+
+	ads *AdService
 }
 
-func NewUserService(userRepo domain.UserRepository) *UserService {
-	return &UserService{UserRepo: userRepo}
+// NewUserService создаёт сервис пользователей; ads переносит в корзину объявления удаляемых пользователей
+func NewUserService(userRepo domain.UserRepository, ads *AdService) *UserService {
+	return &UserService{UserRepo: userRepo, PasswordCost: bcrypt.DefaultCost, TrashRetention: DefaultTrashRetention,
+		Policy: policy.Default(), ads: ads}
 }
 
+// GetUser возвращает пользователя; удалённые пользователи считаются несуществующими
 func (s *UserService) GetUser(ctx context.Context, userID int64) (*models.User, error) {
 	user, err := s.UserRepo.GetUser(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
+	if user.IsDeleted() {
+		return nil, domain.ErrUserNotExist
+	}
 	return user, nil
 }
 
-func (s *UserService) CreateUser(ctx context.Context, nickName string, email string) (*models.User, error) {
-	user := models.User{NickName: nickName, Email: email}
+func (s *UserService) CreateUser(ctx context.Context, nickName string, email string, password string) (*models.User, error) {
+	passwordHash, err := hashPassword(password, s.PasswordCost)
+	if err != nil {
+		return nil, err
+	}
+	user := models.User{NickName: nickName, Email: email, Role: models.RoleUser, PasswordHash: passwordHash}
 	userID, err := s.UserRepo.AddUser(ctx, user)
 	if err != nil {
 		return nil, err
@@ -33,6 +67,12 @@ func (s *UserService) CreateUser(ctx context.Context, nickName string, email str
 }
 
 func (s *UserService) UpdateUser(ctx context.Context, userID int64, nickName string, email string) (*models.User, error) {
+	if err := authorize(ctx, s.Policy, policy.UpdateUser, userID, ErrNoAccess{Err: ErrNoAccessUser}); err != nil {
+		return nil, err
+	}
+	if _, err := s.GetUser(ctx, userID); err != nil {
+		return nil, err
+	}
 	user, err := s.UserRepo.Update(ctx, userID, nickName, email)
 	if err != nil {
 		return nil, err
@@ -40,10 +80,96 @@ func (s *UserService) UpdateUser(ctx context.Context, userID int64, nickName str
 	return user, nil
 }
 
-func (s *UserService) DeleteUser(ctx context.Context, userID int64) error {
-	_, err := s.UserRepo.GetUser(ctx, userID)
+// ChangePassword меняет пароль аутентифицированного пользователя после проверки текущего пароля
+func (s *UserService) ChangePassword(ctx context.Context, userID int64, oldPassword string, newPassword string) error {
+	if err := authorize(ctx, s.Policy, policy.ChangePassword, userID, ErrNoAccess{Err: ErrNoAccessUser}); err != nil {
+		return err
+	}
+	user, err := s.GetUser(ctx, userID)
 	if err != nil {
 		return err
 	}
-	return s.UserRepo.Delete(ctx, userID)
+	if !checkPassword(user.PasswordHash, oldPassword) {
+		return ErrWrongPassword
+	}
+	passwordHash, err := hashPassword(newPassword, s.PasswordCost)
+	if err != nil {
+		return err
+	}
+	return s.UserRepo.SetPasswordHash(ctx, userID, passwordHash)
+}
+
+// DeleteUser помечает пользователя удалённым и переносит в корзину все его объявления. В течение
+// TrashRetention пользователя можно восстановить вместе с ними, после этого Purger удаляет всё окончательно
+func (s *UserService) DeleteUser(ctx context.Context, userID int64) error {
+	if err := authorize(ctx, s.Policy, policy.DeleteUser, userID, ErrNoAccess{Err: ErrNoAccessUser}); err != nil {
+		return err
+	}
+	if _, err := s.GetUser(ctx, userID); err != nil {
+		return err
+	}
+	deletedAt := now()
+	if _, err := s.UserRepo.SetDeletedAt(ctx, userID, deletedAt); err != nil {
+		return err
+	}
+	if err := s.ads.deleteUserAds(ctx, userID, deletedAt); err != nil {
+		return fmt.Errorf("deleting user ads: %w", err)
+	}
+	return nil
+}
+
+// AssignRoles выдаёт роли из Moderators и Admins при запуске, так оператор назначает первого администратора.
+// Несуществующие и удалённые пользователи пропускаются. Роли у пользователей, которых убрали из списков, не отзываются
+func (s *UserService) AssignRoles(ctx context.Context) error {
+	for _, userID := range append(append([]int64{}, s.Moderators...), s.Admins...) {
+		user, err := s.GetUser(ctx, userID)
+		if errors.Is(err, domain.ErrUserNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		role := s.roleFor(userID)
+		if user.Role == role {
+			continue
+		}
+		if _, err := s.UserRepo.SetRole(ctx, userID, role); err != nil {
+			return fmt.Errorf("assigning role %s to user %d: %w", role, userID, err)
+		}
+	}
+	return nil
+}
+
+// SetUserRole меняет роль пользователя; это может только администратор
+func (s *UserService) SetUserRole(ctx context.Context, userID int64, role models.Role) (*models.User, error) {
+	if err := authorize(ctx, s.Policy, policy.AssignRole, noOwner, ErrNoAccess{Err: ErrNoAccessRole}); err != nil {
+		return nil, err
+	}
+	if !role.Valid() {
+		return nil, ErrUnknownRole
+	}
+	if _, err := s.GetUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.UserRepo.SetRole(ctx, userID, role)
+}
+
+// roleFor роль из Moderators и Admins для пользователя userID; администратор важнее модератора
+func (s *UserService) roleFor(userID int64) models.Role {
+	if contains(s.Admins, userID) {
+		return models.RoleAdmin
+	}
+	if contains(s.Moderators, userID) {
+		return models.RoleModerator
+	}
+	return models.RoleUser
+}
+
+func contains(list []int64, value int64) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
 }
@@ -5,9 +5,14 @@ import (
 	"fmt"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"homework10/internal/auth"
+	"homework10/internal/domain"
 	"homework10/internal/domain/models"
 	repoMock "homework10/internal/service/mock"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestCreateUser(t *testing.T) {
@@ -15,15 +20,17 @@ func TestCreateUser(t *testing.T) {
 	defer ctrl.Finish()
 
 	userRepo := repoMock.NewMockUserRepository(ctrl)
-	userService := NewUserService(userRepo)
+	userService := NewUserService(userRepo, nil)
+	userService.PasswordCost = bcrypt.MinCost
 
 	testTable := []struct {
-		name    string
-		userID  int64
-		inUser  *models.User
-		outUser *models.User
-		repoErr error
-		wantErr bool
+		name     string
+		userID   int64
+		inUser   *models.User
+		password string
+		outUser  *models.User
+		repoErr  error
+		wantErr  error
 	}{
 		{
 			name:   "true test CreateUser()",
@@ -32,38 +39,175 @@ func TestCreateUser(t *testing.T) {
 				NickName: "Ivan",
 				Email:    "Ivan@gmail.com",
 			},
+			password: "password123",
 			outUser: &models.User{
 				ID:       100,
 				NickName: "Ivan",
 				Email:    "Ivan@gmail.com",
 			},
-			wantErr: false,
 		},
 		{
-			name:   "error from repository GetUser()",
+			name:   "error from repository AddUser()",
 			userID: 0,
 			inUser: &models.User{
 				NickName: "Ivan",
 				Email:    "Ivan@gmail.com",
 			},
-			repoErr: fmt.Errorf("error from repo GetUser()"),
-			wantErr: true,
+			password: "password123",
+			repoErr:  domain.ErrAlreadyExists{Err: domain.ErrEmailTaken},
+			wantErr:  domain.ErrEmailTaken,
+		},
+		{
+			name: "password is too short",
+			inUser: &models.User{
+				NickName: "Ivan",
+				Email:    "Ivan@gmail.com",
+			},
+			password: "pass",
+			wantErr:  ErrPasswordTooShort,
+		},
+		{
+			name: "password is too long",
+			inUser: &models.User{
+				NickName: "Ivan",
+				Email:    "Ivan@gmail.com",
+			},
+			password: strings.Repeat("p", 73),
+			wantErr:  ErrPasswordTooLong,
 		},
 	}
 
 	for _, testCase := range testTable {
 		t.Run(testCase.name, func(t *testing.T) {
 			ctx := context.Background()
-			userRepo.EXPECT().AddUser(ctx, *testCase.inUser).Return(testCase.userID, testCase.repoErr).Times(1)
+			if testCase.outUser != nil || testCase.repoErr != nil {
+				userRepo.EXPECT().AddUser(ctx, gomock.Any()).DoAndReturn(
+					func(_ context.Context, user models.User) (int64, error) {
+						assert.Equal(t, testCase.inUser.NickName, user.NickName)
+						assert.Equal(t, testCase.inUser.Email, user.Email)
+						assert.Equal(t, models.RoleUser, user.Role)
+						assert.True(t, checkPassword(user.PasswordHash, testCase.password))
+						return testCase.userID, testCase.repoErr
+					}).Times(1)
+			}
 
-			user, err := userService.CreateUser(ctx, testCase.inUser.NickName, testCase.inUser.Email)
-			if testCase.wantErr {
-				assert.Error(t, err)
+			user, err := userService.CreateUser(ctx, testCase.inUser.NickName, testCase.inUser.Email, testCase.password)
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
 				assert.Nil(t, user)
 				return
 			}
 			assert.NoError(t, err)
-			assert.Equal(t, *testCase.outUser, *user)
+			assert.Equal(t, testCase.outUser.ID, user.ID)
+			assert.Equal(t, testCase.outUser.NickName, user.NickName)
+			assert.Equal(t, testCase.outUser.Email, user.Email)
+			assert.NotEqual(t, []byte(testCase.password), user.PasswordHash)
+		})
+	}
+}
+
+func TestAssignRoles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	userRepo := repoMock.NewMockUserRepository(ctrl)
+	userService := NewUserService(userRepo, nil)
+	userService.PasswordCost = bcrypt.MinCost
+	userService.Moderators = []int64{1, 2, 3, 5, 6}
+	userService.Admins = []int64{3, 4}
+
+	// роль не зависит от почты, которую выбрал пользователь
+	userRepo.EXPECT().AddUser(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, user models.User) (int64, error) {
+		assert.Equal(t, models.RoleUser, user.Role)
+		return 7, nil
+	}).Times(1)
+	_, err := userService.CreateUser(ctx, "admin", "admin@gmail.com", "password123")
+	assert.NoError(t, err)
+
+	userRepo.EXPECT().GetUser(ctx, int64(1)).Return(&models.User{ID: 1, Role: models.RoleModerator}, nil).Times(1)
+	userRepo.EXPECT().GetUser(ctx, int64(2)).Return(&models.User{ID: 2, Role: models.RoleUser}, nil).Times(1)
+	userRepo.EXPECT().SetRole(ctx, int64(2), models.RoleModerator).Return(&models.User{ID: 2}, nil).Times(1)
+	// администратор из обоих списков получает роль администратора
+	userRepo.EXPECT().GetUser(ctx, int64(3)).Return(&models.User{ID: 3}, nil).Times(1)
+	userRepo.EXPECT().SetRole(ctx, int64(3), models.RoleAdmin).Return(&models.User{ID: 3, Role: models.RoleAdmin}, nil).Times(1)
+	userRepo.EXPECT().GetUser(ctx, int64(3)).Return(&models.User{ID: 3, Role: models.RoleAdmin}, nil).Times(1)
+	// несуществующие и удалённые пользователи пропускаются
+	userRepo.EXPECT().GetUser(ctx, int64(5)).Return(nil, domain.ErrUserNotExist).Times(1)
+	userRepo.EXPECT().GetUser(ctx, int64(6)).Return(&models.User{ID: 6, DeletedAt: time.Now()}, nil).Times(1)
+	userRepo.EXPECT().GetUser(ctx, int64(4)).Return(&models.User{ID: 4}, nil).Times(1)
+	userRepo.EXPECT().SetRole(ctx, int64(4), models.RoleAdmin).Return(&models.User{ID: 4}, nil).Times(1)
+	assert.NoError(t, userService.AssignRoles(ctx))
+}
+
+func TestSetUserRole(t *testing.T) {
+	adminCtx := auth.WithRole(auth.WithUserID(context.Background(), 7), models.RoleAdmin)
+
+	testTable := []struct {
+		name    string
+		ctx     context.Context
+		role    models.Role
+		getUser error
+		wantErr error
+	}{
+		{
+			name: "true test SetUserRole()",
+			ctx:  adminCtx,
+			role: models.RoleModerator,
+		},
+		{
+			name:    "not authenticated",
+			ctx:     context.Background(),
+			role:    models.RoleModerator,
+			wantErr: ErrNotAuthenticated,
+		},
+		{
+			name:    "user promotes himself",
+			ctx:     auth.WithUserID(context.Background(), 100),
+			role:    models.RoleAdmin,
+			wantErr: ErrNoAccessRole,
+		},
+		{
+			name:    "moderator",
+			ctx:     moderatorContext(7),
+			role:    models.RoleModerator,
+			wantErr: ErrNoAccessRole,
+		},
+		{
+			name:    "unknown role",
+			ctx:     adminCtx,
+			role:    "superuser",
+			wantErr: ErrUnknownRole,
+		},
+		{
+			name:    "user not exist",
+			ctx:     adminCtx,
+			role:    models.RoleModerator,
+			getUser: domain.ErrUserNotExist,
+			wantErr: domain.ErrUserNotExist,
+		},
+	}
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			userRepo := repoMock.NewMockUserRepository(ctrl)
+			userService := NewUserService(userRepo, nil)
+
+			if testCase.getUser != nil {
+				userRepo.EXPECT().GetUser(testCase.ctx, int64(100)).Return(nil, testCase.getUser).Times(1)
+			} else if testCase.wantErr == nil {
+				userRepo.EXPECT().GetUser(testCase.ctx, int64(100)).Return(&models.User{ID: 100}, nil).Times(1)
+				userRepo.EXPECT().SetRole(testCase.ctx, int64(100), testCase.role).
+					Return(&models.User{ID: 100, Role: testCase.role}, nil).Times(1)
+			}
+
+			user, err := userService.SetUserRole(testCase.ctx, 100, testCase.role)
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.role, user.Role)
 		})
 	}
 }
@@ -73,7 +217,7 @@ func TestGetUser(t *testing.T) {
 	defer ctrl.Finish()
 
 	userRepo := repoMock.NewMockUserRepository(ctrl)
-	userService := NewUserService(userRepo)
+	userService := NewUserService(userRepo, nil)
 
 	testTable := []struct {
 		name     string
@@ -122,7 +266,7 @@ func TestUpdateUser(t *testing.T) {
 	defer ctrl.Finish()
 
 	userRepo := repoMock.NewMockUserRepository(ctrl)
-	userService := NewUserService(userRepo)
+	userService := NewUserService(userRepo, nil)
 
 	testTable := []struct {
 		name     string
@@ -159,7 +303,8 @@ func TestUpdateUser(t *testing.T) {
 	}
 	for _, testCase := range testTable {
 		t.Run(testCase.name, func(t *testing.T) {
-			ctx := context.Background()
+			ctx := auth.WithUserID(context.Background(), testCase.inUser.ID)
+			userRepo.EXPECT().GetUser(ctx, testCase.inUser.ID).Return(&models.User{ID: testCase.inUser.ID}, nil).Times(1)
 			userRepo.EXPECT().Update(ctx, testCase.inUser.ID, testCase.inUser.NickName, testCase.inUser.Email).
 				Return(testCase.expected, testCase.repoErr).Times(1)
 
@@ -186,50 +331,220 @@ func TestDeleteUser(t *testing.T) {
 	defer ctrl.Finish()
 
 	userRepo := repoMock.NewMockUserRepository(ctrl)
-	userService := NewUserService(userRepo)
+	adRepo := repoMock.NewMockAdRepository(ctrl)
+	userService := NewUserService(userRepo, NewAdService(adRepo, anyRevisions(ctrl)))
+	errRepo := fmt.Errorf("error from SetDeletedAt()")
 
 	testTable := []struct {
 		name      string
 		userID    int64
-		getAdErr  error
+		user      *models.User
+		getErr    error
 		deleteErr error
-		wantErr   bool
+		wantErr   error
 	}{
 		{
-			name:    "true test",
+			name:   "true test",
+			userID: 100,
+			user:   &models.User{ID: 100},
+		},
+		{
+			name:    "error from GetUser()",
 			userID:  100,
-			wantErr: false,
+			getErr:  domain.ErrUserNotExist,
+			wantErr: domain.ErrUserNotExist,
 		},
 		{
-			name:     "error from GetUser()",
-			userID:   100,
-			getAdErr: fmt.Errorf("error from GetUser()"),
-			wantErr:  true,
+			name:    "the user is already deleted",
+			userID:  100,
+			user:    &models.User{ID: 100, DeletedAt: time.Now().UTC()},
+			wantErr: domain.ErrUserNotExist,
 		},
 		{
-			name:      "error from DeleteUser()",
+			name:      "error from SetDeletedAt()",
 			userID:    100,
-			deleteErr: fmt.Errorf("error from DeleteUser()"),
-			wantErr:   true,
+			user:      &models.User{ID: 100},
+			deleteErr: errRepo,
+			wantErr:   errRepo,
 		},
 	}
 	for _, testCase := range testTable {
 		t.Run(testCase.name, func(t *testing.T) {
-			ctx := context.Background()
+			ctx := auth.WithUserID(context.Background(), testCase.userID)
 			userRepo.EXPECT().GetUser(ctx, testCase.userID).
-				Return(nil, testCase.getAdErr).Times(1)
+				Return(testCase.user, testCase.getErr).Times(1)
 
-			if testCase.getAdErr == nil {
-				userRepo.EXPECT().Delete(ctx, testCase.userID).Return(testCase.deleteErr).Times(1)
+			var deletedAt time.Time
+			if testCase.user != nil && !testCase.user.IsDeleted() {
+				userRepo.EXPECT().SetDeletedAt(ctx, testCase.userID, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ int64, at time.Time) (*models.User, error) {
+						deletedAt = at
+						return testCase.user, testCase.deleteErr
+					}).Times(1)
+			}
+			if testCase.wantErr == nil {
+				ad := &models.Ad{ID: 5, UserID: testCase.userID}
+				adRepo.EXPECT().FindAds(ctx, models.AdFilter{AuthorIDs: []int64{testCase.userID}}).
+					Return([]*models.Ad{ad}, nil).Times(1)
+				adRepo.EXPECT().SetDeletedAt(ctx, ad.ID, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ int64, at time.Time) (*models.Ad, error) {
+						assert.Equal(t, deletedAt, at, "ads are deleted together with the user")
+						return ad, nil
+					}).Times(1)
 			}
 
 			err := userService.DeleteUser(ctx, testCase.userID)
 
-			if testCase.wantErr {
-				assert.Error(t, err)
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestUserAccess(t *testing.T) {
+	testTable := []struct {
+		name    string
+		ctx     context.Context
+		wantErr error
+	}{
+		{
+			name:    "unauthenticated",
+			ctx:     context.Background(),
+			wantErr: ErrNotAuthenticated,
+		},
+		{
+			name:    "another user",
+			ctx:     auth.WithUserID(context.Background(), 7),
+			wantErr: ErrNoAccessUser,
+		},
+		{
+			name:    "moderator",
+			ctx:     moderatorContext(7),
+			wantErr: ErrNoAccessUser,
+		},
+		{
+			name: "admin",
+			ctx:  auth.WithRole(auth.WithUserID(context.Background(), 7), models.RoleAdmin),
+		},
+	}
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			userRepo := repoMock.NewMockUserRepository(ctrl)
+			userService := NewUserService(userRepo, nil)
+
+			if testCase.wantErr == nil {
+				userRepo.EXPECT().GetUser(testCase.ctx, int64(100)).Return(&models.User{ID: 100}, nil).Times(1)
+				userRepo.EXPECT().Update(testCase.ctx, int64(100), "ivan", "ivan@gmail.com").
+					Return(&models.User{ID: 100, NickName: "ivan", Email: "ivan@gmail.com"}, nil).Times(1)
+			}
+
+			_, err := userService.UpdateUser(testCase.ctx, 100, "ivan", "ivan@gmail.com")
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+				assert.ErrorIs(t, userService.DeleteUser(testCase.ctx, 100), testCase.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestChangePassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := repoMock.NewMockUserRepository(ctrl)
+	userService := NewUserService(userRepo, nil)
+	userService.PasswordCost = bcrypt.MinCost
+
+	oldHash, err := bcrypt.GenerateFromPassword([]byte("old password"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	testTable := []struct {
+		name        string
+		authUserID  *int64
+		userID      int64
+		oldPassword string
+		newPassword string
+		getUser     bool
+		setHash     bool
+		wantErr     error
+	}{
+		{
+			name:        "true test ChangePassword()",
+			authUserID:  int64Ptr(7),
+			userID:      7,
+			oldPassword: "old password",
+			newPassword: "new password",
+			getUser:     true,
+			setHash:     true,
+		},
+		{
+			name:        "not authenticated",
+			userID:      7,
+			oldPassword: "old password",
+			newPassword: "new password",
+			wantErr:     ErrNotAuthenticated,
+		},
+		{
+			name:        "password of another user",
+			authUserID:  int64Ptr(8),
+			userID:      7,
+			oldPassword: "old password",
+			newPassword: "new password",
+			wantErr:     ErrNoAccessUser,
+		},
+		{
+			name:        "wrong current password",
+			authUserID:  int64Ptr(7),
+			userID:      7,
+			oldPassword: "wrong password",
+			newPassword: "new password",
+			getUser:     true,
+			wantErr:     ErrWrongPassword,
+		},
+		{
+			name:        "new password is too short",
+			authUserID:  int64Ptr(7),
+			userID:      7,
+			oldPassword: "old password",
+			newPassword: "new",
+			getUser:     true,
+			wantErr:     ErrPasswordTooShort,
+		},
+	}
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctx := context.Background()
+			if testCase.authUserID != nil {
+				ctx = auth.WithUserID(ctx, *testCase.authUserID)
+			}
+			if testCase.getUser {
+				userRepo.EXPECT().GetUser(ctx, testCase.userID).
+					Return(&models.User{ID: testCase.userID, PasswordHash: oldHash}, nil).Times(1)
+			}
+			if testCase.setHash {
+				userRepo.EXPECT().SetPasswordHash(ctx, testCase.userID, gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ int64, passwordHash []byte) error {
+						assert.True(t, checkPassword(passwordHash, testCase.newPassword))
+						return nil
+					}).Times(1)
+			}
+
+			err := userService.ChangePassword(ctx, testCase.userID, testCase.oldPassword, testCase.newPassword)
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
 				return
 			}
 			assert.NoError(t, err)
 		})
 	}
 }
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"errors"
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+
+	"github.com/ilgizjan1/publication"
+)
+
+// validateAd проверяет объявление библиотекой publication и описывает каждую ошибку полем и нарушенным правилом
+func validateAd(ad models.Ad) error {
+	err := publication.Validate(ad)
+	var pubErrs publication.ValidationErrors
+	if !errors.As(err, &pubErrs) {
+		return err
+	}
+	errs := make(domain.ValidationErrors, 0, len(pubErrs))
+	for _, e := range pubErrs {
+		switch e.Err {
+		case publication.ErrInvalidTitle:
+			errs = append(errs, stringFieldError("Title", ad.Title, e.Err))
+		case publication.ErrInvalidText:
+			errs = append(errs, stringFieldError("Text", ad.Text, e.Err))
+		default:
+			errs = append(errs, domain.FieldError{Rule: "invalid", Message: e.Err.Error()})
+		}
+	}
+	return errs
+}
+
+// stringFieldError publication отклоняет пустые (required) и слишком длинные (max) строки
+func stringFieldError(field string, value string, err error) domain.FieldError {
+	rule := "max"
+	if value == "" {
+		rule = "required"
+	}
+	return domain.FieldError{Field: field, Rule: rule, Message: err.Error()}
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"homework10/internal/domain"
+	"homework10/internal/domain/models"
+	"strings"
+	"testing"
+
+	"github.com/ilgizjan1/publication"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAd(t *testing.T) {
+	tests := []struct {
+		name     string
+		ad       models.Ad
+		expected error
+	}{
+		{
+			name: "valid ad",
+			ad:   models.Ad{Title: "title", Text: "text"},
+		},
+		{
+			name: "empty title",
+			ad:   models.Ad{Title: "", Text: "text"},
+			expected: domain.ValidationErrors{
+				{Field: "Title", Rule: "required", Message: publication.ErrInvalidTitle.Error()},
+			},
+		},
+		{
+			name: "empty title and too long text",
+			ad:   models.Ad{Title: "", Text: strings.Repeat("a", 501)},
+			expected: domain.ValidationErrors{
+				{Field: "Title", Rule: "required", Message: publication.ErrInvalidTitle.Error()},
+				{Field: "Text", Rule: "max", Message: publication.ErrInvalidText.Error()},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAd(tt.ad)
+			assert.Equal(t, tt.expected, err)
+			if tt.expected != nil {
+				assert.ErrorIs(t, err, domain.ErrValidation)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogin(t *testing.T) {
+	client := getTestClient()
+
+	_, err := client.createUser("user_1", "email@gmail.com")
+	assert.NoError(t, err)
+
+	response, err := client.login("email@gmail.com", testPassword)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, response.Data.Token)
+	assert.False(t, response.Data.ExpiresAt.IsZero())
+}
+
+func TestLoginUnknownEmail(t *testing.T) {
+	client := getTestClient()
+
+	_, err := client.login("email@gmail.com", testPassword)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestLoginWrongPassword(t *testing.T) {
+	client := getTestClient()
+
+	_, err := client.createUser("user_1", "email@gmail.com")
+	assert.NoError(t, err)
+
+	_, err = client.login("email@gmail.com", "wrong password")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestChangePassword(t *testing.T) {
+	client := getTestClient()
+
+	user, err := client.createUser("user_1", "email@gmail.com")
+	assert.NoError(t, err)
+
+	err = client.changePassword(user.Data.ID, "wrong password", "new password")
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	err = client.changePassword(user.Data.ID, testPassword, "new password")
+	assert.NoError(t, err)
+
+	_, err = client.login("email@gmail.com", testPassword)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	_, err = client.login("email@gmail.com", "new password")
+	assert.NoError(t, err)
+}
+
+func TestChangePasswordOfAnotherUser(t *testing.T) {
+	client := getTestClient()
+
+	user, err := client.createUser("user_1", "email@gmail.com")
+	assert.NoError(t, err)
+
+	userSecond, err := client.createUser("user_2", "email_2@gmail.com")
+	assert.NoError(t, err)
+	client.tokens[user.Data.ID] = client.tokens[userSecond.Data.ID]
+
+	err = client.changePassword(user.Data.ID, testPassword, "new password")
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestCreateUserWithTakenEmail(t *testing.T) {
+	client := getTestClient()
+
+	_, err := client.createUser("user_1", "email@gmail.com")
+	assert.NoError(t, err)
+
+	_, err = client.createUser("user_2", "email@gmail.com")
+	assert.ErrorIs(t, err, ErrConflict)
+
+	_, err = client.createUser("user_1", "email_2@gmail.com")
+	assert.ErrorIs(t, err, ErrConflict)
+}
+
+func TestCreateAdWithoutToken(t *testing.T) {
+	client := getTestClient()
+
+	user, err := client.createUser("user_1", "email@gmail.com")
+	assert.NoError(t, err)
+	delete(client.tokens, user.Data.ID)
+
+	_, err = client.createAd(user.Data.ID, "hello", "world")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestCreateAdWithForgedToken(t *testing.T) {
+	client := getTestClient()
+
+	user, err := client.createUser("user_1", "email@gmail.com")
+	assert.NoError(t, err)
+	client.tokens[user.Data.ID] = "forged.token.value"
+
+	_, err = client.createAd(user.Data.ID, "hello", "world")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestDeleteAdOfAnotherUser(t *testing.T) {
+	client := getTestClient()
+
+	user, err := client.createUser("user_1", "email@gmail.com")
+	assert.NoError(t, err)
+
+	resp, err := client.createAd(user.Data.ID, "hello", "world")
+	assert.NoError(t, err)
+
+	userSecond, err := client.createUser("user_2", "email_2@gmail.com")
+	assert.NoError(t, err)
+
+	_, err = client.deleteAd(userSecond.Data.ID, resp.Data.ID)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	_, err = client.getAd(user.Data.ID, resp.Data.ID)
+	assert.NoError(t, err)
+}
+
+func TestDeletedUserTokenIsRejected(t *testing.T) {
+	client := getTestClient()
+
+	user, err := client.createUser("user_1", "email@gmail.com")
+	assert.NoError(t, err)
+
+	_, err = client.deleteUser(user.Data.ID)
+	assert.NoError(t, err)
+
+	_, err = client.createAd(user.Data.ID, "hello", "world")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestAdminManagesOthersAds(t *testing.T) {
+	client := getTestClient()
+
+	author, err := client.createUser("author", "author@gmail.com")
+	assert.NoError(t, err)
+	ad, err := client.createAd(author.Data.ID, "hello", "world")
+	assert.NoError(t, err)
+
+	adminID, err := client.admin()
+	assert.NoError(t, err)
+	admin, err := client.getUser(adminID)
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", admin.Data.Role)
+
+	updated, err := client.updateAd(adminID, ad.Data.ID, "edited by admin", "world")
+	assert.NoError(t, err)
+	assert.Equal(t, author.Data.ID, updated.Data.AuthorID)
+
+	_, err = client.moderationQueue(adminID)
+	assert.NoError(t, err)
+
+	_, err = client.deleteAd(adminID, ad.Data.ID)
+	assert.NoError(t, err)
+}
+
+func TestRegistrationDoesNotGrantRoles(t *testing.T) {
+	client := getTestClient()
+
+	for nickname, email := range map[string]string{"moderator": testModeratorEmail, "admin": testAdminEmail} {
+		user, err := client.createUser(nickname, email)
+		assert.NoError(t, err)
+		assert.Equal(t, "user", user.Data.Role, email)
+	}
+}
+
+func TestSetUserRole(t *testing.T) {
+	client := getTestClient()
+
+	user, err := client.createUser("ivan", "ivan@gmail.com")
+	assert.NoError(t, err)
+	other, err := client.createUser("oleg", "oleg@gmail.com")
+	assert.NoError(t, err)
+
+	// роли выдаёт только администратор, в том числе себе самому их не выдать
+	_, err = client.setUserRole(user.Data.ID, user.Data.ID, "admin")
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = client.setUserRole(user.Data.ID, other.Data.ID, "moderator")
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	adminID, err := client.admin()
+	assert.NoError(t, err)
+	_, err = client.setUserRole(adminID, user.Data.ID, "superuser")
+	assert.ErrorIs(t, err, ErrBadRequest)
+	_, err = client.setUserRole(adminID, 100, "moderator")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	moderator, err := client.setUserRole(adminID, user.Data.ID, "moderator")
+	assert.NoError(t, err)
+	assert.Equal(t, "moderator", moderator.Data.Role)
+	_, err = client.moderationQueue(user.Data.ID)
+	assert.NoError(t, err, "the role applies to the existing token")
+
+	demoted, err := client.setUserRole(adminID, user.Data.ID, "user")
+	assert.NoError(t, err)
+	assert.Equal(t, "user", demoted.Data.Role)
+	_, err = client.moderationQueue(user.Data.ID)
+	assert.ErrorIs(t, err, ErrForbidden)
+}
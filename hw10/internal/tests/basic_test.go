@@ -29,6 +29,8 @@ func TestChangeAdStatus(t *testing.T) {
 	response, err := client.createAd(user.Data.ID, "hello", "world")
 	assert.NoError(t, err)
 
+	assert.NoError(t, client.approveAd(user.Data.ID, response.Data.ID))
+
 	response, err = client.changeAdStatus(user.Data.ID, response.Data.ID, true)
 	assert.NoError(t, err)
 	assert.True(t, response.Data.Published)
@@ -64,6 +66,8 @@ func TestListAds(t *testing.T) {
 	response, err := client.createAd(user.Data.ID, "hello", "world")
 	assert.NoError(t, err)
 
+	assert.NoError(t, client.approveAd(user.Data.ID, response.Data.ID))
+
 	publishedAd, err := client.changeAdStatus(user.Data.ID, response.Data.ID, true)
 	assert.NoError(t, err)
 
@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	contracts "homework10/internal/api/handlers/grpc/contracts/langs/go"
+)
+
+// createPublishedAd создаёт объявление с характеристиками, проводит его через модерацию и публикует
+func createPublishedAd(t *testing.T, client *testClient, userID int64, body map[string]any) adData {
+	ad, err := client.createAdWithDetails(userID, body)
+	require.NoError(t, err)
+	require.NoError(t, client.approveAd(userID, ad.Data.ID))
+	_, err = client.changeAdStatus(userID, ad.Data.ID, true)
+	require.NoError(t, err)
+	return ad.Data
+}
+
+func TestCreateAdWithDetails(t *testing.T) {
+	client := getTestClient()
+	user, err := client.createUser("user_1", "email@gmail.com")
+	assert.NoError(t, err)
+
+	ad, err := client.createAdWithDetails(user.Data.ID, map[string]any{
+		"title":       "bicycle",
+		"text":        "almost new",
+		"price":       map[string]any{"amount": 1500000, "currency": "rub"},
+		"category_id": "cars",
+		"city":        " Казань ",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &priceData{Amount: 1500000, Currency: "RUB"}, ad.Data.Price)
+	assert.Equal(t, "cars", ad.Data.CategoryID)
+	assert.Equal(t, "Казань", ad.Data.City)
+
+	got, err := client.getAd(user.Data.ID, ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, ad.Data.Price, got.Data.Price)
+
+	_, err = client.createAdWithDetails(user.Data.ID, map[string]any{
+		"title":       "bicycle",
+		"text":        "almost new",
+		"price":       map[string]any{"amount": 100},
+		"category_id": "boats",
+	})
+	assert.ErrorIs(t, err, ErrBadRequest)
+}
+
+func TestListAdsCatalog(t *testing.T) {
+	client := getTestClient()
+	user, err := client.createUser("user_1", "email@gmail.com")
+	assert.NoError(t, err)
+
+	car := createPublishedAd(t, client, user.Data.ID, map[string]any{
+		"title": "car", "text": "sedan", "category_id": "cars", "city": "Москва",
+		"price": map[string]any{"amount": 100000000, "currency": "RUB"},
+	})
+	createPublishedAd(t, client, user.Data.ID, map[string]any{
+		"title": "motorcycle", "text": "fast", "category_id": "motorcycles", "city": "москва",
+		"price": map[string]any{"amount": 30000000, "currency": "RUB"},
+	})
+	createPublishedAd(t, client, user.Data.ID, map[string]any{
+		"title": "flat", "text": "two rooms", "category_id": "apartments", "city": "Казань",
+	})
+
+	ads, err := client.listAdsByQuery(url.Values{"category": {"transport"}})
+	assert.NoError(t, err)
+	assert.Len(t, ads.Data, 2)
+	assert.Equal(t, []facetCountData{
+		{Value: "transport", Count: 2},
+		{Value: "cars", Count: 1},
+		{Value: "motorcycles", Count: 1},
+	}, ads.Facets.Categories)
+	assert.Equal(t, []facetCountData{{Value: "Москва", Count: 2}}, ads.Facets.Cities)
+
+	ads, err = client.listAdsByQuery(url.Values{
+		"price_min": {"50000000"}, "currency": {"RUB"}, "city": {"МОСКВА"},
+	})
+	assert.NoError(t, err)
+	require.Len(t, ads.Data, 1)
+	assert.Equal(t, car.ID, ads.Data[0].ID)
+
+	_, err = client.listAdsByQuery(url.Values{"category": {"boats"}})
+	assert.ErrorIs(t, err, ErrBadRequest)
+	_, err = client.listAdsByQuery(url.Values{"price_min": {"10"}, "price_max": {"5"}, "currency": {"RUB"}})
+	assert.ErrorIs(t, err, ErrBadRequest)
+	_, err = client.listAdsByQuery(url.Values{"price_min": {"10"}})
+	assert.ErrorIs(t, err, ErrBadRequest, "a price range needs a currency")
+}
+
+func TestListCategories(t *testing.T) {
+	client := getTestClient()
+
+	categories, err := client.listCategories()
+	assert.NoError(t, err)
+	require.NotEmpty(t, categories.Data)
+	assert.Contains(t, categories.Data, categoryData{ID: "cars", Name: "Автомобили", ParentID: "transport"})
+}
+
+func TestGRRPCListAdsCatalog(t *testing.T) {
+	ctx, conn := newGRPCTestConn(t)
+
+	_, err := contracts.NewUserServiceClient(conn).CreateUser(ctx,
+		&contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com", Password: testPassword})
+	assert.NoError(t, err, "client.CreateUser")
+	authCtx := grpcLogin(t, ctx, conn, "olega@gmail.com")
+
+	clientAd := contracts.NewAdServiceClient(conn)
+	res, err := clientAd.CreateAd(authCtx, &contracts.CreateAdRequest{Title: "car", Text: "sedan",
+		Price: &contracts.Price{Amount: 100000000, Currency: "RUB"}, CategoryId: "cars", City: "Москва"})
+	assert.NoError(t, err, "client.CreateAd")
+	assert.Equal(t, "RUB", res.GetPrice().GetCurrency())
+	grpcApproveAd(t, authCtx, conn, res.Id)
+	_, err = clientAd.ChangeAdStatus(authCtx, &contracts.ChangeAdStatusRequest{AdId: res.Id, Published: true})
+	assert.NoError(t, err, "client.ChangeAdStatus")
+
+	ads, err := clientAd.ListAds(ctx, &contracts.ListAdsRequest{Category: []string{"transport"}, PriceMax: "100000000",
+		Currency: "RUB"})
+	assert.NoError(t, err, "client.ListAds")
+	require.Len(t, ads.List, 1)
+	assert.Equal(t, res.Id, ads.List[0].Id)
+	require.Len(t, ads.CategoryFacets, 2)
+	assert.Equal(t, "cars", ads.CategoryFacets[0].Value)
+	assert.Equal(t, "transport", ads.CategoryFacets[1].Value)
+	assert.Equal(t, int64(1), ads.CategoryFacets[1].Count)
+
+	_, err = clientAd.ListAds(ctx, &contracts.ListAdsRequest{PriceMax: "100000000"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	details := status.Convert(err).Details()
+	if assert.Len(t, details, 1) {
+		badRequest, ok := details[0].(*errdetails.BadRequest)
+		if assert.True(t, ok) && assert.Len(t, badRequest.FieldViolations, 1) {
+			assert.Equal(t, "Currency", badRequest.FieldViolations[0].Field)
+		}
+	}
+
+	categories, err := clientAd.ListCategories(ctx, &emptypb.Empty{})
+	assert.NoError(t, err, "client.ListCategories")
+	assert.NotEmpty(t, categories.List)
+}
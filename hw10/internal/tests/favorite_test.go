@@ -0,0 +1,128 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	contracts "homework10/internal/api/handlers/grpc/contracts/langs/go"
+)
+
+func TestFavorites(t *testing.T) {
+	client := getTestClient()
+	author, err := client.createUser("author", "author@gmail.com")
+	assert.NoError(t, err)
+	reader, err := client.createUser("reader", "reader@gmail.com")
+	assert.NoError(t, err)
+
+	first := createPublishedAd(t, client, author.Data.ID, map[string]any{"title": "first", "text": "text"})
+	second := createPublishedAd(t, client, author.Data.ID, map[string]any{"title": "second", "text": "text"})
+
+	ad, err := client.addFavorite(reader.Data.ID, first.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, first.ID, ad.Data.ID)
+	_, err = client.addFavorite(reader.Data.ID, second.ID)
+	assert.NoError(t, err)
+	_, err = client.addFavorite(reader.Data.ID, first.ID)
+	assert.NoError(t, err, "adding twice is allowed")
+
+	favorites, err := client.listFavorites(reader.Data.ID)
+	assert.NoError(t, err)
+	require.Len(t, favorites.Data, 2)
+	assert.Equal(t, second.ID, favorites.Data[0].ID)
+	assert.Equal(t, first.ID, favorites.Data[1].ID)
+	assert.False(t, favorites.Data[0].AddedAt.IsZero())
+
+	assert.NoError(t, client.removeFavorite(reader.Data.ID, second.ID))
+	favorites, err = client.listFavorites(reader.Data.ID)
+	assert.NoError(t, err)
+	require.Len(t, favorites.Data, 1)
+	assert.Equal(t, first.ID, favorites.Data[0].ID)
+}
+
+func TestFavorites_Cleanup(t *testing.T) {
+	client := getTestClient()
+	author, err := client.createUser("author", "author@gmail.com")
+	assert.NoError(t, err)
+	reader, err := client.createUser("reader", "reader@gmail.com")
+	assert.NoError(t, err)
+
+	unpublished := createPublishedAd(t, client, author.Data.ID, map[string]any{"title": "first", "text": "text"})
+	deleted := createPublishedAd(t, client, author.Data.ID, map[string]any{"title": "second", "text": "text"})
+	for _, adID := range []int64{unpublished.ID, deleted.ID} {
+		_, err = client.addFavorite(reader.Data.ID, adID)
+		assert.NoError(t, err)
+	}
+
+	_, err = client.changeAdStatus(author.Data.ID, unpublished.ID, false)
+	assert.NoError(t, err)
+	_, err = client.deleteAd(author.Data.ID, deleted.ID)
+	assert.NoError(t, err)
+
+	favorites, err := client.listFavorites(reader.Data.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, favorites.Data)
+
+	// снова опубликованное объявление в закладки само не возвращается
+	_, err = client.changeAdStatus(author.Data.ID, unpublished.ID, true)
+	assert.NoError(t, err)
+	favorites, err = client.listFavorites(reader.Data.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, favorites.Data)
+}
+
+func TestFavorites_Errors(t *testing.T) {
+	client := getTestClient()
+	user, err := client.createUser("user_1", "email@gmail.com")
+	assert.NoError(t, err)
+	draft, err := client.createAd(user.Data.ID, "hello", "world")
+	assert.NoError(t, err)
+
+	_, err = client.addFavorite(user.Data.ID, draft.Data.ID)
+	assert.ErrorIs(t, err, ErrConflict)
+	_, err = client.addFavorite(user.Data.ID, draft.Data.ID+100)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = client.listFavorites(user.Data.ID + 100)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestGRRPCFavorites(t *testing.T) {
+	ctx, conn := newGRPCTestConn(t)
+
+	clientUser := contracts.NewUserServiceClient(conn)
+	user, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com", Password: testPassword})
+	assert.NoError(t, err, "client.CreateUser")
+	authCtx := grpcLogin(t, ctx, conn, "olega@gmail.com")
+
+	clientAd := contracts.NewAdServiceClient(conn)
+	res, err := clientAd.CreateAd(authCtx, &contracts.CreateAdRequest{Title: "cats and dogs", Text: "the text"})
+	assert.NoError(t, err, "client.CreateAd")
+
+	_, err = clientUser.AddFavorite(authCtx, &contracts.FavoriteRequest{UserId: user.UserId, AdId: res.Id})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	grpcApproveAd(t, authCtx, conn, res.Id)
+	_, err = clientAd.ChangeAdStatus(authCtx, &contracts.ChangeAdStatusRequest{AdId: res.Id, Published: true})
+	assert.NoError(t, err, "client.ChangeAdStatus")
+
+	ad, err := clientUser.AddFavorite(authCtx, &contracts.FavoriteRequest{UserId: user.UserId, AdId: res.Id})
+	assert.NoError(t, err, "client.AddFavorite")
+	assert.Equal(t, res.Id, ad.Id)
+
+	favorites, err := clientUser.ListFavorites(authCtx, &contracts.ListFavoritesRequest{UserId: user.UserId})
+	assert.NoError(t, err, "client.ListFavorites")
+	require.Len(t, favorites.List, 1)
+	assert.Equal(t, res.Id, favorites.List[0].Ad.Id)
+	assert.NotNil(t, favorites.List[0].AddedAt)
+
+	_, err = clientUser.RemoveFavorite(authCtx, &contracts.FavoriteRequest{UserId: user.UserId, AdId: res.Id})
+	assert.NoError(t, err, "client.RemoveFavorite")
+	favorites, err = clientUser.ListFavorites(authCtx, &contracts.ListFavoritesRequest{UserId: user.UserId})
+	assert.NoError(t, err, "client.ListFavorites")
+	assert.Empty(t, favorites.List)
+
+	_, err = clientUser.ListFavorites(ctx, &contracts.ListFavoritesRequest{UserId: user.UserId})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
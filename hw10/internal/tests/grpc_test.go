@@ -1,67 +1,24 @@
 package tests
 
 import (
-	"context"
-	"google.golang.org/grpc/credentials/insecure"
-	grpchandler "homework10/internal/api/handlers/grpc"
 	contracts "homework10/internal/api/handlers/grpc/contracts/langs/go"
-	"homework10/internal/repository/local-repo"
-	"net"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/test/bufconn"
-	"homework10/internal/service"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestGRRPCCreateUser(t *testing.T) {
-	lis := bufconn.Listen(1024 * 1024)
-	t.Cleanup(func() {
-		lis.Close()
-	})
-
-	srv := grpc.NewServer()
-	t.Cleanup(func() {
-		srv.Stop()
-	})
-
-	adService := service.NewAdService(localrepo.NewAdRepo())
-	userService := service.NewUserService(localrepo.NewUserRepo())
-
-	grpcAdHandler := grpchandler.NewAdHandler(adService)
-	contracts.RegisterAdServiceServer(srv, grpcAdHandler)
-
-	grpcUserHandler := grpchandler.NewUserHandler(userService)
-	contracts.RegisterUserServiceServer(srv, grpcUserHandler)
-
-	go func() {
-		assert.NoError(t, srv.Serve(lis), "srv.Serve")
-	}()
-
-	dialer := func(context.Context, string) (net.Conn, error) {
-		return lis.Dial()
-	}
+	ctx, conn := newGRPCTestConn(t)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	t.Cleanup(func() {
-		cancel()
-	})
-
-	conn, err := grpc.DialContext(
-		ctx,
-		"",
-		grpc.WithContextDialer(dialer),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
-	assert.NoError(t, err, "grpc.DialContext")
-
-	t.Cleanup(func() {
-		conn.Close()
-	})
 	client := contracts.NewUserServiceClient(conn)
-	res, err := client.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com"})
+	res, err := client.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com", Password: testPassword})
 	assert.NoError(t, err, "client.GetUser")
 
 	assert.Equal(t, "Oleg", res.Nickname)
@@ -69,54 +26,17 @@ func TestGRRPCCreateUser(t *testing.T) {
 }
 
 func TestGRRPCUpdateUser(t *testing.T) {
-	lis := bufconn.Listen(1024 * 1024)
-	t.Cleanup(func() {
-		lis.Close()
-	})
-
-	srv := grpc.NewServer()
-	t.Cleanup(func() {
-		srv.Stop()
-	})
-
-	adService := service.NewAdService(localrepo.NewAdRepo())
-	userService := service.NewUserService(localrepo.NewUserRepo())
+	ctx, conn := newGRPCTestConn(t)
 
-	grpcAdHandler := grpchandler.NewAdHandler(adService)
-	contracts.RegisterAdServiceServer(srv, grpcAdHandler)
-
-	grpcUserHandler := grpchandler.NewUserHandler(userService)
-	contracts.RegisterUserServiceServer(srv, grpcUserHandler)
-
-	go func() {
-		assert.NoError(t, srv.Serve(lis), "srv.Serve")
-	}()
-
-	dialer := func(context.Context, string) (net.Conn, error) {
-		return lis.Dial()
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	t.Cleanup(func() {
-		cancel()
-	})
-
-	conn, err := grpc.DialContext(
-		ctx,
-		"",
-		grpc.WithContextDialer(dialer),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
-	assert.NoError(t, err, "grpc.DialContext")
-
-	t.Cleanup(func() {
-		conn.Close()
-	})
 	client := contracts.NewUserServiceClient(conn)
-	res, err := client.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com"})
+	res, err := client.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com", Password: testPassword})
 	assert.NoError(t, err, "client.GetUser")
 
-	res, err = client.UpdateUser(ctx, &contracts.UpdateUserRequest{UserId: res.UserId, Nickname: "Alena", Email: "alena@gmail.com"})
+	_, err = client.UpdateUser(ctx, &contracts.UpdateUserRequest{UserId: res.UserId, Nickname: "Alena", Email: "alena@gmail.com"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	authCtx := grpcLogin(t, ctx, conn, "olega@gmail.com")
+	res, err = client.UpdateUser(authCtx, &contracts.UpdateUserRequest{UserId: res.UserId, Nickname: "Alena", Email: "alena@gmail.com"})
 	assert.NoError(t, err, "client.UpdateUser")
 
 	assert.Equal(t, "Alena", res.Nickname)
@@ -124,51 +44,10 @@ func TestGRRPCUpdateUser(t *testing.T) {
 }
 
 func TestGRRPCGetUser(t *testing.T) {
-	lis := bufconn.Listen(1024 * 1024)
-	t.Cleanup(func() {
-		lis.Close()
-	})
-
-	srv := grpc.NewServer()
-	t.Cleanup(func() {
-		srv.Stop()
-	})
-
-	adService := service.NewAdService(localrepo.NewAdRepo())
-	userService := service.NewUserService(localrepo.NewUserRepo())
-
-	grpcAdHandler := grpchandler.NewAdHandler(adService)
-	contracts.RegisterAdServiceServer(srv, grpcAdHandler)
-
-	grpcUserHandler := grpchandler.NewUserHandler(userService)
-	contracts.RegisterUserServiceServer(srv, grpcUserHandler)
-
-	go func() {
-		assert.NoError(t, srv.Serve(lis), "srv.Serve")
-	}()
+	ctx, conn := newGRPCTestConn(t)
 
-	dialer := func(context.Context, string) (net.Conn, error) {
-		return lis.Dial()
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	t.Cleanup(func() {
-		cancel()
-	})
-
-	conn, err := grpc.DialContext(
-		ctx,
-		"",
-		grpc.WithContextDialer(dialer),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
-	assert.NoError(t, err, "grpc.DialContext")
-
-	t.Cleanup(func() {
-		conn.Close()
-	})
 	client := contracts.NewUserServiceClient(conn)
-	res, err := client.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com"})
+	res, err := client.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com", Password: testPassword})
 	assert.NoError(t, err, "client.GetUser")
 
 	res, err = client.GetUser(ctx, &contracts.GetUserRequest{UserId: res.UserId})
@@ -179,109 +58,34 @@ func TestGRRPCGetUser(t *testing.T) {
 }
 
 func TestGRRPCDeleteUser(t *testing.T) {
-	lis := bufconn.Listen(1024 * 1024)
-	t.Cleanup(func() {
-		lis.Close()
-	})
-
-	srv := grpc.NewServer()
-	t.Cleanup(func() {
-		srv.Stop()
-	})
-
-	adService := service.NewAdService(localrepo.NewAdRepo())
-	userService := service.NewUserService(localrepo.NewUserRepo())
-
-	grpcAdHandler := grpchandler.NewAdHandler(adService)
-	contracts.RegisterAdServiceServer(srv, grpcAdHandler)
-
-	grpcUserHandler := grpchandler.NewUserHandler(userService)
-	contracts.RegisterUserServiceServer(srv, grpcUserHandler)
-
-	go func() {
-		assert.NoError(t, srv.Serve(lis), "srv.Serve")
-	}()
-
-	dialer := func(context.Context, string) (net.Conn, error) {
-		return lis.Dial()
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	t.Cleanup(func() {
-		cancel()
-	})
+	ctx, conn := newGRPCTestConn(t)
 
-	conn, err := grpc.DialContext(
-		ctx,
-		"",
-		grpc.WithContextDialer(dialer),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
-	assert.NoError(t, err, "grpc.DialContext")
-
-	t.Cleanup(func() {
-		conn.Close()
-	})
 	client := contracts.NewUserServiceClient(conn)
-	res, err := client.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com"})
+	res, err := client.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com", Password: testPassword})
 	assert.NoError(t, err, "client.CreateUser")
 
-	_, err = client.DeleteUser(ctx, &contracts.DeleteUserRequest{UserId: res.UserId})
+	_, err = client.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Alena", Email: "alena@gmail.com", Password: testPassword})
+	assert.NoError(t, err, "client.CreateUser")
+
+	_, err = client.DeleteUser(grpcLogin(t, ctx, conn, "alena@gmail.com"), &contracts.DeleteUserRequest{UserId: res.UserId})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = client.DeleteUser(grpcLogin(t, ctx, conn, "olega@gmail.com"), &contracts.DeleteUserRequest{UserId: res.UserId})
 	assert.NoError(t, err, "client.DeleteUser")
 }
 
 func TestGRRPCGetAd(t *testing.T) {
-	lis := bufconn.Listen(1024 * 1024)
-	t.Cleanup(func() {
-		lis.Close()
-	})
-
-	srv := grpc.NewServer()
-	t.Cleanup(func() {
-		srv.Stop()
-	})
-
-	adService := service.NewAdService(localrepo.NewAdRepo())
-	userService := service.NewUserService(localrepo.NewUserRepo())
-
-	grpcAdHandler := grpchandler.NewAdHandler(adService)
-	contracts.RegisterAdServiceServer(srv, grpcAdHandler)
-
-	grpcUserHandler := grpchandler.NewUserHandler(userService)
-	contracts.RegisterUserServiceServer(srv, grpcUserHandler)
-
-	go func() {
-		assert.NoError(t, srv.Serve(lis), "srv.Serve")
-	}()
-
-	dialer := func(context.Context, string) (net.Conn, error) {
-		return lis.Dial()
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	t.Cleanup(func() {
-		cancel()
-	})
-
-	conn, err := grpc.DialContext(
-		ctx,
-		"",
-		grpc.WithContextDialer(dialer),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
-	assert.NoError(t, err, "grpc.DialContext")
+	ctx, conn := newGRPCTestConn(t)
 
-	t.Cleanup(func() {
-		conn.Close()
-	})
 	clientUser := contracts.NewUserServiceClient(conn)
 
-	user, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com"})
+	_, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com", Password: testPassword})
 	assert.NoError(t, err, "client.CreateUser")
+	ctx = grpcLogin(t, ctx, conn, "olega@gmail.com")
 
 	clientAd := contracts.NewAdServiceClient(conn)
 
-	res, err := clientAd.CreateAd(ctx, &contracts.CreateAdRequest{Title: "the book", Text: "the text", UserId: user.UserId})
+	res, err := clientAd.CreateAd(ctx, &contracts.CreateAdRequest{Title: "the book", Text: "the text"})
 	assert.NoError(t, err, "client.CreateAd")
 
 	res, err = clientAd.GetAd(ctx, &contracts.GetAdRequest{AdId: res.Id})
@@ -292,376 +96,592 @@ func TestGRRPCGetAd(t *testing.T) {
 }
 
 func TestGRRPCCreateAd(t *testing.T) {
-	lis := bufconn.Listen(1024 * 1024)
-	t.Cleanup(func() {
-		lis.Close()
-	})
-
-	srv := grpc.NewServer()
-	t.Cleanup(func() {
-		srv.Stop()
-	})
-
-	adService := service.NewAdService(localrepo.NewAdRepo())
-	userService := service.NewUserService(localrepo.NewUserRepo())
+	ctx, conn := newGRPCTestConn(t)
 
-	grpcAdHandler := grpchandler.NewAdHandler(adService)
-	contracts.RegisterAdServiceServer(srv, grpcAdHandler)
+	clientUser := contracts.NewUserServiceClient(conn)
 
-	grpcUserHandler := grpchandler.NewUserHandler(userService)
-	contracts.RegisterUserServiceServer(srv, grpcUserHandler)
+	_, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com", Password: testPassword})
+	assert.NoError(t, err, "client.CreateUser")
+	ctx = grpcLogin(t, ctx, conn, "olega@gmail.com")
 
-	go func() {
-		assert.NoError(t, srv.Serve(lis), "srv.Serve")
-	}()
+	clientAd := contracts.NewAdServiceClient(conn)
 
-	dialer := func(context.Context, string) (net.Conn, error) {
-		return lis.Dial()
-	}
+	res, err := clientAd.CreateAd(ctx, &contracts.CreateAdRequest{Title: "the book", Text: "the text"})
+	assert.NoError(t, err, "client.CreateAd")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	t.Cleanup(func() {
-		cancel()
-	})
+	assert.Equal(t, "the book", res.Title)
+	assert.Equal(t, "the text", res.Text)
+}
 
-	conn, err := grpc.DialContext(
-		ctx,
-		"",
-		grpc.WithContextDialer(dialer),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
-	assert.NoError(t, err, "grpc.DialContext")
+func TestGRRPCUpdateAd(t *testing.T) {
+	ctx, conn := newGRPCTestConn(t)
 
-	t.Cleanup(func() {
-		conn.Close()
-	})
 	clientUser := contracts.NewUserServiceClient(conn)
 
-	user, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com"})
+	_, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com", Password: testPassword})
 	assert.NoError(t, err, "client.CreateUser")
+	ctx = grpcLogin(t, ctx, conn, "olega@gmail.com")
 
 	clientAd := contracts.NewAdServiceClient(conn)
 
-	res, err := clientAd.CreateAd(ctx, &contracts.CreateAdRequest{Title: "the book", Text: "the text", UserId: user.UserId})
+	res, err := clientAd.CreateAd(ctx, &contracts.CreateAdRequest{Title: "the book", Text: "the text"})
 	assert.NoError(t, err, "client.CreateAd")
 
-	assert.Equal(t, "the book", res.Title)
-	assert.Equal(t, "the text", res.Text)
+	res, err = clientAd.UpdateAd(ctx, &contracts.UpdateAdRequest{AdId: res.Id,
+		Title: "new book", Text: "new text"})
+	assert.NoError(t, err, "client.UpdateAd")
+
+	assert.Equal(t, "new book", res.Title)
+	assert.Equal(t, "new text", res.Text)
 }
 
-func TestGRRPCUpdateAd(t *testing.T) {
-	lis := bufconn.Listen(1024 * 1024)
-	t.Cleanup(func() {
-		lis.Close()
-	})
+func TestGRRPCChangeAdStatus(t *testing.T) {
+	ctx, conn := newGRPCTestConn(t)
 
-	srv := grpc.NewServer()
-	t.Cleanup(func() {
-		srv.Stop()
-	})
+	clientUser := contracts.NewUserServiceClient(conn)
 
-	adService := service.NewAdService(localrepo.NewAdRepo())
-	userService := service.NewUserService(localrepo.NewUserRepo())
+	_, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com", Password: testPassword})
+	assert.NoError(t, err, "client.CreateUser")
+	ctx = grpcLogin(t, ctx, conn, "olega@gmail.com")
 
-	grpcAdHandler := grpchandler.NewAdHandler(adService)
-	contracts.RegisterAdServiceServer(srv, grpcAdHandler)
+	clientAd := contracts.NewAdServiceClient(conn)
 
-	grpcUserHandler := grpchandler.NewUserHandler(userService)
-	contracts.RegisterUserServiceServer(srv, grpcUserHandler)
+	res, err := clientAd.CreateAd(ctx, &contracts.CreateAdRequest{Title: "the book", Text: "the text"})
+	assert.NoError(t, err, "client.CreateAd")
 
-	go func() {
-		assert.NoError(t, srv.Serve(lis), "srv.Serve")
-	}()
+	_, err = clientAd.ChangeAdStatus(ctx, &contracts.ChangeAdStatusRequest{AdId: res.Id, Published: true})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
 
-	dialer := func(context.Context, string) (net.Conn, error) {
-		return lis.Dial()
-	}
+	grpcApproveAd(t, ctx, conn, res.Id)
+	res, err = clientAd.ChangeAdStatus(ctx, &contracts.ChangeAdStatusRequest{AdId: res.Id,
+		Published: true})
+	assert.NoError(t, err, "client.ChangeAdStatus")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	t.Cleanup(func() {
-		cancel()
-	})
+	assert.Equal(t, true, res.Published)
+}
 
-	conn, err := grpc.DialContext(
-		ctx,
-		"",
-		grpc.WithContextDialer(dialer),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
-	assert.NoError(t, err, "grpc.DialContext")
+func TestGRRPCDeleteAd(t *testing.T) {
+	ctx, conn := newGRPCTestConn(t)
 
-	t.Cleanup(func() {
-		conn.Close()
-	})
 	clientUser := contracts.NewUserServiceClient(conn)
 
-	user, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com"})
+	_, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com", Password: testPassword})
 	assert.NoError(t, err, "client.CreateUser")
+	ctx = grpcLogin(t, ctx, conn, "olega@gmail.com")
 
 	clientAd := contracts.NewAdServiceClient(conn)
 
-	res, err := clientAd.CreateAd(ctx, &contracts.CreateAdRequest{Title: "the book", Text: "the text", UserId: user.UserId})
+	res, err := clientAd.CreateAd(ctx, &contracts.CreateAdRequest{Title: "the book", Text: "the text"})
 	assert.NoError(t, err, "client.CreateAd")
 
-	res, err = clientAd.UpdateAd(ctx, &contracts.UpdateAdRequest{AdId: res.Id,
-		Title: "new book", Text: "new text", UserId: user.UserId})
-	assert.NoError(t, err, "client.UpdateAd")
-
-	assert.Equal(t, "new book", res.Title)
-	assert.Equal(t, "new text", res.Text)
+	_, err = clientAd.DeleteAd(ctx, &contracts.DeleteAdRequest{AdId: res.Id})
+	assert.NoError(t, err, "client.DeleteAd")
 }
 
-func TestGRRPCChangeAdStatus(t *testing.T) {
-	lis := bufconn.Listen(1024 * 1024)
-	t.Cleanup(func() {
-		lis.Close()
-	})
+func TestGRRPCSearchAds(t *testing.T) {
+	ctx, conn := newGRPCTestConn(t)
 
-	srv := grpc.NewServer()
-	t.Cleanup(func() {
-		srv.Stop()
-	})
+	clientUser := contracts.NewUserServiceClient(conn)
 
-	adService := service.NewAdService(localrepo.NewAdRepo())
-	userService := service.NewUserService(localrepo.NewUserRepo())
+	_, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com", Password: testPassword})
+	assert.NoError(t, err, "client.CreateUser")
+	ctx = grpcLogin(t, ctx, conn, "olega@gmail.com")
 
-	grpcAdHandler := grpchandler.NewAdHandler(adService)
-	contracts.RegisterAdServiceServer(srv, grpcAdHandler)
+	clientAd := contracts.NewAdServiceClient(conn)
 
-	grpcUserHandler := grpchandler.NewUserHandler(userService)
-	contracts.RegisterUserServiceServer(srv, grpcUserHandler)
+	food, err := clientAd.CreateAd(ctx, &contracts.CreateAdRequest{Title: "dog and cat food", Text: "the text"})
+	assert.NoError(t, err, "client.CreateAd")
 
-	go func() {
-		assert.NoError(t, srv.Serve(lis), "srv.Serve")
-	}()
+	_, err = clientAd.CreateAd(ctx, &contracts.CreateAdRequest{Title: "parrot", Text: "the text"})
+	assert.NoError(t, err, "client.CreateAd")
 
-	dialer := func(context.Context, string) (net.Conn, error) {
-		return lis.Dial()
+	res, err := clientAd.CreateAd(ctx, &contracts.CreateAdRequest{Title: "cats and dogs", Text: "the text"})
+	assert.NoError(t, err, "client.CreateAd")
+
+	// черновики в поиск не попадают
+	_, err = clientAd.CreateAd(ctx, &contracts.CreateAdRequest{Title: "cats toys", Text: "the text"})
+	assert.NoError(t, err, "client.CreateAd")
+
+	for _, adID := range []int64{food.Id, res.Id} {
+		grpcApproveAd(t, ctx, conn, adID)
+		_, err = clientAd.ChangeAdStatus(ctx, &contracts.ChangeAdStatusRequest{AdId: adID, Published: true})
+		assert.NoError(t, err, "client.ChangeAdStatus")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	t.Cleanup(func() {
-		cancel()
-	})
+	ads, err := clientAd.SearchAds(ctx, &contracts.SearchAdsRequest{Text: "cats"})
+	assert.NoError(t, err, "client.SearchAds")
 
-	conn, err := grpc.DialContext(
-		ctx,
-		"",
-		grpc.WithContextDialer(dialer),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
-	assert.NoError(t, err, "grpc.DialContext")
+	assert.Len(t, ads.List, 2)
+	assert.Equal(t, ads.List[0].Ad.Id, res.Id)
+	assert.Equal(t, ads.List[0].Ad.Title, res.Title)
+	assert.Equal(t, ads.List[0].Ad.Text, res.Text)
+	assert.Equal(t, ads.List[0].Ad.UserId, res.UserId)
+	assert.Equal(t, "<mark>cats</mark> and dogs", ads.List[0].Snippet)
+	assert.Greater(t, ads.List[0].Score, ads.List[1].Score)
+}
+
+func TestGRRPCListAds(t *testing.T) {
+	ctx, conn := newGRPCTestConn(t)
 
-	t.Cleanup(func() {
-		conn.Close()
-	})
 	clientUser := contracts.NewUserServiceClient(conn)
 
-	user, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com"})
+	user, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com", Password: testPassword})
 	assert.NoError(t, err, "client.CreateUser")
+	ctx = grpcLogin(t, ctx, conn, "olega@gmail.com")
 
 	clientAd := contracts.NewAdServiceClient(conn)
 
-	res, err := clientAd.CreateAd(ctx, &contracts.CreateAdRequest{Title: "the book", Text: "the text", UserId: user.UserId})
+	_, err = clientAd.CreateAd(ctx, &contracts.CreateAdRequest{Title: "cat and dog", Text: "the text"})
 	assert.NoError(t, err, "client.CreateAd")
 
-	res, err = clientAd.ChangeAdStatus(ctx, &contracts.ChangeAdStatusRequest{AdId: res.Id, UserId: user.UserId,
-		Published: true})
+	res, err := clientAd.CreateAd(ctx, &contracts.CreateAdRequest{Title: "cats and dogs", Text: "the text"})
+	assert.NoError(t, err, "client.CreateAd")
+
+	grpcApproveAd(t, ctx, conn, res.Id)
+	_, err = clientAd.ChangeAdStatus(ctx, &contracts.ChangeAdStatusRequest{AdId: res.Id, Published: true})
 	assert.NoError(t, err, "client.ChangeAdStatus")
 
-	assert.Equal(t, true, res.Published)
+	ads, err := clientAd.ListAds(ctx, &contracts.ListAdsRequest{Published: "true", UserId: strconv.FormatInt(user.UserId, 10)})
+	assert.NoError(t, err, "client.ListAds")
+
+	assert.Len(t, ads.List, 1)
+	assert.Equal(t, ads.List[0].Id, res.Id)
+	assert.Equal(t, ads.List[0].Title, res.Title)
+	assert.Equal(t, ads.List[0].Text, res.Text)
+	assert.Equal(t, ads.List[0].UserId, res.UserId)
 }
 
-func TestGRRPCDeleteAd(t *testing.T) {
-	lis := bufconn.Listen(1024 * 1024)
-	t.Cleanup(func() {
-		lis.Close()
+func TestGRRPCListAdsByDates(t *testing.T) {
+	ctx, conn := newGRPCTestConn(t)
+
+	clientUser := contracts.NewUserServiceClient(conn)
+
+	_, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com", Password: testPassword})
+	assert.NoError(t, err, "client.CreateUser")
+	ctx = grpcLogin(t, ctx, conn, "olega@gmail.com")
+
+	clientAd := contracts.NewAdServiceClient(conn)
+
+	res, err := clientAd.CreateAd(ctx, &contracts.CreateAdRequest{Title: "cat and dog", Text: "the text"})
+	assert.NoError(t, err, "client.CreateAd")
+	assert.NotNil(t, res.DateCreation)
+	assert.True(t, res.DateCreation.AsTime().Equal(res.DateUpdate.AsTime()))
+
+	created := res.DateCreation.AsTime()
+	ads, err := clientAd.ListAds(ctx, &contracts.ListAdsRequest{
+		Published:   "false",
+		CreatedFrom: timestamppb.New(created.Add(-time.Minute)),
+		CreatedTo:   timestamppb.New(created.Add(time.Minute)),
 	})
+	assert.NoError(t, err, "client.ListAds")
+	assert.Len(t, ads.List, 1)
 
-	srv := grpc.NewServer()
-	t.Cleanup(func() {
-		srv.Stop()
+	ads, err = clientAd.ListAds(ctx, &contracts.ListAdsRequest{
+		Published:    "false",
+		UpdatedSince: timestamppb.New(created.Add(time.Minute)),
 	})
+	assert.NoError(t, err, "client.ListAds")
+	assert.Empty(t, ads.List)
 
-	adService := service.NewAdService(localrepo.NewAdRepo())
-	userService := service.NewUserService(localrepo.NewUserRepo())
+	_, err = clientAd.ListAds(ctx, &contracts.ListAdsRequest{
+		Published:   "false",
+		CreatedFrom: timestamppb.New(created.Add(time.Minute)),
+		CreatedTo:   timestamppb.New(created.Add(-time.Minute)),
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
 
-	grpcAdHandler := grpchandler.NewAdHandler(adService)
-	contracts.RegisterAdServiceServer(srv, grpcAdHandler)
+func TestGRRPCListAdsPagination(t *testing.T) {
+	ctx, conn := newGRPCTestConn(t)
 
-	grpcUserHandler := grpchandler.NewUserHandler(userService)
-	contracts.RegisterUserServiceServer(srv, grpcUserHandler)
+	clientUser := contracts.NewUserServiceClient(conn)
 
-	go func() {
-		assert.NoError(t, srv.Serve(lis), "srv.Serve")
-	}()
+	_, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com", Password: testPassword})
+	assert.NoError(t, err, "client.CreateUser")
+	ctx = grpcLogin(t, ctx, conn, "olega@gmail.com")
 
-	dialer := func(context.Context, string) (net.Conn, error) {
-		return lis.Dial()
+	clientAd := contracts.NewAdServiceClient(conn)
+
+	for i := 0; i < 3; i++ {
+		ad, err := clientAd.CreateAd(ctx, &contracts.CreateAdRequest{Title: "cats", Text: "the text"})
+		assert.NoError(t, err, "client.CreateAd")
+		grpcApproveAd(t, ctx, conn, ad.Id)
+		_, err = clientAd.ChangeAdStatus(ctx, &contracts.ChangeAdStatusRequest{AdId: ad.Id, Published: true})
+		assert.NoError(t, err, "client.ChangeAdStatus")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	t.Cleanup(func() {
-		cancel()
-	})
+	ads, err := clientAd.SearchAds(ctx, &contracts.SearchAdsRequest{Text: "cats", Limit: 2, Order: "desc"})
+	assert.NoError(t, err, "client.SearchAds")
+	assert.Len(t, ads.List, 2)
+	assert.Equal(t, int64(2), ads.List[0].Ad.Id)
+	assert.Equal(t, int64(1), ads.List[1].Ad.Id)
+	assert.NotEmpty(t, ads.NextPageToken)
 
-	conn, err := grpc.DialContext(
-		ctx,
-		"",
-		grpc.WithContextDialer(dialer),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
-	assert.NoError(t, err, "grpc.DialContext")
+	ads, err = clientAd.SearchAds(ctx,
+		&contracts.SearchAdsRequest{Text: "cats", Limit: 2, Order: "desc", PageToken: ads.NextPageToken})
+	assert.NoError(t, err, "client.SearchAds")
+	assert.Len(t, ads.List, 1)
+	assert.Equal(t, int64(0), ads.List[0].Ad.Id)
+	assert.Empty(t, ads.NextPageToken)
+}
 
-	t.Cleanup(func() {
-		conn.Close()
-	})
+func TestGRRPCCreateAd_Unauthenticated(t *testing.T) {
+	ctx, conn := newGRPCTestConn(t)
+	clientAd := contracts.NewAdServiceClient(conn)
+
+	_, err := clientAd.CreateAd(ctx, &contracts.CreateAdRequest{Title: "the book", Text: "the text"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestGRRPCLogin_UnknownEmail(t *testing.T) {
+	ctx, conn := newGRPCTestConn(t)
+	clientAuth := contracts.NewAuthServiceClient(conn)
+
+	_, err := clientAuth.Login(ctx, &contracts.LoginRequest{Email: "olega@gmail.com", Password: testPassword})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestGRRPCUpdateAdOfAnotherUser(t *testing.T) {
+	ctx, conn := newGRPCTestConn(t)
 	clientUser := contracts.NewUserServiceClient(conn)
 
-	user, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com"})
+	_, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com", Password: testPassword})
+	assert.NoError(t, err, "client.CreateUser")
+	_, err = clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Alena", Email: "alena@gmail.com", Password: testPassword})
 	assert.NoError(t, err, "client.CreateUser")
 
 	clientAd := contracts.NewAdServiceClient(conn)
 
-	res, err := clientAd.CreateAd(ctx, &contracts.CreateAdRequest{Title: "the book", Text: "the text", UserId: user.UserId})
+	res, err := clientAd.CreateAd(grpcLogin(t, ctx, conn, "olega@gmail.com"),
+		&contracts.CreateAdRequest{Title: "the book", Text: "the text"})
 	assert.NoError(t, err, "client.CreateAd")
 
-	_, err = clientAd.DeleteAd(ctx, &contracts.DeleteAdRequest{AdId: res.Id})
-	assert.NoError(t, err, "client.DeleteAd")
-}
+	_, err = clientAd.UpdateAd(grpcLogin(t, ctx, conn, "alena@gmail.com"),
+		&contracts.UpdateAdRequest{AdId: res.Id, Title: "new book", Text: "new text"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
 
-func TestGRRPCSearchAds(t *testing.T) {
-	lis := bufconn.Listen(1024 * 1024)
-	t.Cleanup(func() {
-		lis.Close()
-	})
+	ad, err := clientAd.GetAd(grpcLogin(t, ctx, conn, "olega@gmail.com"), &contracts.GetAdRequest{AdId: res.Id})
+	assert.NoError(t, err, "client.GetAd")
+	assert.Equal(t, "the book", ad.Title)
 
-	srv := grpc.NewServer()
-	t.Cleanup(func() {
-		srv.Stop()
-	})
+	_, err = clientAd.GetAd(ctx, &contracts.GetAdRequest{AdId: res.Id})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
 
-	adService := service.NewAdService(localrepo.NewAdRepo())
-	userService := service.NewUserService(localrepo.NewUserRepo())
+func TestGRRPCErrorCodes(t *testing.T) {
+	ctx, conn := newGRPCTestConn(t)
+	clientUser := contracts.NewUserServiceClient(conn)
+	clientAd := contracts.NewAdServiceClient(conn)
 
-	grpcAdHandler := grpchandler.NewAdHandler(adService)
-	contracts.RegisterAdServiceServer(srv, grpcAdHandler)
+	_, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com", Password: testPassword})
+	assert.NoError(t, err, "client.CreateUser")
 
-	grpcUserHandler := grpchandler.NewUserHandler(userService)
-	contracts.RegisterUserServiceServer(srv, grpcUserHandler)
+	_, err = clientAd.GetAd(ctx, &contracts.GetAdRequest{AdId: 100})
+	assert.Equal(t, codes.NotFound, status.Code(err))
 
-	go func() {
-		assert.NoError(t, srv.Serve(lis), "srv.Serve")
-	}()
+	_, err = clientUser.GetUser(ctx, &contracts.GetUserRequest{UserId: 100})
+	assert.Equal(t, codes.NotFound, status.Code(err))
 
-	dialer := func(context.Context, string) (net.Conn, error) {
-		return lis.Dial()
+	_, err = clientAd.CreateAd(grpcLogin(t, ctx, conn, "olega@gmail.com"), &contracts.CreateAdRequest{Title: "", Text: "the text"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	details := status.Convert(err).Details()
+	if assert.Len(t, details, 1) {
+		badRequest, ok := details[0].(*errdetails.BadRequest)
+		if assert.True(t, ok) && assert.Len(t, badRequest.FieldViolations, 1) {
+			assert.Equal(t, "Title", badRequest.FieldViolations[0].Field)
+		}
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	t.Cleanup(func() {
-		cancel()
-	})
+	_, err = clientAd.ListAds(ctx, &contracts.ListAdsRequest{Published: "maybe"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
 
-	conn, err := grpc.DialContext(
-		ctx,
-		"",
-		grpc.WithContextDialer(dialer),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
-	assert.NoError(t, err, "grpc.DialContext")
+func TestGRRPCCreateUser_AlreadyExists(t *testing.T) {
+	ctx, conn := newGRPCTestConn(t)
+	clientUser := contracts.NewUserServiceClient(conn)
 
-	t.Cleanup(func() {
-		conn.Close()
-	})
+	_, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com", Password: testPassword})
+	assert.NoError(t, err, "client.CreateUser")
+
+	_, err = clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Alena", Email: "olega@gmail.com", Password: testPassword})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	_, err = clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Alena", Email: "alena@gmail.com", Password: "short"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRRPCChangePassword(t *testing.T) {
+	ctx, conn := newGRPCTestConn(t)
 	clientUser := contracts.NewUserServiceClient(conn)
 
-	user, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com"})
+	user, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com", Password: testPassword})
 	assert.NoError(t, err, "client.CreateUser")
 
+	_, err = clientUser.ChangePassword(ctx,
+		&contracts.ChangePasswordRequest{UserId: user.UserId, OldPassword: testPassword, NewPassword: "new password"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	authCtx := grpcLogin(t, ctx, conn, "olega@gmail.com")
+	_, err = clientUser.ChangePassword(authCtx,
+		&contracts.ChangePasswordRequest{UserId: user.UserId, OldPassword: "wrong password", NewPassword: "new password"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = clientUser.ChangePassword(authCtx,
+		&contracts.ChangePasswordRequest{UserId: user.UserId, OldPassword: testPassword, NewPassword: "new password"})
+	assert.NoError(t, err, "client.ChangePassword")
+
+	_, err = contracts.NewAuthServiceClient(conn).Login(ctx,
+		&contracts.LoginRequest{Email: "olega@gmail.com", Password: "new password"})
+	assert.NoError(t, err, "client.Login")
+}
+
+func TestGRRPCWatchAds(t *testing.T) {
+	ctx, conn := newGRPCTestConn(t)
+	clientUser := contracts.NewUserServiceClient(conn)
 	clientAd := contracts.NewAdServiceClient(conn)
 
-	_, err = clientAd.CreateAd(ctx, &contracts.CreateAdRequest{Title: "cat and dog", Text: "the text", UserId: user.UserId})
-	assert.NoError(t, err, "client.CreateAd")
+	user, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com", Password: testPassword})
+	assert.NoError(t, err, "client.CreateUser")
+	_, err = clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Alena", Email: "alena@gmail.com", Password: testPassword})
+	assert.NoError(t, err, "client.CreateUser")
 
-	res, err := clientAd.CreateAd(ctx, &contracts.CreateAdRequest{Title: "cats and dogs", Text: "the text", UserId: user.UserId})
+	authCtx := grpcLogin(t, ctx, conn, "olega@gmail.com")
+	filter := &contracts.WatchAdsRequest{UserId: strconv.FormatInt(user.UserId, 10)}
+	stream, err := clientAd.WatchAds(authCtx, filter)
+	assert.NoError(t, err, "client.WatchAds")
+	_, err = stream.Header()
+	assert.NoError(t, err, "stream.Header")
+	// анонимный подписчик видит объявление только опубликованным
+	anonymous, err := clientAd.WatchAds(ctx, filter)
+	assert.NoError(t, err, "client.WatchAds")
+	_, err = anonymous.Header()
+	assert.NoError(t, err, "stream.Header")
+
+	_, err = clientAd.CreateAd(grpcLogin(t, ctx, conn, "alena@gmail.com"), &contracts.CreateAdRequest{Title: "other", Text: "ad"})
 	assert.NoError(t, err, "client.CreateAd")
 
-	ads, err := clientAd.SearchAds(ctx, &contracts.SearchAdsRequest{Text: "cats"})
-	assert.NoError(t, err, "client.SearchAds")
+	ad, err := clientAd.CreateAd(authCtx, &contracts.CreateAdRequest{Title: "the book", Text: "the text"})
+	assert.NoError(t, err, "client.CreateAd")
+	_, err = clientAd.UpdateAd(authCtx, &contracts.UpdateAdRequest{AdId: ad.Id, Title: "new book", Text: "the text"})
+	assert.NoError(t, err, "client.UpdateAd")
+	grpcApproveAd(t, authCtx, conn, ad.Id)
+	_, err = clientAd.ChangeAdStatus(authCtx, &contracts.ChangeAdStatusRequest{AdId: ad.Id, Published: true})
+	assert.NoError(t, err, "client.ChangeAdStatus")
+	_, err = clientAd.DeleteAd(authCtx, &contracts.DeleteAdRequest{AdId: ad.Id})
+	assert.NoError(t, err, "client.DeleteAd")
 
-	assert.Len(t, ads.List, 1)
-	assert.Equal(t, ads.List[0].Id, res.Id)
-	assert.Equal(t, ads.List[0].Title, res.Title)
-	assert.Equal(t, ads.List[0].Text, res.Text)
-	assert.Equal(t, ads.List[0].UserId, res.UserId)
+	expected := []contracts.AdEventType{
+		contracts.AdEventType_AD_EVENT_TYPE_CREATED,
+		contracts.AdEventType_AD_EVENT_TYPE_UPDATED,
+		contracts.AdEventType_AD_EVENT_TYPE_MODERATED,
+		contracts.AdEventType_AD_EVENT_TYPE_MODERATED,
+		contracts.AdEventType_AD_EVENT_TYPE_STATUS_CHANGED,
+		contracts.AdEventType_AD_EVENT_TYPE_DELETED,
+	}
+	for _, eventType := range expected {
+		event, err := stream.Recv()
+		if !assert.NoError(t, err, "stream.Recv") {
+			return
+		}
+		assert.Equal(t, eventType, event.Type)
+		assert.Equal(t, ad.Id, event.Ad.Id)
+	}
+	for _, eventType := range expected[4:] {
+		event, err := anonymous.Recv()
+		if !assert.NoError(t, err, "stream.Recv") {
+			return
+		}
+		assert.Equal(t, eventType, event.Type)
+		assert.True(t, event.Ad.Published)
+	}
 }
 
-func TestGRRPCListAds(t *testing.T) {
-	lis := bufconn.Listen(1024 * 1024)
-	t.Cleanup(func() {
-		lis.Close()
-	})
+func TestGRRPCWatchAds_InvalidFilter(t *testing.T) {
+	ctx, conn := newGRPCTestConn(t)
 
-	srv := grpc.NewServer()
-	t.Cleanup(func() {
-		srv.Stop()
-	})
-
-	adService := service.NewAdService(localrepo.NewAdRepo())
-	userService := service.NewUserService(localrepo.NewUserRepo())
+	stream, err := contracts.NewAdServiceClient(conn).WatchAds(ctx, &contracts.WatchAdsRequest{Published: "maybe"})
+	assert.NoError(t, err, "client.WatchAds")
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
 
-	grpcAdHandler := grpchandler.NewAdHandler(adService)
-	contracts.RegisterAdServiceServer(srv, grpcAdHandler)
+func TestGRRPCAdRevisions(t *testing.T) {
+	ctx, conn := newGRPCTestConn(t)
+	clientUser := contracts.NewUserServiceClient(conn)
+	clientAd := contracts.NewAdServiceClient(conn)
 
-	grpcUserHandler := grpchandler.NewUserHandler(userService)
-	contracts.RegisterUserServiceServer(srv, grpcUserHandler)
+	_, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com", Password: testPassword})
+	assert.NoError(t, err, "client.CreateUser")
+	authCtx := grpcLogin(t, ctx, conn, "olega@gmail.com")
 
-	go func() {
-		assert.NoError(t, srv.Serve(lis), "srv.Serve")
-	}()
+	ad, err := clientAd.CreateAd(authCtx, &contracts.CreateAdRequest{Title: "the book", Text: "the text"})
+	assert.NoError(t, err, "client.CreateAd")
+	_, err = clientAd.UpdateAd(authCtx, &contracts.UpdateAdRequest{AdId: ad.Id, Title: "new book", Text: "the text"})
+	assert.NoError(t, err, "client.UpdateAd")
 
-	dialer := func(context.Context, string) (net.Conn, error) {
-		return lis.Dial()
+	// в истории есть неопубликованные версии, её видят только автор, модераторы и администраторы
+	_, err = clientAd.ListAdRevisions(ctx, &contracts.ListAdRevisionsRequest{AdId: ad.Id})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Alena", Email: "alena@gmail.com", Password: testPassword})
+	assert.NoError(t, err, "client.CreateUser")
+	_, err = clientAd.ListAdRevisions(grpcLogin(t, ctx, conn, "alena@gmail.com"), &contracts.ListAdRevisionsRequest{AdId: ad.Id})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	res, err := clientAd.ListAdRevisions(authCtx, &contracts.ListAdRevisionsRequest{AdId: ad.Id})
+	assert.NoError(t, err, "client.ListAdRevisions")
+	if assert.Len(t, res.List, 2) {
+		assert.Equal(t, contracts.AdRevisionType_AD_REVISION_TYPE_UPDATED, res.List[1].Type)
+		assert.Equal(t, "new book", res.List[1].Title)
+		assert.Len(t, res.List[1].Changes, 1)
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	t.Cleanup(func() {
-		cancel()
-	})
 
-	conn, err := grpc.DialContext(
-		ctx,
-		"",
-		grpc.WithContextDialer(dialer),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
-	assert.NoError(t, err, "grpc.DialContext")
+	_, err = clientAd.RestoreAdRevision(ctx, &contracts.RestoreAdRevisionRequest{AdId: ad.Id, Number: 1})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
 
-	t.Cleanup(func() {
-		conn.Close()
-	})
+	restored, err := clientAd.RestoreAdRevision(authCtx, &contracts.RestoreAdRevisionRequest{AdId: ad.Id, Number: 1})
+	assert.NoError(t, err, "client.RestoreAdRevision")
+	assert.Equal(t, "the book", restored.Title)
+
+	_, err = clientAd.ListAdRevisions(authCtx, &contracts.ListAdRevisionsRequest{AdId: 100})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestGRRPCUpdateAd_ExpectedVersion(t *testing.T) {
+	ctx, conn := newGRPCTestConn(t)
 	clientUser := contracts.NewUserServiceClient(conn)
+	clientAd := contracts.NewAdServiceClient(conn)
 
-	user, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com"})
+	_, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com", Password: testPassword})
 	assert.NoError(t, err, "client.CreateUser")
+	authCtx := grpcLogin(t, ctx, conn, "olega@gmail.com")
 
+	ad, err := clientAd.CreateAd(authCtx, &contracts.CreateAdRequest{Title: "the book", Text: "the text"})
+	assert.NoError(t, err, "client.CreateAd")
+	assert.Equal(t, int64(1), ad.Version)
+
+	updated, err := clientAd.UpdateAd(authCtx,
+		&contracts.UpdateAdRequest{AdId: ad.Id, Title: "new book", Text: "the text", ExpectedVersion: ad.Version})
+	assert.NoError(t, err, "client.UpdateAd")
+	assert.Equal(t, int64(2), updated.Version)
+	approved := grpcApproveAd(t, authCtx, conn, ad.Id)
+
+	_, err = clientAd.UpdateAd(authCtx,
+		&contracts.UpdateAdRequest{AdId: ad.Id, Title: "stale book", Text: "the text", ExpectedVersion: ad.Version})
+	assert.Equal(t, codes.Aborted, status.Code(err))
+
+	_, err = clientAd.ChangeAdStatus(authCtx,
+		&contracts.ChangeAdStatusRequest{AdId: ad.Id, Published: true, ExpectedVersion: ad.Version})
+	assert.Equal(t, codes.Aborted, status.Code(err))
+
+	published, err := clientAd.ChangeAdStatus(authCtx,
+		&contracts.ChangeAdStatusRequest{AdId: ad.Id, Published: true, ExpectedVersion: approved.Version})
+	assert.NoError(t, err, "client.ChangeAdStatus")
+	assert.Equal(t, approved.Version+1, published.Version)
+}
+
+func TestGRRPCRestoreAdAndUser(t *testing.T) {
+	ctx, conn := newGRPCTestConn(t)
+	clientUser := contracts.NewUserServiceClient(conn)
 	clientAd := contracts.NewAdServiceClient(conn)
 
-	_, err = clientAd.CreateAd(ctx, &contracts.CreateAdRequest{Title: "cat and dog", Text: "the text", UserId: user.UserId})
-	assert.NoError(t, err, "client.CreateAd")
+	user, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com", Password: testPassword})
+	assert.NoError(t, err, "client.CreateUser")
+	authCtx := grpcLogin(t, ctx, conn, "olega@gmail.com")
 
-	res, err := clientAd.CreateAd(ctx, &contracts.CreateAdRequest{Title: "cats and dogs", Text: "the text", UserId: user.UserId})
+	ad, err := clientAd.CreateAd(authCtx, &contracts.CreateAdRequest{Title: "the book", Text: "the text"})
 	assert.NoError(t, err, "client.CreateAd")
+	_, err = clientAd.DeleteAd(authCtx, &contracts.DeleteAdRequest{AdId: ad.Id})
+	assert.NoError(t, err, "client.DeleteAd")
+	_, err = clientAd.GetAd(ctx, &contracts.GetAdRequest{AdId: ad.Id})
+	assert.Equal(t, codes.NotFound, status.Code(err))
 
-	_, err = clientAd.ChangeAdStatus(ctx, &contracts.ChangeAdStatusRequest{AdId: res.Id, UserId: user.UserId, Published: true})
+	_, err = clientAd.RestoreAd(ctx, &contracts.RestoreAdRequest{AdId: ad.Id})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	restored, err := clientAd.RestoreAd(authCtx, &contracts.RestoreAdRequest{AdId: ad.Id})
+	assert.NoError(t, err, "client.RestoreAd")
+	assert.Equal(t, ad.Id, restored.Id)
+
+	_, err = clientUser.DeleteUser(authCtx, &contracts.DeleteUserRequest{UserId: user.UserId})
+	assert.NoError(t, err, "client.DeleteUser")
+	_, err = clientAd.GetAd(ctx, &contracts.GetAdRequest{AdId: ad.Id})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = clientUser.RestoreUser(ctx, &contracts.RestoreUserRequest{UserId: user.UserId, Password: "wrong password"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = clientUser.RestoreUser(ctx, &contracts.RestoreUserRequest{UserId: user.UserId, Password: testPassword})
+	assert.NoError(t, err, "client.RestoreUser")
+	_, err = clientAd.GetAd(authCtx, &contracts.GetAdRequest{AdId: ad.Id})
+	assert.NoError(t, err, "client.GetAd")
+}
+
+func TestGRRPCModeration(t *testing.T) {
+	ctx, conn := newGRPCTestConn(t)
+	clientUser := contracts.NewUserServiceClient(conn)
+	clientAd := contracts.NewAdServiceClient(conn)
+
+	author, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com", Password: testPassword})
+	assert.NoError(t, err, "client.CreateUser")
+	assert.Equal(t, contracts.UserRole_USER_ROLE_USER, author.Role)
+	moderator := grpcCreateModerator(t, ctx, conn, "moderator", testModeratorEmail)
+	assert.Equal(t, contracts.UserRole_USER_ROLE_MODERATOR, moderator.GetRole())
+	authorCtx := grpcLogin(t, ctx, conn, "olega@gmail.com")
+	moderatorCtx := grpcLogin(t, ctx, conn, testModeratorEmail)
+
+	ad, err := clientAd.CreateAd(authorCtx, &contracts.CreateAdRequest{Title: "the book", Text: "the text"})
+	assert.NoError(t, err, "client.CreateAd")
+	assert.Equal(t, contracts.AdState_AD_STATE_DRAFT, ad.State)
+
+	_, err = clientAd.SubmitAd(authorCtx, &contracts.SubmitAdRequest{AdId: ad.Id})
+	assert.NoError(t, err, "client.SubmitAd")
+
+	_, err = clientAd.ListModerationQueue(authorCtx, &emptypb.Empty{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	queue, err := clientAd.ListModerationQueue(moderatorCtx, &emptypb.Empty{})
+	assert.NoError(t, err, "client.ListModerationQueue")
+	assert.Len(t, queue.List, 1)
+
+	_, err = clientAd.ApproveAd(authorCtx, &contracts.ApproveAdRequest{AdId: ad.Id})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = clientAd.RejectAd(moderatorCtx, &contracts.RejectAdRequest{AdId: ad.Id})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	rejected, err := clientAd.RejectAd(moderatorCtx, &contracts.RejectAdRequest{AdId: ad.Id, Reason: "no photos"})
+	assert.NoError(t, err, "client.RejectAd")
+	assert.Equal(t, contracts.AdState_AD_STATE_REJECTED, rejected.State)
+	assert.Equal(t, "no photos", rejected.RejectionReason)
+
+	_, err = clientAd.ChangeAdStatus(authorCtx, &contracts.ChangeAdStatusRequest{AdId: ad.Id, Published: true})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	_, err = clientAd.SubmitAd(authorCtx, &contracts.SubmitAdRequest{AdId: ad.Id})
+	assert.NoError(t, err, "client.SubmitAd")
+	approved, err := clientAd.ApproveAd(moderatorCtx, &contracts.ApproveAdRequest{AdId: ad.Id})
+	assert.NoError(t, err, "client.ApproveAd")
+	assert.Equal(t, contracts.AdState_AD_STATE_APPROVED, approved.State)
+	assert.Empty(t, approved.RejectionReason)
+
+	published, err := clientAd.ChangeAdStatus(authorCtx, &contracts.ChangeAdStatusRequest{AdId: ad.Id, Published: true})
 	assert.NoError(t, err, "client.ChangeAdStatus")
+	assert.Equal(t, contracts.AdState_AD_STATE_PUBLISHED, published.State)
+}
 
-	ads, err := clientAd.ListAds(ctx, &contracts.ListAdsRequest{Published: "true", UserId: "0"})
-	assert.NoError(t, err, "client.ListAds")
+func TestGRPCSetUserRole(t *testing.T) {
+	ctx, conn := newGRPCTestConn(t)
+	clientUser := contracts.NewUserServiceClient(conn)
 
-	assert.Len(t, ads.List, 1)
-	assert.Equal(t, ads.List[0].Id, res.Id)
-	assert.Equal(t, ads.List[0].Title, res.Title)
-	assert.Equal(t, ads.List[0].Text, res.Text)
-	assert.Equal(t, ads.List[0].UserId, res.UserId)
+	user, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: testModeratorEmail, Password: testPassword})
+	assert.NoError(t, err, "client.CreateUser")
+	assert.Equal(t, contracts.UserRole_USER_ROLE_USER, user.Role, "the email does not grant a role")
+	userCtx := grpcLogin(t, ctx, conn, testModeratorEmail)
+
+	_, err = clientUser.SetUserRole(userCtx, &contracts.SetUserRoleRequest{UserId: user.UserId, Role: contracts.UserRole_USER_ROLE_ADMIN})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	adminCtx := grpcLogin(t, ctx, conn, testAdminEmail)
+	_, err = clientUser.SetUserRole(adminCtx, &contracts.SetUserRoleRequest{UserId: user.UserId})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	moderator, err := clientUser.SetUserRole(adminCtx,
+		&contracts.SetUserRoleRequest{UserId: user.UserId, Role: contracts.UserRole_USER_ROLE_MODERATOR})
+	assert.NoError(t, err, "client.SetUserRole")
+	assert.Equal(t, contracts.UserRole_USER_ROLE_MODERATOR, moderator.Role)
 }
@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	grpchandler "homework10/internal/api/handlers/grpc"
+	contracts "homework10/internal/api/handlers/grpc/contracts/langs/go"
+	"homework10/internal/api/handlers/grpc/interceptors"
+	"homework10/internal/auth"
+	"homework10/internal/idempotency"
+	"homework10/internal/policy"
+	"homework10/internal/repository/local-repo"
+	tracingrepo "homework10/internal/repository/tracing-repo"
+	"homework10/internal/service"
+)
+
+// newGRPCTestConn поднимает gRPC сервер с проверкой токенов поверх bufconn и возвращает подключение к нему
+func newGRPCTestConn(t *testing.T) (context.Context, *grpc.ClientConn) {
+	return newGRPCTestConnWithLimiter(t, nil)
+}
+
+// newGRPCTestConnWithLimiter то же, что newGRPCTestConn, но вызовы проходят через ограничитель limiter
+func newGRPCTestConnWithLimiter(t *testing.T, limiter interceptors.RateLimiter) (context.Context, *grpc.ClientConn) {
+	lis := bufconn.Listen(1024 * 1024)
+	t.Cleanup(func() {
+		lis.Close()
+	})
+
+	userRepo := tracingrepo.NewUserRepo(localrepo.NewUserRepo())
+	adService := service.NewAdService(tracingrepo.NewAdRepo(localrepo.NewAdRepo()), localrepo.NewRevisionRepo())
+	adService.Favorites = localrepo.NewFavoriteRepo()
+	userService := service.NewUserService(userRepo, adService)
+	userService.PasswordCost = bcrypt.MinCost
+	// администратора назначают так же, как при запуске сервиса: по ID уже зарегистрированного пользователя
+	admin, err := userService.CreateUser(context.Background(), "admin", testAdminEmail, testPassword)
+	assert.NoError(t, err, "userService.CreateUser")
+	userService.Admins = []int64{admin.ID}
+	assert.NoError(t, userService.AssignRoles(context.Background()), "userService.AssignRoles")
+	tracedAdService := service.NewTracedAdService(adService)
+	tracedUserService := service.NewTracedUserService(userService)
+	tokens := auth.NewTokenManager([]byte(testTokenSecret), time.Hour)
+	authService := service.NewAuthService(userRepo, tokens)
+
+	userMiddleware := interceptors.NewGRPCUserIdentityMiddleware(tokens, tracedUserService, policy.Default())
+	unary := []grpc.UnaryServerInterceptor{interceptors.GRPCTracingInterceptor, userMiddleware.GRPCUserMiddleware}
+	stream := []grpc.StreamServerInterceptor{interceptors.GRPCTracingStreamInterceptor, userMiddleware.GRPCUserStreamMiddleware}
+	if limiter != nil {
+		rateLimitMiddleware := interceptors.NewGRPCRateLimitMiddleware(limiter)
+		unary = append(unary, rateLimitMiddleware.GRPCRateLimitInterceptor)
+		stream = append(stream, rateLimitMiddleware.GRPCRateLimitStreamInterceptor)
+	}
+	idempotencyMiddleware := interceptors.NewGRPCIdempotencyMiddleware(idempotency.NewStore(time.Hour))
+	unary = append(unary, idempotencyMiddleware.GRPCIdempotencyInterceptor)
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(append(unary, interceptors.ErrorInterceptor)...),
+		grpc.ChainStreamInterceptor(append(stream, interceptors.ErrorStreamInterceptor)...),
+	)
+	t.Cleanup(func() {
+		srv.Stop()
+	})
+
+	grpcAdHandler := grpchandler.NewAdHandler(tracedAdService)
+	contracts.RegisterAdServiceServer(srv, grpcAdHandler)
+
+	grpcUserHandler := grpchandler.NewUserHandler(tracedUserService)
+	contracts.RegisterUserServiceServer(srv, grpcUserHandler)
+
+	grpcMessageHandler := grpchandler.NewMessageHandler(service.NewMessageService(localrepo.NewMessageRepo(), adService))
+	contracts.RegisterMessageServiceServer(srv, grpcMessageHandler)
+
+	grpcAuthHandler := grpchandler.NewAuthHandler(authService)
+	contracts.RegisterAuthServiceServer(srv, grpcAuthHandler)
+
+	go func() {
+		assert.NoError(t, srv.Serve(lis), "srv.Serve")
+	}()
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	t.Cleanup(func() {
+		cancel()
+	})
+
+	conn, err := grpc.DialContext(
+		ctx,
+		"",
+		grpc.WithContextDialer(dialer),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err, "grpc.DialContext")
+
+	t.Cleanup(func() {
+		conn.Close()
+	})
+	return ctx, conn
+}
+
+// grpcLogin получает токен пользователя, зарегистрированного с testPassword, и возвращает контекст, передающий его в метаданных authorization
+func grpcLogin(t *testing.T, ctx context.Context, conn *grpc.ClientConn, email string) context.Context {
+	token, err := contracts.NewAuthServiceClient(conn).Login(ctx, &contracts.LoginRequest{Email: email, Password: testPassword})
+	assert.NoError(t, err, "client.Login")
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token.GetToken())
+}
+
+// grpcCreateModerator регистрирует пользователя и выдаёт ему роль модератора от имени администратора
+func grpcCreateModerator(t *testing.T, ctx context.Context, conn *grpc.ClientConn, nickname string, email string) *contracts.UserResponse {
+	clientUser := contracts.NewUserServiceClient(conn)
+	user, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: nickname, Email: email, Password: testPassword})
+	assert.NoError(t, err, "client.CreateUser")
+	moderator, err := clientUser.SetUserRole(grpcLogin(t, ctx, conn, testAdminEmail),
+		&contracts.SetUserRoleRequest{UserId: user.GetUserId(), Role: contracts.UserRole_USER_ROLE_MODERATOR})
+	assert.NoError(t, err, "client.SetUserRole")
+	return moderator
+}
+
+// grpcApproveAd отправляет объявление на проверку от имени автора (authCtx) и одобряет его модератором
+// с testModeratorEmail, которого регистрирует при первом вызове
+func grpcApproveAd(t *testing.T, authCtx context.Context, conn *grpc.ClientConn, adID int64) *contracts.AdResponse {
+	ctx := metadata.NewOutgoingContext(authCtx, metadata.MD{})
+	_, err := contracts.NewAuthServiceClient(conn).Login(ctx, &contracts.LoginRequest{Email: testModeratorEmail, Password: testPassword})
+	if status.Code(err) == codes.Unauthenticated {
+		grpcCreateModerator(t, ctx, conn, "moderator", testModeratorEmail)
+	}
+
+	clientAd := contracts.NewAdServiceClient(conn)
+	_, err = clientAd.SubmitAd(authCtx, &contracts.SubmitAdRequest{AdId: adID})
+	assert.NoError(t, err, "client.SubmitAd")
+	ad, err := clientAd.ApproveAd(grpcLogin(t, ctx, conn, testModeratorEmail), &contracts.ApproveAdRequest{AdId: adID})
+	assert.NoError(t, err, "client.ApproveAd")
+	return ad
+}
@@ -3,6 +3,7 @@ package tests
 import (
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 // Tests for new http methods from homework 9 and 8 are added here
@@ -16,12 +17,22 @@ func TestGetAd(t *testing.T) {
 	response, err := client.createAd(user.Data.ID, "hello", "world")
 	assert.NoError(t, err)
 
-	response, err = client.getAd(response.Data.ID)
+	response, err = client.getAd(user.Data.ID, response.Data.ID)
 	assert.NoError(t, err)
 	assert.Equal(t, response.Data.Title, "hello")
 	assert.Equal(t, response.Data.Text, "world")
 }
 
+func TestGetAd_NotFound(t *testing.T) {
+	client := getTestClient()
+
+	_, err := client.getAd(anonymous, 100)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = client.getUser(100)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
 func TestDeleteAd(t *testing.T) {
 	client := getTestClient()
 
@@ -31,7 +42,7 @@ func TestDeleteAd(t *testing.T) {
 	response, err := client.createAd(user.Data.ID, "hello", "world")
 	assert.NoError(t, err)
 
-	response, err = client.deleteAd(response.Data.ID)
+	response, err = client.deleteAd(user.Data.ID, response.Data.ID)
 	assert.NoError(t, err)
 	assert.Equal(t, response.Data.Title, "")
 	assert.Equal(t, response.Data.Text, "")
@@ -46,6 +57,8 @@ func TestListAdsWithFilters(t *testing.T) {
 	response, err := client.createAd(user.Data.ID, "hello", "world")
 	assert.NoError(t, err)
 
+	assert.NoError(t, client.approveAd(user.Data.ID, response.Data.ID))
+
 	publishedAd, err := client.changeAdStatus(user.Data.ID, response.Data.ID, true)
 	assert.NoError(t, err)
 
@@ -62,6 +75,45 @@ func TestListAdsWithFilters(t *testing.T) {
 	assert.True(t, ads.Data[0].Published)
 }
 
+func TestListAdsByDates(t *testing.T) {
+	client := getTestClient()
+
+	user, err := client.createUser("user_1", "email@gmail.com")
+	assert.NoError(t, err)
+
+	response, err := client.createAd(user.Data.ID, "hello", "world")
+	assert.NoError(t, err)
+	created, err := time.Parse(time.RFC3339, response.Data.DateCreation)
+	assert.NoError(t, err)
+	assert.Equal(t, response.Data.DateCreation, response.Data.DateUpdate)
+
+	from := created.Add(-time.Minute).Format(time.RFC3339)
+	to := created.Add(time.Minute).Format(time.RFC3339)
+
+	ads, err := client.listAdsByDates(user.Data.ID, from, to, "")
+	assert.NoError(t, err)
+	assert.Len(t, ads.Data, 1)
+	assert.Equal(t, response.Data.ID, ads.Data[0].ID)
+
+	ads, err = client.listAdsByDates(anonymous, from, to, "")
+	assert.NoError(t, err)
+	assert.Empty(t, ads.Data, "drafts are listed only to their author")
+
+	ads, err = client.listAdsByDates(user.Data.ID, "", from, "")
+	assert.NoError(t, err)
+	assert.Empty(t, ads.Data)
+
+	ads, err = client.listAdsByDates(user.Data.ID, "", "", to)
+	assert.NoError(t, err)
+	assert.Empty(t, ads.Data)
+
+	_, err = client.listAdsByDates(user.Data.ID, to, from, "")
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	_, err = client.listAdsByDates(user.Data.ID, "05-01-2023", "", "")
+	assert.ErrorIs(t, err, ErrBadRequest)
+}
+
 func TestSearchAds(t *testing.T) {
 	client := getTestClient()
 
@@ -71,16 +123,66 @@ func TestSearchAds(t *testing.T) {
 	response, err := client.createAd(user.Data.ID, "hello cats", "world")
 	assert.NoError(t, err)
 
-	_, err = client.createAd(user.Data.ID, "best cat", "not for sale")
+	second, err := client.createAd(user.Data.ID, "best cat", "my cat")
+	assert.NoError(t, err)
+
+	third, err := client.createAd(user.Data.ID, "Продам котят", "Хороший кот, недорого")
+	assert.NoError(t, err)
+
+	for _, adID := range []int64{response.Data.ID, second.Data.ID, third.Data.ID} {
+		assert.NoError(t, client.approveAd(user.Data.ID, adID))
+		_, err = client.changeAdStatus(user.Data.ID, adID, true)
+		assert.NoError(t, err)
+	}
+	// черновики в поиск не попадают
+	_, err = client.createAd(user.Data.ID, "cats draft", "world")
 	assert.NoError(t, err)
 
 	ads, err := client.searchAds("cats")
 	assert.NoError(t, err)
+	assert.Len(t, ads.Data, 2)
+	assert.Equal(t, ads.Data[0].ID, second.Data.ID)
+	assert.Equal(t, ads.Data[1].ID, response.Data.ID)
+	assert.Equal(t, ads.Data[1].Title, response.Data.Title)
+	assert.Equal(t, ads.Data[1].Text, response.Data.Text)
+	assert.Equal(t, ads.Data[1].AuthorID, response.Data.AuthorID)
+	assert.Equal(t, "my <mark>cat</mark>", ads.Data[0].Snippet)
+
+	ads, err = client.searchAds("кот")
+	assert.NoError(t, err)
+	assert.Len(t, ads.Data, 1)
+	assert.Equal(t, "Хороший <mark>кот</mark>, недорого", ads.Data[0].Snippet)
+}
+
+func TestListAdsPagination(t *testing.T) {
+	client := getTestClient()
+
+	user, err := client.createUser("user_1", "email@gmail.com")
+	assert.NoError(t, err)
+
+	for _, title := range []string{"b", "c", "a"} {
+		ad, err := client.createAd(user.Data.ID, title, "world")
+		assert.NoError(t, err)
+		assert.NoError(t, client.approveAd(user.Data.ID, ad.Data.ID))
+		_, err = client.changeAdStatus(user.Data.ID, ad.Data.ID, true)
+		assert.NoError(t, err)
+	}
+
+	ads, err := client.listAdsPage(2, "title", "desc", "")
+	assert.NoError(t, err)
+	assert.Len(t, ads.Data, 2)
+	assert.Equal(t, "c", ads.Data[0].Title)
+	assert.Equal(t, "b", ads.Data[1].Title)
+	assert.NotEmpty(t, ads.NextPageToken)
+
+	ads, err = client.listAdsPage(2, "title", "desc", ads.NextPageToken)
+	assert.NoError(t, err)
 	assert.Len(t, ads.Data, 1)
-	assert.Equal(t, ads.Data[0].ID, response.Data.ID)
-	assert.Equal(t, ads.Data[0].Title, response.Data.Title)
-	assert.Equal(t, ads.Data[0].Text, response.Data.Text)
-	assert.Equal(t, ads.Data[0].AuthorID, response.Data.AuthorID)
+	assert.Equal(t, "a", ads.Data[0].Title)
+	assert.Empty(t, ads.NextPageToken)
+
+	_, err = client.listAdsPage(2, "text", "desc", "")
+	assert.ErrorIs(t, err, ErrBadRequest)
 }
 
 func TestCreateUser(t *testing.T) {
@@ -0,0 +1,156 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	contracts "homework10/internal/api/handlers/grpc/contracts/langs/go"
+	"homework10/internal/idempotency"
+)
+
+// idempotentRequest отправляет запрос с заголовком Idempotency-Key и возвращает ответ с прочитанным телом
+func (tc *testClient) idempotentRequest(userID int64, method string, path string, key string, body any) (*http.Response, []byte) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			panic(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, tc.baseURL+path, reader)
+	if err != nil {
+		panic(err)
+	}
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Idempotency-Key", key)
+	tc.authorize(req, userID)
+
+	resp, err := tc.client.Do(req)
+	if err != nil {
+		panic(err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		panic(err)
+	}
+	return resp, respBody
+}
+
+func TestIdempotency_CreateAd(t *testing.T) {
+	client := getTestClient()
+	user, err := client.createUser("ivan", "ivan@gmail.com")
+	assert.NoError(t, err)
+	other, err := client.createUser("petr", "petr@gmail.com")
+	assert.NoError(t, err)
+	body := map[string]any{"title": "hello", "text": "world"}
+
+	first, firstBody := client.idempotentRequest(user.Data.ID, http.MethodPost, "/api/v1/ads/", "key-1", body)
+	assert.Equal(t, http.StatusOK, first.StatusCode)
+	assert.Empty(t, first.Header.Get("Idempotent-Replayed"))
+	retry, retryBody := client.idempotentRequest(user.Data.ID, http.MethodPost, "/api/v1/ads/", "key-1", body)
+	assert.Equal(t, http.StatusOK, retry.StatusCode)
+	assert.Equal(t, "true", retry.Header.Get("Idempotent-Replayed"))
+	assert.Equal(t, string(firstBody), string(retryBody))
+	assert.Equal(t, first.Header.Get("ETag"), retry.Header.Get("ETag"))
+
+	var created adResponse
+	require.NoError(t, json.Unmarshal(firstBody, &created))
+	next, err := client.createAd(user.Data.ID, "hello", "world")
+	assert.NoError(t, err)
+	assert.Equal(t, created.Data.ID+1, next.Data.ID, "the retry did not create an ad")
+
+	changed, _ := client.idempotentRequest(user.Data.ID, http.MethodPost, "/api/v1/ads/", "key-1",
+		map[string]any{"title": "hello", "text": "another world"})
+	assert.Equal(t, http.StatusUnprocessableEntity, changed.StatusCode)
+
+	otherUser, otherBody := client.idempotentRequest(other.Data.ID, http.MethodPost, "/api/v1/ads/", "key-1", body)
+	assert.Equal(t, http.StatusOK, otherUser.StatusCode, "keys of different users do not clash")
+	var otherAd adResponse
+	require.NoError(t, json.Unmarshal(otherBody, &otherAd))
+	assert.Equal(t, next.Data.ID+1, otherAd.Data.ID)
+}
+
+func TestIdempotency_MutatingEndpoints(t *testing.T) {
+	client := getTestClient()
+
+	body := map[string]any{"nickname": "ivan", "email": "ivan@gmail.com", "password": testPassword}
+	first, firstBody := client.idempotentRequest(0, http.MethodPost, "/api/v1/users", "user-key", body)
+	assert.Equal(t, http.StatusOK, first.StatusCode)
+	retry, retryBody := client.idempotentRequest(0, http.MethodPost, "/api/v1/users", "user-key", body)
+	assert.Equal(t, http.StatusOK, retry.StatusCode, "a retried registration is not a conflict")
+	assert.Equal(t, string(firstBody), string(retryBody))
+
+	user, err := client.createUser("petr", "petr@gmail.com")
+	assert.NoError(t, err)
+	ad, err := client.createAd(user.Data.ID, "hello", "world")
+	assert.NoError(t, err)
+
+	// ошибки клиента тоже повторяются: объявление ещё не прошло модерацию
+	statusPath := fmt.Sprintf("/api/v1/ads/%d/status", ad.Data.ID)
+	rejected, _ := client.idempotentRequest(user.Data.ID, http.MethodPut, statusPath, "status-key", map[string]any{"published": true})
+	assert.Equal(t, http.StatusConflict, rejected.StatusCode)
+	assert.NoError(t, client.approveAd(user.Data.ID, ad.Data.ID))
+	rejected, _ = client.idempotentRequest(user.Data.ID, http.MethodPut, statusPath, "status-key", map[string]any{"published": true})
+	assert.Equal(t, http.StatusConflict, rejected.StatusCode)
+	assert.Equal(t, "true", rejected.Header.Get("Idempotent-Replayed"))
+	published, _ := client.idempotentRequest(user.Data.ID, http.MethodPut, statusPath, "status-key-2", map[string]any{"published": true})
+	assert.Equal(t, http.StatusOK, published.StatusCode)
+
+	adPath := fmt.Sprintf("/api/v1/ads/%d", ad.Data.ID)
+	deleted, _ := client.idempotentRequest(user.Data.ID, http.MethodDelete, adPath, "delete-key", nil)
+	assert.Equal(t, http.StatusOK, deleted.StatusCode)
+	deleted, _ = client.idempotentRequest(user.Data.ID, http.MethodDelete, adPath, "delete-key", nil)
+	assert.Equal(t, http.StatusOK, deleted.StatusCode, "a retried deletion gets the first response")
+	_, err = client.deleteAd(user.Data.ID, ad.Data.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestIdempotency_BodyTooLarge(t *testing.T) {
+	client := getTestClient()
+	user, err := client.createUser("ivan", "ivan@gmail.com")
+	require.NoError(t, err)
+
+	body := map[string]any{"title": "hello", "text": strings.Repeat("a", idempotency.MaxBodySize)}
+	resp, _ := client.idempotentRequest(user.Data.ID, http.MethodPost, "/api/v1/ads/", "key-1", body)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+}
+
+func TestGRRPCIdempotency(t *testing.T) {
+	ctx, conn := newGRPCTestConn(t)
+
+	clientUser := contracts.NewUserServiceClient(conn)
+	_, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "ivan", Email: "ivan@gmail.com", Password: testPassword})
+	assert.NoError(t, err, "client.CreateUser")
+	authCtx := grpcLogin(t, ctx, conn, "ivan@gmail.com")
+	keyCtx := metadata.AppendToOutgoingContext(authCtx, "idempotency-key", "key-1")
+
+	clientAd := contracts.NewAdServiceClient(conn)
+	first, err := clientAd.CreateAd(keyCtx, &contracts.CreateAdRequest{Title: "hello", Text: "world"})
+	assert.NoError(t, err, "client.CreateAd")
+
+	var header metadata.MD
+	retry, err := clientAd.CreateAd(keyCtx, &contracts.CreateAdRequest{Title: "hello", Text: "world"}, grpc.Header(&header))
+	assert.NoError(t, err, "client.CreateAd")
+	assert.Equal(t, first.Id, retry.Id)
+	assert.Equal(t, []string{"true"}, header.Get("idempotent-replayed"))
+
+	_, err = clientAd.CreateAd(keyCtx, &contracts.CreateAdRequest{Title: "hello", Text: "another world"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	next, err := clientAd.CreateAd(authCtx, &contracts.CreateAdRequest{Title: "hello", Text: "world"})
+	assert.NoError(t, err, "client.CreateAd")
+	assert.Equal(t, first.Id+1, next.Id)
+}
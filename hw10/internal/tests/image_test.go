@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	contracts "homework10/internal/api/handlers/grpc/contracts/langs/go"
+)
+
+// testImage PNG-изображение 640x480
+func testImage(t *testing.T) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 640, 480))))
+	return buf.Bytes()
+}
+
+func TestUploadAdImage(t *testing.T) {
+	client := getTestClient()
+	user, err := client.createUser("user_1", "email@gmail.com")
+	assert.NoError(t, err)
+	ad, err := client.createAd(user.Data.ID, "hello", "world")
+	assert.NoError(t, err)
+	photo := testImage(t)
+
+	resp, err := client.uploadAdImage(user.Data.ID, ad.Data.ID, "photo.png", photo)
+	assert.NoError(t, err)
+	require.Len(t, resp.Data.Images, 1)
+	uploaded := resp.Data.Images[0]
+	assert.Equal(t, fmt.Sprintf("/api/v1/ads/%d/images/1", ad.Data.ID), uploaded.URL)
+	assert.Equal(t, uploaded.URL+"/thumbnail", uploaded.ThumbnailURL)
+	assert.Equal(t, "image/png", uploaded.ContentType)
+	assert.Equal(t, int64(len(photo)), uploaded.Size)
+	assert.Equal(t, 640, uploaded.Width)
+	assert.Equal(t, 480, uploaded.Height)
+
+	got, err := client.getAd(user.Data.ID, ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, resp.Data.Images, got.Data.Images)
+
+	contentType, data, err := client.download(user.Data.ID, uploaded.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", contentType)
+	assert.Equal(t, photo, data)
+
+	contentType, data, err = client.download(user.Data.ID, uploaded.ThumbnailURL)
+	assert.NoError(t, err)
+	assert.Equal(t, "image/jpeg", contentType)
+	thumbnail, err := jpeg.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 320, 240), thumbnail.Bounds())
+
+	_, _, err = client.download(user.Data.ID, fmt.Sprintf("/api/v1/ads/%d/images/2", ad.Data.ID))
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestUploadAdImage_Errors(t *testing.T) {
+	client := getTestClient()
+	user, err := client.createUser("user_1", "email@gmail.com")
+	assert.NoError(t, err)
+	other, err := client.createUser("user_2", "other@gmail.com")
+	assert.NoError(t, err)
+	ad, err := client.createAd(user.Data.ID, "hello", "world")
+	assert.NoError(t, err)
+
+	// расширение файла не важно, тип определяется по содержимому
+	_, err = client.uploadAdImage(user.Data.ID, ad.Data.ID, "photo.png", []byte("just some text"))
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	_, err = client.uploadAdImage(other.Data.ID, ad.Data.ID, "photo.png", testImage(t))
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	_, err = client.uploadAdImage(-1, ad.Data.ID, "photo.png", testImage(t))
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	_, err = client.uploadAdImage(user.Data.ID, 100, "photo.png", testImage(t))
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestGRRPCUploadAdImage(t *testing.T) {
+	ctx, conn := newGRPCTestConn(t)
+
+	clientUser := contracts.NewUserServiceClient(conn)
+	_, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com", Password: testPassword})
+	assert.NoError(t, err, "client.CreateUser")
+	authCtx := grpcLogin(t, ctx, conn, "olega@gmail.com")
+
+	clientAd := contracts.NewAdServiceClient(conn)
+	ad, err := clientAd.CreateAd(authCtx, &contracts.CreateAdRequest{Title: "the book", Text: "the text"})
+	assert.NoError(t, err, "client.CreateAd")
+
+	upload := func(ctx context.Context, info *contracts.UploadAdImageInfo, data []byte) (*contracts.AdResponse, error) {
+		stream, err := clientAd.UploadAdImage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if info != nil {
+			err = stream.Send(&contracts.UploadAdImageRequest{Data: &contracts.UploadAdImageRequest_Info{Info: info}})
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+		}
+		// изображение передаётся частями
+		for len(data) > 0 {
+			n := 1000
+			if n > len(data) {
+				n = len(data)
+			}
+			err = stream.Send(&contracts.UploadAdImageRequest{Data: &contracts.UploadAdImageRequest_Chunk{Chunk: data[:n]}})
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			data = data[n:]
+		}
+		return stream.CloseAndRecv()
+	}
+
+	photo := testImage(t)
+	res, err := upload(authCtx, &contracts.UploadAdImageInfo{AdId: ad.Id, ExpectedVersion: ad.Version}, photo)
+	assert.NoError(t, err, "client.UploadAdImage")
+	if assert.Len(t, res.Images, 1) {
+		assert.Equal(t, fmt.Sprintf("/api/v1/ads/%d/images/1", ad.Id), res.Images[0].Url)
+		assert.Equal(t, int64(len(photo)), res.Images[0].Size)
+		assert.Equal(t, int32(640), res.Images[0].Width)
+	}
+	assert.Equal(t, ad.Version+1, res.Version)
+
+	_, err = upload(authCtx, nil, photo)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = upload(authCtx, &contracts.UploadAdImageInfo{AdId: ad.Id}, []byte("just some text"))
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = upload(authCtx, &contracts.UploadAdImageInfo{AdId: ad.Id, ExpectedVersion: ad.Version}, photo)
+	assert.Equal(t, codes.Aborted, status.Code(err))
+	_, err = upload(ctx, &contracts.UploadAdImageInfo{AdId: ad.Id}, photo)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	contracts "homework10/internal/api/handlers/grpc/contracts/langs/go"
+)
+
+func TestMessages(t *testing.T) {
+	client := getTestClient()
+	seller, err := client.createUser("seller", "seller@gmail.com")
+	assert.NoError(t, err)
+	buyer, err := client.createUser("buyer", "buyer@gmail.com")
+	assert.NoError(t, err)
+	ad := createPublishedAd(t, client, seller.Data.ID, map[string]any{"title": "bicycle", "text": "almost new"})
+
+	first, err := client.sendMessage(buyer.Data.ID, ad.ID, 0, "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, buyer.Data.ID, first.Data.SenderID)
+	second, err := client.sendMessage(buyer.Data.ID, ad.ID, 0, "is it available?")
+	assert.NoError(t, err)
+	assert.Equal(t, first.Data.ConversationID, second.Data.ConversationID, "one conversation per buyer and ad")
+	conversationID := first.Data.ConversationID
+
+	inbox, err := client.listConversations(seller.Data.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, inbox.UnreadCount)
+	require.Len(t, inbox.Data, 1)
+	assert.Equal(t, ad.ID, inbox.Data[0].AdID)
+	assert.Equal(t, buyer.Data.ID, inbox.Data[0].BuyerID)
+	assert.Equal(t, seller.Data.ID, inbox.Data[0].SellerID)
+	assert.Equal(t, "is it available?", inbox.Data[0].LastMessage.Text)
+
+	assert.NoError(t, client.markConversationRead(seller.Data.ID, conversationID))
+	_, err = client.sendMessage(seller.Data.ID, ad.ID, conversationID, "yes")
+	assert.NoError(t, err)
+
+	inbox, err = client.listConversations(seller.Data.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, inbox.UnreadCount)
+	inbox, err = client.listConversations(buyer.Data.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, inbox.UnreadCount)
+
+	messages, err := client.listMessages(buyer.Data.ID, conversationID)
+	assert.NoError(t, err)
+	require.Len(t, messages.Data, 3)
+	assert.NotEmpty(t, messages.Data[0].ReadAt, "the buyer sees that the seller has read the message")
+	assert.Equal(t, "yes", messages.Data[2].Text)
+	assert.Empty(t, messages.Data[2].ReadAt)
+}
+
+func TestMessages_Errors(t *testing.T) {
+	client := getTestClient()
+	seller, err := client.createUser("seller", "seller@gmail.com")
+	assert.NoError(t, err)
+	buyer, err := client.createUser("buyer", "buyer@gmail.com")
+	assert.NoError(t, err)
+	stranger, err := client.createUser("stranger", "stranger@gmail.com")
+	assert.NoError(t, err)
+	ad := createPublishedAd(t, client, seller.Data.ID, map[string]any{"title": "bicycle", "text": "almost new"})
+	draft, err := client.createAd(seller.Data.ID, "draft", "text")
+	assert.NoError(t, err)
+
+	message, err := client.sendMessage(buyer.Data.ID, ad.ID, 0, "hello")
+	assert.NoError(t, err)
+	conversationID := message.Data.ConversationID
+
+	_, err = client.sendMessage(seller.Data.ID, ad.ID, 0, "hello")
+	assert.ErrorIs(t, err, ErrForbidden, "the author replies only in a conversation")
+	_, err = client.sendMessage(stranger.Data.ID, ad.ID, conversationID, "hello")
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = client.listMessages(stranger.Data.ID, conversationID)
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = client.sendMessage(buyer.Data.ID, draft.Data.ID, 0, "hello")
+	assert.ErrorIs(t, err, ErrConflict)
+	_, err = client.sendMessage(buyer.Data.ID, ad.ID, 0, " ")
+	assert.ErrorIs(t, err, ErrBadRequest)
+	_, err = client.sendMessage(buyer.Data.ID, ad.ID, conversationID+100, "hello")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = client.listConversations(buyer.Data.ID + 100)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestGRRPCMessages(t *testing.T) {
+	ctx, conn := newGRPCTestConn(t)
+
+	clientUser := contracts.NewUserServiceClient(conn)
+	seller, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "seller", Email: "seller@gmail.com", Password: testPassword})
+	assert.NoError(t, err, "client.CreateUser")
+	buyer, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "buyer", Email: "buyer@gmail.com", Password: testPassword})
+	assert.NoError(t, err, "client.CreateUser")
+	sellerCtx := grpcLogin(t, ctx, conn, "seller@gmail.com")
+	buyerCtx := grpcLogin(t, ctx, conn, "buyer@gmail.com")
+
+	clientAd := contracts.NewAdServiceClient(conn)
+	ad, err := clientAd.CreateAd(sellerCtx, &contracts.CreateAdRequest{Title: "bicycle", Text: "almost new"})
+	assert.NoError(t, err, "client.CreateAd")
+	grpcApproveAd(t, sellerCtx, conn, ad.Id)
+	_, err = clientAd.ChangeAdStatus(sellerCtx, &contracts.ChangeAdStatusRequest{AdId: ad.Id, Published: true})
+	assert.NoError(t, err, "client.ChangeAdStatus")
+
+	clientMessage := contracts.NewMessageServiceClient(conn)
+	message, err := clientMessage.SendMessage(buyerCtx, &contracts.SendMessageRequest{AdId: ad.Id, Text: "hello"})
+	assert.NoError(t, err, "client.SendMessage")
+	assert.Equal(t, buyer.UserId, message.SenderId)
+	assert.NotNil(t, message.DateCreation)
+	assert.Nil(t, message.ReadAt)
+
+	inbox, err := clientMessage.ListConversations(sellerCtx, &contracts.ListConversationsRequest{UserId: seller.UserId})
+	assert.NoError(t, err, "client.ListConversations")
+	assert.Equal(t, int64(1), inbox.UnreadCount)
+	require.Len(t, inbox.List, 1)
+	assert.Equal(t, "hello", inbox.List[0].LastMessage.Text)
+
+	marked, err := clientMessage.MarkConversationRead(sellerCtx,
+		&contracts.MarkConversationReadRequest{UserId: seller.UserId, ConversationId: message.ConversationId})
+	assert.NoError(t, err, "client.MarkConversationRead")
+	assert.Equal(t, int64(1), marked.Marked)
+
+	messages, err := clientMessage.ListMessages(buyerCtx,
+		&contracts.ListMessagesRequest{UserId: buyer.UserId, ConversationId: message.ConversationId})
+	assert.NoError(t, err, "client.ListMessages")
+	require.Len(t, messages.List, 1)
+	assert.NotNil(t, messages.List[0].ReadAt)
+
+	_, err = clientMessage.ListConversations(ctx, &contracts.ListConversationsRequest{UserId: seller.UserId})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = clientMessage.ListMessages(sellerCtx,
+		&contracts.ListMessagesRequest{UserId: buyer.UserId, ConversationId: message.ConversationId})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
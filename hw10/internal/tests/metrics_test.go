@@ -0,0 +1,39 @@
+package tests
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"homework10/internal/api/handlers/httpgin"
+	"homework10/internal/metrics"
+)
+
+func TestHTTPMetrics(t *testing.T) {
+	appMetrics := metrics.New()
+	client := getTestClientWithOptions(httpgin.Options{Metrics: appMetrics})
+
+	user, err := client.createUser("ivan", "ivan@gmail.com")
+	assert.NoError(t, err)
+	_, err = client.createAd(user.Data.ID, "hello", "world")
+	assert.NoError(t, err)
+	_, err = client.getAd(anonymous, 100)
+	assert.ErrorIs(t, err, ErrNotFound)
+	resp, err := client.client.Get(client.baseURL + "/unknown/42")
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	w := httptest.NewRecorder()
+	appMetrics.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, err := io.ReadAll(w.Body)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(body), `homework10_http_requests_total{method="POST",route="/api/v1/users",status="200"} 1`)
+	assert.Contains(t, string(body), `homework10_http_requests_total{method="POST",route="/api/v1/ads/",status="200"} 1`)
+	assert.Contains(t, string(body), `homework10_http_requests_total{method="GET",route="/api/v1/ads/:ad_id",status="404"} 1`)
+	assert.Contains(t, string(body), `homework10_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, string(body), `homework10_http_requests_in_flight{method="GET",route="/api/v1/ads/:ad_id"} 0`)
+}
@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModerationFlow(t *testing.T) {
+	client := getTestClient()
+	author, err := client.createUser("author", "author@gmail.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "user", author.Data.Role)
+	moderator, err := client.createModerator("moderator", testModeratorEmail)
+	assert.NoError(t, err)
+	assert.Equal(t, "moderator", moderator.Data.Role)
+
+	ad, err := client.createAd(author.Data.ID, "hello", "world")
+	assert.NoError(t, err)
+	assert.Equal(t, "draft", ad.Data.State)
+
+	// без одобрения модератора объявление не публикуется
+	_, err = client.changeAdStatus(author.Data.ID, ad.Data.ID, true)
+	assert.ErrorIs(t, err, ErrConflict)
+
+	// проверять можно только отправленные объявления
+	_, err = client.reviewAd(moderator.Data.ID, ad.Data.ID, true, "")
+	assert.ErrorIs(t, err, ErrConflict)
+
+	submitted, err := client.submitAd(author.Data.ID, ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "pending_review", submitted.Data.State)
+
+	_, err = client.moderationQueue(author.Data.ID)
+	assert.ErrorIs(t, err, ErrForbidden)
+	queue, err := client.moderationQueue(moderator.Data.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, queue.Data, 1) {
+		assert.Equal(t, ad.Data.ID, queue.Data[0].ID)
+	}
+
+	_, err = client.reviewAd(author.Data.ID, ad.Data.ID, true, "")
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = client.reviewAd(moderator.Data.ID, ad.Data.ID, false, "")
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	rejected, err := client.reviewAd(moderator.Data.ID, ad.Data.ID, false, "no photos")
+	assert.NoError(t, err)
+	assert.Equal(t, "rejected", rejected.Data.State)
+	assert.Equal(t, "no photos", rejected.Data.RejectionReason)
+
+	queue, err = client.moderationQueue(moderator.Data.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, queue.Data)
+
+	// исправленное объявление снова становится черновиком и отправляется на проверку
+	updated, err := client.updateAd(author.Data.ID, ad.Data.ID, "hello", "world with photos")
+	assert.NoError(t, err)
+	assert.Equal(t, "draft", updated.Data.State)
+	assert.Empty(t, updated.Data.RejectionReason)
+
+	_, err = client.submitAd(author.Data.ID, ad.Data.ID)
+	assert.NoError(t, err)
+	approved, err := client.reviewAd(moderator.Data.ID, ad.Data.ID, true, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "approved", approved.Data.State)
+
+	published, err := client.changeAdStatus(author.Data.ID, ad.Data.ID, true)
+	assert.NoError(t, err)
+	assert.True(t, published.Data.Published)
+	assert.Equal(t, "published", published.Data.State)
+
+	archived, err := client.changeAdStatus(author.Data.ID, ad.Data.ID, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "archived", archived.Data.State)
+
+	// снятое с публикации объявление возвращается без повторной проверки
+	published, err = client.changeAdStatus(author.Data.ID, ad.Data.ID, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "published", published.Data.State)
+}
+
+func TestModeratorCantReviewOwnAd(t *testing.T) {
+	client := getTestClient()
+	moderator, err := client.createModerator("moderator", testModeratorEmail)
+	assert.NoError(t, err)
+
+	ad, err := client.createAd(moderator.Data.ID, "hello", "world")
+	assert.NoError(t, err)
+	_, err = client.submitAd(moderator.Data.ID, ad.Data.ID)
+	assert.NoError(t, err)
+
+	_, err = client.reviewAd(moderator.Data.ID, ad.Data.ID, true, "")
+	assert.ErrorIs(t, err, ErrForbidden)
+}
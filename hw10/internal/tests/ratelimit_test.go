@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	contracts "homework10/internal/api/handlers/grpc/contracts/langs/go"
+	"homework10/internal/api/handlers/httpgin"
+	"homework10/internal/ratelimit"
+)
+
+func TestRateLimit(t *testing.T) {
+	limiter := ratelimit.NewLimiter(ratelimit.Limit{}, map[string]ratelimit.Limit{
+		"POST /api/v1/ads/":          {Rate: 1.0 / 60, Burst: 2},
+		"GET /api/v1/users/:user_id": {Rate: 1.0 / 60, Burst: 1},
+	})
+	client := getTestClientWithOptions(httpgin.Options{Limiter: limiter})
+
+	first, err := client.createUser("first", "first@gmail.com")
+	assert.NoError(t, err)
+	second, err := client.createUser("second", "second@gmail.com")
+	assert.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err = client.createAd(first.Data.ID, "hello", "world")
+		assert.NoError(t, err)
+	}
+	_, err = client.createAd(first.Data.ID, "hello", "world")
+	assert.ErrorIs(t, err, ErrTooManyRequests)
+	_, err = client.createAd(second.Data.ID, "hello", "world")
+	assert.NoError(t, err, "each user has its own limit")
+	_, err = client.listAds()
+	assert.NoError(t, err, "routes without a limit are not throttled")
+
+	// публичный маршрут без токена ограничивается по IP-адресу
+	_, err = client.getUser(first.Data.ID)
+	assert.NoError(t, err)
+	resp, err := client.client.Get(fmt.Sprintf(client.baseURL+"/api/v1/users/%d", second.Data.ID))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	retryAfter, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	assert.NoError(t, err)
+	assert.InDelta(t, 60, retryAfter, 1)
+}
+
+func TestRateLimit_ForwardedFor(t *testing.T) {
+	limiter := ratelimit.NewLimiter(ratelimit.Limit{}, map[string]ratelimit.Limit{
+		"GET /api/v1/users/:user_id": {Rate: 1.0 / 60, Burst: 1},
+	})
+	client := getTestClientWithOptions(httpgin.Options{Limiter: limiter})
+	user, err := client.createUser("first", "first@gmail.com")
+	assert.NoError(t, err)
+
+	// клиент не может сменить свой адрес поддельным X-Forwarded-For
+	for i, expected := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf(client.baseURL+"/api/v1/users/%d", user.Data.ID), nil)
+		assert.NoError(t, err)
+		req.Header.Set("X-Forwarded-For", fmt.Sprintf("203.0.113.%d", i+1))
+		resp, err := client.client.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, expected, resp.StatusCode)
+	}
+}
+
+func TestGRRPCRateLimit(t *testing.T) {
+	limiter := ratelimit.NewLimiter(ratelimit.Limit{}, map[string]ratelimit.Limit{
+		"/service.AdService/CreateAd": {Rate: 1.0 / 60, Burst: 1},
+	})
+	ctx, conn := newGRPCTestConnWithLimiter(t, limiter)
+
+	clientUser := contracts.NewUserServiceClient(conn)
+	_, err := clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "first", Email: "first@gmail.com", Password: testPassword})
+	assert.NoError(t, err, "client.CreateUser")
+	_, err = clientUser.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "second", Email: "second@gmail.com", Password: testPassword})
+	assert.NoError(t, err, "client.CreateUser")
+	firstCtx := grpcLogin(t, ctx, conn, "first@gmail.com")
+	secondCtx := grpcLogin(t, ctx, conn, "second@gmail.com")
+
+	clientAd := contracts.NewAdServiceClient(conn)
+	_, err = clientAd.CreateAd(firstCtx, &contracts.CreateAdRequest{Title: "hello", Text: "world"})
+	assert.NoError(t, err, "client.CreateAd")
+
+	var header metadata.MD
+	_, err = clientAd.CreateAd(firstCtx, &contracts.CreateAdRequest{Title: "hello", Text: "world"}, grpc.Header(&header))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, []string{"60"}, header.Get("retry-after"))
+
+	_, err = clientAd.CreateAd(secondCtx, &contracts.CreateAdRequest{Title: "hello", Text: "world"})
+	assert.NoError(t, err, "each user has its own limit")
+}
@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdRevisions(t *testing.T) {
+	client := getTestClient()
+	user, err := client.createUser("user_1", "email@gmail.com")
+	assert.NoError(t, err)
+
+	ad, err := client.createAd(user.Data.ID, "hello", "world")
+	assert.NoError(t, err)
+	_, err = client.updateAd(user.Data.ID, ad.Data.ID, "hello", "new world")
+	assert.NoError(t, err)
+	assert.NoError(t, client.approveAd(user.Data.ID, ad.Data.ID))
+	_, err = client.changeAdStatus(user.Data.ID, ad.Data.ID, true)
+	assert.NoError(t, err)
+
+	revisions, err := client.listAdRevisions(user.Data.ID, ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []revisionData{
+		{
+			Number: 1, Type: "created", AuthorID: user.Data.ID, Title: "hello", Text: "world",
+			Changes: []fieldChangeData{{Field: "title", New: "hello"}, {Field: "text", New: "world"}, {Field: "state", New: "draft"}},
+		},
+		{
+			Number: 2, Type: "updated", AuthorID: user.Data.ID, Title: "hello", Text: "new world",
+			Changes: []fieldChangeData{{Field: "text", Old: "world", New: "new world"}},
+		},
+		{
+			Number: 3, Type: "status_changed", AuthorID: user.Data.ID, Title: "hello", Text: "new world",
+			Changes: []fieldChangeData{{Field: "state", Old: "draft", New: "pending_review"}},
+		},
+		{
+			Number: 4, Type: "status_changed", AuthorID: *client.moderatorID, Title: "hello", Text: "new world",
+			Changes: []fieldChangeData{{Field: "state", Old: "pending_review", New: "approved"}},
+		},
+		{
+			Number: 5, Type: "status_changed", AuthorID: user.Data.ID, Title: "hello", Text: "new world", Published: true,
+			Changes: []fieldChangeData{
+				{Field: "state", Old: "approved", New: "published"},
+				{Field: "published", Old: "false", New: "true"},
+			},
+		},
+	}, revisions.Data)
+
+	// восстановленный текст не проверен модератором, поэтому объявление снимается с публикации
+	restored, err := client.restoreAdRevision(user.Data.ID, ad.Data.ID, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "world", restored.Data.Text)
+	assert.False(t, restored.Data.Published)
+	assert.Equal(t, "draft", restored.Data.State)
+
+	revisions, err = client.listAdRevisions(user.Data.ID, ad.Data.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, revisions.Data, 6) {
+		assert.Equal(t, revisionData{
+			Number: 6, Type: "restored", AuthorID: user.Data.ID, Title: "hello", Text: "world",
+			Changes: []fieldChangeData{
+				{Field: "text", Old: "new world", New: "world"},
+				{Field: "state", Old: "published", New: "draft"},
+				{Field: "published", Old: "true", New: "false"},
+			},
+			RestoredFrom: 1,
+		}, revisions.Data[5])
+	}
+}
+
+func TestRestoreAdRevision_Errors(t *testing.T) {
+	client := getTestClient()
+	owner, err := client.createUser("owner", "owner@gmail.com")
+	assert.NoError(t, err)
+	other, err := client.createUser("other", "other@gmail.com")
+	assert.NoError(t, err)
+
+	ad, err := client.createAd(owner.Data.ID, "hello", "world")
+	assert.NoError(t, err)
+
+	_, err = client.restoreAdRevision(other.Data.ID, ad.Data.ID, 1)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	_, err = client.restoreAdRevision(owner.Data.ID, ad.Data.ID, 5)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = client.restoreAdRevision(100, ad.Data.ID, 1)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	_, err = client.listAdRevisions(owner.Data.ID, 100)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// в истории есть неопубликованные версии, её видят только автор, модераторы и администраторы
+	_, err = client.listAdRevisions(other.Data.ID, ad.Data.ID)
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = client.listAdRevisions(100, ad.Data.ID)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	_, err = client.listAdRevisions(owner.Data.ID, ad.Data.ID)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamAds(t *testing.T) {
+	client := getTestClient()
+
+	user, err := client.createUser("user_1", "email@gmail.com")
+	require.NoError(t, err)
+	other, err := client.createUser("user_2", "other@gmail.com")
+	require.NoError(t, err)
+
+	resp, err := client.streamAds(user.Data.ID, fmt.Sprintf("?user_id=%d", user.Data.ID), "")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	// анонимный подписчик видит объявление только опубликованным
+	anonymous, err := client.streamAds(100, fmt.Sprintf("?user_id=%d", user.Data.ID), "")
+	require.NoError(t, err)
+	defer anonymous.Body.Close()
+
+	_, err = client.createAd(other.Data.ID, "other", "ad")
+	require.NoError(t, err)
+	ad, err := client.createAd(user.Data.ID, "hello", "world")
+	require.NoError(t, err)
+	_, err = client.updateAd(user.Data.ID, ad.Data.ID, "new hello", "world")
+	require.NoError(t, err)
+	assert.NoError(t, client.approveAd(user.Data.ID, ad.Data.ID))
+	_, err = client.changeAdStatus(user.Data.ID, ad.Data.ID, true)
+	require.NoError(t, err)
+	_, err = client.changeAdStatus(user.Data.ID, ad.Data.ID, false)
+	require.NoError(t, err)
+	_, err = client.deleteAd(user.Data.ID, ad.Data.ID)
+	require.NoError(t, err)
+
+	reader := bufio.NewReader(resp.Body)
+	var lastID string
+	for _, name := range []string{"created", "updated", "moderated", "moderated", "published", "unpublished", "deleted"} {
+		event, err := readSSEEvent(reader)
+		require.NoError(t, err)
+		assert.Equal(t, name, event.Event)
+
+		var data adData
+		require.NoError(t, json.Unmarshal([]byte(event.Data), &data))
+		assert.Equal(t, ad.Data.ID, data.ID)
+		lastID = event.ID
+	}
+	anonymousReader := bufio.NewReader(anonymous.Body)
+	for _, name := range []string{"published", "left"} {
+		event, err := readSSEEvent(anonymousReader)
+		require.NoError(t, err)
+		assert.Equal(t, name, event.Event)
+
+		var data adData
+		require.NoError(t, json.Unmarshal([]byte(event.Data), &data))
+		assert.Equal(t, ad.Data.ID, data.ID)
+		if name == "left" {
+			assert.Empty(t, data.Title, "an ad that left the filter is sent without its content")
+		}
+	}
+
+	// переподключение с последним полученным номером не повторяет события
+	resumed, err := client.streamAds(user.Data.ID, fmt.Sprintf("?user_id=%d", user.Data.ID), lastID)
+	require.NoError(t, err)
+	defer resumed.Body.Close()
+
+	_, err = client.createAd(user.Data.ID, "again", "world")
+	require.NoError(t, err)
+	event, err := readSSEEvent(bufio.NewReader(resumed.Body))
+	require.NoError(t, err)
+	assert.Equal(t, "created", event.Event)
+	assert.NotEqual(t, lastID, event.ID)
+}
+
+func TestStreamAds_Resume(t *testing.T) {
+	client := getTestClient()
+
+	user, err := client.createUser("user_1", "email@gmail.com")
+	require.NoError(t, err)
+
+	resp, err := client.streamAds(user.Data.ID, "", "")
+	require.NoError(t, err)
+	ad, err := client.createAd(user.Data.ID, "hello", "world")
+	require.NoError(t, err)
+	created, err := readSSEEvent(bufio.NewReader(resp.Body))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	// пока клиент был отключён, объявление опубликовали
+	assert.NoError(t, client.approveAd(user.Data.ID, ad.Data.ID))
+	_, err = client.changeAdStatus(user.Data.ID, ad.Data.ID, true)
+	require.NoError(t, err)
+
+	resp, err = client.streamAds(user.Data.ID, "", created.ID)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+	for _, name := range []string{"moderated", "moderated", "published"} {
+		event, err := readSSEEvent(reader)
+		require.NoError(t, err)
+		assert.Equal(t, name, event.Event)
+	}
+
+	_, err = client.streamAds(user.Data.ID, "", "abc")
+	assert.Error(t, err)
+}
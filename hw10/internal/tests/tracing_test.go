@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+	contracts "homework10/internal/api/handlers/grpc/contracts/langs/go"
+)
+
+const (
+	testTraceID      = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testParentSpanID = "00f067aa0ba902b7"
+	testTraceParent  = "00-" + testTraceID + "-" + testParentSpanID + "-01"
+)
+
+// useSpanRecorder подменяет глобальные TracerProvider и пропагатор на время теста и возвращает записанные спаны
+func useSpanRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	propagator := otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(trace.NewNoopTracerProvider())
+		otel.SetTextMapPropagator(propagator)
+	})
+	return recorder
+}
+
+// traceSpans спаны трассировки testTraceID по именам
+func traceSpans(recorder *tracetest.SpanRecorder) map[string]sdktrace.ReadOnlySpan {
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		if span.SpanContext().TraceID().String() == testTraceID {
+			spans[span.Name()] = span
+		}
+	}
+	return spans
+}
+
+func TestHTTPTracing(t *testing.T) {
+	client := getTestClient()
+	user, err := client.createUser("ivan", "ivan@gmail.com")
+	require.NoError(t, err)
+	ad, err := client.createAd(user.Data.ID, "hello", "world")
+	require.NoError(t, err)
+
+	recorder := useSpanRecorder(t)
+	var logs bytes.Buffer
+	out := log.StandardLogger().Out
+	log.SetOutput(&logs)
+	t.Cleanup(func() {
+		log.SetOutput(out)
+	})
+
+	for _, adID := range []int64{ad.Data.ID, ad.Data.ID + 100} {
+		req, err := http.NewRequest(http.MethodGet, client.baseURL+"/api/v1/ads/"+strconv.FormatInt(adID, 10), nil)
+		require.NoError(t, err)
+		req.Header.Set("traceparent", testTraceParent)
+		resp, err := client.client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	spans := traceSpans(recorder)
+	server, ok := spans["GET /api/v1/ads/:ad_id"]
+	require.True(t, ok, "server span is recorded")
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind())
+	assert.Equal(t, testParentSpanID, server.Parent().SpanID().String(), "the trace continues from traceparent")
+	assert.True(t, server.Parent().IsRemote())
+	assert.Equal(t, codes.Unset, server.Status().Code, "404 is a client error")
+
+	svc, ok := spans["AdService.GetAdByID"]
+	require.True(t, ok, "service span is recorded")
+	assert.Equal(t, server.SpanContext().SpanID(), svc.Parent().SpanID())
+	assert.Equal(t, codes.Error, svc.Status().Code, "the last request asks for a missing ad")
+
+	repo, ok := spans["AdRepository.GetAd"]
+	require.True(t, ok, "repository span is recorded")
+	assert.Equal(t, svc.SpanContext().SpanID(), repo.Parent().SpanID())
+
+	assert.Contains(t, logs.String(), "TRACE_ID="+testTraceID)
+}
+
+func TestGRPCTracing(t *testing.T) {
+	recorder := useSpanRecorder(t)
+	ctx, conn := newGRPCTestConn(t)
+
+	client := contracts.NewUserServiceClient(conn)
+	ctx = metadata.AppendToOutgoingContext(ctx, "traceparent", testTraceParent)
+	_, err := client.CreateUser(ctx, &contracts.CreateUserRequest{Nickname: "Oleg", Email: "olega@gmail.com", Password: testPassword})
+	require.NoError(t, err)
+
+	spans := traceSpans(recorder)
+	server, ok := spans["service.UserService/CreateUser"]
+	require.True(t, ok, "server span is recorded")
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind())
+	assert.Equal(t, testParentSpanID, server.Parent().SpanID().String(), "the trace continues from metadata")
+
+	svc, ok := spans["UserService.CreateUser"]
+	require.True(t, ok, "service span is recorded")
+	assert.Equal(t, server.SpanContext().SpanID(), svc.Parent().SpanID())
+
+	repo, ok := spans["UserRepository.AddUser"]
+	require.True(t, ok, "repository span is recorded")
+	assert.Equal(t, svc.SpanContext().SpanID(), repo.Parent().SpanID())
+}
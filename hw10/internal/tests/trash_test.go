@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteAndRestoreAd(t *testing.T) {
+	client := getTestClient()
+	user, err := client.createUser("user_1", "email@gmail.com")
+	assert.NoError(t, err)
+	other, err := client.createUser("user_2", "other@gmail.com")
+	assert.NoError(t, err)
+
+	ad, err := client.createAd(user.Data.ID, "hello", "world")
+	assert.NoError(t, err)
+	assert.NoError(t, client.approveAd(user.Data.ID, ad.Data.ID))
+	_, err = client.changeAdStatus(user.Data.ID, ad.Data.ID, true)
+	assert.NoError(t, err)
+
+	_, err = client.deleteAd(user.Data.ID, ad.Data.ID)
+	assert.NoError(t, err)
+
+	_, err = client.getAd(user.Data.ID, ad.Data.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	ads, err := client.listAds()
+	assert.NoError(t, err)
+	assert.Empty(t, ads.Data)
+	found, err := client.searchAds("hello")
+	assert.NoError(t, err)
+	assert.Empty(t, found.Data)
+
+	trash, err := client.listTrash(user.Data.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, trash.Data, 1) {
+		assert.Equal(t, ad.Data.ID, trash.Data[0].ID)
+		assert.WithinDuration(t, time.Now(), trash.Data[0].DeletedAt, time.Minute)
+		assert.Equal(t, trash.Data[0].DeletedAt.Add(30*24*time.Hour), trash.Data[0].PurgeAt)
+	}
+
+	_, err = client.restoreAd(other.Data.ID, ad.Data.ID)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	restored, err := client.restoreAd(user.Data.ID, ad.Data.ID)
+	assert.NoError(t, err)
+	assert.True(t, restored.Data.Published)
+	_, err = client.restoreAd(user.Data.ID, ad.Data.ID)
+	assert.ErrorIs(t, err, ErrConflict)
+
+	ads, err = client.listAds()
+	assert.NoError(t, err)
+	assert.Len(t, ads.Data, 1)
+	found, err = client.searchAds("hello")
+	assert.NoError(t, err)
+	assert.Len(t, found.Data, 1)
+	trash, err = client.listTrash(user.Data.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, trash.Data)
+}
+
+func TestDeleteAndRestoreUser(t *testing.T) {
+	client := getTestClient()
+	user, err := client.createUser("user_1", "email@gmail.com")
+	assert.NoError(t, err)
+
+	kept, err := client.createAd(user.Data.ID, "kept", "text")
+	assert.NoError(t, err)
+	assert.NoError(t, client.approveAd(user.Data.ID, kept.Data.ID))
+	_, err = client.changeAdStatus(user.Data.ID, kept.Data.ID, true)
+	assert.NoError(t, err)
+	trashed, err := client.createAd(user.Data.ID, "trashed", "text")
+	assert.NoError(t, err)
+	_, err = client.deleteAd(user.Data.ID, trashed.Data.ID)
+	assert.NoError(t, err)
+
+	_, err = client.deleteUser(user.Data.ID)
+	assert.NoError(t, err)
+
+	_, err = client.getUser(user.Data.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = client.getAd(user.Data.ID, kept.Data.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = client.login("email@gmail.com", testPassword)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	_, err = client.createAd(user.Data.ID, "title", "text")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	_, err = client.createUser("user_1", "email@gmail.com")
+	assert.ErrorIs(t, err, ErrConflict)
+
+	_, err = client.restoreUser(user.Data.ID, "wrong password")
+	assert.ErrorIs(t, err, ErrForbidden)
+	restored, err := client.restoreUser(user.Data.ID, testPassword)
+	assert.NoError(t, err)
+	assert.Equal(t, user.Data, restored.Data)
+	_, err = client.restoreUser(user.Data.ID, testPassword)
+	assert.ErrorIs(t, err, ErrConflict)
+
+	ad, err := client.getAd(user.Data.ID, kept.Data.ID)
+	assert.NoError(t, err)
+	assert.True(t, ad.Data.Published)
+	trash, err := client.listTrash(user.Data.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, trash.Data, 1) {
+		assert.Equal(t, trashed.Data.ID, trash.Data[0].ID)
+	}
+}
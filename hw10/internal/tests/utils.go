@@ -1,15 +1,26 @@
 package tests
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"golang.org/x/crypto/bcrypt"
 	"homework10/internal/api/handlers/httpgin"
 	"homework10/internal/api/handlers/httpgin/middlewares"
+	"homework10/internal/auth"
+	"homework10/internal/idempotency"
+	"homework10/internal/policy"
 	"homework10/internal/repository/local-repo"
+	tracingrepo "homework10/internal/repository/tracing-repo"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
 
 	"homework10/internal/service"
 )
@@ -20,6 +31,41 @@ type adData struct {
 	Text      string `json:"text"`
 	AuthorID  int64  `json:"author_id"`
 	Published bool   `json:"published"`
+
+	DateCreation    string      `json:"date_creation"`
+	DateUpdate      string      `json:"date_update"`
+	State           string      `json:"state"`
+	RejectionReason string      `json:"rejection_reason"`
+	Images          []imageData `json:"images"`
+	Price           *priceData  `json:"price"`
+	CategoryID      string      `json:"category_id"`
+	City            string      `json:"city"`
+}
+
+type priceData struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+type imageData struct {
+	ID           int64  `json:"id"`
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	ContentType  string `json:"content_type"`
+	Size         int64  `json:"size"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+}
+
+type searchAdData struct {
+	adData
+	Score   float64 `json:"score"`
+	Snippet string  `json:"snippet"`
+}
+
+type searchAdsResponse struct {
+	Data          []searchAdData `json:"data"`
+	NextPageToken string         `json:"next_page_token"`
 }
 
 type adResponse struct {
@@ -34,37 +80,193 @@ type userData struct {
 	ID       int64  `json:"id"`
 	NickName string `json:"nickname"`
 	Email    string `json:"email"`
+	Role     string `json:"role"`
+}
+
+type tokenResponse struct {
+	Data tokenData `json:"data"`
+}
+
+type tokenData struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 type adsResponse struct {
-	Data []adData `json:"data"`
+	Data          []adData   `json:"data"`
+	NextPageToken string     `json:"next_page_token"`
+	Facets        facetsData `json:"facets"`
+}
+
+type facetCountData struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+type facetsData struct {
+	Categories []facetCountData `json:"categories"`
+	Cities     []facetCountData `json:"cities"`
+}
+
+type categoryData struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	ParentID string `json:"parent_id"`
+}
+
+type categoriesResponse struct {
+	Data []categoryData `json:"data"`
+}
+
+type fieldChangeData struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+type revisionData struct {
+	Number       int64             `json:"number"`
+	Type         string            `json:"type"`
+	AuthorID     int64             `json:"author_id"`
+	Title        string            `json:"title"`
+	Text         string            `json:"text"`
+	Published    bool              `json:"published"`
+	Changes      []fieldChangeData `json:"changes"`
+	RestoredFrom int64             `json:"restored_from"`
+}
+
+type revisionsResponse struct {
+	Data []revisionData `json:"data"`
+}
+
+type trashedAdData struct {
+	adData
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+type trashResponse struct {
+	Data []trashedAdData `json:"data"`
+}
+
+type messageData struct {
+	ID             int64  `json:"id"`
+	ConversationID int64  `json:"conversation_id"`
+	SenderID       int64  `json:"sender_id"`
+	Text           string `json:"text"`
+	ReadAt         string `json:"read_at"`
+}
+
+type messageResponse struct {
+	Data messageData `json:"data"`
+}
+
+type messagesResponse struct {
+	Data []messageData `json:"data"`
+}
+
+type conversationData struct {
+	ID          int64       `json:"id"`
+	AdID        int64       `json:"ad_id"`
+	BuyerID     int64       `json:"buyer_id"`
+	SellerID    int64       `json:"seller_id"`
+	LastMessage messageData `json:"last_message"`
+	UnreadCount int         `json:"unread_count"`
+}
+
+type conversationsResponse struct {
+	Data        []conversationData `json:"data"`
+	UnreadCount int                `json:"unread_count"`
+}
+
+type favoriteAdData struct {
+	adData
+	AddedAt time.Time `json:"added_at"`
+}
+
+type favoritesResponse struct {
+	Data []favoriteAdData `json:"data"`
 }
 
 var (
-	ErrBadRequest = fmt.Errorf("bad request")
-	ErrForbidden  = fmt.Errorf("forbidden")
+	ErrBadRequest   = fmt.Errorf("bad request")
+	ErrForbidden    = fmt.Errorf("forbidden")
+	ErrUnauthorized = fmt.Errorf("unauthorized")
+	ErrConflict     = fmt.Errorf("conflict")
+	ErrNotFound     = fmt.Errorf("not found")
+	// ErrPreconditionFailed - объявление изменилось с версии из If-Match
+	ErrPreconditionFailed = fmt.Errorf("precondition failed")
+	// ErrTooManyRequests - клиент превысил лимит запросов
+	ErrTooManyRequests = fmt.Errorf("too many requests")
+)
+
+const (
+	testTokenSecret = "test secret"
+	// testPassword - пароль, с которым createUser регистрирует пользователей
+	testPassword = "test password"
+	// testModeratorEmail - почта модератора, которого регистрируют approveAd и grpcApproveAd
+	testModeratorEmail = "moderator@gmail.com"
+	// testAdminEmail - почта администратора, которому роль выдаётся так же, как при запуске сервиса
+	testAdminEmail = "admin@gmail.com"
 )
 
 type testClient struct {
 	client  *http.Client
 	baseURL string
+	// tokens - токены доступа зарегистрированных через клиента пользователей
+	tokens map[int64]string
+	// moderatorID - модератор, которого approveAd регистрирует при первом вызове
+	moderatorID *int64
+	// adminID - администратор, которого admin регистрирует при первом вызове
+	adminID *int64
+	// users - сервис, через который admin выдаёт роль администратора
+	users *service.UserService
 }
 
 func getTestClient() *testClient {
-	userService := service.NewUserService(localrepo.NewUserRepo())
-	adService := service.NewAdService(localrepo.NewAdRepo())
-
-	userMiddleware := middlewares.NewUserIdentityMiddleware(userService)
+	return getTestClientWithOptions(httpgin.Options{})
+}
 
-	httpAdHandler := httpgin.NewAdHandler(adService, userMiddleware)
-	httpUserHandler := httpgin.NewUserHandler(userService)
-	httpRouter := httpgin.MakeRoutes(httpgin.ApiV1, httpAdHandler, httpUserHandler)
+// getTestClientWithOptions то же, что getTestClient, но с необязательными middleware opts, например
+// с ограничителем частоты запросов; без заданного хранилища ключи идемпотентности хранятся час
+func getTestClientWithOptions(opts httpgin.Options) *testClient {
+	userRepo := tracingrepo.NewUserRepo(localrepo.NewUserRepo())
+	adService := service.NewAdService(tracingrepo.NewAdRepo(localrepo.NewAdRepo()), localrepo.NewRevisionRepo())
+	adService.Favorites = localrepo.NewFavoriteRepo()
+	userService := service.NewUserService(userRepo, adService)
+	userService.PasswordCost = bcrypt.MinCost
+	tracedAdService := service.NewTracedAdService(adService)
+	tracedUserService := service.NewTracedUserService(userService)
+
+	tokens := auth.NewTokenManager([]byte(testTokenSecret), time.Hour)
+	authService := service.NewAuthService(userRepo, tokens)
+
+	userMiddleware := middlewares.NewUserIdentityMiddleware(tokens, tracedUserService, policy.Default())
+
+	httpAdHandler := httpgin.NewAdHandler(tracedAdService)
+	httpUserHandler := httpgin.NewUserHandler(tracedUserService)
+	httpAuthHandler := httpgin.NewAuthHandler(authService)
+	httpMessageHandler := httpgin.NewMessageHandler(service.NewMessageService(localrepo.NewMessageRepo(), adService))
+	if opts.Idempotency == nil {
+		opts.Idempotency = idempotency.NewStore(time.Hour)
+	}
+	httpRouter := httpgin.MakeRoutes(httpgin.ApiV1, userMiddleware, opts, httpAdHandler, httpUserHandler,
+		httpAuthHandler, httpMessageHandler)
 
 	testServer := httptest.NewServer(httpRouter)
 
 	return &testClient{
 		client:  testServer.Client(),
 		baseURL: testServer.URL,
+		tokens:  make(map[int64]string),
+		users:   userService,
+	}
+}
+
+// authorize подписывает запрос токеном пользователя, если пользователь входил через клиента
+func (tc *testClient) authorize(req *http.Request, userID int64) {
+	if token, ok := tc.tokens[userID]; ok {
+		req.Header.Add("Authorization", "Bearer "+token)
 	}
 }
 
@@ -81,6 +283,21 @@ func (tc *testClient) getResponse(req *http.Request, out any) error {
 		if resp.StatusCode == http.StatusForbidden {
 			return ErrForbidden
 		}
+		if resp.StatusCode == http.StatusUnauthorized {
+			return ErrUnauthorized
+		}
+		if resp.StatusCode == http.StatusConflict {
+			return ErrConflict
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			return ErrNotFound
+		}
+		if resp.StatusCode == http.StatusPreconditionFailed {
+			return ErrPreconditionFailed
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return ErrTooManyRequests
+		}
 		return fmt.Errorf("unexpected status code: %s", resp.Status)
 	}
 
@@ -98,12 +315,14 @@ func (tc *testClient) getResponse(req *http.Request, out any) error {
 }
 
 func (tc *testClient) createAd(userID int64, title string, text string) (adResponse, error) {
-	body := map[string]any{
-		"user_id": userID,
-		"title":   title,
-		"text":    text,
-	}
+	return tc.createAdWithDetails(userID, map[string]any{
+		"title": title,
+		"text":  text,
+	})
+}
 
+// createAdWithDetails создаёт объявление с произвольным телом запроса, например с ценой, категорией и городом
+func (tc *testClient) createAdWithDetails(userID int64, body map[string]any) (adResponse, error) {
 	data, err := json.Marshal(body)
 	if err != nil {
 		return adResponse{}, fmt.Errorf("unable to marshal: %w", err)
@@ -115,6 +334,7 @@ func (tc *testClient) createAd(userID int64, title string, text string) (adRespo
 	}
 
 	req.Header.Add("Content-Type", "application/json")
+	tc.authorize(req, userID)
 
 	var response adResponse
 	err = tc.getResponse(req, &response)
@@ -127,7 +347,6 @@ func (tc *testClient) createAd(userID int64, title string, text string) (adRespo
 
 func (tc *testClient) changeAdStatus(userID int64, adID int64, published bool) (adResponse, error) {
 	body := map[string]any{
-		"user_id":   userID,
 		"published": published,
 	}
 
@@ -142,6 +361,41 @@ func (tc *testClient) changeAdStatus(userID int64, adID int64, published bool) (
 	}
 
 	req.Header.Add("Content-Type", "application/json")
+	tc.authorize(req, userID)
+
+	var response adResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return adResponse{}, err
+	}
+
+	return response, nil
+}
+
+func (tc *testClient) submitAd(userID int64, adID int64) (adResponse, error) {
+	return tc.moderateAd(userID, adID, "submit", nil)
+}
+
+func (tc *testClient) reviewAd(moderatorID int64, adID int64, approve bool, reason string) (adResponse, error) {
+	if approve {
+		return tc.moderateAd(moderatorID, adID, "approve", nil)
+	}
+	return tc.moderateAd(moderatorID, adID, "reject", map[string]any{"reason": reason})
+}
+
+func (tc *testClient) moderateAd(userID int64, adID int64, action string, body map[string]any) (adResponse, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return adResponse{}, fmt.Errorf("unable to marshal: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf(tc.baseURL+"/api/v1/ads/%d/%s", adID, action), bytes.NewReader(data))
+	if err != nil {
+		return adResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+	tc.authorize(req, userID)
 
 	var response adResponse
 	err = tc.getResponse(req, &response)
@@ -152,11 +406,100 @@ func (tc *testClient) changeAdStatus(userID int64, adID int64, published bool) (
 	return response, nil
 }
 
+// approveAd отправляет объявление автора на проверку и одобряет его от имени модератора, после чего его можно опубликовать
+func (tc *testClient) approveAd(userID int64, adID int64) error {
+	if tc.moderatorID == nil {
+		moderator, err := tc.createModerator("moderator", testModeratorEmail)
+		if err != nil {
+			return err
+		}
+		tc.moderatorID = &moderator.Data.ID
+	}
+	if _, err := tc.submitAd(userID, adID); err != nil {
+		return err
+	}
+	_, err := tc.reviewAd(*tc.moderatorID, adID, true, "")
+	return err
+}
+
+// admin регистрирует администратора при первом вызове так же, как его назначает оператор при запуске сервиса,
+// и возвращает его ID
+func (tc *testClient) admin() (int64, error) {
+	if tc.adminID == nil {
+		admin, err := tc.createUser("admin", testAdminEmail)
+		if err != nil {
+			return 0, err
+		}
+		tc.users.Admins = append(tc.users.Admins, admin.Data.ID)
+		if err := tc.users.AssignRoles(context.Background()); err != nil {
+			return 0, err
+		}
+		tc.adminID = &admin.Data.ID
+	}
+	return *tc.adminID, nil
+}
+
+// createModerator регистрирует пользователя и выдаёт ему роль модератора от имени администратора
+func (tc *testClient) createModerator(nickname string, email string) (userResponse, error) {
+	user, err := tc.createUser(nickname, email)
+	if err != nil {
+		return userResponse{}, err
+	}
+	adminID, err := tc.admin()
+	if err != nil {
+		return userResponse{}, err
+	}
+	return tc.setUserRole(adminID, user.Data.ID, "moderator")
+}
+
+func (tc *testClient) setUserRole(userID int64, targetID int64, role string) (userResponse, error) {
+	data, err := json.Marshal(map[string]any{"role": role})
+	if err != nil {
+		return userResponse{}, fmt.Errorf("unable to marshal: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf(tc.baseURL+"/api/v1/users/%d/role", targetID), bytes.NewReader(data))
+	if err != nil {
+		return userResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+	tc.authorize(req, userID)
+
+	var response userResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return userResponse{}, err
+	}
+
+	return response, nil
+}
+
+func (tc *testClient) moderationQueue(userID int64) (adsResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, tc.baseURL+"/api/v1/ads/moderation", nil)
+	if err != nil {
+		return adsResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+	tc.authorize(req, userID)
+
+	var response adsResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return adsResponse{}, err
+	}
+
+	return response, nil
+}
+
 func (tc *testClient) updateAd(userID int64, adID int64, title string, text string) (adResponse, error) {
+	return tc.updateAdIfMatch(userID, adID, title, text, "")
+}
+
+// updateAdIfMatch обновляет объявление с заголовком If-Match, если ifMatch не пуст
+func (tc *testClient) updateAdIfMatch(userID int64, adID int64, title string, text string, ifMatch string) (adResponse, error) {
 	body := map[string]any{
-		"user_id": userID,
-		"title":   title,
-		"text":    text,
+		"title": title,
+		"text":  text,
 	}
 
 	data, err := json.Marshal(body)
@@ -170,6 +513,10 @@ func (tc *testClient) updateAd(userID int64, adID int64, title string, text stri
 	}
 
 	req.Header.Add("Content-Type", "application/json")
+	if ifMatch != "" {
+		req.Header.Add("If-Match", ifMatch)
+	}
+	tc.authorize(req, userID)
 
 	var response adResponse
 	err = tc.getResponse(req, &response)
@@ -194,7 +541,12 @@ func (tc *testClient) listAds() (adsResponse, error) {
 
 	return response, nil
 }
-func (tc *testClient) getAd(adID int64) (adResponse, error) {
+
+// anonymous пользователь без токена: под этим ID клиент не входил
+const anonymous int64 = -1
+
+// getAd запрашивает объявление от имени userID: черновики видят только автор, модераторы и администраторы
+func (tc *testClient) getAd(userID int64, adID int64) (adResponse, error) {
 	body := map[string]any{
 		"id": adID,
 	}
@@ -210,6 +562,7 @@ func (tc *testClient) getAd(adID int64) (adResponse, error) {
 	}
 
 	req.Header.Add("Content-Type", "application/json")
+	tc.authorize(req, userID)
 
 	var response adResponse
 	err = tc.getResponse(req, &response)
@@ -220,22 +573,139 @@ func (tc *testClient) getAd(adID int64) (adResponse, error) {
 	return response, nil
 }
 
-func (tc *testClient) deleteAd(adID int64) (adResponse, error) {
-	body := map[string]any{
-		"id": adID,
+// getAdETag возвращает ETag объявления из ответа на GET
+func (tc *testClient) getAdETag(userID int64, adID int64) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf(tc.baseURL+"/api/v1/ads/%d", adID), nil)
+	if err != nil {
+		return "", fmt.Errorf("unable to create request: %w", err)
+	}
+	tc.authorize(req, userID)
+	resp, err := tc.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("unexpected error: %w", err)
 	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code: %s", resp.Status)
+	}
+	return resp.Header.Get("ETag"), nil
+}
 
-	data, err := json.Marshal(body)
+func (tc *testClient) deleteAd(userID int64, adID int64) (adResponse, error) {
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf(tc.baseURL+"/api/v1/ads/%d", adID), nil)
 	if err != nil {
-		return adResponse{}, fmt.Errorf("unable to marshal: %w", err)
+		return adResponse{}, fmt.Errorf("unable to create request: %w", err)
 	}
 
-	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf(tc.baseURL+"/api/v1/ads/%d", adID), bytes.NewReader(data))
+	tc.authorize(req, userID)
+
+	var response adResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return adResponse{}, err
+	}
+
+	return response, nil
+}
+
+// uploadAdImage загружает файл как изображение объявления в поле формы image
+func (tc *testClient) uploadAdImage(userID int64, adID int64, filename string, data []byte) (adResponse, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("image", filename)
+	if err != nil {
+		return adResponse{}, fmt.Errorf("unable to create form: %w", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return adResponse{}, fmt.Errorf("unable to write form: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return adResponse{}, fmt.Errorf("unable to write form: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf(tc.baseURL+"/api/v1/ads/%d/images", adID), &body)
 	if err != nil {
 		return adResponse{}, fmt.Errorf("unable to create request: %w", err)
 	}
 
-	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Content-Type", form.FormDataContentType())
+	tc.authorize(req, userID)
+
+	var response adResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return adResponse{}, err
+	}
+
+	return response, nil
+}
+
+// download скачивает файл по ссылке из ответа API и возвращает его тип и содержимое
+func (tc *testClient) download(userID int64, link string) (string, []byte, error) {
+	req, err := http.NewRequest(http.MethodGet, tc.baseURL+link, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to create request: %w", err)
+	}
+	tc.authorize(req, userID)
+	resp, err := tc.client.Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("unexpected error: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("unexpected status code: %s", resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to read response: %w", err)
+	}
+	return resp.Header.Get("Content-Type"), data, nil
+}
+
+func (tc *testClient) listAdRevisions(userID int64, adID int64) (revisionsResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf(tc.baseURL+"/api/v1/ads/%d/revisions", adID), nil)
+	if err != nil {
+		return revisionsResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+	tc.authorize(req, userID)
+
+	var response revisionsResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return revisionsResponse{}, err
+	}
+
+	return response, nil
+}
+
+func (tc *testClient) restoreAdRevision(userID int64, adID int64, number int64) (adResponse, error) {
+	req, err := http.NewRequest(http.MethodPost,
+		fmt.Sprintf(tc.baseURL+"/api/v1/ads/%d/revisions/%d/restore", adID, number), nil)
+	if err != nil {
+		return adResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	tc.authorize(req, userID)
+
+	var response adResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return adResponse{}, err
+	}
+
+	return response, nil
+}
+
+func (tc *testClient) restoreAd(userID int64, adID int64) (adResponse, error) {
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf(tc.baseURL+"/api/v1/ads/%d/restore", adID), nil)
+	if err != nil {
+		return adResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	tc.authorize(req, userID)
 
 	var response adResponse
 	err = tc.getResponse(req, &response)
@@ -263,10 +733,46 @@ func (tc *testClient) listAdsWithFilters(published bool, userID int64, dateCreat
 	return response, nil
 }
 
-func (tc *testClient) searchAds(text string) (adsResponse, error) {
+func (tc *testClient) listAdsByDates(userID int64, createdFrom string, createdTo string, updatedSince string) (adsResponse, error) {
+	query := url.Values{}
+	query.Set("published", "false")
+	query.Set("created_from", createdFrom)
+	query.Set("created_to", createdTo)
+	query.Set("updated_since", updatedSince)
+	req, err := http.NewRequest(http.MethodGet, tc.baseURL+"/api/v1/ads?"+query.Encode(), nil)
+	if err != nil {
+		return adsResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+	tc.authorize(req, userID)
+
+	var response adsResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return adsResponse{}, err
+	}
+
+	return response, nil
+}
+
+func (tc *testClient) listAdsPage(limit int, sortBy string, order string, pageToken string) (adsResponse, error) {
 	req, err := http.NewRequest(http.MethodGet,
-		fmt.Sprintf(tc.baseURL+"/api/v1/ads/search?text=%s",
-			text), nil)
+		fmt.Sprintf(tc.baseURL+"/api/v1/ads?limit=%d&sort_by=%s&order=%s&page_token=%s",
+			limit, sortBy, order, pageToken), nil)
+	if err != nil {
+		return adsResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	var response adsResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return adsResponse{}, err
+	}
+
+	return response, nil
+}
+
+func (tc *testClient) listAdsByQuery(query url.Values) (adsResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, tc.baseURL+"/api/v1/ads?"+query.Encode(), nil)
 	if err != nil {
 		return adsResponse{}, fmt.Errorf("unable to create request: %w", err)
 	}
@@ -280,10 +786,43 @@ func (tc *testClient) searchAds(text string) (adsResponse, error) {
 	return response, nil
 }
 
+func (tc *testClient) listCategories() (categoriesResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, tc.baseURL+"/api/v1/ads/categories", nil)
+	if err != nil {
+		return categoriesResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	var response categoriesResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return categoriesResponse{}, err
+	}
+
+	return response, nil
+}
+
+func (tc *testClient) searchAds(text string) (searchAdsResponse, error) {
+	req, err := http.NewRequest(http.MethodGet,
+		fmt.Sprintf(tc.baseURL+"/api/v1/ads/search?text=%s",
+			url.QueryEscape(text)), nil)
+	if err != nil {
+		return searchAdsResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	var response searchAdsResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return searchAdsResponse{}, err
+	}
+
+	return response, nil
+}
+
 func (tc *testClient) createUser(nickname string, email string) (userResponse, error) {
 	body := map[string]any{
 		"nickname": nickname,
 		"email":    email,
+		"password": testPassword,
 	}
 
 	data, err := json.Marshal(body)
@@ -305,9 +844,65 @@ func (tc *testClient) createUser(nickname string, email string) (userResponse, e
 		return userResponse{}, err
 	}
 
+	token, err := tc.login(email, testPassword)
+	if err != nil {
+		return userResponse{}, err
+	}
+	tc.tokens[response.Data.ID] = token.Data.Token
+
 	return response, nil
 }
 
+func (tc *testClient) login(email string, password string) (tokenResponse, error) {
+	body := map[string]any{
+		"email":    email,
+		"password": password,
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return tokenResponse{}, fmt.Errorf("unable to marshal: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, tc.baseURL+"/api/v1/auth/login", bytes.NewReader(data))
+	if err != nil {
+		return tokenResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+
+	var response tokenResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return tokenResponse{}, err
+	}
+
+	return response, nil
+}
+
+func (tc *testClient) changePassword(userID int64, oldPassword string, newPassword string) error {
+	body := map[string]any{
+		"old_password": oldPassword,
+		"new_password": newPassword,
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("unable to marshal: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf(tc.baseURL+"/api/v1/users/%d/password", userID), bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+	tc.authorize(req, userID)
+
+	var response map[string]any
+	return tc.getResponse(req, &response)
+}
+
 func (tc *testClient) getUser(userID int64) (userResponse, error) {
 	body := map[string]any{
 		"user_id": userID,
@@ -350,6 +945,7 @@ func (tc *testClient) deleteUser(userID int64) (userResponse, error) {
 	}
 
 	req.Header.Add("Content-Type", "application/json")
+	tc.authorize(req, userID)
 
 	var response userResponse
 	err = tc.getResponse(req, &response)
@@ -359,3 +955,213 @@ func (tc *testClient) deleteUser(userID int64) (userResponse, error) {
 
 	return response, nil
 }
+
+func (tc *testClient) restoreUser(userID int64, password string) (userResponse, error) {
+	data, err := json.Marshal(map[string]any{"password": password})
+	if err != nil {
+		return userResponse{}, fmt.Errorf("unable to marshal: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf(tc.baseURL+"/api/v1/users/%d/restore", userID), bytes.NewReader(data))
+	if err != nil {
+		return userResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+
+	var response userResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return userResponse{}, err
+	}
+
+	return response, nil
+}
+
+func (tc *testClient) listTrash(userID int64) (trashResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf(tc.baseURL+"/api/v1/users/%d/trash", userID), nil)
+	if err != nil {
+		return trashResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	tc.authorize(req, userID)
+
+	var response trashResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return trashResponse{}, err
+	}
+
+	return response, nil
+}
+
+func (tc *testClient) addFavorite(userID int64, adID int64) (adResponse, error) {
+	req, err := http.NewRequest(http.MethodPost,
+		fmt.Sprintf(tc.baseURL+"/api/v1/users/%d/favorites/%d", userID, adID), nil)
+	if err != nil {
+		return adResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	tc.authorize(req, userID)
+
+	var response adResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return adResponse{}, err
+	}
+
+	return response, nil
+}
+
+func (tc *testClient) removeFavorite(userID int64, adID int64) error {
+	req, err := http.NewRequest(http.MethodDelete,
+		fmt.Sprintf(tc.baseURL+"/api/v1/users/%d/favorites/%d", userID, adID), nil)
+	if err != nil {
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+
+	tc.authorize(req, userID)
+
+	return tc.getResponse(req, &map[string]any{})
+}
+
+func (tc *testClient) listFavorites(userID int64) (favoritesResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf(tc.baseURL+"/api/v1/users/%d/favorites", userID), nil)
+	if err != nil {
+		return favoritesResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	tc.authorize(req, userID)
+
+	var response favoritesResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return favoritesResponse{}, err
+	}
+
+	return response, nil
+}
+
+func (tc *testClient) sendMessage(userID int64, adID int64, conversationID int64, text string) (messageResponse, error) {
+	data, err := json.Marshal(map[string]any{"text": text, "conversation_id": conversationID})
+	if err != nil {
+		return messageResponse{}, fmt.Errorf("unable to marshal: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf(tc.baseURL+"/api/v1/ads/%d/messages", adID),
+		bytes.NewReader(data))
+	if err != nil {
+		return messageResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+	tc.authorize(req, userID)
+
+	var response messageResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return messageResponse{}, err
+	}
+
+	return response, nil
+}
+
+func (tc *testClient) listConversations(userID int64) (conversationsResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf(tc.baseURL+"/api/v1/users/%d/conversations", userID), nil)
+	if err != nil {
+		return conversationsResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	tc.authorize(req, userID)
+
+	var response conversationsResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return conversationsResponse{}, err
+	}
+
+	return response, nil
+}
+
+func (tc *testClient) listMessages(userID int64, conversationID int64) (messagesResponse, error) {
+	req, err := http.NewRequest(http.MethodGet,
+		fmt.Sprintf(tc.baseURL+"/api/v1/users/%d/conversations/%d/messages", userID, conversationID), nil)
+	if err != nil {
+		return messagesResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	tc.authorize(req, userID)
+
+	var response messagesResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return messagesResponse{}, err
+	}
+
+	return response, nil
+}
+
+func (tc *testClient) markConversationRead(userID int64, conversationID int64) error {
+	req, err := http.NewRequest(http.MethodPost,
+		fmt.Sprintf(tc.baseURL+"/api/v1/users/%d/conversations/%d/read", userID, conversationID), nil)
+	if err != nil {
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+
+	tc.authorize(req, userID)
+
+	return tc.getResponse(req, &map[string]any{})
+}
+
+// sseEvent событие потока /ads/stream
+type sseEvent struct {
+	ID    string
+	Event string
+	Data  string
+}
+
+// streamAds открывает поток изменений объявлений; когда функция вернула управление, сервер уже подписан
+// streamAds подписывается на ленту от имени пользователя userID; без его токена подписка анонимная
+func (tc *testClient) streamAds(userID int64, query string, lastEventID string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, tc.baseURL+"/api/v1/ads/stream"+query, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create request: %w", err)
+	}
+	tc.authorize(req, userID)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := tc.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected error: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status code: %s", resp.Status)
+	}
+	return resp, nil
+}
+
+// readSSEEvent читает из потока следующее событие, пропуская комментарии
+func readSSEEvent(r *bufio.Reader) (sseEvent, error) {
+	var event sseEvent
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return event, err
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			if event != (sseEvent{}) {
+				return event, nil
+			}
+		case strings.HasPrefix(line, "id:"):
+			event.ID = strings.TrimPrefix(line, "id:")
+		case strings.HasPrefix(line, "event:"):
+			event.Event = strings.TrimPrefix(line, "event:")
+		case strings.HasPrefix(line, "data:"):
+			event.Data = strings.TrimPrefix(line, "data:")
+		}
+	}
+}
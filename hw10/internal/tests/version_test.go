@@ -0,0 +1,38 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateAd_IfMatch(t *testing.T) {
+	client := getTestClient()
+	user, err := client.createUser("user_1", "email@gmail.com")
+	assert.NoError(t, err)
+
+	ad, err := client.createAd(user.Data.ID, "hello", "world")
+	assert.NoError(t, err)
+
+	etag, err := client.getAdETag(user.Data.ID, ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, `"1"`, etag)
+
+	// первый клиент успевает изменить объявление
+	_, err = client.updateAdIfMatch(user.Data.ID, ad.Data.ID, "first", "world", etag)
+	assert.NoError(t, err)
+
+	// второй клиент отправляет изменение по устаревшей версии
+	_, err = client.updateAdIfMatch(user.Data.ID, ad.Data.ID, "second", "world", etag)
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+
+	got, err := client.getAd(user.Data.ID, ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "first", got.Data.Title)
+
+	etag, err = client.getAdETag(user.Data.ID, ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, `"2"`, etag)
+	_, err = client.updateAdIfMatch(user.Data.ID, ad.Data.ID, "second", "world", etag)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,101 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// ExporterNone спаны не экспортируются, но ID трассировки по-прежнему попадают в логи
+	ExporterNone = "none"
+	// ExporterStdout спаны печатаются в stdout
+	ExporterStdout = "stdout"
+	// ExporterOTLP спаны отправляются по OTLP/gRPC коллектору
+	ExporterOTLP = "otlp"
+
+	// DefaultOTLPEndpoint адрес локального коллектора OpenTelemetry
+	DefaultOTLPEndpoint = "localhost:4317"
+
+	serviceName = "homework10"
+	// instrumentationName имя, под которым сервер создаёт спаны
+	instrumentationName = "homework10"
+)
+
+// Setup настраивает глобальные TracerProvider с экспортёром exporter и распространение контекста
+// по W3C Trace Context (заголовок traceparent). Возвращённая функция отправляет накопленные спаны
+// и останавливает экспорт
+func Setup(ctx context.Context, exporter string, otlpEndpoint string) (shutdown func(context.Context) error, err error) {
+	var spanExporter sdktrace.SpanExporter
+	switch exporter {
+	case "", ExporterNone:
+	case ExporterStdout:
+		spanExporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case ExporterOTLP:
+		spanExporter, err = otlptracegrpc.New(ctx,
+			otlptracegrpc.WithEndpoint(otlpEndpoint),
+			otlptracegrpc.WithInsecure(),
+		)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q: expected %s, %s or %s",
+			exporter, ExporterNone, ExporterStdout, ExporterOTLP)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't create %s tracing exporter: %w", exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, err
+	}
+	opts := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+	if spanExporter != nil {
+		opts = append(opts, sdktrace.WithBatcher(spanExporter))
+	}
+	provider := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider.Shutdown, nil
+}
+
+// Start начинает спан name, дочерний к спану из ctx
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+// End завершает спан, отмечая в нём ошибку *err, если она есть. Удобно вызывать через defer
+// с указателем на именованный результат
+func End(span trace.Span, err *error) {
+	if err != nil && *err != nil {
+		Fail(span, *err)
+	}
+	span.End()
+}
+
+// Fail отмечает спан как завершившийся ошибкой err
+func Fail(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// LogFields дополняет поля записи logrus ID трассировки и спана из ctx, если запрос трассируется
+func LogFields(ctx context.Context, fields log.Fields) log.Fields {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if spanContext.IsValid() {
+		fields["TRACE_ID"] = spanContext.TraceID().String()
+		fields["SPAN_ID"] = spanContext.SpanID().String()
+	}
+	return fields
+}